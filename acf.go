@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Correlogram holds the autocorrelation and partial autocorrelation functions of a series.
+// ACF[k] is the autocorrelation at lag k (ACF[0] = 1) and PACF[k-1] the partial autocorrelation at
+// lag k. Values outside ±Band differ from zero at the requested confidence under a white-noise null.
+type Correlogram struct {
+	N          int       `json:"n"`
+	ACF        []float64 `json:"acf"`
+	PACF       []float64 `json:"pacf"`
+	Confidence float64   `json:"confidence"`
+	Band       float64   `json:"band"`
+}
+
+// DefaultMaxLag is R's default lag.max for acf(), 10 log10(n), capped at n - 1
+func DefaultMaxLag(n int) int {
+	return max(0, min(n-1, int(math.Floor(10*math.Log10(float64(n))))))
+}
+
+// ACF returns the sample autocorrelations of values at lags 0 to maxLag, computed as R's acf()
+// does: deviations from the mean with the biased (divide by n) autocovariance.
+// Missing values are dropped first, so the series should have none in its interior.
+func ACF(values []float64, maxLag int) ([]float64, error) {
+	series := finiteInOrder(values)
+	n := len(series)
+	if n < 2 {
+		return nil, fmt.Errorf("need at least two finite values, have %d", n)
+	}
+	if maxLag < 0 || maxLag >= n {
+		return nil, fmt.Errorf("max lag must be between 0 and %d, got %d", n-1, maxLag)
+	}
+
+	mean, _ := meanVariance(series)
+	acov := make([]float64, maxLag+1)
+	for k := range acov {
+		for t := k; t < n; t++ {
+			acov[k] += (series[t] - mean) * (series[t-k] - mean)
+		}
+	}
+	if acov[0] == 0 {
+		return nil, fmt.Errorf("series is constant")
+	}
+	acf := make([]float64, maxLag+1)
+	for k := range acf {
+		acf[k] = acov[k] / acov[0]
+	}
+	return acf, nil
+}
+
+// PACF returns the partial autocorrelations at lags 1 to maxLag, by the Durbin–Levinson recursion
+// on the sample autocorrelations
+func PACF(values []float64, maxLag int) ([]float64, error) {
+	acf, err := ACF(values, maxLag)
+	if err != nil {
+		return nil, err
+	}
+	return pacfFromACF(acf), nil
+}
+
+func pacfFromACF(acf []float64) []float64 {
+	maxLag := len(acf) - 1
+	pacf := make([]float64, maxLag)
+	phi := make([]float64, maxLag+1)
+	prev := make([]float64, maxLag+1)
+	for k := 1; k <= maxLag; k++ {
+		num, den := acf[k], 1.0
+		for j := 1; j < k; j++ {
+			num -= prev[j] * acf[k-j]
+			den -= prev[j] * acf[j]
+		}
+		phi[k] = num / den
+		for j := 1; j < k; j++ {
+			phi[j] = prev[j] - phi[k]*prev[k-j]
+		}
+		pacf[k-1] = phi[k]
+		copy(prev, phi)
+	}
+	return pacf
+}
+
+// NewCorrelogram computes the ACF and PACF up to maxLag (DefaultMaxLag when 0 or less) with the
+// white-noise band ±z/√n at the given confidence level (e.g. 0.95)
+func NewCorrelogram(values []float64, maxLag int, confidence float64) (Correlogram, error) {
+	if !(confidence > 0 && confidence < 1) {
+		return Correlogram{}, fmt.Errorf("confidence must be between 0 and 1, got %v", confidence)
+	}
+	n := len(finiteInOrder(values))
+	if maxLag <= 0 {
+		maxLag = DefaultMaxLag(n)
+	}
+	acf, err := ACF(values, maxLag)
+	if err != nil {
+		return Correlogram{}, err
+	}
+	return Correlogram{
+		N:          n,
+		ACF:        acf,
+		PACF:       pacfFromACF(acf),
+		Confidence: confidence,
+		Band:       normalQuantile((1+confidence)/2) / math.Sqrt(float64(n)),
+	}, nil
+}
+
+// SignificantLags returns the lags whose autocorrelation lies outside the band
+func (c Correlogram) SignificantLags() []int {
+	var lags []int
+	for k := 1; k < len(c.ACF); k++ {
+		if math.Abs(c.ACF[k]) > c.Band {
+			lags = append(lags, k)
+		}
+	}
+	return lags
+}
+
+// finiteInOrder returns the finite values of a series without reordering them
+func finiteInOrder(values []float64) []float64 {
+	out := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !isMissing(v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// printAutocorrelation writes a one-line correlogram summary for a report: the lag-1
+// autocorrelation and the lags outside the 95% white-noise band
+func printAutocorrelation(w io.Writer, name string, values []float64) {
+	c, err := NewCorrelogram(values, 0, 0.95)
+	if err != nil || len(c.ACF) < 2 {
+		fmt.Fprintf(w, "    %-10s autocorrelation n/a\n", name+":")
+		return
+	}
+	lags := c.SignificantLags()
+	outside := "none"
+	if len(lags) > 0 {
+		outside = strings.Trim(fmt.Sprint(lags), "[]")
+	}
+	fmt.Fprintf(w, "    %-10s lag-1 ACF = %.4f, PACF = %.4f, lags outside ±%.4f: %s\n", name+":", c.ACF[1], c.PACF[0], c.Band, outside)
+}
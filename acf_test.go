@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: ACF and PACF of 1..10 match R's acf() and pacf()
+func TestACFMatchesR(t *testing.T) {
+	series := []float64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	acf, err := ACF(series, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acf[0] != 1 || math.Abs(acf[1]-0.7) > 1e-12 || math.Abs(acf[2]-34/82.5) > 1e-12 {
+		t.Errorf("unexpected ACF %v", acf)
+	}
+	pacf, _ := PACF(series, 2)
+	if math.Abs(pacf[0]-0.7) > 1e-12 || math.Abs(pacf[1]-(34/82.5-0.49)/0.51) > 1e-12 {
+		t.Errorf("unexpected PACF %v", pacf)
+	}
+
+	if _, err := ACF(series, 10); err == nil {
+		t.Error("expected an error for a lag beyond the series")
+	}
+	if _, err := ACF([]float64{2, 2, 2}, 1); err == nil {
+		t.Error("expected an error for a constant series")
+	}
+}
+
+// ✅ Test 2: An AR(1) series shows a decaying ACF and a single PACF spike
+func TestCorrelogramAR1(t *testing.T) {
+	rng := rand.New(rand.NewPCG(9, 9))
+	series := make([]float64, 2000)
+	for i := 1; i < len(series); i++ {
+		series[i] = 0.6*series[i-1] + rng.NormFloat64()
+	}
+
+	c, err := NewCorrelogram(series, 0, 0.95)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.ACF) != DefaultMaxLag(2000)+1 || math.Abs(c.Band-1.959963984540054/math.Sqrt(2000)) > 1e-12 {
+		t.Errorf("unexpected lags or band: %d lags, band %v", len(c.ACF), c.Band)
+	}
+	if math.Abs(c.ACF[1]-0.6) > 0.05 || math.Abs(c.ACF[2]-0.36) > 0.06 {
+		t.Errorf("ACF should decay geometrically, got %v", c.ACF[:3])
+	}
+	if math.Abs(c.PACF[0]-0.6) > 0.05 || math.Abs(c.PACF[1]) > c.Band {
+		t.Errorf("PACF should cut off after lag 1, got %v", c.PACF[:2])
+	}
+	if lags := c.SignificantLags(); len(lags) == 0 || lags[0] != 1 {
+		t.Errorf("expected lag 1 to be significant, got %v", lags)
+	}
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// AdviceKind names a recommendation of Advise
+type AdviceKind string
+
+const (
+	AdviceLinear      AdviceKind = "linear"       // nothing found against the straight line
+	AdviceQuadratic   AdviceKind = "quadratic"    // the residuals curve
+	AdviceRobust      AdviceKind = "robust"       // an outlier pulls the line
+	AdviceSinglePoint AdviceKind = "single-point" // one point sets the slope
+	AdviceLackOfFit   AdviceKind = "lack-of-fit"  // the line misses the means of replicated X values
+)
+
+// Advice is one recommendation for a dataset, with the evidence behind it
+type Advice struct {
+	Kind     AdviceKind `json:"kind"`
+	Message  string     `json:"message"`
+	Evidence string     `json:"evidence"`
+
+	// evidence is the format of Evidence and its arguments, kept for Localize
+	evidence []any
+}
+
+// newAdvice returns advice whose evidence is formatted from format and args
+func newAdvice(kind AdviceKind, message, format string, args ...any) Advice {
+	return Advice{Kind: kind, Message: message, Evidence: fmt.Sprintf(format, args...), evidence: append([]any{format}, args...)}
+}
+
+// Localize returns the advice with its message and evidence in the language of m
+func (a Advice) Localize(m Messages) Advice {
+	a.Message = m.Text(a.Message)
+	if len(a.evidence) > 0 {
+		a.Evidence = m.Sprintf(a.evidence[0].(string), a.evidence[1:]...)
+	}
+	return a
+}
+
+// String returns the message followed by its evidence
+func (a Advice) String() string {
+	return fmt.Sprintf("%s (%s)", a.Message, a.Evidence)
+}
+
+// Thresholds of Advise. adviceAlpha is the significance level of its F tests.
+const (
+	adviceAlpha       = 0.01
+	adviceStudentized = 3.0
+)
+
+// leverageLimit is the leverage beyond which one of n points sets the slope: above 0.5 and three
+// times the average of 2/n, since the end points of a small evenly spread design pass 0.5 on their
+// own, but never above 0.95 so that tiny datasets can still be flagged
+func leverageLimit(n int) float64 {
+	return math.Min(math.Max(0.5, 6/float64(n)), 0.95)
+}
+
+// Advise inspects the least squares line through a dataset and recommends what to do instead,
+// automating the lesson of Anscombe's quartet:
+//   - a point with extreme leverage (see leverageLimit) sets the slope almost alone (quartet IV);
+//   - an externally studentized residual beyond 3, or robust fits that disagree with least squares
+//     (see FitEnsemble), calls for a robust fit (quartet III);
+//   - a significant quadratic term calls for a quadratic fit (quartet II);
+//   - with replicated X values, a significant lack-of-fit test says the line misses their means.
+//
+// When none applies the advice is AdviceLinear. Pairs with NaN/Inf values are ignored.
+func Advise(ds Dataset) ([]Advice, error) {
+	x, y, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return nil, err
+	}
+	n := len(x)
+	if n < 4 {
+		return nil, fmt.Errorf("need at least four valid points to judge a line, have %d", n)
+	}
+	if _, v := meanVariance(x); v == 0 {
+		return nil, fmt.Errorf("X is constant")
+	}
+	slope, intercept, _ := ManualRegression(x, y)
+	diags := PointDiagnostics(ds.X, ds.Y, slope, intercept)
+	var advice []Advice
+
+	top := slices.MaxFunc(diags, func(a, b PointDiagnostic) int { return cmp.Compare(a.Leverage, b.Leverage) })
+	if top.Leverage > leverageLimit(n) {
+		advice = append(advice, newAdvice(AdviceSinglePoint, "a single point drives this fit; collect data across the X range before trusting the slope",
+			"point %d at x = %g has leverage %.2f", top.Index+1, top.X, top.Leverage))
+	}
+
+	// externally studentized residuals leave the point out of the variance estimate, so a gross
+	// outlier cannot hide by inflating it (the internal ones are capped at √(n-2))
+	worst, worstT := -1, 0.0
+	for i, d := range diags {
+		r := d.StandardizedResidual
+		if math.IsNaN(r) {
+			continue
+		}
+		t := math.Inf(1)
+		if rest := float64(n-2) - r*r; rest > 0 {
+			t = math.Abs(r) * math.Sqrt(float64(n-3)/rest)
+		}
+		if t > worstT {
+			worst, worstT = i, t
+		}
+	}
+	ensemble, ensembleErr := FitEnsemble(x, y, 0)
+	switch {
+	case worstT > adviceStudentized:
+		advice = append(advice, newAdvice(AdviceRobust, "an outlier pulls the line; use a robust fit (Theil-Sen or Huber) or check that point",
+			"point %d at x = %g has studentized residual %.3g", diags[worst].Index+1, diags[worst].X, worstT))
+	case ensembleErr == nil && ensemble.Disagree:
+		advice = append(advice, newAdvice(AdviceRobust, "robust fits disagree with least squares; use a robust fit",
+			"slopes span %.2f standard errors", ensemble.Spread))
+	}
+
+	if p, ok := quadraticTermP(x, y, slope, intercept); ok && p < adviceAlpha {
+		advice = append(advice, newAdvice(AdviceQuadratic, "the relationship curves; use a quadratic fit (multi -degree 2)",
+			"quadratic term p = %.3g", p))
+	} else if lof, ok := lackOfFit(x, y, slope, intercept); ok && lof.PValue < adviceAlpha {
+		advice = append(advice, newAdvice(AdviceLackOfFit, "the line misses the means of the replicated X values; try a curve or a transform",
+			"lack of fit F(%d, %d) = %.3g, p = %.3g", lof.DF1, lof.DF2, lof.F, lof.PValue))
+	}
+
+	if len(advice) == 0 {
+		advice = append(advice, newAdvice(AdviceLinear, "the straight line looks adequate",
+			"no high-leverage points, outliers or curvature among %d points", n))
+	}
+	return advice, nil
+}
+
+// quadraticTermP tests the quadratic term of y = a + b·x + c·x² against the line; ok is false when
+// X has fewer than three distinct values
+func quadraticTermP(x, y []float64, slope, intercept float64) (p float64, ok bool) {
+	mean, _ := meanVariance(x)
+	md := MultiDataset{Names: []string{"x", "x^2"}, Y: y}
+	for _, v := range x {
+		// centring keeps x and x² from being nearly collinear
+		md.X = append(md.X, []float64{v - mean, (v - mean) * (v - mean)})
+	}
+	quad, err := FitMultiple(md)
+	if err != nil {
+		return 0, false
+	}
+	var sse float64
+	for i, row := range md.X {
+		r := y[i] - quad.Predict(row)
+		sse += r * r
+	}
+	return nestedFTest(residualSumSquares(x, y, slope, intercept), sse, 1, len(x)-3).PValue, true
+}
+
+// lackOfFit compares the line's residuals with the pure error among replicated X values; ok is
+// false without replicates or with fewer than three distinct X values
+func lackOfFit(x, y []float64, slope, intercept float64) (FTest, bool) {
+	groups := map[float64][]float64{}
+	for i, v := range x {
+		groups[v] = append(groups[v], y[i])
+	}
+	m := len(groups)
+	if m < 3 || m == len(x) {
+		return FTest{}, false
+	}
+	var pure float64
+	for _, ys := range groups {
+		mean, _ := meanVariance(ys)
+		for _, v := range ys {
+			pure += (v - mean) * (v - mean)
+		}
+	}
+	sse := residualSumSquares(x, y, slope, intercept)
+	return nestedFTest(sse, pure, m-2, len(x)-m), true
+}
+
+// printAdvice writes a dataset's recommendations in the language of m
+func printAdvice(w io.Writer, data Dataset, m Messages) {
+	label := m.label("Advice")
+	advice, err := Advise(data)
+	if err != nil {
+		fmt.Fprintf(w, "%s%s\n", label, m.Sprintf("n/a (%v)", err))
+		return
+	}
+	for i, a := range advice {
+		if i > 0 {
+			label = strings.Repeat(" ", 13)
+		}
+		fmt.Fprintf(w, "%s%s\n", label, a.Localize(m))
+	}
+}
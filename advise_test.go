@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func adviceKinds(advice []Advice) []AdviceKind {
+	kinds := make([]AdviceKind, len(advice))
+	for i, a := range advice {
+		kinds[i] = a.Kind
+	}
+	return kinds
+}
+
+// ✅ Test 1: The advisor learns Anscombe's lesson
+func TestAdviseAnscombe(t *testing.T) {
+	want := map[string]AdviceKind{"I": AdviceLinear, "II": AdviceQuadratic, "III": AdviceRobust, "IV": AdviceSinglePoint}
+	for name, ds := range LoadAnscombeDatasets() {
+		advice, err := Advise(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(advice) != 1 || advice[0].Kind != want[name] {
+			t.Errorf("dataset %s: got %v, want [%s]", name, adviceKinds(advice), want[name])
+		}
+	}
+	advice, _ := Advise(LoadAnscombeDatasets()["III"])
+	if !strings.Contains(advice[0].Evidence, "point 3 at x = 13") {
+		t.Errorf("evidence should name the outlier, got %q", advice[0].Evidence)
+	}
+}
+
+// ✅ Test 2: Replicated X values expose lack of fit that a quadratic would not capture
+func TestAdviseLackOfFit(t *testing.T) {
+	var ds Dataset
+	// a step: flat, then a jump, then flat again, with three replicates at each of five X values
+	for _, x := range []float64{1, 2, 3, 4, 5} {
+		level := 0.0
+		if x >= 3 {
+			level = 10
+		}
+		if x == 3 {
+			level = 5
+		}
+		for k, noise := range []float64{-0.1, 0, 0.1} {
+			ds.X = append(ds.X, x)
+			ds.Y = append(ds.Y, level+noise*float64(k+1))
+		}
+	}
+	advice, err := Advise(ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := adviceKinds(advice)
+	if len(kinds) != 1 || kinds[0] != AdviceLackOfFit {
+		t.Errorf("got %v", kinds)
+	}
+
+	lof, ok := lackOfFit(ds.X, ds.Y, 2.5, -1)
+	if !ok || lof.DF1 != 3 || lof.DF2 != 10 {
+		t.Errorf("lack of fit %+v, %v", lof, ok)
+	}
+	if _, ok := lackOfFit([]float64{1, 2, 3}, []float64{1, 2, 3}, 1, 0); ok {
+		t.Error("no replicates should skip the test")
+	}
+}
+
+// ✅ Test 3: Missing values keep their original index in the evidence; tiny datasets are refused
+func TestAdviseIndexes(t *testing.T) {
+	ds := LoadAnscombeDatasets()["III"]
+	ds.X = append([]float64{math.NaN()}, ds.X...)
+	ds.Y = append([]float64{1}, ds.Y...)
+	advice, err := Advise(ds)
+	if err != nil || !strings.Contains(advice[0].Evidence, "point 4 at x = 13") {
+		t.Errorf("got %v, %v", advice, err)
+	}
+	if _, err := Advise(Dataset{X: []float64{1, 2, 3}, Y: []float64{1, 2, 4}}); err == nil {
+		t.Error("expected an error for three points")
+	}
+}
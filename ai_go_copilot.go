@@ -1,27 +1,57 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/montanaflynn/stats"
 )
 
-// Dataset represents x and y values for regression
+// Dataset represents x and y values for regression.
+// Weights, Labels and Tags are optional; when set they hold one entry per point.
+// Weights make AnalyzeDataset fit by weighted least squares; labels and tags flow through to diagnostics.
+// XAxis and YAxis name the variables and their units for reports and plots.
 type Dataset struct {
 	X []float64
 	Y []float64
+
+	XAxis Axis
+	YAxis Axis
+
+	Weights []float64
+	Labels  []string
+	Tags    [][]string
 }
 
 // RegressionResult holds regression analysis results
 type RegressionResult struct {
-	Dataset   string
-	Slope     float64
-	Intercept float64
-	RSquared  float64
-	Duration  time.Duration
+	Dataset   string        `json:"dataset"`
+	Slope     float64       `json:"slope"`
+	Intercept float64       `json:"intercept"`
+	RSquared  float64       `json:"rSquared"`
+	Duration  time.Duration `json:"duration"`
+	// Fingerprint identifies the data the result was computed from (see Dataset.Fingerprint)
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// Alloc is only recorded when requested (see AnalyzeOptions.AllocStats)
+	Alloc *AllocStats `json:"alloc,omitempty"`
+	// Imputed lists the points whose missing values were filled in before fitting
+	Imputed []int `json:"imputed,omitempty"`
+	// Engine names the implementation that produced the fit, and Fallback why it did not use the
+	// library's results when it fell back to the manual calculation
+	Engine   string `json:"engine,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
+	// Cached reports that the result was reused from the result cache, so Duration is the time
+	// taken by the run that fitted it rather than by this one
+	Cached bool `json:"cached,omitempty"`
 }
 
 // LoadAnscombeDatasets returns the four Anscombe Quartet datasets
@@ -49,14 +79,30 @@ func LoadAnscombeDatasets() map[string]Dataset {
 // Copilot: improve the PerformLinearRegression function to handle NaN and Inf values and return the ManualRegression function calculation as a fallback if montanaflynn/stats fails.
 // Note: This function may return results from either the montanaflynn/stats package or the manual fallback implementation.
 // This can affect reproducibility and debugging, as results may differ slightly depending on which method is used.
+// It prints nothing: AnalyzeDataset records why it fell back in RegressionResult.Fallback.
 
 func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64, err error) {
+	slope, intercept, rSquared, _, err = performLinearRegression(x, y)
+	return slope, intercept, rSquared, err
+}
+
+// performLinearRegression is PerformLinearRegression also reporting why it fell back to the
+// manual calculation, or "" when the library's results were used. Its scratch slices come from
+// scratchPool, so repeated fits, as in server mode, do not allocate them each time.
+func performLinearRegression(x, y []float64) (slope, intercept, rSquared float64, fallback string, err error) {
+	s := getScratch()
+	defer putScratch(s)
+	return performLinearRegressionScratch(x, y, s)
+}
+
+// performLinearRegressionScratch is performLinearRegression cleaning the pairs into s
+func performLinearRegressionScratch(x, y []float64, s *regressionScratch) (slope, intercept, rSquared float64, fallback string, err error) {
 	// Basic validation
 	if len(x) != len(y) {
-		return 0, 0, 0, fmt.Errorf("x and y length mismatch: %d vs %d", len(x), len(y))
+		return 0, 0, 0, "", fmt.Errorf("x and y length mismatch: %d vs %d", len(x), len(y))
 	}
 	if len(x) < 2 {
-		return 0, 0, 0, fmt.Errorf("need at least two data points")
+		return 0, 0, 0, "", fmt.Errorf("need at least two data points")
 	}
 
 	// Helper function to check for NaN or Inf
@@ -65,9 +111,8 @@ func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64
 	}
 
 	// Clean NaN/Inf values
-	cleanX := make([]float64, 0, len(x))
-	cleanY := make([]float64, 0, len(y))
-	coords := make([]stats.Coordinate, 0, len(x))
+	cleanX, cleanY, coords := s.x[:0], s.y[:0], s.coords[:0]
+	defer func() { s.x, s.y, s.coords = cleanX, cleanY, coords }()
 	for i := range x {
 		xi, yi := x[i], y[i]
 		if isInvalid(xi) || isInvalid(yi) {
@@ -80,7 +125,7 @@ func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64
 	}
 
 	if len(cleanX) < 2 {
-		return 0, 0, 0, fmt.Errorf("not enough valid points after removing NaN/Inf (have %d)", len(cleanX))
+		return 0, 0, 0, "", fmt.Errorf("not enough valid points after removing NaN/Inf (have %d)", len(cleanX))
 	}
 
 	// Try using the library's linear regression first
@@ -88,8 +133,7 @@ func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64
 	if lrErr != nil || len(regressionLine) < 2 {
 		// Fallback: use manual least-squares calculation
 		slope, intercept, rSquared = ManualRegression(cleanX, cleanY)
-		fmt.Printf("\nWarning: falling back to manual regression due to error: %v", lrErr)
-		return slope, intercept, rSquared, nil
+		return slope, intercept, rSquared, fmt.Sprintf("library error: %v", lrErr), nil
 	}
 
 	// Compute slope and intercept from regressionLine endpoints
@@ -100,15 +144,13 @@ func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64
 	if isInvalid(first.X) || isInvalid(first.Y) || isInvalid(last.X) || isInvalid(last.Y) {
 		// fallback to manual method if regression endpoints are invalid
 		slope, intercept, rSquared = ManualRegression(cleanX, cleanY)
-		fmt.Printf("\nWarning: falling back to manual regression due to invalid regression line endpoints")
-		return slope, intercept, rSquared, nil
+		return slope, intercept, rSquared, "invalid regression line endpoints", nil
 	}
 
 	// Protect against division by zero if Xs are identical
 	if math.Abs(last.X-first.X) < 1e-12 {
 		slope, intercept, rSquared = ManualRegression(cleanX, cleanY)
-		fmt.Printf("\nWarning: falling back to manual regression due to vertical line (identical X values)")
-		return slope, intercept, rSquared, nil
+		return slope, intercept, rSquared, "identical X values", nil
 	}
 
 	// Use library line
@@ -119,28 +161,39 @@ func PerformLinearRegression(x, y []float64) (slope, intercept, rSquared float64
 	corr, corrErr := stats.Correlation(cleanX, cleanY)
 	if corrErr != nil || math.IsNaN(corr) {
 		_, _, rSquared = ManualRegression(cleanX, cleanY)
-		fmt.Printf("\nWarning: falling back to manual R² calculation due to error: %v", corrErr)
+		fallback = fmt.Sprintf("R-squared only, correlation error: %v", corrErr)
 	} else {
 		rSquared = corr * corr
 	}
 
-	return slope, intercept, rSquared, nil
+	return slope, intercept, rSquared, fallback, nil
 }
 
 // ManualRegression alternative implementation using basic formulas
 // Ensuring match R/Python results exactly if needed
 func ManualRegression(x, y []float64) (slope, intercept, rSquared float64) {
-	n := float64(len(x))
+	return ManualRegressionWithOptions(x, y, ManualOptions{})
+}
 
-	var sumX, sumY, sumXY, sumXX, sumYY float64
-	for i := range x {
-		sumX += x[i]
-		sumY += y[i]
-		sumXY += x[i] * y[i]
-		sumXX += x[i] * x[i]
-		sumYY += y[i] * y[i]
+// ManualOptions selects numerical variants of the manual engine
+type ManualOptions struct {
+	// Compensated accumulates every sum with Neumaier compensation and exact product error terms
+	// (see compensated.go), for large or badly scaled datasets where plain sums lose digits.
+	Compensated bool
+}
+
+// ManualRegressionWithOptions is ManualRegression with a selectable summation mode
+func ManualRegressionWithOptions(x, y []float64, opts ManualOptions) (slope, intercept, rSquared float64) {
+	if opts.Compensated {
+		return compensatedRegression(x, y)
 	}
 
+	n := float64(len(x))
+
+	// sums come from the chunked kernels in kernels_*.go
+	m := regressionSums(x, y)
+	sumX, sumY, sumXY, sumXX := m.sumX, m.sumY, m.sumXY, m.sumXX
+
 	// Least squares formulas
 	den := n*sumXX - sumX*sumX
 	if den == 0 {
@@ -152,82 +205,716 @@ func ManualRegression(x, y []float64) (slope, intercept, rSquared float64) {
 		intercept = (sumY - slope*sumX) / n
 	}
 
-	// Calculate R-squared manually
-	ssTotal := sumYY - (sumY*sumY)/n
-	ssResidual := 0.0
-	for i := range x {
-		predicted := intercept + slope*x[i]
-		residual := y[i] - predicted
-		ssResidual += residual * residual
-	}
+	// Calculate R-squared manually. The total sum of squares is taken about the mean in a second
+	// pass: sumYY - sumY²/n cancels catastrophically when Y varies little relative to its size,
+	// and could then fall below the residual sum and push R² negative.
+	ssTotal := residualSumSquares(x, y, 0, sumY/n)
+	ssResidual := residualSumSquares(x, y, slope, intercept)
+
+	// the least-squares line never fits worse than the mean, but a slope rounded in the raw sums
+	// can on nearly constant data; report no explained variation rather than a negative R²
+	return slope, intercept, math.Max(rSquaredFrom(ssTotal, ssResidual), 0)
+}
 
+// rSquaredFrom turns total and residual sums of squares into R²
+func rSquaredFrom(ssTotal, ssResidual float64) float64 {
 	if ssTotal > 0 {
-		rSquared = 1 - (ssResidual / ssTotal)
+		return 1 - (ssResidual / ssTotal)
+	}
+	// If total variance is zero, define R² as 1 when residual is zero, else 0
+	if ssResidual == 0 {
+		return 1
+	}
+	return 0
+}
+
+// analysisEngine names the engine AnalyzeDataset fits with; it is part of every cache key
+const analysisEngine = "stats"
+
+// cacheFormat is part of every cache key and changes when cached results gain fields, so results
+// saved by older builds are refitted instead of reused incomplete (v2 added Engine and Fallback;
+// v3 drops results of weighted fits that older builds keyed without their weights)
+const cacheFormat = "v3"
+
+// AnalyzeDataset fits a single named dataset and records how long the fit took
+func AnalyzeDataset(name string, data Dataset) (RegressionResult, error) {
+	return AnalyzeDatasetWithOptions(name, data, AnalyzeOptions{})
+}
+
+// AnalyzeDatasetWithOptions is AnalyzeDataset with optional allocation accounting
+func AnalyzeDatasetWithOptions(name string, data Dataset, opts AnalyzeOptions) (RegressionResult, error) {
+	var meter allocMeter
+	if opts.AllocStats {
+		meter.start()
+	}
+	start := time.Now()
+
+	if err := data.validateMetadata(); err != nil {
+		return RegressionResult{}, err
+	}
+	engine := analysisEngine
+	var slope, intercept, rSquared float64
+	var fallback string
+	var err error
+	if data.Weights != nil {
+		engine = "weighted"
+		slope, intercept, rSquared, err = WeightedRegression(data.X, data.Y, data.Weights)
 	} else {
-		// If total variance is zero, define R² as 1 when residual is zero, else 0
-		if ssResidual == 0 {
-			rSquared = 1
-		} else {
-			rSquared = 0
-		}
+		slope, intercept, rSquared, fallback, err = performLinearRegression(data.X, data.Y)
+	}
+	if err != nil {
+		return RegressionResult{}, err
 	}
 
-	return slope, intercept, rSquared
+	result := RegressionResult{
+		Dataset:   name,
+		Slope:     slope,
+		Intercept: intercept,
+		RSquared:  rSquared,
+		Duration:  time.Since(start),
+		Engine:    engine,
+		Fallback:  fallback,
+	}
+	if opts.AllocStats {
+		result.Alloc = meter.stop()
+	}
+	// hashed after timing so the fingerprint does not count towards Duration or Alloc
+	result.Fingerprint = data.Fingerprint()
+	return result, nil
 }
 
 func main() {
-	fmt.Println("=== Anscombe Quartet Regression Analysis ===")
-	fmt.Println("Loading datasets and performing linear regression...")
+	// When started by the AWS Lambda custom runtime, serve invocations instead of printing the report
+	if os.Getenv("AWS_LAMBDA_RUNTIME_API") != "" {
+		if err := RunLambdaRuntime(os.Getenv("AWS_LAMBDA_RUNTIME_API"), LambdaHandler); err != nil {
+			log.Fatalf("Lambda runtime stopped: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 {
+		subcommands := map[string]func([]string) error{
+			"serve":    runServe,
+			"mqtt":     runMQTT,
+			"bench":    runBench,
+			"diff":     runDiff,
+			"multi":    runMulti,
+			"pca":      runPCA,
+			"explain":  runExplain,
+			"onnx":     runONNX,
+			"pmml":     runPMML,
+			"models":   runModels,
+			"channels": runChannels,
+			"history":  runHistory,
+		}
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				log.Fatalf("%s: %v", os.Args[1], err)
+			}
+			return
+		}
+	}
+
+	if err := runAnalysis(os.Args[1:]); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printOutlierComparison refits data without outliers and prints the change against the full fit
+func printOutlierComparison(data Dataset, full RegressionResult, method OutlierMethod, threshold float64) {
+	cleaned, removed, err := data.FilterOutliers(method, threshold)
+	if err != nil {
+		fmt.Printf("  Outliers:  %v\n", err)
+		return
+	}
+	if len(removed) == 0 {
+		fmt.Printf("  Outliers:  none (%s)\n", method)
+		return
+	}
+
+	fmt.Printf("  Outliers:  %d removed (%s), indexes %v\n", len(removed), method, removed)
+	if data.Labels != nil {
+		labels := make([]string, len(removed))
+		for i, idx := range removed {
+			labels[i] = data.Label(idx)
+		}
+		fmt.Printf("  Labels:    %v\n", labels)
+	}
+	result, err := AnalyzeDataset(full.Dataset, cleaned)
+	if err != nil {
+		fmt.Printf("  Without outliers: %v\n", err)
+		return
+	}
+	fmt.Printf("  Without outliers:\n")
+	fmt.Printf("    Slope:     %.6f (%+.6f)\n", result.Slope, result.Slope-full.Slope)
+	fmt.Printf("    Intercept: %.6f (%+.6f)\n", result.Intercept, result.Intercept-full.Intercept)
+	fmt.Printf("    R-squared: %.6f (%+.6f)\n", result.RSquared, result.RSquared-full.RSquared)
+}
+
+// runAnalysis is the default command: fit every dataset and print the report
+func runAnalysis(args []string) error {
+	flags := flag.NewFlagSet("regression", flag.ContinueOnError)
+	thresholds := DefaultAlertThresholds()
+	alertWebhook := flags.String("alert-webhook", "", "webhook (or Slack incoming webhook) URL notified when a fit breaches the alert thresholds")
+	flags.Float64Var(&thresholds.MinRSquared, "alert-min-r2", thresholds.MinRSquared, "alert when R-squared drops below this value")
+	flags.Float64Var(&thresholds.SlopeMin, "alert-slope-min", thresholds.SlopeMin, "alert when the slope falls below this value")
+	flags.Float64Var(&thresholds.SlopeMax, "alert-slope-max", thresholds.SlopeMax, "alert when the slope rises above this value")
+	workers := flags.Int("workers", runtime.NumCPU(), "number of datasets analyzed concurrently")
+	noCache := flags.Bool("no-cache", false, "always refit instead of reusing results of unchanged datasets")
+	cacheSize := flags.Int("cache-size", defaultCacheSize, "maximum number of fitted results kept in the cache")
+	var removeOutliers OutlierMethod
+	flags.Func("remove-outliers", "also fit each dataset without outliers and compare (iqr, zscore or mad)", func(method string) error {
+		var err error
+		removeOutliers, err = ParseOutlierMethod(method)
+		return err
+	})
+	outlierThreshold := flags.Float64("outlier-threshold", 0, "cut-off for -remove-outliers (0 uses the method's default)")
+	impute := flags.String("impute", "", "fill in missing values instead of dropping the pair (mean, median or interpolate)")
+	merge := flags.Bool("merge", false, "combine all CSV files, in argument order, into one dataset before fitting")
+	dedupTol := flags.Float64("dedup", -1, "with -merge, drop points within this tolerance of an earlier point (0 for exact duplicates, negative disables)")
+	checkUnits := flags.Bool("check-units", false, "with -merge, refuse to combine files whose header units differ")
+	lang := flags.String("lang", "en", "language of the report text and advice ("+languageNames()+")")
+	localeName := flags.String("locale", "", "read CSV files and write fitted values in this number format ("+localeNames()+"); de, fr and ch files separate fields with ';'")
+	spillDir := flags.String("spill", "", "fit each CSV file (- for standard input) in one streaming pass, spilling per-chunk moments to a temporary file in this directory, for inputs larger than memory; prints only the coefficients")
+	spillChunk := flags.Int("spill-chunk", defaultSpillChunk, "with -spill, points per spilled chunk")
+	follow := flags.Bool("follow", false, "follow a single CSV file as another process appends to it, like tail -f, adding each new row to an online fit and printing the updated coefficients until interrupted")
+	followInterval := flags.Duration("follow-interval", defaultFollowInterval, "with -follow, how often to check the file for new rows")
+	groupBy := flags.String("group-by", "", "split each CSV file into one dataset per value of this column (header name or 1-based index)")
+	timeX := flags.Bool("time", false, "treat the first CSV column as timestamps and report trends per day and hour")
+	tz := flags.String("tz", "UTC", "time zone for timestamps without an offset, with -time (IANA name such as Europe/Paris, or Local)")
+	var timeLayouts []string
+	flags.Func("time-layout", "with -time, a layout the timestamps are written in, such as \"02.01.2006 15:04\" (Go reference time), unix or unixms; repeatable, tried in order instead of the ISO-like defaults", func(layout string) error {
+		timeLayouts = append(timeLayouts, layout)
+		return nil
+	})
+	slopeUnit := flags.String("slope-unit", "auto", "with -time, report slopes per this unit ("+timeUnitNames()+"), or auto to pick one from each series' span")
+	period := flags.Int("period", 0, "with -time, observations per seasonal cycle; adds a seasonal-trend decomposition to the report")
+	fitTrend := flags.Bool("fit-trend", false, "with -period, fit the line to the decomposition's trend instead of the raw values")
+	var rules []Rule
+	flags.Func("rule", "validation rule every dataset must pass before fitting; repeatable (x-increasing, no-missing, min-n=N, x-range=LO:HI, y-range=LO:HI)", func(spec string) error {
+		rule, err := ParseRule(spec)
+		if err == nil {
+			rules = append(rules, rule)
+		}
+		return err
+	})
+	var hooks ScriptHooks
+	flags.Func("derive", "rewrite a column of every point before fitting, as in y=log(y) (columns x, y, w; variables x, y, w, i, n); repeatable", hooks.AddDerive)
+	flags.Func("filter", "keep only the points for which this expression holds, as in \"x > 0 && y < 100\"; repeatable", hooks.AddFilter)
+	flags.Func("check", "fail the run unless this expression of slope, intercept, r2 and n holds for every dataset, as in \"r2 > 0.6\"; repeatable", hooks.AddCheck)
+	scriptPath := flags.String("script", "", "read derive, filter and check hooks from this file, one per line (applied before those given as flags)")
+	smooth := flags.String("smooth", "", "smooth Y along X before fitting (sma:WINDOW, ema:ALPHA or holt:ALPHA,BETA)")
+	clusters := flags.String("clusters", "", "look for subpopulations with k-means in xy or residual space and fit a line to each")
+	changePoints := flags.Bool("changepoints", false, "look for X values where the slope shifts and report a fit per segment")
+	lossSpec := flags.String("loss", "", "also fit each dataset by gradient descent under this loss (l2, l1 or huber:DELTA) and report it next to the least squares line")
+	seEstimator := flags.String("se", "", "also report each dataset's coefficient standard errors by this estimator (classical, hc0-hc3 for heteroscedasticity-robust ones, or hac for Newey-West ones robust to autocorrelation in data order) with Breusch-Pagan and Durbin-Watson statistics")
+	hacLag := flags.Int("hac-lag", -1, "with -se hac, the number of neighbouring residuals allowed to be correlated (-1 picks 4·(n/100)^(2/9))")
+	reweight := flags.String("reweight", "", "also fit each dataset by iteratively reweighted least squares, estimating the variance as exp (of X) or power (of the fitted value), and report its standard errors")
+	var measurement MeasurementError
+	flags.Float64Var(&measurement.SDX, "x-error", 0, "standard deviation of the measurement error in X; also fits each dataset correcting the slope for it (errors-in-variables)")
+	flags.Float64Var(&measurement.SDY, "y-error", 0, "with -x-error, standard deviation of the measurement error in Y (0 puts all the scatter beyond X's error on Y)")
+	ensemble := flags.Bool("ensemble", false, "also fit each dataset by least squares, Theil-Sen and Huber regression, and flag datasets where the methods disagree")
+	ensembleThreshold := flags.Float64("ensemble-threshold", DefaultEnsembleThreshold, "with -ensemble, slope spread in standard errors beyond which the methods disagree")
+	influence := flags.Int("influence", 0, "list this many points per dataset, ranked by how far removing each would move the slope, with their leverage and residuals")
+	advise := flags.Bool("advise", false, "recommend a quadratic or robust fit, or more data, for each dataset whose diagnostics argue against the straight line")
+	traceMath := flags.Bool("trace-math", false, "print the intermediate sums, means and sums of squares of each fit, for checking it by hand")
+	checkEngines := flags.Float64("check-engines", 0, "refit each dataset with every registered engine and fail when any two differ by more than this relative tolerance (0 disables)")
+	ancova := flags.Bool("ancova", false, "test whether the datasets' slopes differ (ANCOVA) and fit parallel lines with a shared slope")
+	diagnostics := flags.Bool("diagnostics", false, "add goodness-of-fit tests of the residuals and Y values to each dataset's report")
+	referencePath := flags.String("reference", "", "compare fits with reference results from a JSON or CSV file (such as one written by the R or Python script) and fail when any differ")
+	goldenPath := flags.String("golden", "", "compare the run's JSON record with this golden file and fail on any difference")
+	updateGolden := flags.Bool("update-golden", false, "with -golden, record this run as the golden file instead of comparing")
+	goldenTol := flags.Float64("golden-tol", DefaultGoldenTolerance().Rel, "with -golden, relative tolerance for numbers")
+	manifestPath := flags.String("manifest", "", "also write the run's reproducibility manifest (build, options, seeds, fingerprints) as JSON to this file")
+	historyPath := flags.String("history", "", "record the run (options, coefficients and residual diagnostics of every dataset) in this SQLite history database (see the history subcommand)")
+	registryDir := flags.String("registry", "", "register each dataset's fitted line as a new version in this model registry (see the models subcommand)")
+	modelsDir := flags.String("save-models", "", "save each dataset's fitted line as a JSON model in this directory, for prediction with serve -model")
+	htmlPath := flags.String("html", "", "also write an HTML report with scatter and distribution plots to this file")
+	var outputs []OutputTarget
+	flags.Func("output", "also deliver a report of the run, as FORMAT=DEST: json (the run record), html or manifest, to - (standard output), a file, an http(s) URL to POST to, s3://bucket/key (credentials from the AWS_* environment) or, for json, history:DB; repeatable", func(spec string) error {
+		t, err := ParseOutputTarget(spec)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, t)
+		return nil
+	})
+	var htmlOpts HTMLReportOptions
+	flags.Func("hist-bins", "histogram bin rule for -html (fd or sturges; default fd)", func(rule string) error {
+		if rule != string(BinRuleFreedmanDiaconis) && rule != string(BinRuleSturges) {
+			return fmt.Errorf("unknown bin rule %q", rule)
+		}
+		htmlOpts.Bins = BinRule(rule)
+		return nil
+	})
+	flags.Func("kde-bandwidth", "density estimate bandwidth rule for -html (silverman or scott; default silverman)", func(rule string) error {
+		if rule != string(BandwidthSilverman) && rule != string(BandwidthScott) {
+			return fmt.Errorf("unknown bandwidth rule %q", rule)
+		}
+		htmlOpts.Bandwidth = BandwidthRule(rule)
+		return nil
+	})
+	flags.Func("intervals", "how -html computes the bands around each fitted line: t, or bootstrap to resample the residuals, for small samples with non-normal errors (default t)", func(method string) error {
+		var err error
+		htmlOpts.Intervals, err = ParseIntervalMethod(method)
+		return err
+	})
+	builtin := flags.String("builtin", "anscombe", "built-in dataset collection to analyze when no files are given ("+builtinNames()+")")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s [flags] [file.csv ...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var alerts *AlertHook
+	if *alertWebhook != "" {
+		alerts = NewAlertHook(thresholds, NewWebhookNotifier(*alertWebhook))
+	}
+
+	var jobs []AnalysisJob
+	var title string
+	if *timeX {
+		if flags.NArg() == 0 {
+			return fmt.Errorf("-time needs CSV files")
+		}
+		if len(rules) > 0 {
+			return fmt.Errorf("-rule cannot be combined with -time")
+		}
+		if !hooks.Empty() || *scriptPath != "" {
+			return fmt.Errorf("-derive, -filter, -check and -script cannot be combined with -time")
+		}
+		numbers, err := ParseNumberLocale(*localeName)
+		if err != nil {
+			return err
+		}
+		messages, err := ParseLanguage(*lang)
+		if err != nil {
+			return err
+		}
+		loc, err := time.LoadLocation(*tz)
+		if err != nil {
+			return fmt.Errorf("invalid -tz: %w", err)
+		}
+		if *fitTrend && *period <= 0 {
+			return fmt.Errorf("-fit-trend requires -period")
+		}
+		unit, err := ParseTimeUnit(*slopeUnit)
+		if err != nil {
+			return err
+		}
+		return printTimeSeriesReport(flags.Args(), timeSeriesReportOptions{Format: TimeFormat{Location: loc, Layouts: timeLayouts}, Locale: numbers, Messages: messages, Unit: unit, Period: *period, FitTrend: *fitTrend})
+	}
+	if *period != 0 || *fitTrend || len(timeLayouts) > 0 || *slopeUnit != "auto" {
+		return fmt.Errorf("-period, -fit-trend, -time-layout and -slope-unit require -time")
+	}
+
+	smoothing, err := ParseSmoothOptions(*smooth)
+	if err != nil {
+		return err
+	}
+	script := &hooks
+	if *scriptPath != "" {
+		if script, err = LoadScriptFile(*scriptPath); err != nil {
+			return fmt.Errorf("loading script: %w", err)
+		}
+		script.Merge(&hooks)
+	}
+	var loss Loss
+	if *lossSpec != "" {
+		if loss, err = ParseLoss(*lossSpec); err != nil {
+			return err
+		}
+	}
+	if measurement.SDX < 0 || measurement.SDY < 0 || (measurement.SDY > 0 && measurement.SDX == 0) {
+		return fmt.Errorf("-x-error must be positive, and -y-error non-negative and used with -x-error")
+	}
+	if *reweight != "" && *reweight != string(VarianceExponential) && *reweight != string(VariancePower) {
+		return fmt.Errorf("unknown -reweight variance function %q (use exp or power)", *reweight)
+	}
+	var covariance CovarianceEstimator
+	if *seEstimator != "" {
+		if covariance, err = ParseCovarianceEstimator(*seEstimator); err != nil {
+			return err
+		}
+	}
+	if *hacLag >= 0 && covariance != CovarianceHAC {
+		return fmt.Errorf("-hac-lag needs -se hac")
+	}
+	if *clusters != "" && *clusters != string(ClusterXY) && *clusters != string(ClusterResidual) {
+		return fmt.Errorf("unknown -clusters space %q (use xy or residual)", *clusters)
+	}
+	var reference *Reference
+	if *referencePath != "" {
+		ref, err := LoadReference(*referencePath)
+		if err != nil {
+			return fmt.Errorf("loading reference: %w", err)
+		}
+		reference = &ref
+	} else if collection, ok := LookupBuiltin(*builtin); ok && collection.Reference != nil && flags.NArg() == 0 &&
+		smoothing.Method == SmoothNone && *impute == "" && len(script.Derive)+len(script.Filters) == 0 {
+		// the reference is for the collection as published, not for changed data
+		ref := collection.Reference()
+		reference = &ref
+	}
+	if (*updateGolden || *goldenTol != DefaultGoldenTolerance().Rel) && *goldenPath == "" {
+		return fmt.Errorf("-update-golden and -golden-tol require -golden")
+	}
+	if *goldenTol < 0 {
+		return fmt.Errorf("-golden-tol must not be negative")
+	}
+	if *dedupTol >= 0 && !*merge {
+		return fmt.Errorf("-dedup requires -merge")
+	}
+	if *checkUnits && !*merge {
+		return fmt.Errorf("-check-units requires -merge")
+	}
+	numbers, err := ParseNumberLocale(*localeName)
+	if err != nil {
+		return err
+	}
+	messages, err := ParseLanguage(*lang)
+	if err != nil {
+		return err
+	}
+	if *follow {
+		if flags.NArg() != 1 || flags.Arg(0) == "-" || *merge || *groupBy != "" || *impute != "" || smoothing.Method != SmoothNone || len(rules) > 0 || !script.Empty() || *spillDir != "" {
+			return fmt.Errorf("-follow needs a single CSV file and cannot be combined with -merge, -group-by, -impute, -smooth, -rule, -spill or script hooks")
+		}
+		if *followInterval <= 0 {
+			return fmt.Errorf("-follow-interval must be positive")
+		}
+		return followFile(flags.Arg(0), *followInterval, numbers)
+	}
+	if *spillDir != "" {
+		if flags.NArg() == 0 || *merge || *groupBy != "" || *impute != "" || smoothing.Method != SmoothNone || len(rules) > 0 || !script.Empty() {
+			return fmt.Errorf("-spill needs CSV files and cannot be combined with -merge, -group-by, -impute, -smooth, -rule or script hooks")
+		}
+		if *spillChunk < 1 {
+			return fmt.Errorf("-spill-chunk must be positive")
+		}
+		return printSpilledFits(flags.Args(), SpillOptions{Dir: *spillDir, Chunk: *spillChunk}, numbers)
+	}
+	if *groupBy != "" && (*merge || flags.NArg() == 0) {
+		return fmt.Errorf("-group-by needs CSV files and cannot be combined with -merge")
+	}
+	if flags.NArg() > 0 && *groupBy != "" {
+		grouped, err := groupJobs(flags.Args(), *groupBy, numbers)
+		if err != nil {
+			return err
+		}
+		jobs = grouped
+		title = "Grouped"
+	} else if flags.NArg() > 0 && *merge {
+		data, err := mergeFiles(flags.Args(), *dedupTol, MergeOptions{CheckUnits: *checkUnits}, numbers)
+		if err != nil {
+			return err
+		}
+		jobs = []AnalysisJob{{Name: "merged", Data: data}}
+		title = "Merged"
+	} else if flags.NArg() > 0 {
+		// batch mode: every positional argument is a CSV file
+		jobs = fileJobs(flags.Args())
+		title = "Batch"
+	} else {
+		collection, ok := LookupBuiltin(*builtin)
+		if !ok {
+			return fmt.Errorf("unknown built-in collection %q (available: %s)", *builtin, builtinNames())
+		}
+		datasets, err := collection.Load()
+		if err != nil {
+			return err
+		}
+		jobs = datasetJobs(datasets)
+		title = collection.Title
+	}
+	for i := range jobs {
+		jobs[i].Impute = ImputeStrategy(*impute)
+		jobs[i].Smooth = smoothing
+		jobs[i].Rules = rules
+		jobs[i].Script = script
+		jobs[i].Locale = numbers
+	}
 
-	datasets := LoadAnscombeDatasets()
-	results := make([]RegressionResult, 0, 4)
+	fmt.Printf("=== %s ===\n", messages.Sprintf("%s Regression Analysis", messages.Text(title)))
+	fmt.Println(messages.Text("Loading datasets and performing linear regression..."))
 
 	// Perform regression on all datasets
 	overallStart := time.Now()
 
-	for name, data := range datasets {
-		start := time.Now()
+	var cache *ResultCache
+	cachePath, err := defaultCachePath()
+	if !*noCache && err == nil {
+		cache = NewResultCache(*cacheSize)
+		if err := cache.Load(cachePath); err != nil {
+			log.Printf("Ignoring unreadable result cache %s: %v", cachePath, err)
+		}
+	}
 
-		slope, intercept, rSquared, err := PerformLinearRegression(data.X, data.Y)
-		if err != nil {
-			log.Printf("Regression failed for dataset %s: %v", name, err)
+	outcomes := AnalyzeAllWithCache(jobs, *workers, cache)
+	if cache != nil {
+		if err := cache.Save(cachePath); err != nil {
+			log.Printf("Could not save result cache: %v", err)
+		}
+	}
+	// -manifest, -history and -html are shorthands for outputs, delivered before those of -output
+	var shorthands []OutputTarget
+	if *manifestPath != "" {
+		shorthands = append(shorthands, OutputTarget{Format: OutputManifest, Sink: FileSink{Path: *manifestPath}})
+	}
+	if *historyPath != "" {
+		shorthands = append(shorthands, OutputTarget{Format: OutputJSON, Sink: &HistorySink{Path: *historyPath}})
+	}
+	if *htmlPath != "" {
+		shorthands = append(shorthands, OutputTarget{Format: OutputHTML, Sink: FileSink{Path: *htmlPath}})
+	}
+	outputs = append(shorthands, outputs...)
+	wantsHTML := slices.ContainsFunc(outputs, func(t OutputTarget) bool { return t.Format == OutputHTML })
+
+	results := make([]RegressionResult, 0, len(outcomes))
+	manifest := NewManifest(analysisEngine)
+	manifest.SetOptions(flags)
+	if *clusters != "" {
+		manifest.SetSeed("clusters", clusterSeed)
+	}
+	if htmlOpts.Intervals == IntervalBootstrap {
+		manifest.SetSeed("intervals", intervalSeed)
+	}
+	var reportDatasets []HTMLReportDataset
+	var inconsistent, failedChecks []string
+	cached := 0
+	var registry *ModelRegistry
+	if *registryDir != "" {
+		if registry, err = OpenModelRegistry(*registryDir); err != nil {
+			return fmt.Errorf("opening model registry: %w", err)
+		}
+	}
+	if *modelsDir != "" {
+		if err := os.MkdirAll(*modelsDir, 0o755); err != nil {
+			return fmt.Errorf("creating model directory: %w", err)
+		}
+	}
+
+	for _, outcome := range outcomes {
+		name, result := outcome.Name, outcome.Result
+		if outcome.Err != nil {
+			log.Print(messages.Sprintf("Regression failed for dataset %s: %v", name, outcome.Err))
 			continue
 		}
 
-		elapsed := time.Since(start)
+		results = append(results, result)
+		manifest.AddResult(result)
+		if *modelsDir != "" || registry != nil {
+			model := NewLinearModel(result, outcome.Data)
+			model.Options = manifest.Options
+			if *modelsDir != "" {
+				if err := SaveModelFile(filepath.Join(*modelsDir, modelFileName(name)), model); err != nil {
+					return fmt.Errorf("saving model of dataset %s: %w", name, err)
+				}
+			}
+			if registry != nil {
+				version, err := registry.Save(model)
+				if err != nil {
+					return fmt.Errorf("registering model of dataset %s: %w", name, err)
+				}
+				fmt.Printf("  Model:     %s v%d in %s\n", name, version, *registryDir)
+			}
+		}
+		if wantsHTML {
+			reportDatasets = append(reportDatasets, HTMLReportDataset{Name: name, Data: outcome.Data, Result: result})
+		}
 
-		results = append(results, RegressionResult{
-			Dataset:   name,
-			Slope:     slope,
-			Intercept: intercept,
-			RSquared:  rSquared,
-			Duration:  elapsed,
-		})
+		if alerts != nil {
+			if err := alerts.Evaluate(context.Background(), name, outcome.Data, result); err != nil {
+				log.Printf("Alert delivery failed for dataset %s: %v", name, err)
+			}
+		}
 
-		fmt.Printf("\nDataset %s:\n", name)
-		fmt.Printf("  Slope:     %.6f\n", slope)
-		fmt.Printf("  Intercept: %.6f\n", intercept)
-		fmt.Printf("  R-squared: %.6f\n", rSquared)
-		fmt.Printf("  Time:      %v\n", elapsed)
+		fmt.Printf("\n%s\n", messages.Sprintf("Dataset %s:", name))
+		if axes := outcome.Data.AxesLabel(); axes != "" {
+			fmt.Printf("%s%s\n", messages.label("Axes"), axes)
+		}
+		fmt.Printf("%s%s\n", messages.label("Slope"), numbers.withUnit(result.Slope, outcome.Data.SlopeUnit()))
+		fmt.Printf("%s%s\n", messages.label("Intercept"), numbers.withUnit(result.Intercept, outcome.Data.YAxis.Unit))
+		fmt.Printf("%s%s\n", messages.label("R-squared"), numbers.FormatFloat(result.RSquared, 6))
+		fmt.Printf("%s%s\n", messages.label("Data"), result.Fingerprint)
+		if outcome.Cached {
+			cached++
+			fmt.Printf("%s%s\n", messages.label("Time"), messages.Sprintf("%v (cached)", result.Duration))
+		} else {
+			fmt.Printf("%s%v\n", messages.label("Time"), result.Duration)
+		}
+		if result.Fallback != "" {
+			fmt.Printf("%s%s\n", messages.label("Warning"), messages.Sprintf("the stats engine failed (%s), so the line was fitted by the manual calculation", result.Fallback))
+		}
+		if c, err := LineConditioning(outcome.Data); err == nil {
+			normal := engineSolvesNormalEquations(result.Engine)
+			fmt.Printf("%s%s\n", messages.label("Conditioning"), formatConditioning(c, normal, messages))
+			if c.Unreliable(normal) {
+				fmt.Printf("%s%s\n", messages.label("Warning"), messages.Sprintf("the fit may be numerically unreliable; %s", c.advice(messages)))
+			}
+		}
+
+		if len(result.Imputed) > 0 {
+			fmt.Printf("  Imputed:   %d points (%s), indexes %v\n", len(result.Imputed), *impute, result.Imputed)
+		}
+		if smoothing.Method != SmoothNone {
+			fmt.Printf("  Smoothed:  %s\n", smoothing)
+		}
+
+		if *traceMath {
+			printTrace(os.Stdout, outcome.Data, result)
+		}
+		if *diagnostics {
+			printDistributionDiagnostics(os.Stdout, outcome.Data, result)
+		}
+		if *changePoints {
+			printChangePoints(os.Stdout, outcome.Data, ChangePointOptions{})
+		}
+		if *clusters != "" {
+			printClusters(os.Stdout, outcome.Data, ClusterSpace(*clusters))
+		}
+		if *lossSpec != "" {
+			printGradientFit(os.Stdout, outcome.Data, loss)
+		}
+		if covariance != "" {
+			printStandardErrors(os.Stdout, outcome.Data, result, covariance, *hacLag)
+		}
+		if *reweight != "" {
+			printIRWLS(os.Stdout, outcome.Data, VarianceFunction(*reweight))
+		}
+		if measurement.SDX > 0 {
+			printEIV(os.Stdout, outcome.Data, measurement)
+		}
+		if *ensemble {
+			printEnsemble(os.Stdout, outcome.Data, *ensembleThreshold)
+		}
+		if *advise {
+			printAdvice(os.Stdout, outcome.Data, messages)
+		}
+		if *influence > 0 {
+			printInfluence(os.Stdout, outcome.Data, result, *influence)
+		}
+
+		if len(script.Checks) > 0 {
+			failed := script.FailedChecks(outcome.Data, result)
+			printChecks(os.Stdout, failed, len(script.Checks))
+			if len(failed) > 0 {
+				failedChecks = append(failedChecks, name)
+			}
+		}
+
+		if *checkEngines > 0 {
+			consistency := CheckConsistency(outcome.Data, *checkEngines)
+			printConsistency(os.Stdout, consistency)
+			if !consistency.Consistent() {
+				inconsistent = append(inconsistent, name)
+			}
+		}
+
+		if removeOutliers != "" {
+			printOutlierComparison(outcome.Data, result, removeOutliers, *outlierThreshold)
+		}
 	}
 
 	totalTime := time.Since(overallStart)
 
-	fmt.Printf("\n=== Summary ===\n")
-	fmt.Printf("Total execution time: %v\n", totalTime)
-	if len(datasets) > 0 {
-		avgSeconds := totalTime.Seconds() / float64(len(datasets))
-		fmt.Printf("Average per dataset:  %.6fs\n", avgSeconds)
+	if *groupBy != "" {
+		fmt.Printf("\n=== %s ===\n", messages.Text("Comparison"))
+		PrintComparisonTable(os.Stdout, outcomes)
+	}
+	if *ancova {
+		var names []string
+		var datasets []Dataset
+		for _, o := range outcomes {
+			if o.Err == nil {
+				names, datasets = append(names, o.Name), append(datasets, o.Data)
+			}
+		}
+		fmt.Printf("\n=== %s ===\n", messages.Text("Common Slope (ANCOVA)"))
+		if a, err := FitANCOVA(names, datasets); err != nil {
+			fmt.Printf("Not available: %v\n", err)
+		} else {
+			printANCOVA(os.Stdout, a)
+		}
+	}
+	fmt.Printf("\n=== %s ===\n", messages.Text("Manifest"))
+	PrintManifest(os.Stdout, manifest)
+	if *modelsDir != "" {
+		fmt.Printf("Models written to %s\n", *modelsDir)
+	}
+	if len(outputs) > 0 {
+		htmlOpts.Manifest = &manifest
+		htmlOpts.Locale = numbers
+		htmlOpts.Messages = messages
+		runRecord := NewRunRecord(outcomes, RunRecordOptions{Diagnostics: true, ChangePoints: *changePoints, Clusters: ClusterSpace(*clusters), Influence: *influence})
+		render := func(format OutputFormat) (Output, error) {
+			return renderOutput(format, manifest, runRecord, messages.Sprintf("%s Regression Analysis", messages.Text(title)), reportDatasets, htmlOpts)
+		}
+		err := DeliverOutputs(context.Background(), outputs, render, func(t OutputTarget, out Output) {
+			switch sink := t.Sink.(type) {
+			case ConsoleSink:
+			case *HistorySink:
+				fmt.Printf("Run recorded as %d in %s\n", sink.Recorded, sink.Path)
+			default:
+				fmt.Printf("%s written to %s\n", out.Title(), sink)
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if *goldenPath != "" {
+		record := NewRunRecord(outcomes, RunRecordOptions{Diagnostics: *diagnostics, ChangePoints: *changePoints, Clusters: ClusterSpace(*clusters), Influence: *influence})
+		if *updateGolden {
+			if err := WriteGoldenFile(*goldenPath, record); err != nil {
+				return fmt.Errorf("writing golden file: %w", err)
+			}
+			fmt.Printf("\nGolden file written to %s\n", *goldenPath)
+		} else {
+			diffs, err := CheckGoldenFile(*goldenPath, record, GoldenTolerance{Abs: DefaultGoldenTolerance().Abs, Rel: *goldenTol})
+			if err != nil {
+				return fmt.Errorf("checking golden file: %w", err)
+			}
+			fmt.Printf("\n=== %s ===\n", messages.Sprintf("Golden File (%s)", *goldenPath))
+			if len(diffs) > 0 {
+				fmt.Printf("%d differences:\n", len(diffs))
+				printGoldenDiffs(os.Stdout, diffs, 20)
+				return fmt.Errorf("results differ from golden file %s", *goldenPath)
+			}
+			fmt.Printf("No differences\n")
+		}
+	}
+
+	fmt.Printf("\n=== %s ===\n", messages.Text("Summary"))
+	fmt.Println(messages.Sprintf("Total execution time: %v", totalTime))
+	if len(jobs) > 0 {
+		avgSeconds := totalTime.Seconds() / float64(len(jobs))
+		fmt.Println(messages.Sprintf("Average per dataset:  %.6fs", avgSeconds))
 	} else {
-		fmt.Printf("Average per dataset:  N/A (no datasets)\n")
-	}
-	// Reference values below are based on standard linear regression results for the original Anscombe Quartet datasets.
-	// These values were obtained using R's lm() function and Python's statsmodels. See:
-	// https://en.wikipedia.org/wiki/Anscombe%27s_quartet
-	// For reproducibility, recalculate these if the dataset changes.
-	fmt.Printf("\n=== Expected Results (R/Python Reference) ===\n")
-	fmt.Printf("All datasets should have approximately:\n")
-	fmt.Printf("  Slope:     0.500091\n")
-	fmt.Printf("  Intercept: 3.000091\n")
-	fmt.Printf("  R-squared: 0.666542\n")
+		fmt.Println(messages.Text("Average per dataset:  N/A (no datasets)"))
+	}
+	if cached > 0 {
+		// their fits took no time in this run, so the average above understates a fresh run
+		fmt.Println(messages.Sprintf("Cached results:       %d of %d (use -no-cache to refit)", cached, len(jobs)))
+	}
+	if len(inconsistent) > 0 {
+		return fmt.Errorf("engines disagree beyond %.0e on datasets %s", *checkEngines, strings.Join(inconsistent, ", "))
+	}
+	if len(failedChecks) > 0 {
+		return fmt.Errorf("checks failed on datasets %s", strings.Join(failedChecks, ", "))
+	}
+	if reference == nil {
+		return nil
+	}
+
+	fmt.Printf("\n=== Conformance (%s) ===\n", reference.Source)
+	report := CheckConformance(*reference, results)
+	PrintConformance(os.Stdout, report)
+	if !report.Passed() {
+		return fmt.Errorf("results do not conform to %s", reference.Source)
+	}
+	return nil
 }
@@ -8,6 +8,7 @@ import pandas as pd
 import matplotlib.pyplot as plt
 import numpy as np
 from scipy import stats
+import sys
 import time
 
 # Loading the Anscombe dataset
@@ -46,6 +47,11 @@ print("\nLinear Regression Results for Anscombe Quartet:\n")
 print(df_results)
 print(f"Script execution time: {end - start:.6f} seconds")
 
+# Optionally save the results as a reference for the Go implementation (go run . -reference FILE)
+if len(sys.argv) > 1:
+    df_results.to_csv(sys.argv[1], index=False)
+    print(f"Results written to {sys.argv[1]}")
+
 
 # Plotting anscombe datasets with regression lines
 sns.lmplot(
@@ -19,7 +19,7 @@ create_dataset <- function(n) {
 }
 
 datasets <- map(1:4, create_dataset) %>% 
-  set_names(paste0("dataset", 1:4))
+  set_names(as.character(as.roman(1:4)))  # I to IV, as in Python and Go
 
 # Regression Analysis function
 perform_regression <- function(df, name) {
@@ -64,6 +64,13 @@ cat("Columns: Dataset = dataset name, Intercept = regression intercept, Slope =
 print(results, digits = 4)
 cat("\nExecution time:", round(elapsed_time, 4), "seconds\n")
 
+# Optionally save the results as a reference for the Go implementation (go run . -reference FILE)
+args <- commandArgs(trailingOnly = TRUE)
+if (length(args) > 0) {
+  write.csv(results, args[1], row.names = FALSE)
+  cat("Results written to", args[1], "\n")
+}
+
 
 # Reshape Anscombe dataset for ggplot, joining x and y by dataset number
 anscombe_long <- anscombe %>%
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AlertThresholds configures when a fit is considered out of bounds.
+// Use DefaultAlertThresholds for a value with every check disabled.
+type AlertThresholds struct {
+	MinRSquared float64
+	SlopeMin    float64
+	SlopeMax    float64
+}
+
+// DefaultAlertThresholds returns thresholds that never fire
+func DefaultAlertThresholds() AlertThresholds {
+	return AlertThresholds{MinRSquared: 0, SlopeMin: math.Inf(-1), SlopeMax: math.Inf(1)}
+}
+
+// Violations lists every threshold the result breaches
+func (t AlertThresholds) Violations(result RegressionResult) []string {
+	var violations []string
+	if result.RSquared < t.MinRSquared {
+		violations = append(violations, fmt.Sprintf("R-squared %.4f below minimum %.4f", result.RSquared, t.MinRSquared))
+	}
+	if result.Slope < t.SlopeMin {
+		violations = append(violations, fmt.Sprintf("slope %.4f below minimum %.4f", result.Slope, t.SlopeMin))
+	}
+	if result.Slope > t.SlopeMax {
+		violations = append(violations, fmt.Sprintf("slope %.4f above maximum %.4f", result.Slope, t.SlopeMax))
+	}
+	return violations
+}
+
+// FitAlert is the payload delivered to webhooks when a series breaches its thresholds
+type FitAlert struct {
+	Series     string            `json:"series"`
+	Violations []string          `json:"violations"`
+	Slope      float64           `json:"slope"`
+	Intercept  float64           `json:"intercept"`
+	RSquared   float64           `json:"rSquared"`
+	WorstFits  []PointDiagnostic `json:"worstFits,omitempty"`
+	Time       time.Time         `json:"time"`
+}
+
+// maxAlertPoints is how many of the worst-fitting points are attached to an alert
+const maxAlertPoints = 3
+
+// AlertHook evaluates fits against thresholds and notifies once per breach:
+// a series that stays out of bounds does not re-notify until it has recovered. It is safe for
+// concurrent use.
+type AlertHook struct {
+	Thresholds AlertThresholds
+	Notifier   *WebhookNotifier
+
+	mu     sync.Mutex
+	active map[string]bool
+}
+
+// NewAlertHook creates a hook delivering breaches of thresholds to notifier
+func NewAlertHook(thresholds AlertThresholds, notifier *WebhookNotifier) *AlertHook {
+	return &AlertHook{Thresholds: thresholds, Notifier: notifier, active: map[string]bool{}}
+}
+
+// Evaluate checks a fitted series and fires the webhook when it newly breaches a threshold
+func (h *AlertHook) Evaluate(ctx context.Context, series string, data Dataset, result RegressionResult) error {
+	violations := h.Thresholds.Violations(result)
+
+	h.mu.Lock()
+	wasActive := h.active[series]
+	h.active[series] = len(violations) > 0
+	h.mu.Unlock()
+
+	if len(violations) == 0 || wasActive {
+		return nil
+	}
+
+	alert := FitAlert{
+		Series:     series,
+		Violations: violations,
+		Slope:      result.Slope,
+		Intercept:  result.Intercept,
+		RSquared:   result.RSquared,
+		WorstFits:  worstFits(data, result, maxAlertPoints),
+		Time:       time.Now(),
+	}
+	return h.Notifier.Notify(ctx, alert)
+}
+
+// worstFits returns the n points with the largest absolute residuals
+func worstFits(data Dataset, result RegressionResult, n int) []PointDiagnostic {
+	diags := DatasetDiagnostics(data, result.Slope, result.Intercept)
+	sort.SliceStable(diags, func(i, j int) bool {
+		return math.Abs(diags[i].Residual) > math.Abs(diags[j].Residual)
+	})
+	if len(diags) > n {
+		diags = diags[:n]
+	}
+	return diags
+}
+
+// WebhookNotifier posts alerts as JSON. Slack incoming webhooks receive a formatted text message instead.
+type WebhookNotifier struct {
+	URL    string
+	Slack  bool
+	Client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier for url, using the Slack message format for hooks.slack.com URLs
+func NewWebhookNotifier(rawURL string) *WebhookNotifier {
+	slack := false
+	if u, err := url.Parse(rawURL); err == nil {
+		slack = u.Host == "hooks.slack.com"
+	}
+	return &WebhookNotifier{URL: rawURL, Slack: slack, Client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Notify delivers a single alert
+func (n *WebhookNotifier) Notify(ctx context.Context, alert FitAlert) error {
+	var payload any = alert
+	if n.Slack {
+		payload = map[string]string{"text": slackText(alert)}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting alert: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded %s", resp.Status)
+	}
+	return nil
+}
+
+func slackText(alert FitAlert) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, ":warning: *%s* breached fit-quality thresholds\n", alert.Series)
+	for _, v := range alert.Violations {
+		fmt.Fprintf(&b, "• %s\n", v)
+	}
+	fmt.Fprintf(&b, "slope %.4f, intercept %.4f, R² %.4f", alert.Slope, alert.Intercept, alert.RSquared)
+	for _, d := range alert.WorstFits {
+		name := fmt.Sprintf("#%d", d.Index)
+		if d.Label != "" {
+			name = fmt.Sprintf("%q", d.Label)
+		}
+		fmt.Fprintf(&b, "\n  point %s (x=%g, y=%g): residual %+.4f", name, d.X, d.Y, d.Residual)
+	}
+	return b.String()
+}
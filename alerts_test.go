@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Threshold checks report every breach
+func TestAlertThresholdViolations(t *testing.T) {
+	if v := DefaultAlertThresholds().Violations(RegressionResult{Slope: -1e9, RSquared: 0}); len(v) != 0 {
+		t.Errorf("default thresholds should never fire, got %v", v)
+	}
+
+	th := AlertThresholds{MinRSquared: 0.7, SlopeMin: 0, SlopeMax: 0.4}
+	if v := th.Violations(RegressionResult{Slope: 0.5, RSquared: 0.66}); len(v) != 2 {
+		t.Errorf("expected R-squared and slope violations, got %v", v)
+	}
+}
+
+// ✅ Test 2: Webhook fires once per breach, with the worst points attached
+func TestAlertHookDeliversOnce(t *testing.T) {
+	var received []FitAlert
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert FitAlert
+		json.NewDecoder(r.Body).Decode(&alert)
+		received = append(received, alert)
+	}))
+	defer srv.Close()
+
+	hook := NewAlertHook(AlertThresholds{MinRSquared: 0.9, SlopeMin: -1, SlopeMax: 1}, NewWebhookNotifier(srv.URL))
+	data := LoadAnscombeDatasets()["III"]
+	result, _ := AnalyzeDataset("III", data)
+
+	for i := 0; i < 3; i++ {
+		if err := hook.Evaluate(context.Background(), "III", data, result); err != nil {
+			t.Fatalf("evaluate failed: %v", err)
+		}
+	}
+	if len(received) != 1 {
+		t.Fatalf("expected a single notification, got %d", len(received))
+	}
+	if len(received[0].WorstFits) != maxAlertPoints || received[0].WorstFits[0].Y != 12.74 {
+		t.Errorf("expected the outlier first among worst fits, got %+v", received[0].WorstFits)
+	}
+
+	// recovery re-arms the hook
+	hook.Evaluate(context.Background(), "III", data, RegressionResult{Slope: 0.5, RSquared: 0.95})
+	hook.Evaluate(context.Background(), "III", data, result)
+	if len(received) != 2 {
+		t.Errorf("expected a second notification after recovery, got %d", len(received))
+	}
+}
+
+// ✅ Test 3: Slack hooks receive a text message
+func TestSlackText(t *testing.T) {
+	if !NewWebhookNotifier("https://hooks.slack.com/services/T/B/X").Slack {
+		t.Error("Slack URL not detected")
+	}
+	text := slackText(FitAlert{Series: "IV", Violations: []string{"slope 2 above maximum 1"}})
+	if !strings.Contains(text, "*IV*") || !strings.Contains(text, "above maximum") {
+		t.Errorf("unexpected Slack text: %s", text)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import "runtime"
+
+// AnalyzeOptions tunes AnalyzeDatasetWithOptions
+type AnalyzeOptions struct {
+	// AllocStats records heap allocations made during the fit in RegressionResult.Alloc.
+	// It reads runtime.MemStats, which briefly stops the world, so it is off by default.
+	AllocStats bool
+}
+
+// AllocStats is the heap allocation made by one fit, for capacity planning.
+// Bytes is an upper bound on the extra heap the fit needed at its peak.
+type AllocStats struct {
+	Allocs uint64 `json:"allocs"`
+	Bytes  uint64 `json:"bytes"`
+}
+
+// allocMeter measures heap allocations between start and stop.
+// The counters are process-wide: allocations by concurrent goroutines are included,
+// so measure under representative load or with a single request in flight.
+type allocMeter struct {
+	before runtime.MemStats
+}
+
+func (m *allocMeter) start() {
+	runtime.ReadMemStats(&m.before)
+}
+
+// stop returns the heap objects and bytes allocated since start
+func (m *allocMeter) stop() *AllocStats {
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	return &AllocStats{Allocs: after.Mallocs - m.before.Mallocs, Bytes: after.TotalAlloc - m.before.TotalAlloc}
+}
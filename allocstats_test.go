@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Allocation stats are only recorded when requested
+func TestAnalyzeDatasetAllocStats(t *testing.T) {
+	x, y := syntheticLine(1000)
+	data := Dataset{X: x, Y: y}
+
+	plain, err := AnalyzeDataset("line", data)
+	if err != nil || plain.Alloc != nil {
+		t.Errorf("stats should be off by default: %+v %v", plain, err)
+	}
+
+	measured, err := AnalyzeDatasetWithOptions("line", data, AnalyzeOptions{AllocStats: true})
+	// the stats engine copies the input, so at least one float64 slice of 1000 values is allocated
+	if err != nil || measured.Alloc == nil || measured.Alloc.Allocs == 0 || measured.Alloc.Bytes < 8*1000 {
+		t.Errorf("expected allocations to be recorded: %+v %v", measured, err)
+	}
+	if measured.Slope != plain.Slope {
+		t.Errorf("measuring must not change the fit: %v vs %v", measured.Slope, plain.Slope)
+	}
+}
+
+// ✅ Test 2: Server mode reports allocations per fit behind ServerOptions.AllocStats
+func TestServerFitAllocStats(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		srv := httptest.NewServer(NewServer(ServerOptions{AllocStats: enabled}))
+		resp, err := http.Post(srv.URL+"/fit", "application/json", strings.NewReader(`{"x":[1,2,3],"y":[3,5,7]}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var body map[string]any
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		srv.Close()
+
+		if _, ok := body["alloc"]; ok != enabled {
+			t.Errorf("alloc stats enabled=%v but response %v", enabled, body)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"text/tabwriter"
+)
+
+// ANCOVAGroup is one dataset of an ANCOVA: its own line, and its intercept under the shared slope
+type ANCOVAGroup struct {
+	Name            string  `json:"name"`
+	N               int     `json:"n"`
+	Slope           float64 `json:"slope"`
+	Intercept       float64 `json:"intercept"`
+	SharedIntercept float64 `json:"sharedIntercept"`
+}
+
+// FTest is an F test comparing a restricted model with a more general one
+type FTest struct {
+	F      float64 `json:"f"`
+	DF1    int     `json:"df1"`
+	DF2    int     `json:"df2"`
+	PValue float64 `json:"pValue"`
+}
+
+// ANCOVA is the joint analysis of several datasets with the same X and Y. Slopes tests whether
+// their slopes differ, comparing a line per group with parallel lines sharing SharedSlope (the
+// group-by-X interaction test). Intercepts then tests whether the parallel lines differ at all,
+// comparing them with a single line through the pooled data; it is only meaningful when the
+// slopes test does not reject.
+type ANCOVA struct {
+	Groups      []ANCOVAGroup `json:"groups"`
+	SharedSlope float64       `json:"sharedSlope"`
+	// residual sums of squares of the separate lines, the parallel lines and the single line
+	SSESeparate float64 `json:"sseSeparate"`
+	SSEShared   float64 `json:"sseShared"`
+	SSEPooled   float64 `json:"ssePooled"`
+	Slopes      FTest   `json:"slopes"`
+	Intercepts  FTest   `json:"intercepts"`
+}
+
+// FitANCOVA analyzes the complete pairs of the datasets together. Every dataset needs at least
+// three points and some spread in X.
+func FitANCOVA(names []string, datasets []Dataset) (ANCOVA, error) {
+	if len(names) != len(datasets) {
+		return ANCOVA{}, fmt.Errorf("%d names for %d datasets", len(names), len(datasets))
+	}
+	k := len(datasets)
+	if k < 2 {
+		return ANCOVA{}, fmt.Errorf("need at least two datasets, got %d", k)
+	}
+
+	var a ANCOVA
+	var sxx, sxy, n float64
+	var all Dataset
+	means := make([][2]float64, k)
+	for g, ds := range datasets {
+		x, y, err := cleanPairs(nil, nil, ds.X, ds.Y)
+		if err != nil {
+			return ANCOVA{}, fmt.Errorf("dataset %s: %w", names[g], err)
+		}
+		if len(x) < 3 {
+			return ANCOVA{}, fmt.Errorf("dataset %s: need at least three points, have %d", names[g], len(x))
+		}
+		mx, gxx := centeredSums(x)
+		my, _ := centeredSums(y)
+		if gxx == 0 {
+			return ANCOVA{}, fmt.Errorf("dataset %s: X is constant", names[g])
+		}
+		var gxy float64
+		for i := range x {
+			gxy += (x[i] - mx) * (y[i] - my)
+		}
+		slope := gxy / gxx
+		a.Groups = append(a.Groups, ANCOVAGroup{Name: names[g], N: len(x), Slope: slope, Intercept: my - slope*mx})
+		a.SSESeparate += residualSumSquares(x, y, slope, my-slope*mx)
+		sxx, sxy, n = sxx+gxx, sxy+gxy, n+float64(len(x))
+		means[g] = [2]float64{mx, my}
+		all.X, all.Y = append(all.X, x...), append(all.Y, y...)
+	}
+
+	a.SharedSlope = sxy / sxx
+	for g := range a.Groups {
+		a.Groups[g].SharedIntercept = means[g][1] - a.SharedSlope*means[g][0]
+		x, y, _ := cleanPairs(nil, nil, datasets[g].X, datasets[g].Y)
+		a.SSEShared += residualSumSquares(x, y, a.SharedSlope, a.Groups[g].SharedIntercept)
+	}
+	mx, pxx := centeredSums(all.X)
+	my, _ := centeredSums(all.Y)
+	var pxy float64
+	for i := range all.X {
+		pxy += (all.X[i] - mx) * (all.Y[i] - my)
+	}
+	pooledSlope := pxy / pxx
+	a.SSEPooled = residualSumSquares(all.X, all.Y, pooledSlope, my-pooledSlope*mx)
+
+	a.Slopes = nestedFTest(a.SSEShared, a.SSESeparate, k-1, int(n)-2*k)
+	a.Intercepts = nestedFTest(a.SSEPooled, a.SSEShared, k-1, int(n)-k-1)
+	return a, nil
+}
+
+// nestedFTest compares a restricted model's residual sum of squares with that of a general model
+// having df1 more parameters and df2 residual degrees of freedom
+func nestedFTest(sseRestricted, sseGeneral float64, df1, df2 int) FTest {
+	t := FTest{DF1: df1, DF2: df2}
+	if df2 < 1 {
+		t.F, t.PValue = math.NaN(), math.NaN()
+		return t
+	}
+	extra := math.Max(sseRestricted-sseGeneral, 0) / float64(df1)
+	switch {
+	case sseGeneral > 0:
+		t.F = extra / (sseGeneral / float64(df2))
+	case extra > 0:
+		t.F = math.Inf(1)
+	}
+	t.PValue = fSurvival(t.F, float64(df1), float64(df2))
+	return t
+}
+
+// printANCOVA writes the separate and shared-slope fits with both F tests
+func printANCOVA(w io.Writer, a ANCOVA) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Dataset\tPoints\tSlope\tIntercept\tShared-slope intercept\t")
+	for _, g := range a.Groups {
+		fmt.Fprintf(tw, "%s\t%d\t%.6f\t%.6f\t%.6f\t\n", g.Name, g.N, g.Slope, g.Intercept, g.SharedIntercept)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "Shared slope: %.6f\n", a.SharedSlope)
+	fmt.Fprintf(w, "Slopes differ:     F(%d, %d) = %.4f, p = %.4g\n", a.Slopes.DF1, a.Slopes.DF2, a.Slopes.F, a.Slopes.PValue)
+	fmt.Fprintf(w, "Intercepts differ: F(%d, %d) = %.4f, p = %.4g (given the shared slope)\n", a.Intercepts.DF1, a.Intercepts.DF2, a.Intercepts.F, a.Intercepts.PValue)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The sums of squares match multiple regressions with group dummies, and F tables agree
+func TestFitANCOVA(t *testing.T) {
+	var groups []Dataset
+	md := MultiDataset{Names: []string{"x", "g2", "g3"}}
+	for g, slope := range []float64{1, 1.2, 2} {
+		var ds Dataset
+		for i := 0; i < 12; i++ {
+			x := float64(i) + 0.3*float64(g)
+			y := float64(g) + slope*x + 0.5*math.Sin(float64(7*i+g))
+			ds.X, ds.Y = append(ds.X, x), append(ds.Y, y)
+			md.X = append(md.X, []float64{x, boolFloat(g == 1), boolFloat(g == 2)})
+			md.Y = append(md.Y, y)
+		}
+		groups = append(groups, ds)
+	}
+	groups[1].X = append(groups[1].X, math.NaN())
+	groups[1].Y = append(groups[1].Y, 1)
+
+	a, err := FitANCOVA([]string{"a", "b", "c"}, groups)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parallel, _ := FitMultiple(md)
+	var sse float64
+	for i, row := range md.X {
+		r := md.Y[i] - parallel.Predict(row)
+		sse += r * r
+	}
+	if relErr(a.SSEShared, sse) > 1e-9 || relErr(a.SharedSlope, parallel.Coefficients[1]) > 1e-9 {
+		t.Errorf("shared slope %v (SSE %v), dummy regression %v (SSE %v)", a.SharedSlope, a.SSEShared, parallel.Coefficients[1], sse)
+	}
+	if a.Slopes.DF1 != 2 || a.Slopes.DF2 != 30 || a.Intercepts.DF2 != 32 || a.Groups[1].N != 12 {
+		t.Errorf("unexpected degrees of freedom %+v", a)
+	}
+	if a.Slopes.PValue > 1e-6 || !(a.SSESeparate <= a.SSEShared && a.SSEShared <= a.SSEPooled) {
+		t.Errorf("expected the slopes to differ: %+v", a)
+	}
+
+	// F(3, 36) critical value at 5% and F(1, 10) at 1%
+	if p := fSurvival(2.866, 3, 36); math.Abs(p-0.05) > 1e-3 {
+		t.Errorf("P(F(3,36) >= 2.866) = %v", p)
+	}
+	if p := fSurvival(10.04, 1, 10); math.Abs(p-0.01) > 1e-3 {
+		t.Errorf("P(F(1,10) >= 10.04) = %v", p)
+	}
+}
+
+// ✅ Test 2: The quartet shares one slope, and unusable groups are rejected
+func TestFitANCOVAQuartet(t *testing.T) {
+	quartet := LoadAnscombeDatasets()
+	names := []string{"I", "II", "III", "IV"}
+	var datasets []Dataset
+	for _, name := range names {
+		datasets = append(datasets, quartet[name])
+	}
+	a, err := FitANCOVA(names, datasets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(a.SharedSlope-0.5) > 1e-3 || a.Slopes.PValue < 0.99 || a.Intercepts.PValue < 0.99 {
+		t.Errorf("unexpected quartet analysis %+v", a)
+	}
+	var buf bytes.Buffer
+	printANCOVA(&buf, a)
+	if !strings.Contains(buf.String(), "F(3, 36)") || !strings.Contains(buf.String(), "Shared slope: 0.4999") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+
+	flat := Dataset{X: []float64{1, 1, 1}, Y: []float64{1, 2, 3}}
+	for _, c := range []struct {
+		names    []string
+		datasets []Dataset
+	}{
+		{names[:1], datasets[:1]},
+		{names[:2], datasets[:1]},
+		{[]string{"I", "flat"}, []Dataset{datasets[0], flat}},
+		{[]string{"I", "short"}, []Dataset{datasets[0], {X: []float64{1, 2}, Y: []float64{1, 2}}}},
+	} {
+		if _, err := FitANCOVA(c.names, c.datasets); err == nil {
+			t.Errorf("%v: expected an error", c.names)
+		}
+	}
+}
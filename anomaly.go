@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// AnomalyEvent is raised for a reading whose residual from its device's running fit exceeds
+// K times the fit's residual standard deviation
+type AnomalyEvent struct {
+	Device    string    `json:"device"`
+	X         float64   `json:"x"`
+	Y         float64   `json:"y"`
+	Predicted float64   `json:"predicted"`
+	Residual  float64   `json:"residual"`
+	Sigma     float64   `json:"sigma"`
+	Score     float64   `json:"score"` // residual in units of sigma; 0 when sigma is 0
+	Points    int       `json:"points"`
+	Time      time.Time `json:"time"`
+}
+
+// AnomalyDetector keeps an OnlineRegression per device and checks every reading against the fit
+// of the readings before it. Anomalous readings are left out of the fit so a burst of them cannot
+// inflate sigma and mask the next. It is safe for concurrent use.
+type AnomalyDetector struct {
+	K         float64
+	MinPoints int
+
+	mu      sync.Mutex
+	devices map[string]*OnlineRegression
+}
+
+// NewAnomalyDetector flags readings more than k residual standard deviations from the fit, once
+// a device has minPoints readings (at least 3, so sigma is defined)
+func NewAnomalyDetector(k float64, minPoints int) (*AnomalyDetector, error) {
+	if !(k > 0) {
+		return nil, fmt.Errorf("k must be positive, got %v", k)
+	}
+	if minPoints < 3 {
+		return nil, fmt.Errorf("minimum points must be at least 3, got %d", minPoints)
+	}
+	return &AnomalyDetector{K: k, MinPoints: minPoints, devices: map[string]*OnlineRegression{}}, nil
+}
+
+// Observe checks a reading and adds it to the device's fit, returning an event when it is anomalous.
+// NaN and Inf readings are ignored.
+func (d *AnomalyDetector) Observe(r TelemetryReading) *AnomalyEvent {
+	if isMissing(r.X) || isMissing(r.Y) {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	fit, ok := d.devices[r.Device]
+	if !ok {
+		fit = &OnlineRegression{}
+		d.devices[r.Device] = fit
+	}
+	if fit.N() < d.MinPoints {
+		fit.Add(r.X, r.Y)
+		return nil
+	}
+
+	s := fit.Snapshot()
+	sigma := fit.ResidualStdDev()
+	predicted := s.Intercept + s.Slope*r.X
+	residual := r.Y - predicted
+	// after an exact fit (sigma 0) any departure is anomalous
+	if math.Abs(residual) <= d.K*sigma {
+		fit.Add(r.X, r.Y)
+		return nil
+	}
+	event := &AnomalyEvent{
+		Device:    r.Device,
+		X:         r.X,
+		Y:         r.Y,
+		Predicted: predicted,
+		Residual:  residual,
+		Sigma:     sigma,
+		Points:    s.N,
+		Time:      time.Now(),
+	}
+	if sigma > 0 {
+		event.Score = residual / sigma
+	}
+	return event
+}
+
+// AnomalyFeed fans anomaly events out to subscribers, such as the server's event stream.
+// Publishing never blocks: a subscriber that falls behind misses events.
+type AnomalyFeed struct {
+	mu          sync.Mutex
+	subscribers map[chan AnomalyEvent]struct{}
+}
+
+// NewAnomalyFeed creates a feed without subscribers
+func NewAnomalyFeed() *AnomalyFeed {
+	return &AnomalyFeed{subscribers: map[chan AnomalyEvent]struct{}{}}
+}
+
+// Subscribe returns a channel of future events and a function that unsubscribes and closes it
+func (f *AnomalyFeed) Subscribe() (<-chan AnomalyEvent, func()) {
+	ch := make(chan AnomalyEvent, 64)
+	f.mu.Lock()
+	f.subscribers[ch] = struct{}{}
+	f.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			f.mu.Lock()
+			delete(f.subscribers, ch)
+			f.mu.Unlock()
+			close(ch)
+		})
+	}
+}
+
+// Publish delivers e to every subscriber with room in its buffer
+func (f *AnomalyFeed) Publish(e AnomalyEvent) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for ch := range f.subscribers {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// noisyLine returns y = 2x + 1 plus a deterministic wiggle of amplitude 0.1
+func noisyLine(x float64) float64 {
+	return 2*x + 1 + 0.1*math.Sin(3*x)
+}
+
+// ✅ Test 1: ResidualStdDev matches the batch residual standard deviation
+func TestOnlineResidualStdDev(t *testing.T) {
+	var o OnlineRegression
+	x, y := make([]float64, 20), make([]float64, 20)
+	for i := range x {
+		x[i], y[i] = float64(i), noisyLine(float64(i))
+		o.Add(x[i], y[i])
+	}
+	slope, intercept, _ := ManualRegression(x, y)
+	want := math.Sqrt(residualSumSquares(x, y, slope, intercept) / 18)
+	if math.Abs(o.ResidualStdDev()-want) > 1e-9 {
+		t.Errorf("residual sd %v, want %v", o.ResidualStdDev(), want)
+	}
+	if (&OnlineRegression{}).ResidualStdDev() != 0 {
+		t.Error("expected 0 for an empty fit")
+	}
+}
+
+// ✅ Test 2: Outlying readings are flagged per device and kept out of the fit
+func TestAnomalyDetector(t *testing.T) {
+	d, err := NewAnomalyDetector(4, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 30; i++ {
+		if e := d.Observe(TelemetryReading{Device: "a", X: float64(i), Y: noisyLine(float64(i))}); e != nil {
+			t.Fatalf("reading %d flagged: %+v", i, e)
+		}
+	}
+	// another device has its own fit, still warming up
+	if e := d.Observe(TelemetryReading{Device: "b", X: 30, Y: 500}); e != nil {
+		t.Errorf("device b flagged before reaching the minimum: %+v", e)
+	}
+
+	e := d.Observe(TelemetryReading{Device: "a", X: 30, Y: noisyLine(30) + 5})
+	if e == nil || e.Device != "a" || e.Points != 30 || e.Score < 4 || math.Abs(e.Predicted-noisyLine(30)) > 0.3 {
+		t.Fatalf("expected an anomaly, got %+v", e)
+	}
+	if d.Observe(TelemetryReading{Device: "a", X: 31, Y: noisyLine(31) - 5}) == nil {
+		t.Error("expected the second outlier flagged too")
+	}
+	if d.Observe(TelemetryReading{Device: "a", X: 32, Y: math.NaN()}) != nil {
+		t.Error("NaN readings should be ignored")
+	}
+
+	if _, err := NewAnomalyDetector(0, 10); err == nil {
+		t.Error("expected an error for k = 0")
+	}
+	if _, err := NewAnomalyDetector(3, 2); err == nil {
+		t.Error("expected an error for fewer than 3 points")
+	}
+}
+
+// ✅ Test 3: The feed delivers to subscribers until they unsubscribe
+func TestAnomalyFeed(t *testing.T) {
+	feed := NewAnomalyFeed()
+	events, unsubscribe := feed.Subscribe()
+	feed.Publish(AnomalyEvent{Device: "a"})
+	if e := <-events; e.Device != "a" {
+		t.Errorf("unexpected event %+v", e)
+	}
+	unsubscribe()
+	unsubscribe()
+	feed.Publish(AnomalyEvent{Device: "b"})
+	if _, open := <-events; open {
+		t.Error("expected the channel closed after unsubscribing")
+	}
+}
+
+// ✅ Test 4: Readings posted to the server come back as anomalies and on the event stream
+func TestServerAnomalies(t *testing.T) {
+	detector, _ := NewAnomalyDetector(4, 10)
+	srv := httptest.NewServer(NewServer(ServerOptions{Anomalies: detector}))
+	defer srv.Close()
+
+	stream, err := http.Get(srv.URL + "/anomalies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	if ct := stream.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+
+	readings := make([]TelemetryReading, 0, 21)
+	for i := 0; i < 20; i++ {
+		readings = append(readings, TelemetryReading{Device: "s1", X: float64(i), Y: noisyLine(float64(i))})
+	}
+	readings = append(readings, TelemetryReading{Device: "s1", X: 20, Y: 100})
+	body, _ := json.Marshal(readings)
+	resp, err := http.Post(srv.URL+"/readings", "application/json", strings.NewReader(string(body)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Anomalies []AnomalyEvent `json:"anomalies"`
+	}
+	json.NewDecoder(resp.Body).Decode(&out)
+	resp.Body.Close()
+	if len(out.Anomalies) != 1 || out.Anomalies[0].Y != 100 {
+		t.Fatalf("unexpected anomalies %+v", out.Anomalies)
+	}
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(stream.Body)
+		for scanner.Scan() {
+			if strings.HasPrefix(scanner.Text(), "data: ") {
+				lines <- strings.TrimPrefix(scanner.Text(), "data: ")
+				return
+			}
+		}
+	}()
+	select {
+	case line := <-lines:
+		var event AnomalyEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil || event.Device != "s1" {
+			t.Errorf("unexpected event %q (%v)", line, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event streamed")
+	}
+
+	if resp, _ := http.Post(srv.URL+"/readings", "application/json", strings.NewReader(`{"device":`)); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("malformed body: status %d", resp.StatusCode)
+	}
+	plain := httptest.NewServer(NewServer(ServerOptions{}))
+	defer plain.Close()
+	if resp, _ := http.Get(plain.URL + "/anomalies"); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("anomaly stream should be disabled, got %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIKeys authenticates server requests by key, sent as "Authorization: Bearer KEY" or
+// "X-API-Key: KEY". Keys are held as SHA-256 digests and looked up by the digest of the
+// presented key, so the time taken does not depend on how much of a key was guessed right.
+// APIKeys is safe for concurrent use.
+type APIKeys struct {
+	clients map[[sha256.Size]byte]string
+}
+
+// NewAPIKeys maps each client name to its key. Keys must be distinct and at least 16 characters.
+func NewAPIKeys(keys map[string]string) (*APIKeys, error) {
+	a := &APIKeys{clients: make(map[[sha256.Size]byte]string, len(keys))}
+	for name, key := range keys {
+		if len(key) < 16 {
+			return nil, fmt.Errorf("API key of %s is shorter than 16 characters", name)
+		}
+		digest := sha256.Sum256([]byte(key))
+		if other, dup := a.clients[digest]; dup {
+			return nil, fmt.Errorf("clients %s and %s share an API key", other, name)
+		}
+		a.clients[digest] = name
+	}
+	if len(a.clients) == 0 {
+		return nil, fmt.Errorf("no API keys")
+	}
+	return a, nil
+}
+
+// LoadAPIKeysFile reads client names and keys, one "name key" pair per line. Blank lines and
+// lines starting with # are skipped.
+func LoadAPIKeysFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	keys := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected a client name and a key", path, line)
+		}
+		if _, dup := keys[fields[0]]; dup {
+			return nil, fmt.Errorf("%s:%d: client %s is listed twice", path, line, fields[0])
+		}
+		keys[fields[0]] = fields[1]
+	}
+	return keys, scanner.Err()
+}
+
+// Client returns the name of the client whose key the request presents
+func (a *APIKeys) Client(r *http.Request) (string, bool) {
+	key := r.Header.Get("X-API-Key")
+	if scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " "); ok && strings.EqualFold(scheme, "Bearer") {
+		key = strings.TrimSpace(token)
+	}
+	if key == "" {
+		return "", false
+	}
+	name, ok := a.clients[sha256.Sum256([]byte(key))]
+	return name, ok
+}
+
+// RateLimiter allows each client Rate requests per second on average, in bursts of up to Burst,
+// with a token bucket per client. It is safe for concurrent use.
+type RateLimiter struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	order   *list.List // of *rateBucket, least recently used first
+}
+
+// rateBucket is one client's tokens as of last
+type rateBucket struct {
+	client string
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiterClients is the number of clients whose buckets are kept. Beyond it the least recently
+// used bucket is dropped, which gives that client a full bucket if it comes back.
+const rateLimiterClients = 10000
+
+// NewRateLimiter returns a limiter allowing rate requests per second per client, in bursts of up
+// to burst (at least 1)
+func NewRateLimiter(rate float64, burst int) (*RateLimiter, error) {
+	if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+		return nil, fmt.Errorf("rate must be a positive number of requests per second, got %g", rate)
+	}
+	return &RateLimiter{rate: rate, burst: float64(max(burst, 1)), now: time.Now, buckets: map[string]*list.Element{}, order: list.New()}, nil
+}
+
+// Allow takes a token from the client's bucket. When it is empty, Allow returns false and how long
+// until the next token.
+func (l *RateLimiter) Allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+
+	var b *rateBucket
+	if e, ok := l.buckets[client]; ok {
+		l.order.MoveToBack(e)
+		b = e.Value.(*rateBucket)
+	} else {
+		l.sweep(now)
+		if l.order.Len() >= rateLimiterClients {
+			delete(l.buckets, l.order.Remove(l.order.Front()).(*rateBucket).client)
+		}
+		b = &rateBucket{client: client, tokens: l.burst, last: now}
+		l.buckets[client] = l.order.PushBack(b)
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// sweep drops the least recently used buckets that have refilled, which behave like new ones. It
+// stops at the first that has not, so each bucket is looked at about once.
+func (l *RateLimiter) sweep(now time.Time) {
+	for e := l.order.Front(); e != nil; e = l.order.Front() {
+		b := e.Value.(*rateBucket)
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate < l.burst {
+			return
+		}
+		l.order.Remove(e)
+		delete(l.buckets, b.client)
+	}
+}
+
+// clientContextKey keys the authenticated client's name in a request context
+type clientContextKey struct{}
+
+// RequestClient returns the name of the client authenticated for r, or "" when the server has no
+// API keys
+func RequestClient(r *http.Request) string {
+	name, _ := r.Context().Value(clientContextKey{}).(string)
+	return name
+}
+
+// protect wraps h with authentication by keys and rate limiting by limiter; either may be nil.
+// Requests are limited per client name when they carry a valid key, and per remote address
+// otherwise, so guessing keys is limited too. Rejections are JSON errors like the API's own:
+// 429 with Retry-After when the client is over its rate, then 401 without a valid key.
+func protect(h http.Handler, keys *APIKeys, limiter *RateLimiter) http.Handler {
+	if keys == nil && limiter == nil {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		client, ok := "", keys == nil
+		if keys != nil {
+			client, ok = keys.Client(r)
+		}
+		if limiter != nil {
+			id := "key:" + client
+			if !ok || client == "" {
+				id = "addr:" + remoteHost(r)
+			}
+			if allowed, wait := limiter.Allow(id); !allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": "rate limit exceeded"})
+				return
+			}
+		}
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="module5"`)
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "missing or invalid API key"})
+			return
+		}
+		if client != "" {
+			r = r.WithContext(context.WithValue(r.Context(), clientContextKey{}, client))
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// remoteHost is the address a request came from, without its port. Behind a proxy it is the
+// proxy's address, so limits apply to the proxy as a whole.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testKeyAlice = "alice-0123456789abcdef"
+	testKeyBob   = "bob-0123456789abcdef"
+)
+
+func newTestKeys(t *testing.T) *APIKeys {
+	t.Helper()
+	keys, err := NewAPIKeys(map[string]string{"alice": testKeyAlice, "bob": testKeyBob})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+// ✅ Test 1: Keys are accepted as bearer tokens or X-API-Key and name their client
+func TestAPIKeysClient(t *testing.T) {
+	keys := newTestKeys(t)
+	cases := []struct {
+		header, value, want string
+	}{
+		{"Authorization", "Bearer " + testKeyAlice, "alice"},
+		{"Authorization", "bearer " + testKeyBob, "bob"},
+		{"X-API-Key", testKeyBob, "bob"},
+		{"Authorization", "Basic " + testKeyAlice, ""},
+		{"X-API-Key", testKeyAlice + "x", ""},
+		{"X-API-Key", "", ""},
+	}
+	for _, c := range cases {
+		r := httptest.NewRequest("POST", "/fit", nil)
+		r.Header.Set(c.header, c.value)
+		if got, ok := keys.Client(r); got != c.want || ok != (c.want != "") {
+			t.Errorf("%s: %q: expected client %q, got %q (%v)", c.header, c.value, c.want, got, ok)
+		}
+	}
+
+	for _, bad := range []map[string]string{{}, {"a": "short"}, {"a": testKeyAlice, "b": testKeyAlice}} {
+		if _, err := NewAPIKeys(bad); err == nil {
+			t.Errorf("expected an error for keys %v", bad)
+		}
+	}
+}
+
+// ✅ Test 2: Keys files list one client and key per line
+func TestLoadAPIKeysFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	os.WriteFile(path, []byte("# clients\nalice "+testKeyAlice+"\n\n  bob\t"+testKeyBob+"\n"), 0o600)
+	keys, err := LoadAPIKeysFile(path)
+	if err != nil || len(keys) != 2 || keys["alice"] != testKeyAlice || keys["bob"] != testKeyBob {
+		t.Errorf("unexpected keys %v (err %v)", keys, err)
+	}
+	for _, bad := range []string{"alice\n", "alice a b\n", "alice " + testKeyAlice + "\nalice " + testKeyBob + "\n"} {
+		os.WriteFile(path, []byte(bad), 0o600)
+		if _, err := LoadAPIKeysFile(path); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+// ✅ Test 3: Buckets refill at the rate, up to the burst, independently per client
+func TestRateLimiter(t *testing.T) {
+	l, err := NewRateLimiter(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Fatalf("request %d within the burst was refused", i+1)
+		}
+	}
+	if ok, wait := l.Allow("a"); ok || wait != 500*time.Millisecond {
+		t.Errorf("expected a refusal with 500ms to wait, got %v %v", ok, wait)
+	}
+	if ok, _ := l.Allow("b"); !ok {
+		t.Error("another client should have its own bucket")
+	}
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if ok, _ := l.Allow("a"); !ok {
+			t.Errorf("request %d after a second was refused", i+1)
+		}
+	}
+	if ok, _ := l.Allow("a"); ok {
+		t.Error("expected the refilled tokens to be used up")
+	}
+	now = now.Add(time.Hour)
+	l.sweep(now)
+	if len(l.buckets) != 0 || l.order.Len() != 0 {
+		t.Errorf("expected idle buckets swept, have %d", len(l.buckets))
+	}
+
+	// past the client limit the least recently used bucket goes, even if it is not full
+	for i := 0; i < rateLimiterClients; i++ {
+		l.Allow(strconv.Itoa(i))
+	}
+	l.Allow("0")
+	if ok, _ := l.Allow("new"); !ok || len(l.buckets) != rateLimiterClients {
+		t.Errorf("expected a new client to be admitted within %d buckets, have %d", rateLimiterClients, len(l.buckets))
+	}
+	if _, kept := l.buckets["1"]; kept {
+		t.Error("expected the least recently used bucket to be dropped")
+	}
+	if _, kept := l.buckets["0"]; !kept {
+		t.Error("expected a recently used bucket to be kept")
+	}
+	if _, err := NewRateLimiter(0, 1); err == nil {
+		t.Error("expected an error for a zero rate")
+	}
+}
+
+// ✅ Test 4: The server rejects requests without a valid key or over the rate, with JSON errors
+func TestServerAuthAndRateLimit(t *testing.T) {
+	limiter, _ := NewRateLimiter(0.001, 2)
+	srv := httptest.NewServer(NewServer(ServerOptions{Keys: newTestKeys(t), RateLimit: limiter}))
+	defer srv.Close()
+
+	post := func(key string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("POST", srv.URL+"/fit", strings.NewReader(`{"x":[1,2,3],"y":[3,5,7]}`))
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	if resp := post(""); resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("WWW-Authenticate") == "" {
+		t.Errorf("expected 401 with a challenge, got %d", resp.StatusCode)
+	}
+	for i := 0; i < 2; i++ {
+		if resp := post(testKeyAlice); resp.StatusCode != http.StatusOK {
+			t.Errorf("request %d: expected 200, got %d", i+1, resp.StatusCode)
+		}
+	}
+	if resp := post(testKeyAlice); resp.StatusCode != http.StatusTooManyRequests || resp.Header.Get("Retry-After") == "" {
+		t.Errorf("expected 429 with Retry-After, got %d", resp.StatusCode)
+	}
+	if resp := post(testKeyBob); resp.StatusCode != http.StatusOK {
+		t.Errorf("another client should not share the limit, got %d", resp.StatusCode)
+	}
+	// the first anonymous request used one of the address's two tokens
+	if resp := post("wrong-key-0123456789"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong key, got %d", resp.StatusCode)
+	}
+	if resp := post("wrong-key-0123456789"); resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected key guessing to be rate limited, got %d", resp.StatusCode)
+	}
+}
+
+// ✅ Test 5: Handlers see the authenticated client
+func TestRequestClient(t *testing.T) {
+	var seen string
+	h := protect(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { seen = RequestClient(r) }), newTestKeys(t), nil)
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("X-API-Key", testKeyBob)
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	if seen != "bob" {
+		t.Errorf("expected client bob, got %q", seen)
+	}
+}
+
+// ✅ Test 6: serve refuses API keys with the dashboard, whose requests carry none
+func TestServeDashboardWithKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	os.WriteFile(path, []byte("alice "+testKeyAlice+"\n"), 0o600)
+	for _, args := range [][]string{
+		{"-dashboard", "-api-keys", path},
+		{"-api-key", testKeyAlice, "-dashboard"},
+	} {
+		if err := runServe(args); err == nil || !strings.Contains(err.Error(), "-dashboard cannot be used with API keys") {
+			t.Errorf("%v: expected the combination to be rejected, got %v", args, err)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ConfidenceBand is a model's confidence band for the mean response (MeanLower, MeanUpper) and
+// prediction band for new observations (Lower, Upper) over a grid of X values, ready to be drawn
+// as shaded regions around the fitted curve Y
+type ConfidenceBand struct {
+	Level     float64   `json:"level"`
+	X         []float64 `json:"x"`
+	Y         []float64 `json:"y"`
+	MeanLower []float64 `json:"meanLower"`
+	MeanUpper []float64 `json:"meanUpper"`
+	Lower     []float64 `json:"lower"`
+	Upper     []float64 `json:"upper"`
+}
+
+// BandGrid returns n evenly spaced values from lo to hi inclusive
+func BandGrid(lo, hi float64, n int) []float64 {
+	if n < 2 {
+		return []float64{lo}
+	}
+	grid := make([]float64, n)
+	for i := range grid {
+		grid[i] = lo + (hi-lo)*float64(i)/float64(n-1)
+	}
+	grid[n-1] = hi
+	return grid
+}
+
+// Band evaluates PredictInterval at each value of xs, which only works for models with a single
+// input. Outside the training range the band widens as PredictInterval describes.
+func (m Model) Band(level float64, xs []float64) (ConfidenceBand, error) {
+	if len(m.Inputs()) != 1 {
+		return ConfidenceBand{}, fmt.Errorf("model %s takes %d inputs; bands need a single one", m.Name, len(m.Inputs()))
+	}
+	band := ConfidenceBand{Level: level, X: xs}
+	for _, x := range xs {
+		p, err := m.PredictInterval(level, x)
+		if err != nil {
+			return ConfidenceBand{}, err
+		}
+		band.Y = append(band.Y, p.Y)
+		band.MeanLower = append(band.MeanLower, p.MeanLower)
+		band.MeanUpper = append(band.MeanUpper, p.MeanUpper)
+		band.Lower = append(band.Lower, p.Lower)
+		band.Upper = append(band.Upper, p.Upper)
+	}
+	return band, nil
+}
+
+// FitBand computes the bands of the line fitted to ds over points values spanning its X range
+func FitBand(ds Dataset, result RegressionResult, level float64, points int) (ConfidenceBand, error) {
+	x, _, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return ConfidenceBand{}, err
+	}
+	lo, hi := x[0], x[0]
+	for _, v := range x {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return NewLinearModel(result, ds).Band(level, BandGrid(lo, hi, points))
+}
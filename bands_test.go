@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Bands follow PredictInterval over an even grid and are narrowest at the mean of X
+func TestFitBand(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	result, _ := AnalyzeDataset("I", ds)
+	band, err := FitBand(ds, result, 0.9, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(band.X) != 11 || band.X[0] != 4 || band.X[10] != 14 || band.X[5] != 9 || band.Level != 0.9 {
+		t.Fatalf("unexpected grid %v", band.X)
+	}
+	model := NewLinearModel(result, ds)
+	narrowest := 0
+	for i, x := range band.X {
+		p, _ := model.PredictInterval(0.9, x)
+		if band.Y[i] != p.Y || band.Lower[i] != p.Lower || band.MeanUpper[i] != p.MeanUpper {
+			t.Errorf("x %v: band differs from %+v", x, p)
+		}
+		if !(band.Lower[i] < band.MeanLower[i] && band.MeanUpper[i] < band.Upper[i]) {
+			t.Errorf("x %v: confidence band is not inside the prediction band", x)
+		}
+		if band.MeanUpper[i]-band.MeanLower[i] < band.MeanUpper[narrowest]-band.MeanLower[narrowest] {
+			narrowest = i
+		}
+	}
+	if band.X[narrowest] != 9 {
+		t.Errorf("narrowest at %v, want the mean 9", band.X[narrowest])
+	}
+
+	if _, err := FitBand(Dataset{X: []float64{0, 1}, Y: []float64{1, 3}}, RegressionResult{Slope: 2, Intercept: 1}, 0.95, 10); err == nil {
+		t.Error("expected no band through two points")
+	}
+}
+
+// ✅ Test 2: The scatter plot and dashboard views carry the shaded bands
+func TestPlotBands(t *testing.T) {
+	ds := LoadAnscombeDatasets()["II"]
+	result, _ := AnalyzeDataset("II", ds)
+	if svg := string(svgScatter(ds, result, IntervalT)); strings.Count(svg, "<polygon") != 2 {
+		t.Errorf("expected two shaded bands:\n%s", svg)
+	}
+	two := Dataset{X: []float64{0, 1}, Y: []float64{1, 3}}
+	if svg := string(svgScatter(two, RegressionResult{Slope: 2, Intercept: 1}, IntervalT)); strings.Contains(svg, "<polygon") {
+		t.Error("expected no bands through two points")
+	}
+
+	view, err := newDatasetView("II", ds)
+	if err != nil || view.Band == nil || len(view.Band.X) != 40 || view.Band.Level != scatterBandLevel {
+		t.Errorf("unexpected view band %+v, %v", view.Band, err)
+	}
+}
+
+// ✅ Test 3: POST /bands computes a loaded model's bands over its training range or a requested one
+func TestBandsEndpoint(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	result, _ := AnalyzeDataset("I", ds)
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 10; i++ {
+		md.X = append(md.X, []float64{float64(i), float64(i * i % 7)})
+		md.Y = append(md.Y, float64(i)+float64(i%3))
+	}
+	fit, _ := FitMultiple(md)
+	srv := httptest.NewServer(NewServer(ServerOptions{Models: map[string]Model{
+		"I":     NewLinearModel(result, ds),
+		"plane": NewMultipleModel("plane", fit, md),
+	}}))
+	defer srv.Close()
+
+	post := func(body string) (int, ConfidenceBand) {
+		resp, err := http.Post(srv.URL+"/bands", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var band ConfidenceBand
+		json.NewDecoder(resp.Body).Decode(&band)
+		return resp.StatusCode, band
+	}
+
+	if code, band := post(`{"model": "I"}`); code != http.StatusOK || len(band.X) != 50 || band.X[0] != 4 || band.X[49] != 14 || band.Level != 0.95 {
+		t.Errorf("default grid: %d %+v", code, band)
+	}
+	code, band := post(`{"model": "I", "from": 0, "to": 20, "points": 3, "level": 0.5}`)
+	if code != http.StatusOK || len(band.X) != 3 || band.X[1] != 10 || band.Level != 0.5 || math.Abs(band.Y[2]-(result.Intercept+20*result.Slope)) > 1e-12 {
+		t.Errorf("requested grid: %d %+v", code, band)
+	}
+	for body, want := range map[string]int{
+		`{"model": "I", "points": 1}`:     http.StatusBadRequest,
+		`{"model": "I", "level": 2}`:      http.StatusBadRequest,
+		`{"model": "plane"}`:              http.StatusBadRequest,
+		`{"model": "missing"}`:            http.StatusNotFound,
+		`{"model": "I", "points": 20000}`: http.StatusBadRequest,
+	} {
+		if code, _ := post(body); code != want {
+			t.Errorf("%s: got status %d, want %d", body, code, want)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// AnalysisJob is one unit of work for AnalyzeAll.
+// When Path is set the dataset is loaded from that CSV file by the worker, otherwise Data is used.
+// Impute selects how the job's missing values are handled (see ImputeMissing) and Smooth how Y is
+// smoothed after imputation; the data must pass every rule in Rules before either is applied.
+type AnalysisJob struct {
+	Name   string
+	Path   string
+	Data   Dataset
+	Impute ImputeStrategy
+	Smooth SmoothOptions
+	Rules  []Rule
+	Script *ScriptHooks
+	Locale NumberLocale // how the file at Path writes numbers
+}
+
+// AnalysisOutcome is the result of one AnalysisJob, in the same position as its job
+type AnalysisOutcome struct {
+	Name   string
+	Data   Dataset
+	Result RegressionResult
+	Err    error
+	Cached bool
+}
+
+// AnalyzeAll runs the jobs on a bounded pool of workers and returns outcomes in job order.
+// workers <= 0 uses one worker per CPU.
+func AnalyzeAll(jobs []AnalysisJob, workers int) []AnalysisOutcome {
+	return AnalyzeAllWithCache(jobs, workers, nil)
+}
+
+// AnalyzeAllWithCache is AnalyzeAll reusing results from cache for datasets whose content has
+// already been fitted. A nil cache disables caching.
+func AnalyzeAllWithCache(jobs []AnalysisJob, workers int, cache *ResultCache) []AnalysisOutcome {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	outcomes := make([]AnalysisOutcome, len(jobs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				// each worker writes only its own slot, so no locking is needed
+				outcomes[i] = runAnalysisJob(jobs[i], cache)
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return outcomes
+}
+
+func runAnalysisJob(job AnalysisJob, cache *ResultCache) AnalysisOutcome {
+	outcome := AnalysisOutcome{Name: job.Name, Data: job.Data}
+	if job.Path != "" {
+		data, err := LoadCSVFileWithLocale(job.Path, job.Locale)
+		if err != nil {
+			outcome.Err = err
+			return outcome
+		}
+		outcome.Data = data
+	}
+	data, err := job.Script.Apply(outcome.Data)
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Data = data
+	if err := Validate(outcome.Data, job.Rules); err != nil {
+		outcome.Err = err
+		return outcome
+	}
+
+	if cache == nil {
+		outcome.Result, outcome.Err = analyzeJobData(job, outcome.Data)
+		return outcome
+	}
+
+	key := DatasetFingerprint(outcome.Data, analysisEngine+"|"+cacheFormat+"|impute="+string(job.Impute)+"|smooth="+job.Smooth.String())
+	if result, ok := cache.Get(key); ok {
+		// the same content may have been cached under another file name
+		result.Dataset, result.Cached = job.Name, true
+		outcome.Result, outcome.Cached = result, true
+		return outcome
+	}
+	outcome.Result, outcome.Err = analyzeJobData(job, outcome.Data)
+	if outcome.Err == nil {
+		cache.Put(key, outcome.Result)
+	}
+	return outcome
+}
+
+// analyzeJobData applies the job's imputation strategy and smoothing, then fits the data
+func analyzeJobData(job AnalysisJob, data Dataset) (RegressionResult, error) {
+	var imputed []int
+	if job.Impute != ImputeDrop {
+		var err error
+		if data, imputed, err = ImputeMissing(data, job.Impute); err != nil {
+			return RegressionResult{}, err
+		}
+	}
+	data, err := Smooth(data, job.Smooth)
+	if err != nil {
+		return RegressionResult{}, err
+	}
+
+	result, err := AnalyzeDataset(job.Name, data)
+	if err != nil {
+		return RegressionResult{}, err
+	}
+	result.Imputed = imputed
+	return result, nil
+}
+
+// anscombeJobs returns the built-in quartet as jobs in dataset order (I, II, III, IV)
+func anscombeJobs() []AnalysisJob {
+	return datasetJobs(LoadAnscombeDatasets())
+}
+
+// fileJobs turns CSV paths into jobs named after the file
+func fileJobs(paths []string) []AnalysisJob {
+	jobs := make([]AnalysisJob, 0, len(paths))
+	for _, path := range paths {
+		name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		jobs = append(jobs, AnalysisJob{Name: name, Path: path})
+	}
+	return jobs
+}
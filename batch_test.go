@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Test 1: Outcomes keep job order regardless of worker count
+func TestAnalyzeAllOrdered(t *testing.T) {
+	jobs := make([]AnalysisJob, 200)
+	for i := range jobs {
+		slope := float64(i)
+		jobs[i] = AnalysisJob{Name: fmt.Sprint(i), Data: Dataset{X: []float64{0, 1, 2}, Y: []float64{0, slope, 2 * slope}}}
+	}
+
+	for _, workers := range []int{1, 4, 0, 1000} {
+		outcomes := AnalyzeAll(jobs, workers)
+		for i, o := range outcomes {
+			if o.Err != nil || o.Name != fmt.Sprint(i) || o.Result.Slope != float64(i) {
+				t.Fatalf("workers=%d: outcome %d out of order or wrong: %+v", workers, i, o)
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Per-job failures do not abort the batch, CSV jobs are loaded by the workers
+func TestAnalyzeAllFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.csv")
+	os.WriteFile(good, []byte("x,y\n1,2\n2,4\n3,6\n"), 0o644)
+
+	outcomes := AnalyzeAll(fileJobs([]string{good, filepath.Join(dir, "missing.csv")}), 2)
+	if outcomes[0].Err != nil || outcomes[0].Name != "good" || outcomes[0].Result.Slope != 2 {
+		t.Errorf("unexpected outcome for good.csv: %+v", outcomes[0])
+	}
+	if outcomes[1].Err == nil {
+		t.Error("expected an error for the missing file")
+	}
+}
+
+// ✅ Test 3: The quartet is analyzed in dataset order
+func TestAnscombeJobsOrder(t *testing.T) {
+	jobs := anscombeJobs()
+	for i, want := range []string{"I", "II", "III", "IV"} {
+		if jobs[i].Name != want {
+			t.Errorf("job %d = %s, want %s", i, jobs[i].Name, want)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// BenchCase is one synthetic dataset of the engine comparison harness
+type BenchCase struct {
+	Name         string
+	Size         int
+	Conditioning string
+	X, Y         []float64
+}
+
+// BenchRow is the measurement of one engine on one case
+type BenchRow struct {
+	Engine       string  `json:"engine"`
+	Case         string  `json:"case"`
+	Size         int     `json:"size"`
+	Conditioning string  `json:"conditioning"`
+	NsPerFit     float64 `json:"nsPerFit"`
+	AllocsPerFit float64 `json:"allocsPerFit"`
+	BytesPerFit  float64 `json:"bytesPerFit"`
+	MaxDeviation float64 `json:"maxDeviation"`
+	Error        string  `json:"error,omitempty"`
+}
+
+// benchConditionings are the x layouts generated for every size:
+// well-conditioned, a large offset (the classic normal-equations failure) and a tiny spread
+var benchConditionings = []string{"well", "offset", "tiny-spread"}
+
+// SyntheticBenchCases generates y = 3 + 0.5x + noise for every size and conditioning, reproducibly from seed
+func SyntheticBenchCases(sizes []int, seed uint64) []BenchCase {
+	var cases []BenchCase
+	for _, size := range sizes {
+		for _, cond := range benchConditionings {
+			rng := rand.New(rand.NewPCG(seed, uint64(size)))
+			c := BenchCase{Name: fmt.Sprintf("%s/n=%d", cond, size), Size: size, Conditioning: cond,
+				X: make([]float64, size), Y: make([]float64, size)}
+			for i := range c.X {
+				u := float64(i) / float64(size)
+				switch cond {
+				case "well":
+					c.X[i] = 10 * u
+				case "offset":
+					c.X[i] = 1e6 + 10*u
+				case "tiny-spread":
+					c.X[i] = 1 + 1e-6*u
+				}
+				c.Y[i] = 3 + 0.5*c.X[i] + rng.NormFloat64()*1e-3
+			}
+			cases = append(cases, c)
+		}
+	}
+	return cases
+}
+
+// deviation is the error of got relative to want, measured against max(|want|, 1) so values near zero stay meaningful
+func deviation(got, want float64) float64 {
+	return math.Abs(got-want) / math.Max(math.Abs(want), 1)
+}
+
+// CompareEngines runs every engine over every case reps times, measuring latency, allocations
+// and the largest deviation of slope, intercept or R² from BigFloatRegression
+func CompareEngines(cases []BenchCase, engines []Engine, reps int) []BenchRow {
+	if reps < 1 {
+		reps = 1
+	}
+
+	var rows []BenchRow
+	for _, c := range cases {
+		refSlope, refIntercept, refR2, refErr := BigFloatRegression(c.X, c.Y)
+
+		for _, e := range engines {
+			row := BenchRow{Engine: e.Name, Case: c.Name, Size: c.Size, Conditioning: c.Conditioning}
+			if refErr != nil {
+				row.Error = "reference: " + refErr.Error()
+				rows = append(rows, row)
+				continue
+			}
+
+			// warm-up run also provides the accuracy measurement
+			slope, intercept, r2, err := e.Fit(c.X, c.Y)
+			if err != nil {
+				row.Error = err.Error()
+				rows = append(rows, row)
+				continue
+			}
+			row.MaxDeviation = max(deviation(slope, refSlope), deviation(intercept, refIntercept), deviation(r2, refR2))
+
+			var before, after runtime.MemStats
+			runtime.ReadMemStats(&before)
+			start := time.Now()
+			for i := 0; i < reps; i++ {
+				e.Fit(c.X, c.Y)
+			}
+			elapsed := time.Since(start)
+			runtime.ReadMemStats(&after)
+
+			row.NsPerFit = float64(elapsed.Nanoseconds()) / float64(reps)
+			row.AllocsPerFit = float64(after.Mallocs-before.Mallocs) / float64(reps)
+			row.BytesPerFit = float64(after.TotalAlloc-before.TotalAlloc) / float64(reps)
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// PrintBenchTable writes the comparison as an aligned table
+func PrintBenchTable(w io.Writer, rows []BenchRow) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Case\tEngine\tTime/fit\tAllocs/fit\tBytes/fit\tMax deviation\t")
+	for _, r := range rows {
+		if r.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\terror: %s\t\t\t\t\n", r.Case, r.Engine, r.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%.1f\t%.0f\t%.2e\t\n", r.Case, r.Engine,
+			time.Duration(r.NsPerFit).Round(time.Nanosecond), r.AllocsPerFit, r.BytesPerFit, r.MaxDeviation)
+	}
+	tw.Flush()
+}
+
+// runBench implements the `bench` subcommand
+func runBench(args []string) error {
+	if len(args) == 0 || args[0] != "compare" {
+		return fmt.Errorf("usage: bench compare [flags]")
+	}
+
+	flags := flag.NewFlagSet("bench compare", flag.ContinueOnError)
+	sizesFlag := flags.String("sizes", "100,10000,1000000", "comma-separated dataset sizes")
+	enginesFlag := flags.String("engines", "", "comma-separated engine names (default: all registered)")
+	reps := flags.Int("reps", 5, "timed repetitions per engine and case")
+	seed := flags.Uint64("seed", 1, "random seed for the synthetic datasets")
+	jsonPath := flags.String("json", "", "also write the results as JSON to this file, for use as a -baseline (- writes them to standard output instead of the table)")
+	baselinePath := flags.String("baseline", "", "compare with the results of an earlier -json run and fail when an engine regressed")
+	thresholds := DefaultBenchThresholds()
+	flags.Float64Var(&thresholds.Slowdown, "slowdown", thresholds.Slowdown, "with -baseline, relative increase in time per fit that counts as a regression")
+	flags.Float64Var(&thresholds.Allocs, "alloc-increase", thresholds.Allocs, "with -baseline, relative increase in allocations per fit that counts as a regression")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if thresholds.Slowdown < 0 || thresholds.Allocs < 0 {
+		return fmt.Errorf("-slowdown and -alloc-increase must not be negative")
+	}
+
+	var sizes []int
+	for _, field := range strings.Split(*sizesFlag, ",") {
+		size, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || size < 2 {
+			return fmt.Errorf("invalid size %q", field)
+		}
+		sizes = append(sizes, size)
+	}
+
+	selected, err := parseEngineList(*enginesFlag)
+	if err != nil {
+		return err
+	}
+
+	var baseline BenchReport
+	if *baselinePath != "" {
+		// read it first, so a bad path fails before the benchmarks run
+		if baseline, err = LoadBenchReport(*baselinePath); err != nil {
+			return fmt.Errorf("loading baseline: %w", err)
+		}
+	}
+
+	rows := CompareEngines(SyntheticBenchCases(sizes, *seed), selected, *reps)
+	if *jsonPath != "" {
+		report := BenchReport{Manifest: NewManifest(analysisEngine), Reps: *reps, Rows: rows}
+		report.Manifest.SetOptions(flags)
+		report.Manifest.SetSeed("bench", *seed)
+		if err := writeBenchReportFile(*jsonPath, report); err != nil {
+			return fmt.Errorf("writing results: %w", err)
+		}
+	}
+	if *jsonPath != "-" {
+		PrintBenchTable(os.Stdout, rows)
+	}
+	if *baselinePath == "" {
+		return nil
+	}
+
+	changes := CompareBenchBaseline(baseline.Rows, rows, thresholds)
+	// keep standard output machine-readable when the JSON goes there
+	out := io.Writer(os.Stdout)
+	if *jsonPath == "-" {
+		out = os.Stderr
+	}
+	fmt.Fprintf(out, "\n=== Baseline (%s, %s) ===\n", *baselinePath, baseline.Manifest.Created.Local().Format(time.RFC3339))
+	printBenchChanges(out, changes)
+	var regressed int
+	for _, c := range changes {
+		if c.Regressed {
+			regressed++
+		}
+	}
+	if regressed > 0 {
+		return fmt.Errorf("%d of %d benchmarks regressed beyond the baseline (slowdown %g, allocations %g)", regressed, len(changes), thresholds.Slowdown, thresholds.Allocs)
+	}
+	return nil
+}
+
+// writeBenchReportFile writes the report to path, or to standard output when path is "-"
+func writeBenchReportFile(path string, r BenchReport) error {
+	if path == "-" {
+		return WriteBenchReport(os.Stdout, r)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteBenchReport(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
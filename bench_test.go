@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: CompareEngines reports every engine on every case, and compensated stays accurate when offset
+func TestCompareEngines(t *testing.T) {
+	cases := SyntheticBenchCases([]int{200}, 1)
+	if len(cases) != len(benchConditionings) {
+		t.Fatalf("expected %d cases, got %d", len(benchConditionings), len(cases))
+	}
+
+	rows := CompareEngines(cases, Engines(), 1)
+	if len(rows) != len(cases)*len(Engines()) {
+		t.Fatalf("expected %d rows, got %d", len(cases)*len(Engines()), len(rows))
+	}
+	for _, r := range rows {
+		if r.Error != "" {
+			t.Errorf("%s on %s failed: %s", r.Engine, r.Case, r.Error)
+		}
+		if r.Engine == "compensated" && r.MaxDeviation > 1e-9 {
+			t.Errorf("compensated deviation on %s too large: %g", r.Case, r.MaxDeviation)
+		}
+	}
+
+	var buf bytes.Buffer
+	PrintBenchTable(&buf, rows)
+	if !strings.Contains(buf.String(), "offset/n=200") || !strings.Contains(buf.String(), "Max deviation") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+// ✅ Test 2: The subcommand rejects bad arguments
+func TestRunBenchArgs(t *testing.T) {
+	for _, args := range [][]string{nil, {"run"}, {"compare", "-sizes", "1"}, {"compare", "-engines", "nope"}} {
+		if err := runBench(args); err == nil {
+			t.Errorf("expected error for %v", args)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// BenchReport is the machine-readable result of `bench compare`: the build and options it ran
+// with, and a row per engine and case. Saved as JSON, it is the baseline of later runs.
+type BenchReport struct {
+	Manifest Manifest   `json:"manifest"`
+	Reps     int        `json:"reps"`
+	Rows     []BenchRow `json:"rows"`
+}
+
+// WriteBenchReport writes r as indented JSON
+func WriteBenchReport(w io.Writer, r BenchReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// LoadBenchReport reads a report written by WriteBenchReport
+func LoadBenchReport(path string) (BenchReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return BenchReport{}, err
+	}
+	var r BenchReport
+	if err := json.Unmarshal(data, &r); err != nil {
+		return BenchReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(r.Rows) == 0 {
+		return BenchReport{}, fmt.Errorf("%s: no benchmark rows", path)
+	}
+	return r, nil
+}
+
+// BenchThresholds decide when an engine has regressed on a case: when its time per fit grows by
+// more than Slowdown of the baseline's, or it allocates more than Allocs times as often (and at
+// least once more per fit, so an engine that allocated nothing is not flagged for a stray byte)
+type BenchThresholds struct {
+	Slowdown float64
+	Allocs   float64
+}
+
+// DefaultBenchThresholds flag a slowdown of 20% or 10% more allocations. Timings vary between
+// runs, so compare baselines taken on the same machine with enough -reps to steady them.
+func DefaultBenchThresholds() BenchThresholds {
+	return BenchThresholds{Slowdown: 0.2, Allocs: 0.1}
+}
+
+// BenchChange compares one engine and case with the baseline
+type BenchChange struct {
+	Engine         string  `json:"engine"`
+	Case           string  `json:"case"`
+	BaselineNs     float64 `json:"baselineNs"`
+	NsPerFit       float64 `json:"nsPerFit"`
+	TimeChange     float64 `json:"timeChange"` // relative to the baseline
+	BaselineAllocs float64 `json:"baselineAllocs"`
+	AllocsPerFit   float64 `json:"allocsPerFit"`
+	Error          string  `json:"error,omitempty"`
+	Regressed      bool    `json:"regressed"`
+}
+
+// CompareBenchBaseline matches the current rows with the baseline's by engine and case, in the
+// order of current. Rows without a baseline, such as a new engine, are skipped; a row that fails
+// where the baseline succeeded has regressed.
+func CompareBenchBaseline(baseline, current []BenchRow, th BenchThresholds) []BenchChange {
+	type key struct{ engine, name string }
+	base := make(map[key]BenchRow, len(baseline))
+	for _, r := range baseline {
+		base[key{r.Engine, r.Case}] = r
+	}
+
+	var changes []BenchChange
+	for _, r := range current {
+		b, ok := base[key{r.Engine, r.Case}]
+		if !ok || b.Error != "" {
+			continue
+		}
+		c := BenchChange{Engine: r.Engine, Case: r.Case, BaselineNs: b.NsPerFit, NsPerFit: r.NsPerFit,
+			BaselineAllocs: b.AllocsPerFit, AllocsPerFit: r.AllocsPerFit, Error: r.Error}
+		if r.Error != "" {
+			c.Regressed = true
+			changes = append(changes, c)
+			continue
+		}
+		if b.NsPerFit > 0 {
+			c.TimeChange = (r.NsPerFit - b.NsPerFit) / b.NsPerFit
+		}
+		moreAllocs := r.AllocsPerFit-b.AllocsPerFit >= 1 && r.AllocsPerFit > b.AllocsPerFit*(1+th.Allocs)
+		c.Regressed = c.TimeChange > th.Slowdown || moreAllocs
+		changes = append(changes, c)
+	}
+	return changes
+}
+
+// printBenchChanges writes the comparison with the baseline, marking the regressions
+func printBenchChanges(w io.Writer, changes []BenchChange) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Case\tEngine\tBaseline\tTime/fit\tChange\tAllocs/fit\t\t")
+	for _, c := range changes {
+		mark := ""
+		if c.Regressed {
+			mark = "REGRESSED"
+		}
+		if c.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\t%v\terror: %s\t\t\t%s\t\n", c.Case, c.Engine, benchDuration(c.BaselineNs), c.Error, mark)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%v\t%+.1f%%\t%.1f -> %.1f\t%s\t\n", c.Case, c.Engine, benchDuration(c.BaselineNs),
+			benchDuration(c.NsPerFit), 100*c.TimeChange, c.BaselineAllocs, c.AllocsPerFit, mark)
+	}
+	tw.Flush()
+}
+
+// benchDuration rounds a time per fit for display
+func benchDuration(ns float64) time.Duration {
+	return time.Duration(math.Round(ns))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Slowdowns and extra allocations beyond the thresholds are regressions, speedups are not
+func TestCompareBenchBaseline(t *testing.T) {
+	baseline := []BenchRow{
+		{Engine: "fast", Case: "well/n=100", NsPerFit: 100, AllocsPerFit: 0},
+		{Engine: "fast", Case: "offset/n=100", NsPerFit: 100, AllocsPerFit: 2},
+		{Engine: "manual", Case: "well/n=100", NsPerFit: 100, AllocsPerFit: 10},
+		{Engine: "stats", Case: "well/n=100", NsPerFit: 100},
+		{Engine: "bigfloat", Case: "well/n=100", Error: "reference: failed"},
+	}
+	current := []BenchRow{
+		{Engine: "fast", Case: "well/n=100", NsPerFit: 119, AllocsPerFit: 0.5},  // within both thresholds
+		{Engine: "fast", Case: "offset/n=100", NsPerFit: 50, AllocsPerFit: 4},   // faster, but allocates twice as often
+		{Engine: "manual", Case: "well/n=100", NsPerFit: 130, AllocsPerFit: 10}, // 30% slower
+		{Engine: "stats", Case: "well/n=100", Error: "boom"},                    // now fails
+		{Engine: "bigfloat", Case: "well/n=100", NsPerFit: 1},                   // no usable baseline
+		{Engine: "float32", Case: "well/n=100", NsPerFit: 1},                    // new engine
+	}
+	changes := CompareBenchBaseline(baseline, current, DefaultBenchThresholds())
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 compared rows, got %+v", changes)
+	}
+	for i, want := range []bool{false, true, true, true} {
+		if changes[i].Regressed != want {
+			t.Errorf("%s on %s: expected regressed %v, got %+v", changes[i].Engine, changes[i].Case, want, changes[i])
+		}
+	}
+	if changes[1].TimeChange != -0.5 {
+		t.Errorf("expected a 50%% speedup, got %v", changes[1].TimeChange)
+	}
+
+	var buf bytes.Buffer
+	printBenchChanges(&buf, changes)
+	if strings.Count(buf.String(), "REGRESSED") != 3 || !strings.Contains(buf.String(), "+30.0%") {
+		t.Errorf("unexpected table:\n%s", buf.String())
+	}
+}
+
+// ✅ Test 2: A JSON export reads back as a baseline and the same run does not regress against itself
+func TestBenchReportRoundTrip(t *testing.T) {
+	rows := CompareEngines(SyntheticBenchCases([]int{50}, 1), Engines(), 1)
+	path := filepath.Join(t.TempDir(), "bench.json")
+	if err := writeBenchReportFile(path, BenchReport{Manifest: NewManifest(analysisEngine), Reps: 1, Rows: rows}); err != nil {
+		t.Fatal(err)
+	}
+	report, err := LoadBenchReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Reps != 1 || len(report.Rows) != len(rows) || report.Rows[0] != rows[0] {
+		t.Errorf("unexpected report %+v", report)
+	}
+	for _, c := range CompareBenchBaseline(report.Rows, rows, DefaultBenchThresholds()) {
+		if c.Regressed || c.TimeChange != 0 {
+			t.Errorf("%s on %s regressed against itself: %+v", c.Engine, c.Case, c)
+		}
+	}
+
+	os.WriteFile(path, []byte(`{"rows": []}`), 0o644)
+	if _, err := LoadBenchReport(path); err == nil {
+		t.Error("expected an error for a report without rows")
+	}
+	if err := runBench([]string{"compare", "-sizes", "10", "-baseline", filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected an error for a missing baseline")
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import "math/big"
+
+// bigFloatPrec is the mantissa size used by BigFloatRegression. Products of two float64 values
+// need at most 106 bits, so sums of them keep well over 100 bits of headroom before rounding.
+const bigFloatPrec = 256
+
+// BigFloatRegression fits finite pairs with 256-bit math/big arithmetic. It is far slower than
+// the float64 engines and is meant for verification and for pathological datasets where they
+// lose precision; the accuracy harness uses it as ground truth.
+func BigFloatRegression(x, y []float64) (slope, intercept, rSquared float64, err error) {
+	return BigFloatRegressionPrec(x, y, bigFloatPrec)
+}
+
+// BigFloatRegressionPrec is BigFloatRegression with a caller-chosen mantissa size in bits
+func BigFloatRegressionPrec(x, y []float64, prec uint) (slope, intercept, rSquared float64, err error) {
+	cleanX, cleanY, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	newF := func() *big.Float { return new(big.Float).SetPrec(prec) }
+	sumX, sumY, sumXY, sumXX, sumYY := newF(), newF(), newF(), newF(), newF()
+	xi, yi, prod := newF(), newF(), newF()
+	for i := range cleanX {
+		xi.SetFloat64(cleanX[i])
+		yi.SetFloat64(cleanY[i])
+		sumX.Add(sumX, xi)
+		sumY.Add(sumY, yi)
+		sumXY.Add(sumXY, prod.Mul(xi, yi))
+		sumXX.Add(sumXX, prod.Mul(xi, xi))
+		sumYY.Add(sumYY, prod.Mul(yi, yi))
+	}
+
+	n := newF().SetInt64(int64(len(cleanX)))
+	sxy := newF().Sub(newF().Mul(n, sumXY), newF().Mul(sumX, sumY))
+	sxx := newF().Sub(newF().Mul(n, sumXX), newF().Mul(sumX, sumX))
+	syy := newF().Sub(newF().Mul(n, sumYY), newF().Mul(sumY, sumY))
+
+	// degenerate cases follow ManualRegression: a horizontal line through the mean explains nothing
+	b := newF()
+	if sxx.Sign() != 0 {
+		b.Quo(sxy, sxx)
+	}
+	a := newF().Quo(newF().Sub(sumY, newF().Mul(b, sumX)), n)
+	slope, _ = b.Float64()
+	intercept, _ = a.Float64()
+
+	switch {
+	case syy.Sign() == 0:
+		// constant Y is fitted exactly
+		rSquared = 1
+	case sxx.Sign() == 0:
+		rSquared = 0
+	default:
+		// R² = Sxy² / (Sxx·Syy)
+		rSquared, _ = newF().Quo(newF().Mul(sxy, sxy), newF().Mul(sxx, syy)).Float64()
+	}
+	return slope, intercept, rSquared, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Recovers an exact line far from the origin where float64 normal equations fail
+func TestBigFloatRegressionOffset(t *testing.T) {
+	x := []float64{1e8, 1e8 + 1, 1e8 + 2, 1e8 + 3, math.NaN()}
+	y := make([]float64, len(x))
+	for i, v := range x {
+		y[i] = 2*(v-1e8) + 7
+	}
+	slope, intercept, r2, err := BigFloatRegression(x, y)
+	if err != nil || slope != 2 || math.Abs(intercept-(7-2e8)) > 1e-6 || r2 != 1 {
+		t.Errorf("got slope %v intercept %v r2 %v err %v", slope, intercept, r2, err)
+	}
+}
+
+// ✅ Test 2: Agrees with the float64 engines on Anscombe data and handles degenerate input like ManualRegression
+func TestBigFloatRegressionMatches(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		slope, intercept, r2, err := BigFloatRegression(data.X, data.Y)
+		refSlope, refIntercept, refR2 := ManualRegression(data.X, data.Y)
+		if err != nil || math.Abs(slope-refSlope) > 1e-12 || math.Abs(intercept-refIntercept) > 1e-12 || math.Abs(r2-refR2) > 1e-12 {
+			t.Errorf("%s: bigfloat (%v %v %v %v) vs manual (%v %v %v)", name, slope, intercept, r2, err, refSlope, refIntercept, refR2)
+		}
+	}
+
+	slope, intercept, r2, _ := BigFloatRegression([]float64{2, 2, 2}, []float64{1, 2, 3})
+	if slope != 0 || intercept != 2 || r2 != 0 {
+		t.Errorf("vertical line: got %v %v %v", slope, intercept, r2)
+	}
+	if _, _, _, err := BigFloatRegression([]float64{1, math.Inf(1)}, []float64{1, 2}); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// BinAggregate selects how the points of a bin are summarized
+type BinAggregate string
+
+const (
+	BinMean   BinAggregate = "mean"
+	BinMedian BinAggregate = "median"
+)
+
+// BinOptions configures Bin. Exactly one of Width and Count must be set.
+type BinOptions struct {
+	// Width is the X width of each bin; bins are aligned to multiples of Width
+	Width float64
+	// Count splits the X range into this many equal-width bins
+	Count int
+	// Agg summarizes X and Y within a bin; the default is BinMean
+	Agg BinAggregate
+}
+
+// BinnedDataset is one point per non-empty bin, with the number of points it stands for as weight
+type BinnedDataset struct {
+	Dataset
+	Weights []float64
+}
+
+// Bin aggregates dense X ranges into one point per bin, so millions of points can be plotted and
+// fitted sensibly. Pairs with NaN/Inf values are dropped. Fit the result with FitBinned, which uses
+// the counts as weights.
+func Bin(ds Dataset, opts BinOptions) (BinnedDataset, error) {
+	if len(ds.X) != len(ds.Y) {
+		return BinnedDataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	if (opts.Width > 0) == (opts.Count > 0) {
+		return BinnedDataset{}, fmt.Errorf("set exactly one of bin width and bin count")
+	}
+	if math.IsInf(opts.Width, 0) || math.IsNaN(opts.Width) {
+		return BinnedDataset{}, fmt.Errorf("bin width must be finite, got %v", opts.Width)
+	}
+	agg := opts.Agg
+	if agg == "" {
+		agg = BinMean
+	}
+	if agg != BinMean && agg != BinMedian {
+		return BinnedDataset{}, fmt.Errorf("unknown bin aggregate %q (use mean or median)", agg)
+	}
+
+	var x, y []float64
+	for i := range ds.X {
+		if !isMissing(ds.X[i]) && !isMissing(ds.Y[i]) {
+			x, y = append(x, ds.X[i]), append(y, ds.Y[i])
+		}
+	}
+	if len(x) == 0 {
+		return BinnedDataset{}, nil
+	}
+
+	lo, hi := x[0], x[0]
+	for _, v := range x {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	binOf := func(v float64) int64 { return int64(math.Floor(v / opts.Width)) }
+	if opts.Count > 0 {
+		width := (hi - lo) / float64(opts.Count)
+		binOf = func(v float64) int64 {
+			if width == 0 {
+				return 0
+			}
+			// the maximum belongs to the last bin rather than opening a new one
+			return min(int64((v-lo)/width), int64(opts.Count-1))
+		}
+	}
+
+	members := map[int64][]int{}
+	for i, v := range x {
+		b := binOf(v)
+		members[b] = append(members[b], i)
+	}
+	bins := make([]int64, 0, len(members))
+	for b := range members {
+		bins = append(bins, b)
+	}
+	sort.Slice(bins, func(i, j int) bool { return bins[i] < bins[j] })
+
+	out := BinnedDataset{Weights: make([]float64, 0, len(bins))}
+	out.XAxis, out.YAxis = ds.XAxis, ds.YAxis
+	var bx, by []float64
+	for _, b := range bins {
+		bx, by = bx[:0], by[:0]
+		for _, i := range members[b] {
+			bx = append(bx, x[i])
+			by = append(by, y[i])
+		}
+		out.X = append(out.X, aggregateBin(agg, bx))
+		out.Y = append(out.Y, aggregateBin(agg, by))
+		out.Weights = append(out.Weights, float64(len(bx)))
+	}
+	return out, nil
+}
+
+func aggregateBin(agg BinAggregate, values []float64) float64 {
+	if agg == BinMedian {
+		sort.Float64s(values)
+		return quantileSorted(values, 0.5)
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// FitBinned fits binned data by weighted least squares with the bin counts as weights
+func FitBinned(b BinnedDataset) (slope, intercept, rSquared float64, err error) {
+	return WeightedRegression(b.X, b.Y, b.Weights)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Width and count bins aggregate points with counts as weights
+func TestBin(t *testing.T) {
+	ds := Dataset{X: []float64{0.1, 0.4, 1.2, 1.3, 1.9, 3.5, math.NaN()}, Y: []float64{1, 3, 10, 20, 60, 7, 1}}
+
+	byWidth, err := Bin(ds, BinOptions{Width: 1})
+	if err != nil || !slices.Equal(byWidth.Weights, []float64{2, 3, 1}) || byWidth.Y[0] != 2 || byWidth.Y[1] != 30 {
+		t.Errorf("width bins: %+v (err %v)", byWidth, err)
+	}
+
+	byMedian, _ := Bin(ds, BinOptions{Width: 1, Agg: BinMedian})
+	if byMedian.Y[1] != 20 || byMedian.X[1] != 1.3 {
+		t.Errorf("median bins: %+v", byMedian)
+	}
+
+	// [0.1, 3.5] in two bins of width 1.7: the maximum lands in the last bin
+	byCount, _ := Bin(ds, BinOptions{Count: 2})
+	if !slices.Equal(byCount.Weights, []float64{4, 2}) {
+		t.Errorf("count bins: %+v", byCount)
+	}
+
+	for _, opts := range []BinOptions{{}, {Width: 1, Count: 2}, {Width: 1, Agg: "max"}} {
+		if _, err := Bin(ds, opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
+
+// ✅ Test 2: Fitting a million binned points recovers the full-data fit
+func TestFitBinnedLarge(t *testing.T) {
+	x, y := syntheticLine(1_000_000)
+	binned, err := Bin(Dataset{X: x, Y: y}, BinOptions{Count: 200})
+	if err != nil || len(binned.X) != 200 {
+		t.Fatalf("expected 200 bins, got %d (err %v)", len(binned.X), err)
+	}
+
+	slope, intercept, _, err := FitBinned(binned)
+	refSlope, refIntercept, _, _ := FastRegression(x, y)
+	if err != nil || deviation(slope, refSlope) > 1e-4 || deviation(intercept, refIntercept) > 1e-3 {
+		t.Errorf("binned (%v %v %v) vs full (%v %v)", slope, intercept, err, refSlope, refIntercept)
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// IntervalMethod is how the bands around a fitted line are computed
+type IntervalMethod string
+
+const (
+	// IntervalT uses Student's t, which is exact for independent normal errors of one variance
+	IntervalT IntervalMethod = "t"
+	// IntervalBootstrap resamples the residuals (see BootstrapBand), so the bands take the shape of
+	// the errors the data actually show instead of assuming they are normal
+	IntervalBootstrap IntervalMethod = "bootstrap"
+)
+
+// ParseIntervalMethod returns the method named by s; empty means IntervalT
+func ParseIntervalMethod(s string) (IntervalMethod, error) {
+	switch m := IntervalMethod(s); m {
+	case "":
+		return IntervalT, nil
+	case IntervalT, IntervalBootstrap:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown interval method %q (use t or bootstrap)", s)
+}
+
+// DefaultBootstrapReplicates is the number of replicates behind bootstrap bands, enough for the
+// tails of 95% intervals to move by less than a few percent between seeds
+const DefaultBootstrapReplicates = 2000
+
+// BootstrapBand computes the bands of the least squares line through the finite pairs of ds at
+// each value of xs by the residual bootstrap, as an alternative to the t-based Model.Band for
+// small samples whose errors are skewed or heavy-tailed.
+//
+// The residuals are divided by sqrt(1-h), h being each point's leverage, and centered, so that
+// they have the variance of the errors. Each replicate adds residuals drawn with replacement to the
+// fitted values at the same X and refits the line. The mean band is the basic bootstrap interval of
+// the replicate fits; the prediction band also adds a fresh residual to each replicate prediction,
+// so it allows for the error of the new observation and for the line being estimated. Weights of
+// ds are not supported. Bands depend only on seed.
+func BootstrapBand(ds Dataset, level float64, xs []float64, replicates int, seed uint64) (ConfidenceBand, error) {
+	if ds.Weights != nil {
+		return ConfidenceBand{}, fmt.Errorf("bootstrap bands need unweighted data")
+	}
+	if !(level > 0 && level < 1) {
+		return ConfidenceBand{}, fmt.Errorf("level must be between 0 and 1, got %v", level)
+	}
+	if replicates < 2 {
+		return ConfidenceBand{}, fmt.Errorf("need at least 2 replicates, got %d", replicates)
+	}
+	x, y, meanX, sxx, err := standardErrorPairs(ds.X, ds.Y)
+	if err != nil {
+		return ConfidenceBand{}, err
+	}
+	n := len(x)
+	meanY, _ := meanVariance(y)
+	slope := 0.0
+	for i := range x {
+		slope += (x[i] - meanX) * y[i] / sxx
+	}
+	intercept := meanY - slope*meanX
+
+	fitted := make([]float64, n)
+	residuals := make([]float64, n)
+	var meanResidual float64
+	for i := range x {
+		fitted[i] = intercept + slope*x[i]
+		// a point of leverage 1 has a zero residual whatever its error, so it adds nothing
+		if h := 1/float64(n) + (x[i]-meanX)*(x[i]-meanX)/sxx; 1-h > 1e-12 {
+			residuals[i] = (y[i] - fitted[i]) / math.Sqrt(1-h)
+		}
+		meanResidual += residuals[i] / float64(n)
+	}
+	for i := range residuals {
+		residuals[i] -= meanResidual
+	}
+
+	// mean[k] and predictionErrors[k] collect, for xs[k], the replicate fits' departures from the
+	// line and their prediction errors
+	mean := make([][]float64, len(xs))
+	predictionErrors := make([][]float64, len(xs))
+	for k := range xs {
+		mean[k] = make([]float64, replicates)
+		predictionErrors[k] = make([]float64, replicates)
+	}
+	rng := rand.New(rand.NewPCG(seed, 0))
+	yStar := make([]float64, n)
+	for b := 0; b < replicates; b++ {
+		var sum float64
+		for i := range yStar {
+			yStar[i] = fitted[i] + residuals[rng.IntN(n)]
+			sum += yStar[i]
+		}
+		slopeStar := 0.0
+		for i := range x {
+			slopeStar += (x[i] - meanX) * yStar[i] / sxx
+		}
+		interceptStar := sum/float64(n) - slopeStar*meanX
+		for k, x0 := range xs {
+			mean[k][b] = (interceptStar + slopeStar*x0) - (intercept + slope*x0)
+			predictionErrors[k][b] = mean[k][b] - residuals[rng.IntN(n)]
+		}
+	}
+
+	alpha := (1 - level) / 2
+	band := ConfidenceBand{Level: level, X: xs}
+	for k, x0 := range xs {
+		y0 := intercept + slope*x0
+		slices.Sort(mean[k])
+		slices.Sort(predictionErrors[k])
+		band.Y = append(band.Y, y0)
+		band.MeanLower = append(band.MeanLower, y0-quantileSorted(mean[k], 1-alpha))
+		band.MeanUpper = append(band.MeanUpper, y0-quantileSorted(mean[k], alpha))
+		band.Lower = append(band.Lower, y0-quantileSorted(predictionErrors[k], 1-alpha))
+		band.Upper = append(band.Upper, y0-quantileSorted(predictionErrors[k], alpha))
+	}
+	return band, nil
+}
+
+// FitBootstrapBand is FitBand by the residual bootstrap: the bands of the line fitted to ds over
+// points values spanning its X range
+func FitBootstrapBand(ds Dataset, level float64, points, replicates int, seed uint64) (ConfidenceBand, error) {
+	x, _, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return ConfidenceBand{}, err
+	}
+	lo, hi := x[0], x[0]
+	for _, v := range x {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return BootstrapBand(ds, level, BandGrid(lo, hi, points), replicates, seed)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: With normal errors the bootstrap bands are close to the t-based ones and repeat per seed
+func TestBootstrapBandNormal(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 0))
+	var ds Dataset
+	for i := 0; i < 60; i++ {
+		x := float64(i) / 6
+		ds.X, ds.Y = append(ds.X, x), append(ds.Y, 1+2*x+rng.NormFloat64())
+	}
+	result, _ := AnalyzeDataset("normal", ds)
+	tBand, err := FitBand(ds, result, 0.95, 9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	band, err := FitBootstrapBand(ds, 0.95, 9, DefaultBootstrapReplicates, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, x := range band.X {
+		if math.Abs(band.Y[i]-tBand.Y[i]) > 1e-9 {
+			t.Errorf("x %v: fit %v, t-based %v", x, band.Y[i], tBand.Y[i])
+		}
+		if !(band.Lower[i] < band.MeanLower[i] && band.MeanLower[i] < band.Y[i] && band.Y[i] < band.MeanUpper[i] && band.MeanUpper[i] < band.Upper[i]) {
+			t.Errorf("x %v: bands out of order: %v %v %v %v %v", x, band.Lower[i], band.MeanLower[i], band.Y[i], band.MeanUpper[i], band.Upper[i])
+		}
+		if r := (band.Upper[i] - band.Lower[i]) / (tBand.Upper[i] - tBand.Lower[i]); r < 0.85 || r > 1.15 {
+			t.Errorf("x %v: prediction band %.2f times the t-based width", x, r)
+		}
+		if r := (band.MeanUpper[i] - band.MeanLower[i]) / (tBand.MeanUpper[i] - tBand.MeanLower[i]); r < 0.8 || r > 1.2 {
+			t.Errorf("x %v: confidence band %.2f times the t-based width", x, r)
+		}
+	}
+
+	again, _ := FitBootstrapBand(ds, 0.95, 9, DefaultBootstrapReplicates, 1)
+	if !slices.Equal(band.Lower, again.Lower) || !slices.Equal(band.MeanUpper, again.MeanUpper) {
+		t.Error("same seed gave different bands")
+	}
+	other, _ := FitBootstrapBand(ds, 0.95, 9, DefaultBootstrapReplicates, 2)
+	if slices.Equal(band.Lower, other.Lower) {
+		t.Error("different seeds gave identical bands")
+	}
+}
+
+// ✅ Test 2: Skewed errors give an asymmetric prediction band that still covers new observations
+func TestBootstrapBandSkewed(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 0))
+	newError := func() float64 { return rng.ExpFloat64() - 1 }
+	var ds Dataset
+	for i := 0; i < 15; i++ {
+		x := float64(i)
+		ds.X, ds.Y = append(ds.X, x), append(ds.Y, 3+0.5*x+newError())
+	}
+	band, err := BootstrapBand(ds, 0.9, []float64{7}, DefaultBootstrapReplicates, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if above, below := band.Upper[0]-band.Y[0], band.Y[0]-band.Lower[0]; !(above > 1.3*below) {
+		t.Errorf("prediction band reaches %.3f above the fit and %.3f below; expected a longer upper tail", above, below)
+	}
+
+	// the band is for the fitted line, so coverage is judged over fresh observations at x = 7
+	covered := 0
+	for i := 0; i < 2000; i++ {
+		if y := 3 + 0.5*7 + newError(); y >= band.Lower[0] && y <= band.Upper[0] {
+			covered++
+		}
+	}
+	if c := float64(covered) / 2000; c < 0.8 || c > 0.99 {
+		t.Errorf("90%% prediction band covered %.3f of new observations", c)
+	}
+}
+
+// ✅ Test 3: Bad input is rejected, and the HTML report labels bootstrap bands
+func TestBootstrapBandErrors(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	weighted := ds
+	weighted.Weights = make([]float64, len(ds.X))
+	for name, err := range map[string]error{
+		"level":      func() error { _, err := BootstrapBand(ds, 1, []float64{5}, 100, 1); return err }(),
+		"replicates": func() error { _, err := BootstrapBand(ds, 0.9, []float64{5}, 1, 1); return err }(),
+		"weights":    func() error { _, err := BootstrapBand(weighted, 0.9, []float64{5}, 100, 1); return err }(),
+		"two points": func() error {
+			_, err := BootstrapBand(Dataset{X: []float64{0, 1}, Y: []float64{1, 3}}, 0.9, []float64{5}, 100, 1)
+			return err
+		}(),
+	} {
+		if err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	if m, err := ParseIntervalMethod(""); err != nil || m != IntervalT {
+		t.Errorf("empty method parsed as %q, %v", m, err)
+	}
+	if _, err := ParseIntervalMethod("jackknife"); err == nil {
+		t.Error("expected an unknown method to be rejected")
+	}
+
+	// Anscombe's IV has a point of leverage 1, whose residual cannot be rescaled
+	if _, err := FitBootstrapBand(LoadAnscombeDatasets()["IV"], 0.95, 10, 200, 1); err != nil {
+		t.Errorf("dataset IV: %v", err)
+	}
+
+	result, _ := AnalyzeDataset("I", ds)
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, "Report", []HTMLReportDataset{{Name: "I", Data: ds, Result: result}}, HTMLReportOptions{Intervals: IntervalBootstrap}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "95% bootstrap confidence and prediction bands") || strings.Count(buf.String(), "<polygon") != 2 {
+		t.Error("expected the scatter plot to be drawn with bootstrap bands")
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BuiltinCollection is a named group of example datasets selectable with `-builtin`
+type BuiltinCollection struct {
+	Name        string
+	Title       string
+	Description string
+	Load        func() (map[string]Dataset, error)
+	// Reference, when set, returns the values the report checks the collection's fits against
+	// (see CheckConformance)
+	Reference func() Reference
+}
+
+// builtinCollections lists the collections in the order they are documented
+var builtinCollections = []BuiltinCollection{
+	{
+		Name:        "anscombe",
+		Title:       "Anscombe Quartet",
+		Description: "Anscombe's four datasets with identical summary statistics (1973)",
+		Load:        func() (map[string]Dataset, error) { return LoadAnscombeDatasets(), nil },
+		Reference:   AnscombeReference,
+	},
+	{
+		Name:        "datasaurus",
+		Title:       "Datasaurus Dozen",
+		Description: "Matejka & Fitzmaurice's thirteen datasets with near-identical statistics (2017), downloaded on first use",
+		Load:        LoadDatasaurusDozen,
+	},
+}
+
+// LookupBuiltin finds a built-in collection by name
+func LookupBuiltin(name string) (BuiltinCollection, bool) {
+	for _, c := range builtinCollections {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return BuiltinCollection{}, false
+}
+
+// builtinNames lists the collection names for usage messages
+func builtinNames() string {
+	names := make([]string, 0, len(builtinCollections))
+	for _, c := range builtinCollections {
+		names = append(names, c.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// datasaurusURL is the long-format TSV shipped with the datasauRus R package. It follows the
+// repository's main branch, so every copy is checked with checkDatasaurus before it is used.
+const datasaurusURL = "https://raw.githubusercontent.com/jumpingrivers/datasauRus/main/inst/extdata/DatasaurusDozen-Long.tsv"
+
+// datasaurusNames are the thirteen datasets of the Datasaurus Dozen
+var datasaurusNames = []string{"away", "bullseye", "circle", "dino", "dots", "h_lines", "high_lines",
+	"slant_down", "slant_up", "star", "v_lines", "wide_lines", "x_shape"}
+
+// LoadDatasaurusDozen returns the Datasaurus Dozen keyed by dataset name (dino, star, ...).
+// The data is downloaded once from datasaurusURL and kept in the user cache directory;
+// set DATASAURUS_PATH to read a local copy of the TSV instead. Whichever file is read must hold
+// the published collection (see checkDatasaurus), so a changed upstream file or a damaged copy is
+// reported rather than analyzed.
+func LoadDatasaurusDozen() (map[string]Dataset, error) {
+	path := os.Getenv("DATASAURUS_PATH")
+	if path == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, err
+		}
+		path = filepath.Join(dir, "ai_assitance_go", "DatasaurusDozen-Long.tsv")
+		if err := downloadOnce(datasaurusURL, path, readDatasaurus); err != nil {
+			return nil, fmt.Errorf("fetching Datasaurus Dozen: %w", err)
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	datasets, err := readDatasaurus(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return datasets, nil
+}
+
+// readDatasaurus parses the long-format TSV and checks that it is the Datasaurus Dozen
+func readDatasaurus(r io.Reader) (map[string]Dataset, error) {
+	datasets, err := parseLongTSV(r)
+	if err != nil {
+		return nil, err
+	}
+	return datasets, checkDatasaurus(datasets)
+}
+
+// checkDatasaurus verifies datasets against the published description of the Datasaurus Dozen
+// (Matejka & Fitzmaurice 2017): thirteen named datasets of 142 points, each with X mean 54.26,
+// Y mean 47.83, X SD 16.76, Y SD 26.93 and a correlation between -0.06 and -0.07. Identical
+// statistics are the whole point of the collection, so any edit to the data shows up here.
+func checkDatasaurus(datasets map[string]Dataset) error {
+	if len(datasets) != len(datasaurusNames) {
+		return fmt.Errorf("expected the %d Datasaurus datasets, found %d", len(datasaurusNames), len(datasets))
+	}
+	for _, name := range datasaurusNames {
+		ds, ok := datasets[name]
+		if !ok {
+			return fmt.Errorf("Datasaurus dataset %s is missing", name)
+		}
+		if len(ds.X) != 142 {
+			return fmt.Errorf("Datasaurus dataset %s has %d points, expected 142", name, len(ds.X))
+		}
+		meanX, varX := meanVariance(ds.X)
+		meanY, varY := meanVariance(ds.Y)
+		var sxy float64
+		for i := range ds.X {
+			sxy += (ds.X[i] - meanX) * (ds.Y[i] - meanY)
+		}
+		sdX, sdY := math.Sqrt(varX), math.Sqrt(varY)
+		corr := sxy / float64(len(ds.X)-1) / (sdX * sdY)
+		if math.Abs(meanX-54.26) > 0.01 || math.Abs(meanY-47.83) > 0.01 || math.Abs(sdX-16.76) > 0.02 ||
+			math.Abs(sdY-26.93) > 0.02 || corr > -0.06 || corr < -0.07 {
+			return fmt.Errorf("Datasaurus dataset %s does not have the published statistics "+
+				"(X mean %.2f, Y mean %.2f, X SD %.2f, Y SD %.2f, correlation %.3f)", name, meanX, meanY, sdX, sdY, corr)
+		}
+	}
+	return nil
+}
+
+// downloadOnce fetches url into path unless the file already exists. The download is only kept
+// when check accepts it.
+func downloadOnce(url, path string, check func(io.Reader) (map[string]Dataset, error)) error {
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	// write to a temporary file first so an interrupted download is never mistaken for the data
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	downloaded, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	_, err = check(downloaded)
+	downloaded.Close()
+	if err != nil {
+		return fmt.Errorf("GET %s: %w", url, err)
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// parseLongTSV reads "dataset<TAB>x<TAB>y" rows (with a header) into datasets keyed by name
+func parseLongTSV(r io.Reader) (map[string]Dataset, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = '\t'
+	reader.FieldsPerRecord = 3
+
+	datasets := map[string]Dataset{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading TSV: %w", err)
+		}
+		line++
+
+		x, errX := parseCSVFloat(record[1])
+		y, errY := parseCSVFloat(record[2])
+		if errX != nil || errY != nil {
+			if line == 1 {
+				// header row
+				continue
+			}
+			return nil, fmt.Errorf("line %d: non-numeric value in %q", line, strings.Join(record, "\t"))
+		}
+
+		ds := datasets[record[0]]
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, y)
+		datasets[record[0]] = ds
+	}
+
+	if len(datasets) == 0 {
+		return nil, fmt.Errorf("no data rows found")
+	}
+	return datasets, nil
+}
+
+// datasetJobs returns a collection as jobs sorted by dataset name
+func datasetJobs(datasets map[string]Dataset) []AnalysisJob {
+	names := make([]string, 0, len(datasets))
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	jobs := make([]AnalysisJob, 0, len(names))
+	for _, name := range names {
+		jobs = append(jobs, AnalysisJob{Name: name, Data: datasets[name]})
+	}
+	return jobs
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// small fixture in the layout of DatasaurusDozen-Long.tsv (not the real data)
+const longTSVFixture = "dataset\tx\ty\ndino\t1\t2\ndino\t2\t4\ndino\t3\t6\nstar\t1\t5\nstar\t2\t4\nstar\t3\t3\n"
+
+// datasaurusFixture is random data in the layout of DatasaurusDozen-Long.tsv, scaled to the
+// published statistics of every dataset so that checkDatasaurus accepts it
+func datasaurusFixture() string {
+	rng := rand.New(rand.NewPCG(9, 10))
+	standardize := func(v []float64) {
+		mean, variance := meanVariance(v)
+		for i := range v {
+			v[i] = (v[i] - mean) / math.Sqrt(variance)
+		}
+	}
+	var b strings.Builder
+	b.WriteString("dataset\tx\ty\n")
+	for _, name := range datasaurusNames {
+		zx, ze := make([]float64, 142), make([]float64, 142)
+		for i := range zx {
+			zx[i], ze[i] = rng.NormFloat64(), rng.NormFloat64()
+		}
+		standardize(zx)
+		// make the noise uncorrelated with X, so the correlation comes out exactly as chosen
+		var dot float64
+		for i := range zx {
+			dot += zx[i] * ze[i]
+		}
+		for i := range ze {
+			ze[i] -= dot / float64(len(zx)-1) * zx[i]
+		}
+		standardize(ze)
+		const r = -0.065
+		for i := range zx {
+			fmt.Fprintf(&b, "%s\t%.12g\t%.12g\n", name, 54.26+16.76*zx[i], 47.83+26.93*(r*zx[i]+math.Sqrt(1-r*r)*ze[i]))
+		}
+	}
+	return b.String()
+}
+
+// ✅ Test 1: Long-format TSV is split into one dataset per name
+func TestParseLongTSV(t *testing.T) {
+	datasets, err := parseLongTSV(strings.NewReader(longTSVFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(datasets) != 2 || len(datasets["dino"].X) != 3 || datasets["star"].Y[2] != 3 {
+		t.Errorf("unexpected datasets %+v", datasets)
+	}
+
+	jobs := datasetJobs(datasets)
+	if len(jobs) != 2 || jobs[0].Name != "dino" || jobs[1].Name != "star" {
+		t.Errorf("expected jobs sorted by name, got %+v", jobs)
+	}
+
+	if _, err := parseLongTSV(strings.NewReader("dataset\tx\ty\ndino\t1\tfoo\n")); err == nil {
+		t.Error("expected non-numeric value error")
+	}
+}
+
+// ✅ Test 2: The Datasaurus loader reads DATASAURUS_PATH, rejects data that is not the collection,
+// and the registry exposes both collections
+func TestLoadDatasaurusDozenFromPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dd.tsv")
+	os.WriteFile(path, []byte(datasaurusFixture()), 0o644)
+	t.Setenv("DATASAURUS_PATH", path)
+
+	collection, ok := LookupBuiltin("datasaurus")
+	if !ok {
+		t.Fatal("datasaurus collection not registered")
+	}
+	datasets, err := collection.Load()
+	if err != nil || len(datasets) != 13 || len(datasets["dino"].X) != 142 {
+		t.Errorf("got %d datasets, err %v", len(datasets), err)
+	}
+
+	// a moved point changes the statistics, and a partial file lacks datasets
+	edited := strings.Replace(datasaurusFixture(), "\nstar\t", "\nstar\t1", 1)
+	for name, content := range map[string]string{"edited": edited, "partial": longTSVFixture} {
+		os.WriteFile(path, []byte(content), 0o644)
+		if _, err := collection.Load(); err == nil {
+			t.Errorf("%s: expected the file to be rejected", name)
+		}
+	}
+
+	anscombe, _ := LookupBuiltin("anscombe")
+	if datasets, _ := anscombe.Load(); len(datasets) != 4 {
+		t.Errorf("expected the quartet, got %d datasets", len(datasets))
+	}
+	// only the quartet is checked against reference values
+	if anscombe.Reference == nil || len(anscombe.Reference().Datasets) != 4 || collection.Reference != nil {
+		t.Error("expected a reference for the quartet only")
+	}
+	if _, ok := LookupBuiltin("nope"); ok {
+		t.Error("unknown collection should not be found")
+	}
+}
+
+// ✅ Test 3: Downloads happen once and are reused from disk, and unverified downloads are not kept
+func TestDownloadOnce(t *testing.T) {
+	requests := 0
+	body := datasaurusFixture()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "sub", "dd.tsv")
+	for i := 0; i < 2; i++ {
+		if err := downloadOnce(srv.URL, path, readDatasaurus); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("expected a single download, got %d", requests)
+	}
+	if raw, _ := os.ReadFile(path); string(raw) != body {
+		t.Errorf("unexpected file content %q", raw)
+	}
+
+	body = longTSVFixture
+	other := filepath.Join(filepath.Dir(path), "other.tsv")
+	if err := downloadOnce(srv.URL, other, readDatasaurus); err == nil {
+		t.Error("expected a download that is not the collection to be rejected")
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(path)); len(entries) != 1 {
+		t.Errorf("rejected download left files behind: %v", entries)
+	}
+}
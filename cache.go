@@ -0,0 +1,161 @@
+package main
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// defaultCacheSize is the number of fitted results kept by the analysis cache
+const defaultCacheSize = 256
+
+// DatasetFingerprint hashes the exact float values of data, its weights when it has them,
+// together with the options that influence the fit, so a cached result is only reused for
+// identical input. Unweighted data hashes as it did before weights existed.
+func DatasetFingerprint(data Dataset, options string) string {
+	h := sha256.New()
+	var buf [8]byte
+	writeFloats := func(values []float64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(len(values)))
+		h.Write(buf[:])
+		for _, v := range values {
+			binary.LittleEndian.PutUint64(buf[:], math.Float64bits(v))
+			h.Write(buf[:])
+		}
+	}
+	writeFloats(data.X)
+	writeFloats(data.Y)
+	if data.Weights != nil {
+		// weights change the fit, and all-ones weights are still a weighted fit
+		h.Write([]byte("weights"))
+		writeFloats(data.Weights)
+	}
+	h.Write([]byte(options))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Fingerprint is a stable content hash of the dataset's values and weights, recorded in results and
+// reports so a published result can be tied to the exact data that produced it. It does not depend
+// on the name, axes, labels or tags.
+func (d Dataset) Fingerprint() string {
+	return "sha256:" + DatasetFingerprint(d, "")
+}
+
+// ResultCache is a size-limited LRU of fitted results keyed by DatasetFingerprint.
+// It is safe for concurrent use by the batch workers.
+type ResultCache struct {
+	capacity int
+
+	mu      sync.Mutex
+	order   *list.List // front is most recently used
+	entries map[string]*list.Element
+}
+
+type cacheEntry struct {
+	Key    string           `json:"key"`
+	Result RegressionResult `json:"result"`
+}
+
+// NewResultCache creates a cache holding at most capacity results
+func NewResultCache(capacity int) *ResultCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &ResultCache{capacity: capacity, order: list.New(), entries: map[string]*list.Element{}}
+}
+
+// Get returns the cached result for key and marks it as recently used
+func (c *ResultCache) Get(key string) (RegressionResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return RegressionResult{}, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).Result, true
+}
+
+// Put stores a result, evicting the least recently used entry when the cache is full
+func (c *ResultCache) Put(key string, result RegressionResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cacheEntry).Result = result
+		c.order.MoveToFront(elem)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&cacheEntry{Key: key, Result: result})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).Key)
+	}
+}
+
+// Len returns the number of cached results
+func (c *ResultCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Load reads entries saved by Save. A missing file leaves the cache empty.
+func (c *ResultCache) Load(path string) error {
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var entries []cacheEntry
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return err
+	}
+	// entries are saved most recent first, so insert oldest first to restore the order
+	for i := len(entries) - 1; i >= 0; i-- {
+		c.Put(entries[i].Key, entries[i].Result)
+	}
+	return nil
+}
+
+// Save writes the cache to path, most recently used entries first
+func (c *ResultCache) Save(path string) error {
+	c.mu.Lock()
+	entries := make([]cacheEntry, 0, c.order.Len())
+	for elem := c.order.Front(); elem != nil; elem = elem.Next() {
+		entries = append(entries, *elem.Value.(*cacheEntry))
+	}
+	c.mu.Unlock()
+
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// defaultCachePath is where the analysis command persists its cache between runs
+func defaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "ai_assitance_go", "results.json"), nil
+}
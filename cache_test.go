@@ -0,0 +1,130 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ✅ Test 1: Fingerprints depend on every value and on the options
+func TestDatasetFingerprint(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 3}, Y: []float64{2, 4, 6}}
+	base := DatasetFingerprint(data, "stats")
+	if base != DatasetFingerprint(Dataset{X: []float64{1, 2, 3}, Y: []float64{2, 4, 6}}, "stats") {
+		t.Error("identical data should have identical fingerprints")
+	}
+	if base == DatasetFingerprint(Dataset{X: []float64{1, 2, 3}, Y: []float64{2, 4, 6.0000001}}, "stats") {
+		t.Error("changed value should change the fingerprint")
+	}
+	if base == DatasetFingerprint(data, "manual") {
+		t.Error("changed options should change the fingerprint")
+	}
+	// moving a value between X and Y must not collide
+	if DatasetFingerprint(Dataset{X: []float64{1, 2}, Y: []float64{3}}, "") == DatasetFingerprint(Dataset{X: []float64{1}, Y: []float64{2, 3}}, "") {
+		t.Error("fingerprint should encode slice boundaries")
+	}
+	// a weighted fit is a different fit of the same points
+	weighted := data
+	weighted.Weights = []float64{1, 1, 1}
+	if base == DatasetFingerprint(weighted, "stats") {
+		t.Error("weights should change the fingerprint")
+	}
+	if data.Fingerprint() == weighted.Fingerprint() {
+		t.Error("weights should change the dataset fingerprint")
+	}
+}
+
+// ✅ Test 2: LRU eviction and persistence keep the most recently used entries
+func TestResultCacheLRU(t *testing.T) {
+	c := NewResultCache(2)
+	c.Put("a", RegressionResult{Slope: 1})
+	c.Put("b", RegressionResult{Slope: 2})
+	c.Get("a")
+	c.Put("c", RegressionResult{Slope: 3})
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("least recently used entry should have been evicted")
+	}
+	if r, ok := c.Get("a"); !ok || r.Slope != 1 {
+		t.Errorf("expected a to survive, got %+v %v", r, ok)
+	}
+
+	path := filepath.Join(t.TempDir(), "cache", "results.json")
+	if err := c.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewResultCache(2)
+	if err := loaded.Load(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Put("d", RegressionResult{Slope: 4})
+	// a was used last before saving, so c is the one evicted after reload
+	if _, ok := loaded.Get("c"); ok || loaded.Len() != 2 {
+		t.Errorf("recency order not restored: len %d", loaded.Len())
+	}
+	if err := NewResultCache(1).Load(filepath.Join(t.TempDir(), "missing.json")); err != nil {
+		t.Errorf("missing cache file should not be an error: %v", err)
+	}
+}
+
+// ✅ Test 3: Batch analysis reuses results for unchanged files and refits changed ones
+func TestAnalyzeAllWithCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "line.csv")
+	os.WriteFile(path, []byte("x,y\n1,2\n2,4\n3,6\n"), 0o644)
+	copyPath := filepath.Join(dir, "copy.csv")
+	os.WriteFile(copyPath, []byte("x,y\n1,2\n2,4\n3,6\n"), 0o644)
+
+	cache := NewResultCache(8)
+	first := AnalyzeAllWithCache(fileJobs([]string{path}), 1, cache)
+	if first[0].Err != nil || first[0].Cached {
+		t.Fatalf("first run should fit: %+v", first[0])
+	}
+
+	second := AnalyzeAllWithCache(fileJobs([]string{path, copyPath}), 1, cache)
+	for _, o := range second {
+		if !o.Cached || o.Result.Slope != first[0].Result.Slope {
+			t.Errorf("%s should be served from cache: %+v", o.Name, o)
+		}
+	}
+	if second[1].Result.Dataset != "copy" {
+		t.Errorf("cached result should carry the job name, got %q", second[1].Result.Dataset)
+	}
+
+	os.WriteFile(path, []byte("x,y\n1,1\n2,4\n3,9\n"), 0o644)
+	third := AnalyzeAllWithCache(fileJobs([]string{path}), 1, cache)
+	if third[0].Cached || third[0].Result.Slope == first[0].Result.Slope {
+		t.Errorf("changed file should be refitted: %+v", third[0])
+	}
+
+	// weighting the same points is a different fit, whichever run comes first
+	points := Dataset{X: []float64{1, 2, 3, 4}, Y: []float64{1, 3, 2, 5}}
+	weighted := points
+	weighted.Weights = []float64{1, 0.5, 1.0 / 3, 0.25}
+	fits := AnalyzeAllWithCache([]AnalysisJob{{Name: "weighted", Data: weighted}}, 1, cache)
+	fits = append(fits, AnalyzeAllWithCache([]AnalysisJob{{Name: "plain", Data: points}}, 1, cache)...)
+	if fits[1].Cached || fits[1].Result.Slope == fits[0].Result.Slope {
+		t.Errorf("unweighted fit reused the weighted result: %+v", fits[1])
+	}
+	if again := AnalyzeAllWithCache([]AnalysisJob{{Name: "plain", Data: points}}, 1, cache)[0]; !again.Result.Cached || again.Result.Duration != fits[1].Result.Duration {
+		t.Errorf("reused result should be marked cached with its original time: %+v", again.Result)
+	}
+}
+
+// ✅ Test 4: Results carry a stable fingerprint of their data
+func TestResultFingerprint(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	a, _ := AnalyzeDataset("first", data)
+	b, _ := AnalyzeDataset("second", Dataset{X: append([]float64(nil), data.X...), Y: append([]float64(nil), data.Y...)})
+	if a.Fingerprint == "" || a.Fingerprint != b.Fingerprint || a.Fingerprint != data.Fingerprint() {
+		t.Errorf("fingerprints should depend only on the values: %q vs %q", a.Fingerprint, b.Fingerprint)
+	}
+	if len(a.Fingerprint) != len("sha256:")+64 {
+		t.Errorf("unexpected fingerprint format %q", a.Fingerprint)
+	}
+
+	other, _ := AnalyzeDataset("first", LoadAnscombeDatasets()["II"])
+	if other.Fingerprint == a.Fingerprint {
+		t.Error("different data should have different fingerprints")
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+)
+
+// CategoricalEncoding records how a string-valued column was dummy-encoded. Levels are sorted;
+// Levels[0] is the baseline and every other level gets a 0/1 predictor named "column=level",
+// so its coefficient is the difference from the baseline.
+type CategoricalEncoding struct {
+	Column string   `json:"column"`
+	Levels []string `json:"levels"`
+}
+
+// Baseline returns the level absorbed into the intercept
+func (e CategoricalEncoding) Baseline() string {
+	return e.Levels[0]
+}
+
+// Names returns the predictor names of the dummy columns, one per non-baseline level
+func (e CategoricalEncoding) Names() []string {
+	names := make([]string, 0, len(e.Levels)-1)
+	for _, level := range e.Levels[1:] {
+		names = append(names, e.Column+"="+level)
+	}
+	return names
+}
+
+// Encode returns the dummy values of one observation, so new data can be encoded exactly as the
+// training data was. Missing values encode as NaN; unseen levels are an error.
+func (e CategoricalEncoding) Encode(value string) ([]float64, error) {
+	dummies := make([]float64, len(e.Levels)-1)
+	value = strings.TrimSpace(value)
+	if isMissingField(value) {
+		for i := range dummies {
+			dummies[i] = math.NaN()
+		}
+		return dummies, nil
+	}
+	i := sort.SearchStrings(e.Levels, value)
+	if i == len(e.Levels) || e.Levels[i] != value {
+		return nil, fmt.Errorf("column %s: unknown level %q", e.Column, value)
+	}
+	if i > 0 {
+		dummies[i-1] = 1
+	}
+	return dummies, nil
+}
+
+// LoadMultiCSV reads a CSV stream with a header row into a MultiDataset. target names the response
+// column (the last column when empty); every other column is a predictor. Columns whose values are
+// all numeric (or missing) are used as-is, and any other column is dummy-encoded, with the mapping
+// kept in MultiDataset.Encodings.
+func LoadMultiCSV(r io.Reader, target string) (MultiDataset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return MultiDataset{}, fmt.Errorf("no header row found")
+	}
+	if err != nil {
+		return MultiDataset{}, fmt.Errorf("reading CSV: %w", err)
+	}
+	records, err := reader.ReadAll()
+	if err != nil {
+		return MultiDataset{}, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return MultiDataset{}, fmt.Errorf("no data rows found")
+	}
+	if len(header) < 2 {
+		return MultiDataset{}, fmt.Errorf("need a response and at least one predictor column, got %d columns", len(header))
+	}
+
+	yCol := len(header) - 1
+	if target != "" {
+		yCol = -1
+		for i, name := range header {
+			if strings.EqualFold(strings.TrimSpace(name), target) {
+				yCol = i
+			}
+		}
+		if yCol < 0 {
+			return MultiDataset{}, fmt.Errorf("response column %q not found in header", target)
+		}
+	}
+
+	var md MultiDataset
+	encoders := make([]func(string) ([]float64, error), 0, len(header)-1)
+	columns := make([]int, 0, len(header)-1)
+	for c, name := range header {
+		if c == yCol {
+			continue
+		}
+		name = strings.TrimSpace(name)
+		columns = append(columns, c)
+		if enc, ok := categoricalColumn(name, records, c); ok {
+			md.Encodings = append(md.Encodings, enc)
+			md.Names = append(md.Names, enc.Names()...)
+			encoders = append(encoders, enc.Encode)
+			continue
+		}
+		md.Names = append(md.Names, name)
+		encoders = append(encoders, func(field string) ([]float64, error) {
+			v, err := parseCSVFloat(field)
+			return []float64{v}, err
+		})
+	}
+
+	for i, record := range records {
+		line := i + 2
+		y, err := parseCSVFloat(record[yCol])
+		if err != nil {
+			return MultiDataset{}, fmt.Errorf("line %d: non-numeric response %q", line, record[yCol])
+		}
+		row := make([]float64, 0, len(md.Names))
+		for k, c := range columns {
+			values, err := encoders[k](record[c])
+			if err != nil {
+				return MultiDataset{}, fmt.Errorf("line %d: %w", line, err)
+			}
+			row = append(row, values...)
+		}
+		md.X = append(md.X, row)
+		md.Y = append(md.Y, y)
+	}
+	return md, nil
+}
+
+// LoadMultiCSVFile opens path and loads it with LoadMultiCSV
+func LoadMultiCSVFile(path, target string) (MultiDataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return MultiDataset{}, err
+	}
+	defer f.Close()
+
+	md, err := LoadMultiCSV(f, target)
+	if err != nil {
+		return MultiDataset{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return md, nil
+}
+
+// categoricalColumn returns the encoding of column c when any of its values is not numeric
+func categoricalColumn(name string, records [][]string, c int) (CategoricalEncoding, bool) {
+	numeric := true
+	seen := map[string]bool{}
+	for _, record := range records {
+		field := strings.TrimSpace(record[c])
+		if _, err := parseCSVFloat(field); err != nil {
+			numeric = false
+		}
+		if !isMissingField(field) {
+			seen[field] = true
+		}
+	}
+	if numeric {
+		return CategoricalEncoding{}, false
+	}
+	enc := CategoricalEncoding{Column: name}
+	for level := range seen {
+		enc.Levels = append(enc.Levels, level)
+	}
+	sort.Strings(enc.Levels)
+	return enc, true
+}
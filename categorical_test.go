@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const categoricalCSV = `size,color,price
+1,red,12
+2,blue,14
+3,green,21
+4,red,18
+5,blue,20
+6,green,27
+7,red,24
+8,NA,30
+`
+
+// ✅ Test 1: String columns are dummy-encoded against the first sorted level
+func TestLoadMultiCSVCategorical(t *testing.T) {
+	md, err := LoadMultiCSV(strings.NewReader(categoricalCSV), "price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(md.Names, []string{"size", "color=green", "color=red"}) || len(md.Encodings) != 1 {
+		t.Fatalf("unexpected columns %v %+v", md.Names, md.Encodings)
+	}
+	if enc := md.Encodings[0]; enc.Baseline() != "blue" || !slices.Equal(md.X[0], []float64{1, 0, 1}) {
+		t.Errorf("unexpected encoding %+v, first row %v", enc, md.X[0])
+	}
+
+	// price = 10 + 2*size, plus 5 for green; red costs the same as blue
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{10, 2, 5, 0}
+	for j, c := range fit.Coefficients {
+		if math.Abs(c-want[j]) > 1e-9 {
+			t.Errorf("coefficient %d: expected %v, got %v", j, want[j], c)
+		}
+	}
+	if fit.N != 7 {
+		t.Errorf("the row with a missing color should be dropped, used %d rows", fit.N)
+	}
+
+	var out bytes.Buffer
+	PrintMultiFit(&out, fit)
+	if !strings.Contains(out.String(), "color=green") || !strings.Contains(out.String(), "vs blue") {
+		t.Errorf("report does not name levels against the baseline:\n%s", out.String())
+	}
+}
+
+// ✅ Test 2: Encoding new values reuses the training levels
+func TestCategoricalEncode(t *testing.T) {
+	enc := CategoricalEncoding{Column: "color", Levels: []string{"blue", "green", "red"}}
+	if d, err := enc.Encode("red"); err != nil || !slices.Equal(d, []float64{0, 1}) {
+		t.Errorf("red encoded as %v (err %v)", d, err)
+	}
+	if d, _ := enc.Encode("blue"); !slices.Equal(d, []float64{0, 0}) {
+		t.Errorf("baseline encoded as %v", d)
+	}
+	if _, err := enc.Encode("purple"); err == nil {
+		t.Error("expected an unknown level error")
+	}
+	if _, err := LoadMultiCSV(strings.NewReader(categoricalCSV), "weight"); err == nil {
+		t.Error("expected a missing response column error")
+	}
+}
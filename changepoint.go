@@ -0,0 +1,218 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// ChangePointOptions configures DetectChangePoints
+type ChangePointOptions struct {
+	// MinSegment is the fewest points a segment may have; 5 when 0, and at least 3
+	MinSegment int
+	// MaxChanges caps the number of change points; 0 means no cap
+	MaxChanges int
+	// Penalty is the drop in residual sum of squares a split must achieve. When 0 it is the BIC-style
+	// 3 σ² ln n, with the noise variance σ² estimated robustly from second differences of Y.
+	Penalty float64
+}
+
+// SegmentFit is the line fitted to one segment between change points. Start and End index the
+// points sorted by X (End exclusive).
+type SegmentFit struct {
+	Start     int     `json:"start"`
+	End       int     `json:"end"`
+	XMin      float64 `json:"xMin"`
+	XMax      float64 `json:"xMax"`
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+}
+
+// ChangePoints is the result of DetectChangePoints. Breaks[i] is the X midway between Segments[i]
+// and Segments[i+1].
+type ChangePoints struct {
+	Breaks   []float64    `json:"breaks"`
+	Segments []SegmentFit `json:"segments"`
+	Penalty  float64      `json:"penalty"`
+}
+
+// DetectChangePoints finds where the linear relationship between X and Y shifts, by binary
+// segmentation: the segment whose best split into two separately fitted lines most reduces the
+// residual sum of squares is split, as long as the reduction exceeds the penalty. Points are
+// taken in X order; pairs with NaN or Inf are dropped.
+func DetectChangePoints(ds Dataset, opts ChangePointOptions) (ChangePoints, error) {
+	x, y, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return ChangePoints{}, err
+	}
+	minSegment := opts.MinSegment
+	if minSegment == 0 {
+		minSegment = 5
+	}
+	if minSegment < 3 {
+		return ChangePoints{}, fmt.Errorf("minimum segment must be at least 3 points, got %d", minSegment)
+	}
+	if opts.MaxChanges < 0 || opts.Penalty < 0 {
+		return ChangePoints{}, fmt.Errorf("maximum changes and penalty must not be negative")
+	}
+	n := len(x)
+	if n < minSegment {
+		return ChangePoints{}, fmt.Errorf("need at least %d complete points, have %d", minSegment, n)
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return x[order[a]] < x[order[b]] })
+	sx, sy := make([]float64, n), make([]float64, n)
+	for k, i := range order {
+		sx[k], sy[k] = x[i], y[i]
+	}
+
+	costs := newSegmentCosts(sx, sy)
+	penalty := opts.Penalty
+	if penalty == 0 {
+		sigma := secondDifferenceSigma(sy)
+		penalty = 3 * sigma * sigma * math.Log(float64(n))
+	}
+	// splits must also beat rounding noise, or exact piecewise lines would split everywhere
+	floor := 1e-9 * costs.cost(0, n)
+
+	type split struct {
+		at   int
+		gain float64
+	}
+	bestSplit := func(start, end int) split {
+		best := split{at: -1}
+		whole := costs.cost(start, end)
+		for at := start + minSegment; at <= end-minSegment; at++ {
+			// points sharing an X value stay in the same segment
+			if sx[at] == sx[at-1] {
+				continue
+			}
+			if gain := whole - costs.cost(start, at) - costs.cost(at, end); best.at < 0 || gain > best.gain {
+				best = split{at, gain}
+			}
+		}
+		return best
+	}
+
+	bounds := []int{0, n}
+	for opts.MaxChanges == 0 || len(bounds)-2 < opts.MaxChanges {
+		chosen := split{at: -1}
+		for s := 0; s+1 < len(bounds); s++ {
+			if candidate := bestSplit(bounds[s], bounds[s+1]); candidate.at >= 0 && (chosen.at < 0 || candidate.gain > chosen.gain) {
+				chosen = candidate
+			}
+		}
+		if chosen.at < 0 || chosen.gain <= penalty || chosen.gain <= floor {
+			break
+		}
+		bounds = append(bounds, chosen.at)
+		sort.Ints(bounds)
+	}
+
+	cp := ChangePoints{Penalty: penalty}
+	for s := 0; s+1 < len(bounds); s++ {
+		start, end := bounds[s], bounds[s+1]
+		slope, intercept, r2 := ManualRegression(sx[start:end], sy[start:end])
+		cp.Segments = append(cp.Segments, SegmentFit{
+			Start: start, End: end, XMin: sx[start], XMax: sx[end-1],
+			Slope: slope, Intercept: intercept, RSquared: r2,
+		})
+		if s > 0 {
+			cp.Breaks = append(cp.Breaks, (sx[start-1]+sx[start])/2)
+		}
+	}
+	return cp, nil
+}
+
+// segmentCosts gives the residual sum of squares of a line fitted to any run of points in O(1),
+// from prefix sums of the data centred on its means
+type segmentCosts struct {
+	x, y, xx, xy, yy []float64
+}
+
+func newSegmentCosts(x, y []float64) segmentCosts {
+	meanX, _ := meanVariance(x)
+	meanY, _ := meanVariance(y)
+	c := segmentCosts{
+		x: make([]float64, len(x)+1), y: make([]float64, len(x)+1),
+		xx: make([]float64, len(x)+1), xy: make([]float64, len(x)+1), yy: make([]float64, len(x)+1),
+	}
+	for i := range x {
+		dx, dy := x[i]-meanX, y[i]-meanY
+		c.x[i+1] = c.x[i] + dx
+		c.y[i+1] = c.y[i] + dy
+		c.xx[i+1] = c.xx[i] + dx*dx
+		c.xy[i+1] = c.xy[i] + dx*dy
+		c.yy[i+1] = c.yy[i] + dy*dy
+	}
+	return c
+}
+
+// cost is the residual sum of squares of points start to end-1 about their least-squares line
+func (c segmentCosts) cost(start, end int) float64 {
+	n := float64(end - start)
+	sx, sy := c.x[end]-c.x[start], c.y[end]-c.y[start]
+	sxx := c.xx[end] - c.xx[start] - sx*sx/n
+	sxy := c.xy[end] - c.xy[start] - sx*sy/n
+	syy := c.yy[end] - c.yy[start] - sy*sy/n
+	if sxx > 0 {
+		syy -= sxy * sxy / sxx
+	}
+	return math.Max(syy, 0)
+}
+
+// secondDifferenceSigma estimates the noise standard deviation of values ordered along a line:
+// second differences cancel a linear trend and have variance 6σ² for independent noise, and
+// their MAD keeps the estimate robust to the few differences that straddle a change
+func secondDifferenceSigma(values []float64) float64 {
+	if len(values) < 3 {
+		return 0
+	}
+	diffs := make([]float64, len(values)-2)
+	for i := range diffs {
+		diffs[i] = values[i+2] - 2*values[i+1] + values[i]
+	}
+	sort.Float64s(diffs)
+	median := quantileSorted(diffs, 0.5)
+	for i := range diffs {
+		diffs[i] = math.Abs(diffs[i] - median)
+	}
+	sort.Float64s(diffs)
+	return quantileSorted(diffs, 0.5) / 0.6744897501960817 / math.Sqrt(6)
+}
+
+// printChangePoints writes the change-point section of a dataset report
+func printChangePoints(w io.Writer, data Dataset, opts ChangePointOptions) {
+	cp, err := DetectChangePoints(data, opts)
+	if err != nil {
+		fmt.Fprintf(w, "  Change points: n/a (%v)\n", err)
+		return
+	}
+	if len(cp.Breaks) == 0 {
+		fmt.Fprintf(w, "  Change points: none\n")
+		return
+	}
+	fmt.Fprintf(w, "  Change points: %d (at x = %s)\n", len(cp.Breaks), formatFloats(cp.Breaks))
+	for _, s := range cp.Segments {
+		fmt.Fprintf(w, "    x in [%g, %g]: slope %.6f, intercept %.6f, R-squared %.6f (%d points)\n",
+			s.XMin, s.XMax, s.Slope, s.Intercept, s.RSquared, s.End-s.Start)
+	}
+}
+
+// formatFloats joins values with commas using the shortest representation
+func formatFloats(values []float64) string {
+	out := ""
+	for i, v := range values {
+		if i > 0 {
+			out += ", "
+		}
+		out += fmt.Sprintf("%g", v)
+	}
+	return out
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: A slope change in noisy data is found with a fit per segment
+func TestDetectChangePoints(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	var ds Dataset
+	for i := 0; i < 120; i++ {
+		x := float64(i)
+		y := 1 + 0.5*x
+		if x >= 60 {
+			y = 31 + 2*(x-60)
+		}
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, y+0.5*rng.NormFloat64())
+	}
+	// reversed input order must not matter
+	for i, j := 0, len(ds.X)-1; i < j; i, j = i+1, j-1 {
+		ds.X[i], ds.X[j] = ds.X[j], ds.X[i]
+		ds.Y[i], ds.Y[j] = ds.Y[j], ds.Y[i]
+	}
+
+	cp, err := DetectChangePoints(ds, ChangePointOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Breaks) != 1 || math.Abs(cp.Breaks[0]-59.5) > 2 {
+		t.Fatalf("breaks %v, want one near 59.5", cp.Breaks)
+	}
+	if math.Abs(cp.Segments[0].Slope-0.5) > 0.05 || math.Abs(cp.Segments[1].Slope-2) > 0.05 {
+		t.Errorf("segment slopes %v and %v", cp.Segments[0].Slope, cp.Segments[1].Slope)
+	}
+	if cp.Segments[0].Start != 0 || cp.Segments[1].End != 120 || cp.Segments[0].End != cp.Segments[1].Start {
+		t.Errorf("segments do not cover the data: %+v", cp.Segments)
+	}
+}
+
+// ✅ Test 2: A single noisy line has no change points
+func TestDetectChangePointsNone(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 6))
+	var ds Dataset
+	for i := 0; i < 200; i++ {
+		ds.X = append(ds.X, float64(i))
+		ds.Y = append(ds.Y, 3-0.2*float64(i)+rng.NormFloat64())
+	}
+	cp, err := DetectChangePoints(ds, ChangePointOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Breaks) != 0 || len(cp.Segments) != 1 {
+		t.Errorf("expected no breaks, got %v", cp.Breaks)
+	}
+}
+
+// ✅ Test 3: Exact piecewise lines split at the kink only, and MaxChanges caps the splits
+func TestDetectChangePointsExact(t *testing.T) {
+	var ds Dataset
+	for i := 0; i < 30; i++ {
+		x := float64(i)
+		y := x
+		if i >= 10 {
+			y = 10 + 3*(x-10)
+		}
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, y)
+	}
+	cp, err := DetectChangePoints(ds, ChangePointOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cp.Breaks) != 1 || cp.Breaks[0] != 9.5 || cp.Segments[1].Slope != 3 {
+		t.Errorf("breaks %v, want [9.5]", cp.Breaks)
+	}
+
+	for i := range ds.Y {
+		ds.Y[i] += math.Sin(float64(i))
+	}
+	if cp, _ := DetectChangePoints(ds, ChangePointOptions{MaxChanges: 1, Penalty: 1e-6}); len(cp.Breaks) != 1 {
+		t.Errorf("MaxChanges 1 gave %v", cp.Breaks)
+	}
+	if _, err := DetectChangePoints(ds, ChangePointOptions{MinSegment: 2}); err == nil {
+		t.Error("expected an error for a two-point minimum segment")
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"text/tabwriter"
+)
+
+// The summaries in this file judge a binary classifier from its scores: the predicted probability
+// of the positive class for each observation, with the observed labels. They make no assumption
+// about how the scores were fitted, so any probabilistic model can be summarized the same way.
+
+// ROCPoint is one threshold of a ROC curve: observations scoring at or above Threshold are
+// classified positive
+type ROCPoint struct {
+	Threshold         float64 `json:"threshold"`
+	TruePositiveRate  float64 `json:"truePositiveRate"`
+	FalsePositiveRate float64 `json:"falsePositiveRate"`
+}
+
+// ROCCurve is a classifier's receiver operating characteristic: its true and false positive rates
+// at every distinct score, from the highest threshold (nothing positive) to the lowest (everything
+// positive), and the area under it
+type ROCCurve struct {
+	Points    []ROCPoint `json:"points"`
+	AUC       float64    `json:"auc"`
+	Positives int        `json:"positives"`
+	Negatives int        `json:"negatives"`
+}
+
+// ROC computes the ROC curve of scores against labels. The AUC is the probability that a random
+// positive scores above a random negative, counting ties as half, which is the area under the
+// curve with tied scores joined by straight segments. Observations with a NaN score are dropped;
+// both classes must be present.
+func ROC(scores []float64, labels []bool) (ROCCurve, error) {
+	order, err := classifierOrder(scores, labels)
+	if err != nil {
+		return ROCCurve{}, err
+	}
+	var curve ROCCurve
+	for _, i := range order {
+		if labels[i] {
+			curve.Positives++
+		} else {
+			curve.Negatives++
+		}
+	}
+	if curve.Positives == 0 || curve.Negatives == 0 {
+		return ROCCurve{}, fmt.Errorf("need both positive and negative labels (have %d and %d)", curve.Positives, curve.Negatives)
+	}
+
+	pos, neg := float64(curve.Positives), float64(curve.Negatives)
+	curve.Points = append(curve.Points, ROCPoint{Threshold: math.Inf(1)})
+	var tp, fp float64
+	for k := 0; k < len(order); {
+		threshold := scores[order[k]]
+		for ; k < len(order) && scores[order[k]] == threshold; k++ {
+			if labels[order[k]] {
+				tp++
+			} else {
+				fp++
+			}
+		}
+		prev := curve.Points[len(curve.Points)-1]
+		point := ROCPoint{Threshold: threshold, TruePositiveRate: tp / pos, FalsePositiveRate: fp / neg}
+		curve.AUC += (point.FalsePositiveRate - prev.FalsePositiveRate) * (point.TruePositiveRate + prev.TruePositiveRate) / 2
+		curve.Points = append(curve.Points, point)
+	}
+	return curve, nil
+}
+
+// classifierOrder checks scores and labels and returns the indexes of the scored observations by
+// descending score
+func classifierOrder(scores []float64, labels []bool) ([]int, error) {
+	if len(scores) != len(labels) {
+		return nil, fmt.Errorf("scores and labels length mismatch: %d vs %d", len(scores), len(labels))
+	}
+	order := make([]int, 0, len(scores))
+	for i, s := range scores {
+		if !math.IsNaN(s) {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return cmp.Compare(scores[b], scores[a]) })
+	return order, nil
+}
+
+// ConfusionMatrix counts a classifier's outcomes at a threshold
+type ConfusionMatrix struct {
+	Threshold      float64 `json:"threshold"`
+	TruePositives  int     `json:"truePositives"`
+	FalsePositives int     `json:"falsePositives"`
+	TrueNegatives  int     `json:"trueNegatives"`
+	FalseNegatives int     `json:"falseNegatives"`
+}
+
+// Confusion classifies observations scoring at or above threshold as positive and counts the
+// outcomes against labels. Observations with a NaN score are dropped.
+func Confusion(scores []float64, labels []bool, threshold float64) (ConfusionMatrix, error) {
+	if len(scores) != len(labels) {
+		return ConfusionMatrix{}, fmt.Errorf("scores and labels length mismatch: %d vs %d", len(scores), len(labels))
+	}
+	m := ConfusionMatrix{Threshold: threshold}
+	for i, s := range scores {
+		switch {
+		case math.IsNaN(s):
+		case s >= threshold && labels[i]:
+			m.TruePositives++
+		case s >= threshold:
+			m.FalsePositives++
+		case labels[i]:
+			m.FalseNegatives++
+		default:
+			m.TrueNegatives++
+		}
+	}
+	return m, nil
+}
+
+// Accuracy is the share of observations classified correctly
+func (m ConfusionMatrix) Accuracy() float64 {
+	return ratio(m.TruePositives+m.TrueNegatives, m.TruePositives+m.TrueNegatives+m.FalsePositives+m.FalseNegatives)
+}
+
+// Sensitivity is the share of positives classified positive (the recall, or true positive rate)
+func (m ConfusionMatrix) Sensitivity() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalseNegatives)
+}
+
+// Specificity is the share of negatives classified negative
+func (m ConfusionMatrix) Specificity() float64 {
+	return ratio(m.TrueNegatives, m.TrueNegatives+m.FalsePositives)
+}
+
+// Precision is the share of positive classifications that are right
+func (m ConfusionMatrix) Precision() float64 {
+	return ratio(m.TruePositives, m.TruePositives+m.FalsePositives)
+}
+
+// F1 is the harmonic mean of precision and sensitivity
+func (m ConfusionMatrix) F1() float64 {
+	return ratio(2*m.TruePositives, 2*m.TruePositives+m.FalsePositives+m.FalseNegatives)
+}
+
+// ratio is a/b, or NaN when b is zero
+func ratio(a, b int) float64 {
+	if b == 0 {
+		return math.NaN()
+	}
+	return float64(a) / float64(b)
+}
+
+// CalibrationBin compares the mean predicted probability of a group of observations with the
+// share of them that are positive
+type CalibrationBin struct {
+	N         int     `json:"n"`
+	Predicted float64 `json:"predicted"`
+	Observed  float64 `json:"observed"`
+}
+
+// Calibration summarizes how well scores read as probabilities. Brier is the mean squared
+// difference between score and outcome; HosmerLemeshow tests whether the observed positives in
+// each bin match the predicted ones, chi-squared with bins - 2 degrees of freedom for a fitted
+// logistic model; a small p-value means the probabilities are off.
+type Calibration struct {
+	Bins           []CalibrationBin `json:"bins"`
+	Brier          float64          `json:"brier"`
+	HosmerLemeshow ChiSquaredResult `json:"hosmerLemeshow"`
+}
+
+// Calibrate groups the observations into bins of equal size by ascending score (deciles for
+// bins = 10), keeping tied scores together, and compares predicted with observed positives.
+// Scores must be probabilities between 0 and 1; NaN scores are dropped.
+func Calibrate(scores []float64, labels []bool, bins int) (Calibration, error) {
+	order, err := classifierOrder(scores, labels)
+	if err != nil {
+		return Calibration{}, err
+	}
+	slices.Reverse(order)
+	if bins < 3 {
+		return Calibration{}, fmt.Errorf("need at least 3 bins, got %d", bins)
+	}
+	if len(order) < bins {
+		return Calibration{}, fmt.Errorf("need at least one observation per bin (have %d for %d bins)", len(order), bins)
+	}
+
+	var c Calibration
+	var bin CalibrationBin
+	var observed float64
+	flush := func() {
+		if bin.N == 0 {
+			return
+		}
+		expected := bin.Predicted
+		bin.Predicted /= float64(bin.N)
+		bin.Observed = observed / float64(bin.N)
+		// the binomial variance of the bin's positive count
+		if variance := expected * (1 - bin.Predicted); variance > 0 {
+			c.HosmerLemeshow.Statistic += (observed - expected) * (observed - expected) / variance
+		}
+		c.Bins = append(c.Bins, bin)
+		bin, observed = CalibrationBin{}, 0
+	}
+	for k, i := range order {
+		s := scores[i]
+		if s < 0 || s > 1 {
+			return Calibration{}, fmt.Errorf("score %d is %v; scores must be probabilities between 0 and 1", i, s)
+		}
+		outcome := 0.0
+		if labels[i] {
+			outcome = 1
+		}
+		c.Brier += (s - outcome) * (s - outcome) / float64(len(order))
+		bin.N++
+		bin.Predicted += s
+		observed += outcome
+		// bins close at their share of the observations, but never between tied scores
+		last := k == len(order)-1
+		if !last && (k+1)*bins/len(order) > len(c.Bins) && scores[order[k+1]] != s {
+			flush()
+		}
+	}
+	flush()
+	c.HosmerLemeshow.DF = len(c.Bins) - 2
+	if c.HosmerLemeshow.DF < 1 {
+		return Calibration{}, fmt.Errorf("tied scores leave only %d bins", len(c.Bins))
+	}
+	c.HosmerLemeshow.PValue = chiSquaredSurvival(c.HosmerLemeshow.Statistic, float64(c.HosmerLemeshow.DF))
+	return c, nil
+}
+
+// PrintClassifierSummary writes the AUC, the confusion matrix at threshold with the rates it
+// implies, and the calibration by bins, as the linear fits' diagnostics are written
+func PrintClassifierSummary(w io.Writer, scores []float64, labels []bool, threshold float64, bins int) error {
+	roc, err := ROC(scores, labels)
+	if err != nil {
+		return err
+	}
+	m, err := Confusion(scores, labels, threshold)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "  ROC:       AUC = %.4f (%d positives, %d negatives)\n", roc.AUC, roc.Positives, roc.Negatives)
+	fmt.Fprintf(w, "  Confusion at threshold %g:\n", threshold)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "    \tPredicted +\tPredicted -\t")
+	fmt.Fprintf(tw, "    Actual +\t%d\t%d\t\n", m.TruePositives, m.FalseNegatives)
+	fmt.Fprintf(tw, "    Actual -\t%d\t%d\t\n", m.FalsePositives, m.TrueNegatives)
+	tw.Flush()
+	fmt.Fprintf(w, "    accuracy %.4f, sensitivity %.4f, specificity %.4f, precision %.4f, F1 %.4f\n",
+		m.Accuracy(), m.Sensitivity(), m.Specificity(), m.Precision(), m.F1())
+
+	c, err := Calibrate(scores, labels, bins)
+	if err != nil {
+		fmt.Fprintf(w, "  Calibration: n/a (%v)\n", err)
+		return nil
+	}
+	hl := c.HosmerLemeshow
+	fmt.Fprintf(w, "  Calibration: Brier score %.4f, Hosmer-Lemeshow chi2(%d) = %.4f, p = %.4g\n", c.Brier, hl.DF, hl.Statistic, hl.PValue)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "    Bin\tN\tPredicted\tObserved\t")
+	for k, b := range c.Bins {
+		fmt.Fprintf(tw, "    %d\t%d\t%.4f\t%.4f\t\n", k+1, b.N, b.Predicted, b.Observed)
+	}
+	tw.Flush()
+	return nil
+}
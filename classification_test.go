@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The AUC is the share of positive-negative pairs ranked right, with ties counting half
+func TestROC(t *testing.T) {
+	scores := []float64{0.9, 0.8, 0.7, 0.6, 0.6, 0.4, 0.3, 0.2, math.NaN()}
+	labels := []bool{true, true, false, true, false, false, true, false, true}
+	roc, err := ROC(scores, labels)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var right float64
+	for i := range 8 {
+		for j := range 8 {
+			if labels[i] && !labels[j] {
+				switch {
+				case scores[i] > scores[j]:
+					right++
+				case scores[i] == scores[j]:
+					right += 0.5
+				}
+			}
+		}
+	}
+	if want := right / 16; math.Abs(roc.AUC-want) > 1e-12 || roc.Positives != 4 || roc.Negatives != 4 {
+		t.Errorf("AUC %g (%d+, %d-), want %g (4+, 4-)", roc.AUC, roc.Positives, roc.Negatives, want)
+	}
+	// one point per distinct score after the all-negative start
+	if last := roc.Points[len(roc.Points)-1]; len(roc.Points) != 8 || last.TruePositiveRate != 1 || last.FalsePositiveRate != 1 {
+		t.Errorf("unexpected curve %+v", roc.Points)
+	}
+
+	if _, err := ROC([]float64{0.1, 0.2}, []bool{true, true}); err == nil {
+		t.Error("expected an error without negatives")
+	}
+}
+
+// ✅ Test 2: The confusion matrix counts scores at the threshold as positive
+func TestConfusion(t *testing.T) {
+	m, err := Confusion([]float64{0.9, 0.5, 0.5, 0.2, 0.1}, []bool{true, false, true, true, false}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.TruePositives != 2 || m.FalsePositives != 1 || m.FalseNegatives != 1 || m.TrueNegatives != 1 {
+		t.Errorf("unexpected matrix %+v", m)
+	}
+	if m.Accuracy() != 0.6 || m.Sensitivity() != 2.0/3 || m.Specificity() != 0.5 || m.Precision() != 2.0/3 || m.F1() != 2.0/3 {
+		t.Errorf("unexpected rates %v %v %v %v %v", m.Accuracy(), m.Sensitivity(), m.Specificity(), m.Precision(), m.F1())
+	}
+	if none := (ConfusionMatrix{}); !math.IsNaN(none.Precision()) {
+		t.Error("expected NaN precision without positive classifications")
+	}
+}
+
+// ✅ Test 3: Calibrated scores pass Hosmer-Lemeshow and miscalibrated ones fail
+func TestCalibrate(t *testing.T) {
+	var scores []float64
+	var labels []bool
+	for i := range 1000 {
+		p := float64(i%100)/100 + 0.005
+		scores = append(scores, p)
+		// outcomes spread evenly so each score's positive share is p
+		labels = append(labels, float64((i*37)%1000)/1000 < p)
+	}
+	c, err := Calibrate(scores, labels, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Bins) != 10 || c.Bins[0].N != 100 || c.HosmerLemeshow.DF != 8 || c.HosmerLemeshow.PValue < 0.05 {
+		t.Errorf("expected ten bins of calibrated scores, got %+v", c)
+	}
+
+	squeezed := make([]float64, len(scores))
+	for i, s := range scores {
+		squeezed[i] = 0.4 + 0.2*s
+	}
+	if bad, err := Calibrate(squeezed, labels, 10); err != nil || bad.HosmerLemeshow.PValue > 1e-6 || bad.Brier <= c.Brier {
+		t.Errorf("expected squeezed scores to fail calibration, got %+v (err %v)", bad.HosmerLemeshow, err)
+	}
+
+	if _, err := Calibrate([]float64{0.2, 1.5, 0.3, 0.4}, []bool{true, false, true, false}, 3); err == nil {
+		t.Error("expected an error for a score above 1")
+	}
+}
+
+// ✅ Test 4: The summary reports AUC, the confusion matrix and calibration bins
+func TestPrintClassifierSummary(t *testing.T) {
+	scores := []float64{0.95, 0.85, 0.75, 0.65, 0.55, 0.45, 0.35, 0.25, 0.15, 0.05}
+	labels := []bool{true, true, true, false, true, false, false, true, false, false}
+	var buf bytes.Buffer
+	if err := PrintClassifierSummary(&buf, scores, labels, 0.5, 3); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"AUC = 0.8400", "Actual +", "accuracy 0.8000", "Hosmer-Lemeshow chi2(1)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary is missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+)
+
+// KMeansResult is a k-means partition. Assignments[i] is the cluster of point i and Inertia the
+// sum of squared distances from points to their centres. Silhouette is the mean silhouette width
+// (0 for a single cluster).
+type KMeansResult struct {
+	K           int         `json:"k"`
+	Centers     [][]float64 `json:"centers"`
+	Assignments []int       `json:"assignments"`
+	Inertia     float64     `json:"inertia"`
+	Silhouette  float64     `json:"silhouette"`
+}
+
+// kMeansRestarts is how many k-means++ starts KMeans tries, keeping the lowest inertia
+const kMeansRestarts = 10
+
+// KMeans partitions points (all of the same dimension) into k clusters by Lloyd's algorithm from
+// k-means++ starting centres. Results depend only on seed.
+func KMeans(points [][]float64, k int, seed uint64) (KMeansResult, error) {
+	if k < 1 {
+		return KMeansResult{}, fmt.Errorf("k must be at least 1, got %d", k)
+	}
+	if len(points) < k {
+		return KMeansResult{}, fmt.Errorf("need at least %d points for %d clusters, have %d", k, k, len(points))
+	}
+	for i, p := range points {
+		if len(p) != len(points[0]) {
+			return KMeansResult{}, fmt.Errorf("point %d has %d coordinates, expected %d", i, len(p), len(points[0]))
+		}
+		if hasMissing(p) {
+			return KMeansResult{}, fmt.Errorf("point %d has a missing coordinate", i)
+		}
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	var best KMeansResult
+	for restart := 0; restart < kMeansRestarts; restart++ {
+		r := lloyd(points, kMeansPlusPlus(points, k, rng))
+		if restart == 0 || r.Inertia < best.Inertia {
+			best = r
+		}
+	}
+	best.Silhouette = Silhouette(points, best.Assignments, k)
+	return best, nil
+}
+
+// kMeansPlusPlus picks starting centres, each new one with probability proportional to its squared
+// distance from the nearest centre chosen so far
+func kMeansPlusPlus(points [][]float64, k int, rng *rand.Rand) [][]float64 {
+	centers := [][]float64{append([]float64(nil), points[rng.IntN(len(points))]...)}
+	nearest := make([]float64, len(points))
+	for len(centers) < k {
+		var total float64
+		for i, p := range points {
+			nearest[i] = math.Inf(1)
+			for _, c := range centers {
+				nearest[i] = math.Min(nearest[i], squaredDistance(p, c))
+			}
+			total += nearest[i]
+		}
+		next := rng.IntN(len(points))
+		if total > 0 {
+			target := rng.Float64() * total
+			for i, d := range nearest {
+				if target -= d; target < 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centers = append(centers, append([]float64(nil), points[next]...))
+	}
+	return centers
+}
+
+// lloyd alternates assignment and centre updates until no point changes cluster
+func lloyd(points [][]float64, centers [][]float64) KMeansResult {
+	r := KMeansResult{K: len(centers), Centers: centers, Assignments: make([]int, len(points))}
+	for i := range r.Assignments {
+		r.Assignments[i] = -1
+	}
+	for iteration := 0; iteration < 300; iteration++ {
+		changed := false
+		r.Inertia = 0
+		for i, p := range points {
+			best, bestDist := 0, math.Inf(1)
+			for c, center := range r.Centers {
+				if d := squaredDistance(p, center); d < bestDist {
+					best, bestDist = c, d
+				}
+			}
+			if r.Assignments[i] != best {
+				r.Assignments[i], changed = best, true
+			}
+			r.Inertia += bestDist
+		}
+		if !changed {
+			break
+		}
+
+		counts := make([]int, len(r.Centers))
+		sums := make([][]float64, len(r.Centers))
+		for c := range sums {
+			sums[c] = make([]float64, len(points[0]))
+		}
+		for i, p := range points {
+			c := r.Assignments[i]
+			counts[c]++
+			for j, v := range p {
+				sums[c][j] += v
+			}
+		}
+		for c := range r.Centers {
+			// an emptied cluster keeps its centre
+			if counts[c] == 0 {
+				continue
+			}
+			for j := range sums[c] {
+				r.Centers[c][j] = sums[c][j] / float64(counts[c])
+			}
+		}
+	}
+	return r
+}
+
+// Silhouette returns the mean silhouette width of a partition: for each point, (b - a) / max(a, b)
+// with a its mean distance to its own cluster and b to the nearest other cluster. Points alone in
+// their cluster count as 0. It is 0 for fewer than two clusters.
+func Silhouette(points [][]float64, assignments []int, k int) float64 {
+	if k < 2 || len(points) == 0 {
+		return 0
+	}
+	var total float64
+	sums := make([]float64, k)
+	counts := make([]int, k)
+	for i, p := range points {
+		clear(sums)
+		clear(counts)
+		for j, q := range points {
+			if i != j {
+				sums[assignments[j]] += math.Sqrt(squaredDistance(p, q))
+				counts[assignments[j]]++
+			}
+		}
+		own := assignments[i]
+		if counts[own] == 0 {
+			continue
+		}
+		a := sums[own] / float64(counts[own])
+		b := math.Inf(1)
+		for c := range sums {
+			if c != own && counts[c] > 0 {
+				b = math.Min(b, sums[c]/float64(counts[c]))
+			}
+		}
+		if math.IsInf(b, 1) || math.Max(a, b) == 0 {
+			continue
+		}
+		total += (b - a) / math.Max(a, b)
+	}
+	return total / float64(len(points))
+}
+
+// SelectK runs KMeans for every k from 2 to maxK and returns the partition with the highest
+// silhouette, along with the silhouette of each k tried (index 0 is k = 2)
+func SelectK(points [][]float64, maxK int, seed uint64) (KMeansResult, []float64, error) {
+	if maxK < 2 {
+		return KMeansResult{}, nil, fmt.Errorf("maximum k must be at least 2, got %d", maxK)
+	}
+	maxK = min(maxK, len(points)-1)
+	if maxK < 2 {
+		return KMeansResult{}, nil, fmt.Errorf("need at least 3 points to compare clusterings, have %d", len(points))
+	}
+	var best KMeansResult
+	scores := make([]float64, 0, maxK-1)
+	for k := 2; k <= maxK; k++ {
+		r, err := KMeans(points, k, seed)
+		if err != nil {
+			return KMeansResult{}, nil, err
+		}
+		scores = append(scores, r.Silhouette)
+		if k == 2 || r.Silhouette > best.Silhouette {
+			best = r
+		}
+	}
+	return best, scores, nil
+}
+
+func squaredDistance(a, b []float64) float64 {
+	var d float64
+	for i := range a {
+		d += (a[i] - b[i]) * (a[i] - b[i])
+	}
+	return d
+}
+
+// ClusterSpace chooses the coordinates a dataset is clustered in
+type ClusterSpace string
+
+const (
+	ClusterXY       ClusterSpace = "xy"       // standardized (x, y)
+	ClusterResidual ClusterSpace = "residual" // standardized residuals from the single-line fit
+)
+
+// Clusters are taken as real structure when the mean silhouette reaches heterogeneousSilhouette
+// (Kaufman & Rousseeuw's "reasonable structure" starts at 0.51) and each has enough points for its
+// own line to mean something; small samples otherwise split into tight clusters of two or three.
+const (
+	heterogeneousSilhouette = 0.5
+	minClusterSize          = 5
+)
+
+// ClusterFit is the line fitted to one cluster of a dataset
+type ClusterFit struct {
+	Size      int     `json:"size"`
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+}
+
+// DatasetClusters is a k-means clustering of a dataset's complete pairs with a line per cluster
+type DatasetClusters struct {
+	Space ClusterSpace `json:"space"`
+	KMeansResult
+	Fits []ClusterFit `json:"fits"`
+	// Scores holds the silhouette for each k tried, from k = 2
+	Scores []float64 `json:"scores"`
+}
+
+// Heterogeneous reports whether the clusters are distinct and large enough to suggest
+// subpopulations that a single line does not describe
+func (c DatasetClusters) Heterogeneous() bool {
+	if c.K < 2 || c.Silhouette < heterogeneousSilhouette {
+		return false
+	}
+	for _, fit := range c.Fits {
+		if fit.Size < minClusterSize {
+			return false
+		}
+	}
+	return true
+}
+
+// ClusterDataset clusters the complete pairs of ds in space, choosing k from 2 to maxK by
+// silhouette, and fits a line to each cluster. Assignments index the complete pairs in order.
+func ClusterDataset(ds Dataset, space ClusterSpace, maxK int, seed uint64) (DatasetClusters, error) {
+	x, y, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return DatasetClusters{}, err
+	}
+	var columns [][]float64
+	switch space {
+	case ClusterXY:
+		columns = [][]float64{x, y}
+	case ClusterResidual:
+		slope, intercept, _ := ManualRegression(x, y)
+		columns = [][]float64{Residuals(x, y, slope, intercept)}
+	default:
+		return DatasetClusters{}, fmt.Errorf("unknown cluster space %q (use xy or residual)", space)
+	}
+
+	points := make([][]float64, len(x))
+	for i := range points {
+		points[i] = make([]float64, len(columns))
+	}
+	for j, column := range columns {
+		mean, variance := meanVariance(column)
+		scale := math.Sqrt(variance)
+		if scale == 0 {
+			scale = 1
+		}
+		for i, v := range column {
+			points[i][j] = (v - mean) / scale
+		}
+	}
+
+	best, scores, err := SelectK(points, maxK, seed)
+	if err != nil {
+		return DatasetClusters{}, err
+	}
+	c := DatasetClusters{Space: space, KMeansResult: best, Scores: scores}
+	for k := 0; k < best.K; k++ {
+		var cx, cy []float64
+		for i, a := range best.Assignments {
+			if a == k {
+				cx, cy = append(cx, x[i]), append(cy, y[i])
+			}
+		}
+		fit := ClusterFit{Size: len(cx)}
+		if len(cx) >= 2 {
+			fit.Slope, fit.Intercept, fit.RSquared = ManualRegression(cx, cy)
+		}
+		c.Fits = append(c.Fits, fit)
+	}
+	return c, nil
+}
+
+// printClusters writes the clustering section of a dataset report
+func printClusters(w io.Writer, data Dataset, space ClusterSpace) {
+	c, err := ClusterDataset(data, space, 6, clusterSeed)
+	if err != nil {
+		fmt.Fprintf(w, "  Clusters:  n/a (%v)\n", err)
+		return
+	}
+	verdict := "no distinct subpopulations"
+	if c.Heterogeneous() {
+		verdict = "distinct subpopulations; a single line may not fit"
+	}
+	fmt.Fprintf(w, "  Clusters:  k = %d in %s space, silhouette %.4f (%s)\n", c.K, c.Space, c.Silhouette, verdict)
+	if !c.Heterogeneous() {
+		return
+	}
+	for k, fit := range c.Fits {
+		fmt.Fprintf(w, "    Cluster %d: slope %.6f, intercept %.6f, R-squared %.6f (%d points)\n", k+1, fit.Slope, fit.Intercept, fit.RSquared, fit.Size)
+	}
+}
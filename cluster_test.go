@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: Well separated blobs are recovered with k chosen by silhouette
+func TestSelectKBlobs(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	centres := [][]float64{{0, 0}, {10, 0}, {0, 10}}
+	var points [][]float64
+	for i := 0; i < 90; i++ {
+		c := centres[i%3]
+		points = append(points, []float64{c[0] + 0.5*rng.NormFloat64(), c[1] + 0.5*rng.NormFloat64()})
+	}
+	best, scores, err := SelectK(points, 6, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if best.K != 3 || len(scores) != 5 || best.Silhouette < 0.8 {
+		t.Fatalf("k = %d, silhouette %v, scores %v", best.K, best.Silhouette, scores)
+	}
+	for i, a := range best.Assignments {
+		if a != best.Assignments[i%3] {
+			t.Fatalf("point %d assigned to %d, its blob to %d", i, a, best.Assignments[i%3])
+		}
+	}
+
+	again, _ := KMeans(points, 3, 7)
+	if again.Inertia != best.Inertia {
+		t.Error("expected the same seed to give the same clustering")
+	}
+}
+
+// ✅ Test 2: Silhouette of a hand-checked partition
+func TestSilhouette(t *testing.T) {
+	points := [][]float64{{0}, {1}, {10}, {11}}
+	// a = 1 for every point; b is 10.5 for the outer points and 9.5 for the inner ones
+	want := (2*(10.5-1)/10.5 + 2*(9.5-1)/9.5) / 4
+	if got := Silhouette(points, []int{0, 0, 1, 1}, 2); math.Abs(got-want) > 1e-12 {
+		t.Errorf("silhouette %v, want %v", got, want)
+	}
+	if Silhouette(points, []int{0, 0, 0, 0}, 1) != 0 {
+		t.Error("expected 0 for one cluster")
+	}
+	if _, err := KMeans(points, 5, 1); err == nil {
+		t.Error("expected an error for more clusters than points")
+	}
+}
+
+// ✅ Test 3: Two parallel lines separate in residual space with a fit each
+func TestClusterDatasetResidual(t *testing.T) {
+	var ds Dataset
+	for i := 0; i < 40; i++ {
+		x := float64(i / 2)
+		offset := 0.0
+		if i%2 == 1 {
+			offset = 8
+		}
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, 1+0.5*x+offset+0.1*math.Sin(float64(i)))
+	}
+	c, err := ClusterDataset(ds, ClusterResidual, 5, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.K != 2 || !c.Heterogeneous() {
+		t.Fatalf("expected two distinct clusters, got k = %d, silhouette %v", c.K, c.Silhouette)
+	}
+	for _, fit := range c.Fits {
+		if fit.Size != 20 || math.Abs(fit.Slope-0.5) > 0.02 {
+			t.Errorf("unexpected cluster fit %+v", fit)
+		}
+	}
+	if math.Abs(math.Abs(c.Fits[0].Intercept-c.Fits[1].Intercept)-8) > 0.1 {
+		t.Errorf("intercepts %v and %v should differ by 8", c.Fits[0].Intercept, c.Fits[1].Intercept)
+	}
+
+	if _, err := ClusterDataset(ds, "pca", 5, 1); err == nil {
+		t.Error("expected an error for an unknown space")
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import "math"
+
+// doubleDouble is an unevaluated sum hi+lo carrying roughly twice the precision of a float64.
+// The compensated manual engine accumulates its sums in this form: each addition keeps the
+// rounding error of the running sum (Neumaier) and each product keeps its exact rounding error
+// (via FMA), so the normal-equation differences like n*Σx² - (Σx)² no longer cancel away all digits.
+type doubleDouble struct {
+	hi, lo float64
+}
+
+// twoSum returns s = fl(a+b) and the exact error e so that a+b = s+e
+func twoSum(a, b float64) (s, e float64) {
+	s = a + b
+	bb := s - a
+	e = (a - (s - bb)) + (b - bb)
+	return s, e
+}
+
+// twoProd returns p = fl(a*b) and the exact error e so that a*b = p+e
+func twoProd(a, b float64) (p, e float64) {
+	p = a * b
+	return p, math.FMA(a, b, -p)
+}
+
+func (d doubleDouble) add(v float64) doubleDouble {
+	s, e := twoSum(d.hi, v)
+	return normalizeDD(s, d.lo+e)
+}
+
+func (d doubleDouble) addDD(o doubleDouble) doubleDouble {
+	s, e := twoSum(d.hi, o.hi)
+	return normalizeDD(s, e+d.lo+o.lo)
+}
+
+func (d doubleDouble) neg() doubleDouble {
+	return doubleDouble{-d.hi, -d.lo}
+}
+
+func (d doubleDouble) mul(o doubleDouble) doubleDouble {
+	p, e := twoProd(d.hi, o.hi)
+	return normalizeDD(p, e+d.hi*o.lo+d.lo*o.hi)
+}
+
+func (d doubleDouble) float() float64 {
+	return d.hi + d.lo
+}
+
+func normalizeDD(hi, lo float64) doubleDouble {
+	s := hi + lo
+	return doubleDouble{s, lo - (s - hi)}
+}
+
+// addProduct accumulates a*b including the product's rounding error
+func (d doubleDouble) addProduct(a, b float64) doubleDouble {
+	p, e := twoProd(a, b)
+	return d.add(p).add(e)
+}
+
+// compensatedRegression evaluates the same formulas as ManualRegression in double-double arithmetic
+func compensatedRegression(x, y []float64) (slope, intercept, rSquared float64) {
+	n := doubleDouble{hi: float64(len(x))}
+
+	var sumX, sumY, sumXY, sumXX, sumYY doubleDouble
+	for i := range x {
+		sumX = sumX.add(x[i])
+		sumY = sumY.add(y[i])
+		sumXY = sumXY.addProduct(x[i], y[i])
+		sumXX = sumXX.addProduct(x[i], x[i])
+		sumYY = sumYY.addProduct(y[i], y[i])
+	}
+
+	den := n.mul(sumXX).addDD(sumX.mul(sumX).neg())
+	if den.float() == 0 {
+		// degenerate case: treat slope as 0 to avoid division by zero
+		slope = 0
+		intercept = sumY.float() / n.hi
+	} else {
+		num := n.mul(sumXY).addDD(sumX.mul(sumY).neg())
+		slope = num.float() / den.float()
+		intercept = sumY.addDD(doubleDouble{hi: slope}.mul(sumX).neg()).float() / n.hi
+	}
+
+	// ssTotal = Σy² - (Σy)²/n, computed as (nΣy² - (Σy)²)/n to stay in double-double
+	ssTotal := n.mul(sumYY).addDD(sumY.mul(sumY).neg()).float() / n.hi
+
+	var ssResidual doubleDouble
+	for i := range x {
+		// residual = y - intercept - slope*x, with the product's error kept
+		p, e := twoProd(slope, x[i])
+		r := doubleDouble{hi: y[i]}.add(-intercept).add(-p).add(-e).float()
+		ssResidual = ssResidual.addProduct(r, r)
+	}
+
+	return slope, intercept, rSquaredFrom(ssTotal, ssResidual.float())
+}
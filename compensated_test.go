@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func relErr(got, want float64) float64 {
+	return math.Abs(got-want) / math.Max(math.Abs(want), 1e-300)
+}
+
+// ✅ Test 1: Compensated mode stays accurate where plain sums break down
+func TestCompensatedAccuracy(t *testing.T) {
+	cases := []struct {
+		name   string
+		offset float64
+		n      int
+	}{
+		{"offset 1e4", 1e4, 1000},
+		{"offset 1e6", 1e6, 1000},
+		{"offset 1e7 large n", 1e7, 100_000},
+	}
+
+	for _, c := range cases {
+		x, y := syntheticLine(c.n)
+		for i := range x {
+			x[i] = c.offset + x[i]*0.001
+		}
+		refSlope, refIntercept, _, _ := BigFloatRegression(x, y)
+
+		slope, intercept, _ := ManualRegressionWithOptions(x, y, ManualOptions{Compensated: true})
+		if e := relErr(slope, refSlope); e > 1e-12 {
+			t.Errorf("%s: compensated slope relative error %.3g", c.name, e)
+		}
+		if e := relErr(intercept, refIntercept); e > 1e-9 {
+			t.Errorf("%s: compensated intercept relative error %.3g", c.name, e)
+		}
+
+		plainSlope, _, _ := ManualRegression(x, y)
+		t.Logf("%s: slope relative error plain %.3g, compensated %.3g", c.name, relErr(plainSlope, refSlope), relErr(slope, refSlope))
+	}
+}
+
+// ✅ Test 2: On well-conditioned data both modes agree
+func TestCompensatedMatchesPlain(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		s1, i1, r1 := ManualRegression(data.X, data.Y)
+		s2, i2, r2 := ManualRegressionWithOptions(data.X, data.Y, ManualOptions{Compensated: true})
+		if math.Abs(s1-s2) > 1e-12 || math.Abs(i1-i2) > 1e-12 || math.Abs(r1-r2) > 1e-12 {
+			t.Errorf("%s: plain (%v %v %v) vs compensated (%v %v %v)", name, s1, i1, r1, s2, i2, r2)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// captureStdout runs f and returns what it wrote to standard output
+func captureStdout(t *testing.T, f func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+	f()
+	w.Close()
+	return <-done
+}
+
+// ✅ Test 1: Falling back to the manual calculation is recorded in the result, not printed
+func TestFallbackIsSilent(t *testing.T) {
+	data := LoadAnscombeDatasets()["IV"]
+	var result RegressionResult
+	out := captureStdout(t, func() {
+		var err error
+		if result, err = AnalyzeDataset("IV", data); err != nil {
+			t.Error(err)
+		}
+		if _, _, _, err := PerformLinearRegression([]float64{1, 1, 1}, []float64{1, 2, 3}); err != nil {
+			t.Error(err)
+		}
+	})
+	if out != "" {
+		t.Errorf("expected no output, got %q", out)
+	}
+	if result.Fallback == "" {
+		t.Errorf("expected the fallback to be recorded")
+	}
+}
+
+// ✅ Test 2: The library surface can be used from many goroutines at once (run with -race)
+func TestConcurrentUse(t *testing.T) {
+	quartet := LoadAnscombeDatasets()
+	cache := NewResultCache(8)
+	history, err := OpenHistory(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer history.Close()
+	var hooks ScriptHooks
+	if err := hooks.AddFilter("x < 19"); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name, data := range quartet {
+				result, err := AnalyzeDataset(name, data)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				for _, e := range Engines() {
+					if _, _, _, err := e.Fit(data.X, data.Y); err != nil {
+						t.Errorf("%s on %s: %v", e.Name, name, err)
+					}
+				}
+				if _, ok := LookupEngine("manual"); !ok {
+					t.Error("manual engine not found")
+				}
+				cache.Put(DatasetFingerprint(data, "test"), result)
+				cache.Get(DatasetFingerprint(data, "test"))
+				FitEnsemble(data.X, data.Y, 0)
+				Advise(data)
+				InfluenceRanking(data, result.Slope, result.Intercept)
+				if _, err := hooks.Apply(data); err != nil {
+					t.Error(err)
+				}
+				DefaultGoldenTolerance()
+			}
+			outcomes := AnalyzeAll(anscombeJobs(), 2)
+			if _, err := history.Record(NewManifest(analysisEngine), NewRunRecord(outcomes, RunRecordOptions{})); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if runs, err := history.Runs(0); err != nil || len(runs) != 8 {
+		t.Errorf("expected 8 recorded runs, got %d (%v)", len(runs), err)
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Conditioning describes how sensitive a least squares fit is to rounding, from the singular
+// values of its design matrix: a column of ones for the intercept and one per predictor.
+//
+// ConditionNumber is κ, the ratio of the largest singular value to the smallest. A QR fit loses
+// about log10 κ of the 16 significant digits of float64; the normal equations (the raw sums of the
+// manual and stats engines) square the matrix and lose twice as many. ScaledConditionNumber is κ
+// after scaling every column to unit length, which does not depend on the predictors' units, so
+// a large κ with a small scaled κ points at units or an offset rather than at collinearity.
+// Offsets are how many standard deviations each predictor's mean lies from zero: a line fitted to
+// X values far from the origin is ill-conditioned unless X is centered first.
+type Conditioning struct {
+	ConditionNumber       float64   `json:"conditionNumber"`
+	ScaledConditionNumber float64   `json:"scaledConditionNumber"`
+	Names                 []string  `json:"names"`
+	Offsets               []float64 `json:"offsets"`
+}
+
+// conditioningDigits is the number of digits a fit may lose before Conditioning.Unreliable warns,
+// which leaves 8 of float64's 16 for results printed to 6
+const conditioningDigits = 8
+
+// DigitsLost is the number of significant digits rounding may cost a fit solved by the normal
+// equations, or by QR when normalEquations is false
+func (c Conditioning) DigitsLost(normalEquations bool) float64 {
+	digits := math.Log10(c.ConditionNumber)
+	if normalEquations {
+		digits *= 2
+	}
+	return digits
+}
+
+// Unreliable reports whether a fit may have lost more than half its digits to rounding
+func (c Conditioning) Unreliable(normalEquations bool) bool {
+	return !(c.DigitsLost(normalEquations) <= conditioningDigits)
+}
+
+// Advice suggests how to make an unreliable fit better conditioned
+func (c Conditioning) Advice() string {
+	return c.advice(Messages{})
+}
+
+// advice is Advice in the language of m
+func (c Conditioning) advice(m Messages) string {
+	worst := -1
+	for j, offset := range c.Offsets {
+		if offset > 10 && (worst < 0 || offset > c.Offsets[worst]) {
+			worst = j
+		}
+	}
+	switch {
+	case worst >= 0:
+		return m.Sprintf("center %s, whose mean lies %.3g standard deviations from 0", c.Names[worst], c.Offsets[worst])
+	case c.ScaledConditionNumber*100 < c.ConditionNumber:
+		return m.Text("rescale the predictors to similar magnitudes")
+	default:
+		return m.Text("drop or combine nearly collinear predictors")
+	}
+}
+
+// LineConditioning is the conditioning of a straight-line fit to the finite pairs of d
+func LineConditioning(d Dataset) (Conditioning, error) {
+	x, _, err := cleanPairs(nil, nil, d.X, d.Y)
+	if err != nil {
+		return Conditioning{}, err
+	}
+	name := d.XAxis.Label
+	if name == "" {
+		name = "X"
+	}
+	return DesignConditioning([]string{name}, [][]float64{x})
+}
+
+// MultiConditioning is the conditioning of a multiple regression's design over the complete rows
+// of md, which holds what the fit's Predict takes, in the fitted terms (see PartialPlots)
+func MultiConditioning(fit MultiFit, md MultiDataset) (Conditioning, error) {
+	cols := make([][]float64, len(fit.Names))
+	for i, row := range md.X {
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		for j, v := range fit.features(row) {
+			cols[j] = append(cols[j], v)
+		}
+	}
+	return DesignConditioning(fit.Names, cols)
+}
+
+// DesignConditioning is the conditioning of the design matrix of an intercept and the predictor
+// columns cols, which must be complete and of equal length. The singular values come from a
+// one-sided Jacobi SVD of the matrix itself, never forming its cross-product, so they are accurate
+// however badly conditioned it is. A design with a constant or collinear predictor has a condition
+// number near 1e16 or infinite.
+func DesignConditioning(names []string, cols [][]float64) (Conditioning, error) {
+	if len(cols) == 0 || len(cols[0]) < len(cols)+1 {
+		return Conditioning{}, fmt.Errorf("need more rows than columns for a condition number")
+	}
+	n := len(cols[0])
+	design := make([][]float64, 0, len(cols)+1)
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	design = append(design, ones)
+	c := Conditioning{Names: names, Offsets: make([]float64, len(cols))}
+	for j, col := range cols {
+		if len(col) != n {
+			return Conditioning{}, fmt.Errorf("column %s has %d rows, expected %d", names[j], len(col), n)
+		}
+		design = append(design, append([]float64(nil), col...))
+		var mean, ss float64
+		for _, v := range col {
+			mean += v / float64(n)
+		}
+		for _, v := range col {
+			ss += (v - mean) * (v - mean)
+		}
+		if ss > 0 {
+			c.Offsets[j] = math.Abs(mean) / math.Sqrt(ss/float64(n))
+		}
+	}
+
+	scaled := make([][]float64, len(design))
+	for j, col := range design {
+		scaled[j] = append([]float64(nil), col...)
+		if norm := norm2(col); norm > 0 {
+			for i := range scaled[j] {
+				scaled[j][i] /= norm
+			}
+		}
+	}
+	c.ConditionNumber = conditionNumber(design)
+	c.ScaledConditionNumber = conditionNumber(scaled)
+	return c, nil
+}
+
+// conditionNumber is the ratio of the extreme singular values of the matrix with the given
+// columns, found by orthogonalizing the columns with one-sided Jacobi rotations (Hestenes'
+// method); the singular values are then the column norms. It overwrites cols.
+func conditionNumber(cols [][]float64) float64 {
+	p := len(cols)
+	for sweep := 0; sweep < 60; sweep++ {
+		rotated := false
+		for j := 0; j < p; j++ {
+			for k := j + 1; k < p; k++ {
+				var alpha, beta, gamma float64
+				for i := range cols[j] {
+					alpha += cols[j][i] * cols[j][i]
+					beta += cols[k][i] * cols[k][i]
+					gamma += cols[j][i] * cols[k][i]
+				}
+				if gamma == 0 || math.Abs(gamma) <= 1e-15*math.Sqrt(alpha*beta) {
+					continue
+				}
+				rotated = true
+				zeta := (beta - alpha) / (2 * gamma)
+				t := math.Copysign(1, zeta) / (math.Abs(zeta) + math.Sqrt(1+zeta*zeta))
+				cos := 1 / math.Sqrt(1+t*t)
+				sin := cos * t
+				for i := range cols[j] {
+					a, b := cols[j][i], cols[k][i]
+					cols[j][i], cols[k][i] = cos*a-sin*b, sin*a+cos*b
+				}
+			}
+		}
+		if !rotated {
+			break
+		}
+	}
+	largest, smallest := 0.0, math.Inf(1)
+	for _, col := range cols {
+		s := norm2(col)
+		largest, smallest = math.Max(largest, s), math.Min(smallest, s)
+	}
+	if !(smallest > largest*1e-300) {
+		return math.Inf(1)
+	}
+	return largest / smallest
+}
+
+// engineSolvesNormalEquations reports whether an engine fits from raw sums, which lose digits to
+// the square of a design's condition number; the others work with centered co-moments or QR
+func engineSolvesNormalEquations(engine string) bool {
+	return engine == "" || engine == "stats" || engine == "manual"
+}
+
+// formatConditioning describes c in one line, in the language of m: κ, the scaled κ and the digits
+// the fit may lose
+func formatConditioning(c Conditioning, normalEquations bool, m Messages) string {
+	if normalEquations {
+		return m.Sprintf("κ = %.3g (scaled %.3g), about %.1f digits lost to the normal equations", c.ConditionNumber, c.ScaledConditionNumber, c.DigitsLost(true))
+	}
+	return m.Sprintf("κ = %.3g (scaled %.3g), about %.1f digits lost to QR", c.ConditionNumber, c.ScaledConditionNumber, c.DigitsLost(false))
+}
+
+// printMultiConditioning writes the conditioning of a multiple regression's design, solved by QR,
+// with a warning when the fit may be unreliable
+func printMultiConditioning(w io.Writer, fit MultiFit, md MultiDataset) {
+	c, err := MultiConditioning(fit, md)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "Conditioning: %s\n", formatConditioning(c, false, Messages{}))
+	if c.Unreliable(false) {
+		fmt.Fprintf(w, "Warning: the coefficients may be numerically unreliable; %s\n", c.Advice())
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// lineConditionNumber is κ of the design [1 x] from the eigenvalues of its 2×2 cross-product,
+// whose determinant n·Sxx is taken about the mean so that it stays accurate
+func lineConditionNumber(x []float64) float64 {
+	n := float64(len(x))
+	var sum, sumSq, mean, sxx float64
+	for _, v := range x {
+		sum += v
+		sumSq += v * v
+	}
+	mean = sum / n
+	for _, v := range x {
+		sxx += (v - mean) * (v - mean)
+	}
+	trace, det := n+sumSq, n*sxx
+	largest := trace/2 + math.Sqrt(trace*trace/4-det)
+	return math.Sqrt(largest / (det / largest))
+}
+
+// ✅ Test 1: Condition numbers match the closed form for a line, near the origin and far from it
+func TestLineConditioning(t *testing.T) {
+	for _, offset := range []float64{0, 1e3, 1e6} {
+		var d Dataset
+		for i := range 20 {
+			d.X = append(d.X, offset+float64(i))
+			d.Y = append(d.Y, float64(i%3))
+		}
+		c, err := LineConditioning(d)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := lineConditionNumber(d.X); math.Abs(c.ConditionNumber-want)/want > 1e-8 {
+			t.Errorf("offset %g: κ = %g, want %g", offset, c.ConditionNumber, want)
+		}
+		if unreliable := c.Unreliable(true); unreliable != (offset >= 1e3) {
+			t.Errorf("offset %g: κ = %g, unexpected unreliable %v", offset, c.ConditionNumber, unreliable)
+		}
+	}
+
+	var far Dataset
+	for i := range 20 {
+		far.X, far.Y = append(far.X, 1e3+float64(i)), append(far.Y, float64(i))
+	}
+	c, _ := LineConditioning(far)
+	if !c.Unreliable(true) || c.Unreliable(false) || !strings.HasPrefix(c.Advice(), "center X, whose mean lies") {
+		t.Errorf("expected QR to cope and the advice to center X, got %+v: %s", c, c.Advice())
+	}
+}
+
+// ✅ Test 2: Scaling separates units from collinearity in multiple regressions
+func TestMultiConditioning(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := range 30 {
+		a := float64(i)
+		md.X = append(md.X, []float64{a, 1e6 * float64((i*7)%5)})
+		md.Y = append(md.Y, a)
+	}
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := MultiConditioning(fit, md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.ConditionNumber < 1e5 || c.ScaledConditionNumber > 10 || c.Advice() != "rescale the predictors to similar magnitudes" {
+		t.Errorf("expected bad units but a well-scaled design, got %+v: %s", c, c.Advice())
+	}
+
+	for i := range md.X {
+		md.X[i][1] = 2*md.X[i][0] + 1e-7*float64(i%2)
+	}
+	if fit, err = FitMultiple(md); err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	printMultiConditioning(&buf, fit, md)
+	if !strings.Contains(buf.String(), "Warning: the coefficients may be numerically unreliable; drop or combine nearly collinear predictors") {
+		t.Errorf("expected a collinearity warning, got %q", buf.String())
+	}
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+)
+
+// ReferenceValues are the expected fit of one dataset; nil fields are not checked
+type ReferenceValues struct {
+	Slope     *float64 `json:"slope,omitempty"`
+	Intercept *float64 `json:"intercept,omitempty"`
+	RSquared  *float64 `json:"rSquared,omitempty"`
+}
+
+// ReferenceTolerances is the largest absolute difference accepted per metric
+type ReferenceTolerances struct {
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+}
+
+// DefaultReferenceTolerance accepts references printed to six decimals
+const DefaultReferenceTolerance = 1e-6
+
+// Reference is a set of expected results, typically produced by R or Python, keyed by dataset name.
+// Zero tolerances are replaced by DefaultReferenceTolerance when loaded.
+type Reference struct {
+	Source     string                     `json:"source"`
+	Tolerances ReferenceTolerances        `json:"tolerances"`
+	Datasets   map[string]ReferenceValues `json:"datasets"`
+}
+
+// AnscombeReference is the exact least-squares fit of the built-in Anscombe quartet, computed with
+// RationalRegression, so the expected values follow the data rather than published constants.
+// R's lm() and Python's scipy.stats.linregress agree with it to their printed precision.
+func AnscombeReference() Reference {
+	ref := Reference{
+		Source:     "exact rational arithmetic",
+		Tolerances: ReferenceTolerances{DefaultReferenceTolerance, DefaultReferenceTolerance, DefaultReferenceTolerance},
+		Datasets:   map[string]ReferenceValues{},
+	}
+	for name, ds := range LoadAnscombeDatasets() {
+		exact, err := RationalRegression(ds.X, ds.Y)
+		if err != nil {
+			panic(fmt.Sprintf("built-in dataset %s: %v", name, err))
+		}
+		slope, intercept, r2 := exact.Float64()
+		ref.Datasets[name] = ReferenceValues{Slope: &slope, Intercept: &intercept, RSquared: &r2}
+	}
+	return ref
+}
+
+// LoadReference reads a reference from a JSON file in the Reference format, or from a CSV file with
+// a header naming the dataset, slope, intercept and R-squared columns in any order, as written by
+// ai_python_copilot.py and ai_r_copilot.r when given an output path. Other columns are ignored.
+func LoadReference(path string) (Reference, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Reference{}, err
+	}
+	defer f.Close()
+
+	var ref Reference
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.NewDecoder(f).Decode(&ref)
+	} else {
+		ref, err = readReferenceCSV(f)
+		ref.Source = filepath.Base(path)
+	}
+	if err != nil {
+		return Reference{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(ref.Datasets) == 0 {
+		return Reference{}, fmt.Errorf("%s: no reference datasets", path)
+	}
+	for _, tol := range []*float64{&ref.Tolerances.Slope, &ref.Tolerances.Intercept, &ref.Tolerances.RSquared} {
+		if *tol < 0 {
+			return Reference{}, fmt.Errorf("%s: tolerances must not be negative", path)
+		}
+		if *tol == 0 {
+			*tol = DefaultReferenceTolerance
+		}
+	}
+	return ref, nil
+}
+
+func readReferenceCSV(r io.Reader) (Reference, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return Reference{}, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return Reference{}, fmt.Errorf("no header row found")
+	}
+
+	// header names are compared by their letters only, so "R-squared", "R_squared" and "rSquared" agree
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		key := strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) {
+				return unicode.ToLower(r)
+			}
+			return -1
+		}, name)
+		columns[key] = i
+	}
+	nameCol, ok := columns["dataset"]
+	if !ok {
+		return Reference{}, fmt.Errorf("no dataset column in header")
+	}
+
+	ref := Reference{Datasets: map[string]ReferenceValues{}}
+	for line, record := range records[1:] {
+		var values ReferenceValues
+		for key, field := range map[string]**float64{"slope": &values.Slope, "intercept": &values.Intercept, "rsquared": &values.RSquared} {
+			col, ok := columns[key]
+			if !ok || isMissingField(strings.TrimSpace(record[col])) {
+				continue
+			}
+			v, err := parseCSVFloat(record[col])
+			if err != nil {
+				return Reference{}, fmt.Errorf("line %d: invalid %s %q", line+2, key, record[col])
+			}
+			*field = &v
+		}
+		ref.Datasets[strings.TrimSpace(record[nameCol])] = values
+	}
+	return ref, nil
+}
+
+// ConformanceCheck compares one metric of one dataset with its reference
+type ConformanceCheck struct {
+	Dataset   string  `json:"dataset"`
+	Metric    string  `json:"metric"`
+	Expected  float64 `json:"expected"`
+	Actual    float64 `json:"actual"`
+	Delta     float64 `json:"delta"`
+	Tolerance float64 `json:"tolerance"`
+	Pass      bool    `json:"pass"`
+}
+
+// ConformanceReport is the outcome of checking results against a Reference. Missing lists the
+// reference datasets without a result, which fail the report.
+type ConformanceReport struct {
+	Source  string             `json:"source"`
+	Checks  []ConformanceCheck `json:"checks"`
+	Missing []string           `json:"missing,omitempty"`
+}
+
+// Passed reports whether every reference dataset has a result and every check is within tolerance
+func (r ConformanceReport) Passed() bool {
+	return len(r.Missing) == 0 && r.Failures() == 0
+}
+
+// Failures counts the checks outside their tolerance
+func (r ConformanceReport) Failures() int {
+	n := 0
+	for _, c := range r.Checks {
+		if !c.Pass {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckConformance compares results with ref by dataset name. Results without a reference entry
+// are not checked.
+func CheckConformance(ref Reference, results []RegressionResult) ConformanceReport {
+	byName := make(map[string]RegressionResult, len(results))
+	for _, r := range results {
+		byName[r.Dataset] = r
+	}
+	names := make([]string, 0, len(ref.Datasets))
+	for name := range ref.Datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	report := ConformanceReport{Source: ref.Source}
+	for _, name := range names {
+		result, ok := byName[name]
+		if !ok {
+			report.Missing = append(report.Missing, name)
+			continue
+		}
+		expected := ref.Datasets[name]
+		metrics := []struct {
+			name      string
+			expected  *float64
+			actual    float64
+			tolerance float64
+		}{
+			{"slope", expected.Slope, result.Slope, ref.Tolerances.Slope},
+			{"intercept", expected.Intercept, result.Intercept, ref.Tolerances.Intercept},
+			{"r-squared", expected.RSquared, result.RSquared, ref.Tolerances.RSquared},
+		}
+		for _, m := range metrics {
+			if m.expected == nil {
+				continue
+			}
+			delta := m.actual - *m.expected
+			report.Checks = append(report.Checks, ConformanceCheck{
+				Dataset: name, Metric: m.name, Expected: *m.expected, Actual: m.actual,
+				Delta: delta, Tolerance: m.tolerance, Pass: math.Abs(delta) <= m.tolerance,
+			})
+		}
+	}
+	return report
+}
+
+// PrintConformance writes the report as a table followed by the overall verdict
+func PrintConformance(w io.Writer, report ConformanceReport) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Dataset\tMetric\tExpected\tActual\tDelta\tTolerance\tResult\t")
+	for _, c := range report.Checks {
+		verdict := "PASS"
+		if !c.Pass {
+			verdict = "FAIL"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%.6f\t%.6f\t%+.2e\t%.0e\t%s\t\n", c.Dataset, c.Metric, c.Expected, c.Actual, c.Delta, c.Tolerance, verdict)
+	}
+	tw.Flush()
+	for _, name := range report.Missing {
+		fmt.Fprintf(w, "Missing result for reference dataset %s\n", name)
+	}
+	verdict := "PASS"
+	if !report.Passed() {
+		verdict = "FAIL"
+	}
+	fmt.Fprintf(w, "Result: %s (%d of %d checks within tolerance", verdict, len(report.Checks)-report.Failures(), len(report.Checks))
+	if len(report.Missing) > 0 {
+		fmt.Fprintf(w, ", %d datasets missing", len(report.Missing))
+	}
+	fmt.Fprintln(w, ")")
+}
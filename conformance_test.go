@@ -0,0 +1,87 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The built-in quartet conforms to its exact reference
+func TestAnscombeConformance(t *testing.T) {
+	var results []RegressionResult
+	for name, ds := range LoadAnscombeDatasets() {
+		slope, intercept, r2, err := PerformLinearRegression(ds.X, ds.Y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, RegressionResult{Dataset: name, Slope: slope, Intercept: intercept, RSquared: r2})
+	}
+	report := CheckConformance(AnscombeReference(), results)
+	if !report.Passed() || len(report.Checks) != 12 {
+		var buf bytes.Buffer
+		PrintConformance(&buf, report)
+		t.Fatalf("expected 12 passing checks:\n%s", buf.String())
+	}
+}
+
+// ✅ Test 2: Deltas beyond tolerance and missing datasets fail the report
+func TestConformanceFailures(t *testing.T) {
+	ref := AnscombeReference()
+	results := []RegressionResult{{Dataset: "I", Slope: 0.5001, Intercept: 3.000091, RSquared: 0.666542}}
+	report := CheckConformance(ref, results)
+	if report.Passed() || report.Failures() != 1 || len(report.Missing) != 3 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if c := report.Checks[0]; c.Metric != "slope" || c.Pass || c.Delta < 8e-6 || c.Delta > 1e-5 {
+		t.Errorf("unexpected slope check %+v", c)
+	}
+
+	var buf bytes.Buffer
+	PrintConformance(&buf, report)
+	if out := buf.String(); !strings.Contains(out, "FAIL") || !strings.Contains(out, "Missing result for reference dataset II") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+// ✅ Test 3: CSV references with R or Python headers, and JSON with per-field tolerances
+func TestLoadReference(t *testing.T) {
+	dir := t.TempDir()
+	rCSV := filepath.Join(dir, "r.csv")
+	os.WriteFile(rCSV, []byte("\"Dataset\",\"Intercept\",\"Slope\",\"R_squared\",\"Adj_R_squared\",\"P_value\"\n\"I\",3.00009090909091,0.500090909090909,0.666542459508775,0.629491621676416,0.00216962887307879\n"), 0o644)
+	ref, err := LoadReference(rCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ref.Datasets["I"]; v.Slope == nil || *v.RSquared != 0.666542459508775 || ref.Tolerances.Intercept != DefaultReferenceTolerance {
+		t.Fatalf("unexpected reference %+v", ref)
+	}
+
+	pyCSV := filepath.Join(dir, "py.csv")
+	os.WriteFile(pyCSV, []byte("Dataset,Intercept,Slope,R-squared\nII,3.000909,0.5,\n"), 0o644)
+	ref, err = LoadReference(pyCSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := ref.Datasets["II"]; v.RSquared != nil || *v.Slope != 0.5 {
+		t.Fatalf("expected an empty R-squared to be left unchecked, got %+v", v)
+	}
+
+	js := filepath.Join(dir, "ref.json")
+	os.WriteFile(js, []byte(`{"source": "hand", "tolerances": {"slope": 0.01}, "datasets": {"I": {"slope": 0.5}}}`), 0o644)
+	ref, err = LoadReference(js)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := CheckConformance(ref, []RegressionResult{{Dataset: "I", Slope: 0.505}})
+	if !report.Passed() || len(report.Checks) != 1 || report.Source != "hand" {
+		t.Fatalf("expected a single passing slope check, got %+v", report)
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	os.WriteFile(bad, []byte("name,slope\nI,0.5\n"), 0o644)
+	if _, err := LoadReference(bad); err == nil {
+		t.Error("expected an error without a dataset column")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// EngineFit is the fit of one engine in a consistency check; Err is set when the engine rejected the data
+type EngineFit struct {
+	Engine    string  `json:"engine"`
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+	Err       string  `json:"error,omitempty"`
+}
+
+// Disagreement is a quantity on which two engines differ by more than the tolerance. Deviation is
+// measured like the bench harness, relative to max(|B|, 1); it is +Inf when only one engine failed.
+type Disagreement struct {
+	Quantity  string  `json:"quantity"`
+	EngineA   string  `json:"engineA"`
+	EngineB   string  `json:"engineB"`
+	A         float64 `json:"a"`
+	B         float64 `json:"b"`
+	Deviation float64 `json:"deviation"`
+}
+
+// ConsistencyReport is the outcome of fitting one dataset with several engines
+type ConsistencyReport struct {
+	Tolerance     float64        `json:"tolerance"`
+	Fits          []EngineFit    `json:"fits"`
+	Disagreements []Disagreement `json:"disagreements,omitempty"`
+}
+
+// Consistent reports whether every pair of engines agreed within the tolerance
+func (r ConsistencyReport) Consistent() bool {
+	return len(r.Disagreements) == 0
+}
+
+// CheckConsistency fits ds with each of engines (every registered engine when none are given) and
+// reports each pair that disagrees on slope, intercept or R² by more than tol, or where one engine
+// fails and the other does not. Engines that both fail agree.
+func CheckConsistency(ds Dataset, tol float64, engines ...Engine) ConsistencyReport {
+	if len(engines) == 0 {
+		engines = Engines()
+	}
+	report := ConsistencyReport{Tolerance: tol}
+	for _, e := range engines {
+		fit := EngineFit{Engine: e.Name}
+		var err error
+		fit.Slope, fit.Intercept, fit.RSquared, err = e.Fit(ds.X, ds.Y)
+		if err != nil {
+			fit = EngineFit{Engine: e.Name, Err: err.Error()}
+		}
+		report.Fits = append(report.Fits, fit)
+	}
+
+	for i, a := range report.Fits {
+		for _, b := range report.Fits[i+1:] {
+			switch {
+			case a.Err != "" && b.Err != "":
+				continue
+			case a.Err != "" || b.Err != "":
+				report.Disagreements = append(report.Disagreements, Disagreement{Quantity: "error", EngineA: a.Engine, EngineB: b.Engine, Deviation: math.Inf(1)})
+				continue
+			}
+			quantities := []struct {
+				name string
+				a, b float64
+			}{
+				{"slope", a.Slope, b.Slope},
+				{"intercept", a.Intercept, b.Intercept},
+				{"r-squared", a.RSquared, b.RSquared},
+			}
+			for _, q := range quantities {
+				// NaN compares false, so a NaN on either side counts as a disagreement
+				if d := deviation(q.a, q.b); !(d <= tol) {
+					report.Disagreements = append(report.Disagreements, Disagreement{Quantity: q.name, EngineA: a.Engine, EngineB: b.Engine, A: q.a, B: q.b, Deviation: d})
+				}
+			}
+		}
+	}
+	return report
+}
+
+// printConsistency writes a one-line verdict, followed by the disagreements and failed engines if any
+func printConsistency(w io.Writer, report ConsistencyReport) {
+	if report.Consistent() {
+		fmt.Fprintf(w, "  Engines:   %d agree within %.0e\n", len(report.Fits), report.Tolerance)
+		return
+	}
+	fmt.Fprintf(w, "  Engines:   %d disagreements beyond %.0e\n", len(report.Disagreements), report.Tolerance)
+	for _, f := range report.Fits {
+		if f.Err != "" {
+			fmt.Fprintf(w, "    %s failed: %s\n", f.Engine, f.Err)
+		}
+	}
+	for _, d := range report.Disagreements {
+		if d.Quantity == "error" {
+			continue
+		}
+		fmt.Fprintf(w, "    %-9s %s %.10g vs %s %.10g (%.1e)\n", d.Quantity, d.EngineA, d.A, d.EngineB, d.B, d.Deviation)
+	}
+}
+
+// parseEngineList looks up a comma-separated list of engine names; an empty list selects every engine
+func parseEngineList(list string) ([]Engine, error) {
+	if list == "" {
+		return Engines(), nil
+	}
+	var selected []Engine
+	for _, name := range strings.Split(list, ",") {
+		e, ok := LookupEngine(strings.TrimSpace(name))
+		if !ok {
+			return nil, fmt.Errorf("unknown engine %q", name)
+		}
+		selected = append(selected, e)
+	}
+	return selected, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The double-precision engines agree on Anscombe I; float32 only to single precision
+func TestCheckConsistencyAnscombe(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	engines, err := parseEngineList("stats, manual,compensated,fast,parallel,bigfloat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report := CheckConsistency(ds, 1e-12, engines...); !report.Consistent() || len(report.Fits) != 6 {
+		t.Errorf("expected 6 consistent fits, got %+v", report)
+	}
+
+	report := CheckConsistency(ds, 1e-12)
+	if len(report.Fits) != len(Engines()) || report.Consistent() {
+		t.Fatalf("expected float32 to disagree at 1e-12, got %+v", report)
+	}
+	for _, d := range report.Disagreements {
+		if (d.EngineA != "float32" && d.EngineB != "float32") || d.Deviation > 1e-6 {
+			t.Errorf("unexpected disagreement %+v", d)
+		}
+	}
+	if !CheckConsistency(ds, 1e-6).Consistent() {
+		t.Error("every engine should agree within 1e-6")
+	}
+
+	if _, err := parseEngineList("manual,nope"); err == nil {
+		t.Error("expected an unknown engine error")
+	}
+}
+
+// ✅ Test 2: An engine failing alone, or returning NaN, is reported; engines failing together agree
+func TestCheckConsistencyFailures(t *testing.T) {
+	manual, _ := LookupEngine("manual")
+	failing := Engine{Name: "failing", Fit: func(x, y []float64) (float64, float64, float64, error) {
+		return 0, 0, 0, errors.New("boom")
+	}}
+	broken := Engine{Name: "nan", Fit: func(x, y []float64) (float64, float64, float64, error) {
+		slope, intercept, _, err := manual.Fit(x, y)
+		return slope, intercept, math.NaN(), err
+	}}
+
+	report := CheckConsistency(LoadAnscombeDatasets()["II"], 1e-9, manual, failing, broken)
+	if len(report.Disagreements) != 3 {
+		t.Fatalf("expected 3 disagreements, got %+v", report.Disagreements)
+	}
+	if d := report.Disagreements[0]; d.Quantity != "error" || d.EngineB != "failing" || !math.IsInf(d.Deviation, 1) {
+		t.Errorf("unexpected first disagreement %+v", d)
+	}
+	if d := report.Disagreements[1]; d.Quantity != "r-squared" || d.EngineA != "manual" || d.EngineB != "nan" {
+		t.Errorf("unexpected second disagreement %+v", d)
+	}
+
+	var out bytes.Buffer
+	printConsistency(&out, report)
+	if !strings.Contains(out.String(), "failing failed: boom") || !strings.Contains(out.String(), "3 disagreements") {
+		t.Errorf("unexpected output:\n%s", out.String())
+	}
+
+	if report := CheckConsistency(Dataset{X: []float64{1}, Y: []float64{2}}, 1e-9, manual, failing); !report.Consistent() {
+		t.Errorf("engines failing together should agree, got %+v", report.Disagreements)
+	}
+}
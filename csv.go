@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadCSVDataset reads x,y pairs from the first two columns of a CSV stream.
+// A leading header row is skipped when its first two fields are not numeric; its column names
+// become the dataset's axes, with units written as "dose (mg)" or "dose [mg]" (see ParseAxis).
+func LoadCSVDataset(r io.Reader) (Dataset, error) {
+	return LoadCSVDatasetWithLocale(r, NumberLocale{})
+}
+
+// LoadCSVDatasetWithLocale is LoadCSVDataset for a stream written in locale l, such as German
+// exports with decimal commas and ';' between fields
+func LoadCSVDatasetWithLocale(r io.Reader, l NumberLocale) (Dataset, error) {
+	src := NewCSVSourceWithLocale(r, l)
+
+	var ds Dataset
+	for {
+		x, y, ok := src.Next()
+		if !ok {
+			break
+		}
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, y)
+	}
+	if err := src.Err(); err != nil {
+		return Dataset{}, err
+	}
+	if header := src.Header(); header != nil {
+		ds.XAxis, ds.YAxis = ParseAxis(header[0]), ParseAxis(header[1])
+	}
+
+	if len(ds.X) == 0 {
+		return Dataset{}, fmt.Errorf("no data rows found")
+	}
+	return ds, nil
+}
+
+// LoadCSVFile opens path and loads it with LoadCSVDataset
+func LoadCSVFile(path string) (Dataset, error) {
+	return LoadCSVFileWithLocale(path, NumberLocale{})
+}
+
+// LoadCSVFileWithLocale opens path and loads it with LoadCSVDatasetWithLocale
+func LoadCSVFileWithLocale(path string, l NumberLocale) (Dataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Dataset{}, err
+	}
+	defer f.Close()
+
+	ds, err := LoadCSVDatasetWithLocale(f, l)
+	if err != nil {
+		return Dataset{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return ds, nil
+}
+
+// parseCSVFloat parses a numeric field; empty and NA fields become NaN so regression can skip them
+func parseCSVFloat(field string) (float64, error) {
+	if isMissingField(field) {
+		return math.NaN(), nil
+	}
+	return strconv.ParseFloat(strings.TrimSpace(field), 64)
+}
+
+// isMissingField reports whether a CSV field is one of the spellings parseCSVFloat reads as NaN
+func isMissingField(field string) bool {
+	switch strings.ToUpper(strings.TrimSpace(field)) {
+	case "", "NA", "NAN", "NULL":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Header row is skipped and missing values become NaN
+func TestLoadCSVDataset(t *testing.T) {
+	ds, err := LoadCSVDataset(strings.NewReader("x,y\n1,2\n2,NA\n3, 6\n"))
+	if err != nil {
+		t.Fatalf("load failed: %v", err)
+	}
+	if len(ds.X) != 3 || ds.X[2] != 3 || ds.Y[2] != 6 {
+		t.Errorf("unexpected dataset: %+v", ds)
+	}
+	if !math.IsNaN(ds.Y[1]) {
+		t.Errorf("NA should load as NaN, got %v", ds.Y[1])
+	}
+}
+
+// ✅ Test 2: Malformed input is rejected with the offending line
+func TestLoadCSVDatasetErrors(t *testing.T) {
+	for _, input := range []string{"", "x,y\n", "1\n", "1,2\nfoo,3\n"} {
+		if _, err := LoadCSVDataset(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
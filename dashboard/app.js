@@ -0,0 +1,173 @@
+"use strict";
+
+const W = 640, H = 420, PAD = 40;
+const svgNS = "http://www.w3.org/2000/svg";
+let views = [];
+
+function el(name, attrs, text) {
+  const node = document.createElementNS(svgNS, name);
+  for (const [k, v] of Object.entries(attrs)) node.setAttribute(k, v);
+  if (text !== undefined) node.textContent = text;
+  return node;
+}
+
+// row makes a table row of plain-text cells, as names and units come from uploaded files
+function row(label, value, className) {
+  const tr = document.createElement("tr");
+  for (const text of [label, value]) {
+    const td = document.createElement("td");
+    td.textContent = text;
+    tr.append(td);
+  }
+  if (className) tr.lastChild.className = className;
+  return tr;
+}
+
+function extent(values) {
+  let lo = Math.min(...values), hi = Math.max(...values);
+  if (lo === hi) { lo -= 1; hi += 1; }
+  const pad = (hi - lo) * 0.08;
+  return [lo - pad, hi + pad];
+}
+
+function axisText(axis, fallback) {
+  if (!axis || (!axis.label && !axis.unit)) return fallback;
+  return axis.unit ? `${axis.label || fallback} (${axis.unit})` : axis.label;
+}
+
+function withUnit(value, unit) {
+  return unit ? `${value.toFixed(6)} ${unit}` : value.toFixed(6);
+}
+
+function slopeUnit(view) {
+  const x = view.xAxis?.unit, y = view.yAxis?.unit;
+  if (!x) return y;
+  return y ? `${y}/${x}` : `1/${x}`;
+}
+
+function plot(view) {
+  const svg = document.getElementById("plot");
+  svg.replaceChildren();
+  const [x0, x1] = extent(view.x);
+  const [y0, y1] = extent(view.band ? view.y.concat(view.band.lower, view.band.upper) : view.y);
+  const sx = x => PAD + (x - x0) / (x1 - x0) * (W - 2 * PAD);
+  const sy = y => H - PAD - (y - y0) / (y1 - y0) * (H - 2 * PAD);
+
+  svg.append(el("line", { class: "axis", x1: PAD, y1: H - PAD, x2: W - PAD, y2: H - PAD }));
+  svg.append(el("line", { class: "axis", x1: PAD, y1: PAD, x2: PAD, y2: H - PAD }));
+  for (let i = 0; i <= 4; i++) {
+    const xv = x0 + (x1 - x0) * i / 4, yv = y0 + (y1 - y0) * i / 4;
+    svg.append(el("text", { x: sx(xv), y: H - PAD + 16, "text-anchor": "middle" }, xv.toFixed(1)));
+    svg.append(el("text", { x: PAD - 6, y: sy(yv) + 4, "text-anchor": "end" }, yv.toFixed(1)));
+  }
+  svg.append(el("text", { class: "title", x: W / 2, y: H - 6, "text-anchor": "middle" }, axisText(view.xAxis, "x")));
+  svg.append(el("text", { class: "title", x: 12, y: H / 2, "text-anchor": "middle", transform: `rotate(-90 12 ${H / 2})` }, axisText(view.yAxis, "y")));
+
+  // prediction band, then the narrower confidence band, under the line and points
+  const band = view.band;
+  if (band) {
+    const area = (lower, upper) => band.x.map((x, i) => `${sx(x)},${sy(upper[i])}`)
+      .concat(band.x.map((x, i) => `${sx(x)},${sy(lower[i])}`).reverse()).join(" ");
+    svg.append(el("polygon", { class: "band prediction", points: area(band.lower, band.upper) }));
+    svg.append(el("polygon", { class: "band", points: area(band.meanLower, band.meanUpper) }));
+  }
+
+  const f = view.fit;
+  svg.append(el("line", {
+    class: "fit",
+    x1: sx(x0), y1: sy(f.intercept + f.slope * x0),
+    x2: sx(x1), y2: sy(f.intercept + f.slope * x1),
+  }));
+
+  // the what-if line without the points clicked out of the plot
+  const r = view.refit;
+  if (r) {
+    svg.append(el("line", {
+      class: "fit refit",
+      x1: sx(x0), y1: sy(r.intercept + r.slope * x0),
+      x2: sx(x1), y2: sy(r.intercept + r.slope * x1),
+    }));
+  }
+
+  const hover = document.getElementById("hover");
+  const excluded = view.excluded || new Set();
+  view.x.forEach((x, i) => {
+    const y = view.y[i];
+    const dot = el("circle", { cx: sx(x), cy: sy(y), r: 5, class: excluded.has(i) ? "excluded" : "" });
+    dot.addEventListener("mouseenter", () => {
+      const fitted = f.intercept + f.slope * x;
+      hover.textContent = `x = ${x}, y = ${y}, fitted = ${fitted.toFixed(4)}, residual = ${(y - fitted).toFixed(4)} (click to ${excluded.has(i) ? "include" : "exclude"})`;
+    });
+    dot.addEventListener("click", () => toggle(view, i));
+    svg.append(dot);
+  });
+
+  document.getElementById("title").textContent = `Dataset ${view.name}`;
+  const table = document.getElementById("fit");
+  table.replaceChildren(
+    row("Slope", withUnit(f.slope, slopeUnit(view))),
+    row("Intercept", withUnit(f.intercept, view.yAxis?.unit)),
+    row("R-squared", f.rSquared.toFixed(6)),
+    row("Points", view.x.length));
+  if (r) {
+    table.append(row(`Without ${r.without.length}`,
+      `slope ${withUnit(r.slope, slopeUnit(view))} (${r.slopeChange >= 0 ? "+" : ""}${r.slopeChange.toFixed(6)}), ` +
+      `intercept ${withUnit(r.intercept, view.yAxis?.unit)}, R-squared ${r.rSquared.toFixed(6)}`));
+  } else if (view.refitError) {
+    table.append(row(`Without ${excluded.size}`, view.refitError, "error"));
+  }
+}
+
+// toggle excludes point i from the what-if refit, or brings it back, and refits
+async function toggle(view, i) {
+  view.excluded = view.excluded || new Set();
+  if (!view.excluded.delete(i)) view.excluded.add(i);
+  view.refit = null;
+  view.refitError = null;
+  if (view.excluded.size > 0) {
+    const resp = await fetch("api/refit", {
+      method: "POST",
+      headers: { "Content-Type": "application/json" },
+      body: JSON.stringify({ x: view.x, y: view.y, without: [...view.excluded] }),
+    });
+    const body = await resp.json();
+    if (resp.ok) view.refit = body; else view.refitError = body.error;
+  }
+  plot(view);
+}
+
+function renderList(selected) {
+  const list = document.getElementById("datasets");
+  list.replaceChildren();
+  views.forEach(view => {
+    const li = document.createElement("li");
+    li.textContent = view.name;
+    if (view === selected) li.className = "active";
+    li.addEventListener("click", () => { renderList(view); plot(view); });
+    list.append(li);
+  });
+}
+
+async function load() {
+  const resp = await fetch("api/datasets");
+  views = await resp.json();
+  if (views.length > 0) { renderList(views[0]); plot(views[0]); }
+}
+
+document.getElementById("upload").addEventListener("submit", async event => {
+  event.preventDefault();
+  const resp = await fetch("api/upload", { method: "POST", body: new FormData(event.target) });
+  const body = await resp.json();
+  if (!resp.ok) {
+    const title = document.getElementById("title");
+    title.textContent = body.error;
+    title.className = "error";
+    return;
+  }
+  document.getElementById("title").className = "";
+  views.push(body);
+  renderList(body);
+  plot(body);
+});
+
+load();
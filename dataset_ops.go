@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Merge returns a new dataset with the points of a followed by the points of b.
+// Neither input is modified, so merging files in argument order is deterministic.
+// Metadata present on only one side is padded with weight 1, an empty label and no tags.
+func Merge(a, b Dataset) (Dataset, error) {
+	return MergeWithOptions(a, b, MergeOptions{})
+}
+
+// MergeOptions controls the checks MergeWithOptions applies before combining datasets
+type MergeOptions struct {
+	// CheckUnits rejects datasets whose axes carry different units. An axis without a unit
+	// matches any unit, so data with and without headers can still be merged.
+	CheckUnits bool
+}
+
+// MergeWithOptions is Merge with optional unit-consistency checks.
+// The merged axes are a's, or b's where a has none.
+func MergeWithOptions(a, b Dataset, opts MergeOptions) (Dataset, error) {
+	if len(a.X) != len(a.Y) || len(b.X) != len(b.Y) {
+		return Dataset{}, fmt.Errorf("x and y length mismatch")
+	}
+	if err := a.validateMetadata(); err != nil {
+		return Dataset{}, err
+	}
+	if err := b.validateMetadata(); err != nil {
+		return Dataset{}, err
+	}
+	if opts.CheckUnits {
+		if err := checkUnits("x", a.XAxis, b.XAxis); err != nil {
+			return Dataset{}, err
+		}
+		if err := checkUnits("y", a.YAxis, b.YAxis); err != nil {
+			return Dataset{}, err
+		}
+	}
+
+	out := Dataset{X: make([]float64, 0, len(a.X)+len(b.X)), Y: make([]float64, 0, len(a.Y)+len(b.Y))}
+	out.XAxis, out.YAxis = a.XAxis.or(b.XAxis), a.YAxis.or(b.YAxis)
+	out.X = append(append(out.X, a.X...), b.X...)
+	out.Y = append(append(out.Y, a.Y...), b.Y...)
+	if a.Weights != nil || b.Weights != nil {
+		out.Weights = append(padded(a.Weights, len(a.X), 1), padded(b.Weights, len(b.X), 1)...)
+	}
+	if a.Labels != nil || b.Labels != nil {
+		out.Labels = append(padded(a.Labels, len(a.X), ""), padded(b.Labels, len(b.X), "")...)
+	}
+	if a.Tags != nil || b.Tags != nil {
+		out.Tags = append(padded(a.Tags, len(a.X), nil), padded(b.Tags, len(b.X), nil)...)
+	}
+	return out, nil
+}
+
+// padded returns a copy of values, or n copies of fill when values is nil
+func padded[T any](values []T, n int, fill T) []T {
+	if values != nil {
+		return append([]T(nil), values...)
+	}
+	out := make([]T, n)
+	for i := range out {
+		out[i] = fill
+	}
+	return out
+}
+
+// Append adds one point to the end of ds. Like the built-in append it may reuse ds's backing arrays.
+// Metadata carried by ds is extended with weight 1, an empty label and no tags.
+func Append(ds Dataset, x, y float64) Dataset {
+	ds.X, ds.Y = append(ds.X, x), append(ds.Y, y)
+	if ds.Weights != nil {
+		ds.Weights = append(ds.Weights, 1)
+	}
+	if ds.Labels != nil {
+		ds.Labels = append(ds.Labels, "")
+	}
+	if ds.Tags != nil {
+		ds.Tags = append(ds.Tags, nil)
+	}
+	return ds
+}
+
+// Dedup removes points whose X and Y are both within tol of an earlier kept point, keeping the
+// first occurrence (with its metadata) and the original order. tol = 0 removes exact duplicates only; points with
+// NaN/Inf values are always kept.
+func Dedup(ds Dataset, tol float64) (Dataset, error) {
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	if err := ds.validateMetadata(); err != nil {
+		return Dataset{}, err
+	}
+	if !(tol >= 0) || math.IsInf(tol, 0) {
+		return Dataset{}, fmt.Errorf("dedup tolerance must be a non-negative number, got %v", tol)
+	}
+
+	// kept points are hashed into a grid of tol-sized cells, so a new point is only compared
+	// with the kept points in its own and the eight neighbouring cells
+	type cell struct{ cx, cy float64 }
+	grid := map[cell][]int{}
+	cellOf := func(x, y float64) cell {
+		if tol == 0 {
+			return cell{x, y}
+		}
+		return cell{math.Floor(x / tol), math.Floor(y / tol)}
+	}
+
+	out := Dataset{XAxis: ds.XAxis, YAxis: ds.YAxis}
+	for i := range ds.X {
+		x, y := ds.X[i], ds.Y[i]
+		if isMissing(x) || isMissing(y) {
+			out.appendPoint(ds, i)
+			continue
+		}
+
+		c := cellOf(x, y)
+		duplicate := false
+		for dx := -1.0; dx <= 1 && !duplicate; dx++ {
+			for dy := -1.0; dy <= 1 && !duplicate; dy++ {
+				for _, k := range grid[cell{c.cx + dx, c.cy + dy}] {
+					if math.Abs(out.X[k]-x) <= tol && math.Abs(out.Y[k]-y) <= tol {
+						duplicate = true
+						break
+					}
+				}
+			}
+		}
+		if duplicate {
+			continue
+		}
+		grid[c] = append(grid[c], len(out.X))
+		out.appendPoint(ds, i)
+	}
+	return out, nil
+}
+
+// mergeFiles loads the CSV files, written in locale l, in order and merges them into one dataset,
+// deduplicated with tolerance dedupTol unless it is negative
+func mergeFiles(paths []string, dedupTol float64, opts MergeOptions, l NumberLocale) (Dataset, error) {
+	var merged Dataset
+	for _, path := range paths {
+		data, err := LoadCSVFileWithLocale(path, l)
+		if err != nil {
+			return Dataset{}, err
+		}
+		if merged, err = MergeWithOptions(merged, data, opts); err != nil {
+			return Dataset{}, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	if dedupTol < 0 {
+		return merged, nil
+	}
+	return Dedup(merged, dedupTol)
+}
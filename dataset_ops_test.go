@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Merge and Append keep points in a deterministic order without touching the inputs
+func TestMergeAppend(t *testing.T) {
+	a := Dataset{X: []float64{1, 2}, Y: []float64{10, 20}}
+	b := Dataset{X: []float64{3}, Y: []float64{30}}
+
+	merged, err := Merge(a, b)
+	if err != nil || !slices.Equal(merged.X, []float64{1, 2, 3}) || !slices.Equal(merged.Y, []float64{10, 20, 30}) {
+		t.Errorf("unexpected merge %+v (err %v)", merged, err)
+	}
+	merged.X[0] = 99
+	if a.X[0] != 1 {
+		t.Error("merge must copy its inputs")
+	}
+	if _, err := Merge(a, Dataset{X: []float64{1}}); err == nil {
+		t.Error("expected length mismatch error")
+	}
+
+	appended := Append(b, 4, 40)
+	if !slices.Equal(appended.X, []float64{3, 4}) || !slices.Equal(appended.Y, []float64{30, 40}) {
+		t.Errorf("unexpected append %+v", appended)
+	}
+}
+
+// ✅ Test 2: Dedup keeps first occurrences in input order within the tolerance
+func TestDedup(t *testing.T) {
+	ds := Dataset{
+		X: []float64{1, 2, 1, 1.05, 5, math.NaN(), math.NaN(), 2},
+		Y: []float64{1, 2, 1, 0.96, 5, 1, 1, 2.2},
+	}
+
+	exact, _ := Dedup(ds, 0)
+	if len(exact.X) != 7 || exact.X[2] != 1.05 {
+		t.Errorf("exact dedup: %+v", exact)
+	}
+
+	near, _ := Dedup(ds, 0.1)
+	// (1.05, 0.96) is within 0.1 of (1, 1); (2, 2.2) is not within 0.1 of (2, 2); NaN points are kept
+	if !slices.Equal(near.Y, []float64{1, 2, 5, 1, 1, 2.2}) || near.X[0] != 1 || near.X[2] != 5 {
+		t.Errorf("near dedup: %+v", near)
+	}
+
+	if _, err := Dedup(ds, -1); err == nil {
+		t.Error("expected negative tolerance error")
+	}
+}
+
+// ✅ Test 3: Files are merged in argument order and deduplicated
+func TestMergeFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.csv")
+	second := filepath.Join(dir, "b.csv")
+	os.WriteFile(first, []byte("x,y\n1,2\n2,4\n"), 0o644)
+	os.WriteFile(second, []byte("x,y\n2,4\n3,6\n"), 0o644)
+
+	merged, err := mergeFiles([]string{first, second}, -1, MergeOptions{}, NumberLocale{})
+	if err != nil || len(merged.X) != 4 {
+		t.Errorf("expected 4 points, got %+v (err %v)", merged, err)
+	}
+	deduped, err := mergeFiles([]string{first, second}, 0, MergeOptions{}, NumberLocale{})
+	if err != nil || !slices.Equal(deduped.X, []float64{1, 2, 3}) {
+		t.Errorf("expected duplicates removed, got %+v (err %v)", deduped, err)
+	}
+}
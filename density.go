@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BandwidthRule selects the kernel density bandwidth from the data
+type BandwidthRule string
+
+const (
+	BandwidthSilverman BandwidthRule = "silverman" // 0.9 min(sd, IQR/1.34) n^(-1/5), R's bw.nrd0
+	BandwidthScott     BandwidthRule = "scott"     // 1.06 min(sd, IQR/1.34) n^(-1/5), R's bw.nrd
+)
+
+// SelectBandwidth returns the bandwidth rule gives for the finite values. The empty rule means
+// Silverman's. As in R, a zero spread falls back to the standard deviation, then |x|, then 1.
+func SelectBandwidth(values []float64, rule BandwidthRule) (float64, error) {
+	sorted := sortedFinite(values)
+	n := len(sorted)
+	if n < 2 {
+		return 0, fmt.Errorf("need at least two finite values, have %d", n)
+	}
+	factor := 0.9
+	switch rule {
+	case BandwidthSilverman, "":
+	case BandwidthScott:
+		factor = 1.06
+	default:
+		return 0, fmt.Errorf("unknown bandwidth rule %q (use silverman or scott)", rule)
+	}
+
+	_, variance := meanVariance(sorted)
+	sd := math.Sqrt(variance)
+	spread := math.Min(sd, (quantileSorted(sorted, 0.75)-quantileSorted(sorted, 0.25))/1.34)
+	switch {
+	case spread > 0:
+	case sd > 0:
+		spread = sd
+	case sorted[0] != 0:
+		spread = math.Abs(sorted[0])
+	default:
+		spread = 1
+	}
+	return factor * spread * math.Pow(float64(n), -0.2), nil
+}
+
+// KDE is a Gaussian kernel density estimate
+type KDE struct {
+	Points    []float64 `json:"points"`
+	Bandwidth float64   `json:"bandwidth"`
+}
+
+// NewKDE builds a density estimate of the finite values. A bandwidth of 0 or less is chosen
+// with Silverman's rule.
+func NewKDE(values []float64, bandwidth float64) (KDE, error) {
+	points := sortedFinite(values)
+	if len(points) == 0 {
+		return KDE{}, fmt.Errorf("no finite values")
+	}
+	if !(bandwidth > 0) {
+		var err error
+		if bandwidth, err = SelectBandwidth(points, BandwidthSilverman); err != nil {
+			return KDE{}, err
+		}
+	}
+	return KDE{Points: points, Bandwidth: bandwidth}, nil
+}
+
+// Density evaluates the estimate at x
+func (k KDE) Density(x float64) float64 {
+	var sum float64
+	for _, p := range k.Points {
+		u := (x - p) / k.Bandwidth
+		sum += math.Exp(-u * u / 2)
+	}
+	return sum / (float64(len(k.Points)) * k.Bandwidth * math.Sqrt(2*math.Pi))
+}
+
+// Curve evaluates the estimate at n evenly spaced points from three bandwidths below the smallest
+// value to three above the largest, R's density() default range
+func (k KDE) Curve(n int) (xs, ys []float64) {
+	lo := k.Points[0] - 3*k.Bandwidth
+	hi := k.Points[len(k.Points)-1] + 3*k.Bandwidth
+	xs, ys = make([]float64, n), make([]float64, n)
+	for i := range xs {
+		xs[i] = lo
+		if n > 1 {
+			xs[i] = lo + (hi-lo)*float64(i)/float64(n-1)
+		}
+		ys[i] = k.Density(xs[i])
+	}
+	return xs, ys
+}
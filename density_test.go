@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Bandwidths match R's bw.nrd0 and bw.nrd
+func TestSelectBandwidth(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	silverman, err := SelectBandwidth(values, BandwidthSilverman)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 0.9 * (2 / 1.34) * math.Pow(5, -0.2); math.Abs(silverman-want) > 1e-12 {
+		t.Errorf("Silverman bandwidth %v, want %v", silverman, want)
+	}
+	scott, _ := SelectBandwidth(values, BandwidthScott)
+	if math.Abs(scott/silverman-1.06/0.9) > 1e-12 {
+		t.Errorf("Scott bandwidth %v is not 1.06/0.9 of Silverman's", scott)
+	}
+	// constant data falls back to |x|
+	if bw, _ := SelectBandwidth([]float64{4, 4, 4}, ""); math.Abs(bw-0.9*4*math.Pow(3, -0.2)) > 1e-12 {
+		t.Errorf("constant-data bandwidth %v", bw)
+	}
+	if _, err := SelectBandwidth([]float64{1}, ""); err == nil {
+		t.Error("expected an error for a single value")
+	}
+}
+
+// ✅ Test 2: The density of one point is a normal curve and the estimate integrates to one
+func TestKDEDensity(t *testing.T) {
+	single := KDE{Points: []float64{0}, Bandwidth: 1}
+	if math.Abs(single.Density(0)-1/math.Sqrt(2*math.Pi)) > 1e-12 || math.Abs(single.Density(1)-math.Exp(-0.5)/math.Sqrt(2*math.Pi)) > 1e-12 {
+		t.Errorf("unexpected density %v at 0", single.Density(0))
+	}
+
+	kde, err := NewKDE([]float64{1, 2, 2.5, 4, 7, math.NaN()}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kde.Points) != 5 || kde.Bandwidth <= 0 {
+		t.Fatalf("unexpected estimate %+v", kde)
+	}
+	xs, ys := kde.Curve(512)
+	var area float64
+	for i := 1; i < len(xs); i++ {
+		area += (ys[i] + ys[i-1]) / 2 * (xs[i] - xs[i-1])
+	}
+	// the curve stops three bandwidths out, leaving about 0.27% of each kernel outside
+	if math.Abs(area-1) > 0.005 {
+		t.Errorf("density integrates to %v", area)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// PointDiagnostic describes how a single observation relates to the fitted line.
+// Quantities that are undefined for a point (e.g. Cook's distance when leverage is 1) are NaN.
+type PointDiagnostic struct {
+	Index                int     `json:"index"`
+	X                    float64 `json:"x"`
+	Y                    float64 `json:"y"`
+	Fitted               float64 `json:"fitted"`
+	Residual             float64 `json:"residual"`
+	Leverage             float64 `json:"leverage"`
+	StandardizedResidual float64 `json:"standardizedResidual"`
+	CooksDistance        float64 `json:"cooksDistance"`
+	// SlopeChange is how much the slope would change if the point were removed (DFBETA, unscaled)
+	SlopeChange float64  `json:"slopeChange"`
+	Label       string   `json:"label,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// MarshalJSON encodes NaN/Inf diagnostics as null, since JSON has no representation for them
+func (d PointDiagnostic) MarshalJSON() ([]byte, error) {
+	nullable := func(v float64) *float64 {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil
+		}
+		return &v
+	}
+	return json.Marshal(struct {
+		Index                int      `json:"index"`
+		X                    float64  `json:"x"`
+		Y                    float64  `json:"y"`
+		Fitted               float64  `json:"fitted"`
+		Residual             float64  `json:"residual"`
+		Leverage             *float64 `json:"leverage"`
+		StandardizedResidual *float64 `json:"standardizedResidual"`
+		CooksDistance        *float64 `json:"cooksDistance"`
+		SlopeChange          *float64 `json:"slopeChange"`
+		Label                string   `json:"label,omitempty"`
+		Tags                 []string `json:"tags,omitempty"`
+	}{d.Index, d.X, d.Y, d.Fitted, d.Residual, nullable(d.Leverage), nullable(d.StandardizedResidual), nullable(d.CooksDistance), nullable(d.SlopeChange), d.Label, d.Tags})
+}
+
+// PointDiagnostics computes fitted values, residuals, leverage, standardized residuals, Cook's
+// distance and the leave-one-out change in slope for every valid (non NaN/Inf) pair. Index refers
+// to the position in the input slices.
+func PointDiagnostics(x, y []float64, slope, intercept float64) []PointDiagnostic {
+	isInvalid := func(v float64) bool {
+		return math.IsNaN(v) || math.IsInf(v, 0)
+	}
+
+	var n, sumX float64
+	for i := range x {
+		if i >= len(y) || isInvalid(x[i]) || isInvalid(y[i]) {
+			continue
+		}
+		n++
+		sumX += x[i]
+	}
+	if n == 0 {
+		return nil
+	}
+	meanX := sumX / n
+
+	diags := make([]PointDiagnostic, 0, int(n))
+	var sxx, sse float64
+	for i := range x {
+		if i >= len(y) || isInvalid(x[i]) || isInvalid(y[i]) {
+			continue
+		}
+		fitted := intercept + slope*x[i]
+		residual := y[i] - fitted
+		sxx += (x[i] - meanX) * (x[i] - meanX)
+		sse += residual * residual
+		diags = append(diags, PointDiagnostic{Index: i, X: x[i], Y: y[i], Fitted: fitted, Residual: residual})
+	}
+
+	// mean squared error of the two-parameter model
+	mse := math.NaN()
+	if n > 2 {
+		mse = sse / (n - 2)
+	}
+
+	for i := range diags {
+		d := &diags[i]
+		if sxx > 0 {
+			d.Leverage = 1/n + (d.X-meanX)*(d.X-meanX)/sxx
+		} else {
+			d.Leverage = 1 / n
+		}
+
+		// removing a point moves the slope by -(x - x̄)·e / (Sxx·(1 - h)); with leverage 1 the
+		// remaining X values are all equal and the slope is undefined
+		d.SlopeChange = math.NaN()
+		if sxx > 0 && d.Leverage < 1 {
+			d.SlopeChange = -(d.X - meanX) * d.Residual / (sxx * (1 - d.Leverage))
+		}
+
+		d.StandardizedResidual = math.NaN()
+		d.CooksDistance = math.NaN()
+		if d.Leverage < 1 && mse > 0 {
+			d.StandardizedResidual = d.Residual / math.Sqrt(mse*(1-d.Leverage))
+			d.CooksDistance = d.StandardizedResidual * d.StandardizedResidual / 2 * d.Leverage / (1 - d.Leverage)
+		}
+	}
+
+	return diags
+}
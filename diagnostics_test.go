@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Leverage sums to the number of parameters and residuals sum to zero
+func TestPointDiagnosticsProperties(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	slope, intercept, _ := ManualRegression(data.X, data.Y)
+
+	diags := PointDiagnostics(data.X, data.Y, slope, intercept)
+	if len(diags) != len(data.X) {
+		t.Fatalf("expected %d diagnostics, got %d", len(data.X), len(diags))
+	}
+
+	var sumH, sumR float64
+	for _, d := range diags {
+		sumH += d.Leverage
+		sumR += d.Residual
+	}
+	if math.Abs(sumH-2) > 1e-9 {
+		t.Errorf("leverage should sum to 2, got %.12f", sumH)
+	}
+	if math.Abs(sumR) > 1e-9 {
+		t.Errorf("residuals should sum to 0, got %.12f", sumR)
+	}
+}
+
+// ✅ Test 2: Dataset IV's isolated point has leverage 1 and undefined influence
+func TestPointDiagnosticsFullLeverage(t *testing.T) {
+	data := LoadAnscombeDatasets()["IV"]
+	slope, intercept, _ := ManualRegression(data.X, data.Y)
+
+	d := PointDiagnostics(data.X, data.Y, slope, intercept)[7]
+	if math.Abs(d.Leverage-1) > 1e-9 {
+		t.Errorf("leverage of x=19 should be 1, got %.6f", d.Leverage)
+	}
+	if !math.IsNaN(d.CooksDistance) {
+		t.Errorf("Cook's distance should be undefined, got %v", d.CooksDistance)
+	}
+	if _, err := d.MarshalJSON(); err != nil {
+		t.Errorf("NaN diagnostics must still encode: %v", err)
+	}
+}
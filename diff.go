@@ -0,0 +1,222 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// KSResult is a two-sample Kolmogorov–Smirnov test
+type KSResult struct {
+	Statistic float64 `json:"statistic"`
+	PValue    float64 `json:"pValue"`
+}
+
+// DatasetComparison describes how dataset B differs from dataset A
+type DatasetComparison struct {
+	PointsA int              `json:"pointsA"`
+	PointsB int              `json:"pointsB"`
+	X       KSResult         `json:"x"`
+	Y       KSResult         `json:"y"`
+	FitA    RegressionResult `json:"fitA"`
+	FitB    RegressionResult `json:"fitB"`
+	// SlopeChange, InterceptChange and RSquaredChange are B minus A
+	SlopeChange     float64 `json:"slopeChange"`
+	InterceptChange float64 `json:"interceptChange"`
+	RSquaredChange  float64 `json:"rSquaredChange"`
+	// Location tests of A against B; each is nil when the data are too small or degenerate for it
+	YMeans *TTestResult       `json:"yMeans,omitempty"`
+	YRanks *MannWhitneyResult `json:"yRanks,omitempty"`
+	Slopes *TTestResult       `json:"slopes,omitempty"`
+}
+
+// CompareDatasets reports differences in the X and Y distributions (KS statistic) and in the
+// fitted coefficients between two exports of the same data, to detect drift
+func CompareDatasets(a, b Dataset) (DatasetComparison, error) {
+	fitA, err := AnalyzeDataset("A", a)
+	if err != nil {
+		return DatasetComparison{}, fmt.Errorf("dataset A: %w", err)
+	}
+	fitB, err := AnalyzeDataset("B", b)
+	if err != nil {
+		return DatasetComparison{}, fmt.Errorf("dataset B: %w", err)
+	}
+
+	c := DatasetComparison{
+		PointsA:         len(a.X),
+		PointsB:         len(b.X),
+		X:               KolmogorovSmirnov(a.X, b.X),
+		Y:               KolmogorovSmirnov(a.Y, b.Y),
+		FitA:            fitA,
+		FitB:            fitB,
+		SlopeChange:     fitB.Slope - fitA.Slope,
+		InterceptChange: fitB.Intercept - fitA.Intercept,
+		RSquaredChange:  fitB.RSquared - fitA.RSquared,
+	}
+	if t, err := WelchTTest(a.Y, b.Y); err == nil {
+		c.YMeans = &t
+	}
+	if u, err := MannWhitneyU(a.Y, b.Y); err == nil {
+		c.YRanks = &u
+	}
+	if t, err := CompareSlopes(a, b); err == nil {
+		c.Slopes = &t
+	}
+	return c, nil
+}
+
+// KolmogorovSmirnov computes the two-sample KS statistic, the largest distance between the empirical
+// distribution functions of a and b, with the asymptotic p-value. NaN/Inf values are ignored.
+func KolmogorovSmirnov(a, b []float64) KSResult {
+	sa, sb := sortedFinite(a), sortedFinite(b)
+	if len(sa) == 0 || len(sb) == 0 {
+		return KSResult{Statistic: math.NaN(), PValue: math.NaN()}
+	}
+
+	var d float64
+	i, j := 0, 0
+	for i < len(sa) && j < len(sb) {
+		// step past every copy of the smaller value so ties move both functions together
+		v := math.Min(sa[i], sb[j])
+		for i < len(sa) && sa[i] == v {
+			i++
+		}
+		for j < len(sb) && sb[j] == v {
+			j++
+		}
+		d = math.Max(d, math.Abs(float64(i)/float64(len(sa))-float64(j)/float64(len(sb))))
+	}
+
+	ne := float64(len(sa)) * float64(len(sb)) / float64(len(sa)+len(sb))
+	sqrtNe := math.Sqrt(ne)
+	return KSResult{Statistic: d, PValue: kolmogorovQ((sqrtNe + 0.12 + 0.11/sqrtNe) * d)}
+}
+
+// kolmogorovQ is the survival function of the Kolmogorov distribution,
+// Q(λ) = 2 Σ (-1)^(k-1) exp(-2k²λ²)
+func kolmogorovQ(lambda float64) float64 {
+	if lambda < 1e-3 {
+		return 1
+	}
+	var sum float64
+	sign := 1.0
+	for k := 1; k <= 100; k++ {
+		term := sign * math.Exp(-2*float64(k*k)*lambda*lambda)
+		sum += term
+		if math.Abs(term) < 1e-12 {
+			break
+		}
+		sign = -sign
+	}
+	return math.Min(math.Max(2*sum, 0), 1)
+}
+
+func sortedFinite(values []float64) []float64 {
+	out := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !isMissing(v) {
+			out = append(out, v)
+		}
+	}
+	sort.Float64s(out)
+	return out
+}
+
+// PrintComparison writes a comparison as a report
+func PrintComparison(w io.Writer, nameA, nameB string, c DatasetComparison) {
+	fmt.Fprintf(w, "=== Dataset Diff: %s -> %s ===\n", nameA, nameB)
+	fmt.Fprintf(w, "Points: %d -> %d\n", c.PointsA, c.PointsB)
+	fmt.Fprintf(w, "Data:   %s -> %s\n\n", c.FitA.Fingerprint, c.FitB.Fingerprint)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "\tA\tB\tChange\t")
+	fmt.Fprintf(tw, "Slope\t%.6f\t%.6f\t%+.6f\t\n", c.FitA.Slope, c.FitB.Slope, c.SlopeChange)
+	fmt.Fprintf(tw, "Intercept\t%.6f\t%.6f\t%+.6f\t\n", c.FitA.Intercept, c.FitB.Intercept, c.InterceptChange)
+	fmt.Fprintf(tw, "R-squared\t%.6f\t%.6f\t%+.6f\t\n", c.FitA.RSquared, c.FitB.RSquared, c.RSquaredChange)
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nDistribution drift (two-sample Kolmogorov-Smirnov):\n")
+	fmt.Fprintf(w, "  X: D = %.4f, p = %.4g\n", c.X.Statistic, c.X.PValue)
+	fmt.Fprintf(w, "  Y: D = %.4f, p = %.4g\n", c.Y.Statistic, c.Y.PValue)
+
+	if c.YMeans == nil && c.YRanks == nil && c.Slopes == nil {
+		return
+	}
+	fmt.Fprintf(w, "\nLocation tests (A vs B):\n")
+	if t := c.YMeans; t != nil {
+		fmt.Fprintf(w, "  Y means (Welch t):      diff = %+.6f, t = %.4f, df = %.1f, p = %.4g, d = %.3f\n", t.Difference, t.Statistic, t.DF, t.PValue, t.EffectSize)
+	}
+	if u := c.YRanks; u != nil {
+		fmt.Fprintf(w, "  Y ranks (Mann-Whitney): U = %.1f, z = %.4f, p = %.4g, r = %.3f\n", u.U, u.Z, u.PValue, u.RankBiserial)
+	}
+	if t := c.Slopes; t != nil {
+		fmt.Fprintf(w, "  Slopes (t):             diff = %+.6f, t = %.4f, df = %.0f, p = %.4g\n", t.Difference, t.Statistic, t.DF, t.PValue)
+	}
+}
+
+// runDiff implements the `diff` subcommand comparing two CSV exports, or two JSON result exports
+// field by field (see DiffRuns)
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	alpha := flags.Float64("alpha", 0.05, "significance level below which a KS p-value is reported as drift")
+	opts := RunDiffOptions{Tolerance: DefaultGoldenTolerance(), Ignore: slices.Clone(defaultRunDiffIgnore)}
+	flags.Float64Var(&opts.Tolerance.Abs, "abs", opts.Tolerance.Abs, "with JSON exports, absolute tolerance for numbers")
+	flags.Float64Var(&opts.Tolerance.Rel, "rel", opts.Tolerance.Rel, "with JSON exports, relative tolerance for numbers")
+	flags.Func("ignore", "with JSON exports, also skip this field wherever it appears; repeatable (always skipped: "+strings.Join(defaultRunDiffIgnore, ", ")+")", func(name string) error {
+		opts.Ignore = append(opts.Ignore, name)
+		return nil
+	})
+	var numbers NumberLocale
+	flags.Func("locale", "with CSV files, their number format ("+localeNames()+")", func(name string) (err error) {
+		numbers, err = ParseNumberLocale(name)
+		return err
+	})
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: diff [flags] a.csv b.csv | run1.json run2.json")
+	}
+	if opts.Tolerance.Abs < 0 || opts.Tolerance.Rel < 0 {
+		return fmt.Errorf("-abs and -rel must not be negative")
+	}
+	if jsonA, jsonB := isJSONExport(flags.Arg(0)), isJSONExport(flags.Arg(1)); jsonA || jsonB {
+		if !jsonA || !jsonB {
+			return fmt.Errorf("cannot compare a JSON export with a CSV file")
+		}
+		return runRunDiff(flags.Arg(0), flags.Arg(1), opts)
+	}
+
+	a, err := LoadCSVFileWithLocale(flags.Arg(0), numbers)
+	if err != nil {
+		return err
+	}
+	b, err := LoadCSVFileWithLocale(flags.Arg(1), numbers)
+	if err != nil {
+		return err
+	}
+
+	c, err := CompareDatasets(a, b)
+	if err != nil {
+		return err
+	}
+	PrintComparison(os.Stdout, flags.Arg(0), flags.Arg(1), c)
+
+	var drifted []string
+	if c.X.PValue < *alpha {
+		drifted = append(drifted, "X")
+	}
+	if c.Y.PValue < *alpha {
+		drifted = append(drifted, "Y")
+	}
+	if len(drifted) > 0 {
+		fmt.Printf("\nDrift detected in %s at alpha %.3g\n", strings.Join(drifted, " and "), *alpha)
+	}
+	return nil
+}
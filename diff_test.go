@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: KS statistic and p-value on known samples
+func TestKolmogorovSmirnov(t *testing.T) {
+	if ks := KolmogorovSmirnov([]float64{1, 2, 3}, []float64{4, 5, 6}); ks.Statistic != 1 {
+		t.Errorf("disjoint samples: D = %v, want 1", ks.Statistic)
+	}
+	if ks := KolmogorovSmirnov([]float64{1, 2, 2, 3}, []float64{3, 2, 1, 2}); ks.Statistic != 0 || ks.PValue != 1 {
+		t.Errorf("identical samples: %+v", ks)
+	}
+	if ks := KolmogorovSmirnov([]float64{1, 2, 3, 4}, []float64{3, 4, 5, 6}); ks.Statistic != 0.5 {
+		t.Errorf("half-overlapping samples: D = %v, want 0.5", ks.Statistic)
+	}
+
+	rng := rand.New(rand.NewPCG(5, 6))
+	a, same, shifted := make([]float64, 500), make([]float64, 500), make([]float64, 500)
+	for i := range a {
+		a[i], same[i], shifted[i] = rng.NormFloat64(), rng.NormFloat64(), rng.NormFloat64()+0.5
+	}
+	if p := KolmogorovSmirnov(a, same).PValue; p < 0.01 {
+		t.Errorf("samples from the same distribution should not drift, p = %v", p)
+	}
+	if p := KolmogorovSmirnov(a, shifted).PValue; p > 1e-6 {
+		t.Errorf("shifted samples should drift, p = %v", p)
+	}
+	if ks := KolmogorovSmirnov(nil, a); !math.IsNaN(ks.Statistic) {
+		t.Errorf("empty sample should give NaN, got %+v", ks)
+	}
+}
+
+// ✅ Test 2: CompareDatasets reports coefficient changes and the report renders them
+func TestCompareDatasets(t *testing.T) {
+	quartet := LoadAnscombeDatasets()
+	c, err := CompareDatasets(quartet["I"], quartet["IV"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.PointsA != 11 || math.Abs(c.SlopeChange) > 1e-3 || c.X.Statistic < 0.4 {
+		t.Errorf("unexpected comparison %+v", c)
+	}
+
+	var buf bytes.Buffer
+	PrintComparison(&buf, "I", "IV", c)
+	for _, want := range []string{"I -> IV", "Slope", "X: D = "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+
+	if err := runDiff([]string{"only-one.csv"}); err == nil {
+		t.Error("expected usage error")
+	}
+}
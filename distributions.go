@@ -0,0 +1,182 @@
+package main
+
+import "math"
+
+// studentTTwoSided returns P(|T| >= |t|) for Student's t distribution with df degrees of freedom
+func studentTTwoSided(t, df float64) float64 {
+	if math.IsNaN(t) || !(df > 0) {
+		return math.NaN()
+	}
+	if math.IsInf(t, 0) {
+		return 0
+	}
+	return regIncBeta(df/2, 0.5, df/(df+t*t))
+}
+
+// studentTQuantile is the inverse of the CDF of Student's t distribution with df degrees of freedom,
+// found by bisection on studentTTwoSided
+func studentTQuantile(p, df float64) float64 {
+	if !(p > 0 && p < 1) || !(df > 0) {
+		return math.NaN()
+	}
+	if p == 0.5 {
+		return 0
+	}
+	target := 2 * math.Min(p, 1-p)
+	lo, hi := 0.0, 1.0
+	for studentTTwoSided(hi, df) > target {
+		lo, hi = hi, 2*hi
+	}
+	for i := 0; i < 200 && hi-lo > 1e-14*hi; i++ {
+		mid := (lo + hi) / 2
+		if studentTTwoSided(mid, df) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return math.Copysign((lo+hi)/2, p-0.5)
+}
+
+// fSurvival returns P(F >= f) for Fisher's F distribution with d1 and d2 degrees of freedom
+func fSurvival(f, d1, d2 float64) float64 {
+	if math.IsNaN(f) || !(d1 > 0) || !(d2 > 0) {
+		return math.NaN()
+	}
+	if f <= 0 {
+		return 1
+	}
+	if math.IsInf(f, 1) {
+		return 0
+	}
+	return regIncBeta(d2/2, d1/2, d2/(d2+d1*f))
+}
+
+// normalTwoSided returns P(|Z| >= |z|) for a standard normal Z
+func normalTwoSided(z float64) float64 {
+	return math.Erfc(math.Abs(z) / math.Sqrt2)
+}
+
+// regIncBeta is the regularized incomplete beta function I_x(a, b), evaluated with the continued
+// fraction on whichever side of the mean converges quickly (Numerical Recipes 6.4)
+func regIncBeta(a, b, x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x >= 1:
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log1p(-x))
+	if x < (a+1)/(a+b+2) {
+		return front * betaContinuedFraction(a, b, x) / a
+	}
+	return 1 - front*betaContinuedFraction(b, a, 1-x)/b
+}
+
+// betaContinuedFraction evaluates the continued fraction of I_x(a, b) by the modified Lentz method
+func betaContinuedFraction(a, b, x float64) float64 {
+	const (
+		maxIterations = 300
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+	clamp := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+
+	c, d := 1.0, 1/clamp(1-(a+b)*x/(a+1))
+	h := d
+	for m := 1; m <= maxIterations; m++ {
+		fm := float64(m)
+		// even step
+		aa := fm * (b - fm) * x / ((a + 2*fm - 1) * (a + 2*fm))
+		d = 1 / clamp(1+aa*d)
+		c = clamp(1 + aa/c)
+		h *= d * c
+		// odd step
+		aa = -(a + fm) * (a + b + fm) * x / ((a + 2*fm) * (a + 2*fm + 1))
+		d = 1 / clamp(1+aa*d)
+		c = clamp(1 + aa/c)
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return h
+}
+
+// normalCDF returns P(Z <= z) for a standard normal Z
+func normalCDF(z float64) float64 {
+	return 0.5 * math.Erfc(-z/math.Sqrt2)
+}
+
+// normalQuantile is the inverse of normalCDF
+func normalQuantile(p float64) float64 {
+	return math.Sqrt2 * math.Erfinv(2*p-1)
+}
+
+// chiSquaredSurvival returns P(X >= x) for a chi-squared X with df degrees of freedom
+func chiSquaredSurvival(x, df float64) float64 {
+	if math.IsNaN(x) || !(df > 0) {
+		return math.NaN()
+	}
+	if x <= 0 {
+		return 1
+	}
+	return regIncGammaUpper(df/2, x/2)
+}
+
+// regIncGammaUpper is the regularized upper incomplete gamma function Q(a, x), from its series
+// below a+1 and its continued fraction above (Numerical Recipes 6.2)
+func regIncGammaUpper(a, x float64) float64 {
+	lga, _ := math.Lgamma(a)
+	front := math.Exp(-x + a*math.Log(x) - lga)
+	const (
+		maxIterations = 500
+		epsilon       = 1e-15
+		tiny          = 1e-300
+	)
+
+	if x < a+1 {
+		term := 1 / a
+		sum := term
+		for n := 1; n <= maxIterations; n++ {
+			term *= x / (a + float64(n))
+			sum += term
+			if math.Abs(term) < math.Abs(sum)*epsilon {
+				break
+			}
+		}
+		return 1 - front*sum
+	}
+
+	b := x + 1 - a
+	c, d := 1/tiny, 1/b
+	h := d
+	for n := 1; n <= maxIterations; n++ {
+		an := -float64(n) * (float64(n) - a)
+		b += 2
+		d = an*d + b
+		if math.Abs(d) < tiny {
+			d = tiny
+		}
+		c = b + an/c
+		if math.Abs(c) < tiny {
+			c = tiny
+		}
+		d = 1 / d
+		delta := d * c
+		h *= delta
+		if math.Abs(delta-1) < epsilon {
+			break
+		}
+	}
+	return front * h
+}
@@ -0,0 +1,30 @@
+// Command module5 fits straight lines (and multiple regressions) to datasets such as Anscombe's
+// quartet, and reports, compares, serves and monitors the fits.
+//
+// # Concurrency
+//
+// The exported functions are safe for concurrent use: they keep no state between calls, write
+// nothing to standard output and never exit the process; failures are returned as errors and
+// the details of a fit (such as why the stats engine fell back to the manual calculation) are
+// recorded in its result. The engine registry is guarded by a lock, so RegisterEngine may run
+// alongside fits. Package-level tables, templates and defaults are never modified after
+// initialization; defaults such as DefaultGoldenTolerance and DefaultAlertThresholds return a
+// fresh value each time, so callers may change it freely.
+//
+// Types with state document their own guarantees. ResultCache, AlertHook, HistoryStore, APIKeys,
+// RateLimiter and LineFit are safe for concurrent use; accumulators such as OnlineRegression, RollingRegression and
+// ReusableFitter are not, so keep one per goroutine.
+//
+// Only the command itself (main and the run* functions behind each subcommand) prints to
+// standard output or calls log.Fatal. The race detector checks these guarantees in
+// concurrency_test.go: go test -race .
+//
+// # Testing
+//
+// Each build is tested on its own, as they compute some sums differently or add engines:
+//
+//	go test .
+//	go test -tags purego .
+//	go test -tags gonum .
+//	go test -race .
+package main
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DriftOptions configures a SlopeDriftMonitor. The band is [SlopeMin, SlopeMax] when SlopeMin <
+// SlopeMax, otherwise the baseline slope (the first fit observed) plus or minus Threshold.
+// An alert needs the slope outside the band for at least SustainPoints consecutive fits and for
+// at least Sustain; both default to an immediate alert.
+type DriftOptions struct {
+	Threshold     float64
+	SlopeMin      float64
+	SlopeMax      float64
+	Sustain       time.Duration
+	SustainPoints int
+	// History is how many recent slopes are kept for History; 0 keeps none
+	History int
+}
+
+// SlopeSample is one observed slope with whether it was inside the band
+type SlopeSample struct {
+	Time   time.Time `json:"time"`
+	Slope  float64   `json:"slope"`
+	InBand bool      `json:"inBand"`
+}
+
+// SlopeDriftMonitor follows the slope of a rolling or online fit over time and raises an alert
+// once per sustained excursion outside the tolerated band, re-arming when the slope returns
+type SlopeDriftMonitor struct {
+	opts DriftOptions
+
+	baseline    float64
+	hasBaseline bool
+	drifting    bool // an alert was raised for the current excursion
+	outSince    time.Time
+	outCount    int
+	history     []SlopeSample
+}
+
+// NewSlopeDriftMonitor validates opts and creates a monitor
+func NewSlopeDriftMonitor(opts DriftOptions) (*SlopeDriftMonitor, error) {
+	if opts.Threshold < 0 || opts.Sustain < 0 || opts.SustainPoints < 0 || opts.History < 0 {
+		return nil, fmt.Errorf("drift threshold, sustain and history must not be negative")
+	}
+	if opts.SlopeMin > opts.SlopeMax {
+		return nil, fmt.Errorf("slope band is empty: %v > %v", opts.SlopeMin, opts.SlopeMax)
+	}
+	return &SlopeDriftMonitor{opts: opts}, nil
+}
+
+// Observe records the slope of fit and returns an alert when it has now been outside the band
+// for long enough. The alert's Device is left for the caller to fill in.
+func (m *SlopeDriftMonitor) Observe(fit RollingFit) *SlopeDriftAlert {
+	if isMissing(fit.Slope) {
+		return nil
+	}
+	if !m.hasBaseline {
+		m.baseline, m.hasBaseline = fit.Slope, true
+	}
+
+	inBand := m.inBand(fit.Slope)
+	if m.opts.History > 0 {
+		if len(m.history) == m.opts.History {
+			m.history = append(m.history[:0], m.history[1:]...)
+		}
+		m.history = append(m.history, SlopeSample{Time: fit.Time, Slope: fit.Slope, InBand: inBand})
+	}
+	if inBand {
+		m.drifting, m.outCount = false, 0
+		return nil
+	}
+
+	if m.outCount == 0 {
+		m.outSince = fit.Time
+	}
+	m.outCount++
+	if m.drifting || m.outCount < m.opts.SustainPoints || fit.Time.Sub(m.outSince) < m.opts.Sustain {
+		return nil
+	}
+	m.drifting = true
+	return &SlopeDriftAlert{
+		Slope:    fit.Slope,
+		Baseline: m.baseline,
+		Drift:    fit.Slope - m.baseline,
+		RSquared: fit.RSquared,
+		Points:   fit.N,
+		Time:     fit.Time,
+		Since:    m.outSince,
+	}
+}
+
+func (m *SlopeDriftMonitor) inBand(slope float64) bool {
+	if m.opts.SlopeMin < m.opts.SlopeMax {
+		return slope >= m.opts.SlopeMin && slope <= m.opts.SlopeMax
+	}
+	return math.Abs(slope-m.baseline) <= m.opts.Threshold
+}
+
+// Baseline returns the slope the relative band is centred on, once a fit has been observed
+func (m *SlopeDriftMonitor) Baseline() (float64, bool) {
+	return m.baseline, m.hasBaseline
+}
+
+// History returns a copy of the most recent slopes, oldest first
+func (m *SlopeDriftMonitor) History() []SlopeSample {
+	return append([]SlopeSample(nil), m.history...)
+}
+
+// ParseSlopeBand reads a "LO:HI" slope band
+func ParseSlopeBand(spec string) (lo, hi float64, err error) {
+	loText, hiText, ok := strings.Cut(spec, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid slope band %q (use LO:HI)", spec)
+	}
+	if lo, err = strconv.ParseFloat(loText, 64); err == nil {
+		hi, err = strconv.ParseFloat(hiText, 64)
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid slope band %q: %w", spec, err)
+	}
+	if !(lo < hi) {
+		return 0, 0, fmt.Errorf("invalid slope band %q: LO must be below HI", spec)
+	}
+	return lo, hi, nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// slopeFits returns one fit per minute with the given slopes, starting at start
+func slopeFits(start time.Time, slopes ...float64) []RollingFit {
+	fits := make([]RollingFit, len(slopes))
+	for i, s := range slopes {
+		fits[i] = RollingFit{Time: start.Add(time.Duration(i) * time.Minute), OnlineSnapshot: OnlineSnapshot{N: 10, Slope: s}}
+	}
+	return fits
+}
+
+func countAlerts(m *SlopeDriftMonitor, fits []RollingFit) (alerts []*SlopeDriftAlert) {
+	for _, f := range fits {
+		if a := m.Observe(f); a != nil {
+			alerts = append(alerts, a)
+		}
+	}
+	return alerts
+}
+
+// ✅ Test 1: Short excursions are ignored until they last SustainPoints fits and Sustain time
+func TestSlopeDriftSustained(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	m, err := NewSlopeDriftMonitor(DriftOptions{Threshold: 0.5, SustainPoints: 3, Sustain: 3 * time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// two-fit blip, back in band, then a long excursion
+	alerts := countAlerts(m, slopeFits(start, 1, 2, 2, 1, 2, 2, 2, 2, 2, 2))
+	if len(alerts) != 1 {
+		t.Fatalf("expected one alert, got %d", len(alerts))
+	}
+	a := alerts[0]
+	// out since minute 4; three fits are reached at minute 6 but three minutes only at minute 7
+	if !a.Since.Equal(start.Add(4*time.Minute)) || !a.Time.Equal(start.Add(7*time.Minute)) {
+		t.Errorf("alert since %v at %v", a.Since, a.Time)
+	}
+	if a.Baseline != 1 || a.Drift != 1 {
+		t.Errorf("baseline %v, drift %v", a.Baseline, a.Drift)
+	}
+}
+
+// ✅ Test 2: A fixed band ignores the baseline and history keeps the latest slopes
+func TestSlopeDriftFixedBand(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	lo, hi, err := ParseSlopeBand("-1:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, _ := NewSlopeDriftMonitor(DriftOptions{SlopeMin: lo, SlopeMax: hi, History: 3})
+	if alerts := countAlerts(m, slopeFits(start, 5, 0.5, -2, -3, 0, 4)); len(alerts) != 3 {
+		t.Errorf("expected alerts at 5, -2 and 4, got %d", len(alerts))
+	}
+	history := m.History()
+	if len(history) != 3 || history[0].Slope != -3 || history[2].Slope != 4 || history[1].InBand != true {
+		t.Errorf("unexpected history %+v", history)
+	}
+
+	for _, spec := range []string{"1", "2:1", "a:b"} {
+		if _, _, err := ParseSlopeBand(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+	if _, err := NewSlopeDriftMonitor(DriftOptions{SustainPoints: -1}); err == nil {
+		t.Error("expected an error for negative sustain points")
+	}
+}
+
+// ✅ Test 3: The telemetry monitor applies sustain settings per device
+func TestTelemetryMonitorSustain(t *testing.T) {
+	monitor := NewTelemetryMonitor(5, 0.5)
+	monitor.Drift.SustainPoints = 21
+	monitor.Drift.History = 100
+	alerts := 0
+	for i := 0; i < 40; i++ {
+		slope := 1.0
+		if i >= 20 {
+			slope = 3
+		}
+		if monitor.Observe(TelemetryReading{Device: "d", X: float64(i), Y: slope * float64(i)}) != nil {
+			alerts++
+		}
+	}
+	if alerts != 0 {
+		t.Errorf("expected no alert before 21 drifting windows, got %d", alerts)
+	}
+	if n := len(monitor.SlopeHistory("d")); n != 36 {
+		t.Errorf("expected 36 slopes in the history, got %d", n)
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// MeasurementError describes the noise in both axes for ErrorsInVariablesRegression, as standard
+// deviations in data units. SigmaX and SigmaY give one uncertainty per point and, when set,
+// override the common SDX and SDY.
+type MeasurementError struct {
+	SDX, SDY       float64
+	SigmaX, SigmaY []float64
+}
+
+// EIVFit is a line fitted allowing for error in X. ChiSquare is Σ W·(y - ŷ)² with the effective
+// weights W = 1/(σy² + slope²·σx²); ReducedChiSquare near 1 says the stated uncertainties explain
+// the scatter, well above 1 that they are too small or the relation is not a line.
+type EIVFit struct {
+	Slope            float64 `json:"slope"`
+	Intercept        float64 `json:"intercept"`
+	SlopeSE          float64 `json:"slopeSE"`
+	InterceptSE      float64 `json:"interceptSE"`
+	OLSSlope         float64 `json:"olsSlope"`
+	ChiSquare        float64 `json:"chiSquare"`
+	ReducedChiSquare float64 `json:"reducedChiSquare"`
+	N                int     `json:"n"`
+	Iterations       int     `json:"iterations"`
+	Converged        bool    `json:"converged"`
+}
+
+// ErrorsInVariablesRegression fits a line when X is measured with error, which biases the least
+// squares slope towards zero (attenuation). It uses York's iteration (York et al. 2004) for
+// uncorrelated errors, which with common uncertainties reduces to Deming regression with
+// variance ratio SDY²/SDX², and with no error in X to weighted least squares. The standard errors
+// are York's, taking the uncertainties as known. Pairs with NaN/Inf values are dropped.
+func ErrorsInVariablesRegression(x, y []float64, me MeasurementError) (EIVFit, error) {
+	if len(x) != len(y) {
+		return EIVFit{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(x), len(y))
+	}
+	for _, s := range []struct {
+		name  string
+		sigma []float64
+	}{{"x", me.SigmaX}, {"y", me.SigmaY}} {
+		if s.sigma != nil && len(s.sigma) != len(x) {
+			return EIVFit{}, fmt.Errorf("%d %s uncertainties for %d points", len(s.sigma), s.name, len(x))
+		}
+	}
+
+	var px, py, vx, vy []float64
+	for i := range x {
+		if isMissing(x[i]) || isMissing(y[i]) {
+			continue
+		}
+		sx, sy := me.SDX, me.SDY
+		if me.SigmaX != nil {
+			sx = me.SigmaX[i]
+		}
+		if me.SigmaY != nil {
+			sy = me.SigmaY[i]
+		}
+		if !(sx >= 0 && sy >= 0) || math.IsInf(sx, 0) || math.IsInf(sy, 0) {
+			return EIVFit{}, fmt.Errorf("point %d has uncertainties %v and %v; they must be finite and non-negative", i, sx, sy)
+		}
+		if sx == 0 && sy == 0 {
+			return EIVFit{}, fmt.Errorf("point %d has no uncertainty in either axis", i)
+		}
+		px, py = append(px, x[i]), append(py, y[i])
+		vx, vy = append(vx, sx*sx), append(vy, sy*sy)
+	}
+	n := len(px)
+	if n < 3 {
+		return EIVFit{}, fmt.Errorf("need at least three valid points, have %d", n)
+	}
+	if _, v := meanVariance(px); v == 0 {
+		return EIVFit{}, fmt.Errorf("X is constant")
+	}
+
+	fit := EIVFit{N: n}
+	fit.OLSSlope, _, _ = ManualRegression(px, py)
+	b := fit.OLSSlope
+	w := make([]float64, n)
+	beta := make([]float64, n)
+	var meanX, meanY, sumW float64
+	// the effective weights and adjusted points use variances rather than York's inverse-variance
+	// weights, so an axis without error needs no special case
+	centre := func(b float64) {
+		meanX, meanY, sumW = 0, 0, 0
+		for i := range px {
+			w[i] = 1 / (vy[i] + b*b*vx[i])
+			sumW += w[i]
+			meanX += w[i] * px[i]
+			meanY += w[i] * py[i]
+		}
+		meanX /= sumW
+		meanY /= sumW
+		for i := range px {
+			beta[i] = w[i] * (vy[i]*(px[i]-meanX) + b*vx[i]*(py[i]-meanY))
+		}
+	}
+	for fit.Iterations < 100 {
+		fit.Iterations++
+		centre(b)
+		var num, den float64
+		for i := range px {
+			num += w[i] * beta[i] * (py[i] - meanY)
+			den += w[i] * beta[i] * (px[i] - meanX)
+		}
+		next := num / den
+		moved := relChange(next, b)
+		b = next
+		if moved <= 1e-12 {
+			fit.Converged = true
+			break
+		}
+	}
+
+	centre(b)
+	fit.Slope = b
+	fit.Intercept = meanY - b*meanX
+	// York's standard errors come from the spread of the adjusted X values x̄ + β
+	var meanAdj float64
+	for i := range px {
+		meanAdj += w[i] * (meanX + beta[i])
+	}
+	meanAdj /= sumW
+	var suu float64
+	for i := range px {
+		u := meanX + beta[i] - meanAdj
+		suu += w[i] * u * u
+		r := py[i] - fit.Intercept - b*px[i]
+		fit.ChiSquare += w[i] * r * r
+	}
+	fit.SlopeSE = math.Sqrt(1 / suu)
+	fit.InterceptSE = math.Sqrt(1/sumW + meanAdj*meanAdj/suu)
+	fit.ReducedChiSquare = fit.ChiSquare / float64(n-2)
+	return fit, nil
+}
+
+// printEIV writes a dataset's errors-in-variables fit next to the attenuated least squares slope
+func printEIV(w io.Writer, data Dataset, me MeasurementError) {
+	fit, err := ErrorsInVariablesRegression(data.X, data.Y, me)
+	if err != nil {
+		fmt.Fprintf(w, "  Errors in X: n/a (%v)\n", err)
+		return
+	}
+	note := ""
+	if !fit.Converged {
+		note = fmt.Sprintf(" (not converged after %d iterations)", fit.Iterations)
+	}
+	fmt.Fprintf(w, "  Errors in X (sd x %g, sd y %g)%s:\n", me.SDX, me.SDY, note)
+	fmt.Fprintf(w, "    slope %s ± %.6f (least squares %.6f), intercept %.6f ± %.6f, reduced chi-square %.4f\n",
+		withUnit(fit.Slope, data.SlopeUnit()), fit.SlopeSE, fit.OLSSlope, fit.Intercept, fit.InterceptSE, fit.ReducedChiSquare)
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// demingSlope is the closed-form Deming slope for error variance ratio delta = σy²/σx²
+func demingSlope(x, y []float64, delta float64) float64 {
+	mx, _ := meanVariance(x)
+	my, _ := meanVariance(y)
+	var sxx, syy, sxy float64
+	for i := range x {
+		sxx += (x[i] - mx) * (x[i] - mx)
+		syy += (y[i] - my) * (y[i] - my)
+		sxy += (x[i] - mx) * (y[i] - my)
+	}
+	d := syy - delta*sxx
+	return (d + math.Sqrt(d*d+4*delta*sxy*sxy)) / (2 * sxy)
+}
+
+// ✅ Test 1: Common uncertainties give the Deming line, and no error in X gives least squares
+func TestEIVDeming(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		for _, sdy := range []float64{0.5, 1, 3} {
+			fit, err := ErrorsInVariablesRegression(ds.X, ds.Y, MeasurementError{SDX: 1, SDY: sdy})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := demingSlope(ds.X, ds.Y, sdy*sdy); !fit.Converged || relErr(fit.Slope, want) > 1e-9 {
+				t.Errorf("dataset %s, sd y %v: slope %v, Deming %v", name, sdy, fit.Slope, want)
+			}
+		}
+		fit, err := ErrorsInVariablesRegression(ds.X, ds.Y, MeasurementError{SDY: 1})
+		slope, intercept, _ := ManualRegression(ds.X, ds.Y)
+		if err != nil || relErr(fit.Slope, slope) > 1e-12 || relErr(fit.Intercept, intercept) > 1e-12 {
+			t.Errorf("dataset %s without x error: got %+v, %v", name, fit, err)
+		}
+	}
+}
+
+// ✅ Test 2: The attenuation of least squares is corrected, with honest standard errors
+func TestEIVCorrectsAttenuation(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 8))
+	covered, trials := 0, 200
+	var olsSum, eivSum float64
+	for trial := 0; trial < trials; trial++ {
+		var x, y []float64
+		for i := 0; i < 50; i++ {
+			truth := rng.Float64() * 10
+			x = append(x, truth+2*rng.NormFloat64())
+			y = append(y, 1+2*truth+rng.NormFloat64())
+		}
+		fit, err := ErrorsInVariablesRegression(x, y, MeasurementError{SDX: 2, SDY: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		olsSum += fit.OLSSlope
+		eivSum += fit.Slope
+		if math.Abs(fit.Slope-2) <= 1.96*fit.SlopeSE {
+			covered++
+		}
+	}
+	// X has variance 100/12 + 4, so least squares keeps only about 68% of the slope
+	if ols := olsSum / float64(trials); math.Abs(ols-2*(100.0/12)/(100.0/12+4)) > 0.05 {
+		t.Errorf("least squares slope averages %v", ols)
+	}
+	if eiv := eivSum / float64(trials); math.Abs(eiv-2) > 0.05 {
+		t.Errorf("corrected slope averages %v, want 2", eiv)
+	}
+	if share := float64(covered) / float64(trials); share < 0.88 {
+		t.Errorf("slope covered in only %.0f%% of samples", 100*share)
+	}
+}
+
+// ✅ Test 3: Per-point uncertainties weight the points, and bad input is rejected
+func TestEIVPerPoint(t *testing.T) {
+	x := []float64{0, 1, 2, 3, 4, math.NaN()}
+	y := []float64{0, 1, 2, 3, 10, 5}
+	// a huge uncertainty on the last point leaves the line through the others
+	fit, err := ErrorsInVariablesRegression(x, y, MeasurementError{SigmaX: []float64{0.1, 0.1, 0.1, 0.1, 1e3, 0}, SigmaY: []float64{0.1, 0.1, 0.1, 0.1, 1e3, 0}})
+	if err != nil || fit.N != 5 || math.Abs(fit.Slope-1) > 1e-3 || math.Abs(fit.Intercept) > 1e-3 {
+		t.Errorf("got %+v, %v", fit, err)
+	}
+	for _, me := range []MeasurementError{
+		{},
+		{SDX: -1, SDY: 1},
+		{SDX: 1, SigmaY: []float64{1}},
+		{SDX: math.Inf(1), SDY: 1},
+	} {
+		if _, err := ErrorsInVariablesRegression(x, y, me); err == nil {
+			t.Errorf("expected an error for %+v", me)
+		}
+	}
+	if _, err := ErrorsInVariablesRegression([]float64{1, 1, 1}, []float64{1, 2, 3}, MeasurementError{SDX: 1, SDY: 1}); err == nil {
+		t.Error("expected an error for constant x")
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Engine is a named regression implementation. Every engine applies the same validation and
+// NaN/Inf filtering as PerformLinearRegression, so engines can be swapped and compared freely.
+type Engine struct {
+	Name        string
+	Description string
+	Fit         func(x, y []float64) (slope, intercept, rSquared float64, err error)
+}
+
+// engines holds the registered engines in registration order
+var (
+	enginesMu sync.RWMutex
+	engines   []Engine
+)
+
+// RegisterEngine adds an engine to the registry. Registering a name twice panics.
+// It may be called while other goroutines use the registry.
+func RegisterEngine(e Engine) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	for _, registered := range engines {
+		if registered.Name == e.Name {
+			panic(fmt.Sprintf("engine %q registered twice", e.Name))
+		}
+	}
+	engines = append(engines, e)
+}
+
+// Engines returns every registered engine in registration order
+func Engines() []Engine {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return append([]Engine(nil), engines...)
+}
+
+// LookupEngine finds a registered engine by name
+func LookupEngine(name string) (Engine, bool) {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	for _, e := range engines {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Engine{}, false
+}
+
+func init() {
+	RegisterEngine(Engine{Name: "stats", Description: "montanaflynn/stats with manual fallback", Fit: PerformLinearRegression})
+	RegisterEngine(Engine{Name: "manual", Description: "normal equations on raw sums", Fit: cleanedFit(ManualRegression)})
+	RegisterEngine(Engine{Name: "compensated", Description: "normal equations in double-double arithmetic", Fit: cleanedFit(func(x, y []float64) (float64, float64, float64) {
+		return ManualRegressionWithOptions(x, y, ManualOptions{Compensated: true})
+	})})
+	RegisterEngine(Engine{Name: "fast", Description: "single-pass Welford co-moments", Fit: FastRegression})
+	RegisterEngine(Engine{Name: "parallel", Description: "chunked co-moments merged across CPUs", Fit: ParallelRegression})
+	RegisterEngine(Engine{Name: "bigfloat", Description: "256-bit math/big reference", Fit: BigFloatRegression})
+	RegisterEngine(Engine{Name: "float32", Description: "single-precision storage, float64 accumulation", Fit: float32Fit})
+}
+
+// cleanedFit adapts a raw fitting function into an Engine.Fit with the standard validation
+func cleanedFit(fit func(x, y []float64) (slope, intercept, rSquared float64)) func(x, y []float64) (float64, float64, float64, error) {
+	return func(x, y []float64) (float64, float64, float64, error) {
+		cleanX, cleanY, err := cleanPairs(nil, nil, x, y)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+		slope, intercept, rSquared := fit(cleanX, cleanY)
+		return slope, intercept, rSquared, nil
+	}
+}
+
+// cleanPairs validates x and y like PerformLinearRegression and appends the finite pairs to dstX and dstY
+func cleanPairs(dstX, dstY, x, y []float64) ([]float64, []float64, error) {
+	if err := checkPairLengths(len(x), len(y)); err != nil {
+		return dstX, dstY, err
+	}
+
+	start := len(dstX)
+	for i := range x {
+		if math.IsNaN(x[i]) || math.IsInf(x[i], 0) || math.IsNaN(y[i]) || math.IsInf(y[i], 0) {
+			continue
+		}
+		dstX = append(dstX, x[i])
+		dstY = append(dstY, y[i])
+	}
+	return dstX, dstY, checkValidPairs(len(dstX) - start)
+}
+
+// checkPairLengths is the first check of cleanPairs, for fitters that skip NaN/Inf pairs as they
+// read them instead of copying the finite ones out: x and y of these lengths must pair up, with
+// at least two pairs
+func checkPairLengths(nx, ny int) error {
+	if nx != ny {
+		return fmt.Errorf("x and y length mismatch: %d vs %d", nx, ny)
+	}
+	if nx < 2 {
+		return fmt.Errorf("need at least two data points")
+	}
+	return nil
+}
+
+// checkValidPairs is the last check of cleanPairs: at least two finite pairs must be left
+func checkValidPairs(valid int) error {
+	if valid < 2 {
+		return fmt.Errorf("not enough valid points after removing NaN/Inf (have %d)", valid)
+	}
+	return nil
+}
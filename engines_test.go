@@ -0,0 +1,40 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Every built-in engine is registered and agrees on Anscombe I
+func TestEnginesAgree(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	for _, name := range []string{"stats", "manual", "compensated", "fast", "parallel"} {
+		e, ok := LookupEngine(name)
+		if !ok {
+			t.Fatalf("engine %q not registered", name)
+		}
+		slope, intercept, r2, err := e.Fit(data.X, data.Y)
+		if err != nil || math.Abs(slope-0.5001) > 1e-3 || math.Abs(intercept-3.0001) > 1e-3 || math.Abs(r2-0.6665) > 1e-3 {
+			t.Errorf("%s: got %.4f %.4f %.4f %v", name, slope, intercept, r2, err)
+		}
+	}
+}
+
+// ✅ Test 2: Engines validate input consistently and duplicates are rejected
+func TestEngineValidationAndRegistry(t *testing.T) {
+	for _, e := range Engines() {
+		if _, _, _, err := e.Fit([]float64{1, 2}, []float64{1}); err == nil {
+			t.Errorf("%s: expected length mismatch error", e.Name)
+		}
+		if _, _, _, err := e.Fit([]float64{1, math.NaN()}, []float64{1, 2}); err == nil {
+			t.Errorf("%s: expected not enough valid points error", e.Name)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected duplicate registration to panic")
+		}
+	}()
+	RegisterEngine(Engine{Name: "manual"})
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"text/tabwriter"
+)
+
+// TheilSenRegression fits the line whose slope is the median of the slopes between every pair of
+// points with distinct X, and whose intercept is the median of y - slope·x. Up to 29% of the
+// points can be arbitrarily wrong without moving it far. It looks at every pair, so it takes
+// O(n²) time and memory. Pairs with NaN/Inf values are dropped as by the engines.
+func TheilSenRegression(x, y []float64) (slope, intercept float64, err error) {
+	x, y, err = cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return 0, 0, err
+	}
+	var slopes []float64
+	for i := range x {
+		for j := i + 1; j < len(x); j++ {
+			if x[j] != x[i] {
+				slopes = append(slopes, (y[j]-y[i])/(x[j]-x[i]))
+			}
+		}
+	}
+	if len(slopes) > 0 {
+		slices.Sort(slopes)
+		slope = quantileSorted(slopes, 0.5)
+	}
+	offsets := make([]float64, len(x))
+	for i := range x {
+		offsets[i] = y[i] - slope*x[i]
+	}
+	slices.Sort(offsets)
+	return slope, quantileSorted(offsets, 0.5), nil
+}
+
+// EnsembleMember is one method's line in an Ensemble
+type EnsembleMember struct {
+	Method    string  `json:"method"`
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+}
+
+// Ensemble compares the least squares line with robust alternatives. Slope and Intercept are the
+// combined line (see FitEnsemble). Spread is the range of the members' slopes in units of SlopeSE, the
+// least squares standard error: lines that differ by less than their own uncertainty agree, while
+// a spread beyond a standard error means a few points are steering least squares. Agreement maps
+// the spread onto [0, 1], 1 when the slopes coincide and 0 from two standard errors apart.
+//
+// Every method is fooled alike when one point alone sets the slope, as in quartet IV, so the
+// ensemble also reports the largest leverage (hat value) of any point; beyond leverageLimit that
+// point dominates the fit and HighLeverage is set.
+type Ensemble struct {
+	Members   []EnsembleMember `json:"members"`
+	Slope     float64          `json:"slope"`
+	Intercept float64          `json:"intercept"`
+	SlopeSE   float64          `json:"slopeSE"`
+	Spread    float64          `json:"spread"`
+	Agreement float64          `json:"agreement"`
+	// Disagree is set when Spread exceeds the threshold FitEnsemble was given
+	Disagree     bool    `json:"disagree"`
+	MaxLeverage  float64 `json:"maxLeverage"`
+	HighLeverage bool    `json:"highLeverage"`
+}
+
+// Flagged reports whether the ensemble found the least squares line fragile for either reason
+func (e Ensemble) Flagged() bool {
+	return e.Disagree || e.HighLeverage
+}
+
+// DefaultEnsembleThreshold is the slope spread, in least squares standard errors, beyond which
+// an ensemble is flagged
+const DefaultEnsembleThreshold = 1.0
+
+// FitEnsemble fits x and y by least squares, Theil–Sen and Huber regression and combines them,
+// taking the median slope and then the median of y - slope·x as the intercept. The
+// Huber threshold is 1.345 robust standard deviations (1.4826·MAD) of the least squares residuals,
+// the usual choice for 95% efficiency on normal errors. threshold <= 0 selects the default.
+func FitEnsemble(x, y []float64, threshold float64) (Ensemble, error) {
+	if threshold <= 0 {
+		threshold = DefaultEnsembleThreshold
+	}
+	x, y, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return Ensemble{}, err
+	}
+	if len(x) < 3 {
+		return Ensemble{}, fmt.Errorf("need at least three valid points, have %d", len(x))
+	}
+
+	var e Ensemble
+	slope, intercept, _ := ManualRegression(x, y)
+	e.Members = append(e.Members, EnsembleMember{Method: "least squares", Slope: slope, Intercept: intercept})
+	if stats := linePredictionStats(x, y, slope, intercept); stats != nil {
+		e.SlopeSE = stats.ResidualSE * math.Sqrt(stats.Covariance[1][1])
+	}
+
+	tsSlope, tsIntercept, _ := TheilSenRegression(x, y)
+	e.Members = append(e.Members, EnsembleMember{Method: "theil-sen", Slope: tsSlope, Intercept: tsIntercept})
+
+	residuals := make([]float64, len(x))
+	for i := range x {
+		residuals[i] = y[i] - (intercept + slope*x[i])
+	}
+	slices.Sort(residuals)
+	center := quantileSorted(residuals, 0.5)
+	for i := range residuals {
+		residuals[i] = math.Abs(residuals[i] - center)
+	}
+	slices.Sort(residuals)
+	if scale := 1.4826 * quantileSorted(residuals, 0.5); scale > 0 {
+		huber, err := GradientRegression(x, y, HuberLoss(1.345*scale), GradientOptions{})
+		if err != nil {
+			return Ensemble{}, err
+		}
+		e.Members = append(e.Members, EnsembleMember{Method: "huber", Slope: huber.Slope, Intercept: huber.Intercept})
+	} else {
+		// more than half the points sit on the least squares line, which is then also Huber's
+		e.Members = append(e.Members, EnsembleMember{Method: "huber", Slope: slope, Intercept: intercept})
+	}
+
+	slopes := make([]float64, len(e.Members))
+	for i, m := range e.Members {
+		slopes[i] = m.Slope
+	}
+	slices.Sort(slopes)
+	e.Slope = quantileSorted(slopes, 0.5)
+	offsets := make([]float64, len(x))
+	for i := range x {
+		offsets[i] = y[i] - e.Slope*x[i]
+	}
+	slices.Sort(offsets)
+	e.Intercept = quantileSorted(offsets, 0.5)
+
+	width := slopes[len(slopes)-1] - slopes[0]
+	switch {
+	case e.SlopeSE > 0:
+		e.Spread = width / e.SlopeSE
+	case width > 0:
+		e.Spread = math.Inf(1)
+	}
+	e.Agreement = math.Max(0, 1-e.Spread/2)
+	e.Disagree = e.Spread > threshold
+
+	// the hat value of a point in simple regression is 1/n + (x - x̄)²/Sxx
+	mean, sxx := centeredSums(x)
+	for _, v := range x {
+		h := 1 / float64(len(x))
+		if sxx > 0 {
+			h += (v - mean) * (v - mean) / sxx
+		}
+		e.MaxLeverage = math.Max(e.MaxLeverage, h)
+	}
+	e.HighLeverage = e.MaxLeverage > leverageLimit(len(x))
+	return e, nil
+}
+
+// printEnsemble writes each method's line, the combined estimate and the agreement verdict
+func printEnsemble(w io.Writer, data Dataset, threshold float64) {
+	e, err := FitEnsemble(data.X, data.Y, threshold)
+	if err != nil {
+		fmt.Fprintf(w, "  Ensemble:  n/a (%v)\n", err)
+		return
+	}
+	verdict := "methods agree"
+	if e.Disagree {
+		verdict = "METHODS DISAGREE: a few points drive the least squares fit"
+	}
+	fmt.Fprintf(w, "  Ensemble:  slope %s, intercept %.6f, agreement %.2f (slopes span %.2f standard errors; %s)\n",
+		withUnit(e.Slope, data.SlopeUnit()), e.Intercept, e.Agreement, e.Spread, verdict)
+	if e.HighLeverage {
+		fmt.Fprintf(w, "    WARNING: one point has leverage %.2f and sets the slope almost alone; no method can check it\n", e.MaxLeverage)
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, m := range e.Members {
+		fmt.Fprintf(tw, "    %s\tslope %.6f\tintercept %.6f\n", m.Method, m.Slope, m.Intercept)
+	}
+	tw.Flush()
+}
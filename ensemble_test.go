@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Theil–Sen ignores a gross outlier and handles repeated X
+func TestTheilSenRegression(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6, 7, math.NaN()}
+	y := []float64{3, 5, 7, 9, 11, 13, 100, 0}
+	slope, intercept, err := TheilSenRegression(x, y)
+	if err != nil || slope != 2 || intercept != 1 {
+		t.Errorf("got %v + %v x, %v", intercept, slope, err)
+	}
+	slope, intercept, err = TheilSenRegression([]float64{4, 4, 4}, []float64{1, 2, 9})
+	if err != nil || slope != 0 || intercept != 2 {
+		t.Errorf("constant x: got %v + %v x, %v", intercept, slope, err)
+	}
+	if _, _, err := TheilSenRegression([]float64{1}, []float64{1}); err == nil {
+		t.Error("expected an error for a single point")
+	}
+}
+
+// ✅ Test 2: On the quartet the methods agree on I and II, disagree on III, and IV is caught by leverage
+func TestFitEnsembleAnscombe(t *testing.T) {
+	want := map[string][2]bool{"I": {false, false}, "II": {false, false}, "III": {true, false}, "IV": {false, true}}
+	for name, ds := range LoadAnscombeDatasets() {
+		e, err := FitEnsemble(ds.X, ds.Y, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(e.Members) != 3 || e.Members[0].Method != "least squares" {
+			t.Fatalf("dataset %s: members %+v", name, e.Members)
+		}
+		if e.Disagree != want[name][0] || e.HighLeverage != want[name][1] || e.Flagged() != (want[name][0] || want[name][1]) {
+			t.Errorf("dataset %s: disagree %v, high leverage %v (spread %.3g, leverage %.3g)", name, e.Disagree, e.HighLeverage, e.Spread, e.MaxLeverage)
+		}
+		if e.Agreement < 0 || e.Agreement > 1 || (e.Agreement > 0.5) == e.Disagree {
+			t.Errorf("dataset %s: agreement %v with spread %v", name, e.Agreement, e.Spread)
+		}
+	}
+	// III's ten regular points lie on y = 4.01 + 0.345x, which the combined line moves towards
+	iii := LoadAnscombeDatasets()["III"]
+	e, _ := FitEnsemble(iii.X, iii.Y, 0)
+	if !(e.Slope < e.Members[0].Slope && e.Slope > 0.345) {
+		t.Errorf("combined slope %v", e.Slope)
+	}
+	// a looser threshold accepts III
+	if e, _ := FitEnsemble(iii.X, iii.Y, 2); e.Disagree {
+		t.Errorf("spread %v should pass a threshold of 2", e.Spread)
+	}
+}
+
+// ✅ Test 3: An exact line agrees perfectly, and the report names the verdict
+func TestFitEnsembleExact(t *testing.T) {
+	ds := Dataset{X: []float64{1, 2, 3, 4, 5}, Y: []float64{2, 4, 6, 8, 10}}
+	e, err := FitEnsemble(ds.X, ds.Y, 0)
+	if err != nil || e.Spread != 0 || e.Agreement != 1 || e.Slope != 2 || e.Intercept != 0 {
+		t.Errorf("got %+v, %v", e, err)
+	}
+	var b strings.Builder
+	printEnsemble(&b, LoadAnscombeDatasets()["III"], 0)
+	if !strings.Contains(b.String(), "METHODS DISAGREE") || !strings.Contains(b.String(), "theil-sen") {
+		t.Errorf("report:\n%s", b.String())
+	}
+}
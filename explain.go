@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// SummaryStats are the statistics Anscombe showed to agree across the quartet
+type SummaryStats struct {
+	N           int     `json:"n"`
+	MeanX       float64 `json:"meanX"`
+	VarianceX   float64 `json:"varianceX"`
+	MeanY       float64 `json:"meanY"`
+	VarianceY   float64 `json:"varianceY"`
+	Correlation float64 `json:"correlation"`
+	Slope       float64 `json:"slope"`
+	Intercept   float64 `json:"intercept"`
+	RSquared    float64 `json:"rSquared"`
+}
+
+// DatasetExplanation is what the explain walkthrough reveals about one dataset: its summary
+// statistics and, in order of importance, the findings the summary hides
+type DatasetExplanation struct {
+	Name     string       `json:"name"`
+	Data     Dataset      `json:"-"`
+	Summary  SummaryStats `json:"summary"`
+	Findings []string     `json:"findings"`
+}
+
+// Thresholds for the findings: a point is influential from half the total leverage of a
+// two-parameter fit or a Cook's distance above 1, an outlier beyond 2.5 standardized residuals, and
+// residuals are curved when a parabola in X explains half their variation
+const (
+	explainLeverage    = 0.5
+	explainCooks       = 1.0
+	explainOutlier     = 2.5
+	explainCurvatureR2 = 0.5
+)
+
+// ExplainDataset computes the summary statistics of ds and looks for what they hide
+func ExplainDataset(name string, ds Dataset) (DatasetExplanation, error) {
+	x, y, err := cleanPairs(nil, nil, ds.X, ds.Y)
+	if err != nil {
+		return DatasetExplanation{}, err
+	}
+	e := DatasetExplanation{Name: name, Data: Dataset{X: x, Y: y}}
+	s := &e.Summary
+	s.N = len(x)
+	s.MeanX, s.VarianceX = meanVariance(x)
+	s.MeanY, s.VarianceY = meanVariance(y)
+	s.Slope, s.Intercept, s.RSquared = ManualRegression(x, y)
+	s.Correlation = math.Copysign(math.Sqrt(s.RSquared), s.Slope)
+
+	distinct := map[float64]bool{}
+	for _, v := range x {
+		distinct[v] = true
+	}
+	if len(distinct) <= 2 {
+		e.Findings = append(e.Findings, fmt.Sprintf("X takes only %d distinct values, so the line just joins the means of %d vertical stacks of points; it says nothing about the relationship in between.", len(distinct), len(distinct)))
+	}
+
+	diags := PointDiagnostics(x, y, s.Slope, s.Intercept)
+	for _, d := range diags {
+		switch {
+		case d.Leverage >= 1-1e-9:
+			e.Findings = append(e.Findings, fmt.Sprintf("The point (%g, %g) has leverage 1: the line passes through it exactly, and without it the slope would be undefined. One observation determines the whole fit.", d.X, d.Y))
+		case d.Leverage >= explainLeverage || d.CooksDistance > explainCooks:
+			e.Findings = append(e.Findings, fmt.Sprintf("The point (%g, %g) is influential (leverage %.2f, Cook's distance %.2f): moving it would move the line.", d.X, d.Y, d.Leverage, d.CooksDistance))
+		}
+	}
+
+	worst := -1
+	for i, d := range diags {
+		if !math.IsNaN(d.StandardizedResidual) && math.Abs(d.StandardizedResidual) > explainOutlier && (worst < 0 || math.Abs(d.StandardizedResidual) > math.Abs(diags[worst].StandardizedResidual)) {
+			worst = i
+		}
+	}
+	if worst >= 0 {
+		d := diags[worst]
+		rx := append(append([]float64(nil), x[:worst]...), x[worst+1:]...)
+		ry := append(append([]float64(nil), y[:worst]...), y[worst+1:]...)
+		slope, intercept, r2 := ManualRegression(rx, ry)
+		e.Findings = append(e.Findings, fmt.Sprintf("The point (%g, %g) is an outlier (standardized residual %.2f). Without it the fit is y = %.4f + %.4f x with R-squared %.4f.", d.X, d.Y, d.StandardizedResidual, intercept, slope, r2))
+	}
+
+	if len(distinct) > 2 {
+		residuals := Residuals(x, y, s.Slope, s.Intercept)
+		curve := make([]float64, len(x))
+		for i, v := range x {
+			curve[i] = (v - s.MeanX) * (v - s.MeanX)
+		}
+		if _, _, r2 := ManualRegression(curve, residuals); r2 >= explainCurvatureR2 {
+			e.Findings = append(e.Findings, fmt.Sprintf("The residuals curve: a parabola in X explains %.0f%% of them. The relationship is not linear, so the straight line is the wrong model.", 100*r2))
+		}
+	}
+
+	if len(e.Findings) == 0 {
+		e.Findings = append(e.Findings, "The residuals show no pattern, no point dominates the fit and none lies far from the line: here the summary statistics tell the story.")
+	}
+	return e, nil
+}
+
+// ExplainOptions configures Explain
+type ExplainOptions struct {
+	// Pause waits for Enter on the input between steps
+	Pause bool
+	// PlotWidth and PlotHeight size the text scatter plots, in characters; 60 by 18 when 0
+	PlotWidth, PlotHeight int
+}
+
+// Explain walks through datasets in order: first their near-identical summary statistics side by
+// side, then each dataset's plot and findings, and finally the lesson
+func Explain(w io.Writer, in io.Reader, title string, datasets []DatasetExplanation, opts ExplainOptions) error {
+	width, height := opts.PlotWidth, opts.PlotHeight
+	if width == 0 {
+		width = 60
+	}
+	if height == 0 {
+		height = 18
+	}
+	input := bufio.NewReader(in)
+	pause := func() error {
+		if !opts.Pause {
+			fmt.Fprintln(w)
+			return nil
+		}
+		fmt.Fprint(w, "\n[press Enter to continue] ")
+		if _, err := input.ReadString('\n'); err != nil && err != io.EOF {
+			return err
+		}
+		fmt.Fprintln(w)
+		return nil
+	}
+
+	fmt.Fprintf(w, "=== %s: the same numbers, different data ===\n\n", title)
+	fmt.Fprintf(w, "Step 1. The summary statistics of every dataset:\n\n")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Dataset\tn\tmean x\tvar x\tmean y\tvar y\tcorr\tslope\tintercept\tR²\t")
+	for _, e := range datasets {
+		s := e.Summary
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%.2f\t%.2f\t%.3f\t%.3f\t%.3f\t%.2f\t%.3f\t\n",
+			e.Name, s.N, s.MeanX, s.VarianceX, s.MeanY, s.VarianceY, s.Correlation, s.Slope, s.Intercept, s.RSquared)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "\nTo two or three decimals the datasets are indistinguishable, and so is their fitted line.\n")
+	if err := pause(); err != nil {
+		return err
+	}
+
+	for i, e := range datasets {
+		fmt.Fprintf(w, "Step %d. Dataset %s, plotted with its fitted line y = %.2f + %.3f x:\n\n", i+2, e.Name, e.Summary.Intercept, e.Summary.Slope)
+		textScatter(w, e.Data, e.Summary.Slope, e.Summary.Intercept, width, height)
+		fmt.Fprintln(w)
+		for _, f := range e.Findings {
+			fmt.Fprintf(w, "  - %s\n", f)
+		}
+		if err := pause(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(w, "Lesson: identical summary statistics and regression lines can describe very different data.\n")
+	fmt.Fprintf(w, "Always plot the data and check the residuals before trusting a fit.\n")
+	return nil
+}
+
+// textScatter draws the points as * (# where several share a cell) over the fitted line as .
+func textScatter(w io.Writer, ds Dataset, slope, intercept float64, width, height int) {
+	xMin, xMax := minMax(ds.X)
+	yMin, yMax := minMax(ds.Y)
+	for _, x := range []float64{xMin, xMax} {
+		yMin, yMax = math.Min(yMin, intercept+slope*x), math.Max(yMax, intercept+slope*x)
+	}
+	if xMax == xMin {
+		xMin, xMax = xMin-1, xMax+1
+	}
+	if yMax == yMin {
+		yMin, yMax = yMin-1, yMax+1
+	}
+	col := func(x float64) int { return int(math.Round((x - xMin) / (xMax - xMin) * float64(width-1))) }
+	row := func(y float64) int { return height - 1 - int(math.Round((y-yMin)/(yMax-yMin)*float64(height-1))) }
+
+	grid := make([][]byte, height)
+	for r := range grid {
+		grid[r] = []byte(strings.Repeat(" ", width))
+	}
+	for c := 0; c < width; c++ {
+		x := xMin + (xMax-xMin)*float64(c)/float64(width-1)
+		if r := row(intercept + slope*x); r >= 0 && r < height {
+			grid[r][c] = '.'
+		}
+	}
+	for i := range ds.X {
+		r, c := row(ds.Y[i]), col(ds.X[i])
+		if grid[r][c] == '*' || grid[r][c] == '#' {
+			grid[r][c] = '#'
+		} else {
+			grid[r][c] = '*'
+		}
+	}
+
+	label := fmt.Sprintf("%.4g", yMax)
+	margin := max(len(label), len(fmt.Sprintf("%.4g", yMin)))
+	for r, line := range grid {
+		tick := ""
+		switch r {
+		case 0:
+			tick = fmt.Sprintf("%.4g", yMax)
+		case height - 1:
+			tick = fmt.Sprintf("%.4g", yMin)
+		}
+		fmt.Fprintf(w, "  %*s |%s\n", margin, tick, line)
+	}
+	fmt.Fprintf(w, "  %*s +%s\n", margin, "", strings.Repeat("-", width))
+	left, right := fmt.Sprintf("%.4g", xMin), fmt.Sprintf("%.4g", xMax)
+	fmt.Fprintf(w, "  %*s  %s%*s\n", margin, "", left, width-len(left), right)
+}
+
+func minMax(values []float64) (lo, hi float64) {
+	lo, hi = math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	return lo, hi
+}
+
+// runExplain implements the explain subcommand
+func runExplain(args []string) error {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	builtin := flags.String("builtin", "anscombe", "built-in dataset collection to walk through ("+builtinNames()+")")
+	noPause := flags.Bool("no-pause", false, "print every step without waiting for Enter (the default when input is not a terminal)")
+	htmlPath := flags.String("html", "", "also write an HTML report with the plots and residual distributions to this file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: explain [flags]")
+	}
+	collection, ok := LookupBuiltin(*builtin)
+	if !ok {
+		return fmt.Errorf("unknown built-in collection %q (use %s)", *builtin, builtinNames())
+	}
+	data, err := collection.Load()
+	if err != nil {
+		return err
+	}
+
+	var explanations []DatasetExplanation
+	var report []HTMLReportDataset
+	for _, job := range datasetJobs(data) {
+		e, err := ExplainDataset(job.Name, job.Data)
+		if err != nil {
+			return fmt.Errorf("dataset %s: %w", job.Name, err)
+		}
+		explanations = append(explanations, e)
+		s := e.Summary
+		report = append(report, HTMLReportDataset{Name: e.Name, Data: e.Data, Result: RegressionResult{Dataset: e.Name, Slope: s.Slope, Intercept: s.Intercept, RSquared: s.RSquared}})
+	}
+
+	pause := !*noPause
+	if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		pause = false
+	}
+	if err := Explain(os.Stdout, os.Stdin, collection.Title, explanations, ExplainOptions{Pause: pause}); err != nil {
+		return err
+	}
+	if *htmlPath != "" {
+		if err := WriteHTMLReportFile(*htmlPath, collection.Title, report, HTMLReportOptions{}); err != nil {
+			return fmt.Errorf("writing HTML report: %w", err)
+		}
+		fmt.Printf("\nHTML report with the plots written to %s\n", *htmlPath)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Each Anscombe dataset gets the finding that sets it apart, despite equal summaries
+func TestExplainAnscombe(t *testing.T) {
+	want := map[string][]string{
+		"I":   {"no pattern"},
+		"II":  {"residuals curve"},
+		"III": {"influential", "outlier"},
+		"IV":  {"2 distinct values", "leverage 1"},
+	}
+	for name, ds := range LoadAnscombeDatasets() {
+		e, err := ExplainDataset(name, ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := e.Summary; s.N != 11 || math.Abs(s.MeanX-9) > 1e-9 || math.Abs(s.VarianceX-11) > 1e-9 || math.Abs(s.MeanY-7.5) > 0.01 || math.Abs(s.Correlation-0.816) > 0.001 {
+			t.Errorf("dataset %s: unexpected summary %+v", name, s)
+		}
+		if len(e.Findings) != len(want[name]) {
+			t.Fatalf("dataset %s: expected %d findings, got %q", name, len(want[name]), e.Findings)
+		}
+		for i, fragment := range want[name] {
+			if !strings.Contains(e.Findings[i], fragment) {
+				t.Errorf("dataset %s: finding %q should mention %q", name, e.Findings[i], fragment)
+			}
+		}
+	}
+}
+
+// ✅ Test 2: The walkthrough pauses between steps and plots every point
+func TestExplainWalkthrough(t *testing.T) {
+	var explanations []DatasetExplanation
+	for _, job := range anscombeJobs() {
+		e, err := ExplainDataset(job.Name, job.Data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		explanations = append(explanations, e)
+	}
+
+	var out bytes.Buffer
+	if err := Explain(&out, strings.NewReader("\n\n\n\n\n"), "Anscombe Quartet", explanations, ExplainOptions{Pause: true, PlotWidth: 40, PlotHeight: 12}); err != nil {
+		t.Fatal(err)
+	}
+	text := out.String()
+	if got := strings.Count(text, "press Enter"); got != 5 {
+		t.Errorf("expected 5 pauses, got %d", got)
+	}
+	if !strings.Contains(text, "Step 5. Dataset IV") || !strings.Contains(text, "Lesson:") {
+		t.Errorf("missing steps in:\n%s", text)
+	}
+
+	// dataset IV stacks ten points on x = 8, so its plot shows shared cells
+	step := text[strings.Index(text, "Step 5."):]
+	if !strings.Contains(step, "#") {
+		t.Errorf("expected overlapping points in dataset IV's plot:\n%s", step)
+	}
+}
+
+// ✅ Test 3: The text scatter puts the extreme points in the corners
+func TestTextScatter(t *testing.T) {
+	var out bytes.Buffer
+	textScatter(&out, Dataset{X: []float64{0, 10}, Y: []float64{0, 10}}, 1, 0, 11, 6)
+	lines := strings.Split(out.String(), "\n")
+	if !strings.HasSuffix(lines[0], "*") || !strings.Contains(lines[5], "|*") || strings.Count(out.String(), "*") != 2 {
+		t.Errorf("unexpected plot:\n%s", out.String())
+	}
+}
@@ -0,0 +1,21 @@
+package main
+
+// FastRegression is a single-pass, allocation-free alternative to PerformLinearRegression.
+// Means and co-moments are accumulated in one loop (see OnlineRegression) and NaN/Inf pairs are
+// skipped in place, so no intermediate slices are built.
+func FastRegression(x, y []float64) (slope, intercept, rSquared float64, err error) {
+	if err := checkPairLengths(len(x), len(y)); err != nil {
+		return 0, 0, 0, err
+	}
+
+	var acc OnlineRegression
+	for i := range x {
+		acc.Add(x[i], y[i])
+	}
+	if err := checkValidPairs(acc.N()); err != nil {
+		return 0, 0, 0, err
+	}
+
+	s := acc.Snapshot()
+	return s.Slope, s.Intercept, s.RSquared, nil
+}
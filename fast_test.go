@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: Same coefficients as PerformLinearRegression, including NaN handling
+func TestFastRegressionMatches(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		x := append([]float64{math.NaN()}, data.X...)
+		y := append([]float64{1}, data.Y...)
+
+		slope, intercept, r2, err := FastRegression(x, y)
+		refSlope, refIntercept, refR2, _ := PerformLinearRegression(data.X, data.Y)
+		if err != nil || math.Abs(slope-refSlope) > 1e-9 || math.Abs(intercept-refIntercept) > 1e-9 || math.Abs(r2-refR2) > 1e-9 {
+			t.Errorf("%s: fast (%.9f %.9f %.9f %v) vs reference (%.9f %.9f %.9f)", name, slope, intercept, r2, err, refSlope, refIntercept, refR2)
+		}
+	}
+}
+
+// ✅ Test 2: Validation errors and zero allocations
+func TestFastRegressionValidationAndAllocs(t *testing.T) {
+	if _, _, _, err := FastRegression([]float64{1, 2}, []float64{1}); err == nil {
+		t.Error("expected length mismatch error")
+	}
+	if _, _, _, err := FastRegression([]float64{1, math.NaN()}, []float64{1, 2}); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+
+	data := LoadAnscombeDatasets()["I"]
+	if allocs := testing.AllocsPerRun(100, func() { FastRegression(data.X, data.Y) }); allocs != 0 {
+		t.Errorf("expected no allocations, got %.1f", allocs)
+	}
+}
+
+func syntheticLine(n int) (x, y []float64) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	x = make([]float64, n)
+	y = make([]float64, n)
+	for i := range x {
+		x[i] = float64(i)
+		y[i] = 3 + 0.5*x[i] + rng.NormFloat64()
+	}
+	return x, y
+}
+
+// ✅ Benchmark: single-pass path vs the stats/fallback path for large n
+func BenchmarkFitPaths(b *testing.B) {
+	for _, n := range []int{1_000, 100_000} {
+		x, y := syntheticLine(n)
+		b.Run(fmt.Sprintf("Perform/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				PerformLinearRegression(x, y)
+			}
+		})
+		b.Run(fmt.Sprintf("Fast/n=%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FastRegression(x, y)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FeatureOptions selects the terms NewFeatureExpansion builds
+type FeatureOptions struct {
+	// Degree is the highest power of each numeric predictor; 0 and 1 keep predictors linear
+	Degree int
+	// Interactions adds the product of every pair of predictors
+	Interactions bool
+}
+
+// FeatureTerm is one expanded column: the product of the input predictors listed in Factors,
+// with repetition for powers (e.g. {0, 0} is the square of input 0)
+type FeatureTerm struct {
+	Name    string `json:"name"`
+	Factors []int  `json:"factors"`
+}
+
+// FeatureExpansion maps rows of the input predictors to polynomial and interaction terms.
+// It is stored in the MultiFit it produced so predictions take raw inputs (see MultiFit.Predict).
+type FeatureExpansion struct {
+	Inputs []string      `json:"inputs"`
+	Terms  []FeatureTerm `json:"terms"`
+}
+
+// NewFeatureExpansion builds the terms for md's predictors: each predictor, its powers up to
+// opts.Degree, then pairwise products. Dummy columns of categorical predictors are never raised
+// to a power (a 0/1 value equals its square), and dummies of the same column are not multiplied
+// together since at most one of them is 1.
+func NewFeatureExpansion(md MultiDataset, opts FeatureOptions) (FeatureExpansion, error) {
+	if opts.Degree < 0 {
+		return FeatureExpansion{}, fmt.Errorf("degree must not be negative, got %d", opts.Degree)
+	}
+	dummyOf := map[string]string{}
+	for _, enc := range md.Encodings {
+		for _, name := range enc.Names() {
+			dummyOf[name] = enc.Column
+		}
+	}
+
+	e := FeatureExpansion{Inputs: md.Names}
+	for i, name := range md.Names {
+		e.Terms = append(e.Terms, FeatureTerm{Name: name, Factors: []int{i}})
+	}
+	for i, name := range md.Names {
+		if _, dummy := dummyOf[name]; dummy {
+			continue
+		}
+		for d := 2; d <= opts.Degree; d++ {
+			factors := make([]int, d)
+			for k := range factors {
+				factors[k] = i
+			}
+			e.Terms = append(e.Terms, FeatureTerm{Name: fmt.Sprintf("%s^%d", name, d), Factors: factors})
+		}
+	}
+	if opts.Interactions {
+		for i, a := range md.Names {
+			for j := i + 1; j < len(md.Names); j++ {
+				b := md.Names[j]
+				if ca, ok := dummyOf[a]; ok && ca == dummyOf[b] {
+					continue
+				}
+				e.Terms = append(e.Terms, FeatureTerm{Name: a + "*" + b, Factors: []int{i, j}})
+			}
+		}
+	}
+	return e, nil
+}
+
+// Names returns the expanded predictor names in column order
+func (e FeatureExpansion) Names() []string {
+	names := make([]string, len(e.Terms))
+	for i, t := range e.Terms {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// Row expands one row of inputs, given in Inputs order
+func (e FeatureExpansion) Row(x []float64) []float64 {
+	out := make([]float64, len(e.Terms))
+	for i, t := range e.Terms {
+		v := 1.0
+		for _, f := range t.Factors {
+			v *= x[f]
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// Apply returns md with every row expanded. Y and the categorical encodings are shared with md.
+func (e FeatureExpansion) Apply(md MultiDataset) (MultiDataset, error) {
+	if strings.Join(md.Names, "\x00") != strings.Join(e.Inputs, "\x00") {
+		return MultiDataset{}, fmt.Errorf("dataset predictors %v do not match the expansion inputs %v", md.Names, e.Inputs)
+	}
+	out := MultiDataset{Names: e.Names(), X: make([][]float64, len(md.X)), Y: md.Y, Encodings: md.Encodings}
+	for i, row := range md.X {
+		if len(row) != len(e.Inputs) {
+			return MultiDataset{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), len(e.Inputs))
+		}
+		out.X[i] = e.Row(row)
+	}
+	return out, nil
+}
+
+// FitExpanded expands md's predictors with opts and fits the result with FitMultiple.
+// The expansion is recorded in the fit, so Predict takes rows of the original predictors.
+func FitExpanded(md MultiDataset, opts FeatureOptions) (MultiFit, error) {
+	e, err := NewFeatureExpansion(md, opts)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	expanded, err := e.Apply(md)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	fit, err := FitMultiple(expanded)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	fit.Expansion = &e
+	return fit, nil
+}
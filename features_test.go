@@ -0,0 +1,62 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Terms are built in order, skipping powers and same-column products of dummies
+func TestNewFeatureExpansion(t *testing.T) {
+	md := MultiDataset{
+		Names:     []string{"a", "b", "color=green", "color=red"},
+		Encodings: []CategoricalEncoding{{Column: "color", Levels: []string{"blue", "green", "red"}}},
+	}
+	e, err := NewFeatureExpansion(md, FeatureOptions{Degree: 3, Interactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"a", "b", "color=green", "color=red", "a^2", "a^3", "b^2", "b^3",
+		"a*b", "a*color=green", "a*color=red", "b*color=green", "b*color=red",
+	}
+	if !slices.Equal(e.Names(), want) {
+		t.Errorf("unexpected terms:\n got %v\nwant %v", e.Names(), want)
+	}
+	if row := e.Row([]float64{2, 3, 1, 0}); row[5] != 8 || row[8] != 6 || row[9] != 2 || row[10] != 0 {
+		t.Errorf("unexpected expanded row %v", row)
+	}
+	if _, err := NewFeatureExpansion(md, FeatureOptions{Degree: -1}); err == nil {
+		t.Error("expected a negative degree error")
+	}
+}
+
+// ✅ Test 2: A quadratic with an interaction is recovered and predicts from raw inputs
+func TestFitExpanded(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 30; i++ {
+		a, b := float64(i%6), float64(i/6)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+a-2*b+0.5*a*a+3*a*b)
+	}
+
+	fit, err := FitExpanded(md, FeatureOptions{Degree: 2, Interactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// terms: a, b, a^2, b^2, a*b
+	want := []float64{1, 1, -2, 0.5, 0, 3}
+	for j, c := range fit.Coefficients {
+		if math.Abs(c-want[j]) > 1e-9 {
+			t.Errorf("%s: expected %v, got %v", append([]string{"(intercept)"}, fit.Names...)[j], want[j], c)
+		}
+	}
+	if got := fit.Predict([]float64{10, 10}); math.Abs(got-(1+10-20+50+300)) > 1e-7 {
+		t.Errorf("prediction from raw inputs: got %v", got)
+	}
+
+	if _, err := (FeatureExpansion{Inputs: []string{"z"}}).Apply(md); err == nil || !strings.Contains(err.Error(), "do not match") {
+		t.Errorf("expected an input mismatch error, got %v", err)
+	}
+}
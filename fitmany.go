@@ -0,0 +1,58 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// fitManyBlock is how many consecutive series a worker claims at a time. Claiming blocks
+// instead of single series keeps scheduling overhead negligible next to fits of a few points.
+const fitManyBlock = 256
+
+// SeriesFit is the outcome of one series passed to FitMany
+type SeriesFit struct {
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+	Err       error   `json:"-"`
+}
+
+// FitMany fits many small, independent series (such as per-customer trends) on all CPUs.
+// Results are returned in input order; a series that cannot be fitted carries its own Err
+// without affecting the others. Each fit uses FastRegression, so the only allocation is the result slice.
+func FitMany(series []Dataset) []SeriesFit {
+	results := make([]SeriesFit, len(series))
+	blocks := (len(series) + fitManyBlock - 1) / fitManyBlock
+	workers := min(runtime.GOMAXPROCS(0), blocks)
+
+	fitRange := func(start, end int) {
+		for i := start; i < end; i++ {
+			r := &results[i]
+			r.Slope, r.Intercept, r.RSquared, r.Err = FastRegression(series[i].X, series[i].Y)
+		}
+	}
+
+	if workers <= 1 {
+		fitRange(0, len(series))
+		return results
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				b := int(next.Add(1) - 1)
+				if b >= blocks {
+					return
+				}
+				fitRange(b*fitManyBlock, min((b+1)*fitManyBlock, len(series)))
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Results come back in input order with per-series errors
+func TestFitManyOrderAndErrors(t *testing.T) {
+	series := make([]Dataset, 3*fitManyBlock+7)
+	for i := range series {
+		slope := float64(i)
+		series[i] = Dataset{X: []float64{0, 1, 2}, Y: []float64{1, 1 + slope, 1 + 2*slope}}
+	}
+	series[5] = Dataset{X: []float64{1}, Y: []float64{1}}
+	series[600] = Dataset{X: []float64{1, math.NaN()}, Y: []float64{1, 2}}
+
+	results := FitMany(series)
+	if len(results) != len(series) {
+		t.Fatalf("expected %d results, got %d", len(series), len(results))
+	}
+	for i, r := range results {
+		switch i {
+		case 5, 600:
+			if r.Err == nil {
+				t.Errorf("series %d: expected an error", i)
+			}
+		default:
+			if r.Err != nil || math.Abs(r.Slope-float64(i)) > 1e-9 || math.Abs(r.Intercept-1) > 1e-9 {
+				t.Errorf("series %d: got %+v", i, r)
+			}
+		}
+	}
+
+	if got := FitMany(nil); len(got) != 0 {
+		t.Errorf("expected no results for no series, got %d", len(got))
+	}
+}
+
+// ✅ Test 2: Benchmark thousands of small series
+func BenchmarkFitMany(b *testing.B) {
+	for _, count := range []int{1000, 100000} {
+		series := make([]Dataset, count)
+		for i := range series {
+			x, y := syntheticLine(12)
+			series[i] = Dataset{X: x, Y: y}
+		}
+		b.Run(fmt.Sprintf("series=%d", count), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				FitMany(series)
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+// Dataset32 stores a series in single precision, halving memory compared to Dataset.
+// It is meant for very large inputs on memory-constrained (embedded/edge) hosts.
+type Dataset32 struct {
+	X []float32
+	Y []float32
+}
+
+// NewDataset32 converts data to single precision. Values are rounded to the nearest float32.
+func NewDataset32(data Dataset) Dataset32 {
+	out := Dataset32{X: make([]float32, len(data.X)), Y: make([]float32, len(data.Y))}
+	for i, v := range data.X {
+		out.X[i] = float32(v)
+	}
+	for i, v := range data.Y {
+		out.Y[i] = float32(v)
+	}
+	return out
+}
+
+// Float32Regression fits single-precision data without converting the inputs to float64 slices.
+// Each value is promoted to float64 as it is read and the Welford co-moments are accumulated in
+// float64, so the arithmetic adds no error beyond storage: the coefficients equal those of
+// FastRegression on the float32-rounded values.
+//
+// The accuracy trade-off is the storage rounding itself. float32 keeps about 7 significant digits,
+// so for well-conditioned data expect coefficients within ~1e-5 relative of the float64 fit.
+// Data whose X spread is small relative to its magnitude (e.g. timestamps, x = 1e6 + small steps)
+// loses most of its information when stored as float32; keep such data in float64 or centre it first.
+func Float32Regression(x, y []float32) (slope, intercept, rSquared float64, err error) {
+	if err := checkPairLengths(len(x), len(y)); err != nil {
+		return 0, 0, 0, err
+	}
+
+	// OnlineRegression skips NaN/Inf pairs itself, so no float64 copy of the data is needed
+	var acc OnlineRegression
+	for i := range x {
+		acc.Add(float64(x[i]), float64(y[i]))
+	}
+	if err := checkValidPairs(acc.N()); err != nil {
+		return 0, 0, 0, err
+	}
+
+	s := acc.Snapshot()
+	return s.Slope, s.Intercept, s.RSquared, nil
+}
+
+// float32Fit adapts Float32Regression to Engine.Fit by rounding the inputs to single precision first.
+// Values beyond the float32 range become Inf and are skipped like any other invalid pair.
+func float32Fit(x, y []float64) (float64, float64, float64, error) {
+	data := NewDataset32(Dataset{X: x, Y: y})
+	return Float32Regression(data.X, data.Y)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"unsafe"
+)
+
+// ✅ Test 1: Inputs exactly representable in float32 give the float64 result
+func TestFloat32RegressionExact(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		d32 := NewDataset32(data)
+		// Anscombe values have at most 2 decimals, so compare against the rounded values in float64
+		x64 := make([]float64, len(d32.X))
+		y64 := make([]float64, len(d32.Y))
+		for i := range d32.X {
+			x64[i], y64[i] = float64(d32.X[i]), float64(d32.Y[i])
+		}
+
+		slope, intercept, r2, err := Float32Regression(d32.X, d32.Y)
+		refSlope, refIntercept, refR2, _ := FastRegression(x64, y64)
+		if err != nil || slope != refSlope || intercept != refIntercept || r2 != refR2 {
+			t.Errorf("%s: float32 (%v %v %v %v) vs float64 on rounded values (%v %v %v)", name, slope, intercept, r2, err, refSlope, refIntercept, refR2)
+		}
+	}
+}
+
+// ✅ Test 2: The documented accuracy trade-off holds for well-conditioned data and memory is halved
+func TestFloat32AccuracyBound(t *testing.T) {
+	x, y := syntheticLine(100000)
+	slope, intercept, _, err := float32Fit(x, y)
+	refSlope, refIntercept, _, _ := BigFloatRegression(x, y)
+	if err != nil || deviation(slope, refSlope) > 1e-5 || deviation(intercept, refIntercept) > 1e-5 {
+		t.Errorf("float32 fit outside documented bound: slope %v vs %v, intercept %v vs %v (%v)", slope, refSlope, intercept, refIntercept, err)
+	}
+
+	if size := unsafe.Sizeof(float32(0)) * 2; size != unsafe.Sizeof(float64(0)) {
+		t.Errorf("expected float32 storage to be half of float64, got %d bytes per pair", size)
+	}
+}
+
+// ✅ Test 3: Validation errors match the other engines
+func TestFloat32RegressionValidation(t *testing.T) {
+	if _, _, _, err := Float32Regression([]float32{1, 2}, []float32{1}); err == nil {
+		t.Error("expected length mismatch error")
+	}
+	if _, _, _, err := Float32Regression([]float32{1, float32(math.NaN())}, []float32{1, 2}); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+	if _, _, _, err := float32Fit([]float64{1, 1e300}, []float64{1, 2}); err == nil {
+		t.Error("values outside the float32 range should be treated as invalid")
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+// defaultFollowInterval is how often -follow checks its file for new rows
+const defaultFollowInterval = time.Second
+
+// FollowUpdate is the fit of a followed file after the rows added since the previous update
+type FollowUpdate struct {
+	OnlineSnapshot
+	At      time.Time `json:"at"`
+	Added   int       `json:"added"`   // rows read by this update
+	Read    int       `json:"read"`    // rows read since the file was first opened, or last reset
+	Skipped int       `json:"skipped"` // malformed rows skipped by this update
+	Warning string    `json:"warning,omitempty"`
+	// Reset reports that the file was truncated or replaced, so the fit started again from its
+	// first row
+	Reset bool `json:"reset,omitempty"`
+}
+
+// CSVFollower tails a CSV file that another process keeps appending to, like tail -f, feeding
+// each complete new row into an OnlineRegression. The rules are those of CSVSource, except that a
+// malformed row is skipped and reported rather than ending the stream, as a long-running writer
+// may be restarted halfway through a line. Quoted fields must not contain line breaks.
+type CSVFollower struct {
+	path    string
+	locale  NumberLocale
+	file    *os.File
+	offset  int64
+	pending []byte // the start of a row whose line break has not been written yet
+	line    int
+	header  []string
+	acc     OnlineRegression
+	read    int
+}
+
+// NewCSVFollower opens path for following from its first row
+func NewCSVFollower(path string, l NumberLocale) (*CSVFollower, error) {
+	f := &CSVFollower{path: path, locale: l}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// open (re)opens the file and forgets everything read from it
+func (f *CSVFollower) open() error {
+	file, err := os.Open(f.path)
+	if err != nil {
+		return err
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file, f.offset, f.pending, f.line, f.header = file, 0, nil, 0, nil
+	f.acc, f.read = OnlineRegression{}, 0
+	return nil
+}
+
+// Poll reads the rows appended since the last call and returns the updated fit. A file that has
+// shrunk or been replaced, as log rotation does, is read again from the start with a new fit.
+func (f *CSVFollower) Poll() (FollowUpdate, error) {
+	var u FollowUpdate
+	if reset, err := f.replaced(); err != nil {
+		return FollowUpdate{}, err
+	} else if reset {
+		if err := f.open(); err != nil {
+			return FollowUpdate{}, err
+		}
+		u.Reset = true
+	}
+
+	chunk, err := io.ReadAll(f.file)
+	if err != nil {
+		return FollowUpdate{}, fmt.Errorf("reading %s: %w", f.path, err)
+	}
+	f.offset += int64(len(chunk))
+	f.pending = append(f.pending, chunk...)
+	end := bytes.LastIndexByte(f.pending, '\n')
+	if end >= 0 {
+		f.parse(string(f.pending[:end+1]), &u)
+		f.pending = append(f.pending[:0], f.pending[end+1:]...)
+	}
+	u.Read = f.read
+	u.OnlineSnapshot = f.acc.Snapshot()
+	return u, nil
+}
+
+// replaced reports whether the file at path is no longer the one being read, or has been
+// truncated below what was read from it
+func (f *CSVFollower) replaced() (bool, error) {
+	current, err := os.Stat(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		// between a rotation's rename and the new file's creation; keep reading the old one
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	open, err := f.file.Stat()
+	if err != nil {
+		return false, err
+	}
+	return !os.SameFile(open, current) || current.Size() < f.offset, nil
+}
+
+// parse adds the complete rows of text to the fit
+func (f *CSVFollower) parse(text string, u *FollowUpdate) {
+	reader := f.locale.newCSVReader(strings.NewReader(text))
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		f.line++
+		if err != nil {
+			u.Skipped++
+			u.Warning = fmt.Sprintf("line %d: %v", f.line, err)
+			continue
+		}
+		if len(record) < 2 {
+			u.Skipped++
+			u.Warning = fmt.Sprintf("line %d: expected at least 2 columns, got %d", f.line, len(record))
+			continue
+		}
+		x, errX := f.locale.ParseFloat(record[0])
+		y, errY := f.locale.ParseFloat(record[1])
+		if errX != nil || errY != nil {
+			if f.line == 1 {
+				f.header = append([]string(nil), record...)
+				continue
+			}
+			u.Skipped++
+			u.Warning = fmt.Sprintf("line %d: non-numeric value in %q", f.line, strings.Join(record[:2], string(reader.Comma)))
+			continue
+		}
+		f.acc.Add(x, y)
+		f.read++
+		u.Added++
+	}
+}
+
+// Axes returns the axes named by the file's header, if it has one
+func (f *CSVFollower) Axes() Dataset {
+	var axes Dataset
+	if f.header != nil {
+		axes.XAxis, axes.YAxis = ParseAxis(f.header[0]), ParseAxis(f.header[1])
+	}
+	return axes
+}
+
+// Follow polls every interval until ctx is done, calling fn with each update that read rows,
+// skipped some or reset the fit. An error from fn or from reading stops it; cancelling ctx returns
+// nil.
+func (f *CSVFollower) Follow(ctx context.Context, interval time.Duration, fn func(FollowUpdate) error) error {
+	defer f.file.Close()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		u, err := f.Poll()
+		if err != nil {
+			return err
+		}
+		if u.Added > 0 || u.Skipped > 0 || u.Reset {
+			u.At = time.Now()
+			if err := fn(u); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// printFollowUpdate writes an update as one line, in the number format of l
+func printFollowUpdate(w io.Writer, u FollowUpdate, axes Dataset, l NumberLocale) {
+	if u.Reset {
+		fmt.Fprintf(w, "%s  file truncated or replaced; refitting from the first row\n", u.At.Format(time.TimeOnly))
+	}
+	fit := "too few points for a line"
+	if u.N >= 2 {
+		fit = fmt.Sprintf("slope %s, intercept %s, R-squared %s",
+			l.withUnit(u.Slope, axes.SlopeUnit()), l.withUnit(u.Intercept, axes.YAxis.Unit), l.FormatFloat(u.RSquared, 6))
+	}
+	fmt.Fprintf(w, "%s  +%d rows, %d used of %d: %s\n", u.At.Format(time.TimeOnly), u.Added, u.N, u.Read, fit)
+	if u.Skipped > 0 {
+		fmt.Fprintf(w, "%s  skipped %d rows (last: %s)\n", u.At.Format(time.TimeOnly), u.Skipped, u.Warning)
+	}
+}
+
+// followFile prints the fit of the CSV file at path each time rows are appended to it, until
+// interrupted
+func followFile(path string, interval time.Duration, l NumberLocale) error {
+	f, err := NewCSVFollower(path, l)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	fmt.Printf("=== Following %s (every %s; interrupt to stop) ===\n", path, interval)
+	return f.Follow(ctx, interval, func(u FollowUpdate) error {
+		printFollowUpdate(os.Stdout, u, f.Axes(), l)
+		return nil
+	})
+}
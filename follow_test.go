@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// appendFile appends text to the file at path
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(text); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+}
+
+// ✅ Test 1: Appended rows update the fit once their line is complete, matching a fit of the whole file
+func TestCSVFollowerPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.csv")
+	appendFile(t, path, "dose (mg),response (%)\n1,2.1\n2,3.9\n")
+	f, err := NewCSVFollower(path, NumberLocale{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := f.Poll()
+	if err != nil || u.Added != 2 || u.N != 2 || u.Reset {
+		t.Fatalf("first poll %+v, %v", u, err)
+	}
+	if axes := f.Axes(); axes.XAxis.Unit != "mg" || axes.YAxis.Unit != "%" {
+		t.Errorf("unexpected axes %+v", axes)
+	}
+
+	// a row written in two pieces counts once it is complete
+	appendFile(t, path, "3,6.")
+	if u, _ = f.Poll(); u.Added != 0 || u.N != 2 {
+		t.Errorf("partial row was read: %+v", u)
+	}
+	appendFile(t, path, "2\n4,7.8\nNA,9\n")
+	if u, _ = f.Poll(); u.Added != 3 || u.Read != 5 || u.N != 4 {
+		t.Errorf("unexpected update %+v", u)
+	}
+	want, _ := LoadCSVFile(path)
+	slope, intercept, r2, _ := FastRegression(want.X, want.Y)
+	if math.Abs(u.Slope-slope) > 1e-12 || math.Abs(u.Intercept-intercept) > 1e-12 || math.Abs(u.RSquared-r2) > 1e-12 {
+		t.Errorf("followed fit %+v, whole file %v %v %v", u.OnlineSnapshot, slope, intercept, r2)
+	}
+
+	if u, _ = f.Poll(); u.Added != 0 || u.Skipped != 0 {
+		t.Errorf("expected nothing new, got %+v", u)
+	}
+}
+
+// ✅ Test 2: Malformed rows are skipped, and truncated or replaced files are refitted from the start
+func TestCSVFollowerResets(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.csv")
+	appendFile(t, path, "1,2\n2,4\nthree,6\n7\n4,8\n")
+	f, err := NewCSVFollower(path, NumberLocale{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := f.Poll()
+	if u.Added != 3 || u.Skipped != 2 || !strings.Contains(u.Warning, "line 4") {
+		t.Errorf("unexpected update %+v", u)
+	}
+
+	if err := os.WriteFile(path, []byte("1,5\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ = f.Poll(); !u.Reset || u.Added != 1 || u.Read != 1 {
+		t.Errorf("truncation: %+v", u)
+	}
+
+	// rotation: the old file is renamed away and a new one created in its place
+	appendFile(t, path, "2,5\n3,5\n4,5\n5,5\n")
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if u, _ = f.Poll(); u.Reset || u.Added != 4 {
+		t.Errorf("before the new file exists: %+v", u)
+	}
+	appendFile(t, path, "x,y\n10,1\n20,3\n")
+	if u, _ = f.Poll(); !u.Reset || u.Read != 2 || math.Abs(u.Slope-0.2) > 1e-12 {
+		t.Errorf("rotation: %+v", u)
+	}
+}
+
+// ✅ Test 3: Follow reports each batch of new rows until cancelled, and updates print as one line
+func TestCSVFollowerFollow(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.csv")
+	appendFile(t, path, "1,1\n2,3\n")
+	f, err := NewCSVFollower(path, NumberLocale{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	var updates []FollowUpdate
+	err = f.Follow(ctx, 5*time.Millisecond, func(u FollowUpdate) error {
+		updates = append(updates, u)
+		if len(updates) == 1 {
+			appendFile(t, path, "3,5\n")
+		} else {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || updates[1].N != 3 || math.Abs(updates[1].Slope-2) > 1e-12 || updates[1].At.IsZero() {
+		t.Fatalf("unexpected updates %+v", updates)
+	}
+
+	var buf bytes.Buffer
+	u := updates[1]
+	u.Skipped, u.Warning = 1, "line 9: non-numeric value"
+	printFollowUpdate(&buf, u, Dataset{}, NumberLocale{})
+	out := buf.String()
+	if !strings.Contains(out, "+1 rows, 3 used of 3: slope 2.000000, intercept -1.000000, R-squared 1.000000") || !strings.Contains(out, "skipped 1 rows (last: line 9") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+
+	if _, err := NewCSVFollower(filepath.Join(t.TempDir(), "missing.csv"), NumberLocale{}); err == nil {
+		t.Error("expected a missing file to be rejected")
+	}
+}
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Formula is a model specification in the R style, such as "y ~ x + x^2 + group + x:group".
+// Terms are separated by "+"; a term is a product of factors joined by ":", and a factor is a
+// column name optionally raised to a whole power with "^". "a*b" is shorthand for
+// "a + b + a:b", and "." stands for every column but the response. Column names that are not
+// plain identifiers are written in backquotes, as in "`dose (mg)`". A categorical column
+// contributes its dummy columns, so a product with one gives a term per level. The intercept is
+// always included; "1" may be written for it.
+type Formula struct {
+	Response string
+	// Terms lists the factors of each term, with a column repeated for its powers
+	Terms [][]string
+}
+
+// String writes the formula back in canonical form
+func (f Formula) String() string {
+	terms := make([]string, len(f.Terms))
+	for i, t := range f.Terms {
+		terms[i] = formulaTermName(t)
+	}
+	if len(terms) == 0 {
+		terms = []string{"1"}
+	}
+	return formulaQuote(f.Response) + " ~ " + strings.Join(terms, " + ")
+}
+
+func formulaQuote(name string) string {
+	for i, r := range name {
+		if !(unicode.IsLetter(r) || r == '_' || r == '.' || i > 0 && unicode.IsDigit(r)) {
+			return "`" + name + "`"
+		}
+	}
+	return name
+}
+
+// formulaTermName writes a term's factors as in the formula: powers as "x^2", products with ":"
+func formulaTermName(factors []string) string {
+	var parts []string
+	for i := 0; i < len(factors); {
+		j := i
+		for j < len(factors) && factors[j] == factors[i] {
+			j++
+		}
+		part := formulaQuote(factors[i])
+		if j-i > 1 {
+			part += "^" + strconv.Itoa(j-i)
+		}
+		parts = append(parts, part)
+		i = j
+	}
+	return strings.Join(parts, ":")
+}
+
+type formulaToken struct {
+	kind byte // 'n' for a name, '#' for a number, or the operator itself
+	text string
+}
+
+func tokenizeFormula(s string) ([]formulaToken, error) {
+	var tokens []formulaToken
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case strings.IndexByte("~+:*^.-", c) >= 0 && !(c == '.' && i+1 < len(s) && isFormulaNameByte(s[i+1])):
+			tokens = append(tokens, formulaToken{kind: c, text: string(c)})
+			i++
+		case c == '`':
+			end := strings.IndexByte(s[i+1:], '`')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated backquote at offset %d", i)
+			}
+			tokens = append(tokens, formulaToken{kind: 'n', text: s[i+1 : i+1+end]})
+			i += end + 2
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, formulaToken{kind: '#', text: s[i:j]})
+			i = j
+		case isFormulaNameByte(c):
+			j := i
+			for j < len(s) && (isFormulaNameByte(s[j]) || s[j] >= '0' && s[j] <= '9') {
+				j++
+			}
+			tokens = append(tokens, formulaToken{kind: 'n', text: s[i:j]})
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+	}
+	return tokens, nil
+}
+
+func isFormulaNameByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+// ParseFormula parses a formula such as "y ~ x + x^2 + group"
+func ParseFormula(s string) (Formula, error) {
+	tokens, err := tokenizeFormula(s)
+	if err != nil {
+		return Formula{}, fmt.Errorf("formula %q: %w", s, err)
+	}
+	if len(tokens) < 2 || tokens[0].kind != 'n' || tokens[1].kind != '~' {
+		return Formula{}, fmt.Errorf("formula %q: expected \"response ~ terms\"", s)
+	}
+	f := Formula{Response: tokens[0].text}
+	seen := map[string]bool{}
+	add := func(factors []string) {
+		sort.Strings(factors)
+		key := strings.Join(factors, "\x00")
+		if !seen[key] {
+			seen[key] = true
+			f.Terms = append(f.Terms, factors)
+		}
+	}
+
+	// each "+"-separated part is a product of factors; "*" also adds every sub-product
+	pos := 2
+	for {
+		type factor struct {
+			names []string
+			cross bool // joined to the previous factor with "*"
+		}
+		var factors []factor
+		intercept := false
+		for {
+			if pos >= len(tokens) {
+				return Formula{}, fmt.Errorf("formula %q: missing term at the end", s)
+			}
+			tok := tokens[pos]
+			pos++
+			switch {
+			case tok.kind == '-':
+				return Formula{}, fmt.Errorf("formula %q: removing terms (including the intercept) is not supported", s)
+			case tok.kind == '#' && tok.text == "1" && len(factors) == 0:
+				intercept = true
+			case tok.kind == '#':
+				return Formula{}, fmt.Errorf("formula %q: unexpected number %s (only 1, the intercept, may stand alone)", s, tok.text)
+			case tok.kind == '.':
+				factors = append(factors, factor{names: []string{"."}})
+			case tok.kind == 'n':
+				names := []string{tok.text}
+				if pos < len(tokens) && tokens[pos].kind == '^' {
+					if pos+1 >= len(tokens) || tokens[pos+1].kind != '#' {
+						return Formula{}, fmt.Errorf("formula %q: expected a whole power after ^", s)
+					}
+					power, _ := strconv.Atoi(tokens[pos+1].text)
+					if power < 1 || power > 10 {
+						return Formula{}, fmt.Errorf("formula %q: power %d out of range 1 to 10", s, power)
+					}
+					for len(names) < power {
+						names = append(names, tok.text)
+					}
+					pos += 2
+				}
+				factors = append(factors, factor{names: names})
+			default:
+				return Formula{}, fmt.Errorf("formula %q: unexpected %q", s, tok.text)
+			}
+			if pos >= len(tokens) || tokens[pos].kind == '+' {
+				break
+			}
+			if intercept || tokens[pos].kind != ':' && tokens[pos].kind != '*' {
+				return Formula{}, fmt.Errorf("formula %q: unexpected %q", s, tokens[pos].text)
+			}
+			if tokens[pos].kind == '*' {
+				factors = append(factors, factor{cross: true})
+			}
+			pos++
+		}
+
+		// split at "*" into groups multiplied with ":" and add every product of a subset of groups
+		var groups [][]string
+		var current []string
+		for _, fac := range factors {
+			if fac.cross {
+				groups = append(groups, current)
+				current = nil
+				continue
+			}
+			current = append(current, fac.names...)
+		}
+		groups = append(groups, current)
+		if len(groups) > 6 {
+			return Formula{}, fmt.Errorf("formula %q: too many factors crossed with *", s)
+		}
+		for mask := 1; mask < 1<<len(groups); mask++ {
+			var term []string
+			for g, names := range groups {
+				if mask&(1<<g) != 0 {
+					term = append(term, names...)
+				}
+			}
+			if len(term) > 0 {
+				add(term)
+			}
+		}
+
+		if pos >= len(tokens) {
+			break
+		}
+		pos++ // "+"
+	}
+	for _, t := range f.Terms {
+		if len(t) > 1 && slices.Contains(t, ".") {
+			return Formula{}, fmt.Errorf("formula %q: . cannot be part of a product or power", s)
+		}
+	}
+	return f, nil
+}
+
+// formulaColumn is a column of a MultiDataset as a formula sees it: a numeric predictor, or a
+// categorical one with its dummy predictors
+type formulaColumn struct {
+	name    string
+	inputs  []int // indices into MultiDataset.Names
+	dummies bool
+}
+
+// formulaColumns groups md's predictors into the columns they were loaded from
+func formulaColumns(md MultiDataset) []formulaColumn {
+	dummyOf := map[string]string{}
+	for _, enc := range md.Encodings {
+		for _, name := range enc.Names() {
+			dummyOf[name] = enc.Column
+		}
+	}
+	var cols []formulaColumn
+	index := map[string]int{}
+	for i, name := range md.Names {
+		column, dummy := dummyOf[name]
+		if !dummy {
+			column = name
+		}
+		k, ok := index[column]
+		if !ok || !dummy {
+			k = len(cols)
+			index[column] = k
+			cols = append(cols, formulaColumn{name: column, dummies: dummy})
+		}
+		cols[k].inputs = append(cols[k].inputs, i)
+	}
+	return cols
+}
+
+// Design resolves the formula against md's predictors. It returns md restricted to the columns
+// the formula uses, and the expansion that builds the formula's terms from that dataset's rows.
+func (f Formula) Design(md MultiDataset) (MultiDataset, FeatureExpansion, error) {
+	cols := formulaColumns(md)
+	lookup := func(name string) (formulaColumn, error) {
+		for _, c := range cols {
+			if c.name == name {
+				return c, nil
+			}
+		}
+		for _, c := range cols {
+			if strings.EqualFold(c.name, name) {
+				return c, nil
+			}
+		}
+		return formulaColumn{}, fmt.Errorf("unknown column %q", name)
+	}
+
+	terms := f.Terms
+	for i := 0; i < len(terms); i++ {
+		if len(terms[i]) == 1 && terms[i][0] == "." {
+			var all [][]string
+			for _, c := range cols {
+				all = append(all, []string{c.name})
+			}
+			terms = append(append(append([][]string{}, terms[:i]...), all...), terms[i+1:]...)
+			i += len(all) - 1
+		}
+	}
+
+	// every term becomes the products of one input per factor, across the dummies of categorical factors
+	type expanded struct {
+		name    string
+		factors []int
+	}
+	var built []expanded
+	used := map[int]bool{}
+	seen := map[string]bool{}
+	for _, term := range terms {
+		products := []expanded{{}}
+		for i := 0; i < len(term); {
+			j := i
+			for j < len(term) && term[j] == term[i] {
+				j++
+			}
+			col, err := lookup(term[i])
+			if err != nil {
+				return MultiDataset{}, FeatureExpansion{}, err
+			}
+			power := j - i
+			if col.dummies && power > 1 {
+				return MultiDataset{}, FeatureExpansion{}, fmt.Errorf("categorical column %s cannot be raised to a power", col.name)
+			}
+			var next []expanded
+			for _, p := range products {
+				for _, input := range col.inputs {
+					name := md.Names[input]
+					if power > 1 {
+						name += "^" + strconv.Itoa(power)
+					}
+					if p.name != "" {
+						name = p.name + "*" + name
+					}
+					factors := append([]int(nil), p.factors...)
+					for k := 0; k < power; k++ {
+						factors = append(factors, input)
+					}
+					next = append(next, expanded{name, factors})
+				}
+			}
+			products = next
+			i = j
+		}
+		for _, p := range products {
+			if !seen[p.name] {
+				seen[p.name] = true
+				built = append(built, p)
+				for _, input := range p.factors {
+					used[input] = true
+				}
+			}
+		}
+	}
+	if len(built) == 0 {
+		return MultiDataset{}, FeatureExpansion{}, fmt.Errorf("formula %s has no predictors", f)
+	}
+
+	// keep the used inputs in dataset order, with the encodings of their columns
+	position := map[int]int{}
+	sub := MultiDataset{Y: md.Y, X: make([][]float64, len(md.X))}
+	for i, name := range md.Names {
+		if used[i] {
+			position[i] = len(sub.Names)
+			sub.Names = append(sub.Names, name)
+		}
+	}
+	for r, row := range md.X {
+		sub.X[r] = make([]float64, 0, len(sub.Names))
+		for i, v := range row {
+			if used[i] {
+				sub.X[r] = append(sub.X[r], v)
+			}
+		}
+	}
+	for _, enc := range md.Encodings {
+		for _, name := range enc.Names() {
+			if i := slices.Index(md.Names, name); i >= 0 && used[i] {
+				sub.Encodings = append(sub.Encodings, enc)
+				break
+			}
+		}
+	}
+	e := FeatureExpansion{Inputs: sub.Names}
+	for _, b := range built {
+		factors := make([]int, len(b.factors))
+		for k, input := range b.factors {
+			factors[k] = position[input]
+		}
+		e.Terms = append(e.Terms, FeatureTerm{Name: b.name, Factors: factors})
+	}
+	return sub, e, nil
+}
+
+// FitFormula fits the formula's terms to md with FitMultiple. It also returns the dataset of the
+// columns the formula uses, whose rows are what the fit's Predict takes.
+func FitFormula(md MultiDataset, f Formula) (MultiFit, MultiDataset, error) {
+	sub, e, err := f.Design(md)
+	if err != nil {
+		return MultiFit{}, MultiDataset{}, err
+	}
+	expanded, err := e.Apply(sub)
+	if err != nil {
+		return MultiFit{}, MultiDataset{}, err
+	}
+	fit, err := FitMultiple(expanded)
+	if err != nil {
+		return MultiFit{}, MultiDataset{}, err
+	}
+	fit.Expansion = &e
+	return fit, sub, nil
+}
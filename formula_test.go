@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Formulas parse into canonical terms, expanding * and powers
+func TestParseFormula(t *testing.T) {
+	cases := map[string]string{
+		"y ~ x + x^2 + group":       "y ~ x + x^2 + group",
+		"y~a*b":                     "y ~ a + b + a:b",
+		"y ~ 1 + x + x":             "y ~ x",
+		"y ~ b:a + a:b":             "y ~ a:b",
+		"`dose (mg)` ~ `t 1`^3 + .": "`dose (mg)` ~ `t 1`^3 + .",
+		"resp ~ x.1 + g*x^2":        "resp ~ x.1 + g + x^2 + g:x^2",
+		"y ~ 1":                     "y ~ 1",
+		"y ~ a * b * c":             "y ~ a + b + a:b + c + a:c + b:c + a:b:c",
+	}
+	for in, want := range cases {
+		f, err := ParseFormula(in)
+		if err != nil {
+			t.Errorf("%q: %v", in, err)
+			continue
+		}
+		if got := f.String(); got != want {
+			t.Errorf("%q parsed as %q, want %q", in, got, want)
+		}
+	}
+	for _, bad := range []string{"", "y", "~ x", "y ~", "y ~ x +", "y ~ x - 1", "y ~ 0 + x", "y ~ x^", "y ~ x^0", "y ~ x^a", "y ~ (x)", "y ~ `x", "y ~ .:x", "y ~ 1:x", "y ~ x x"} {
+		if _, err := ParseFormula(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+// ✅ Test 2: A formula fit matches building the design by hand, with dummies and interactions
+func TestFitFormula(t *testing.T) {
+	var csv strings.Builder
+	csv.WriteString("x,noise,group,y\n")
+	for i := 0; i < 30; i++ {
+		x := float64(i % 10)
+		group := []string{"a", "b", "c"}[i%3]
+		y := 1 + 0.5*x - 0.1*x*x + 0.01*math.Sin(float64(i))
+		switch group {
+		case "b":
+			y += 2 + 0.3*x
+		case "c":
+			y -= 1
+		}
+		fmt.Fprintf(&csv, "%g,%d,%s,%v\n", x, i*7%5, group, y)
+	}
+	md, err := LoadMultiCSV(strings.NewReader(csv.String()), "y")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, _ := ParseFormula("y ~ x + x^2 + group + x:group")
+	fit, sub, err := FitFormula(md, f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(sub.Names, ",") != "x,group=b,group=c" || len(sub.Encodings) != 1 {
+		t.Errorf("unexpected inputs %v, encodings %v", sub.Names, sub.Encodings)
+	}
+	if got := strings.Join(fit.Names, ","); got != "x,x^2,group=b,group=c,group=b*x,group=c*x" {
+		t.Errorf("unexpected terms %s", got)
+	}
+
+	hand := MultiDataset{Names: []string{"x", "x2", "b", "c", "bx", "cx"}, Y: md.Y}
+	for _, row := range sub.X {
+		x, b, c := row[0], row[1], row[2]
+		hand.X = append(hand.X, []float64{x, x * x, b, c, b * x, c * x})
+	}
+	ref, _ := FitMultiple(hand)
+	for j := range ref.Coefficients {
+		if math.Abs(fit.Coefficients[j]-ref.Coefficients[j]) > 1e-9 {
+			t.Errorf("coefficient %d: %v, want %v", j, fit.Coefficients[j], ref.Coefficients[j])
+		}
+	}
+	if y := fit.Predict([]float64{4, 1, 0}); math.Abs(y-(1+2-1.6+2+1.2)) > 0.05 {
+		t.Errorf("prediction for group b at 4: %v", y)
+	}
+	if m := NewMultipleModel("f", fit, sub); strings.Join(m.Inputs(), ",") != "x,group=b,group=c" || m.Stats == nil {
+		t.Errorf("model inputs %v", m.Inputs())
+	}
+
+	all, _ := ParseFormula("y ~ .")
+	if fit, sub, err := FitFormula(md, all); err != nil || len(sub.Names) != 4 || len(fit.Names) != 4 {
+		t.Errorf("y ~ .: %v %v, %v", sub.Names, fit.Names, err)
+	}
+	for _, bad := range []string{"y ~ missing", "y ~ group^2", "y ~ group:group"} {
+		f, err := ParseFormula(bad)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := FitFormula(md, f); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
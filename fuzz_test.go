@@ -0,0 +1,190 @@
+package main
+
+// Fuzz targets for the fitters and loaders. `go test .` runs only the seeds below and the saved
+// corpus in testdata/fuzz; to search for new failures run one target at a time, for example
+//
+//	go test -run '^$' -fuzz '^FuzzPerformLinearRegression$' -fuzztime 1m .
+//
+// and commit any failing input the fuzzer writes to testdata/fuzz alongside the fix.
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fuzzFloats decodes data as little-endian float64 bit patterns, so the fuzzer reaches NaN
+// payloads, infinities, denormals and negative zero directly
+func fuzzFloats(data []byte) []float64 {
+	values := make([]float64, len(data)/8)
+	for i := range values {
+		values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data[8*i:]))
+	}
+	return values
+}
+
+// fuzzPairs splits data into equally long x and y halves
+func fuzzPairs(data []byte) (x, y []float64) {
+	values := fuzzFloats(data)
+	half := len(values) / 2
+	return values[:half], values[half : 2*half]
+}
+
+func floatBytes(values ...float64) []byte {
+	data := make([]byte, 8*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint64(data[8*i:], math.Float64bits(v))
+	}
+	return data
+}
+
+func addFloatSeeds(f *testing.F) {
+	f.Add(floatBytes(1, 2, 3, 2, 4, 6))
+	f.Add(floatBytes(10, 8, 13, 9, 8.04, 6.95, 7.58, 8.81))
+	f.Add(floatBytes(1, math.NaN(), 3, math.Inf(1), 2, 4, 6, 8))
+	f.Add(floatBytes(5e-324, 1e-320, 2.2e-308, 1, 2, 3))
+	f.Add(floatBytes(1e308, -1e308, 1e308, 1, 2, 3))
+	f.Add(floatBytes(math.Copysign(0, -1), 0, 1, 1, 1, 1))
+	f.Add(floatBytes(7, 7, 7, 1, 2, 3))
+}
+
+// finitePairs counts the pairs PerformLinearRegression keeps
+func finitePairs(x, y []float64) int {
+	n := 0
+	for i := range x {
+		if !isMissing(x[i]) && !isMissing(y[i]) {
+			n++
+		}
+	}
+	return n
+}
+
+// ✅ Test 1: PerformLinearRegression never panics, fails exactly when fewer than two pairs are finite,
+// and keeps R-squared in [0, 1]
+func FuzzPerformLinearRegression(f *testing.F) {
+	addFloatSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		x, y := fuzzPairs(data)
+		_, _, r2, err := PerformLinearRegression(x, y)
+		if (err != nil) != (finitePairs(x, y) < 2) {
+			t.Fatalf("error %v with %d finite pairs", err, finitePairs(x, y))
+		}
+		if err == nil && !math.IsNaN(r2) && (r2 < -1e-9 || r2 > 1+1e-9) {
+			t.Fatalf("R-squared %v out of range for x %v, y %v", r2, x, y)
+		}
+	})
+}
+
+// ✅ Test 2: Every registered engine validates input like PerformLinearRegression and never panics
+func FuzzEngines(f *testing.F) {
+	addFloatSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		x, y := fuzzPairs(data)
+		if len(x) > 64 {
+			// the bigfloat engine is slow, and longer inputs find nothing new
+			x, y = x[:64], y[:64]
+		}
+		for _, e := range Engines() {
+			finite := finitePairs(x, y)
+			if e.Name == "float32" {
+				// values beyond the float32 range become Inf and are skipped (see float32Fit)
+				single := NewDataset32(Dataset{X: x, Y: y})
+				finite = 0
+				for i := range single.X {
+					if !isMissing(float64(single.X[i])) && !isMissing(float64(single.Y[i])) {
+						finite++
+					}
+				}
+			}
+			if _, _, _, err := e.Fit(x, y); (err != nil) != (finite < 2) {
+				t.Fatalf("engine %s: error %v with %d finite pairs", e.Name, err, finite)
+			}
+		}
+	})
+}
+
+func addCSVSeeds(f *testing.F) {
+	f.Add([]byte("x,y\n1,2\n2,4\n3,6\n"))
+	f.Add([]byte("dose (mg),response [1/s]\n1,NA\n2,\n3,NaN\n4,null\n"))
+	f.Add([]byte("\ufeffx,y\r\n1e308,1e-320\r\n-0,+Inf\r\n"))
+	f.Add([]byte("\"1\",\"2\"\n\"3\n\",4\n"))
+	f.Add([]byte("1\n2,3,4\n,,\n"))
+	f.Add([]byte("a,\"b\"\"c\n"))
+	f.Add([]byte(strings.Repeat("1,", 100) + "\n"))
+}
+
+// ✅ Test 3: LoadCSVDataset rejects hostile input with an error and only returns paired columns
+func FuzzLoadCSVDataset(f *testing.F) {
+	addCSVSeeds(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ds, err := LoadCSVDataset(bytes.NewReader(data))
+		if err != nil {
+			return
+		}
+		if len(ds.X) == 0 || len(ds.X) != len(ds.Y) {
+			t.Fatalf("loaded %d x and %d y values", len(ds.X), len(ds.Y))
+		}
+	})
+}
+
+// ✅ Test 4: LoadTimeSeriesCSV returns a chronologically sorted, paired series or an error
+func FuzzLoadTimeSeriesCSV(f *testing.F) {
+	addCSVSeeds(f)
+	f.Add([]byte("time,value\n2024-01-02T00:00:00Z,2\n2024-01-01 12:00,1\n1700000000.5,3\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		ts, err := LoadTimeSeriesCSV(bytes.NewReader(data), time.UTC)
+		if err != nil {
+			return
+		}
+		if len(ts.Times) == 0 || len(ts.Times) != len(ts.Y) {
+			t.Fatalf("loaded %d times and %d values", len(ts.Times), len(ts.Y))
+		}
+		for i := 1; i < len(ts.Times); i++ {
+			if ts.Times[i].Before(ts.Times[i-1]) {
+				t.Fatalf("times out of order at %d", i)
+			}
+		}
+	})
+}
+
+// ✅ Test 5: LoadMultiCSV returns one row of predictors per response, each as wide as Names
+func FuzzLoadMultiCSV(f *testing.F) {
+	addCSVSeeds(f)
+	f.Add([]byte("x1,color,y\n1,red,2\n2,blue,3\n3,red,NA\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		md, err := LoadMultiCSV(bytes.NewReader(data), "")
+		if err != nil {
+			return
+		}
+		if len(md.X) != len(md.Y) {
+			t.Fatalf("%d predictor rows for %d responses", len(md.X), len(md.Y))
+		}
+		for i, row := range md.X {
+			if len(row) != len(md.Names) {
+				t.Fatalf("row %d has %d predictors for %d names", i, len(row), len(md.Names))
+			}
+		}
+	})
+}
+
+// ✅ Test 6: LoadCSVGroups returns non-empty, paired datasets under distinct keys
+func FuzzLoadCSVGroups(f *testing.F) {
+	addCSVSeeds(f)
+	f.Add([]byte("site,x,y\na,1,2\nb,1,3\na,2,4\n"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		groups, err := LoadCSVGroups(bytes.NewReader(data), "1")
+		if err != nil {
+			return
+		}
+		seen := map[string]bool{}
+		for _, g := range groups {
+			if seen[g.Key] || len(g.Data.X) == 0 || len(g.Data.X) != len(g.Data.Y) {
+				t.Fatalf("group %q: duplicate or unpaired (%d x, %d y)", g.Key, len(g.Data.X), len(g.Data.Y))
+			}
+			seen[g.Key] = true
+		}
+	})
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// RunRecord is the machine-readable output of an analysis run, as kept in golden files. It leaves
+// out timings and anything else that differs between two runs on the same data.
+type RunRecord struct {
+	Datasets []DatasetRecord `json:"datasets"`
+}
+
+// DatasetRecord is the recorded output for one dataset. Optional sections are present when the
+// run asked for them.
+type DatasetRecord struct {
+	Name         string             `json:"name"`
+	Error        string             `json:"error,omitempty"`
+	Points       int                `json:"points"`
+	Slope        float64            `json:"slope"`
+	Intercept    float64            `json:"intercept"`
+	RSquared     float64            `json:"rSquared"`
+	Fingerprint  string             `json:"fingerprint,omitempty"`
+	Engine       string             `json:"engine,omitempty"`
+	Fallback     string             `json:"fallback,omitempty"`
+	Imputed      []int              `json:"imputed,omitempty"`
+	Residuals    *DistributionTests `json:"residuals,omitempty"`
+	ChangePoints *ChangePoints      `json:"changePoints,omitempty"`
+	Clusters     *DatasetClusters   `json:"clusters,omitempty"`
+	Influence    []PointDiagnostic  `json:"influence,omitempty"`
+}
+
+// RunRecordOptions selects the optional sections of a RunRecord, matching the report flags
+type RunRecordOptions struct {
+	Diagnostics  bool
+	ChangePoints bool
+	Clusters     ClusterSpace
+	Influence    int // number of most influential points to record, 0 for none
+}
+
+// NewRunRecord records outcomes in order. Sections that cannot be computed for a dataset are
+// left out, as the text report prints n/a for them.
+func NewRunRecord(outcomes []AnalysisOutcome, opts RunRecordOptions) RunRecord {
+	rec := RunRecord{Datasets: make([]DatasetRecord, 0, len(outcomes))}
+	for _, o := range outcomes {
+		d := DatasetRecord{Name: o.Name, Points: len(o.Data.X)}
+		if o.Err != nil {
+			d.Error = o.Err.Error()
+			rec.Datasets = append(rec.Datasets, d)
+			continue
+		}
+		d.Slope, d.Intercept, d.RSquared = o.Result.Slope, o.Result.Intercept, o.Result.RSquared
+		d.Fingerprint, d.Imputed = o.Result.Fingerprint, o.Result.Imputed
+		d.Engine, d.Fallback = o.Result.Engine, o.Result.Fallback
+		if opts.Diagnostics {
+			if tests, err := NormalityTests(Residuals(o.Data.X, o.Data.Y, d.Slope, d.Intercept)); err == nil {
+				d.Residuals = &tests
+			}
+		}
+		if opts.ChangePoints {
+			if cp, err := DetectChangePoints(o.Data, ChangePointOptions{}); err == nil {
+				d.ChangePoints = &cp
+			}
+		}
+		if opts.Clusters != "" {
+			if c, err := ClusterDataset(o.Data, opts.Clusters, 6, clusterSeed); err == nil {
+				d.Clusters = &c
+			}
+		}
+		if opts.Influence > 0 {
+			ranking := InfluenceRanking(o.Data, d.Slope, d.Intercept)
+			d.Influence = ranking[:min(opts.Influence, len(ranking))]
+		}
+		rec.Datasets = append(rec.Datasets, d)
+	}
+	return rec
+}
+
+// WriteGoldenFile records rec at path as indented JSON
+func WriteGoldenFile(path string, rec RunRecord) error {
+	data, err := json.MarshalIndent(rec, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// CheckGoldenFile compares rec with the golden file at path
+func CheckGoldenFile(path string, rec RunRecord, tol GoldenTolerance) ([]GoldenDiff, error) {
+	want, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	got, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	return CompareGolden(want, got, tol)
+}
+
+// GoldenTolerance bounds how far a recorded number may move: a value matches when it is within
+// Abs + Rel * |golden| of the golden value
+type GoldenTolerance struct {
+	Abs float64
+	Rel float64
+}
+
+// DefaultGoldenTolerance() allows for differences in floating-point summation order only
+func DefaultGoldenTolerance() GoldenTolerance {
+	return GoldenTolerance{Abs: 1e-12, Rel: 1e-9}
+}
+
+// GoldenDiff is one difference from a golden file. Path locates it in JSON Pointer form; Want or
+// Got is nil when the value is missing on that side.
+type GoldenDiff struct {
+	Path string `json:"path"`
+	Want any    `json:"want"`
+	Got  any    `json:"got"`
+}
+
+func (d GoldenDiff) String() string {
+	switch {
+	case d.Want == nil:
+		return fmt.Sprintf("%s: unexpected %v", d.Path, d.Got)
+	case d.Got == nil:
+		return fmt.Sprintf("%s: missing, want %v", d.Path, d.Want)
+	}
+	return fmt.Sprintf("%s: got %v, want %v", d.Path, d.Got, d.Want)
+}
+
+// CompareGolden compares two JSON documents, numbers within tol and everything else exactly,
+// and returns the differences in document order
+func CompareGolden(want, got []byte, tol GoldenTolerance) ([]GoldenDiff, error) {
+	var w, g any
+	if err := json.Unmarshal(want, &w); err != nil {
+		return nil, fmt.Errorf("golden: %w", err)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return nil, fmt.Errorf("output: %w", err)
+	}
+	var diffs []GoldenDiff
+	compareJSON("", w, g, tol, &diffs)
+	return diffs, nil
+}
+
+func compareJSON(path string, want, got any, tol GoldenTolerance, diffs *[]GoldenDiff) {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(g))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range g {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			compareJSON(path+"/"+k, w[k], g[k], tol, diffs)
+		}
+		return
+	case []any:
+		g, ok := got.([]any)
+		if !ok {
+			break
+		}
+		for i := 0; i < max(len(w), len(g)); i++ {
+			var wi, gi any
+			if i < len(w) {
+				wi = w[i]
+			}
+			if i < len(g) {
+				gi = g[i]
+			}
+			compareJSON(fmt.Sprintf("%s/%d", path, i), wi, gi, tol, diffs)
+		}
+		return
+	case float64:
+		if g, ok := got.(float64); ok && math.Abs(g-w) <= tol.Abs+tol.Rel*math.Abs(w) {
+			return
+		}
+	default:
+		if want == got {
+			return
+		}
+	}
+	*diffs = append(*diffs, GoldenDiff{Path: path, Want: want, Got: got})
+}
+
+// printGoldenDiffs writes the differences from a golden file, at most limit of them
+func printGoldenDiffs(w io.Writer, diffs []GoldenDiff, limit int) {
+	for i, d := range diffs {
+		if i == limit {
+			fmt.Fprintf(w, "  ... and %d more\n", len(diffs)-limit)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", d)
+	}
+}
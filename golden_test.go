@@ -0,0 +1,66 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var updateGoldenFiles = flag.Bool("update", false, "rewrite the golden files in testdata instead of comparing")
+
+// ✅ Test 1: The full Anscombe record matches testdata (go test -run TestAnscombeGolden -update to re-record)
+func TestAnscombeGolden(t *testing.T) {
+	record := NewRunRecord(AnalyzeAll(anscombeJobs(), 1), RunRecordOptions{Diagnostics: true, ChangePoints: true, Clusters: ClusterXY})
+	path := filepath.Join("testdata", "anscombe.golden.json")
+	if *updateGoldenFiles {
+		if err := WriteGoldenFile(path, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+	diffs, err := CheckGoldenFile(path, record, DefaultGoldenTolerance())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range diffs {
+		t.Error(d)
+	}
+}
+
+// ✅ Test 2: Numbers are compared within tolerance, everything else exactly
+func TestCompareGolden(t *testing.T) {
+	want := []byte(`{"a": 1.0, "b": [1, 2, 3], "c": "x", "d": {"e": true}}`)
+	got := []byte(`{"a": 1.0000000001, "b": [1, 2], "c": "y", "d": {"e": true, "f": 1}}`)
+	diffs, err := CompareGolden(want, got, GoldenTolerance{Rel: 1e-9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, d := range diffs {
+		paths = append(paths, d.Path)
+	}
+	if strings.Join(paths, " ") != "/b/2 /c /d/f" {
+		t.Fatalf("unexpected differences %v", diffs)
+	}
+	if diffs[0].String() != "/b/2: missing, want 3" || diffs[2].String() != "/d/f: unexpected 1" {
+		t.Errorf("unexpected messages %q, %q", diffs[0], diffs[2])
+	}
+
+	diffs, _ = CompareGolden(want, got, GoldenTolerance{})
+	if len(diffs) != 4 || diffs[0].Path != "/a" {
+		t.Errorf("expected /a to differ without tolerance, got %v", diffs)
+	}
+	if _, err := CompareGolden([]byte("{"), got, DefaultGoldenTolerance()); err == nil {
+		t.Error("expected an error for an invalid golden file")
+	}
+}
+
+// ✅ Test 3: Failed datasets are recorded with their error and no fit
+func TestRunRecordError(t *testing.T) {
+	jobs := []AnalysisJob{{Name: "short", Data: Dataset{X: []float64{1, 2, 3}, Y: []float64{1, 2}}}}
+	record := NewRunRecord(AnalyzeAll(jobs, 1), RunRecordOptions{ChangePoints: true})
+	d := record.Datasets[0]
+	if d.Error == "" || d.Points != 3 || d.ChangePoints != nil {
+		t.Fatalf("unexpected record %+v", d)
+	}
+}
@@ -0,0 +1,85 @@
+//go:build gonum
+
+package main
+
+import (
+	"fmt"
+	"math"
+
+	"gonum.org/v1/gonum/mat"
+	"gonum.org/v1/gonum/stat"
+)
+
+// The gonum engine is built only with -tags gonum, so the default build does not link gonum.
+
+func init() {
+	RegisterEngine(Engine{Name: "gonum", Description: "gonum/stat linear regression", Fit: cleanedFit(GonumRegression)})
+}
+
+// GonumRegression fits the line with gonum's stat.LinearRegression and stat.RSquared. Constant X
+// gives a zero slope and constant Y an R² of 1, as in ManualRegression, where gonum itself would
+// return NaN.
+func GonumRegression(x, y []float64) (slope, intercept, rSquared float64) {
+	if stat.Variance(x, nil) == 0 {
+		mean := stat.Mean(y, nil)
+		if stat.Variance(y, nil) == 0 {
+			return 0, mean, 1
+		}
+		return 0, mean, 0
+	}
+	intercept, slope = stat.LinearRegression(x, y, nil, false)
+	if stat.Variance(y, nil) == 0 {
+		return slope, intercept, 1
+	}
+	return slope, intercept, math.Max(stat.RSquared(x, y, nil, intercept, slope), 0)
+}
+
+// FitMultipleGonum is FitMultiple solved with gonum's QR decomposition, for cross-checking it or
+// for designs large enough to benefit from gonum's blocked linear algebra. Rank deficiency is
+// detected from the condition number of the design rather than column by column, so the error
+// does not name the offending predictor.
+func FitMultipleGonum(md MultiDataset) (MultiFit, error) {
+	if len(md.X) != len(md.Y) {
+		return MultiFit{}, fmt.Errorf("x and y length mismatch: %d rows vs %d responses", len(md.X), len(md.Y))
+	}
+	p := len(md.Names)
+	var data, y []float64
+	for i, row := range md.X {
+		if len(row) != p {
+			return MultiFit{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), p)
+		}
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		data = append(append(data, 1), row...)
+		y = append(y, md.Y[i])
+	}
+	n := len(y)
+	if n <= p+1 {
+		return MultiFit{}, fmt.Errorf("need more complete rows than coefficients (have %d rows for %d coefficients)", n, p+1)
+	}
+
+	design := mat.NewDense(n, p+1, data)
+	var qr mat.QR
+	qr.Factorize(design)
+	if cond := qr.Cond(); cond > 1e12 || math.IsInf(cond, 0) || math.IsNaN(cond) {
+		return MultiFit{}, fmt.Errorf("predictors are collinear (condition number %.3g)", cond)
+	}
+	var beta mat.Dense
+	if err := qr.SolveTo(&beta, false, mat.NewDense(n, 1, y)); err != nil {
+		return MultiFit{}, fmt.Errorf("solving the least squares problem: %w", err)
+	}
+
+	fit := MultiFit{Names: md.Names, Coefficients: mat.Col(nil, 0, &beta), N: n, Encodings: md.Encodings}
+	var fitted mat.VecDense
+	fitted.MulVec(design, beta.ColView(0))
+	mean := stat.Mean(y, nil)
+	var ssTotal, ssResidual float64
+	for i, v := range y {
+		r := v - fitted.AtVec(i)
+		ssTotal += (v - mean) * (v - mean)
+		ssResidual += r * r
+	}
+	fit.RSquared = rSquaredFrom(ssTotal, ssResidual)
+	return fit, nil
+}
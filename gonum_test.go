@@ -0,0 +1,84 @@
+//go:build gonum
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func init() {
+	// gonum centres the data before forming its sums, so it keeps up with the compensated engine
+	engineULPBounds["gonum"] = 8
+	for name, bound := range map[string][3]float64{
+		"well": {1e-13, 1e-13, 1e-13}, "offset 1e6": {1e-12, 1e-10, 1e-11}, "offset 1e9": {1e-13, 1e-11, 1e-8},
+		"huge 1e150": {1e-13, 1e-9, 1e-13}, "tiny 1e-150": {1e-13, 1e-11, 1e-13},
+		"tiny y variance": {1e-13, 1e-13, 1e-9}, "near-constant x": {1e-13, 1e-13, 1e-10},
+	} {
+		stabilityBounds[name]["gonum"] = bound
+	}
+}
+
+// ✅ Test 1: The gonum engine agrees with the manual engine on the quartet
+func TestGonumMatchesManual(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		gs, gi, gr := GonumRegression(ds.X, ds.Y)
+		ms, mi, mr := ManualRegression(ds.X, ds.Y)
+		if relErr(gs, ms) > 1e-12 || relErr(gi, mi) > 1e-12 || math.Abs(gr-mr) > 1e-12 {
+			t.Errorf("dataset %s: gonum %v %v %v, manual %v %v %v", name, gs, gi, gr, ms, mi, mr)
+		}
+	}
+}
+
+// ✅ Test 2: The registered engine handles constant X, missing values and bad input like the others
+func TestGonumDegenerate(t *testing.T) {
+	slope, intercept, r2 := GonumRegression([]float64{2, 2, 2}, []float64{1, 2, 3})
+	if slope != 0 || intercept != 2 || r2 != 0 {
+		t.Errorf("constant x: got %v %v %v", slope, intercept, r2)
+	}
+	engine, ok := LookupEngine("gonum")
+	if !ok {
+		t.Fatal("gonum engine is not registered")
+	}
+	slope, intercept, _, err := engine.Fit([]float64{1, 2, math.NaN(), 3}, []float64{2, 4, 5, 6})
+	if err != nil || relErr(slope, 2) > 1e-12 || math.Abs(intercept) > 1e-12 {
+		t.Errorf("missing value: got %v %v %v", slope, intercept, err)
+	}
+	if _, _, _, err := engine.Fit([]float64{1}, []float64{2, 3}); err == nil {
+		t.Error("expected an error for mismatched lengths")
+	}
+}
+
+// ✅ Test 3: FitMultipleGonum reproduces FitMultiple and rejects collinear predictors
+func TestFitMultipleGonum(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 20; i++ {
+		a, b := float64(i), math.Sin(float64(i))
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1.5+0.25*a-3*b+0.01*math.Cos(7*float64(i)))
+	}
+	md.X[4][1], md.Y[9] = math.NaN(), math.Inf(1)
+	want, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := FitMultipleGonum(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.N != want.N || math.Abs(got.RSquared-want.RSquared) > 1e-12 {
+		t.Errorf("got N %d R² %v, want N %d R² %v", got.N, got.RSquared, want.N, want.RSquared)
+	}
+	for j := range want.Coefficients {
+		if relErr(got.Coefficients[j], want.Coefficients[j]) > 1e-10 {
+			t.Errorf("coefficient %d: got %v, want %v", j, got.Coefficients[j], want.Coefficients[j])
+		}
+	}
+
+	for i := range md.X {
+		md.X[i][1] = 2 * md.X[i][0]
+	}
+	if _, err := FitMultipleGonum(md); err == nil {
+		t.Error("expected an error for collinear predictors")
+	}
+}
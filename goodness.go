@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// ChiSquaredResult is a chi-squared goodness-of-fit test
+type ChiSquaredResult struct {
+	Statistic float64 `json:"statistic"`
+	DF        int     `json:"df"`
+	PValue    float64 `json:"pValue"`
+}
+
+// DistributionTests bundles the goodness-of-fit tests of a sample against a normal distribution
+// with the sample's own mean and standard deviation
+type DistributionTests struct {
+	N          int              `json:"n"`
+	KS         KSResult         `json:"ks"`
+	ChiSquared ChiSquaredResult `json:"chiSquared"`
+}
+
+// KSOneSample compares the empirical distribution of values with the continuous CDF cdf and
+// returns the largest distance with its asymptotic p-value. NaN and Inf values are ignored.
+func KSOneSample(values []float64, cdf func(float64) float64) (KSResult, error) {
+	sorted := sortedFinite(values)
+	if len(sorted) == 0 {
+		return KSResult{}, fmt.Errorf("no finite values")
+	}
+	n := float64(len(sorted))
+	var d float64
+	for i, v := range sorted {
+		f := cdf(v)
+		d = math.Max(d, math.Max(float64(i+1)/n-f, f-float64(i)/n))
+	}
+	sqrtN := math.Sqrt(n)
+	return KSResult{Statistic: d, PValue: kolmogorovQ((sqrtN + 0.12 + 0.11/sqrtN) * d)}, nil
+}
+
+// ChiSquaredGOF compares observed bin counts with expected ones. ddof is the number of parameters
+// estimated from the data, which are subtracted from the degrees of freedom along with one.
+func ChiSquaredGOF(observed, expected []float64, ddof int) (ChiSquaredResult, error) {
+	if len(observed) != len(expected) {
+		return ChiSquaredResult{}, fmt.Errorf("observed and expected length mismatch: %d vs %d", len(observed), len(expected))
+	}
+	df := len(observed) - 1 - ddof
+	if df < 1 {
+		return ChiSquaredResult{}, fmt.Errorf("need more bins than %d estimated parameters plus one (have %d)", ddof, len(observed))
+	}
+	var stat float64
+	for i, o := range observed {
+		if !(expected[i] > 0) {
+			return ChiSquaredResult{}, fmt.Errorf("bin %d: expected count must be positive, got %v", i, expected[i])
+		}
+		stat += (o - expected[i]) * (o - expected[i]) / expected[i]
+	}
+	return ChiSquaredResult{Statistic: stat, DF: df, PValue: chiSquaredSurvival(stat, float64(df))}, nil
+}
+
+// NormalityTests tests whether values look normally distributed, fitting the mean and standard
+// deviation from the sample. The chi-squared test uses ceil(2 n^0.4) equiprobable bins (at least 4).
+// Because the parameters are estimated, the KS p-value is conservative (Lilliefors); the chi-squared
+// degrees of freedom account for them.
+func NormalityTests(values []float64) (DistributionTests, error) {
+	sorted := sortedFinite(values)
+	n := len(sorted)
+	bins := max(4, int(math.Ceil(2*math.Pow(float64(n), 0.4))))
+	if n < bins {
+		return DistributionTests{}, fmt.Errorf("need at least %d finite values, have %d", bins, n)
+	}
+	mean, variance := meanVariance(sorted)
+	if variance == 0 {
+		return DistributionTests{}, fmt.Errorf("values are constant")
+	}
+	sd := math.Sqrt(variance)
+	cdf := func(v float64) float64 { return normalCDF((v - mean) / sd) }
+
+	ks, err := KSOneSample(sorted, cdf)
+	if err != nil {
+		return DistributionTests{}, err
+	}
+
+	observed, expected := make([]float64, bins), make([]float64, bins)
+	for b := range expected {
+		expected[b] = float64(n) / float64(bins)
+	}
+	// the upper edge of bin b is the normal quantile at (b+1)/bins; values are sorted, so each
+	// bin's count is the difference between consecutive edge positions
+	below := 0
+	for b := range observed {
+		upper := n
+		if b < bins-1 {
+			edge := mean + sd*normalQuantile(float64(b+1)/float64(bins))
+			upper = sort.Search(n, func(i int) bool { return sorted[i] > edge })
+		}
+		observed[b] = float64(upper - below)
+		below = upper
+	}
+	chi, err := ChiSquaredGOF(observed, expected, 2)
+	if err != nil {
+		return DistributionTests{}, err
+	}
+	return DistributionTests{N: n, KS: ks, ChiSquared: chi}, nil
+}
+
+// Residuals returns y - (intercept + slope*x) for every complete pair, in input order
+func Residuals(x, y []float64, slope, intercept float64) []float64 {
+	out := make([]float64, 0, len(x))
+	for i := range x {
+		if i < len(y) && !isMissing(x[i]) && !isMissing(y[i]) {
+			out = append(out, y[i]-(intercept+slope*x[i]))
+		}
+	}
+	return out
+}
+
+// printDistributionDiagnostics writes the diagnostics section of a dataset report: normality of
+// the residuals (an assumption of the fit's inference) and of the raw Y values
+func printDistributionDiagnostics(w io.Writer, data Dataset, result RegressionResult) {
+	fmt.Fprintf(w, "  Diagnostics:\n")
+	samples := []struct {
+		name   string
+		values []float64
+	}{
+		{"Residuals", Residuals(data.X, data.Y, result.Slope, result.Intercept)},
+		{"Y", data.Y},
+	}
+	for _, s := range samples {
+		tests, err := NormalityTests(s.values)
+		if err != nil {
+			fmt.Fprintf(w, "    %-10s normality n/a (%v)\n", s.name+":", err)
+			continue
+		}
+		fmt.Fprintf(w, "    %-10s KS D = %.4f (p = %.4g), chi-squared = %.4f (df %d, p = %.4g)\n",
+			s.name+":", tests.KS.Statistic, tests.KS.PValue, tests.ChiSquared.Statistic, tests.ChiSquared.DF, tests.ChiSquared.PValue)
+	}
+	// residuals are in input order, so autocorrelation is only meaningful for ordered data
+	printAutocorrelation(w, "Residuals", samples[0].values)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Chi-squared tail probabilities and the one-sample KS statistic on known values
+func TestGoodnessOfFitBasics(t *testing.T) {
+	if p := chiSquaredSurvival(3, 2); math.Abs(p-math.Exp(-1.5)) > 1e-12 {
+		t.Errorf("df 2: expected exp(-x/2), got %v", p)
+	}
+	if p := chiSquaredSurvival(3.841458820694124, 1); math.Abs(p-0.05) > 1e-10 {
+		t.Errorf("df 1: expected the 5%% critical value, got %v", p)
+	}
+	if p := chiSquaredSurvival(30, 20); math.Abs(p-0.06985366069940878) > 1e-9 {
+		t.Errorf("df 20: got %v", p)
+	}
+
+	ks, err := KSOneSample([]float64{0.9, 0.1, 0.5, math.NaN()}, func(v float64) float64 { return v })
+	if err != nil || math.Abs(ks.Statistic-0.7/3) > 1e-12 {
+		t.Errorf("uniform KS: %+v (err %v)", ks, err)
+	}
+
+	chi, err := ChiSquaredGOF([]float64{10, 20, 30}, []float64{20, 20, 20}, 0)
+	if err != nil || chi.Statistic != 10 || chi.DF != 2 || math.Abs(chi.PValue-math.Exp(-5)) > 1e-12 {
+		t.Errorf("unexpected chi-squared %+v (err %v)", chi, err)
+	}
+	if _, err := ChiSquaredGOF([]float64{1, 2}, []float64{1, 2}, 1); err == nil {
+		t.Error("expected an error without degrees of freedom")
+	}
+}
+
+// ✅ Test 2: Normal samples pass the normality tests and skewed ones fail
+func TestNormalityTests(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	normal, skewed := make([]float64, 2000), make([]float64, 2000)
+	for i := range normal {
+		normal[i], skewed[i] = 5+2*rng.NormFloat64(), rng.ExpFloat64()
+	}
+
+	got, err := NormalityTests(normal)
+	if err != nil || got.KS.PValue < 0.01 || got.ChiSquared.PValue < 0.01 || got.ChiSquared.DF != 39 {
+		t.Errorf("normal sample rejected: %+v (err %v)", got, err)
+	}
+	if got, _ := NormalityTests(skewed); got.KS.PValue > 1e-6 || got.ChiSquared.PValue > 1e-6 {
+		t.Errorf("exponential sample accepted: %+v", got)
+	}
+	if _, err := NormalityTests([]float64{1, 1, 1, 1, 1}); err == nil {
+		t.Error("expected an error for constant values")
+	}
+}
+
+// ✅ Test 3: The report's diagnostics section covers residuals and Y
+func TestPrintDistributionDiagnostics(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	result, _ := AnalyzeDataset("I", data)
+	var buf bytes.Buffer
+	printDistributionDiagnostics(&buf, data, result)
+	for _, want := range []string{"Diagnostics:", "Residuals: KS D = ", "Y:         KS D = "} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Loss is a penalty on residuals r = y - ŷ for GradientRegression. Derivative is dL/dr; for losses
+// with kinks, such as L1 at zero, any subgradient will do. Name is used in reports.
+type Loss struct {
+	Name       string
+	Value      func(r float64) float64
+	Derivative func(r float64) float64
+}
+
+// SquaredLoss is the L2 loss r²/2 of ordinary least squares
+func SquaredLoss() Loss {
+	return Loss{
+		Name:       "l2",
+		Value:      func(r float64) float64 { return r * r / 2 },
+		Derivative: func(r float64) float64 { return r },
+	}
+}
+
+// AbsoluteLoss is the L1 loss |r| of least absolute deviations, which fits the conditional median
+func AbsoluteLoss() Loss {
+	return Loss{
+		Name:  "l1",
+		Value: math.Abs,
+		Derivative: func(r float64) float64 {
+			switch {
+			case r > 0:
+				return 1
+			case r < 0:
+				return -1
+			}
+			return 0
+		},
+	}
+}
+
+// HuberLoss is quadratic for residuals within delta (in Y units) and linear beyond, so points
+// further than delta from the line pull on it with a fixed force however far out they are
+func HuberLoss(delta float64) Loss {
+	return Loss{
+		Name: fmt.Sprintf("huber:%g", delta),
+		Value: func(r float64) float64 {
+			if a := math.Abs(r); a > delta {
+				return delta * (a - delta/2)
+			}
+			return r * r / 2
+		},
+		Derivative: func(r float64) float64 {
+			return math.Max(-delta, math.Min(delta, r))
+		},
+	}
+}
+
+// ParseLoss parses the command-line form of a built-in loss: l2, l1 or huber:DELTA
+func ParseLoss(spec string) (Loss, error) {
+	name, arg, hasArg := strings.Cut(spec, ":")
+	switch name {
+	case "l2", "l1":
+		if hasArg {
+			return Loss{}, fmt.Errorf("loss %s takes no parameter", name)
+		}
+		if name == "l1" {
+			return AbsoluteLoss(), nil
+		}
+		return SquaredLoss(), nil
+	case "huber":
+		delta, err := strconv.ParseFloat(arg, 64)
+		if err != nil || !(delta > 0) || math.IsInf(delta, 0) {
+			return Loss{}, fmt.Errorf("invalid loss %q: huber needs a positive delta, as in huber:1.5", spec)
+		}
+		return HuberLoss(delta), nil
+	}
+	return Loss{}, fmt.Errorf("unknown loss %q (use l2, l1 or huber:DELTA)", spec)
+}
+
+// GradientOptions control GradientRegression. Zero values select the defaults.
+type GradientOptions struct {
+	MaxIterations int     // default 1000
+	Tolerance     float64 // stop when an iteration lowers the mean loss by less than this fraction; default 1e-12
+}
+
+// GradientFit is the line GradientRegression found
+type GradientFit struct {
+	Loss       string  `json:"loss"`
+	Slope      float64 `json:"slope"`
+	Intercept  float64 `json:"intercept"`
+	MeanLoss   float64 `json:"meanLoss"`
+	Iterations int     `json:"iterations"`
+	Converged  bool    `json:"converged"`
+}
+
+// GradientRegression fits y = intercept + slope·x minimizing the mean of loss over the residuals,
+// by gradient descent with a backtracking (Armijo) line search. It starts from the least squares
+// line and works on standardized X and Y, so the step size needs no tuning; the loss still sees
+// residuals in Y units. Pairs with NaN/Inf values are dropped as by the engines.
+//
+// Convex losses reach their minimum. For non-smooth losses such as L1 the descent stops once no
+// step along the subgradient helps, which lands close to, but not exactly on, the minimum.
+func GradientRegression(x, y []float64, loss Loss, opts GradientOptions) (GradientFit, error) {
+	if loss.Value == nil || loss.Derivative == nil {
+		return GradientFit{}, fmt.Errorf("loss %q needs both a value and a derivative", loss.Name)
+	}
+	x, y, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return GradientFit{}, err
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 1000
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-12
+	}
+
+	mx, vx := meanVariance(x)
+	my, vy := meanVariance(y)
+	sx, sy := math.Sqrt(vx), math.Sqrt(vy)
+	fit := GradientFit{Loss: loss.Name}
+	if sy == 0 {
+		fit.Intercept, fit.MeanLoss, fit.Converged = my, loss.Value(0), true
+		return fit, nil
+	}
+	if sx == 0 {
+		// the slope stays zero, as in ManualRegression, but the best level still depends on the loss
+		sx = 1
+	}
+	u := make([]float64, len(x))
+	v := make([]float64, len(y))
+	for i := range x {
+		u[i] = (x[i] - mx) / sx
+		v[i] = (y[i] - my) / sy
+	}
+
+	// a and b are the intercept and slope on the standardized scale; residuals go back to Y units
+	meanLoss := func(a, b float64) float64 {
+		var sum float64
+		for i := range u {
+			sum += loss.Value(sy * (v[i] - a - b*u[i]))
+		}
+		return sum / float64(len(u))
+	}
+	var a, b float64
+	if vx > 0 {
+		var cuv float64
+		for i := range u {
+			cuv += u[i] * v[i]
+		}
+		b = cuv / float64(len(u)-1)
+	}
+	current := meanLoss(a, b)
+	step := 1.0
+	for fit.Iterations < opts.MaxIterations {
+		fit.Iterations++
+		var ga, gb float64
+		for i := range u {
+			d := -sy * loss.Derivative(sy*(v[i]-a-b*u[i]))
+			ga += d
+			if vx > 0 {
+				gb += d * u[i]
+			}
+		}
+		ga /= float64(len(u))
+		gb /= float64(len(u))
+		g2 := ga*ga + gb*gb
+		if g2 == 0 {
+			fit.Converged = true
+			break
+		}
+
+		// grow the previous step, then halve it until the loss drops enough
+		step *= 2
+		next := current
+		var na, nb float64
+		for ; step > 1e-16; step /= 2 {
+			na, nb = a-step*ga, b-step*gb
+			if next = meanLoss(na, nb); next <= current-1e-4*step*g2 {
+				break
+			}
+		}
+		if step <= 1e-16 {
+			fit.Converged = true
+			break
+		}
+		decrease := current - next
+		a, b, current = na, nb, next
+		if decrease <= opts.Tolerance*math.Max(current, math.SmallestNonzeroFloat64) {
+			fit.Converged = true
+			break
+		}
+	}
+
+	fit.Slope = b * sy / sx
+	fit.Intercept = my + a*sy - fit.Slope*mx
+	fit.MeanLoss = current
+	return fit, nil
+}
+
+// printGradientFit writes the fit of a dataset under a custom loss next to its least squares line
+func printGradientFit(w io.Writer, data Dataset, loss Loss) {
+	fit, err := GradientRegression(data.X, data.Y, loss, GradientOptions{})
+	if err != nil {
+		fmt.Fprintf(w, "  Loss %s:  n/a (%v)\n", loss.Name, err)
+		return
+	}
+	note := ""
+	if !fit.Converged {
+		note = fmt.Sprintf(", not converged after %d iterations", fit.Iterations)
+	}
+	fmt.Fprintf(w, "  Loss %s:  slope %.6f, intercept %.6f, mean loss %.6g%s\n", loss.Name, fit.Slope, fit.Intercept, fit.MeanLoss, note)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: The L2 loss reproduces the least squares line
+func TestGradientRegressionSquared(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		fit, err := GradientRegression(ds.X, ds.Y, SquaredLoss(), GradientOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slope, intercept, _ := ManualRegression(ds.X, ds.Y)
+		if !fit.Converged || relErr(fit.Slope, slope) > 1e-9 || relErr(fit.Intercept, intercept) > 1e-9 {
+			t.Errorf("dataset %s: got %+v, want slope %v intercept %v", name, fit, slope, intercept)
+		}
+	}
+}
+
+// ✅ Test 2: L1 and Huber losses ignore the outlier of Anscombe III that drags least squares
+func TestGradientRegressionRobust(t *testing.T) {
+	ds := LoadAnscombeDatasets()["III"]
+	// the ten other points lie on y = 4.01 + 0.345x to two decimals, which L1 passes through exactly;
+	// Huber still feels the outlier's clipped pull, against least squares' slope of 0.5
+	for _, c := range []struct {
+		loss Loss
+		tol  float64
+	}{{AbsoluteLoss(), 1e-6}, {HuberLoss(0.1), 1e-2}} {
+		fit, err := GradientRegression(ds.X, ds.Y, c.loss, GradientOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(fit.Slope-0.345) > c.tol || math.Abs(fit.Intercept-4.01) > 5*c.tol {
+			t.Errorf("loss %s: got slope %v intercept %v", c.loss.Name, fit.Slope, fit.Intercept)
+		}
+	}
+}
+
+// ✅ Test 3: A custom loss plugs in, and constant or missing data are handled
+func TestGradientRegressionCustom(t *testing.T) {
+	// log-cosh behaves like L2 near zero and like L1 far out
+	logCosh := Loss{
+		Name:       "logcosh",
+		Value:      func(r float64) float64 { return math.Log(math.Cosh(r)) },
+		Derivative: math.Tanh,
+	}
+	x := []float64{0, 1, 2, 3, math.NaN(), 4, 5}
+	y := []float64{1, 3, 5, 7, 100, 9, 11}
+	fit, err := GradientRegression(x, y, logCosh, GradientOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit.Loss != "logcosh" || relErr(fit.Slope, 2) > 1e-6 || math.Abs(fit.Intercept-1) > 1e-6 {
+		t.Errorf("got %+v", fit)
+	}
+
+	fit, err = GradientRegression([]float64{1, 2, 3}, []float64{4, 4, 4}, AbsoluteLoss(), GradientOptions{})
+	if err != nil || fit.Slope != 0 || fit.Intercept != 4 || !fit.Converged {
+		t.Errorf("constant y: got %+v, %v", fit, err)
+	}
+	// with constant X the L1 level is the median, not the mean
+	fit, err = GradientRegression([]float64{2, 2, 2}, []float64{1, 2, 30}, AbsoluteLoss(), GradientOptions{})
+	if err != nil || fit.Slope != 0 || math.Abs(fit.Intercept-2) > 1e-6 {
+		t.Errorf("constant x: got %+v, %v", fit, err)
+	}
+	if _, err := GradientRegression(x, y, Loss{Name: "broken"}, GradientOptions{}); err == nil {
+		t.Error("expected an error for a loss without functions")
+	}
+}
+
+// ✅ Test 4: ParseLoss accepts the built-in losses and rejects bad specs
+func TestParseLoss(t *testing.T) {
+	for _, spec := range []string{"l2", "l1", "huber:1.5"} {
+		loss, err := ParseLoss(spec)
+		if err != nil || loss.Name != spec {
+			t.Errorf("%s: got %q, %v", spec, loss.Name, err)
+		}
+	}
+	for _, spec := range []string{"", "l3", "l1:2", "huber", "huber:0", "huber:-1", "huber:x"} {
+		if _, err := ParseLoss(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+	if d := HuberLoss(2).Derivative(-5); d != -2 {
+		t.Errorf("huber derivative clipped to %v, want -2", d)
+	}
+}
@@ -0,0 +1,554 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// The GraphQL endpoint supports the read-only subset the dashboard and frontend teams need:
+// a single query operation with field selections, aliases, arguments and variables.
+// Fragments, directives, mutations and subscriptions are rejected with an error.
+//
+// Schema:
+//
+//	type Query {
+//	  datasets(name: String): [Dataset!]!
+//	  dataset(name: String!): Dataset
+//	}
+//	type Dataset { name: String! n: Int! x: [Float!]! y: [Float!]! fit: Fit perPointDiagnostics: [PointDiagnostic!] }
+//	type Fit { slope: Float! intercept: Float! rSquared: Float! duration: Int! }
+//	type PointDiagnostic { index: Int! x: Float! y: Float! fitted: Float! residual: Float!
+//	                       leverage: Float standardizedResidual: Float cooksDistance: Float slopeChange: Float }
+
+// GraphQLRequest is the standard GraphQL-over-HTTP request body
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// GraphQLError is a single entry of the response "errors" list
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// GraphQLResponse is the standard GraphQL response envelope
+type GraphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
+
+// graphQLSchema lists the fields of every object type and the type of each object-valued field
+var graphQLSchema = map[string]map[string]string{
+	"Query":           {"datasets": "Dataset", "dataset": "Dataset"},
+	"Dataset":         {"name": "", "n": "", "x": "", "y": "", "fit": "Fit", "perPointDiagnostics": "PointDiagnostic"},
+	"Fit":             {"slope": "", "intercept": "", "rSquared": "", "duration": ""},
+	"PointDiagnostic": {"index": "", "x": "", "y": "", "fitted": "", "residual": "", "leverage": "", "standardizedResidual": "", "cooksDistance": "", "slopeChange": ""},
+}
+
+// graphQLDataset is the resolved value of the Dataset type
+type graphQLDataset struct {
+	Name                string            `json:"name"`
+	N                   int               `json:"n"`
+	X                   []float64         `json:"x"`
+	Y                   []float64         `json:"y"`
+	Fit                 *RegressionResult `json:"fit"`
+	PerPointDiagnostics []PointDiagnostic `json:"perPointDiagnostics"`
+}
+
+type gqlField struct {
+	Alias      string
+	Name       string
+	Args       map[string]any
+	Selections []gqlField
+}
+
+// ExecuteGraphQL runs a query against the given datasets
+func ExecuteGraphQL(req GraphQLRequest, datasets map[string]Dataset) GraphQLResponse {
+	selections, err := parseGraphQL(req.Query, req.Variables)
+	if err == nil {
+		err = validateGraphQL(selections, "Query")
+	}
+	if err != nil {
+		return GraphQLResponse{Errors: []GraphQLError{{Message: err.Error()}}}
+	}
+
+	var errs []GraphQLError
+	data := orderedObject{}
+	for _, field := range selections {
+		key := field.responseKey()
+		var value any
+		switch field.Name {
+		case "__typename":
+			data = append(data, orderedField{key, "Query"})
+			continue
+		case "datasets":
+			name, _ := field.Args["name"].(string)
+			nodes := []any{}
+			for _, dsName := range sortedDatasetNames(datasets) {
+				if name != "" && dsName != name {
+					continue
+				}
+				nodes = append(nodes, resolveGraphQLDataset(dsName, datasets[dsName]))
+			}
+			value = nodes
+		case "dataset":
+			name, ok := field.Args["name"].(string)
+			if !ok {
+				errs = append(errs, GraphQLError{Message: `argument "name" of type String! is required`, Path: []any{key}})
+				data = append(data, orderedField{key, nil})
+				continue
+			}
+			if ds, found := datasets[name]; found {
+				value = resolveGraphQLDataset(name, ds)
+			}
+		}
+
+		projected, err := projectGraphQL(value, field.Selections)
+		if err != nil {
+			errs = append(errs, GraphQLError{Message: err.Error(), Path: []any{key}})
+		}
+		data = append(data, orderedField{key, projected})
+	}
+
+	return GraphQLResponse{Data: data, Errors: errs}
+}
+
+func sortedDatasetNames(datasets map[string]Dataset) []string {
+	names := make([]string, 0, len(datasets))
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func resolveGraphQLDataset(name string, ds Dataset) graphQLDataset {
+	node := graphQLDataset{Name: name}
+	for i := range ds.X {
+		if i >= len(ds.Y) || math.IsNaN(ds.X[i]) || math.IsInf(ds.X[i], 0) || math.IsNaN(ds.Y[i]) || math.IsInf(ds.Y[i], 0) {
+			continue
+		}
+		node.X = append(node.X, ds.X[i])
+		node.Y = append(node.Y, ds.Y[i])
+	}
+	node.N = len(node.X)
+
+	if result, err := AnalyzeDataset(name, ds); err == nil {
+		node.Fit = &result
+		node.PerPointDiagnostics = PointDiagnostics(ds.X, ds.Y, result.Slope, result.Intercept)
+	}
+	return node
+}
+
+// validateGraphQL checks every selection against the schema before anything is resolved
+func validateGraphQL(selections []gqlField, typeName string) error {
+	fields := graphQLSchema[typeName]
+	for _, sel := range selections {
+		if sel.Name == "__typename" {
+			continue
+		}
+		childType, ok := fields[sel.Name]
+		if !ok {
+			return fmt.Errorf("Cannot query field %q on type %q", sel.Name, typeName)
+		}
+		if childType == "" && len(sel.Selections) > 0 {
+			return fmt.Errorf("Field %q must not have a selection since it is a scalar", sel.Name)
+		}
+		if childType != "" && len(sel.Selections) == 0 {
+			return fmt.Errorf("Field %q of type %q must have a selection of subfields", sel.Name, childType)
+		}
+		if err := validateGraphQL(sel.Selections, childType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// projectGraphQL reduces a resolved Dataset value to the selected fields, preserving query order
+func projectGraphQL(value any, selections []gqlField) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	// Round-trip through JSON so struct values become generic maps keyed by their JSON names
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var generic any
+	if err := json.Unmarshal(raw, &generic); err != nil {
+		return nil, err
+	}
+
+	return projectGeneric(generic, "Dataset", selections), nil
+}
+
+func projectGeneric(value any, typeName string, selections []gqlField) any {
+	switch v := value.(type) {
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = projectGeneric(item, typeName, selections)
+		}
+		return out
+	case map[string]any:
+		obj := orderedObject{}
+		for _, sel := range selections {
+			if sel.Name == "__typename" {
+				obj = append(obj, orderedField{sel.responseKey(), typeName})
+				continue
+			}
+			child := projectGeneric(v[sel.Name], graphQLSchema[typeName][sel.Name], sel.Selections)
+			obj = append(obj, orderedField{sel.responseKey(), child})
+		}
+		return obj
+	default:
+		return v
+	}
+}
+
+func (f gqlField) responseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type orderedField struct {
+	Key   string
+	Value any
+}
+
+// orderedObject marshals as a JSON object whose keys keep the order of the query
+type orderedObject []orderedField
+
+func (o orderedObject) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(f.Key)
+		buf.Write(key)
+		buf.WriteByte(':')
+		value, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// --- parsing ---
+
+// maxGraphQLDepth bounds how deeply selection sets may nest. The schema is a few levels deep, and
+// the parser recurses once per level, so a deeper query is rejected rather than parsed.
+const maxGraphQLDepth = 32
+
+type gqlParser struct {
+	src       string
+	pos       int
+	depth     int // selection sets currently open
+	variables map[string]any
+	defaults  map[string]any
+}
+
+func parseGraphQL(query string, variables map[string]any) ([]gqlField, error) {
+	p := &gqlParser{src: query, variables: variables, defaults: map[string]any{}}
+
+	p.skipIgnored()
+	if name := p.peekName(); name != "" {
+		p.readName()
+		if name != "query" {
+			return nil, fmt.Errorf("only query operations are supported, got %q", name)
+		}
+		p.skipIgnored()
+		if p.peekName() != "" {
+			p.readName()
+		}
+		p.skipIgnored()
+		if p.peek() == '(' {
+			if err := p.parseVariableDefinitions(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	selections, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+	p.skipIgnored()
+	if p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q after query (multiple operations and fragments are not supported)", p.src[p.pos:min(p.pos+10, len(p.src))])
+	}
+	return selections, nil
+}
+
+func (p *gqlParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("Syntax Error at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *gqlParser) skipIgnored() {
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '#':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		case c == ',' || unicode.IsSpace(rune(c)):
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+func (p *gqlParser) peek() byte {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *gqlParser) expect(c byte) error {
+	p.skipIgnored()
+	if p.peek() != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func isNameByte(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (!first && c >= '0' && c <= '9')
+}
+
+func (p *gqlParser) peekName() string {
+	end := p.pos
+	for end < len(p.src) && isNameByte(p.src[end], end == p.pos) {
+		end++
+	}
+	return p.src[p.pos:end]
+}
+
+func (p *gqlParser) readName() string {
+	p.skipIgnored()
+	name := p.peekName()
+	p.pos += len(name)
+	return name
+}
+
+func (p *gqlParser) parseVariableDefinitions() error {
+	p.pos++ // (
+	for {
+		p.skipIgnored()
+		if p.peek() == ')' {
+			p.pos++
+			return nil
+		}
+		if err := p.expect('$'); err != nil {
+			return err
+		}
+		name := p.readName()
+		if name == "" {
+			return p.errorf("expected variable name")
+		}
+		if err := p.expect(':'); err != nil {
+			return err
+		}
+		// the type is not checked, only skipped
+		for {
+			p.skipIgnored()
+			if c := p.peek(); c == 0 || (strings.IndexByte("[]!", c) < 0 && !isNameByte(c, false)) {
+				break
+			}
+			p.pos++
+		}
+		if p.peek() == '=' {
+			p.pos++
+			value, err := p.parseValue()
+			if err != nil {
+				return err
+			}
+			p.defaults[name] = value
+		}
+	}
+}
+
+func (p *gqlParser) parseSelectionSet() ([]gqlField, error) {
+	if err := p.expect('{'); err != nil {
+		return nil, err
+	}
+	if p.depth++; p.depth > maxGraphQLDepth {
+		return nil, p.errorf("selections nested more than %d deep", maxGraphQLDepth)
+	}
+	defer func() { p.depth-- }()
+	var fields []gqlField
+	for {
+		p.skipIgnored()
+		switch p.peek() {
+		case '}':
+			p.pos++
+			if len(fields) == 0 {
+				return nil, p.errorf("empty selection set")
+			}
+			return fields, nil
+		case 0:
+			return nil, p.errorf("unexpected end of query")
+		case '.', '@':
+			return nil, p.errorf("fragments and directives are not supported")
+		}
+
+		field, err := p.parseField()
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, field)
+	}
+}
+
+func (p *gqlParser) parseField() (gqlField, error) {
+	name := p.readName()
+	if name == "" {
+		return gqlField{}, p.errorf("expected field name")
+	}
+	field := gqlField{Name: name}
+
+	p.skipIgnored()
+	if p.peek() == ':' {
+		p.pos++
+		field.Alias = name
+		field.Name = p.readName()
+		if field.Name == "" {
+			return gqlField{}, p.errorf("expected field name after alias %q", name)
+		}
+		p.skipIgnored()
+	}
+
+	if p.peek() == '(' {
+		p.pos++
+		field.Args = map[string]any{}
+		for {
+			p.skipIgnored()
+			if p.peek() == ')' {
+				p.pos++
+				break
+			}
+			argName := p.readName()
+			if argName == "" {
+				return gqlField{}, p.errorf("expected argument name")
+			}
+			if err := p.expect(':'); err != nil {
+				return gqlField{}, err
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return gqlField{}, err
+			}
+			field.Args[argName] = value
+		}
+		p.skipIgnored()
+	}
+
+	if p.peek() == '{' {
+		selections, err := p.parseSelectionSet()
+		if err != nil {
+			return gqlField{}, err
+		}
+		field.Selections = selections
+	}
+	return field, nil
+}
+
+func (p *gqlParser) parseValue() (any, error) {
+	p.skipIgnored()
+	switch c := p.peek(); {
+	case c == '$':
+		p.pos++
+		name := p.readName()
+		if v, ok := p.variables[name]; ok {
+			return v, nil
+		}
+		return p.defaults[name], nil
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.src) && p.src[end] != '"' {
+			if p.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.src) {
+			return nil, p.errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(p.src[p.pos : end+1])
+		if err != nil {
+			return nil, p.errorf("invalid string: %v", err)
+		}
+		p.pos = end + 1
+		return s, nil
+	case c == '-' || (c >= '0' && c <= '9'):
+		end := p.pos + 1
+		for end < len(p.src) && strings.IndexByte("0123456789.eE+-", p.src[end]) >= 0 {
+			end++
+		}
+		f, err := strconv.ParseFloat(p.src[p.pos:end], 64)
+		if err != nil {
+			return nil, p.errorf("invalid number %q", p.src[p.pos:end])
+		}
+		p.pos = end
+		return f, nil
+	case isNameByte(c, true):
+		switch name := p.readName(); name {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		default:
+			// enum value
+			return name, nil
+		}
+	default:
+		return nil, p.errorf("unexpected character %q in value", c)
+	}
+}
+
+// handleGraphQL serves POST (JSON body) and GET (?query=) GraphQL requests
+func handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req GraphQLRequest
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: []GraphQLError{{Message: "invalid variables: " + err.Error()}}})
+				return
+			}
+		}
+	} else if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, GraphQLResponse{Errors: []GraphQLError{{Message: "invalid request body: " + err.Error()}}})
+		return
+	}
+
+	resp := ExecuteGraphQL(req, LoadAnscombeDatasets())
+	status := http.StatusOK
+	if resp.Data == nil {
+		status = http.StatusBadRequest
+	}
+	writeJSON(w, status, resp)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Only the selected fields are returned, in query order, with aliases
+func TestGraphQLSelection(t *testing.T) {
+	resp := ExecuteGraphQL(GraphQLRequest{
+		Query: `query Q($n: String = "II") { one: dataset(name: $n) { n fit { slope rSquared } name } }`,
+	}, LoadAnscombeDatasets())
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+
+	raw, _ := json.Marshal(resp.Data)
+	got := string(raw)
+	if !strings.HasPrefix(got, `{"one":{"n":11,"fit":{"slope":`) || !strings.HasSuffix(got, `"name":"II"}}`) {
+		t.Errorf("unexpected response: %s", got)
+	}
+	if strings.Contains(got, "intercept") {
+		t.Errorf("unselected field leaked into response: %s", got)
+	}
+}
+
+// ✅ Test 2: Variables override defaults and diagnostics are queryable
+func TestGraphQLDiagnostics(t *testing.T) {
+	resp := ExecuteGraphQL(GraphQLRequest{
+		Query:     `query($n: String) { datasets(name: $n) { __typename perPointDiagnostics { index leverage cooksDistance } } }`,
+		Variables: map[string]any{"n": "IV"},
+	}, LoadAnscombeDatasets())
+	if len(resp.Errors) > 0 {
+		t.Fatalf("unexpected errors: %+v", resp.Errors)
+	}
+
+	raw, _ := json.Marshal(resp.Data)
+	if !strings.Contains(string(raw), `"__typename":"Dataset"`) || !strings.Contains(string(raw), `{"index":7,"leverage":1,"cooksDistance":null}`) {
+		t.Errorf("unexpected response: %s", raw)
+	}
+}
+
+// ✅ Test 3: Schema and syntax errors are reported without data
+func TestGraphQLErrors(t *testing.T) {
+	for _, query := range []string{
+		`{ datasets { bogus } }`,
+		`{ datasets }`,
+		`{ datasets { name { x } } }`,
+		`mutation { datasets { name } }`,
+		`{ datasets { ...f } }`,
+		`{ datasets { name }`,
+	} {
+		resp := ExecuteGraphQL(GraphQLRequest{Query: query}, LoadAnscombeDatasets())
+		if len(resp.Errors) == 0 || resp.Data != nil {
+			t.Errorf("expected error for %q, got %+v", query, resp)
+		}
+	}
+}
+
+// ✅ Test 4: Deeply nested selections are rejected before they can exhaust the stack
+func TestGraphQLDepthLimit(t *testing.T) {
+	for _, query := range []string{
+		strings.Repeat("{a", 8<<20),
+		strings.Repeat("{a", maxGraphQLDepth+1) + strings.Repeat("}", maxGraphQLDepth+1),
+	} {
+		resp := ExecuteGraphQL(GraphQLRequest{Query: query}, LoadAnscombeDatasets())
+		if len(resp.Errors) == 0 || !strings.Contains(resp.Errors[0].Message, "nested more than 32 deep") {
+			t.Errorf("expected a depth error for %.20q..., got %+v", query, resp.Errors)
+		}
+	}
+	// at the limit the query parses, and fails only on the schema
+	query := strings.Repeat("{a", maxGraphQLDepth) + strings.Repeat("}", maxGraphQLDepth)
+	if resp := ExecuteGraphQL(GraphQLRequest{Query: query}, LoadAnscombeDatasets()); len(resp.Errors) == 0 || strings.Contains(resp.Errors[0].Message, "nested") {
+		t.Errorf("unexpected errors %+v", resp.Errors)
+	}
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// DatasetGroup is the part of a grouped CSV sharing one key value
+type DatasetGroup struct {
+	Key  string
+	Data Dataset
+}
+
+// LoadCSVGroups splits a CSV stream into one dataset per value of groupColumn. The column is
+// named by its header or by a 1-based index; X and Y are the first two other columns. Groups are
+// returned in order of first appearance, and value parsing follows LoadCSVDataset.
+func LoadCSVGroups(r io.Reader, groupColumn string) ([]DatasetGroup, error) {
+	return LoadCSVGroupsWithLocale(r, groupColumn, NumberLocale{})
+}
+
+// LoadCSVGroupsWithLocale is LoadCSVGroups for a stream written in locale l
+func LoadCSVGroupsWithLocale(r io.Reader, groupColumn string, l NumberLocale) ([]DatasetGroup, error) {
+	reader := l.newCSVReader(r)
+
+	group := -1
+	if n, err := strconv.Atoi(groupColumn); err == nil && n >= 1 {
+		group = n - 1
+	}
+
+	var groups []DatasetGroup
+	var xAxis, yAxis Axis
+	index := map[string]int{}
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading CSV: %w", err)
+		}
+		line++
+
+		if line == 1 && group < 0 {
+			// the group column is named, so the first row must be the header
+			for i, name := range record {
+				if strings.EqualFold(strings.TrimSpace(name), groupColumn) {
+					group = i
+				}
+			}
+			if group < 0 {
+				return nil, fmt.Errorf("group column %q not found in header", groupColumn)
+			}
+			if names := valueFields(record, group); len(names) == 2 {
+				xAxis, yAxis = ParseAxis(names[0]), ParseAxis(names[1])
+			}
+			continue
+		}
+		if len(record) < 3 || group >= len(record) {
+			return nil, fmt.Errorf("line %d: expected the group column and 2 value columns, got %d columns", line, len(record))
+		}
+
+		fields := valueFields(record, group)
+		x, errX := l.ParseFloat(fields[0])
+		y, errY := l.ParseFloat(fields[1])
+		if errX != nil || errY != nil {
+			if line == 1 {
+				// header row of a file grouped by index
+				xAxis, yAxis = ParseAxis(fields[0]), ParseAxis(fields[1])
+				continue
+			}
+			return nil, fmt.Errorf("line %d: non-numeric value in %q", line, strings.Join(fields, string(reader.Comma)))
+		}
+
+		key := strings.TrimSpace(record[group])
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, DatasetGroup{Key: key, Data: Dataset{XAxis: xAxis, YAxis: yAxis}})
+		}
+		groups[i].Data.X = append(groups[i].Data.X, x)
+		groups[i].Data.Y = append(groups[i].Data.Y, y)
+	}
+
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no data rows found")
+	}
+	return groups, nil
+}
+
+// valueFields returns the first two fields of record other than the group column
+func valueFields(record []string, group int) []string {
+	fields := make([]string, 0, 2)
+	for i, field := range record {
+		if i != group && len(fields) < 2 {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// groupJobs loads each file grouped by column and returns a job per group. Jobs are named after
+// the group, prefixed with the file name when several files are given.
+func groupJobs(paths []string, column string, l NumberLocale) ([]AnalysisJob, error) {
+	var jobs []AnalysisJob
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		groups, err := LoadCSVGroupsWithLocale(f, column, l)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+
+		prefix := ""
+		if len(paths) > 1 {
+			prefix = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)) + "/"
+		}
+		for _, g := range groups {
+			jobs = append(jobs, AnalysisJob{Name: prefix + g.Key, Data: g.Data})
+		}
+	}
+	return jobs, nil
+}
+
+// PrintComparisonTable writes one row per fitted outcome so several datasets can be compared
+func PrintComparisonTable(w io.Writer, outcomes []AnalysisOutcome) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Dataset\tPoints\tSlope\tIntercept\tR-squared\t")
+	for _, o := range outcomes {
+		if o.Err != nil {
+			fmt.Fprintf(tw, "%s\t%d\terror: %v\t\t\t\n", o.Name, len(o.Data.X), o.Err)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.6f\t%.6f\t%.6f\t\n", o.Name, len(o.Data.X), o.Result.Slope, o.Result.Intercept, o.Result.RSquared)
+	}
+	tw.Flush()
+}
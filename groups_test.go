@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: A key column splits one CSV into datasets in order of first appearance
+func TestLoadCSVGroups(t *testing.T) {
+	input := "x,region,y\n1,north,2\n1,south,5\n2,north,4\n2,south,NA\n"
+	for _, column := range []string{"region", "Region", "2"} {
+		groups, err := LoadCSVGroups(strings.NewReader(input), column)
+		if err != nil || len(groups) != 2 || groups[0].Key != "north" || groups[1].Key != "south" {
+			t.Fatalf("%s: unexpected groups %+v (err %v)", column, groups, err)
+		}
+		if north := groups[0].Data; len(north.X) != 2 || north.X[1] != 2 || north.Y[1] != 4 {
+			t.Errorf("%s: unexpected north data %+v", column, north)
+		}
+	}
+
+	if _, err := LoadCSVGroups(strings.NewReader(input), "country"); err == nil {
+		t.Error("expected missing column error")
+	}
+	if _, err := LoadCSVGroups(strings.NewReader("g,x,y\na,1,foo\n"), "g"); err == nil {
+		t.Error("expected non-numeric value error")
+	}
+}
+
+// ✅ Test 2: Grouped jobs are named per file and group and compared in one table
+func TestGroupJobsComparison(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.csv")
+	b := filepath.Join(dir, "b.csv")
+	os.WriteFile(a, []byte("g,x,y\nk1,1,1\nk1,2,2\nk2,1,3\nk2,2,1\n"), 0o644)
+	os.WriteFile(b, []byte("g,x,y\nk1,1,0\nk1,2,4\n"), 0o644)
+
+	jobs, err := groupJobs([]string{a, b}, "g", NumberLocale{})
+	if err != nil || len(jobs) != 3 || jobs[0].Name != "a/k1" || jobs[2].Name != "b/k1" {
+		t.Fatalf("unexpected jobs %+v (err %v)", jobs, err)
+	}
+
+	var buf bytes.Buffer
+	PrintComparisonTable(&buf, AnalyzeAll(jobs, 1))
+	out := buf.String()
+	for _, want := range []string{"a/k2", "-2.000000", "4.000000"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("comparison table missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// BinRule chooses the number of histogram bins from the data
+type BinRule string
+
+const (
+	BinRuleFreedmanDiaconis BinRule = "fd"      // width 2 IQR n^(-1/3); robust to outliers
+	BinRuleSturges          BinRule = "sturges" // ceil(log2 n) + 1 bins; assumes roughly normal data
+)
+
+// Histogram counts values in equal-width bins. Bin k covers [Edges[k], Edges[k+1]), except the last,
+// which also includes its upper edge.
+type Histogram struct {
+	Edges  []float64 `json:"edges"`
+	Counts []int     `json:"counts"`
+}
+
+// HistogramBins returns the number of bins rule gives for values, ignoring NaN and Inf.
+// Freedman–Diaconis falls back to Sturges when the interquartile range is zero.
+func HistogramBins(values []float64, rule BinRule) (int, error) {
+	sorted := sortedFinite(values)
+	n := len(sorted)
+	if n == 0 {
+		return 0, fmt.Errorf("no finite values")
+	}
+	sturges := int(math.Ceil(math.Log2(float64(n)))) + 1
+	switch rule {
+	case BinRuleSturges:
+		return sturges, nil
+	case BinRuleFreedmanDiaconis, "":
+		iqr := quantileSorted(sorted, 0.75) - quantileSorted(sorted, 0.25)
+		span := sorted[n-1] - sorted[0]
+		if iqr == 0 || span == 0 {
+			return sturges, nil
+		}
+		width := 2 * iqr / math.Cbrt(float64(n))
+		return max(1, int(math.Ceil(span/width))), nil
+	}
+	return 0, fmt.Errorf("unknown bin rule %q (use fd or sturges)", rule)
+}
+
+// NewHistogram bins the finite values over their range with the number of bins rule chooses.
+// The empty rule means Freedman–Diaconis.
+func NewHistogram(values []float64, rule BinRule) (Histogram, error) {
+	bins, err := HistogramBins(values, rule)
+	if err != nil {
+		return Histogram{}, err
+	}
+	return HistogramWithBins(values, bins)
+}
+
+// HistogramWithBins bins the finite values into the given number of equal-width bins over their
+// range. A constant sample gets a single bin of width 1 centred on the value.
+func HistogramWithBins(values []float64, bins int) (Histogram, error) {
+	if bins < 1 {
+		return Histogram{}, fmt.Errorf("need at least one bin, got %d", bins)
+	}
+	sorted := sortedFinite(values)
+	if len(sorted) == 0 {
+		return Histogram{}, fmt.Errorf("no finite values")
+	}
+	lo, hi := sorted[0], sorted[len(sorted)-1]
+	if lo == hi {
+		return Histogram{Edges: []float64{lo - 0.5, lo + 0.5}, Counts: []int{len(sorted)}}, nil
+	}
+
+	h := Histogram{Edges: make([]float64, bins+1), Counts: make([]int, bins)}
+	width := (hi - lo) / float64(bins)
+	for k := range h.Edges {
+		h.Edges[k] = lo + float64(k)*width
+	}
+	h.Edges[bins] = hi
+	for _, v := range sorted {
+		k := min(bins-1, int((v-lo)/width))
+		h.Counts[k]++
+	}
+	return h, nil
+}
+
+// N returns the number of values counted
+func (h Histogram) N() int {
+	n := 0
+	for _, c := range h.Counts {
+		n += c
+	}
+	return n
+}
+
+// Densities returns each bin's count divided by n times its width, so the bars integrate to one
+// and can be drawn on the same scale as a density estimate
+func (h Histogram) Densities() []float64 {
+	n := float64(h.N())
+	out := make([]float64, len(h.Counts))
+	for k, c := range h.Counts {
+		out[k] = float64(c) / (n * (h.Edges[k+1] - h.Edges[k]))
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Bin counts match R's nclass.FD and nclass.Sturges
+func TestHistogramBins(t *testing.T) {
+	values := make([]float64, 100)
+	for i := range values {
+		values[i] = float64(i + 1)
+	}
+	if k, err := HistogramBins(values, BinRuleFreedmanDiaconis); err != nil || k != 5 {
+		t.Errorf("Freedman–Diaconis: %d bins (%v), want 5", k, err)
+	}
+	if k, _ := HistogramBins(values, BinRuleSturges); k != 8 {
+		t.Errorf("Sturges: %d bins, want 8", k)
+	}
+	// zero IQR falls back to Sturges
+	if k, _ := HistogramBins([]float64{1, 1, 1, 1, 1, 1, 1, 9}, BinRuleFreedmanDiaconis); k != 4 {
+		t.Errorf("zero IQR: %d bins, want 4", k)
+	}
+	if _, err := HistogramBins(values, "scott"); err == nil {
+		t.Error("expected an error for an unknown rule")
+	}
+}
+
+// ✅ Test 2: Every finite value lands in one bin and densities integrate to one
+func TestHistogramCounts(t *testing.T) {
+	h, err := HistogramWithBins([]float64{0, 1, 2, 2.5, 3, 4, math.NaN()}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{1, 1, 2, 2}
+	for k := range want {
+		if h.Counts[k] != want[k] {
+			t.Fatalf("counts %v, want %v", h.Counts, want)
+		}
+	}
+	if h.N() != 6 || h.Edges[0] != 0 || h.Edges[4] != 4 {
+		t.Errorf("unexpected histogram %+v", h)
+	}
+	var area float64
+	for k, d := range h.Densities() {
+		area += d * (h.Edges[k+1] - h.Edges[k])
+	}
+	if math.Abs(area-1) > 1e-12 {
+		t.Errorf("densities integrate to %v", area)
+	}
+
+	constant, err := HistogramWithBins([]float64{5, 5}, 3)
+	if err != nil || len(constant.Counts) != 1 || constant.Counts[0] != 2 {
+		t.Errorf("constant sample: %+v (%v)", constant, err)
+	}
+	if _, err := NewHistogram([]float64{math.NaN()}, ""); err == nil {
+		t.Error("expected an error without finite values")
+	}
+}
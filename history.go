@@ -0,0 +1,316 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// HistoryStore keeps every recorded analysis run in an SQLite database, so fits of the same data
+// can be followed across runs, engine changes and data updates. A run holds its manifest (options,
+// build and engine) and one row per dataset with the coefficients in columns, for querying, and
+// the full DatasetRecord, diagnostics included, as JSON. A HistoryStore is safe for concurrent
+// use, and several processes may record into the same database.
+type HistoryStore struct {
+	db *sql.DB
+}
+
+// HistoryRun is one recorded run
+type HistoryRun struct {
+	ID       int64           `json:"id"`
+	Started  time.Time       `json:"started"`
+	Manifest Manifest        `json:"manifest"`
+	Datasets []DatasetRecord `json:"datasets"`
+}
+
+const historySchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	started  TEXT NOT NULL,
+	manifest TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS fits (
+	run_id      INTEGER NOT NULL REFERENCES runs(id),
+	position    INTEGER NOT NULL,
+	dataset     TEXT NOT NULL,
+	fingerprint TEXT,
+	slope       REAL,
+	intercept   REAL,
+	r_squared   REAL,
+	error       TEXT,
+	record      TEXT NOT NULL,
+	PRIMARY KEY (run_id, position)
+);
+CREATE INDEX IF NOT EXISTS fits_dataset ON fits(dataset, run_id);
+`
+
+// OpenHistory opens the history database at path, creating it if needed
+func OpenHistory(path string) (*HistoryStore, error) {
+	// an absolute path keeps the URI free of an authority, and url.URL escapes '?', '#' and '%'
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	// every pooled connection waits up to 5s for another writer's lock instead of failing at once
+	dsn := url.URL{Scheme: "file", Path: filepath.ToSlash(abs), RawQuery: "_pragma=busy_timeout(5000)"}
+	db, err := sql.Open("sqlite", dsn.String())
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(historySchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening history %s: %w", path, err)
+	}
+	return &HistoryStore{db: db}, nil
+}
+
+// Close closes the database
+func (s *HistoryStore) Close() error {
+	return s.db.Close()
+}
+
+// Record stores a run and returns its id. The start time is the manifest's creation time.
+func (s *HistoryStore) Record(manifest Manifest, rec RunRecord) (int64, error) {
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return 0, err
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	res, err := tx.Exec("INSERT INTO runs (started, manifest) VALUES (?, ?)", manifest.Created.UTC().Format(time.RFC3339Nano), string(manifestJSON))
+	if err != nil {
+		return 0, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	for i, d := range rec.Datasets {
+		record, err := json.Marshal(d)
+		if err != nil {
+			return 0, fmt.Errorf("recording dataset %s: %w", d.Name, err)
+		}
+		// failed fits have no coefficients, so they are stored as NULL rather than zero
+		var slope, intercept, r2 any
+		if d.Error == "" {
+			slope, intercept, r2 = d.Slope, d.Intercept, d.RSquared
+		}
+		if _, err := tx.Exec("INSERT INTO fits (run_id, position, dataset, fingerprint, slope, intercept, r_squared, error, record) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			id, i, d.Name, d.Fingerprint, slope, intercept, r2, d.Error, string(record)); err != nil {
+			return 0, err
+		}
+	}
+	return id, tx.Commit()
+}
+
+// Runs returns the most recent runs, newest first, without their datasets. limit <= 0 returns all.
+func (s *HistoryStore) Runs(limit int) ([]HistoryRun, error) {
+	query := "SELECT id, started, manifest FROM runs ORDER BY id DESC"
+	if limit > 0 {
+		query += " LIMIT " + strconv.Itoa(limit)
+	}
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var runs []HistoryRun
+	for rows.Next() {
+		run, err := scanHistoryRun(rows)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// Run returns a run with its datasets in their original order
+func (s *HistoryStore) Run(id int64) (HistoryRun, error) {
+	run, err := scanHistoryRun(s.db.QueryRow("SELECT id, started, manifest FROM runs WHERE id = ?", id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return HistoryRun{}, fmt.Errorf("no run %d in the history", id)
+	}
+	if err != nil {
+		return HistoryRun{}, err
+	}
+	rows, err := s.db.Query("SELECT record FROM fits WHERE run_id = ? ORDER BY position", id)
+	if err != nil {
+		return HistoryRun{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var record string
+		var d DatasetRecord
+		if err := rows.Scan(&record); err != nil {
+			return HistoryRun{}, err
+		}
+		if err := json.Unmarshal([]byte(record), &d); err != nil {
+			return HistoryRun{}, fmt.Errorf("run %d: %w", id, err)
+		}
+		run.Datasets = append(run.Datasets, d)
+	}
+	return run, rows.Err()
+}
+
+// DatasetCount returns how many datasets a run recorded
+func (s *HistoryStore) DatasetCount(id int64) (int, error) {
+	var n int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM fits WHERE run_id = ?", id).Scan(&n)
+	return n, err
+}
+
+func scanHistoryRun(row interface{ Scan(...any) error }) (HistoryRun, error) {
+	var run HistoryRun
+	var started, manifest string
+	if err := row.Scan(&run.ID, &started, &manifest); err != nil {
+		return HistoryRun{}, err
+	}
+	var err error
+	if run.Started, err = time.Parse(time.RFC3339Nano, started); err != nil {
+		return HistoryRun{}, fmt.Errorf("run %d: %w", run.ID, err)
+	}
+	if err := json.Unmarshal([]byte(manifest), &run.Manifest); err != nil {
+		return HistoryRun{}, fmt.Errorf("run %d: %w", run.ID, err)
+	}
+	return run, nil
+}
+
+// formatOptions writes run options as sorted -name=value pairs
+func formatOptions(options map[string]string) string {
+	parts := make([]string, 0, len(options))
+	for name, value := range options {
+		parts = append(parts, "-"+name+"="+value)
+	}
+	slices.Sort(parts)
+	return strings.Join(parts, " ")
+}
+
+func printHistoryRuns(w io.Writer, s *HistoryStore, runs []HistoryRun) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Run\tStarted\tEngine\tDatasets\tOptions\t")
+	for _, run := range runs {
+		n, err := s.DatasetCount(run.ID)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t\n", run.ID, run.Started.Local().Format(time.RFC3339), run.Manifest.Engine, n, formatOptions(run.Manifest.Options))
+	}
+	return tw.Flush()
+}
+
+func printHistoryRun(w io.Writer, run HistoryRun) {
+	fmt.Fprintf(w, "Run %d, started %s\n", run.ID, run.Started.Local().Format(time.RFC3339))
+	fmt.Fprintf(w, "Build:   %s %s (%s), engine %s\n", run.Manifest.Module, run.Manifest.Version, run.Manifest.GoVersion, run.Manifest.Engine)
+	if len(run.Manifest.Options) > 0 {
+		fmt.Fprintf(w, "Options: %s\n", formatOptions(run.Manifest.Options))
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Dataset\tN\tSlope\tIntercept\tR²\tFingerprint\t")
+	for _, d := range run.Datasets {
+		if d.Error != "" {
+			fmt.Fprintf(tw, "%s\t%d\terror: %s\t\t\t\t\n", d.Name, d.Points, d.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%.6f\t%.6f\t%.6f\t%s\t\n", d.Name, d.Points, d.Slope, d.Intercept, d.RSquared, d.Fingerprint)
+	}
+	tw.Flush()
+}
+
+// defaultHistoryPath is where runs are recorded when no database is named
+const defaultHistoryPath = "history.db"
+
+// runHistory implements the history subcommand for listing and showing recorded runs
+func runHistory(args []string) error {
+	flags := flag.NewFlagSet("history", flag.ContinueOnError)
+	dbPath := flags.String("db", defaultHistoryPath, "history database written by the regression command's -history flag")
+	limit := flags.Int("n", 20, "with list, number of most recent runs to show (0 for all)")
+	asJSON := flags.Bool("json", false, "with show, print the run as JSON")
+	th := DefaultTrendThresholds()
+	flags.Float64Var(&th.SlopeRel, "slope-tol", th.SlopeRel, "with trend, relative change of slope between runs reported as a shift")
+	flags.Float64Var(&th.RSquaredAbs, "r2-tol", th.RSquaredAbs, "with trend, change of R-squared between runs reported as a shift")
+	htmlPath := flags.String("html", "", "with trend, also write the trend with plots to this HTML file")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: history [-db FILE] list | show [-json] ID | trend [-html FILE] DATASET\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) > 0 {
+		// allow flags after the command, as in "history show -json 3"
+		if err := flags.Parse(args[1:]); err != nil {
+			return err
+		}
+		args = append(args[:1], flags.Args()...)
+	}
+	if _, err := os.Stat(*dbPath); err != nil {
+		return fmt.Errorf("no history at %s: %w", *dbPath, err)
+	}
+	s, err := OpenHistory(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		runs, err := s.Runs(*limit)
+		if err != nil {
+			return err
+		}
+		return printHistoryRuns(os.Stdout, s, runs)
+	case len(args) == 2 && args[0] == "show":
+		id, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid run id %q", args[1])
+		}
+		run, err := s.Run(id)
+		if err != nil {
+			return err
+		}
+		if *asJSON {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(run)
+		}
+		printHistoryRun(os.Stdout, run)
+	case len(args) == 2 && args[0] == "trend":
+		points, err := s.Series(args[1])
+		if err != nil {
+			return err
+		}
+		if len(points) == 0 {
+			return fmt.Errorf("no dataset named %s in %s", args[1], *dbPath)
+		}
+		DetectTrendChanges(points, th)
+		printTrend(os.Stdout, args[1], points)
+		if *htmlPath != "" {
+			if err := WriteTrendHTMLFile(*htmlPath, args[1], points, th); err != nil {
+				return fmt.Errorf("writing trend: %w", err)
+			}
+			fmt.Printf("\nTrend written to %s\n", *htmlPath)
+		}
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown history command %q", strings.Join(args, " "))
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestHistory(t *testing.T) *HistoryStore {
+	t.Helper()
+	s, err := OpenHistory(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// ✅ Test 1: A recorded run reads back with its manifest and datasets in order
+func TestHistoryRecordAndRead(t *testing.T) {
+	s := openTestHistory(t)
+	jobs := append(anscombeJobs(), AnalysisJob{Name: "empty", Data: Dataset{}})
+	outcomes := AnalyzeAll(jobs, 2)
+	manifest := NewManifest(analysisEngine)
+	manifest.Options = map[string]string{"advise": "true"}
+	rec := NewRunRecord(outcomes, RunRecordOptions{Diagnostics: true})
+
+	id, err := s.Record(manifest, rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := s.Run(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !run.Started.Equal(manifest.Created) || run.Manifest.Options["advise"] != "true" || run.Manifest.Engine != analysisEngine {
+		t.Errorf("unexpected run header %+v", run)
+	}
+	if len(run.Datasets) != 5 {
+		t.Fatalf("expected 5 datasets, got %d", len(run.Datasets))
+	}
+	for i, d := range run.Datasets[:4] {
+		want := rec.Datasets[i]
+		if d.Name != want.Name || d.Slope != want.Slope || d.Fingerprint != want.Fingerprint || d.Residuals == nil {
+			t.Errorf("dataset %d: expected %+v, got %+v", i, want, d)
+		}
+	}
+	if run.Datasets[4].Error == "" {
+		t.Errorf("expected the empty dataset's error to be kept")
+	}
+
+	// failed fits have no coefficients in the queryable columns
+	var nulls int
+	if err := s.db.QueryRow("SELECT COUNT(*) FROM fits WHERE slope IS NULL").Scan(&nulls); err != nil || nulls != 1 {
+		t.Errorf("expected one fit without a slope, got %d (%v)", nulls, err)
+	}
+}
+
+// ✅ Test 2: Runs are listed newest first and a missing run is an error
+func TestHistoryRuns(t *testing.T) {
+	s := openTestHistory(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		manifest := NewManifest(analysisEngine)
+		manifest.Created = start.Add(time.Duration(i) * time.Hour)
+		if _, err := s.Record(manifest, RunRecord{Datasets: []DatasetRecord{{Name: "I", Slope: float64(i)}}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	runs, err := s.Runs(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != 3 || runs[1].ID != 2 || !runs[0].Started.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("unexpected runs %+v", runs)
+	}
+	if all, _ := s.Runs(0); len(all) != 3 {
+		t.Errorf("expected all 3 runs, got %d", len(all))
+	}
+	if _, err := s.Run(42); err == nil || !strings.Contains(err.Error(), "no run 42") {
+		t.Errorf("expected a missing run error, got %v", err)
+	}
+}
+
+// ✅ Test 3: A history database can be reopened and appended to
+func TestHistoryReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	for i := 0; i < 2; i++ {
+		s, err := OpenHistory(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Record(NewManifest(analysisEngine), RunRecord{}); err != nil {
+			t.Fatal(err)
+		}
+		s.Close()
+	}
+	s, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if runs, err := s.Runs(0); err != nil || len(runs) != 2 {
+		t.Errorf("expected 2 runs after reopening, got %d (%v)", len(runs), err)
+	}
+}
+
+// ✅ Test 4: Paths with URI metacharacters open the file they name
+func TestHistoryPathEscaping(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs #1 50%")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "history?.db")
+	s, err := OpenHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Record(NewManifest(analysisEngine), RunRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the database at %q: %v", path, err)
+	}
+}
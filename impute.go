@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// ImputeStrategy selects how missing (NaN/Inf) values are handled before fitting
+type ImputeStrategy string
+
+const (
+	ImputeDrop        ImputeStrategy = ""            // drop incomplete pairs (the default behaviour of every engine)
+	ImputeMean        ImputeStrategy = "mean"        // replace a missing value with the mean of its variable
+	ImputeMedian      ImputeStrategy = "median"      // replace a missing value with the median of its variable
+	ImputeInterpolate ImputeStrategy = "interpolate" // interpolate a missing Y linearly between its neighbours along X
+)
+
+// ImputeMissing returns a copy of ds with missing values filled in and the indexes of the points
+// that were changed. With ImputeInterpolate, missing Y values at either end of the X range take the
+// nearest observed value, and points with a missing X are left to be dropped since they have no
+// position along X.
+func ImputeMissing(ds Dataset, strategy ImputeStrategy) (Dataset, []int, error) {
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, nil, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+
+	out := Dataset{X: append([]float64(nil), ds.X...), Y: append([]float64(nil), ds.Y...), XAxis: ds.XAxis, YAxis: ds.YAxis}
+	var imputed []int
+
+	switch strategy {
+	case ImputeDrop:
+		return out, nil, nil
+	case ImputeMean, ImputeMedian:
+		fillX, okX := imputeCenter(strategy, ds.X)
+		fillY, okY := imputeCenter(strategy, ds.Y)
+		for i := range out.X {
+			changed := false
+			if isMissing(out.X[i]) && okX {
+				out.X[i], changed = fillX, true
+			}
+			if isMissing(out.Y[i]) && okY {
+				out.Y[i], changed = fillY, true
+			}
+			if changed {
+				imputed = append(imputed, i)
+			}
+		}
+	case ImputeInterpolate:
+		imputed = interpolateAlongX(out)
+	default:
+		return Dataset{}, nil, fmt.Errorf("unknown imputation strategy %q (use mean, median or interpolate)", strategy)
+	}
+	return out, imputed, nil
+}
+
+func isMissing(v float64) bool {
+	return math.IsNaN(v) || math.IsInf(v, 0)
+}
+
+// imputeCenter returns the mean or median of the observed values; ok is false when there are none
+func imputeCenter(strategy ImputeStrategy, values []float64) (float64, bool) {
+	observed := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !isMissing(v) {
+			observed = append(observed, v)
+		}
+	}
+	if len(observed) == 0 {
+		return 0, false
+	}
+
+	if strategy == ImputeMedian {
+		sort.Float64s(observed)
+		return quantileSorted(observed, 0.5), true
+	}
+	var acc OnlineRegression
+	for _, v := range observed {
+		acc.Add(v, 0)
+	}
+	return acc.meanX, true
+}
+
+// interpolateAlongX fills missing Y values in place and returns the changed indexes in ascending order
+func interpolateAlongX(ds Dataset) []int {
+	order := make([]int, 0, len(ds.X))
+	for i, x := range ds.X {
+		if !isMissing(x) {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ds.X[order[a]] < ds.X[order[b]] })
+
+	// observed holds the positions in order whose Y is known, before any filling
+	var observed []int
+	for pos, i := range order {
+		if !isMissing(ds.Y[i]) {
+			observed = append(observed, pos)
+		}
+	}
+	if len(observed) == 0 {
+		return nil
+	}
+
+	var imputed []int
+	next := 0 // index into observed of the first known point at or after pos
+	for pos, i := range order {
+		for next < len(observed) && observed[next] < pos {
+			next++
+		}
+		if !isMissing(ds.Y[i]) {
+			continue
+		}
+
+		switch {
+		case next == 0:
+			ds.Y[i] = ds.Y[order[observed[0]]]
+		case next == len(observed):
+			ds.Y[i] = ds.Y[order[observed[len(observed)-1]]]
+		default:
+			lo, hi := order[observed[next-1]], order[observed[next]]
+			if ds.X[hi] == ds.X[lo] {
+				ds.Y[i] = (ds.Y[lo] + ds.Y[hi]) / 2
+			} else {
+				t := (ds.X[i] - ds.X[lo]) / (ds.X[hi] - ds.X[lo])
+				ds.Y[i] = ds.Y[lo] + t*(ds.Y[hi]-ds.Y[lo])
+			}
+		}
+		imputed = append(imputed, i)
+	}
+
+	sort.Ints(imputed)
+	return imputed
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Mean, median and interpolation fill the right values and record the indexes
+func TestImputeMissing(t *testing.T) {
+	nan := math.NaN()
+	ds := Dataset{
+		X: []float64{1, 2, 3, nan, 5, 10},
+		Y: []float64{nan, 4, nan, 8, 10, 20},
+	}
+
+	mean, idx, err := ImputeMissing(ds, ImputeMean)
+	// mean X of {1,2,3,5,10} = 4.2, mean Y of {4,8,10,20} = 10.5
+	if err != nil || !slices.Equal(idx, []int{0, 2, 3}) || mean.Y[0] != 10.5 || mean.Y[2] != 10.5 || mean.X[3] != 4.2 {
+		t.Errorf("mean: %+v %v %v", mean, idx, err)
+	}
+
+	median, _, _ := ImputeMissing(ds, ImputeMedian)
+	if median.Y[0] != 9 || median.X[3] != 3 {
+		t.Errorf("median: %+v", median)
+	}
+
+	interp, idx, _ := ImputeMissing(ds, ImputeInterpolate)
+	// index 0 is before the first observed Y (takes 4); index 2 lies between x=2 (4) and x=5 (10)
+	if !slices.Equal(idx, []int{0, 2}) || interp.Y[0] != 4 || interp.Y[2] != 6 || !math.IsNaN(interp.X[3]) {
+		t.Errorf("interpolate: %+v %v", interp, idx)
+	}
+	if !math.IsNaN(ds.Y[0]) {
+		t.Error("input dataset must not be modified")
+	}
+
+	if _, _, err := ImputeMissing(ds, "knn"); err == nil {
+		t.Error("expected unknown strategy error")
+	}
+}
+
+// ✅ Test 2: Batch jobs impute per dataset and report the imputed indexes
+func TestAnalyzeAllImpute(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 3, 4}, Y: []float64{2, math.NaN(), 6, 8}}
+	outcomes := AnalyzeAll([]AnalysisJob{
+		{Name: "dropped", Data: data},
+		{Name: "interpolated", Data: data, Impute: ImputeInterpolate},
+	}, 1)
+
+	if outcomes[0].Err != nil || outcomes[0].Result.Imputed != nil {
+		t.Errorf("dropping should not impute: %+v", outcomes[0])
+	}
+	if r := outcomes[1].Result; outcomes[1].Err != nil || !slices.Equal(r.Imputed, []int{1}) || math.Abs(r.Slope-2) > 1e-12 {
+		t.Errorf("interpolation: %+v", outcomes[1])
+	}
+}
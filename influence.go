@@ -0,0 +1,63 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"text/tabwriter"
+)
+
+// InfluenceRanking returns the dataset's point diagnostics ordered by how far removing each point
+// would move the slope, most influential first. Points whose removal leaves the slope undefined
+// (leverage 1) come before all others.
+func InfluenceRanking(d Dataset, slope, intercept float64) []PointDiagnostic {
+	diags := DatasetDiagnostics(d, slope, intercept)
+	slices.SortStableFunc(diags, func(a, b PointDiagnostic) int {
+		undefinedA, undefinedB := math.IsNaN(a.SlopeChange), math.IsNaN(b.SlopeChange)
+		if undefinedA != undefinedB {
+			if undefinedA {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(math.Abs(b.SlopeChange), math.Abs(a.SlopeChange))
+	})
+	return diags
+}
+
+// printInfluence writes the top most influential points of a dataset as a table
+func printInfluence(w io.Writer, data Dataset, result RegressionResult, top int) {
+	ranking := InfluenceRanking(data, result.Slope, result.Intercept)
+	if len(ranking) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  Influence (top %d of %d points by effect on the slope):\n", min(top, len(ranking)), len(ranking))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "    Point\tx\ty\tResidual\tLeverage\tCook's D\tSlope without it\tChange\t")
+	for _, d := range ranking[:min(top, len(ranking))] {
+		name := fmt.Sprint(d.Index + 1)
+		if d.Label != "" {
+			name = d.Label
+		}
+		without, change := "undefined", "-"
+		if !math.IsNaN(d.SlopeChange) {
+			without = fmt.Sprintf("%.6f", result.Slope+d.SlopeChange)
+			change = fmt.Sprintf("%+.6f", d.SlopeChange)
+			if result.Slope != 0 {
+				change += fmt.Sprintf(" (%+.1f%%)", 100*d.SlopeChange/math.Abs(result.Slope))
+			}
+		}
+		fmt.Fprintf(tw, "    %s\t%g\t%g\t%.4f\t%.3f\t%s\t%s\t%s\t\n", name, d.X, d.Y, d.Residual, d.Leverage, formatDiagnostic(d.CooksDistance), without, change)
+	}
+	tw.Flush()
+}
+
+// formatDiagnostic prints an undefined diagnostic as a dash
+func formatDiagnostic(v float64) string {
+	if math.IsNaN(v) {
+		return "-"
+	}
+	return fmt.Sprintf("%.3f", v)
+}
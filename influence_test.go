@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The slope change matches refitting without each point
+func TestSlopeChangeLeaveOneOut(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		slope, intercept, _ := ManualRegression(ds.X, ds.Y)
+		for _, d := range PointDiagnostics(ds.X, ds.Y, slope, intercept) {
+			i := d.Index
+			x := append(append([]float64(nil), ds.X[:i]...), ds.X[i+1:]...)
+			y := append(append([]float64(nil), ds.Y[:i]...), ds.Y[i+1:]...)
+			if d.Leverage >= 1-1e-12 {
+				if !math.IsNaN(d.SlopeChange) {
+					t.Errorf("dataset %s point %d: leverage 1 should leave the change undefined", name, i)
+				}
+				continue
+			}
+			without, _, _ := ManualRegression(x, y)
+			if math.Abs(slope+d.SlopeChange-without) > 1e-12 {
+				t.Errorf("dataset %s point %d: change %v, refit %v", name, i, d.SlopeChange, without-slope)
+			}
+		}
+	}
+}
+
+// ✅ Test 2: The ranking puts IV's lone point first and III's outlier first
+func TestInfluenceRanking(t *testing.T) {
+	sets := LoadAnscombeDatasets()
+	for name, wantX := range map[string]float64{"III": 13, "IV": 19} {
+		ds := sets[name]
+		slope, intercept, _ := ManualRegression(ds.X, ds.Y)
+		ranking := InfluenceRanking(ds, slope, intercept)
+		if len(ranking) != len(ds.X) || ranking[0].X != wantX {
+			t.Errorf("dataset %s: first point %+v", name, ranking[0])
+		}
+		for i := 2; i < len(ranking); i++ {
+			if math.Abs(ranking[i].SlopeChange) > math.Abs(ranking[i-1].SlopeChange) {
+				t.Errorf("dataset %s: ranking out of order at %d", name, i)
+			}
+		}
+	}
+
+	// an undefined change encodes as null
+	ds := sets["IV"]
+	slope, intercept, _ := ManualRegression(ds.X, ds.Y)
+	data, err := json.Marshal(InfluenceRanking(ds, slope, intercept)[0])
+	if err != nil || !strings.Contains(string(data), `"slopeChange":null`) {
+		t.Errorf("got %s, %v", data, err)
+	}
+}
+
+// ✅ Test 3: The text report, HTML report and run record carry the influence table
+func TestInfluenceOutputs(t *testing.T) {
+	ds := LoadAnscombeDatasets()["IV"]
+	slope, intercept, r2 := ManualRegression(ds.X, ds.Y)
+	result := RegressionResult{Slope: slope, Intercept: intercept, RSquared: r2}
+
+	var text bytes.Buffer
+	printInfluence(&text, ds, result, 2)
+	if out := text.String(); !strings.Contains(out, "top 2 of 11") || !strings.Contains(out, "undefined") || strings.Count(out, "\n") != 4 {
+		t.Errorf("text report:\n%s", out)
+	}
+
+	var page bytes.Buffer
+	if err := WriteHTMLReport(&page, "IV", []HTMLReportDataset{{Name: "IV", Data: ds, Result: result}}, HTMLReportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if out := page.String(); !strings.Contains(out, "the fit hinges on this point") || strings.Count(out, "<tr><td>") < reportInfluencePoints {
+		t.Error("HTML report should list the influential points")
+	}
+
+	rec := NewRunRecord([]AnalysisOutcome{{Name: "IV", Data: ds, Result: result}}, RunRecordOptions{Influence: 3})
+	if got := rec.Datasets[0].Influence; len(got) != 3 || got[0].X != 19 {
+		t.Errorf("run record influence %+v", got)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// VarianceFunction is how IRWLSRegression models the residual variance
+type VarianceFunction string
+
+const (
+	// VarianceExponential models log σ² as linear in X: σ² = exp(a + b·x)
+	VarianceExponential VarianceFunction = "exp"
+	// VariancePower models σ² as a power of the fitted value: σ² = exp(a)·|ŷ|^b
+	VariancePower VarianceFunction = "power"
+)
+
+// IRWLSOptions control IRWLSRegression. Zero values select the defaults.
+type IRWLSOptions struct {
+	Variance      VarianceFunction // default VarianceExponential
+	MaxIterations int              // default 50
+	Tolerance     float64          // relative change in slope and intercept that ends the loop; default 1e-8
+}
+
+// IRWLSFit is a line fitted with estimated weights. The standard errors assume the variance
+// function is right; OLSSlopeSE and OLSInterceptSE are the usual least squares ones, which
+// heteroscedasticity makes unreliable, for comparison.
+type IRWLSFit struct {
+	Variance    VarianceFunction `json:"variance"`
+	Slope       float64          `json:"slope"`
+	Intercept   float64          `json:"intercept"`
+	RSquared    float64          `json:"rSquared"`
+	SlopeSE     float64          `json:"slopeSE"`
+	InterceptSE float64          `json:"interceptSE"`
+	// VarianceParams are a and b of the variance function
+	VarianceParams [2]float64 `json:"varianceParams"`
+	OLSSlope       float64    `json:"olsSlope"`
+	OLSIntercept   float64    `json:"olsIntercept"`
+	OLSSlopeSE     float64    `json:"olsSlopeSE"`
+	OLSInterceptSE float64    `json:"olsInterceptSE"`
+	// Weights are the final weights of the complete pairs, scaled to average 1
+	Weights    []float64 `json:"weights"`
+	Iterations int       `json:"iterations"`
+	Converged  bool      `json:"converged"`
+}
+
+// IRWLSRegression fits a line to heteroscedastic data without user-supplied weights. Starting from
+// least squares, it alternates between fitting the variance function to the squared residuals
+// (see fitVarianceFunction) and refitting the line by WeightedRegression with weights 1/σ², until
+// the line stops moving. Pairs with NaN/Inf values are dropped; at least four complete pairs with spread in X are needed.
+func IRWLSRegression(x, y []float64, opts IRWLSOptions) (IRWLSFit, error) {
+	if opts.Variance == "" {
+		opts.Variance = VarianceExponential
+	}
+	if opts.Variance != VarianceExponential && opts.Variance != VariancePower {
+		return IRWLSFit{}, fmt.Errorf("unknown variance function %q (use exp or power)", opts.Variance)
+	}
+	if opts.MaxIterations <= 0 {
+		opts.MaxIterations = 50
+	}
+	if opts.Tolerance <= 0 {
+		opts.Tolerance = 1e-8
+	}
+	x, y, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return IRWLSFit{}, err
+	}
+	n := len(x)
+	if n < 4 {
+		return IRWLSFit{}, fmt.Errorf("need at least four points to estimate a variance function, have %d", n)
+	}
+	if _, vx := meanVariance(x); vx == 0 {
+		return IRWLSFit{}, fmt.Errorf("X is constant")
+	}
+
+	fit := IRWLSFit{Variance: opts.Variance}
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 1
+	}
+	fit.OLSSlope, fit.OLSIntercept, _, _ = WeightedRegression(x, y, w)
+	fit.OLSSlopeSE, fit.OLSInterceptSE = weightedLineSE(x, y, w, fit.OLSSlope, fit.OLSIntercept)
+
+	slope, intercept := fit.OLSSlope, fit.OLSIntercept
+	z := make([]float64, n)
+	r2 := make([]float64, n)
+	for fit.Iterations < opts.MaxIterations {
+		fit.Iterations++
+		for i := range x {
+			r := y[i] - (intercept + slope*x[i])
+			r2[i] = r * r
+			z[i] = x[i]
+			if opts.Variance == VariancePower {
+				z[i] = math.Log(math.Max(math.Abs(intercept+slope*x[i]), math.SmallestNonzeroFloat64))
+			}
+		}
+		a, b, ok := fitVarianceFunction(z, r2)
+		if !ok {
+			// the line is exact, so there is no variance left to model
+			fit.Converged = true
+			break
+		}
+		fit.VarianceParams = [2]float64{a, b}
+
+		var sumW float64
+		for i := range w {
+			w[i] = math.Exp(-(a + b*z[i]))
+			sumW += w[i]
+		}
+		if sumW == 0 || math.IsInf(sumW, 0) || math.IsNaN(sumW) {
+			return IRWLSFit{}, fmt.Errorf("variance function diverged after %d iterations", fit.Iterations)
+		}
+		for i := range w {
+			w[i] *= float64(n) / sumW
+		}
+
+		nextSlope, nextIntercept, _, err := WeightedRegression(x, y, w)
+		if err != nil {
+			return IRWLSFit{}, err
+		}
+		moved := math.Max(relChange(nextSlope, slope), relChange(nextIntercept, intercept))
+		slope, intercept = nextSlope, nextIntercept
+		if moved <= opts.Tolerance {
+			fit.Converged = true
+			break
+		}
+	}
+
+	fit.Slope, fit.Intercept = slope, intercept
+	_, _, fit.RSquared, _ = WeightedRegression(x, y, w)
+	fit.SlopeSE, fit.InterceptSE = weightedLineSE(x, y, w, slope, intercept)
+	fit.Weights = w
+	return fit, nil
+}
+
+// fitVarianceFunction fits log E[r²] = a + b·z to squared residuals by the gamma pseudo-likelihood,
+// minimizing Σ r²/σ² + log σ² with Fisher scoring. Unlike regressing log r² on z it copes with
+// zero residuals and is not dominated by the smallest ones. ok is false when every residual is zero.
+func fitVarianceFunction(z, r2 []float64) (a, b float64, ok bool) {
+	var mean float64
+	for _, v := range r2 {
+		mean += v / float64(len(r2))
+	}
+	if mean == 0 {
+		return 0, 0, false
+	}
+	a = math.Log(mean)
+	work := make([]float64, len(z))
+	for iteration := 0; iteration < 100; iteration++ {
+		for i := range z {
+			eta := a + b*z[i]
+			work[i] = eta + r2[i]/math.Exp(eta) - 1
+		}
+		nextB, nextA, _ := ManualRegression(z, work)
+		done := relChange(nextA, a) <= 1e-10 && relChange(nextB, b) <= 1e-10
+		a, b = nextA, nextB
+		if done {
+			break
+		}
+	}
+	return a, b, true
+}
+
+// weightedLineSE returns the standard errors of a weighted least squares line, estimating the
+// residual variance from the weighted residuals with n - 2 degrees of freedom
+func weightedLineSE(x, y, w []float64, slope, intercept float64) (slopeSE, interceptSE float64) {
+	var sumW, meanX float64
+	for i := range x {
+		sumW += w[i]
+		meanX += w[i] * x[i]
+	}
+	meanX /= sumW
+	var sxx, sse float64
+	for i := range x {
+		r := y[i] - (intercept + slope*x[i])
+		sxx += w[i] * (x[i] - meanX) * (x[i] - meanX)
+		sse += w[i] * r * r
+	}
+	s2 := sse / float64(len(x)-2)
+	return math.Sqrt(s2 / sxx), math.Sqrt(s2 * (1/sumW + meanX*meanX/sxx))
+}
+
+// relChange is |a - b| relative to the larger magnitude, or absolute when both are below 1
+func relChange(a, b float64) float64 {
+	return math.Abs(a-b) / math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+// printIRWLS writes a dataset's reweighted fit next to the least squares standard errors
+func printIRWLS(w io.Writer, data Dataset, variance VarianceFunction) {
+	fit, err := IRWLSRegression(data.X, data.Y, IRWLSOptions{Variance: variance})
+	if err != nil {
+		fmt.Fprintf(w, "  Reweighted: n/a (%v)\n", err)
+		return
+	}
+	note := ""
+	if !fit.Converged {
+		note = fmt.Sprintf(" (not converged after %d iterations)", fit.Iterations)
+	}
+	fmt.Fprintf(w, "  Reweighted (%s variance, a = %.4g, b = %.4g)%s:\n", fit.Variance, fit.VarianceParams[0], fit.VarianceParams[1], note)
+	fmt.Fprintf(w, "    slope %.6f ± %.6f, intercept %.6f ± %.6f, weighted R-squared %.6f\n", fit.Slope, fit.SlopeSE, fit.Intercept, fit.InterceptSE, fit.RSquared)
+	fmt.Fprintf(w, "    least squares: slope %.6f ± %.6f, intercept %.6f ± %.6f\n", fit.OLSSlope, fit.OLSSlopeSE, fit.OLSIntercept, fit.OLSInterceptSE)
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// heteroscedasticLine returns y = 2 + 3x with noise whose standard deviation is exp(x/2), so the
+// log variance has slope 1 in x
+func heteroscedasticLine(n int, seed uint64) (x, y []float64) {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	for i := 0; i < n; i++ {
+		xi := 8 * float64(i) / float64(n-1)
+		x = append(x, xi)
+		y = append(y, 2+3*xi+math.Exp(xi/2)*rng.NormFloat64())
+	}
+	return x, y
+}
+
+// ✅ Test 1: The exponential variance function is recovered and the line is estimated more tightly
+func TestIRWLSRecoversVariance(t *testing.T) {
+	x, y := heteroscedasticLine(400, 7)
+	fit, err := IRWLSRegression(x, y, IRWLSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fit.Converged || fit.Variance != VarianceExponential {
+		t.Fatalf("got %+v", fit)
+	}
+	if math.Abs(fit.VarianceParams[1]-1) > 0.15 {
+		t.Errorf("variance slope %v, want about 1", fit.VarianceParams[1])
+	}
+	if math.Abs(fit.Slope-3) > 2*fit.SlopeSE || math.Abs(fit.Intercept-2) > 2*fit.InterceptSE {
+		t.Errorf("line %v + %v x is off by more than two standard errors (%v, %v)", fit.Intercept, fit.Slope, fit.InterceptSE, fit.SlopeSE)
+	}
+	// the low-noise points near x = 0 pin the intercept down far better than least squares can
+	if fit.InterceptSE > fit.OLSInterceptSE/3 {
+		t.Errorf("intercept SE %v, least squares %v", fit.InterceptSE, fit.OLSInterceptSE)
+	}
+	var sum float64
+	for _, w := range fit.Weights {
+		sum += w
+	}
+	if len(fit.Weights) != len(x) || math.Abs(sum-float64(len(x))) > 1e-9 || fit.Weights[0] < fit.Weights[len(x)-1] {
+		t.Errorf("weights should average 1 and fall with x, got sum %v", sum)
+	}
+}
+
+// ✅ Test 2: The standard errors are honest: over repeated samples the slope lands within two of
+// them about 95% of the time
+func TestIRWLSCoverage(t *testing.T) {
+	covered, trials := 0, 200
+	for seed := 0; seed < trials; seed++ {
+		x, y := heteroscedasticLine(100, uint64(seed))
+		fit, err := IRWLSRegression(x, y, IRWLSOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(fit.Slope-3) <= 1.96*fit.SlopeSE {
+			covered++
+		}
+	}
+	if share := float64(covered) / float64(trials); share < 0.88 || share > 0.99 {
+		t.Errorf("slope covered in %.0f%% of samples, want about 95%%", 100*share)
+	}
+}
+
+// ✅ Test 3: Homoscedastic and exact data leave least squares alone; bad input is rejected
+func TestIRWLSDegenerate(t *testing.T) {
+	x := []float64{0, 1, 2, 3, math.NaN(), 4}
+	y := []float64{1, 3, 5, 7, 0, 9}
+	fit, err := IRWLSRegression(x, y, IRWLSOptions{Variance: VariancePower})
+	if err != nil || !fit.Converged || relErr(fit.Slope, 2) > 1e-12 || math.Abs(fit.Intercept-1) > 1e-12 {
+		t.Errorf("exact line: got %+v, %v", fit, err)
+	}
+	ds := LoadAnscombeDatasets()["I"]
+	fit, err = IRWLSRegression(ds.X, ds.Y, IRWLSOptions{})
+	if err != nil || !fit.Converged || math.Abs(fit.Slope-fit.OLSSlope) > fit.OLSSlopeSE {
+		t.Errorf("Anscombe I: got %+v, %v", fit, err)
+	}
+	for _, bad := range []struct {
+		x, y []float64
+		opts IRWLSOptions
+	}{
+		{[]float64{1, 2, 3}, []float64{1, 2, 3}, IRWLSOptions{}},
+		{[]float64{2, 2, 2, 2}, []float64{1, 2, 3, 4}, IRWLSOptions{}},
+		{x, y, IRWLSOptions{Variance: "quadratic"}},
+	} {
+		if _, err := IRWLSRegression(bad.x, bad.y, bad.opts); err == nil {
+			t.Errorf("expected an error for %v", bad)
+		}
+	}
+}
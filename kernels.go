@@ -0,0 +1,19 @@
+package main
+
+// regressionMoments holds the raw sums ManualRegression needs
+type regressionMoments struct {
+	sumX, sumY, sumXY, sumXX float64
+}
+
+// The reduction kernels live in kernels_unrolled.go (default) and kernels_purego.go (-tags purego).
+// The unrolled version splits each sum across four independent accumulators so the loads and
+// multiply-adds of consecutive elements do not wait on each other; this is what lets the CPU keep
+// several floating-point pipelines busy on 10M+ point inputs. It is plain Go, not SIMD: the
+// compiler does not vectorize floating-point reductions, and the gain is instruction-level
+// parallelism in scalar code. The purego version is the plain sequential loop, kept as the
+// reference and for platforms where the unrolled form is slower. Both require len(y) >= len(x).
+//
+// Adding in a different order rounds differently, so the two builds, and ManualRegression before
+// and after the kernels were introduced, can disagree in the last bits of every result. Both stay
+// within the usual bound for recursive summation, n·ε·Σ|term| for each sum, which
+// TestRegressionSumsRounding checks against exact sums.
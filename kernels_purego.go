@@ -0,0 +1,25 @@
+//go:build purego
+
+package main
+
+// regressionSums computes the sums of x, y, x*y and x*x sequentially
+func regressionSums(x, y []float64) regressionMoments {
+	var m regressionMoments
+	for i := range x {
+		m.sumX += x[i]
+		m.sumY += y[i]
+		m.sumXY += x[i] * y[i]
+		m.sumXX += x[i] * x[i]
+	}
+	return m
+}
+
+// residualSumSquares computes sum((y - (intercept + slope*x))^2) sequentially
+func residualSumSquares(x, y []float64, slope, intercept float64) float64 {
+	var ss float64
+	for i := range x {
+		r := y[i] - (intercept + slope*x[i])
+		ss += r * r
+	}
+	return ss
+}
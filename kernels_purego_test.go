@@ -0,0 +1,8 @@
+//go:build purego
+
+package main
+
+// manualULPBound is the largest error, in ULPs, the manual engine may make on the quartet with the
+// sequential kernels. Their single running sums round the quartet's sums less closely than the
+// unrolled kernels' partial sums: the intercept of dataset III is about 63 ULPs off.
+const manualULPBound = 96
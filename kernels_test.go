@@ -0,0 +1,126 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"testing"
+)
+
+func naiveSums(x, y []float64) regressionMoments {
+	var m regressionMoments
+	for i := range x {
+		m.sumX += x[i]
+		m.sumY += y[i]
+		m.sumXY += x[i] * y[i]
+		m.sumXX += x[i] * x[i]
+	}
+	return m
+}
+
+// ✅ Test 1: Kernels agree with the sequential loop for every tail length
+func TestRegressionSumsKernel(t *testing.T) {
+	x, y := syntheticLine(37)
+	for n := 0; n <= len(x); n++ {
+		got, want := regressionSums(x[:n], y[:n]), naiveSums(x[:n], y[:n])
+		close := func(a, b float64) bool { return math.Abs(a-b) <= 1e-9*math.Max(1, math.Abs(b)) }
+		if !close(got.sumX, want.sumX) || !close(got.sumY, want.sumY) || !close(got.sumXY, want.sumXY) ||
+			!close(got.sumXX, want.sumXX) {
+			t.Errorf("n=%d: kernel %+v, naive %+v", n, got, want)
+		}
+
+		var rss float64
+		for i := 0; i < n; i++ {
+			r := y[i] - (1 + 0.5*x[i])
+			rss += r * r
+		}
+		if got := residualSumSquares(x[:n], y[:n], 0.5, 1); math.Abs(got-rss) > 1e-9*math.Max(1, rss) {
+			t.Errorf("n=%d: residual kernel %.12f, naive %.12f", n, got, rss)
+		}
+	}
+}
+
+// ✅ Benchmark: chunked kernel vs sequential loop on a large array
+func BenchmarkRegressionSums(b *testing.B) {
+	x, y := syntheticLine(1_000_000)
+	b.Run("kernel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			regressionSums(x, y)
+		}
+	})
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			naiveSums(x, y)
+		}
+	})
+}
+
+// exactSums computes the kernel sums exactly: products of two float64 values need at most 106
+// bits, and a 2048-bit mantissa holds any sum of them without rounding
+func exactSums(x, y []float64) regressionMoments {
+	sums := make([]*big.Float, 4)
+	for k := range sums {
+		sums[k] = new(big.Float).SetPrec(2048)
+	}
+	term := new(big.Float).SetPrec(2048)
+	for i := range x {
+		xi, yi := big.NewFloat(x[i]), big.NewFloat(y[i])
+		sums[0].Add(sums[0], xi)
+		sums[1].Add(sums[1], yi)
+		sums[2].Add(sums[2], term.Mul(xi, yi))
+		sums[3].Add(sums[3], term.Mul(xi, xi))
+	}
+	var f [4]float64
+	for k := range sums {
+		f[k], _ = sums[k].Float64()
+	}
+	return regressionMoments{sumX: f[0], sumY: f[1], sumXY: f[2], sumXX: f[3]}
+}
+
+// ✅ Test 2: Kernel sums stay within the rounding bound of recursive summation, and the
+// regression they feed moves only in its last bits
+func TestRegressionSumsRounding(t *testing.T) {
+	// values of mixed sign and magnitude, so the summation order matters
+	rng := rand.New(rand.NewPCG(7, 8))
+	n := 10_001
+	x, y := make([]float64, n), make([]float64, n)
+	for i := range x {
+		x[i] = (rng.Float64() - 0.3) * math.Pow(10, float64(rng.IntN(7)))
+		y[i] = 1e3 + 2*x[i] + rng.NormFloat64()
+	}
+
+	got, want := regressionSums(x, y), exactSums(x, y)
+	abs := naiveSums(absValues(x), absValues(y)) // Σ|term| of every sum, to rounding
+	gamma := float64(n) * 0x1p-53 / (1 - float64(n)*0x1p-53)
+	for _, c := range []struct {
+		name           string
+		got, want, abs float64
+	}{
+		{"sumX", got.sumX, want.sumX, abs.sumX},
+		{"sumY", got.sumY, want.sumY, abs.sumY},
+		{"sumXY", got.sumXY, want.sumXY, abs.sumXY},
+		{"sumXX", got.sumXX, want.sumXX, abs.sumXX},
+	} {
+		if err := math.Abs(c.got - c.want); err > gamma*c.abs {
+			t.Errorf("%s: off the exact sum by %g, beyond the bound %g", c.name, err, gamma*c.abs)
+		}
+	}
+
+	// on well-conditioned data the reordering is invisible beyond the last few bits of the fit
+	lx, ly := syntheticLine(100_003)
+	slope, intercept, _ := ManualRegression(lx, ly)
+	m, nf := naiveSums(lx, ly), float64(len(lx))
+	wantSlope := (nf*m.sumXY - m.sumX*m.sumY) / (nf*m.sumXX - m.sumX*m.sumX)
+	wantIntercept := (m.sumY - wantSlope*m.sumX) / nf
+	if math.Abs(slope-wantSlope) > 1e-12*math.Abs(wantSlope) || math.Abs(intercept-wantIntercept) > 1e-9*math.Abs(wantIntercept) {
+		t.Errorf("kernel fit %v %v, sequential sums %v %v", slope, intercept, wantSlope, wantIntercept)
+	}
+}
+
+func absValues(v []float64) []float64 {
+	out := make([]float64, len(v))
+	for i, f := range v {
+		out[i] = math.Abs(f)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+//go:build !purego
+
+package main
+
+// regressionSums computes the sums of x, y, x*y and x*x in chunks of four
+func regressionSums(x, y []float64) regressionMoments {
+	var sx0, sx1, sx2, sx3 float64
+	var sy0, sy1, sy2, sy3 float64
+	var sxy0, sxy1, sxy2, sxy3 float64
+	var sxx0, sxx1, sxx2, sxx3 float64
+
+	n := len(x)
+	y = y[:n] // hoist the bounds check out of the loop
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		x0, x1, x2, x3 := x[i], x[i+1], x[i+2], x[i+3]
+		y0, y1, y2, y3 := y[i], y[i+1], y[i+2], y[i+3]
+
+		sx0 += x0
+		sx1 += x1
+		sx2 += x2
+		sx3 += x3
+		sy0 += y0
+		sy1 += y1
+		sy2 += y2
+		sy3 += y3
+		sxy0 += x0 * y0
+		sxy1 += x1 * y1
+		sxy2 += x2 * y2
+		sxy3 += x3 * y3
+		sxx0 += x0 * x0
+		sxx1 += x1 * x1
+		sxx2 += x2 * x2
+		sxx3 += x3 * x3
+	}
+	for ; i < n; i++ {
+		sx0 += x[i]
+		sy0 += y[i]
+		sxy0 += x[i] * y[i]
+		sxx0 += x[i] * x[i]
+	}
+
+	return regressionMoments{
+		sumX:  (sx0 + sx1) + (sx2 + sx3),
+		sumY:  (sy0 + sy1) + (sy2 + sy3),
+		sumXY: (sxy0 + sxy1) + (sxy2 + sxy3),
+		sumXX: (sxx0 + sxx1) + (sxx2 + sxx3),
+	}
+}
+
+// residualSumSquares computes sum((y - (intercept + slope*x))^2) in chunks of four
+func residualSumSquares(x, y []float64, slope, intercept float64) float64 {
+	var s0, s1, s2, s3 float64
+
+	n := len(x)
+	y = y[:n]
+	i := 0
+	for ; i+4 <= n; i += 4 {
+		r0 := y[i] - (intercept + slope*x[i])
+		r1 := y[i+1] - (intercept + slope*x[i+1])
+		r2 := y[i+2] - (intercept + slope*x[i+2])
+		r3 := y[i+3] - (intercept + slope*x[i+3])
+		s0 += r0 * r0
+		s1 += r1 * r1
+		s2 += r2 * r2
+		s3 += r3 * r3
+	}
+	for ; i < n; i++ {
+		r := y[i] - (intercept + slope*x[i])
+		s0 += r * r
+	}
+	return (s0 + s1) + (s2 + s3)
+}
@@ -0,0 +1,7 @@
+//go:build !purego
+
+package main
+
+// manualULPBound is the largest error, in ULPs, the manual engine may make on the quartet with the
+// unrolled kernels, whose four partial sums happen to round the quartet's sums closely
+const manualULPBound = 32
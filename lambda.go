@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FitRequest is the JSON body accepted by the serverless handler
+type FitRequest struct {
+	Dataset string    `json:"dataset"`
+	X       []float64 `json:"x"`
+	Y       []float64 `json:"y"`
+}
+
+// APIGatewayProxyRequest mirrors the API Gateway proxy event (REST v1 and HTTP API v2 payloads)
+type APIGatewayProxyRequest struct {
+	HTTPMethod      string            `json:"httpMethod"`
+	Path            string            `json:"path"`
+	RawPath         string            `json:"rawPath"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+	RequestContext  struct {
+		HTTP struct {
+			Method string `json:"method"`
+		} `json:"http"`
+	} `json:"requestContext"`
+}
+
+// APIGatewayProxyResponse mirrors the response shape API Gateway expects from a proxy integration
+type APIGatewayProxyResponse struct {
+	StatusCode      int               `json:"statusCode"`
+	Headers         map[string]string `json:"headers"`
+	Body            string            `json:"body"`
+	IsBase64Encoded bool              `json:"isBase64Encoded"`
+}
+
+// LambdaHandler turns an API Gateway event carrying a FitRequest into a RegressionResult response.
+// Client mistakes are reported as 4xx responses rather than invocation errors so API Gateway passes them through.
+func LambdaHandler(ctx context.Context, req APIGatewayProxyRequest) (APIGatewayProxyResponse, error) {
+	method := req.HTTPMethod
+	if method == "" {
+		method = req.RequestContext.HTTP.Method
+	}
+	if method != "" && method != http.MethodPost {
+		return jsonResponse(http.StatusMethodNotAllowed, map[string]string{"error": "only POST is supported"}), nil
+	}
+
+	body := []byte(req.Body)
+	if req.IsBase64Encoded {
+		decoded, err := base64.StdEncoding.DecodeString(req.Body)
+		if err != nil {
+			return jsonResponse(http.StatusBadRequest, map[string]string{"error": "invalid base64 body"}), nil
+		}
+		body = decoded
+	}
+
+	var fit FitRequest
+	if err := json.Unmarshal(body, &fit); err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)}), nil
+	}
+
+	result, err := AnalyzeDataset(fit.Dataset, Dataset{X: fit.X, Y: fit.Y})
+	if err != nil {
+		return jsonResponse(http.StatusBadRequest, map[string]string{"error": err.Error()}), nil
+	}
+
+	return jsonResponse(http.StatusOK, result), nil
+}
+
+func jsonResponse(status int, v any) APIGatewayProxyResponse {
+	body, err := json.Marshal(v)
+	if err != nil {
+		status = http.StatusInternalServerError
+		body = []byte(`{"error":"failed to encode response"}`)
+	}
+	return APIGatewayProxyResponse{
+		StatusCode: status,
+		Headers:    map[string]string{"Content-Type": "application/json"},
+		Body:       string(body),
+	}
+}
+
+// RunLambdaRuntime polls the Lambda Runtime API and dispatches each invocation to handler.
+// This lets the binary run as a `provided.al2` custom runtime without the aws-lambda-go dependency.
+func RunLambdaRuntime(api string, handler func(context.Context, APIGatewayProxyRequest) (APIGatewayProxyResponse, error)) error {
+	base := "http://" + api + "/2018-06-01/runtime/invocation/"
+	client := &http.Client{}
+
+	for {
+		resp, err := client.Get(base + "next")
+		if err != nil {
+			return fmt.Errorf("fetching next invocation: %w", err)
+		}
+		event, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reading invocation event: %w", err)
+		}
+
+		requestID := resp.Header.Get("Lambda-Runtime-Aws-Request-Id")
+		ctx := context.Background()
+		cancel := func() {}
+		if ms, err := strconv.ParseInt(resp.Header.Get("Lambda-Runtime-Deadline-Ms"), 10, 64); err == nil {
+			ctx, cancel = context.WithDeadline(ctx, time.UnixMilli(ms))
+		}
+
+		var req APIGatewayProxyRequest
+		var out APIGatewayProxyResponse
+		err = json.Unmarshal(event, &req)
+		if err == nil {
+			out, err = handler(ctx, req)
+		}
+		cancel()
+
+		if err != nil {
+			payload, _ := json.Marshal(map[string]string{"errorMessage": err.Error(), "errorType": "HandlerError"})
+			err = postRuntime(client, base+requestID+"/error", payload)
+		} else {
+			payload, _ := json.Marshal(out)
+			err = postRuntime(client, base+requestID+"/response", payload)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func postRuntime(client *http.Client, url string, payload []byte) error {
+	resp, err := client.Post(url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("posting to runtime API: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("runtime API rejected %s: %s %s", url, resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"net/http"
+	"testing"
+)
+
+// ✅ Test 1: API Gateway event in, RegressionResult out
+func TestLambdaHandlerFitsDataset(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	body, _ := json.Marshal(FitRequest{Dataset: "I", X: data.X, Y: data.Y})
+
+	resp, err := LambdaHandler(context.Background(), APIGatewayProxyRequest{HTTPMethod: http.MethodPost, Body: string(body)})
+	if err != nil {
+		t.Fatalf("handler returned error: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.StatusCode, resp.Body)
+	}
+
+	var result RegressionResult
+	if err := json.Unmarshal([]byte(resp.Body), &result); err != nil {
+		t.Fatalf("response is not a RegressionResult: %v", err)
+	}
+	if result.Dataset != "I" || math.Abs(result.Slope-0.5) > 0.01 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+// ✅ Test 2: HTTP API v2 payloads with base64 bodies are accepted
+func TestLambdaHandlerBase64V2(t *testing.T) {
+	body, _ := json.Marshal(FitRequest{X: []float64{1, 2, 3}, Y: []float64{2, 4, 6}})
+	req := APIGatewayProxyRequest{Body: base64.StdEncoding.EncodeToString(body), IsBase64Encoded: true}
+	req.RequestContext.HTTP.Method = http.MethodPost
+
+	resp, _ := LambdaHandler(context.Background(), req)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", resp.StatusCode, resp.Body)
+	}
+}
+
+// ✅ Test 3: Client errors become 4xx responses, not invocation errors
+func TestLambdaHandlerRejectsBadInput(t *testing.T) {
+	cases := []APIGatewayProxyRequest{
+		{HTTPMethod: http.MethodGet},
+		{HTTPMethod: http.MethodPost, Body: "not json"},
+		{HTTPMethod: http.MethodPost, Body: `{"x":[1],"y":[1,2]}`},
+	}
+	for _, req := range cases {
+		resp, err := LambdaHandler(context.Background(), req)
+		if err != nil {
+			t.Errorf("unexpected invocation error: %v", err)
+		}
+		if resp.StatusCode < 400 || resp.StatusCode >= 500 {
+			t.Errorf("expected 4xx for %+v, got %d", req, resp.StatusCode)
+		}
+	}
+}
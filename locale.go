@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NumberLocale describes how numbers are written in a region's exports and reports: the decimal
+// mark, the characters that may separate thousands, and the CSV field separator, which is ';' where
+// the decimal mark is a comma. The zero NumberLocale reads and writes numbers as Go does, with a
+// decimal point, no thousands separators and comma-separated fields.
+type NumberLocale struct {
+	Name      string
+	Decimal   rune   // '.' when zero
+	Group     string // accepted thousands separators; the first is used for output
+	Separator rune   // CSV field separator; ',' when zero
+}
+
+// numberLocales are the locales accepted by ParseNumberLocale
+var numberLocales = []NumberLocale{
+	{Name: "en", Decimal: '.', Group: ",", Separator: ','},
+	{Name: "de", Decimal: ',', Group: ".", Separator: ';'},
+	{Name: "fr", Decimal: ',', Group: " \u00a0\u202f", Separator: ';'},
+	{Name: "ch", Decimal: '.', Group: "'\u2019", Separator: ';'},
+}
+
+// localeNames lists the locale names for usage messages
+func localeNames() string {
+	names := make([]string, len(numberLocales))
+	for i, l := range numberLocales {
+		names[i] = l.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseNumberLocale returns a locale by name: en (1,234.5), de (1.234,5 with ';' between fields,
+// also right for most of continental Europe), fr (1 234,5 with ';') or ch (1'234.5 with ';').
+// The empty name returns the zero NumberLocale.
+func ParseNumberLocale(name string) (NumberLocale, error) {
+	if name == "" {
+		return NumberLocale{}, nil
+	}
+	for _, l := range numberLocales {
+		if strings.EqualFold(l.Name, name) {
+			return l, nil
+		}
+	}
+	return NumberLocale{}, fmt.Errorf("unknown locale %q (use %s)", name, localeNames())
+}
+
+func (l NumberLocale) decimal() rune {
+	if l.Decimal == 0 {
+		return '.'
+	}
+	return l.Decimal
+}
+
+// ParseFloat parses a numeric CSV field written in the locale. Empty and NA fields become NaN, as
+// with every loader. Thousands separators must group the integer digits in threes, so a field
+// written for another locale, such as "1.5" read as German, is an error rather than 15.
+func (l NumberLocale) ParseFloat(field string) (float64, error) {
+	if isMissingField(field) {
+		return math.NaN(), nil
+	}
+	field = strings.TrimSpace(field)
+	if l.Decimal == 0 && l.Group == "" {
+		return strconv.ParseFloat(field, 64)
+	}
+
+	var b strings.Builder
+	var groups []int // lengths of the digit runs between thousands separators
+	run, inFraction, inExponent := 0, false, false
+	for _, r := range field {
+		switch {
+		case inExponent:
+		case r >= '0' && r <= '9':
+			run++
+		case r == 'e' || r == 'E':
+			inExponent = true
+		case r == l.decimal() && !inFraction:
+			inFraction = true
+			groups = append(groups, run)
+			run = 0
+			b.WriteRune('.')
+			continue
+		case strings.ContainsRune(l.Group, r) && !inFraction:
+			groups = append(groups, run)
+			run = 0
+			continue
+		case r == '.' || r == ',':
+			return 0, fmt.Errorf("invalid number %q in locale %s", field, l.Name)
+		}
+		b.WriteRune(r)
+	}
+	if !inFraction {
+		groups = append(groups, run)
+	}
+	// with separators, only the leading run may have fewer than three digits
+	for i, n := range groups {
+		if len(groups) > 1 && (i == 0 && (n < 1 || n > 3) || i > 0 && n != 3) {
+			return 0, fmt.Errorf("invalid digit grouping in %q for locale %s", field, l.Name)
+		}
+	}
+	v, err := strconv.ParseFloat(b.String(), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q in locale %s", field, l.Name)
+	}
+	return v, nil
+}
+
+// FormatFloat writes v with prec decimals in the locale, grouping the integer digits in threes
+func (l NumberLocale) FormatFloat(v float64, prec int) string {
+	s := strconv.FormatFloat(v, 'f', prec, 64)
+	if l.Decimal == 0 && l.Group == "" || math.IsNaN(v) || math.IsInf(v, 0) {
+		return s
+	}
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	whole, fraction, _ := strings.Cut(s, ".")
+	if l.Group != "" {
+		sep := string([]rune(l.Group)[0])
+		var parts []string
+		for len(whole) > 3 {
+			parts = append(parts, whole[len(whole)-3:])
+			whole = whole[:len(whole)-3]
+		}
+		parts = append(parts, whole)
+		slices.Reverse(parts)
+		whole = strings.Join(parts, sep)
+	}
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + string(l.decimal()) + fraction
+}
+
+// withUnit is the package's withUnit in the locale
+func (l NumberLocale) withUnit(v float64, unit string) string {
+	if unit == "" {
+		return l.FormatFloat(v, 6)
+	}
+	return l.FormatFloat(v, 6) + " " + unit
+}
+
+// newCSVReader returns a CSV reader using the locale's field separator
+func (l NumberLocale) newCSVReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	if l.Separator != 0 {
+		reader.Comma = l.Separator
+	}
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	return reader
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+func mustLocale(t *testing.T, name string) NumberLocale {
+	t.Helper()
+	l, err := ParseNumberLocale(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// ✅ Test 1: Numbers written with each locale's decimal mark and thousands separators parse
+func TestNumberLocaleParseFloat(t *testing.T) {
+	cases := []struct {
+		locale, field string
+		want          float64
+	}{
+		{"en", "1,234.5", 1234.5},
+		{"en", "-1,234,567", -1234567},
+		{"en", "1.5e3", 1500},
+		{"de", "1.234,5", 1234.5},
+		{"de", "-0,25", -0.25},
+		{"de", "1234", 1234},
+		{"de", "2,5E-3", 0.0025},
+		{"fr", "1 234,5", 1234.5},
+		{"fr", "12 345", 12345},
+		{"ch", "1'234.5", 1234.5},
+		{"ch", "1’000", 1000},
+		{"", "1234.5", 1234.5},
+	}
+	for _, c := range cases {
+		got, err := mustLocale(t, c.locale).ParseFloat(c.field)
+		if err != nil || got != c.want {
+			t.Errorf("%s %q: expected %g, got %g (err %v)", c.locale, c.field, c.want, got, err)
+		}
+	}
+	if v, err := mustLocale(t, "de").ParseFloat(" NA "); err != nil || !math.IsNaN(v) {
+		t.Errorf("expected a missing field to be NaN, got %g (err %v)", v, err)
+	}
+}
+
+// ✅ Test 2: Numbers written for another locale are rejected rather than misread
+func TestNumberLocaleParseErrors(t *testing.T) {
+	cases := []struct{ locale, field string }{
+		{"de", "1.5"},     // an English decimal, not fifteen
+		{"de", "1,234.5"}, // English grouping
+		{"en", "1,5"},     // a German decimal
+		{"en", "12,34.5"}, // groups of two
+		{"en", ",123"},    // no leading digits
+		{"fr", "1.234,5"}, // fr does not group with points
+		{"ch", "1'234,5"}, // ch writes a decimal point
+		{"de", "1,2,3"},   // two decimal marks
+		{"de", "zwölf"},   // not a number
+		{"", "1,234"},     // the zero locale does not group
+	}
+	for _, c := range cases {
+		if v, err := mustLocale(t, c.locale).ParseFloat(c.field); err == nil {
+			t.Errorf("%s %q: expected an error, got %g", c.locale, c.field, v)
+		}
+	}
+	if _, err := ParseNumberLocale("xx"); err == nil || !strings.Contains(err.Error(), "de") {
+		t.Errorf("expected an unknown locale error listing the locales, got %v", err)
+	}
+}
+
+// ✅ Test 3: Formatted numbers group the integer digits and parse back to the same value
+func TestNumberLocaleFormatFloat(t *testing.T) {
+	cases := []struct {
+		locale string
+		v      float64
+		prec   int
+		want   string
+	}{
+		{"en", 1234567.891, 2, "1,234,567.89"},
+		{"de", -1234.5, 1, "-1.234,5"},
+		{"fr", 1234.5, 1, "1 234,5"},
+		{"ch", 999.5, 1, "999.5"},
+		{"de", 12, 0, "12"},
+		{"", 1234.5, 1, "1234.5"},
+	}
+	for _, c := range cases {
+		l := mustLocale(t, c.locale)
+		got := l.FormatFloat(c.v, c.prec)
+		if got != c.want {
+			t.Errorf("%s %g: expected %q, got %q", c.locale, c.v, c.want, got)
+		}
+		if back, err := l.ParseFloat(got); err != nil || math.Abs(back-c.v) > math.Pow(10, -float64(c.prec)) {
+			t.Errorf("%s %q: parsed back as %g (err %v)", c.locale, got, back, err)
+		}
+	}
+	if got := mustLocale(t, "de").withUnit(0.5, "mg"); got != "0,500000 mg" {
+		t.Errorf("expected a localized value with its unit, got %q", got)
+	}
+}
+
+// ✅ Test 4: A German export with ';' between fields loads like the equivalent plain CSV
+func TestLoadCSVDatasetWithLocale(t *testing.T) {
+	german := "Dosis (mg);Wirkung\n1,5;2.001,25\n2,5;NA\n4;2.006\n"
+	ds, err := LoadCSVDatasetWithLocale(strings.NewReader(german), mustLocale(t, "de"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ds.X) != 3 || ds.X[0] != 1.5 || ds.Y[0] != 2001.25 || !math.IsNaN(ds.Y[1]) || ds.Y[2] != 2006 || ds.XAxis.Unit != "mg" {
+		t.Errorf("unexpected dataset %+v", ds)
+	}
+	if _, err := LoadCSVDatasetWithLocale(strings.NewReader("x;y\n1;2\n1.5;3\n"), mustLocale(t, "de")); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected an error on the English decimal, got %v", err)
+	}
+
+	groups, err := LoadCSVGroupsWithLocale(strings.NewReader("g;x;y\na;1,5;2\na;2,5;3\n"), "g", mustLocale(t, "de"))
+	if err != nil || len(groups) != 1 || groups[0].Data.X[1] != 2.5 {
+		t.Errorf("unexpected groups %+v (err %v)", groups, err)
+	}
+}
+
+// ✅ Test 5: The HTML report writes fitted values in the report's locale
+func TestHTMLReportLocale(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 3, 4}, Y: []float64{1000.5, 2000.5, 3000.5, 4000.75}}
+	slope, intercept, r2, _, err := performLinearRegression(data.X, data.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := RegressionResult{Dataset: "thousands", Slope: slope, Intercept: intercept, RSquared: r2}
+	var buf bytes.Buffer
+	datasets := []HTMLReportDataset{{Name: "thousands", Data: data, Result: result}}
+	if err := WriteHTMLReport(&buf, "Report", datasets, HTMLReportOptions{Locale: mustLocale(t, "de")}); err != nil {
+		t.Fatal(err)
+	}
+	if want := mustLocale(t, "de").FormatFloat(result.Slope, 6); !strings.Contains(buf.String(), want) || !strings.Contains(want, ".") {
+		t.Errorf("expected the slope written as %s in the report", want)
+	}
+}
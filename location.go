@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// TTestResult is a two-sample t-test of A minus B
+type TTestResult struct {
+	Difference float64 `json:"difference"`
+	StdErr     float64 `json:"stdErr"`
+	Statistic  float64 `json:"t"`
+	DF         float64 `json:"df"`
+	PValue     float64 `json:"pValue"`
+	// EffectSize is Cohen's d, the difference in units of the average standard deviation.
+	// It is left at 0 by CompareSlopes, where no such scale exists.
+	EffectSize float64 `json:"effectSize"`
+}
+
+// MannWhitneyResult is a Mann–Whitney U (Wilcoxon rank-sum) test of A against B
+type MannWhitneyResult struct {
+	// U counts the pairs where A's value exceeds B's (ties count half); it is R's W statistic
+	U      float64 `json:"u"`
+	Z      float64 `json:"z"`
+	PValue float64 `json:"pValue"`
+	// RankBiserial is the effect size 2U/(nA nB) - 1, from -1 (every B larger) to 1 (every A larger)
+	RankBiserial float64 `json:"rankBiserial"`
+}
+
+// WelchTTest compares the means of a and b without assuming equal variances, with the
+// Welch–Satterthwaite degrees of freedom. NaN and Inf values are ignored.
+func WelchTTest(a, b []float64) (TTestResult, error) {
+	a, b = sortedFinite(a), sortedFinite(b)
+	if len(a) < 2 || len(b) < 2 {
+		return TTestResult{}, fmt.Errorf("need at least two values per sample (have %d and %d)", len(a), len(b))
+	}
+	meanA, varA := meanVariance(a)
+	meanB, varB := meanVariance(b)
+	va, vb := varA/float64(len(a)), varB/float64(len(b))
+	if va+vb == 0 {
+		return TTestResult{}, fmt.Errorf("both samples are constant")
+	}
+
+	r := TTestResult{Difference: meanA - meanB, StdErr: math.Sqrt(va + vb)}
+	r.Statistic = r.Difference / r.StdErr
+	r.DF = (va + vb) * (va + vb) / (va*va/float64(len(a)-1) + vb*vb/float64(len(b)-1))
+	r.PValue = studentTTwoSided(r.Statistic, r.DF)
+	r.EffectSize = r.Difference / math.Sqrt((varA+varB)/2)
+	return r, nil
+}
+
+// MannWhitneyU compares the locations of a and b by ranks, using the normal approximation with
+// tie and continuity corrections (as R's wilcox.test(exact = FALSE)). NaN and Inf values are ignored.
+func MannWhitneyU(a, b []float64) (MannWhitneyResult, error) {
+	a, b = sortedFinite(a), sortedFinite(b)
+	if len(a) == 0 || len(b) == 0 {
+		return MannWhitneyResult{}, fmt.Errorf("need at least one value per sample (have %d and %d)", len(a), len(b))
+	}
+	na, nb := float64(len(a)), float64(len(b))
+	n := na + nb
+
+	type obs struct {
+		v     float64
+		fromA bool
+	}
+	all := make([]obs, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range b {
+		all = append(all, obs{v, false})
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].v < all[j].v })
+
+	// tied values share their average rank
+	var rankSumA, tieTerm float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].v == all[i].v {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].fromA {
+				rankSumA += rank
+			}
+		}
+		t := float64(j - i)
+		tieTerm += t*t*t - t
+		i = j
+	}
+
+	r := MannWhitneyResult{U: rankSumA - na*(na+1)/2}
+	r.RankBiserial = 2*r.U/(na*nb) - 1
+	sigma := math.Sqrt(na * nb / 12 * ((n + 1) - tieTerm/(n*(n-1))))
+	if sigma == 0 {
+		r.PValue = 1
+		return r, nil
+	}
+	shift := r.U - na*nb/2
+	correction := 0.5 * math.Copysign(1, shift)
+	if shift == 0 {
+		correction = 0
+	}
+	r.Z = (shift - correction) / sigma
+	r.PValue = normalTwoSided(r.Z)
+	return r, nil
+}
+
+// CompareSlopes tests whether two datasets have the same regression slope:
+// t = (b_A - b_B) / sqrt(se_A² + se_B²) with n_A + n_B - 4 degrees of freedom.
+func CompareSlopes(a, b Dataset) (TTestResult, error) {
+	slopeA, seA, nA, err := slopeStdErr(a)
+	if err != nil {
+		return TTestResult{}, fmt.Errorf("dataset A: %w", err)
+	}
+	slopeB, seB, nB, err := slopeStdErr(b)
+	if err != nil {
+		return TTestResult{}, fmt.Errorf("dataset B: %w", err)
+	}
+	r := TTestResult{Difference: slopeA - slopeB, StdErr: math.Hypot(seA, seB), DF: float64(nA + nB - 4)}
+	if r.StdErr == 0 {
+		return TTestResult{}, fmt.Errorf("both fits are exact, so the slopes have no standard error")
+	}
+	r.Statistic = r.Difference / r.StdErr
+	r.PValue = studentTTwoSided(r.Statistic, r.DF)
+	return r, nil
+}
+
+// slopeStdErr fits d and returns the slope with its standard error, sqrt(SSres/(n-2) / Sxx)
+func slopeStdErr(d Dataset) (slope, stdErr float64, n int, err error) {
+	x, y, err := cleanPairs(nil, nil, d.X, d.Y)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	if len(x) < 3 {
+		return 0, 0, 0, fmt.Errorf("need at least three points for a slope standard error (have %d)", len(x))
+	}
+	slope, intercept, _ := ManualRegression(x, y)
+	_, varX := meanVariance(x)
+	sxx := varX * float64(len(x)-1)
+	if sxx == 0 {
+		return 0, 0, 0, fmt.Errorf("x is constant, so the slope is undefined")
+	}
+	ssRes := residualSumSquares(x, y, slope, intercept)
+	return slope, math.Sqrt(ssRes / float64(len(x)-2) / sxx), len(x), nil
+}
+
+// meanVariance returns the mean and the sample (n-1) variance, by Welford's method
+func meanVariance(values []float64) (mean, variance float64) {
+	var m2 float64
+	for i, v := range values {
+		delta := v - mean
+		mean += delta / float64(i+1)
+		m2 += delta * (v - mean)
+	}
+	if len(values) > 1 {
+		variance = m2 / float64(len(values)-1)
+	}
+	return mean, variance
+}
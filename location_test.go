@@ -0,0 +1,83 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: Student t and normal tail probabilities match closed forms
+func TestTailProbabilities(t *testing.T) {
+	cases := []struct{ got, want float64 }{
+		{studentTTwoSided(1, 1), 0.5},                // Cauchy: 1 - 2 atan(1)/π
+		{studentTTwoSided(2, 2), 1 - 2/math.Sqrt(6)}, // df 2: 1 - t/sqrt(t²+2)
+		{studentTTwoSided(0, 7), 1},
+		{normalTwoSided(1.959963984540054), 0.05},
+	}
+	for i, c := range cases {
+		if math.Abs(c.got-c.want) > 1e-12 {
+			t.Errorf("case %d: expected %v, got %v", i, c.want, c.got)
+		}
+	}
+}
+
+// ✅ Test 2: Welch's t-test statistic, degrees of freedom and effect size
+func TestWelchTTest(t *testing.T) {
+	a := []float64{1, 2, 3, 4, 5}      // mean 3, variance 2.5
+	b := []float64{2, 4, 6, 8, 10, 12} // mean 7, variance 14
+	r, err := WelchTTest(a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	va, vb := 2.5/5, 14.0/6
+	wantDF := (va + vb) * (va + vb) / (va*va/4 + vb*vb/5)
+	if math.Abs(r.Statistic-(-4/math.Sqrt(va+vb))) > 1e-12 || math.Abs(r.DF-wantDF) > 1e-12 {
+		t.Errorf("unexpected statistic %+v", r)
+	}
+	if math.Abs(r.EffectSize-(-4/math.Sqrt(8.25))) > 1e-12 || !(r.PValue > 0.01 && r.PValue < 0.05) {
+		t.Errorf("unexpected effect size or p-value %+v", r)
+	}
+	if _, err := WelchTTest([]float64{1}, b); err == nil {
+		t.Error("expected an error for a single value")
+	}
+}
+
+// ✅ Test 3: Mann-Whitney U matches R's wilcox.test(1:5, 6:10, exact = FALSE)
+func TestMannWhitneyU(t *testing.T) {
+	r, err := MannWhitneyU([]float64{1, 2, 3, 4, 5}, []float64{6, 7, 8, 9, 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.U != 0 || r.RankBiserial != -1 || math.Abs(r.PValue-0.01219) > 5e-5 {
+		t.Errorf("unexpected result %+v", r)
+	}
+
+	// ties share ranks: every pair is tied, so U is half the pairs
+	if r, _ := MannWhitneyU([]float64{3, 3}, []float64{3, 3, 3}); r.U != 3 || r.PValue != 1 {
+		t.Errorf("all-tied samples: %+v", r)
+	}
+}
+
+// ✅ Test 4: Slope comparison separates different slopes, not equal ones
+func TestCompareSlopes(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	line := func(slope float64) Dataset {
+		var d Dataset
+		for i := 0; i < 50; i++ {
+			x := float64(i)
+			d.X, d.Y = append(d.X, x), append(d.Y, slope*x+rng.NormFloat64())
+		}
+		return d
+	}
+	if r, err := CompareSlopes(line(1), line(1)); err != nil || r.PValue < 0.01 || r.DF != 96 {
+		t.Errorf("equal slopes: %+v (err %v)", r, err)
+	}
+	if r, err := CompareSlopes(line(1), line(1.2)); err != nil || r.PValue > 1e-6 {
+		t.Errorf("different slopes: %+v (err %v)", r, err)
+	}
+
+	quartet := LoadAnscombeDatasets()
+	if c, _ := CompareDatasets(quartet["I"], quartet["II"]); c.YMeans == nil || c.YRanks == nil || c.Slopes == nil || c.Slopes.PValue < 0.9 {
+		t.Errorf("expected location tests in the comparison, got %+v", c)
+	}
+}
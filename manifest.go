@@ -0,0 +1,148 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"time"
+)
+
+// statsModule is the module path of the library behind the default engine
+const statsModule = "github.com/montanaflynn/stats"
+
+// clusterSeed seeds the k-means clustering of the text, HTML and golden reports
+const clusterSeed = 1
+
+// intervalSeed seeds the bootstrap bands of the HTML report (see BootstrapBand)
+const intervalSeed = 1
+
+// Manifest records what is needed to reproduce a run: the build, the options it was given, the
+// seeds it used and, per dataset, the data fingerprint and the engine that produced the fit.
+// Results may come from montanaflynn/stats or from the manual fallback (see
+// PerformLinearRegression), so the engine is recorded per dataset rather than per run.
+type Manifest struct {
+	Created      time.Time         `json:"created"`
+	Module       string            `json:"module"`
+	Version      string            `json:"version"`
+	Revision     string            `json:"revision,omitempty"`
+	Modified     bool              `json:"modified,omitempty"`
+	GoVersion    string            `json:"goVersion"`
+	StatsVersion string            `json:"statsVersion"`
+	Engine       string            `json:"engine"`
+	Options      map[string]string `json:"options,omitempty"`
+	Seeds        map[string]uint64 `json:"seeds,omitempty"`
+	Datasets     []ManifestDataset `json:"datasets"`
+}
+
+// ManifestDataset is the provenance of one dataset's result
+type ManifestDataset struct {
+	Name        string `json:"name"`
+	Fingerprint string `json:"fingerprint"`
+	Engine      string `json:"engine"`
+	Fallback    string `json:"fallback,omitempty"`
+}
+
+// NewManifest describes the running binary, with engine as the engine requested for the run.
+// Versions the binary was not built with are "unknown"; a build from a source tree is "(devel)".
+func NewManifest(engine string) Manifest {
+	m := Manifest{
+		Created:      time.Now().UTC(),
+		Version:      "unknown",
+		GoVersion:    runtime.Version(),
+		StatsVersion: "unknown",
+		Engine:       engine,
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return m
+	}
+	m.Module, m.Version = info.Main.Path, info.Main.Version
+	for _, dep := range info.Deps {
+		if dep.Path == statsModule {
+			m.StatsVersion = dep.Version
+			if dep.Replace != nil {
+				m.StatsVersion += " => " + dep.Replace.Path + " " + dep.Replace.Version
+			}
+		}
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			m.Revision = setting.Value
+		case "vcs.modified":
+			m.Modified = setting.Value == "true"
+		}
+	}
+	return m
+}
+
+// SetOptions records the flags set on the command line, by name
+func (m *Manifest) SetOptions(flags *flag.FlagSet) {
+	m.Options = map[string]string{}
+	flags.Visit(func(f *flag.Flag) {
+		m.Options[f.Name] = f.Value.String()
+	})
+}
+
+// SetSeed records the seed used for a randomized step of the run
+func (m *Manifest) SetSeed(step string, seed uint64) {
+	if m.Seeds == nil {
+		m.Seeds = map[string]uint64{}
+	}
+	m.Seeds[step] = seed
+}
+
+// AddResult records the provenance of a dataset's result
+func (m *Manifest) AddResult(r RegressionResult) {
+	m.Datasets = append(m.Datasets, ManifestDataset{Name: r.Dataset, Fingerprint: r.Fingerprint, Engine: r.Engine, Fallback: r.Fallback})
+}
+
+// PrintManifest writes the manifest section of the text report; per-dataset fingerprints are
+// already printed with each dataset, so only fallbacks are listed
+func PrintManifest(w io.Writer, m Manifest) {
+	build := m.Version
+	if m.Revision != "" {
+		build += " (" + m.Revision
+		if m.Modified {
+			build += ", modified"
+		}
+		build += ")"
+	}
+	fmt.Fprintf(w, "Build:   %s %s, %s\n", m.Module, build, m.GoVersion)
+	fmt.Fprintf(w, "Engine:  %s, montanaflynn/stats %s\n", m.Engine, m.StatsVersion)
+	if len(m.Options) > 0 {
+		fmt.Fprintf(w, "Options: %s\n", formatSorted(m.Options))
+	}
+	if len(m.Seeds) > 0 {
+		seeds := make(map[string]string, len(m.Seeds))
+		for step, seed := range m.Seeds {
+			seeds[step] = fmt.Sprint(seed)
+		}
+		fmt.Fprintf(w, "Seeds:   %s\n", formatSorted(seeds))
+	}
+	for _, d := range m.Datasets {
+		if d.Fallback != "" {
+			fmt.Fprintf(w, "Dataset %s fell back to the manual calculation: %s\n", d.Name, d.Fallback)
+		}
+	}
+}
+
+// formatSorted joins key=value pairs in key order
+func formatSorted(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	for k := range values {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	out := ""
+	for i, k := range keys {
+		if i > 0 {
+			out += " "
+		}
+		out += k + "=" + values[k]
+	}
+	return out
+}
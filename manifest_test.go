@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Results record their engine and why they fell back to the manual calculation
+func TestResultEngine(t *testing.T) {
+	data := LoadAnscombeDatasets()
+	r, err := AnalyzeDataset("I", data["I"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Engine != "stats" || r.Fallback != "" {
+		t.Errorf("expected the library's fit for dataset I, got engine %q, fallback %q", r.Engine, r.Fallback)
+	}
+	// dataset IV's library line has identical X values at both ends
+	r, err = AnalyzeDataset("IV", data["IV"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Engine != "stats" || r.Fallback != "identical X values" {
+		t.Errorf("expected a recorded fallback for dataset IV, got engine %q, fallback %q", r.Engine, r.Fallback)
+	}
+
+	weighted := Dataset{X: []float64{1, 2, 3}, Y: []float64{2, 4, 7}, Weights: []float64{1, 1, 2}}
+	if r, err = AnalyzeDataset("w", weighted); err != nil || r.Engine != "weighted" {
+		t.Errorf("expected the weighted engine, got %q (%v)", r.Engine, err)
+	}
+}
+
+// ✅ Test 2: The manifest captures the build, set flags, seeds and dataset provenance
+func TestManifest(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.String("clusters", "", "")
+	flags.Int("workers", 4, "")
+	if err := flags.Parse([]string{"-clusters", "xy"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := NewManifest("stats")
+	m.SetOptions(flags)
+	m.SetSeed("clusters", clusterSeed)
+	m.AddResult(RegressionResult{Dataset: "IV", Fingerprint: "sha256:abc", Engine: "stats", Fallback: "identical X values"})
+	if m.GoVersion == "" || m.StatsVersion == "" || m.Engine != "stats" {
+		t.Errorf("incomplete build information %+v", m)
+	}
+	if len(m.Options) != 1 || m.Options["clusters"] != "xy" || m.Seeds["clusters"] != 1 {
+		t.Errorf("unexpected options %v and seeds %v", m.Options, m.Seeds)
+	}
+
+	var buf bytes.Buffer
+	PrintManifest(&buf, m)
+	for _, want := range []string{"Options: clusters=xy", "Seeds:   clusters=1", "Dataset IV fell back to the manual calculation: identical X values"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("expected %q in:\n%s", want, buf.String())
+		}
+	}
+}
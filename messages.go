@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Messages translates the text of reports and diagnostics into one language. Text is looked up by
+// its English wording, which is also what is written for anything the catalog lacks, so the zero
+// Messages writes English and a partial translation still reads.
+type Messages struct {
+	Lang  string
+	table map[string]string
+}
+
+// messageCatalog maps each language to the translations of the English report text. Keys are the
+// exact English strings, including their format verbs; a translation may reorder its arguments
+// with explicit indexes such as %[2]g.
+var messageCatalog = map[string]map[string]string{
+	"es": {
+		// report
+		"%s Regression Analysis": "Análisis de regresión: %s",
+		"Batch":                  "Lote",
+		"Grouped":                "Por grupos",
+		"Merged":                 "Combinado",
+		"Loading datasets and performing linear regression...": "Cargando los conjuntos de datos y ajustando la regresión lineal...",
+		"Dataset %s:":                          "Conjunto de datos %s:",
+		"Axes":                                 "Ejes",
+		"Slope":                                "Pendiente",
+		"Intercept":                            "Intercepto",
+		"R-squared":                            "R²",
+		"Points":                               "Puntos",
+		"Engine":                               "Motor",
+		"Data":                                 "Datos",
+		"Time":                                 "Tiempo",
+		"%v (cached)":                          "%v (en caché)",
+		"Advice":                               "Consejo",
+		"Warning":                              "Aviso",
+		"n/a (%v)":                             "no disponible (%v)",
+		"Most influential points":              "Puntos más influyentes",
+		"Regression failed for dataset %s: %v": "La regresión falló para el conjunto de datos %s: %v",
+		"manual fallback: %s":                  "cálculo manual: %s",
+		"the stats engine failed (%s), so the line was fitted by the manual calculation": "el motor estadístico falló (%s), así que la recta se ajustó con el cálculo manual",
+		"Conditioning": "Condicionamiento",
+		"κ = %.3g (scaled %.3g), about %.1f digits lost to the normal equations": "κ = %.3g (escalado %.3g), unos %.1f dígitos perdidos en las ecuaciones normales",
+		"κ = %.3g (scaled %.3g), about %.1f digits lost to QR":                   "κ = %.3g (escalado %.3g), unos %.1f dígitos perdidos en QR",
+		"the fit may be numerically unreliable; %s":                              "el ajuste puede ser numéricamente poco fiable; %s",
+		"center %s, whose mean lies %.3g standard deviations from 0":             "centre %s, cuya media está a %.3g desviaciones estándar de 0",
+		"rescale the predictors to similar magnitudes":                           "reescale los predictores a magnitudes similares",
+		"drop or combine nearly collinear predictors":                            "elimine o combine los predictores casi colineales",
+		"Summary":                                                 "Resumen",
+		"Total execution time: %v":                                "Tiempo total de ejecución: %v",
+		"Average per dataset:  %.6fs":                             "Promedio por conjunto de datos: %.6fs",
+		"Average per dataset:  N/A (no datasets)":                 "Promedio por conjunto de datos: N/D (sin conjuntos de datos)",
+		"Cached results:       %d of %d (use -no-cache to refit)": "Resultados en caché: %d de %d (use -no-cache para reajustar)",
+		"Comparison":                                              "Comparación",
+		"Common Slope (ANCOVA)":                                   "Pendiente común (ANCOVA)",
+		"Manifest":                                                "Manifiesto",
+		"Golden File (%s)":                                        "Archivo de referencia (%s)",
+		"Time Series Regression Analysis":                         "Análisis de regresión de series temporales",
+
+		// HTML report
+		"Reproducibility":         "Reproducibilidad",
+		"Build":                   "Compilación",
+		"Created":                 "Creado",
+		"Seed (%s)":               "Semilla (%s)",
+		"Point":                   "Punto",
+		"Residual":                "Residuo",
+		"Leverage":                "Apalancamiento",
+		"Cook's distance":         "Distancia de Cook",
+		"Slope change if removed": "Cambio de pendiente al quitarlo",
+		"Data and fit with %g%% confidence and prediction bands":           "Datos y ajuste con bandas de confianza y de predicción del %g%%",
+		"Data and fit with %g%% bootstrap confidence and prediction bands": "Datos y ajuste con bandas bootstrap de confianza y de predicción del %g%%",
+		"Distribution of %s":        "Distribución de %s",
+		"Distribution of residuals": "Distribución de los residuos",
+
+		// advice
+		"a single point drives this fit; collect data across the X range before trusting the slope": "un solo punto determina este ajuste; recoja datos en todo el rango de X antes de confiar en la pendiente",
+		"point %d at x = %g has leverage %.2f":                                                      "el punto %d en x = %g tiene un apalancamiento de %.2f",
+		"an outlier pulls the line; use a robust fit (Theil-Sen or Huber) or check that point":      "un valor atípico arrastra la recta; use un ajuste robusto (Theil-Sen o Huber) o revise ese punto",
+		"point %d at x = %g has studentized residual %.3g":                                          "el punto %d en x = %g tiene un residuo estudentizado de %.3g",
+		"robust fits disagree with least squares; use a robust fit":                                 "los ajustes robustos no coinciden con los mínimos cuadrados; use un ajuste robusto",
+		"slopes span %.2f standard errors":                                                          "las pendientes difieren en %.2f errores estándar",
+		"the relationship curves; use a quadratic fit (multi -degree 2)":                            "la relación es curva; use un ajuste cuadrático (multi -degree 2)",
+		"quadratic term p = %.3g":                                                                   "término cuadrático p = %.3g",
+		"the line misses the means of the replicated X values; try a curve or a transform":          "la recta no pasa por las medias de los valores de X repetidos; pruebe una curva o una transformación",
+		"lack of fit F(%d, %d) = %.3g, p = %.3g":                                                    "falta de ajuste F(%d, %d) = %.3g, p = %.3g",
+		"the straight line looks adequate":                                                          "la recta parece adecuada",
+		"no high-leverage points, outliers or curvature among %d points":                            "ni puntos de alto apalancamiento, ni valores atípicos, ni curvatura entre %d puntos",
+	},
+	"zh": {
+		// report
+		"%s Regression Analysis": "%s回归分析",
+		"Batch":                  "批量",
+		"Grouped":                "分组",
+		"Merged":                 "合并",
+		"Loading datasets and performing linear regression...": "正在加载数据集并进行线性回归……",
+		"Dataset %s:":                          "数据集 %s：",
+		"Axes":                                 "坐标轴",
+		"Slope":                                "斜率",
+		"Intercept":                            "截距",
+		"R-squared":                            "R²",
+		"Points":                               "点数",
+		"Engine":                               "引擎",
+		"Data":                                 "数据",
+		"Time":                                 "耗时",
+		"%v (cached)":                          "%v（缓存）",
+		"Advice":                               "建议",
+		"Warning":                              "警告",
+		"n/a (%v)":                             "不可用（%v）",
+		"Most influential points":              "影响最大的点",
+		"Regression failed for dataset %s: %v": "数据集 %s 的回归失败：%v",
+		"manual fallback: %s":                  "手工计算：%s",
+		"the stats engine failed (%s), so the line was fitted by the manual calculation": "统计引擎失败（%s），因此改用手工计算拟合直线",
+		"Conditioning": "条件数",
+		"κ = %.3g (scaled %.3g), about %.1f digits lost to the normal equations": "κ = %.3g（缩放后 %.3g），正规方程约损失 %.1f 位有效数字",
+		"κ = %.3g (scaled %.3g), about %.1f digits lost to QR":                   "κ = %.3g（缩放后 %.3g），QR 约损失 %.1f 位有效数字",
+		"the fit may be numerically unreliable; %s":                              "拟合结果在数值上可能不可靠；%s",
+		"center %s, whose mean lies %.3g standard deviations from 0":             "请将 %s 中心化，其均值距 0 有 %.3g 个标准差",
+		"rescale the predictors to similar magnitudes":                           "请将各预测变量缩放到相近的量级",
+		"drop or combine nearly collinear predictors":                            "请删除或合并近似共线的预测变量",
+		"Summary":                                                 "摘要",
+		"Total execution time: %v":                                "总执行时间：%v",
+		"Average per dataset:  %.6fs":                             "每个数据集平均：%.6fs",
+		"Average per dataset:  N/A (no datasets)":                 "每个数据集平均：不适用（无数据集）",
+		"Cached results:       %d of %d (use -no-cache to refit)": "缓存结果：%d / %d（使用 -no-cache 重新拟合）",
+		"Comparison":                                              "比较",
+		"Common Slope (ANCOVA)":                                   "共同斜率（ANCOVA）",
+		"Manifest":                                                "运行清单",
+		"Golden File (%s)":                                        "基准文件（%s）",
+		"Time Series Regression Analysis":                         "时间序列回归分析",
+
+		// HTML report
+		"Reproducibility":         "可复现性",
+		"Build":                   "构建",
+		"Created":                 "创建时间",
+		"Seed (%s)":               "随机种子（%s）",
+		"Point":                   "点",
+		"Residual":                "残差",
+		"Leverage":                "杠杆值",
+		"Cook's distance":         "Cook 距离",
+		"Slope change if removed": "移除后的斜率变化",
+		"Data and fit with %g%% confidence and prediction bands":           "数据与拟合（%g%% 置信带和预测带）",
+		"Data and fit with %g%% bootstrap confidence and prediction bands": "数据与拟合（%g%% bootstrap 置信带和预测带）",
+		"Distribution of %s":        "%s 的分布",
+		"Distribution of residuals": "残差分布",
+
+		// advice
+		"a single point drives this fit; collect data across the X range before trusting the slope": "单个点决定了这次拟合；在信任斜率之前，请在整个 X 范围内收集数据",
+		"point %d at x = %g has leverage %.2f":                                                      "x = %[2]g 处的第 %[1]d 个点杠杆值为 %.2[3]f",
+		"an outlier pulls the line; use a robust fit (Theil-Sen or Huber) or check that point":      "一个离群点拉偏了直线；请使用稳健拟合（Theil-Sen 或 Huber）或检查该点",
+		"point %d at x = %g has studentized residual %.3g":                                          "x = %[2]g 处的第 %[1]d 个点学生化残差为 %.3[3]g",
+		"robust fits disagree with least squares; use a robust fit":                                 "稳健拟合与最小二乘结果不一致；请使用稳健拟合",
+		"slopes span %.2f standard errors":                                                          "斜率相差 %.2f 个标准误",
+		"the relationship curves; use a quadratic fit (multi -degree 2)":                            "关系呈曲线；请使用二次拟合（multi -degree 2）",
+		"quadratic term p = %.3g":                                                                   "二次项 p = %.3g",
+		"the line misses the means of the replicated X values; try a curve or a transform":          "直线偏离了重复 X 值的均值；请尝试曲线或变换",
+		"lack of fit F(%d, %d) = %.3g, p = %.3g":                                                    "失拟检验 F(%d, %d) = %.3g，p = %.3g",
+		"the straight line looks adequate":                                                          "直线拟合看起来合适",
+		"no high-leverage points, outliers or curvature among %d points":                            "%d 个点中没有高杠杆点、离群点或曲率",
+	},
+}
+
+// languageNames lists the report languages for usage messages
+func languageNames() string {
+	return strings.Join(append([]string{"en"}, slices.Sorted(maps.Keys(messageCatalog))...), ", ")
+}
+
+// ParseLanguage returns the messages of a language by its code. Region and encoding suffixes, as in
+// "es_MX.UTF-8" or "zh-CN", are ignored. English and the empty code return the zero Messages.
+func ParseLanguage(lang string) (Messages, error) {
+	code, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(lang)), ".")
+	code, _, _ = strings.Cut(strings.ReplaceAll(code, "_", "-"), "-")
+	if code == "" || code == "en" {
+		return Messages{}, nil
+	}
+	table, ok := messageCatalog[code]
+	if !ok {
+		return Messages{}, fmt.Errorf("unknown language %q (use %s)", lang, languageNames())
+	}
+	return Messages{Lang: code, table: table}, nil
+}
+
+// Text returns the translation of s, or s itself
+func (m Messages) Text(s string) string {
+	if t, ok := m.table[s]; ok {
+		return t
+	}
+	return s
+}
+
+// Sprintf formats with the translation of format
+func (m Messages) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(m.Text(format), args...)
+}
+
+// label returns a report line's translated label, indented and padded so that values line up at
+// column 13 as in "  Slope:     ". Han characters take two columns in a terminal.
+func (m Messages) label(name string) string {
+	name = m.Text(name) + ":"
+	width := 0
+	for _, r := range name {
+		width++
+		if unicode.Is(unicode.Han, r) {
+			width++
+		}
+	}
+	return "  " + name + strings.Repeat(" ", max(11-width, 1))
+}
+
+// htmlLang is the language of HTML reports
+func (m Messages) htmlLang() string {
+	if m.Lang == "" {
+		return "en"
+	}
+	return m.Lang
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Languages are found by code with region and encoding suffixes ignored
+func TestParseLanguage(t *testing.T) {
+	for lang, want := range map[string]string{"": "", "en": "", "en_US.UTF-8": "", "es": "es", "es_MX.UTF-8": "es", "zh-CN": "zh", "ZH": "zh"} {
+		m, err := ParseLanguage(lang)
+		if err != nil || m.Lang != want {
+			t.Errorf("%q: expected language %q, got %q (err %v)", lang, want, m.Lang, err)
+		}
+	}
+	if _, err := ParseLanguage("xx"); err == nil || !strings.Contains(err.Error(), "en, es, zh") {
+		t.Errorf("expected an unknown language error listing the languages, got %v", err)
+	}
+	if got := (Messages{}).Sprintf("Dataset %s:", "I"); got != "Dataset I:" {
+		t.Errorf("expected English from the zero Messages, got %q", got)
+	}
+}
+
+// formatVerbs returns the verbs of a format string without their argument indexes, sorted
+func formatVerbs(format string) []string {
+	verbs := regexp.MustCompile(`%[-+# 0]*(\[\d+\])?\d*(\.\d*)?(\[\d+\])?[a-zA-Z%]`).FindAllString(format, -1)
+	index := regexp.MustCompile(`\[\d+\]`)
+	for i, v := range verbs {
+		verbs[i] = index.ReplaceAllString(v, "")
+	}
+	slices.Sort(verbs)
+	return verbs
+}
+
+// ✅ Test 2: Every language translates the same text and keeps each message's format verbs
+func TestMessageCatalog(t *testing.T) {
+	keys := slices.Sorted(maps.Keys(messageCatalog["es"]))
+	for lang, table := range messageCatalog {
+		if got := slices.Sorted(maps.Keys(table)); !slices.Equal(got, keys) {
+			t.Errorf("%s: translates a different set of messages than es", lang)
+		}
+		for english, translated := range table {
+			if !slices.Equal(formatVerbs(english), formatVerbs(translated)) {
+				t.Errorf("%s: %q has verbs %v, want %v", lang, translated, formatVerbs(translated), formatVerbs(english))
+			}
+		}
+	}
+}
+
+// ✅ Test 3: Advice is localized with its evidence reformatted, reordered where the language needs it
+func TestAdviceLocalize(t *testing.T) {
+	advice, err := Advise(LoadAnscombeDatasets()["IV"])
+	if err != nil || len(advice) == 0 || advice[0].Kind != AdviceSinglePoint {
+		t.Fatalf("expected single-point advice for quartet IV, got %v (err %v)", advice, err)
+	}
+	zh, _ := ParseLanguage("zh")
+	local := advice[0].Localize(zh)
+	if !strings.Contains(local.Message, "单个点") || !strings.HasPrefix(local.Evidence, "x = 19 处的第 8 个点") || strings.Contains(local.Evidence, "%!") {
+		t.Errorf("unexpected localized advice %+v", local)
+	}
+	if local.Kind != advice[0].Kind {
+		t.Errorf("expected the kind to stay %q, got %q", advice[0].Kind, local.Kind)
+	}
+	if english := advice[0].Localize(Messages{}); english.Message != advice[0].Message || english.Evidence != advice[0].Evidence {
+		t.Errorf("expected English advice unchanged, got %+v", english)
+	}
+
+	var buf bytes.Buffer
+	es, _ := ParseLanguage("es")
+	printAdvice(&buf, LoadAnscombeDatasets()["I"], es)
+	if got := buf.String(); got != "  Consejo:   la recta parece adecuada (ni puntos de alto apalancamiento, ni valores atípicos, ni curvatura entre 11 puntos)\n" {
+		t.Errorf("unexpected Spanish advice %q", got)
+	}
+}
+
+// ✅ Test 4: Report labels line their values up whatever the language
+func TestMessagesLabel(t *testing.T) {
+	es, _ := ParseLanguage("es")
+	zh, _ := ParseLanguage("zh")
+	cases := []struct{ got, want string }{
+		{(Messages{}).label("Slope"), "  Slope:     "},
+		{(Messages{}).label("Intercept"), "  Intercept: "},
+		{es.label("Slope"), "  Pendiente: "},
+		{zh.label("Slope"), "  斜率:      "},
+		{es.label("Most influential points"), "  Puntos más influyentes: "},
+	}
+	for _, c := range cases {
+		if got, want := c.got, c.want; got != want {
+			t.Errorf("expected label %q, got %q", want, got)
+		}
+	}
+}
+
+// reportHeadings are the headings of the console and HTML reports, which every language translates
+var reportHeadings = []string{
+	"%s Regression Analysis", "Time Series Regression Analysis", "Dataset %s:", "Comparison",
+	"Common Slope (ANCOVA)", "Manifest", "Golden File (%s)", "Summary", "Most influential points",
+	"Reproducibility", "Point", "Residual", "Leverage", "Cook's distance", "Slope change if removed",
+	"Data and fit with %g%% confidence and prediction bands", "Data and fit with %g%% bootstrap confidence and prediction bands",
+	"Distribution of %s", "Distribution of residuals",
+}
+
+// ✅ Test 5: Every heading of the console and HTML reports is written in the report language
+func TestMessageHeadings(t *testing.T) {
+	dir := t.TempDir()
+	groups := filepath.Join(dir, "groups.csv")
+	series := filepath.Join(dir, "series.csv")
+	golden := filepath.Join(dir, "golden.json")
+	if err := os.WriteFile(groups, []byte("x,y,g\n1,2,a\n2,4.1,a\n3,5.9,a\n4,8.2,a\n1,1,b\n2,2.1,b\n3,2.9,b\n4,4.2,b\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(series, []byte("t,y\n2024-03-01,1\n2024-03-02,2.1\n2024-03-03,2.9\n2024-03-04,4.2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-no-cache", "-group-by", "g", "-ancova", "-golden", golden}
+	captureStdout(t, func() {
+		if err := runAnalysis(append(args, "-update-golden", groups)); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	data := LoadAnscombeDatasets()["IV"]
+	result, err := AnalyzeDataset("IV", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := NewManifest(analysisEngine)
+	heading := regexp.MustCompile(`(?m)^=== (.*) ===$`)
+
+	for lang := range messageCatalog {
+		m, _ := ParseLanguage(lang)
+		for _, h := range reportHeadings {
+			if m.Text(h) == h {
+				t.Errorf("%s: heading %q is not translated", lang, h)
+			}
+		}
+
+		out := captureStdout(t, func() {
+			if err := runAnalysis(append(args, "-lang", lang, groups)); err != nil {
+				t.Error(err)
+			}
+			if err := runAnalysis([]string{"-time", "-lang", lang, series}); err != nil {
+				t.Error(err)
+			}
+		})
+		var got []string
+		for _, f := range heading.FindAllStringSubmatch(out, -1) {
+			got = append(got, f[1])
+		}
+		want := []string{
+			m.Sprintf("%s Regression Analysis", m.Text("Grouped")), m.Text("Comparison"), m.Text("Common Slope (ANCOVA)"),
+			m.Text("Manifest"), m.Sprintf("Golden File (%s)", golden), m.Text("Summary"), m.Text("Time Series Regression Analysis"),
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: expected console headings %q, got %q", lang, want, got)
+		}
+		if !strings.Contains(out, m.Sprintf("Dataset %s:", series)) {
+			t.Errorf("%s: expected the time series dataset heading", lang)
+		}
+
+		var buf bytes.Buffer
+		opts := HTMLReportOptions{Manifest: &manifest, Messages: m}
+		if err := WriteHTMLReport(&buf, "Report", []HTMLReportDataset{{Name: "IV", Data: data, Result: result}}, opts); err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range reportHeadings {
+			if strings.Contains(h, "%") || !strings.Contains(buf.String(), ">"+h+"<") {
+				continue
+			}
+			t.Errorf("%s: HTML heading %q is in English", lang, h)
+		}
+		for _, want := range []string{m.Text("Reproducibility"), m.Text("Cook's distance"), m.Sprintf("Data and fit with %g%% confidence and prediction bands", 100*scatterBandLevel), m.Text("Distribution of residuals")} {
+			if !strings.Contains(buf.String(), want) {
+				t.Errorf("%s: expected %q in the HTML report", lang, want)
+			}
+		}
+	}
+}
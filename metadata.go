@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// validateMetadata checks that every optional per-point slice matches the number of points
+func (d Dataset) validateMetadata() error {
+	n := len(d.X)
+	if d.Weights != nil && len(d.Weights) != n {
+		return fmt.Errorf("dataset has %d points but %d weights", n, len(d.Weights))
+	}
+	if d.Labels != nil && len(d.Labels) != n {
+		return fmt.Errorf("dataset has %d points but %d labels", n, len(d.Labels))
+	}
+	if d.Tags != nil && len(d.Tags) != n {
+		return fmt.Errorf("dataset has %d points but %d tag lists", n, len(d.Tags))
+	}
+	return nil
+}
+
+// Label returns the label of point i, or "" when the dataset has none
+func (d Dataset) Label(i int) string {
+	if i < len(d.Labels) {
+		return d.Labels[i]
+	}
+	return ""
+}
+
+// appendPoint copies point i of src, with whatever metadata src carries, to the end of d
+func (d *Dataset) appendPoint(src Dataset, i int) {
+	d.X = append(d.X, src.X[i])
+	d.Y = append(d.Y, src.Y[i])
+	if src.Weights != nil {
+		d.Weights = append(d.Weights, src.Weights[i])
+	}
+	if src.Labels != nil {
+		d.Labels = append(d.Labels, src.Labels[i])
+	}
+	if src.Tags != nil {
+		d.Tags = append(d.Tags, src.Tags[i])
+	}
+}
+
+// DatasetDiagnostics is PointDiagnostics for a dataset, attaching each point's label and tags so
+// flagged points can be identified by name rather than only by index
+func DatasetDiagnostics(d Dataset, slope, intercept float64) []PointDiagnostic {
+	diags := PointDiagnostics(d.X, d.Y, slope, intercept)
+	for i := range diags {
+		idx := diags[i].Index
+		diags[i].Label = d.Label(idx)
+		if idx < len(d.Tags) {
+			diags[i].Tags = d.Tags[idx]
+		}
+	}
+	return diags
+}
+
+// Axis names a dataset variable and the unit it is measured in. Both fields are optional.
+type Axis struct {
+	Label string `json:"label,omitempty"`
+	Unit  string `json:"unit,omitempty"`
+}
+
+// ParseAxis splits a column header such as "dose (mg)" or "dose [mg]" into label and unit.
+// Headers without a trailing bracketed unit are labels only.
+func ParseAxis(header string) Axis {
+	header = strings.TrimSpace(header)
+	for _, pair := range []string{"()", "[]"} {
+		if !strings.HasSuffix(header, pair[1:]) {
+			continue
+		}
+		if open := strings.LastIndexByte(header, pair[0]); open >= 0 {
+			return Axis{
+				Label: strings.TrimSpace(header[:open]),
+				Unit:  strings.TrimSpace(header[open+1 : len(header)-1]),
+			}
+		}
+	}
+	return Axis{Label: header}
+}
+
+// String formats the axis the way ParseAxis reads it, e.g. "dose (mg)"
+func (a Axis) String() string {
+	switch {
+	case a.Unit == "":
+		return a.Label
+	case a.Label == "":
+		return "(" + a.Unit + ")"
+	}
+	return a.Label + " (" + a.Unit + ")"
+}
+
+// or returns a, or fallback when a is empty
+func (a Axis) or(fallback Axis) Axis {
+	if a == (Axis{}) {
+		return fallback
+	}
+	return a
+}
+
+// SlopeUnit returns the unit of the fitted slope, Y's unit per X's unit (e.g. "mg/day").
+// It is empty when neither axis has a unit.
+func (d Dataset) SlopeUnit() string {
+	x, y := d.XAxis.Unit, d.YAxis.Unit
+	switch {
+	case x == "":
+		return y
+	case y == "":
+		return "1/" + x
+	}
+	return y + "/" + x
+}
+
+// AxesLabel describes the axes as "y vs x" for report headings, or "" when neither is named
+func (d Dataset) AxesLabel() string {
+	if d.XAxis == (Axis{}) && d.YAxis == (Axis{}) {
+		return ""
+	}
+	x, y := d.XAxis.String(), d.YAxis.String()
+	if x == "" {
+		x = "x"
+	}
+	if y == "" {
+		y = "y"
+	}
+	return y + " vs " + x
+}
+
+// checkUnits reports an error when two axes both carry units and the units differ
+func checkUnits(axis string, a, b Axis) error {
+	if a.Unit != "" && b.Unit != "" && a.Unit != b.Unit {
+		return fmt.Errorf("%s units differ: %q vs %q", axis, a.Unit, b.Unit)
+	}
+	return nil
+}
+
+// withUnit formats a fitted value for reports, followed by its unit when it has one
+func withUnit(v float64, unit string) string {
+	if unit == "" {
+		return fmt.Sprintf("%.6f", v)
+	}
+	return fmt.Sprintf("%.6f %s", v, unit)
+}
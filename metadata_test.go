@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Labels and tags are attached to point diagnostics
+func TestDatasetDiagnosticsLabels(t *testing.T) {
+	data := Dataset{
+		X:      []float64{1, 2, 3, 4},
+		Y:      []float64{2, 4, 9, 8},
+		Labels: []string{"a", "b", "c", "d"},
+		Tags:   [][]string{nil, nil, {"suspect"}, nil},
+	}
+	diags := DatasetDiagnostics(data, 2, 0)
+	if diags[2].Label != "c" || !slices.Equal(diags[2].Tags, []string{"suspect"}) || diags[0].Tags != nil {
+		t.Errorf("unexpected diagnostics: %+v", diags)
+	}
+}
+
+// ✅ Test 2: AnalyzeDataset honors weights and rejects mismatched metadata
+func TestAnalyzeDatasetWeights(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 3, 4}, Y: []float64{2, 4, 6, 20}, Weights: []float64{1, 1, 1, 0}}
+	result, err := AnalyzeDataset("weighted", data)
+	if err != nil || math.Abs(result.Slope-2) > 1e-12 || math.Abs(result.Intercept) > 1e-12 {
+		t.Errorf("weights ignored: %+v (err %v)", result, err)
+	}
+
+	data.Labels = []string{"only one"}
+	if _, err := AnalyzeDataset("bad", data); err == nil {
+		t.Error("expected a label length error")
+	}
+}
+
+// ✅ Test 3: Filtering, merging and appending keep metadata aligned with points
+func TestMetadataFollowsPoints(t *testing.T) {
+	data := LoadAnscombeDatasets()["III"]
+	data.Labels = make([]string, len(data.X))
+	for i := range data.Labels {
+		data.Labels[i] = string(rune('a' + i))
+	}
+
+	cleaned, removed, err := data.FilterOutliers(OutlierIQR, 0)
+	if err != nil || !slices.Equal(removed, []int{2}) || len(cleaned.Labels) != 10 || cleaned.Labels[2] != "d" {
+		t.Fatalf("labels misaligned after filtering: %v (err %v)", cleaned.Labels, err)
+	}
+
+	merged, err := Merge(Dataset{X: []float64{1}, Y: []float64{1}}, cleaned)
+	if err != nil || len(merged.Labels) != 11 || merged.Labels[0] != "" || merged.Labels[1] != "a" {
+		t.Errorf("unexpected merged labels: %v (err %v)", merged.Labels, err)
+	}
+
+	appended := Append(merged, 20, 20)
+	if len(appended.Labels) != 12 || appended.Weights != nil {
+		t.Errorf("unexpected appended metadata: %+v", appended)
+	}
+}
+
+// ✅ Test 4: Column headers become axes and give the slope its unit
+func TestAxesFromHeader(t *testing.T) {
+	data, err := LoadCSVDataset(strings.NewReader("time [day],dose (mg)\n0,1\n2,2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data.XAxis != (Axis{Label: "time", Unit: "day"}) || data.YAxis != (Axis{Label: "dose", Unit: "mg"}) {
+		t.Fatalf("unexpected axes: %+v %+v", data.XAxis, data.YAxis)
+	}
+	if unit := data.SlopeUnit(); unit != "mg/day" {
+		t.Errorf("expected mg/day, got %q", unit)
+	}
+	if label := data.AxesLabel(); label != "dose (mg) vs time (day)" {
+		t.Errorf("unexpected axes label %q", label)
+	}
+	if got := withUnit(0.5, data.SlopeUnit()); got != "0.500000 mg/day" {
+		t.Errorf("unexpected formatted slope %q", got)
+	}
+
+	if axis := ParseAxis("x"); axis != (Axis{Label: "x"}) || (Dataset{}).SlopeUnit() != "" {
+		t.Errorf("plain header should be a label only, got %+v", axis)
+	}
+}
+
+// ✅ Test 5: Merging checks units only when asked, and an axis without a unit matches any
+func TestMergeCheckUnits(t *testing.T) {
+	mg := Dataset{X: []float64{1}, Y: []float64{1}, YAxis: Axis{Label: "dose", Unit: "mg"}}
+	g := Dataset{X: []float64{2}, Y: []float64{2}, YAxis: Axis{Label: "dose", Unit: "g"}}
+	bare := Dataset{X: []float64{3}, Y: []float64{3}}
+
+	if _, err := MergeWithOptions(mg, g, MergeOptions{CheckUnits: true}); err == nil {
+		t.Error("expected a unit mismatch error")
+	}
+	if _, err := Merge(mg, g); err != nil {
+		t.Errorf("unchecked merge failed: %v", err)
+	}
+	merged, err := MergeWithOptions(bare, mg, MergeOptions{CheckUnits: true})
+	if err != nil || merged.YAxis.Unit != "mg" {
+		t.Errorf("expected the mg axis to be kept, got %+v (err %v)", merged.YAxis, err)
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ModelKind names the kind of fit a Model holds
+type ModelKind string
+
+const (
+	ModelLinear   ModelKind = "linear"
+	ModelMultiple ModelKind = "multiple"
+	ModelPower    ModelKind = "power"
+)
+
+// ModelFormat selects the encoding Model.Save writes; LoadModel detects it
+type ModelFormat string
+
+const (
+	ModelJSON ModelFormat = "json"
+	ModelGob  ModelFormat = "gob"
+)
+
+// modelVersion is written into every saved model and bumped when a change to Model would make
+// older files load incorrectly; LoadModel refuses files from newer versions
+const modelVersion = 1
+
+// Model is a fitted regression saved for prediction elsewhere, for example fitted by a batch run
+// and loaded by `serve -model`. Exactly one of Line, Multi and Power is set, according to Kind;
+// the other fields record how it was trained.
+type Model struct {
+	Version int       `json:"version"`
+	Kind    ModelKind `json:"kind"`
+	Name    string    `json:"name"`
+
+	Line  *RegressionResult `json:"line,omitempty"`
+	Multi *MultiFit         `json:"multi,omitempty"`
+	Power *PowerFit         `json:"power,omitempty"`
+
+	// N is the number of points the model was fitted to; a linear model's Line also records their
+	// fingerprint
+	N     int  `json:"n"`
+	XAxis Axis `json:"xAxis"`
+	YAxis Axis `json:"yAxis"`
+	// Trained is when the model was fitted, Build the module version that fitted it, and Options
+	// the command-line flags it was fitted with
+	Trained time.Time         `json:"trained"`
+	Build   string            `json:"build,omitempty"`
+	Options map[string]string `json:"options,omitempty"`
+	// Stats give PredictInterval its intervals; they are missing from models fitted to too few points
+	Stats *PredictionStats `json:"stats,omitempty"`
+	// Ranges are the spans of the inputs in the training data, in Inputs order, beyond which
+	// predictions are extrapolations (see Model.Extrapolation)
+	Ranges []InputRange `json:"ranges,omitempty"`
+}
+
+// NewLinearModel wraps the line fitted to ds
+func NewLinearModel(result RegressionResult, ds Dataset) Model {
+	x, _, _ := cleanPairs(nil, nil, ds.X, ds.Y)
+	return newModel(ModelLinear, result.Dataset, len(x), func(m *Model) {
+		m.Line = &result
+		m.XAxis, m.YAxis = ds.XAxis, ds.YAxis
+		m.Stats = linePredictionStats(ds.X, ds.Y, result.Slope, result.Intercept)
+		m.Ranges = inputRanges(m.Inputs(), [][]float64{x})
+	})
+}
+
+// NewMultipleModel wraps a multiple regression fitted to md
+func NewMultipleModel(name string, fit MultiFit, md MultiDataset) Model {
+	return newModel(ModelMultiple, name, fit.N, func(m *Model) {
+		m.Multi = &fit
+		if fit.Penalty == nil {
+			// shrunken coefficients are biased, so the least squares intervals would not hold
+			m.Stats = multiPredictionStats(fit, md)
+		}
+		cols := make([][]float64, len(m.Inputs()))
+		for i, row := range md.X {
+			if len(row) != len(cols) || hasMissing(row) || isMissing(md.Y[i]) {
+				continue
+			}
+			for j, v := range row {
+				cols[j] = append(cols[j], v)
+			}
+		}
+		m.Ranges = inputRanges(m.Inputs(), cols)
+	})
+}
+
+// NewPowerModel wraps a fit on power-transformed data, predicting on the original scale
+func NewPowerModel(name string, fit PowerFit, ds Dataset) Model {
+	x, _, _ := cleanPairs(nil, nil, ds.X, ds.Y)
+	return newModel(ModelPower, name, len(x), func(m *Model) {
+		m.Power = &fit
+		m.XAxis, m.YAxis = ds.XAxis, ds.YAxis
+		m.Ranges = inputRanges(m.Inputs(), [][]float64{x})
+		if len(ds.X) == len(ds.Y) {
+			tx, ty := make([]float64, len(ds.X)), make([]float64, len(ds.Y))
+			for i := range ds.X {
+				tx[i], ty[i] = fit.X.Apply(ds.X[i]), fit.Y.Apply(ds.Y[i])
+			}
+			m.Stats = linePredictionStats(tx, ty, fit.Slope, fit.Intercept)
+		}
+	})
+}
+
+func newModel(kind ModelKind, name string, n int, set func(*Model)) Model {
+	m := Model{Version: modelVersion, Kind: kind, Name: name, N: n, Trained: time.Now().UTC(), Build: NewManifest("").Version}
+	set(&m)
+	return m
+}
+
+// Inputs names the values Predict takes, in order
+func (m Model) Inputs() []string {
+	switch {
+	case m.Multi != nil && m.Multi.Expansion != nil:
+		return m.Multi.Expansion.Inputs
+	case m.Multi != nil && m.Multi.PCA != nil:
+		return m.Multi.PCA.Names
+	case m.Multi != nil:
+		return m.Multi.Names
+	case m.XAxis.Label != "":
+		return []string{m.XAxis.Label}
+	}
+	return []string{"x"}
+}
+
+// Predict evaluates the model at one observation of its Inputs
+func (m Model) Predict(x ...float64) (float64, error) {
+	if want := len(m.Inputs()); len(x) != want {
+		return 0, fmt.Errorf("model %s takes %d inputs (%s), got %d", m.Name, want, strings.Join(m.Inputs(), ", "), len(x))
+	}
+	switch m.Kind {
+	case ModelLinear:
+		return m.Line.Intercept + m.Line.Slope*x[0], nil
+	case ModelMultiple:
+		return m.Multi.Predict(x), nil
+	case ModelPower:
+		return m.Power.Predict(x[0]), nil
+	}
+	return 0, fmt.Errorf("unknown model kind %q", m.Kind)
+}
+
+// validate checks that a decoded model is complete and can be used by this build
+func (m Model) validate() error {
+	if m.Version < 1 || m.Version > modelVersion {
+		return fmt.Errorf("unsupported model version %d (this build reads up to %d)", m.Version, modelVersion)
+	}
+	var ok bool
+	switch m.Kind {
+	case ModelLinear:
+		ok = m.Line != nil && !math.IsNaN(m.Line.Slope) && !math.IsNaN(m.Line.Intercept)
+	case ModelMultiple:
+		ok = m.Multi != nil && len(m.Multi.Coefficients) == len(m.Multi.Names)+1
+	case ModelPower:
+		ok = m.Power != nil
+	default:
+		return fmt.Errorf("unknown model kind %q", m.Kind)
+	}
+	if !ok {
+		return fmt.Errorf("%s model has no usable coefficients", m.Kind)
+	}
+	if m.Stats != nil {
+		// PredictInterval multiplies the covariance by the design row, an intercept and one term
+		// per coefficient
+		k := 2
+		if m.Kind == ModelMultiple {
+			k = len(m.Multi.Coefficients)
+		}
+		if len(m.Stats.Covariance) != k {
+			return fmt.Errorf("%s model has a covariance matrix of %d rows for %d coefficients", m.Kind, len(m.Stats.Covariance), k)
+		}
+		for i, row := range m.Stats.Covariance {
+			if len(row) != k {
+				return fmt.Errorf("%s model has %d columns in row %d of its covariance matrix, expected %d", m.Kind, len(row), i+1, k)
+			}
+		}
+	}
+	return nil
+}
+
+// Save writes the model to w as indented JSON or as gob
+func (m Model) Save(w io.Writer, format ModelFormat) error {
+	switch format {
+	case ModelJSON, "":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m)
+	case ModelGob:
+		return gob.NewEncoder(w).Encode(m)
+	}
+	return fmt.Errorf("unknown model format %q (use json or gob)", format)
+}
+
+// LoadModel reads a model written by Model.Save in either format: JSON starts with '{', anything
+// else is decoded as gob
+func LoadModel(r io.Reader) (Model, error) {
+	br := bufio.NewReader(r)
+	var first []byte
+	for {
+		var err error
+		if first, err = br.Peek(1); err != nil {
+			return Model{}, fmt.Errorf("reading model: %w", err)
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(first[0])) {
+			break
+		}
+		br.ReadByte()
+	}
+	var m Model
+	var err error
+	if first[0] == '{' {
+		err = json.NewDecoder(br).Decode(&m)
+	} else {
+		err = gob.NewDecoder(br).Decode(&m)
+	}
+	if err != nil {
+		return Model{}, fmt.Errorf("decoding model: %w", err)
+	}
+	if err := m.validate(); err != nil {
+		return Model{}, err
+	}
+	return m, nil
+}
+
+// SaveModelFile writes the model to path, as gob when the extension is .gob and as JSON otherwise
+func SaveModelFile(path string, m Model) error {
+	format := ModelJSON
+	if filepath.Ext(path) == ".gob" {
+		format = ModelGob
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := m.Save(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// LoadModelFile reads a model saved by SaveModelFile
+func LoadModelFile(path string) (Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Model{}, err
+	}
+	defer f.Close()
+	m, err := LoadModel(f)
+	if err != nil {
+		return Model{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// modelFileName turns a dataset name such as "data/a.csv[site=b]" into a file name
+func modelFileName(name string) string {
+	safe := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '_'
+	}, name)
+	return strings.Trim(safe, ".") + ".json"
+}
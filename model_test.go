@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Linear, multiple and power models predict the same after a JSON or gob round trip
+func TestModelRoundTrip(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	ds.XAxis = Axis{Label: "dose", Unit: "mg"}
+	result, err := AnalyzeDataset("I", ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 20; i++ {
+		a, b := float64(i), float64(i*i%7)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+2*a-b+0.5*a*b)
+	}
+	multi, err := FitExpanded(md, FeatureOptions{Degree: 1, Interactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	power, err := FitPower(ds, PowerOptions{X: PowerLog, Y: PowerLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	models := []struct {
+		model  Model
+		inputs []float64
+		want   float64
+	}{
+		{NewLinearModel(result, ds), []float64{10}, result.Intercept + 10*result.Slope},
+		{NewMultipleModel("plane", multi, md), []float64{3, 4}, 1 + 6 - 4 + 6},
+		{NewPowerModel("I", power, ds), []float64{10}, power.Predict(10)},
+	}
+	for _, c := range models {
+		for _, format := range []ModelFormat{ModelJSON, ModelGob} {
+			var buf bytes.Buffer
+			if err := c.model.Save(&buf, format); err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := LoadModel(&buf)
+			if err != nil {
+				t.Fatalf("%s model as %s: %v", c.model.Kind, format, err)
+			}
+			if loaded.Kind != c.model.Kind || loaded.N != c.model.N || !loaded.Trained.Equal(c.model.Trained) {
+				t.Errorf("%s model as %s: metadata changed to %+v", c.model.Kind, format, loaded)
+			}
+			y, err := loaded.Predict(c.inputs...)
+			if err != nil || math.Abs(y-c.want) > 1e-9 {
+				t.Errorf("%s model as %s: predicted %v, %v; want %v", c.model.Kind, format, y, err, c.want)
+			}
+		}
+	}
+	if got := models[0].model.Inputs(); len(got) != 1 || got[0] != "dose" {
+		t.Errorf("linear model inputs = %v", got)
+	}
+	if _, err := models[1].model.Predict(1); err == nil || !strings.Contains(err.Error(), "takes 2 inputs (a, b)") {
+		t.Errorf("expected an input count error, got %v", err)
+	}
+}
+
+// ✅ Test 2: Truncated, unknown, newer and inconsistent models are rejected
+func TestLoadModelErrors(t *testing.T) {
+	for name, body := range map[string]string{
+		"empty":      "",
+		"garbage":    "not a model",
+		"newer":      `{"version": 2, "kind": "linear", "line": {"slope": 1}}`,
+		"kind":       `{"version": 1, "kind": "spline"}`,
+		"missing":    `{"version": 1, "kind": "multiple"}`,
+		"covariance": `{"version": 1, "kind": "linear", "line": {"slope": 1}, "stats": {"df": 3, "residualSE": 1, "covariance": [[1]]}}`,
+		"ragged":     `{"version": 1, "kind": "linear", "line": {"slope": 1}, "stats": {"df": 3, "residualSE": 1, "covariance": [[1, 0], [0]]}}`,
+		"multiple":   `{"version": 1, "kind": "multiple", "multi": {"names": ["a"], "coefficients": [1, 2]}, "stats": {"df": 3, "residualSE": 1, "covariance": [[1, 0, 0], [0, 1, 0], [0, 0, 1]]}}`,
+	} {
+		if _, err := LoadModel(strings.NewReader(body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+	// with a covariance of the right shape the same model gives intervals
+	valid := `{"version": 1, "kind": "linear", "line": {"slope": 1}, "stats": {"df": 3, "residualSE": 1, "covariance": [[1, 0], [0, 1]]}}`
+	if m, err := LoadModel(strings.NewReader(valid)); err != nil {
+		t.Errorf("valid model: %v", err)
+	} else if _, err := m.PredictInterval(0.95, 2); err != nil {
+		t.Errorf("valid model: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), modelFileName("data/a.csv[site=b]"))
+	if filepath.Base(path) != "data_a.csv_site_b_.json" {
+		t.Errorf("unexpected file name %s", filepath.Base(path))
+	}
+	model := NewLinearModel(RegressionResult{Dataset: "a", Slope: 2, Intercept: 1}, Dataset{X: []float64{0, 1}, Y: []float64{1, 3}})
+	if err := SaveModelFile(path, model); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err := LoadModelFile(path); err != nil || loaded.Line.Slope != 2 {
+		t.Errorf("loaded %+v, %v", loaded, err)
+	}
+}
+
+// ✅ Test 3: POST /predict evaluates a loaded model for values and rows
+func TestPredictEndpoint(t *testing.T) {
+	model := NewLinearModel(RegressionResult{Dataset: "line", Slope: 2, Intercept: 1}, Dataset{X: []float64{0, 1}, Y: []float64{1, 3}})
+	srv := httptest.NewServer(NewServer(ServerOptions{Models: map[string]Model{"line": model}}))
+	defer srv.Close()
+
+	post := func(body string) (int, PredictResponse) {
+		resp, err := http.Post(srv.URL+"/predict", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out PredictResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	if code, out := post(`{"x": [0, 1.5], "rows": [[10]]}`); code != http.StatusOK || out.Model != "line" || len(out.Predictions) != 3 || out.Predictions[0] != 21 || out.Predictions[2] != 4 {
+		t.Errorf("got %d %+v", code, out)
+	}
+	if code, _ := post(`{"model": "other", "x": [1]}`); code != http.StatusNotFound {
+		t.Errorf("unknown model: got status %d", code)
+	}
+	if code, _ := post(`{"rows": [[1, 2]]}`); code != http.StatusBadRequest {
+		t.Errorf("wrong input count: got status %d", code)
+	}
+}
@@ -0,0 +1,276 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MQTTClient is a minimal MQTT 3.1.1 client: QoS 0 publish/subscribe plus keep-alive,
+// which is all the telemetry ingestion needs without pulling in a full client library.
+type MQTTClient struct {
+	conn     net.Conn
+	reader   *bufio.Reader
+	writeMu  sync.Mutex
+	packetID uint16
+	done     chan struct{}
+	closed   sync.Once
+}
+
+// MQTTOptions configures the CONNECT handshake
+type MQTTOptions struct {
+	ClientID  string
+	Username  string
+	Password  string
+	KeepAlive time.Duration
+}
+
+// MQTTMessage is an application message received from the broker
+type MQTTMessage struct {
+	Topic   string
+	Payload []byte
+}
+
+const (
+	mqttConnect     = 1
+	mqttConnAck     = 2
+	mqttPublish     = 3
+	mqttPubAck      = 4
+	mqttSubscribe   = 8
+	mqttSubAck      = 9
+	mqttPingReq     = 12
+	mqttPingResp    = 13
+	mqttDisconnect  = 14
+	mqttMaxRemLen   = 268435455
+	mqttDefaultPort = "1883"
+)
+
+// DialMQTT connects to broker ("host:port", optionally prefixed with tcp:// or mqtt://) and performs CONNECT
+func DialMQTT(broker string, opts MQTTOptions) (*MQTTClient, error) {
+	addr := strings.TrimPrefix(strings.TrimPrefix(broker, "tcp://"), "mqtt://")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, mqttDefaultPort)
+	}
+
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to broker: %w", err)
+	}
+
+	client, err := NewMQTTClient(conn, opts)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// NewMQTTClient performs the CONNECT handshake over an established connection
+func NewMQTTClient(conn net.Conn, opts MQTTOptions) (*MQTTClient, error) {
+	c := &MQTTClient{conn: conn, reader: bufio.NewReader(conn), done: make(chan struct{})}
+
+	keepAlive := uint16(opts.KeepAlive / time.Second)
+	var body []byte
+	body = appendMQTTString(body, "MQTT")
+	flags := byte(0x02) // clean session
+	if opts.Username != "" {
+		flags |= 0x80
+	}
+	if opts.Password != "" {
+		flags |= 0x40
+	}
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, keepAlive)
+	body = appendMQTTString(body, opts.ClientID)
+	if opts.Username != "" {
+		body = appendMQTTString(body, opts.Username)
+	}
+	if opts.Password != "" {
+		body = appendMQTTString(body, opts.Password)
+	}
+
+	if err := c.writePacket(mqttConnect<<4, body); err != nil {
+		return nil, err
+	}
+
+	header, payload, err := c.readPacket()
+	if err != nil {
+		return nil, fmt.Errorf("waiting for CONNACK: %w", err)
+	}
+	if header>>4 != mqttConnAck || len(payload) != 2 {
+		return nil, fmt.Errorf("expected CONNACK, got packet type %d", header>>4)
+	}
+	if payload[1] != 0 {
+		return nil, fmt.Errorf("broker refused connection (return code %d)", payload[1])
+	}
+
+	if keepAlive > 0 {
+		go c.keepAlive(opts.KeepAlive / 2)
+	}
+	return c, nil
+}
+
+func (c *MQTTClient) keepAlive(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			if err := c.writePacket(mqttPingReq<<4, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Subscribe subscribes to a topic filter at QoS 0 and waits for the SUBACK
+func (c *MQTTClient) Subscribe(filter string) error {
+	c.packetID++
+	id := c.packetID
+
+	body := binary.BigEndian.AppendUint16(nil, id)
+	body = appendMQTTString(body, filter)
+	body = append(body, 0)
+	if err := c.writePacket(mqttSubscribe<<4|0x02, body); err != nil {
+		return err
+	}
+
+	for {
+		header, payload, err := c.readPacket()
+		if err != nil {
+			return fmt.Errorf("waiting for SUBACK: %w", err)
+		}
+		if header>>4 != mqttSubAck {
+			continue
+		}
+		if len(payload) < 3 || binary.BigEndian.Uint16(payload) != id {
+			return fmt.Errorf("malformed SUBACK")
+		}
+		if payload[2] == 0x80 {
+			return fmt.Errorf("broker rejected subscription to %q", filter)
+		}
+		return nil
+	}
+}
+
+// Publish sends a QoS 0 message
+func (c *MQTTClient) Publish(topic string, payload []byte) error {
+	body := appendMQTTString(nil, topic)
+	body = append(body, payload...)
+	return c.writePacket(mqttPublish<<4, body)
+}
+
+// ReadMessage blocks until the next PUBLISH arrives, answering QoS 1 deliveries with PUBACK
+func (c *MQTTClient) ReadMessage() (MQTTMessage, error) {
+	for {
+		header, payload, err := c.readPacket()
+		if err != nil {
+			return MQTTMessage{}, err
+		}
+		if header>>4 != mqttPublish {
+			// PINGRESP and anything else we did not ask for
+			continue
+		}
+
+		if len(payload) < 2 {
+			return MQTTMessage{}, fmt.Errorf("malformed PUBLISH")
+		}
+		topicLen := int(binary.BigEndian.Uint16(payload))
+		if len(payload) < 2+topicLen {
+			return MQTTMessage{}, fmt.Errorf("malformed PUBLISH topic")
+		}
+		msg := MQTTMessage{Topic: string(payload[2 : 2+topicLen])}
+		rest := payload[2+topicLen:]
+
+		if qos := (header >> 1) & 0x03; qos > 0 {
+			if len(rest) < 2 {
+				return MQTTMessage{}, fmt.Errorf("malformed PUBLISH packet id")
+			}
+			if qos == 1 {
+				if err := c.writePacket(mqttPubAck<<4, rest[:2]); err != nil {
+					return MQTTMessage{}, err
+				}
+			}
+			rest = rest[2:]
+		}
+		msg.Payload = rest
+		return msg, nil
+	}
+}
+
+// Close sends DISCONNECT and closes the connection
+func (c *MQTTClient) Close() error {
+	var err error
+	c.closed.Do(func() {
+		close(c.done)
+		c.writePacket(mqttDisconnect<<4, nil)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+func (c *MQTTClient) writePacket(header byte, body []byte) error {
+	if len(body) > mqttMaxRemLen {
+		return fmt.Errorf("packet too large: %d bytes", len(body))
+	}
+	packet := []byte{header}
+	for n := len(body); ; {
+		b := byte(n % 128)
+		n /= 128
+		if n > 0 {
+			b |= 0x80
+		}
+		packet = append(packet, b)
+		if n == 0 {
+			break
+		}
+	}
+	packet = append(packet, body...)
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.conn.Write(packet)
+	return err
+}
+
+func (c *MQTTClient) readPacket() (byte, []byte, error) {
+	header, err := c.reader.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	length, multiplier := 0, 1
+	for i := 0; ; i++ {
+		if i == 4 {
+			return 0, nil, errors.New("malformed remaining length")
+		}
+		b, err := c.reader.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		length += int(b&0x7f) * multiplier
+		multiplier *= 128
+		if b&0x80 == 0 {
+			break
+		}
+	}
+
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	return header, payload, nil
+}
+
+func appendMQTTString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bufio"
+	"net"
+	"testing"
+)
+
+// ✅ Test 1: CONNECT/SUBSCRIBE handshake, QoS 1 delivery with PUBACK, and QoS 0 publish
+func TestMQTTClientExchange(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	broker := &MQTTClient{conn: brokerConn, reader: bufio.NewReader(brokerConn), done: make(chan struct{})}
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- func() error {
+			header, body, err := broker.readPacket()
+			if err != nil || header>>4 != mqttConnect || string(body[2:6]) != "MQTT" {
+				t.Errorf("expected CONNECT, got %d %q (%v)", header>>4, body, err)
+			}
+			broker.writePacket(mqttConnAck<<4, []byte{0, 0})
+
+			header, body, _ = broker.readPacket()
+			if header != mqttSubscribe<<4|0x02 {
+				t.Errorf("expected SUBSCRIBE, got %#x", header)
+			}
+			broker.writePacket(mqttSubAck<<4, []byte{body[0], body[1], 0})
+
+			// QoS 1 publish with packet id 7
+			publish := appendMQTTString(nil, "sensors/a")
+			publish = append(publish, 0, 7)
+			publish = append(publish, `{"x":1,"y":2}`...)
+			broker.writePacket(mqttPublish<<4|0x02, publish)
+
+			header, body, _ = broker.readPacket()
+			if header>>4 != mqttPubAck || body[1] != 7 {
+				t.Errorf("expected PUBACK for id 7, got %#x %v", header, body)
+			}
+
+			header, body, _ = broker.readPacket()
+			if header>>4 != mqttPublish || string(body[2:2+len("alerts")]) != "alerts" {
+				t.Errorf("expected PUBLISH to alerts, got %#x %q", header, body)
+			}
+			return nil
+		}()
+	}()
+
+	client, err := NewMQTTClient(clientConn, MQTTOptions{ClientID: "test"})
+	if err != nil {
+		t.Fatalf("connect failed: %v", err)
+	}
+	if err := client.Subscribe("sensors/#"); err != nil {
+		t.Fatalf("subscribe failed: %v", err)
+	}
+
+	msg, err := client.ReadMessage()
+	if err != nil || msg.Topic != "sensors/a" || string(msg.Payload) != `{"x":1,"y":2}` {
+		t.Fatalf("unexpected message %+v (%v)", msg, err)
+	}
+	if err := client.Publish("alerts", []byte("drift")); err != nil {
+		t.Fatalf("publish failed: %v", err)
+	}
+	<-errs
+	brokerConn.Close()
+}
+
+// ✅ Test 2: Broker refusal is surfaced as an error
+func TestMQTTClientRefused(t *testing.T) {
+	clientConn, brokerConn := net.Pipe()
+	broker := &MQTTClient{conn: brokerConn, reader: bufio.NewReader(brokerConn)}
+	go func() {
+		broker.readPacket()
+		broker.writePacket(mqttConnAck<<4, []byte{0, 5})
+	}()
+
+	if _, err := NewMQTTClient(clientConn, MQTTOptions{ClientID: "test"}); err == nil {
+		t.Error("expected refused connection error")
+	}
+}
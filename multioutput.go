@@ -0,0 +1,212 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"text/tabwriter"
+	"time"
+)
+
+// FitMulti fits a line to each response in ys against the same x, as instruments recording
+// several channels per sample produce. The X-side work (dropping non-finite X, its mean and sum
+// of squares) is done once and shared by every response that is complete wherever X is; a
+// response with its own missing values is fitted to its complete pairs alone. Sums are taken
+// about the means, so the results match ManualRegression to rounding. The Duration of every
+// result is that of the whole call.
+func FitMulti(x []float64, ys [][]float64) ([]RegressionResult, error) {
+	start := time.Now()
+	for k, y := range ys {
+		if len(y) != len(x) {
+			return nil, fmt.Errorf("response %d has %d values for %d x values", k+1, len(y), len(x))
+		}
+	}
+
+	keep := make([]int, 0, len(x))
+	for i, v := range x {
+		if !isMissing(v) {
+			keep = append(keep, i)
+		}
+	}
+	shared := make([]float64, len(keep))
+	for j, i := range keep {
+		shared[j] = x[i]
+	}
+	meanX, sxx := centeredSums(shared)
+
+	results := make([]RegressionResult, len(ys))
+	ry := make([]float64, len(keep))
+	for k, y := range ys {
+		complete := true
+		for j, i := range keep {
+			ry[j] = y[i]
+			complete = complete && !isMissing(y[i])
+		}
+		var err error
+		if complete && len(keep) >= 2 {
+			results[k], err = centeredFit(shared, ry, meanX, sxx)
+		} else {
+			var cx, cy []float64
+			if cx, cy, err = cleanPairs(nil, nil, x, y); err == nil {
+				mx, s := centeredSums(cx)
+				results[k], err = centeredFit(cx, cy, mx, s)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("response %d: %w", k+1, err)
+		}
+	}
+	elapsed := time.Since(start)
+	for k := range results {
+		results[k].Duration = elapsed
+	}
+	return results, nil
+}
+
+// centeredSums returns the mean of x and the sum of squared deviations from it
+func centeredSums(x []float64) (mean, ss float64) {
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	for _, v := range x {
+		ss += (v - mean) * (v - mean)
+	}
+	return mean, ss
+}
+
+// centeredFit fits y against x given the X-side sums. Constant X gives a zero slope, as in
+// ManualRegression.
+func centeredFit(x, y []float64, meanX, sxx float64) (RegressionResult, error) {
+	if len(x) < 2 {
+		return RegressionResult{}, fmt.Errorf("need at least two data points")
+	}
+	meanY, _ := centeredSums(y)
+	var sxy float64
+	for i := range x {
+		sxy += (x[i] - meanX) * (y[i] - meanY)
+	}
+	var slope float64
+	if sxx != 0 {
+		slope = sxy / sxx
+	}
+	intercept := meanY - slope*meanX
+	ssTotal := residualSumSquares(x, y, 0, meanY)
+	ssResidual := residualSumSquares(x, y, slope, intercept)
+	return RegressionResult{Slope: slope, Intercept: intercept, RSquared: math.Max(rSquaredFrom(ssTotal, ssResidual), 0)}, nil
+}
+
+// ChannelDataset is a shared X column with several response channels, such as one instrument's
+// samples of several sensors
+type ChannelDataset struct {
+	X     []float64
+	XAxis Axis
+	Names []string
+	Axes  []Axis
+	Y     [][]float64
+}
+
+// LoadChannelCSV reads a CSV stream with a header row whose first column is X and whose other
+// columns are the channels. Empty and NA fields are missing values.
+func LoadChannelCSV(r io.Reader) (ChannelDataset, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return ChannelDataset{}, fmt.Errorf("no header row found")
+	}
+	if err != nil {
+		return ChannelDataset{}, fmt.Errorf("reading CSV: %w", err)
+	}
+	if len(header) < 2 {
+		return ChannelDataset{}, fmt.Errorf("need an x column and at least one channel, got %d columns", len(header))
+	}
+	cd := ChannelDataset{XAxis: ParseAxis(header[0]), Y: make([][]float64, len(header)-1)}
+	for _, name := range header[1:] {
+		axis := ParseAxis(name)
+		cd.Names = append(cd.Names, axis.Label)
+		cd.Axes = append(cd.Axes, axis)
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return ChannelDataset{}, fmt.Errorf("reading CSV: %w", err)
+		}
+		values := make([]float64, len(record))
+		for c, field := range record {
+			if values[c], err = parseCSVFloat(field); err != nil {
+				return ChannelDataset{}, fmt.Errorf("line %d, column %s: invalid number %q", line, header[c], field)
+			}
+		}
+		cd.X = append(cd.X, values[0])
+		for c := range cd.Y {
+			cd.Y[c] = append(cd.Y[c], values[c+1])
+		}
+	}
+	if len(cd.X) == 0 {
+		return ChannelDataset{}, fmt.Errorf("no data rows found")
+	}
+	return cd, nil
+}
+
+// Fit fits every channel with FitMulti and names the results after the channels
+func (cd ChannelDataset) Fit() ([]RegressionResult, error) {
+	results, err := FitMulti(cd.X, cd.Y)
+	if err != nil {
+		return nil, err
+	}
+	for k := range results {
+		results[k].Dataset = cd.Names[k]
+	}
+	return results, nil
+}
+
+// Dataset returns channel k paired with X
+func (cd ChannelDataset) Dataset(k int) Dataset {
+	return Dataset{X: cd.X, Y: cd.Y[k], XAxis: cd.XAxis, YAxis: cd.Axes[k]}
+}
+
+// runChannels implements the channels subcommand: fit every channel of a CSV file against its X column
+func runChannels(args []string) error {
+	flags := flag.NewFlagSet("channels", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: channels file.csv (first column X, one channel per other column)\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("expected one CSV file")
+	}
+	f, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cd, err := LoadChannelCSV(f)
+	if err != nil {
+		return fmt.Errorf("%s: %w", flags.Arg(0), err)
+	}
+	results, err := cd.Fit()
+	if err != nil {
+		return err
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Channel\tSlope\tIntercept\tR-squared\tPoints\t")
+	for k, r := range results {
+		x, _, _ := cleanPairs(nil, nil, cd.X, cd.Y[k])
+		ds := cd.Dataset(k)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.6f\t%d\t\n", r.Dataset, withUnit(r.Slope, ds.SlopeUnit()), withUnit(r.Intercept, ds.YAxis.Unit), r.RSquared, len(x))
+	}
+	return tw.Flush()
+}
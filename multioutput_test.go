@@ -0,0 +1,73 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Each response matches fitting it alone, including ones with their own missing values
+func TestFitMulti(t *testing.T) {
+	x := []float64{1, 2, 3, 4, math.NaN(), 6, 7, 8}
+	ys := [][]float64{
+		{3, 5, 7, 9, 11, 13, 15, 17},
+		{2.1, 3.9, 6.2, 8.1, 100, 12.3, 13.8, 16.4},
+		{1, math.NaN(), 0.5, 0.4, 0.2, math.Inf(1), 0.1, -0.3},
+		{4, 4, 4, 4, 4, 4, 4, 4},
+	}
+	results, err := FitMulti(x, ys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(ys) {
+		t.Fatalf("got %d results", len(results))
+	}
+	for k, y := range ys {
+		cx, cy, _ := cleanPairs(nil, nil, x, y)
+		slope, intercept, r2 := ManualRegression(cx, cy)
+		got := results[k]
+		if relErr(got.Slope, slope) > 1e-12 || relErr(got.Intercept, intercept) > 1e-12 || math.Abs(got.RSquared-r2) > 1e-12 {
+			t.Errorf("response %d: got %+v, want slope %v intercept %v r2 %v", k+1, got, slope, intercept, r2)
+		}
+	}
+	if results[0].Slope != 2 || results[0].RSquared != 1 || results[3].Slope != 0 || results[3].Intercept != 4 {
+		t.Errorf("unexpected exact fits %+v", results)
+	}
+
+	if _, err := FitMulti(x, [][]float64{{1, 2}}); err == nil {
+		t.Error("expected a length mismatch error")
+	}
+	if _, err := FitMulti(x, [][]float64{{1, math.NaN(), math.NaN(), math.NaN(), 1, math.NaN(), math.NaN(), math.NaN()}}); err == nil || !strings.Contains(err.Error(), "response 1") {
+		t.Errorf("expected an error naming the response, got %v", err)
+	}
+	if results, err := FitMulti(x, nil); err != nil || len(results) != 0 {
+		t.Errorf("no responses: %v, %v", results, err)
+	}
+}
+
+// ✅ Test 2: Channel CSV files load with units and fit one result per channel
+func TestLoadChannelCSV(t *testing.T) {
+	cd, err := LoadChannelCSV(strings.NewReader("time (s),temp (C),pressure [kPa]\n0,20,101\n1,21,NA\n2,22.1,100.5\n3,22.9,100.2\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cd.XAxis.Unit != "s" || strings.Join(cd.Names, ",") != "temp,pressure" || cd.Axes[1].Unit != "kPa" || len(cd.Y[1]) != 4 || !math.IsNaN(cd.Y[1][1]) {
+		t.Fatalf("unexpected dataset %+v", cd)
+	}
+	results, err := cd.Fit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Dataset != "temp" || math.Abs(results[0].Slope-0.98) > 1e-9 || results[1].Dataset != "pressure" || results[1].Slope >= 0 {
+		t.Errorf("unexpected results %+v", results)
+	}
+	if unit := cd.Dataset(0).SlopeUnit(); unit != "C/s" {
+		t.Errorf("slope unit %q", unit)
+	}
+
+	for _, bad := range []string{"", "x\n1\n", "x,y\n", "x,y\n1,abc\n"} {
+		if _, err := LoadChannelCSV(strings.NewReader(bad)); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
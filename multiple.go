@@ -0,0 +1,368 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+)
+
+// MultiDataset holds a response and several named predictors for multiple regression.
+// X is row-major: X[i][j] is predictor Names[j] of observation i.
+// Encodings records how categorical columns were expanded into Names (see LoadMultiCSV).
+type MultiDataset struct {
+	Names     []string
+	X         [][]float64
+	Y         []float64
+	Encodings []CategoricalEncoding
+}
+
+// MultiFit is a multiple linear regression. Coefficients[0] is the intercept and Coefficients[j+1]
+// belongs to Names[j].
+type MultiFit struct {
+	Names        []string              `json:"names"`
+	Coefficients []float64             `json:"coefficients"`
+	RSquared     float64               `json:"rSquared"`
+	N            int                   `json:"n"`
+	Encodings    []CategoricalEncoding `json:"encodings,omitempty"`
+	// Expansion is set when the fit used polynomial or interaction terms (see FitExpanded)
+	Expansion *FeatureExpansion `json:"expansion,omitempty"`
+	// PCA is set when the fit regressed on principal components (see FitPrincipalComponents)
+	PCA *PCA `json:"pca,omitempty"`
+	// Penalty is set when the coefficients were shrunk (see FitElasticNet)
+	Penalty *Penalty `json:"penalty,omitempty"`
+}
+
+// Intercept returns the fitted constant term
+func (f MultiFit) Intercept() float64 {
+	return f.Coefficients[0]
+}
+
+// Predict evaluates the fit at one row of predictors, in Names order. Fits with an Expansion take
+// the original predictors, in Expansion.Inputs order, and expand them first; fits with a PCA take
+// them in PCA.Names order and project them onto the components.
+func (f MultiFit) Predict(x []float64) float64 {
+	y := f.Coefficients[0]
+	for j, v := range f.features(x) {
+		y += f.Coefficients[j+1] * v
+	}
+	return y
+}
+
+// features maps a row of the inputs Predict takes to the fitted predictors, in Names order
+func (f MultiFit) features(x []float64) []float64 {
+	if f.Expansion != nil {
+		x = f.Expansion.Row(x)
+	}
+	if f.PCA != nil {
+		x = f.PCA.Transform(x)[:len(f.Names)]
+	}
+	return x
+}
+
+// FitMultiple fits Y = b0 + b1*X1 + ... + bp*Xp by least squares using a Householder QR
+// decomposition, which avoids squaring the condition number as the normal equations would.
+// Rows with a missing (NaN/Inf) value are dropped; collinear predictors are an error.
+func FitMultiple(md MultiDataset) (MultiFit, error) {
+	if len(md.X) != len(md.Y) {
+		return MultiFit{}, fmt.Errorf("x and y length mismatch: %d rows vs %d responses", len(md.X), len(md.Y))
+	}
+	p := len(md.Names)
+
+	// columns of the design matrix, the first being the intercept
+	cols := make([][]float64, p+1)
+	var y []float64
+	for i, row := range md.X {
+		if len(row) != p {
+			return MultiFit{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), p)
+		}
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		cols[0] = append(cols[0], 1)
+		for j, v := range row {
+			cols[j+1] = append(cols[j+1], v)
+		}
+		y = append(y, md.Y[i])
+	}
+	if len(y) <= p+1 {
+		return MultiFit{}, fmt.Errorf("need more complete rows than coefficients (have %d rows for %d coefficients)", len(y), p+1)
+	}
+
+	coef, singular := leastSquaresQR(cols, y)
+	if singular == 0 {
+		return MultiFit{}, fmt.Errorf("predictors are collinear: the intercept column is zero")
+	}
+	if singular > 0 {
+		return MultiFit{}, fmt.Errorf("predictors are collinear: %s is a linear combination of the intercept and earlier predictors", md.Names[singular-1])
+	}
+
+	fit := MultiFit{Names: md.Names, Coefficients: coef, N: len(y), Encodings: md.Encodings}
+
+	// the decomposition overwrote cols and y, so residuals are taken from the original rows
+	var mean float64
+	for i, row := range md.X {
+		if !isMissing(md.Y[i]) && !hasMissing(row) {
+			mean += md.Y[i]
+		}
+	}
+	mean /= float64(fit.N)
+	var ssTotal, ssResidual float64
+	for i, row := range md.X {
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		r := md.Y[i] - fit.Predict(row)
+		ssTotal += (md.Y[i] - mean) * (md.Y[i] - mean)
+		ssResidual += r * r
+	}
+	fit.RSquared = rSquaredFrom(ssTotal, ssResidual)
+	return fit, nil
+}
+
+// PrintMultiFit writes the coefficients of a multiple regression, naming the baseline level
+// each dummy coefficient is measured against
+func PrintMultiFit(w io.Writer, fit MultiFit) {
+	baselines := map[string]string{}
+	for _, enc := range fit.Encodings {
+		for _, name := range enc.Names() {
+			baselines[name] = enc.Baseline()
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Term\tCoefficient\t")
+	fmt.Fprintf(tw, "(intercept)\t%.6f\t\n", fit.Intercept())
+	for j, name := range fit.Names {
+		note := ""
+		if base, ok := baselines[name]; ok {
+			note = "vs " + base
+		}
+		fmt.Fprintf(tw, "%s\t%.6f\t%s\n", name, fit.Coefficients[j+1], note)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "R-squared: %.6f (%d rows)\n", fit.RSquared, fit.N)
+	if fit.PCA != nil {
+		var explained float64
+		for _, share := range fit.PCA.ExplainedVariance()[:len(fit.Names)] {
+			explained += share
+		}
+		fmt.Fprintf(w, "Components explain %.2f%% of the predictors' variance\n", 100*explained)
+	}
+}
+
+// runMulti implements the `multi` subcommand: fit a multiple regression to every column of a CSV file
+func runMulti(args []string) error {
+	flags := flag.NewFlagSet("multi", flag.ContinueOnError)
+	target := flags.String("target", "", "response column (defaults to the last column)")
+	var features FeatureOptions
+	flags.IntVar(&features.Degree, "degree", 1, "add powers of each numeric predictor up to this degree")
+	flags.BoolVar(&features.Interactions, "interactions", false, "add the product of every pair of predictors")
+	formula := flags.String("formula", "", "fit the terms of an R-style formula such as \"y ~ x + x^2 + group + x:group\" instead of every column")
+	components := flags.Int("pca", 0, "regress on this many principal components of the standardized predictors instead")
+	penalty := flags.String("penalty", "", "shrink the coefficients with a ridge, lasso or elasticnet:ALPHA penalty, choosing lambda by cross-validation unless -lambda is set")
+	lambda := flags.Float64("lambda", -1, "with -penalty, use this lambda instead of cross-validating")
+	folds := flags.Int("folds", 0, "with -penalty, number of cross-validation folds (default 10)")
+	seed := flags.Uint64("seed", 1, "with -penalty, seed for assigning rows to folds")
+	curvePath := flags.String("cv-curve", "", "with -penalty, write the cross-validation curve as CSV to this file")
+	htmlPath := flags.String("html", "", "also write an HTML report with partial residual and added-variable plots of every term to this file")
+	modelPath := flags.String("save-model", "", "save the fit as a model to this file (gob when it ends in .gob, JSON otherwise), for prediction with serve -model")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: multi [flags] file.csv")
+	}
+	if *components > 0 && (features.Degree > 1 || features.Interactions) {
+		return fmt.Errorf("-pca cannot be combined with -degree or -interactions")
+	}
+	var alpha float64
+	if *penalty != "" {
+		if *components > 0 || *formula != "" {
+			return fmt.Errorf("-penalty cannot be combined with -formula or -pca")
+		}
+		var err error
+		if alpha, err = ParsePenaltyMix(*penalty); err != nil {
+			return err
+		}
+	} else if *lambda >= 0 || *curvePath != "" {
+		return fmt.Errorf("-lambda and -cv-curve require -penalty")
+	}
+	var spec Formula
+	if *formula != "" {
+		if *components > 0 || features.Degree > 1 || features.Interactions || *target != "" {
+			return fmt.Errorf("-formula cannot be combined with -target, -degree, -interactions or -pca")
+		}
+		var err error
+		if spec, err = ParseFormula(*formula); err != nil {
+			return err
+		}
+		*target = spec.Response
+	}
+
+	md, err := LoadMultiCSVFile(flags.Arg(0), *target)
+	if err != nil {
+		return err
+	}
+	var fit MultiFit
+	if *formula != "" {
+		fit, md, err = FitFormula(md, spec)
+	} else if *components > 0 {
+		fit, err = FitPrincipalComponents(md, *components, true)
+	} else if *penalty != "" {
+		fit, err = fitPenalized(md, features, alpha, *lambda, TuneOptions{Folds: *folds, Seed: *seed}, *curvePath)
+	} else {
+		fit, err = FitExpanded(md, features)
+	}
+	if err != nil {
+		return err
+	}
+	PrintMultiFit(os.Stdout, fit)
+	printMultiConditioning(os.Stdout, fit, md)
+	if *htmlPath != "" {
+		if err := WriteMultiHTMLReportFile(*htmlPath, "Multiple Regression of "+filepath.Base(flags.Arg(0)), fit, md); err != nil {
+			return fmt.Errorf("writing HTML report: %w", err)
+		}
+		fmt.Printf("HTML report written to %s\n", *htmlPath)
+	}
+	if *modelPath != "" {
+		model := NewMultipleModel(strings.TrimSuffix(filepath.Base(flags.Arg(0)), filepath.Ext(flags.Arg(0))), fit, md)
+		model.Options = map[string]string{}
+		flags.Visit(func(f *flag.Flag) { model.Options[f.Name] = f.Value.String() })
+		if err := SaveModelFile(*modelPath, model); err != nil {
+			return fmt.Errorf("saving model: %w", err)
+		}
+		fmt.Printf("Model written to %s\n", *modelPath)
+	}
+	return nil
+}
+
+// fitPenalized expands md's predictors and fits them under an elastic-net penalty, cross-validating
+// lambda (and reporting the search, optionally writing its curve to curvePath) when it is negative
+func fitPenalized(md MultiDataset, features FeatureOptions, alpha, lambda float64, opts TuneOptions, curvePath string) (MultiFit, error) {
+	e, err := NewFeatureExpansion(md, features)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	expanded, err := e.Apply(md)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	var fit MultiFit
+	if lambda >= 0 {
+		fit, err = FitElasticNet(expanded, Penalty{Lambda: lambda, Alpha: alpha})
+	} else {
+		var res CVResult
+		if res, err = TuneElasticNet(expanded, alpha, opts); err != nil {
+			return MultiFit{}, err
+		}
+		PrintCVResult(os.Stdout, res)
+		if curvePath != "" {
+			f, err := os.Create(curvePath)
+			if err != nil {
+				return MultiFit{}, err
+			}
+			err = WriteCVCurve(f, res)
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return MultiFit{}, fmt.Errorf("writing the cross-validation curve: %w", err)
+			}
+		}
+		fit = res.Fit
+	}
+	if err != nil {
+		return MultiFit{}, err
+	}
+	fit.Expansion = &e
+	return fit, nil
+}
+
+// leastSquaresQR solves min ||A b - y|| for A given by columns, overwriting the columns and y.
+// When a column is (numerically) a combination of earlier ones its index is returned instead
+// of coefficients; singular is -1 on success.
+func leastSquaresQR(cols [][]float64, y []float64) (coef []float64, singular int) {
+	n, p := len(y), len(cols)
+
+	var scale float64
+	for _, c := range cols {
+		scale = math.Max(scale, norm2(c))
+	}
+	tol := 1e-10 * scale
+
+	rdiag := make([]float64, p)
+	for k := 0; k < p; k++ {
+		ck := cols[k]
+		alpha := norm2(ck[k:])
+		if alpha <= tol {
+			return nil, k
+		}
+		if ck[k] > 0 {
+			alpha = -alpha
+		}
+		// v = x - alpha*e1 is stored in ck[k:]; H = I - 2vv'/v'v
+		ck[k] -= alpha
+		vv := 0.0
+		for i := k; i < n; i++ {
+			vv += ck[i] * ck[i]
+		}
+		reflect := func(target []float64) {
+			dot := 0.0
+			for i := k; i < n; i++ {
+				dot += ck[i] * target[i]
+			}
+			f := 2 * dot / vv
+			for i := k; i < n; i++ {
+				target[i] -= f * ck[i]
+			}
+		}
+		for j := k + 1; j < p; j++ {
+			reflect(cols[j])
+		}
+		reflect(y)
+		rdiag[k] = alpha
+	}
+
+	// back substitution on R b = Q'y; R's off-diagonal entries are cols[j][k] for k < j
+	coef = make([]float64, p)
+	for k := p - 1; k >= 0; k-- {
+		s := y[k]
+		for j := k + 1; j < p; j++ {
+			s -= cols[j][k] * coef[j]
+		}
+		coef[k] = s / rdiag[k]
+	}
+	return coef, -1
+}
+
+func norm2(v []float64) float64 {
+	// scaled to avoid overflow for large values
+	var scale, sum float64 = 0, 1
+	for _, x := range v {
+		if x == 0 {
+			continue
+		}
+		ax := math.Abs(x)
+		if scale < ax {
+			sum = 1 + sum*(scale/ax)*(scale/ax)
+			scale = ax
+		} else {
+			sum += (ax / scale) * (ax / scale)
+		}
+	}
+	return scale * math.Sqrt(sum)
+}
+
+func hasMissing(row []float64) bool {
+	for _, v := range row {
+		if isMissing(v) {
+			return true
+		}
+	}
+	return false
+}
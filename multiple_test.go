@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: An exact plane is recovered, rows with missing values are dropped
+func TestFitMultiple(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 20; i++ {
+		a, b := float64(i), float64((i*7)%5)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 3+2*a-0.5*b)
+	}
+	md.X = append(md.X, []float64{math.NaN(), 1})
+	md.Y = append(md.Y, 100)
+
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{3, 2, -0.5}
+	for j, c := range fit.Coefficients {
+		if math.Abs(c-want[j]) > 1e-10 {
+			t.Errorf("coefficient %d: expected %v, got %v", j, want[j], c)
+		}
+	}
+	if fit.N != 20 || math.Abs(fit.RSquared-1) > 1e-12 || math.Abs(fit.Predict([]float64{1, 2})-4) > 1e-10 {
+		t.Errorf("unexpected fit %+v", fit)
+	}
+}
+
+// ✅ Test 2: One predictor agrees with the simple regression engines
+func TestFitMultipleMatchesSimple(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	md := MultiDataset{Names: []string{"x"}, Y: data.Y}
+	for _, x := range data.X {
+		md.X = append(md.X, []float64{x})
+	}
+	fit, err := FitMultiple(md)
+	slope, intercept, r2 := ManualRegression(data.X, data.Y)
+	if err != nil || math.Abs(fit.Coefficients[1]-slope) > 1e-12 || math.Abs(fit.Intercept()-intercept) > 1e-12 || math.Abs(fit.RSquared-r2) > 1e-12 {
+		t.Errorf("got %+v (err %v), want slope %v intercept %v", fit, err, slope, intercept)
+	}
+}
+
+// ✅ Test 3: Collinear predictors and too few rows are rejected
+func TestFitMultipleErrors(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "twice a"}}
+	for i := 0; i < 5; i++ {
+		md.X = append(md.X, []float64{float64(i), 2 * float64(i)})
+		md.Y = append(md.Y, float64(i))
+	}
+	if _, err := FitMultiple(md); err == nil || !strings.Contains(err.Error(), "twice a") {
+		t.Errorf("expected a collinearity error naming the column, got %v", err)
+	}
+
+	md.X, md.Y = md.X[:3], md.Y[:3]
+	if _, err := FitMultiple(md); err == nil {
+		t.Error("expected an error with as many rows as coefficients")
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import "math"
+
+// OnlineRegression maintains a simple linear regression incrementally.
+// It keeps running means and co-moments updated with Welford-style recurrences, which stay
+// accurate for data far from the origin where the raw-sum formulas of ManualRegression lose precision.
+// Every update is O(1), so streaming sources never need to refit from scratch.
+// An OnlineRegression is not safe for concurrent use.
+type OnlineRegression struct {
+	n            int
+	meanX, meanY float64
+	cxx, cxy     float64
+	cyy          float64
+}
+
+// OnlineSnapshot is the state of an OnlineRegression at a point in time.
+// Slope, Intercept and RSquared are zero until at least two points have been added.
+type OnlineSnapshot struct {
+	N         int     `json:"n"`
+	MeanX     float64 `json:"meanX"`
+	MeanY     float64 `json:"meanY"`
+	Slope     float64 `json:"slope"`
+	Intercept float64 `json:"intercept"`
+	RSquared  float64 `json:"rSquared"`
+}
+
+// Add includes a point in the fit. NaN and Inf values are ignored.
+func (o *OnlineRegression) Add(x, y float64) {
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+		return
+	}
+
+	o.n++
+	n := float64(o.n)
+	dx := x - o.meanX
+	dy := y - o.meanY
+	o.meanX += dx / n
+	o.meanY += dy / n
+	o.cxx += dx * (x - o.meanX)
+	o.cxy += dx * (y - o.meanY)
+	o.cyy += dy * (y - o.meanY)
+}
+
+// Remove excludes a point that was previously added. Removing a point that was never
+// added silently corrupts the state; NaN/Inf values and removals from an empty fit are ignored.
+func (o *OnlineRegression) Remove(x, y float64) {
+	if o.n == 0 || math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+		return
+	}
+	if o.n == 1 {
+		*o = OnlineRegression{}
+		return
+	}
+
+	// Invert the Add recurrence: m' = m - (v-m)/(n-1), C' = C - (v-m')(w-m)
+	n := float64(o.n)
+	prevMeanX := o.meanX - (x-o.meanX)/(n-1)
+	prevMeanY := o.meanY - (y-o.meanY)/(n-1)
+	o.cxx -= (x - prevMeanX) * (x - o.meanX)
+	o.cxy -= (x - prevMeanX) * (y - o.meanY)
+	o.cyy -= (y - prevMeanY) * (y - o.meanY)
+	o.meanX, o.meanY = prevMeanX, prevMeanY
+	o.n--
+
+	// cancellation can leave tiny negative sums of squares
+	o.cxx = math.Max(o.cxx, 0)
+	o.cyy = math.Max(o.cyy, 0)
+}
+
+// Merge folds the points of other into o using the pairwise update of Chan et al.,
+// which combines partial means and co-moments without revisiting the data
+func (o *OnlineRegression) Merge(other OnlineRegression) {
+	if other.n == 0 {
+		return
+	}
+	if o.n == 0 {
+		*o = other
+		return
+	}
+
+	na, nb := float64(o.n), float64(other.n)
+	n := na + nb
+	dx := other.meanX - o.meanX
+	dy := other.meanY - o.meanY
+	w := na * nb / n
+
+	o.meanX += dx * nb / n
+	o.meanY += dy * nb / n
+	o.cxx += other.cxx + dx*dx*w
+	o.cxy += other.cxy + dx*dy*w
+	o.cyy += other.cyy + dy*dy*w
+	o.n += other.n
+}
+
+// N returns the number of points currently in the fit
+func (o *OnlineRegression) N() int {
+	return o.n
+}
+
+// Reset clears all points
+func (o *OnlineRegression) Reset() {
+	*o = OnlineRegression{}
+}
+
+// ResidualStdDev returns the standard deviation of the residuals about the current line,
+// sqrt(SSres / (n - 2)), or 0 with fewer than three points
+func (o *OnlineRegression) ResidualStdDev() float64 {
+	if o.n < 3 {
+		return 0
+	}
+	ssRes := o.cyy
+	if o.cxx > 0 {
+		ssRes -= o.cxy * o.cxy / o.cxx
+	}
+	return math.Sqrt(math.Max(ssRes, 0) / float64(o.n-2))
+}
+
+// Snapshot returns the current coefficients, following the same degenerate-case rules as ManualRegression
+func (o *OnlineRegression) Snapshot() OnlineSnapshot {
+	s := OnlineSnapshot{N: o.n, MeanX: o.meanX, MeanY: o.meanY}
+	if o.n < 2 {
+		return s
+	}
+
+	if o.cxx > 0 {
+		s.Slope = o.cxy / o.cxx
+	}
+	s.Intercept = o.meanY - s.Slope*o.meanX
+
+	switch {
+	case o.cyy > 0 && o.cxx > 0:
+		// dividing by the square roots first keeps the product in range for magnitudes near 1e±150
+		r := o.cxy / math.Sqrt(o.cxx) / math.Sqrt(o.cyy)
+		s.RSquared = r * r
+	case o.cyy > 0:
+		// no variance in X: the horizontal line explains nothing
+		s.RSquared = 0
+	default:
+		// constant Y is fitted exactly
+		s.RSquared = 1
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Incremental fit matches the batch fit on every quartet dataset
+func TestOnlineMatchesManual(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		var o OnlineRegression
+		for i := range data.X {
+			o.Add(data.X[i], data.Y[i])
+		}
+		slope, intercept, rSquared := ManualRegression(data.X, data.Y)
+		s := o.Snapshot()
+		if math.Abs(s.Slope-slope) > 1e-9 || math.Abs(s.Intercept-intercept) > 1e-9 || math.Abs(s.RSquared-rSquared) > 1e-9 {
+			t.Errorf("%s: online %+v, manual %.6f %.6f %.6f", name, s, slope, intercept, rSquared)
+		}
+	}
+}
+
+// ✅ Test 2: Remove undoes Add
+func TestOnlineRemove(t *testing.T) {
+	data := LoadAnscombeDatasets()["III"]
+	var full, partial OnlineRegression
+	for i := range data.X {
+		full.Add(data.X[i], data.Y[i])
+		if i != 2 {
+			partial.Add(data.X[i], data.Y[i])
+		}
+	}
+	full.Remove(data.X[2], data.Y[2])
+
+	a, b := full.Snapshot(), partial.Snapshot()
+	if a.N != b.N || math.Abs(a.Slope-b.Slope) > 1e-12 || math.Abs(a.Intercept-b.Intercept) > 1e-12 {
+		t.Errorf("remove mismatch: %+v vs %+v", a, b)
+	}
+
+	for i := range data.X {
+		full.Remove(data.X[i], data.Y[i])
+	}
+	if full.N() != 0 {
+		t.Errorf("expected empty accumulator, got n=%d", full.N())
+	}
+}
+
+// ✅ Test 3: Large offsets do not destroy precision
+func TestOnlineLargeOffset(t *testing.T) {
+	var o OnlineRegression
+	for i := 0; i < 1000; i++ {
+		x := 1e9 + float64(i)
+		o.Add(x, 2*float64(i)+1)
+	}
+	if s := o.Snapshot(); math.Abs(s.Slope-2) > 1e-9 {
+		t.Errorf("slope lost precision: %.12f", s.Slope)
+	}
+}
+
+// ✅ Test 4: Degenerate inputs follow ManualRegression's conventions
+func TestOnlineDegenerate(t *testing.T) {
+	var o OnlineRegression
+	o.Add(1, 1)
+	o.Add(math.NaN(), 3)
+	if s := o.Snapshot(); s.N != 1 || s.Slope != 0 {
+		t.Errorf("single point snapshot: %+v", s)
+	}
+	o.Add(1, 3)
+	if s := o.Snapshot(); s.Slope != 0 || s.Intercept != 2 || s.RSquared != 0 {
+		t.Errorf("vertical data snapshot: %+v", s)
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ONNX export writes the protobuf wire format directly: the handful of ONNX messages a linear model
+// needs do not justify a protobuf dependency. Field numbers follow onnx/onnx.proto.
+
+// onnxOpset is the ai.onnx operator set the exported graphs use. ReduceProd still takes its axes as
+// an attribute there, and every runtime released since 2020 supports it.
+const (
+	onnxOpset     = 13
+	onnxIRVersion = 7
+)
+
+// ONNX tensor element types
+const (
+	onnxFloat  = 1
+	onnxInt64  = 7
+	onnxDouble = 11
+)
+
+// ONNXOptions configures ExportONNX
+type ONNXOptions struct {
+	// Double computes in float64 like Model.Predict. The default is float32, which every runtime and
+	// accelerator supports but rounds coefficients to about seven significant digits.
+	Double bool
+}
+
+// ExportONNX writes a linear or multiple regression model as an ONNX graph taking a batch of
+// observations "x", shaped [N, len(m.Inputs())], and returning the predictions "y", shaped [N, 1].
+// Polynomial and interaction terms are computed in the graph from the raw inputs (Gather and
+// ReduceProd per term), and principal component models standardize and project the inputs
+// (Sub, Div and MatMul), so the graph takes the same inputs as Model.Predict.
+func ExportONNX(w io.Writer, m Model, opts ONNXOptions) error {
+	g := onnxGraph{name: m.Name, elemType: onnxFloat}
+	if opts.Double {
+		g.elemType = onnxDouble
+	}
+
+	var coefficients []float64
+	var intercept float64
+	switch m.Kind {
+	case ModelLinear:
+		coefficients, intercept = []float64{m.Line.Slope}, m.Line.Intercept
+	case ModelMultiple:
+		coefficients, intercept = m.Multi.Coefficients[1:], m.Multi.Coefficients[0]
+	default:
+		return fmt.Errorf("%s models cannot be exported to ONNX", m.Kind)
+	}
+
+	features := "x"
+	if m.Multi != nil && m.Multi.Expansion != nil {
+		var terms []string
+		for i, t := range m.Multi.Expansion.Terms {
+			indices := make([]int64, len(t.Factors))
+			for j, f := range t.Factors {
+				indices[j] = int64(f)
+			}
+			idx := g.int64Initializer(fmt.Sprintf("term%d_factors", i), indices)
+			factors := g.node("Gather", []string{"x", idx}, onnxIntAttribute("axis", 1))
+			terms = append(terms, g.node("ReduceProd", []string{factors}, onnxIntsAttribute("axes", 1), onnxIntAttribute("keepdims", 1)))
+		}
+		features = g.node("Concat", terms, onnxIntAttribute("axis", 1))
+	}
+	if m.Multi != nil && m.Multi.PCA != nil {
+		p := m.Multi.PCA
+		k := len(m.Multi.Names)
+		projection := make([]float64, 0, len(p.Names)*k)
+		for j := range p.Names {
+			for c := 0; c < k; c++ {
+				projection = append(projection, p.Components[c][j])
+			}
+		}
+		means := g.initializer("pca_means", []int64{int64(len(p.Means))}, p.Means)
+		scales := g.initializer("pca_scales", []int64{int64(len(p.Scales))}, p.Scales)
+		components := g.initializer("pca_components", []int64{int64(len(p.Names)), int64(k)}, projection)
+		centered := g.node("Sub", []string{features, means})
+		standardized := g.node("Div", []string{centered, scales})
+		features = g.node("MatMul", []string{standardized, components})
+	}
+	weights := g.initializer("coefficients", []int64{int64(len(coefficients)), 1}, coefficients)
+	bias := g.initializer("intercept", []int64{1}, []float64{intercept})
+	g.nodes = append(g.nodes, onnxNode("Gemm", []string{features, weights, bias}, []string{"y"}))
+
+	doc := fmt.Sprintf("%s regression %s: y from %s", m.Kind, m.Name, strings.Join(m.Inputs(), ", "))
+	metadata := [][2]string{
+		{"kind", string(m.Kind)},
+		{"inputs", strings.Join(m.Inputs(), ",")},
+		{"n", strconv.Itoa(m.N)},
+		{"trained", m.Trained.Format(time.RFC3339)},
+	}
+
+	var model onnxMessage
+	model.varint(1, onnxIRVersion)
+	model.bytes(2, []byte("ai_assitance_go"))
+	model.bytes(3, []byte(m.Build))
+	model.bytes(6, []byte(doc))
+	model.message(7, g.encode(len(m.Inputs())))
+	var opset onnxMessage
+	opset.varint(2, onnxOpset)
+	model.message(8, opset)
+	for _, kv := range metadata {
+		var entry onnxMessage
+		entry.bytes(1, []byte(kv[0]))
+		entry.bytes(2, []byte(kv[1]))
+		model.message(14, entry)
+	}
+	_, err := w.Write(model)
+	return err
+}
+
+// onnxMessage accumulates the protobuf encoding of one message
+type onnxMessage []byte
+
+func (m *onnxMessage) tag(field, wireType int) {
+	*m = binary.AppendUvarint(*m, uint64(field<<3|wireType))
+}
+
+func (m *onnxMessage) varint(field int, v int64) {
+	m.tag(field, 0)
+	*m = binary.AppendUvarint(*m, uint64(v))
+}
+
+func (m *onnxMessage) bytes(field int, b []byte) {
+	m.tag(field, 2)
+	*m = binary.AppendUvarint(*m, uint64(len(b)))
+	*m = append(*m, b...)
+}
+
+func (m *onnxMessage) message(field int, sub onnxMessage) {
+	m.bytes(field, sub)
+}
+
+// packedVarints writes a repeated int64 field in packed form
+func (m *onnxMessage) packedVarints(field int, values []int64) {
+	var packed []byte
+	for _, v := range values {
+		packed = binary.AppendUvarint(packed, uint64(v))
+	}
+	m.bytes(field, packed)
+}
+
+// onnxGraph collects the nodes and initializers of a GraphProto
+type onnxGraph struct {
+	name         string
+	elemType     int64
+	nodes        []onnxMessage
+	initializers []onnxMessage
+}
+
+// node appends a single-output node and returns the name of its output
+func (g *onnxGraph) node(op string, inputs []string, attributes ...onnxMessage) string {
+	output := fmt.Sprintf("%s_%d", strings.ToLower(op), len(g.nodes))
+	g.nodes = append(g.nodes, onnxNode(op, inputs, []string{output}, attributes...))
+	return output
+}
+
+// initializer adds a constant tensor of the graph's element type and returns its name
+func (g *onnxGraph) initializer(name string, dims []int64, values []float64) string {
+	var t onnxMessage
+	t.packedVarints(1, dims)
+	t.varint(2, g.elemType)
+	var data []byte
+	if g.elemType == onnxDouble {
+		for _, v := range values {
+			data = binary.LittleEndian.AppendUint64(data, math.Float64bits(v))
+		}
+		t.bytes(10, data)
+	} else {
+		for _, v := range values {
+			data = binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(v)))
+		}
+		t.bytes(4, data)
+	}
+	t.bytes(8, []byte(name))
+	g.initializers = append(g.initializers, t)
+	return name
+}
+
+// int64Initializer adds a constant one-dimensional int64 tensor and returns its name
+func (g *onnxGraph) int64Initializer(name string, values []int64) string {
+	var t onnxMessage
+	t.packedVarints(1, []int64{int64(len(values))})
+	t.varint(2, onnxInt64)
+	t.packedVarints(7, values)
+	t.bytes(8, []byte(name))
+	g.initializers = append(g.initializers, t)
+	return name
+}
+
+// encode builds the GraphProto with input x of shape [N, inputs] and output y of shape [N, 1]
+func (g *onnxGraph) encode(inputs int) onnxMessage {
+	valueInfo := func(name string, columns int64) onnxMessage {
+		var batch, width, shape, tensor, typ, info onnxMessage
+		batch.bytes(2, []byte("N"))
+		width.varint(1, columns)
+		shape.message(1, batch)
+		shape.message(1, width)
+		tensor.varint(1, g.elemType)
+		tensor.message(2, shape)
+		typ.message(1, tensor)
+		info.bytes(1, []byte(name))
+		info.message(2, typ)
+		return info
+	}
+
+	var graph onnxMessage
+	for _, n := range g.nodes {
+		graph.message(1, n)
+	}
+	graph.bytes(2, []byte(g.name))
+	for _, t := range g.initializers {
+		graph.message(5, t)
+	}
+	graph.message(11, valueInfo("x", int64(inputs)))
+	graph.message(12, valueInfo("y", 1))
+	return graph
+}
+
+func onnxNode(op string, inputs, outputs []string, attributes ...onnxMessage) onnxMessage {
+	var n onnxMessage
+	for _, in := range inputs {
+		n.bytes(1, []byte(in))
+	}
+	for _, out := range outputs {
+		n.bytes(2, []byte(out))
+	}
+	n.bytes(4, []byte(op))
+	for _, a := range attributes {
+		n.message(5, a)
+	}
+	return n
+}
+
+func onnxIntAttribute(name string, v int64) onnxMessage {
+	var a onnxMessage
+	a.bytes(1, []byte(name))
+	a.varint(3, v)
+	a.varint(20, 2) // AttributeType INT
+	return a
+}
+
+func onnxIntsAttribute(name string, values ...int64) onnxMessage {
+	var a onnxMessage
+	a.bytes(1, []byte(name))
+	a.packedVarints(8, values)
+	a.varint(20, 7) // AttributeType INTS
+	return a
+}
+
+// ExportONNXFile writes the model to path with ExportONNX
+func ExportONNXFile(path string, m Model, opts ONNXOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := ExportONNX(f, m, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// runONNX implements the onnx subcommand: convert a saved model for ONNX runtimes
+func runONNX(args []string) error {
+	flags := flag.NewFlagSet("onnx", flag.ContinueOnError)
+	double := flags.Bool("double", false, "compute in float64 instead of float32")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: onnx [-double] model.json out.onnx")
+	}
+	m, err := LoadModelFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := ExportONNXFile(flags.Arg(1), m, ONNXOptions{Double: *double}); err != nil {
+		return err
+	}
+	fmt.Printf("Model %s written to %s (input x: [N, %d] = %s)\n", m.Name, flags.Arg(1), len(m.Inputs()), strings.Join(m.Inputs(), ", "))
+	return nil
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// pbField is one decoded protobuf field: v holds varints and fixed-width values, b length-delimited ones
+type pbField struct {
+	num, wire int
+	v         uint64
+	b         []byte
+}
+
+func decodePB(t *testing.T, data []byte) []pbField {
+	t.Helper()
+	var fields []pbField
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			t.Fatalf("bad tag")
+		}
+		data = data[n:]
+		f := pbField{num: int(key >> 3), wire: int(key & 7)}
+		switch f.wire {
+		case 0:
+			f.v, n = binary.Uvarint(data)
+			data = data[n:]
+		case 2:
+			length, n := binary.Uvarint(data)
+			f.b, data = data[n:n+int(length)], data[n+int(length):]
+		default:
+			t.Fatalf("unexpected wire type %d", f.wire)
+		}
+		fields = append(fields, f)
+	}
+	return fields
+}
+
+func pbVarints(b []byte) []int64 {
+	var values []int64
+	for len(b) > 0 {
+		v, n := binary.Uvarint(b)
+		values, b = append(values, int64(v)), b[n:]
+	}
+	return values
+}
+
+// onnxTensor is a row-major tensor of the evaluator below
+type onnxTensor struct {
+	dims []int
+	data []float64
+}
+
+// evalONNX runs an exported model on rows with a minimal interpreter of the operators ExportONNX
+// uses, returning the predictions and the graph's element type
+func evalONNX(t *testing.T, model []byte, rows [][]float64) ([]float64, int64) {
+	t.Helper()
+	var graph []pbField
+	for _, f := range decodePB(t, model) {
+		if f.num == 8 {
+			for _, o := range decodePB(t, f.b) {
+				if o.num == 2 && o.v != onnxOpset {
+					t.Errorf("opset %d", o.v)
+				}
+			}
+		}
+		if f.num == 7 {
+			graph = decodePB(t, f.b)
+		}
+	}
+
+	values := map[string]onnxTensor{"x": {dims: []int{len(rows), len(rows[0])}}}
+	for _, r := range rows {
+		values["x"] = onnxTensor{dims: values["x"].dims, data: append(values["x"].data, r...)}
+	}
+	var elemType int64
+	for _, f := range graph {
+		if f.num != 5 {
+			continue
+		}
+		var name string
+		var tensor onnxTensor
+		for _, g := range decodePB(t, f.b) {
+			switch g.num {
+			case 1:
+				for _, d := range pbVarints(g.b) {
+					tensor.dims = append(tensor.dims, int(d))
+				}
+			case 2:
+				if g.v != onnxInt64 {
+					elemType = int64(g.v)
+				}
+			case 4:
+				for i := 0; i < len(g.b); i += 4 {
+					tensor.data = append(tensor.data, float64(math.Float32frombits(binary.LittleEndian.Uint32(g.b[i:]))))
+				}
+			case 7:
+				for _, v := range pbVarints(g.b) {
+					tensor.data = append(tensor.data, float64(v))
+				}
+			case 8:
+				name = string(g.b)
+			case 10:
+				for i := 0; i < len(g.b); i += 8 {
+					tensor.data = append(tensor.data, math.Float64frombits(binary.LittleEndian.Uint64(g.b[i:])))
+				}
+			}
+		}
+		values[name] = tensor
+	}
+
+	for _, f := range graph {
+		if f.num != 1 {
+			continue
+		}
+		var inputs []onnxTensor
+		var output, op string
+		attrs := map[string][]int64{}
+		for _, g := range decodePB(t, f.b) {
+			switch g.num {
+			case 1:
+				in, ok := values[string(g.b)]
+				if !ok {
+					t.Fatalf("undefined input %s", g.b)
+				}
+				inputs = append(inputs, in)
+			case 2:
+				output = string(g.b)
+			case 4:
+				op = string(g.b)
+			case 5:
+				var name string
+				var ints []int64
+				for _, a := range decodePB(t, g.b) {
+					switch a.num {
+					case 1:
+						name = string(a.b)
+					case 3:
+						ints = []int64{int64(a.v)}
+					case 8:
+						ints = pbVarints(a.b)
+					}
+				}
+				attrs[name] = ints
+			}
+		}
+
+		a := inputs[0]
+		n, cols := a.dims[0], a.dims[len(a.dims)-1]
+		out := onnxTensor{}
+		switch op {
+		case "Gather":
+			idx := inputs[1].data
+			out.dims = []int{n, len(idx)}
+			for i := 0; i < n; i++ {
+				for _, j := range idx {
+					out.data = append(out.data, a.data[i*cols+int(j)])
+				}
+			}
+		case "ReduceProd":
+			out.dims = []int{n, 1}
+			for i := 0; i < n; i++ {
+				p := 1.0
+				for _, v := range a.data[i*cols : (i+1)*cols] {
+					p *= v
+				}
+				out.data = append(out.data, p)
+			}
+		case "Concat":
+			width := 0
+			for _, in := range inputs {
+				width += in.dims[1]
+			}
+			out.dims = []int{n, width}
+			for i := 0; i < n; i++ {
+				for _, in := range inputs {
+					out.data = append(out.data, in.data[i*in.dims[1]:(i+1)*in.dims[1]]...)
+				}
+			}
+		case "Sub", "Div":
+			out.dims = a.dims
+			for i, v := range a.data {
+				if op == "Sub" {
+					out.data = append(out.data, v-inputs[1].data[i%cols])
+				} else {
+					out.data = append(out.data, v/inputs[1].data[i%cols])
+				}
+			}
+		case "MatMul", "Gemm":
+			b := inputs[1]
+			k := b.dims[1]
+			out.dims = []int{n, k}
+			for i := 0; i < n; i++ {
+				for c := 0; c < k; c++ {
+					s := 0.0
+					if op == "Gemm" {
+						s = inputs[2].data[0]
+					}
+					for j := 0; j < cols; j++ {
+						s += a.data[i*cols+j] * b.data[j*k+c]
+					}
+					out.data = append(out.data, s)
+				}
+			}
+		default:
+			t.Fatalf("unexpected operator %s", op)
+		}
+		if op == "Gather" || op == "Concat" {
+			if attrs["axis"][0] != 1 {
+				t.Errorf("%s on axis %v", op, attrs["axis"])
+			}
+		}
+		values[output] = out
+	}
+	y, ok := values["y"]
+	if !ok || len(y.dims) != 2 || y.dims[0] != len(rows) || y.dims[1] != 1 {
+		t.Fatalf("unexpected output %+v", y)
+	}
+	return y.data, elemType
+}
+
+// ✅ Test 1: Exported linear, polynomial and principal component models predict like Model.Predict
+func TestExportONNX(t *testing.T) {
+	ds := LoadAnscombeDatasets()["II"]
+	line, err := AnalyzeDataset("II", ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 30; i++ {
+		a, b := float64(i%10), float64(i*i%13)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+2*a-b+0.5*a*b+0.1*a*a+0.01*float64(i%3))
+	}
+	poly, err := FitExpanded(md, FeatureOptions{Degree: 2, Interactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcr, err := FitPrincipalComponents(md, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows2 := [][]float64{{0, 0}, {3, 4}, {9, 12}, {-2, 0.5}}
+	models := []struct {
+		model Model
+		rows  [][]float64
+	}{
+		{NewLinearModel(line, ds), [][]float64{{4}, {10}, {14}, {-1}}},
+		{NewMultipleModel("poly", poly, md), rows2},
+		{NewMultipleModel("pcr", pcr, md), rows2},
+	}
+	for _, c := range models {
+		for _, double := range []bool{true, false} {
+			var buf bytes.Buffer
+			if err := ExportONNX(&buf, c.model, ONNXOptions{Double: double}); err != nil {
+				t.Fatal(err)
+			}
+			got, elemType := evalONNX(t, buf.Bytes(), c.rows)
+			tol, wantType := 1e-12, int64(onnxDouble)
+			if !double {
+				tol, wantType = 1e-5, onnxFloat
+			}
+			if elemType != wantType {
+				t.Errorf("%s: element type %d, want %d", c.model.Name, elemType, wantType)
+			}
+			for i, row := range c.rows {
+				want, _ := c.model.Predict(row...)
+				if math.Abs(got[i]-want) > tol*math.Max(math.Abs(want), 1) {
+					t.Errorf("%s (double %v): row %v predicted %v, want %v", c.model.Name, double, row, got[i], want)
+				}
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Power models are rejected and files are written by ExportONNXFile
+func TestExportONNXFile(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	power, err := FitPower(ds, PowerOptions{X: PowerLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "model.onnx")
+	if err := ExportONNXFile(path, NewPowerModel("I", power, ds), ONNXOptions{}); err == nil {
+		t.Error("expected power models to be rejected")
+	}
+	if err := ExportONNXFile(path, NewLinearModel(RegressionResult{Dataset: "I", Slope: 2, Intercept: 1}, ds), ONNXOptions{}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// OutlierMethod selects how FilterOutliers flags points
+type OutlierMethod string
+
+const (
+	OutlierIQR    OutlierMethod = "iqr"    // outside [Q1 - k·IQR, Q3 + k·IQR], default k = 1.5
+	OutlierZScore OutlierMethod = "zscore" // |v - mean| / sd > k, default k = 3
+	OutlierMAD    OutlierMethod = "mad"    // |v - median| / (1.4826·MAD) > k, default k = 3.5
+)
+
+// defaultOutlierThresholds are the conventional cut-offs used when threshold <= 0
+var defaultOutlierThresholds = map[OutlierMethod]float64{OutlierIQR: 1.5, OutlierZScore: 3, OutlierMAD: 3.5}
+
+// ParseOutlierMethod returns the method named by s
+func ParseOutlierMethod(s string) (OutlierMethod, error) {
+	m := OutlierMethod(s)
+	if _, ok := defaultOutlierThresholds[m]; !ok {
+		return "", fmt.Errorf("unknown outlier method %q (use iqr, zscore or mad)", s)
+	}
+	return m, nil
+}
+
+// FilterOutliers removes points whose X or Y value is flagged by method and returns the cleaned
+// dataset (with its weights, labels and tags) and the indexes of the removed points. NaN/Inf values are left for the fit to skip.
+// threshold <= 0 uses the method's conventional cut-off.
+func (d Dataset) FilterOutliers(method OutlierMethod, threshold float64) (Dataset, []int, error) {
+	if len(d.X) != len(d.Y) {
+		return Dataset{}, nil, fmt.Errorf("x and y length mismatch: %d vs %d", len(d.X), len(d.Y))
+	}
+	if err := d.validateMetadata(); err != nil {
+		return Dataset{}, nil, err
+	}
+	if _, err := ParseOutlierMethod(string(method)); err != nil {
+		return Dataset{}, nil, err
+	}
+	def := defaultOutlierThresholds[method]
+	if threshold <= 0 {
+		threshold = def
+	}
+
+	isOutlierX := outlierTest(method, threshold, d.X)
+	isOutlierY := outlierTest(method, threshold, d.Y)
+
+	cleaned := Dataset{XAxis: d.XAxis, YAxis: d.YAxis}
+	var removed []int
+	for i := range d.X {
+		if isOutlierX(d.X[i]) || isOutlierY(d.Y[i]) {
+			removed = append(removed, i)
+			continue
+		}
+		cleaned.appendPoint(d, i)
+	}
+	return cleaned, removed, nil
+}
+
+// outlierTest estimates the method's statistics from the finite values and returns the predicate
+func outlierTest(method OutlierMethod, k float64, values []float64) func(float64) bool {
+	finite := make([]float64, 0, len(values))
+	for _, v := range values {
+		if !math.IsNaN(v) && !math.IsInf(v, 0) {
+			finite = append(finite, v)
+		}
+	}
+	if len(finite) == 0 {
+		return func(float64) bool { return false }
+	}
+	sort.Float64s(finite)
+
+	switch method {
+	case OutlierIQR:
+		q1, q3 := quantileSorted(finite, 0.25), quantileSorted(finite, 0.75)
+		lo, hi := q1-k*(q3-q1), q3+k*(q3-q1)
+		return func(v float64) bool { return v < lo || v > hi }
+	case OutlierZScore:
+		var acc OnlineRegression
+		for _, v := range finite {
+			acc.Add(v, 0)
+		}
+		mean := acc.meanX
+		sd := math.Sqrt(acc.cxx / math.Max(float64(acc.n-1), 1))
+		return func(v float64) bool { return math.Abs(v-mean) > k*sd }
+	default:
+		median := quantileSorted(finite, 0.5)
+		deviations := make([]float64, len(finite))
+		for i, v := range finite {
+			deviations[i] = math.Abs(v - median)
+		}
+		sort.Float64s(deviations)
+		// 1.4826 makes the MAD a consistent estimator of the standard deviation for normal data
+		scale := 1.4826 * quantileSorted(deviations, 0.5)
+		return func(v float64) bool { return math.Abs(v-median) > k*scale }
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Each method removes the Anscombe III outlier and reports its index
+func TestFilterOutliersAnscombeIII(t *testing.T) {
+	data := LoadAnscombeDatasets()["III"]
+	for _, method := range []OutlierMethod{OutlierIQR, OutlierZScore, OutlierMAD} {
+		threshold := 0.0
+		if method == OutlierZScore {
+			// with 11 points a single outlier cannot exceed z = 3; use a tighter cut-off
+			threshold = 2.5
+		}
+		cleaned, removed, err := data.FilterOutliers(method, threshold)
+		if err != nil || !slices.Equal(removed, []int{2}) || len(cleaned.X) != 10 {
+			t.Errorf("%s: removed %v (err %v)", method, removed, err)
+			continue
+		}
+		if _, _, r2, _ := FastRegression(cleaned.X, cleaned.Y); r2 < 0.9999 {
+			t.Errorf("%s: expected a near-perfect line after filtering, got R² %v", method, r2)
+		}
+	}
+}
+
+// ✅ Test 2: Clean data is untouched, NaN is kept and bad input is rejected
+func TestFilterOutliersEdgeCases(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 3, 4, math.NaN()}, Y: []float64{2, 4, 6, 8, 10}}
+	cleaned, removed, err := data.FilterOutliers(OutlierIQR, 0)
+	if err != nil || len(removed) != 0 || len(cleaned.X) != 5 {
+		t.Errorf("expected nothing removed, got %v (err %v)", removed, err)
+	}
+
+	if _, _, err := data.FilterOutliers("grubbs", 0); err == nil {
+		t.Error("expected unknown method error")
+	}
+	if m, err := ParseOutlierMethod("mad"); err != nil || m != OutlierMAD {
+		t.Errorf("mad parsed as %q, %v", m, err)
+	}
+	for _, bad := range []string{"", "IQR", "grubbs"} {
+		if _, err := ParseOutlierMethod(bad); err == nil {
+			t.Errorf("%q: expected unknown method error", bad)
+		}
+	}
+	if _, _, err := (Dataset{X: []float64{1}, Y: nil}).FilterOutliers(OutlierMAD, 0); err == nil {
+		t.Error("expected length mismatch error")
+	}
+	if q := quantileSorted([]float64{1, 2, 3, 4}, 0.25); q != 1.75 {
+		t.Errorf("expected type 7 quantile 1.75, got %v", q)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+const (
+	// parallelThreshold is the input size above which ParallelRegression splits the work
+	parallelThreshold = 1 << 17
+	// parallelChunk is the fixed chunk size. Chunk boundaries depend only on the input length,
+	// never on GOMAXPROCS, and partials are merged in chunk order, so results are bit-stable.
+	parallelChunk = 1 << 15
+)
+
+// ParallelRegression fits very large inputs by accumulating per-chunk moments on all CPUs and
+// merging them in a fixed order. Inputs below parallelThreshold go straight to FastRegression.
+func ParallelRegression(x, y []float64) (slope, intercept, rSquared float64, err error) {
+	if len(x) <= parallelThreshold {
+		return FastRegression(x, y)
+	}
+	if err := checkPairLengths(len(x), len(y)); err != nil {
+		return 0, 0, 0, err
+	}
+
+	acc := chunkedMoments(x, y, parallelChunk, runtime.GOMAXPROCS(0))
+	if err := checkValidPairs(acc.N()); err != nil {
+		return 0, 0, 0, err
+	}
+
+	s := acc.Snapshot()
+	return s.Slope, s.Intercept, s.RSquared, nil
+}
+
+// chunkedMoments accumulates each chunk on a pool of workers and merges the partials in chunk order
+func chunkedMoments(x, y []float64, chunk, workers int) OnlineRegression {
+	chunks := (len(x) + chunk - 1) / chunk
+	partials := make([]OnlineRegression, chunks)
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, chunks); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for c := range next {
+				// accumulate locally so neighbouring partials do not share a cache line while hot
+				var local OnlineRegression
+				end := min((c+1)*chunk, len(x))
+				for i := c * chunk; i < end; i++ {
+					local.Add(x[i], y[i])
+				}
+				partials[c] = local
+			}
+		}()
+	}
+	for c := 0; c < chunks; c++ {
+		next <- c
+	}
+	close(next)
+	wg.Wait()
+
+	var acc OnlineRegression
+	for _, p := range partials {
+		acc.Merge(p)
+	}
+	return acc
+}
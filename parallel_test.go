@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+	"runtime"
+	"testing"
+)
+
+// ✅ Test 1: Merging partial moments equals accumulating everything at once
+func TestOnlineMerge(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	var whole, a, b OnlineRegression
+	for i := range data.X {
+		whole.Add(data.X[i], data.Y[i])
+		if i < 4 {
+			a.Add(data.X[i], data.Y[i])
+		} else {
+			b.Add(data.X[i], data.Y[i])
+		}
+	}
+	a.Merge(b)
+
+	got, want := a.Snapshot(), whole.Snapshot()
+	if got.N != want.N || math.Abs(got.Slope-want.Slope) > 1e-12 || math.Abs(got.RSquared-want.RSquared) > 1e-12 {
+		t.Errorf("merged %+v, whole %+v", got, want)
+	}
+}
+
+// ✅ Test 2: Parallel fit matches the sequential fit and is bit-identical across GOMAXPROCS
+func TestParallelRegressionBitStable(t *testing.T) {
+	x, y := syntheticLine(parallelThreshold*2 + 123)
+	x[10] = math.NaN()
+
+	refSlope, refIntercept, _, _ := FastRegression(x, y)
+
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	var first [3]float64
+	for i, procs := range []int{1, 3, 8} {
+		runtime.GOMAXPROCS(procs)
+		slope, intercept, r2, err := ParallelRegression(x, y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(slope-refSlope) > 1e-9 || math.Abs(intercept-refIntercept) > 1e-6 {
+			t.Errorf("procs=%d: parallel (%v, %v) vs sequential (%v, %v)", procs, slope, intercept, refSlope, refIntercept)
+		}
+		if i == 0 {
+			first = [3]float64{slope, intercept, r2}
+		} else if first != [3]float64{slope, intercept, r2} {
+			t.Errorf("procs=%d: result %v differs from procs=1 result %v", procs, [3]float64{slope, intercept, r2}, first)
+		}
+	}
+}
+
+// ✅ Benchmark: parallel vs single-pass on a large input
+func BenchmarkParallelRegression(b *testing.B) {
+	x, y := syntheticLine(4_000_000)
+	b.Run("parallel", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			ParallelRegression(x, y)
+		}
+	})
+	b.Run("fast", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			FastRegression(x, y)
+		}
+	})
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// PartialPlot holds the two plots of one term of a multiple regression that show its relationship
+// with the response once the other terms are accounted for, which a raw scatter plot of the term
+// against the response does not when the terms are correlated.
+//
+// The partial residual (component-plus-residual) plot is the term's values X against the fit's
+// residuals plus the term's contribution, PartialResidual; its least squares slope is the term's
+// coefficient, and curvature in it suggests a transform of the term. The added-variable plot is
+// AddedX, the term's residuals from regressing it on the other terms, against AddedY, the
+// response's residuals from the same regression; its slope is the least squares coefficient and
+// the points that stand out are those driving it.
+type PartialPlot struct {
+	Term            string    `json:"term"`
+	Coefficient     float64   `json:"coefficient"`
+	X               []float64 `json:"x"`
+	PartialResidual []float64 `json:"partialResidual"`
+	AddedX          []float64 `json:"addedX"`
+	AddedY          []float64 `json:"addedY"`
+}
+
+// PartialPlots computes the plots of each of the fit's terms over the complete rows of md, which
+// holds what the fit's Predict takes. Terms are the fitted predictors, so expanded fits have a plot
+// per polynomial or interaction term and PCA fits one per component. The added-variable plots
+// always regress by least squares, so for a penalized fit their slopes are the unshrunk
+// coefficients.
+func PartialPlots(fit MultiFit, md MultiDataset) ([]PartialPlot, error) {
+	p := len(fit.Names)
+	cols := make([][]float64, p)
+	var y, residuals []float64
+	for i, row := range md.X {
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		for j, v := range fit.features(row) {
+			cols[j] = append(cols[j], v)
+		}
+		y = append(y, md.Y[i])
+		residuals = append(residuals, md.Y[i]-fit.Predict(row))
+	}
+	if len(y) <= p+1 {
+		return nil, fmt.Errorf("need more complete rows than coefficients (have %d rows for %d coefficients)", len(y), p+1)
+	}
+
+	plots := make([]PartialPlot, p)
+	others := make([][]float64, 0, p)
+	for j, name := range fit.Names {
+		b := fit.Coefficients[j+1]
+		plot := PartialPlot{Term: name, Coefficient: b, X: cols[j], PartialResidual: make([]float64, len(y))}
+		for i, r := range residuals {
+			plot.PartialResidual[i] = r + b*cols[j][i]
+		}
+
+		others = append(others[:0], cols[:j]...)
+		others = append(others, cols[j+1:]...)
+		var err error
+		if plot.AddedX, err = residualize(cols[j], others); err != nil {
+			return nil, fmt.Errorf("term %s: %w", name, err)
+		}
+		if plot.AddedY, err = residualize(y, others); err != nil {
+			return nil, fmt.Errorf("term %s: %w", name, err)
+		}
+		plots[j] = plot
+	}
+	return plots, nil
+}
+
+// residualize returns the residuals of target regressed by least squares on an intercept and the
+// columns of others, none of which it modifies
+func residualize(target []float64, others [][]float64) ([]float64, error) {
+	n := len(target)
+	design := make([][]float64, 0, len(others)+1)
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	design = append(design, ones)
+	for _, c := range others {
+		design = append(design, append([]float64(nil), c...))
+	}
+	coef, singular := leastSquaresQR(design, append([]float64(nil), target...))
+	if singular >= 0 {
+		return nil, fmt.Errorf("the other terms are collinear")
+	}
+
+	residuals := make([]float64, n)
+	for i := range target {
+		fitted := coef[0]
+		for k, c := range others {
+			fitted += coef[k+1] * c[i]
+		}
+		residuals[i] = target[i] - fitted
+	}
+	return residuals, nil
+}
+
+// WriteMultiHTMLReport writes a self-contained HTML page with a multiple regression's coefficients
+// and, for every term, its partial residual and added-variable plots (see PartialPlots)
+func WriteMultiHTMLReport(w io.Writer, title string, fit MultiFit, md MultiDataset) error {
+	plots, err := PartialPlots(fit, md)
+	if err != nil {
+		return err
+	}
+	type term struct {
+		PartialPlot
+		Partial, Added template.HTML
+	}
+	terms := make([]term, len(plots))
+	for j, p := range plots {
+		terms[j] = term{
+			PartialPlot: p,
+			Partial:     svgPointsWithLine(p.X, p.PartialResidual, p.Coefficient, p.Term, "partial residual"),
+			// the added-variable line passes through the origin, as both residuals have mean zero
+			Added: svgPointsWithLine(p.AddedX, p.AddedY, addedSlope(p.AddedX, p.AddedY), p.Term+" | others", "response | others"),
+		}
+	}
+	return multiReportTemplate.Execute(w, struct {
+		Title string
+		Fit   MultiFit
+		Terms []term
+	}{title, fit, terms})
+}
+
+// WriteMultiHTMLReportFile writes the report to path
+func WriteMultiHTMLReportFile(path, title string, fit MultiFit, md MultiDataset) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteMultiHTMLReport(f, title, fit, md); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// addedSlope is the least squares slope through the origin of an added-variable plot
+func addedSlope(x, y []float64) float64 {
+	var sxy, sxx float64
+	for i := range x {
+		sxy += x[i] * y[i]
+		sxx += x[i] * x[i]
+	}
+	if sxx == 0 {
+		return 0
+	}
+	return sxy / sxx
+}
+
+// svgPointsWithLine plots points with the line of the given slope through their means, which is
+// where a least squares line of that slope passes
+func svgPointsWithLine(x, y []float64, slope float64, xTitle, yTitle string) template.HTML {
+	if len(x) == 0 {
+		return template.HTML(`<p>No complete points to plot.</p>`)
+	}
+	xMin, xMax, yMin, yMax := x[0], x[0], y[0], y[0]
+	var meanX, meanY float64
+	for i := range x {
+		xMin, xMax = math.Min(xMin, x[i]), math.Max(xMax, x[i])
+		yMin, yMax = math.Min(yMin, y[i]), math.Max(yMax, y[i])
+		meanX += x[i] / float64(len(x))
+		meanY += y[i] / float64(len(y))
+	}
+	lineLo, lineHi := meanY+slope*(xMin-meanX), meanY+slope*(xMax-meanX)
+	yMin, yMax = math.Min(yMin, math.Min(lineLo, lineHi)), math.Max(yMax, math.Max(lineLo, lineHi))
+	f := newPlotFrame(xMin, xMax, yMin, yMax)
+
+	var b strings.Builder
+	f.open(&b, xTitle, yTitle)
+	for i := range x {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="#3b6ea5" fill-opacity="0.7"/>`, f.px(x[i]), f.py(y[i]))
+	}
+	fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#c0392b" stroke-width="2"/>`, f.px(xMin), f.py(lineLo), f.px(xMax), f.py(lineHi))
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var multiReportTemplate = template.Must(template.New("multi").Funcs(template.FuncMap{
+	"num": func(v float64) string { return fmt.Sprintf("%.6f", v) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+section { margin-bottom: 2.5rem; }
+table { border-collapse: collapse; margin-bottom: 1rem; }
+td { padding: 0.15rem 1rem 0.15rem 0; }
+td:first-child { color: #666; }
+.plots { display: flex; flex-wrap: wrap; gap: 1.5rem; }
+figure { margin: 0; }
+figcaption { font-size: 0.9rem; color: #444; margin-bottom: 0.25rem; }
+svg text { font-size: 11px; fill: #555; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+<table>
+<tr><td>(intercept)</td><td>{{num .Fit.Intercept}}</td></tr>
+{{range .Terms}}<tr><td>{{.Term}}</td><td>{{num .Coefficient}}</td></tr>
+{{end}}<tr><td>R-squared</td><td>{{num .Fit.RSquared}}</td></tr>
+<tr><td>Rows</td><td>{{.Fit.N}}</td></tr>
+</table>
+{{range .Terms}}<section>
+<h2>{{.Term}}</h2>
+<div class="plots">
+<figure><figcaption>Partial residuals (slope {{num .Coefficient}})</figcaption>{{.Partial}}</figure>
+<figure><figcaption>Added variable, adjusted for the other terms</figcaption>{{.Added}}</figure>
+</div>
+</section>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// correlatedDataset has two correlated predictors and a response with noise
+func correlatedDataset() MultiDataset {
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 30; i++ {
+		a := float64(i)
+		b := 0.8*a + float64((i*7)%5)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+0.5*a-2*b+float64((i*3)%4)-1.5)
+	}
+	return md
+}
+
+// olsSlope is the least squares slope of y on x with an intercept
+func olsSlope(x, y []float64) float64 {
+	slope, _, _ := ManualRegression(x, y)
+	return slope
+}
+
+// ✅ Test 1: Both plots of each term have the term's coefficient as their slope
+func TestPartialPlots(t *testing.T) {
+	md := correlatedDataset()
+	md.X = append(md.X, []float64{math.NaN(), 1})
+	md.Y = append(md.Y, 5)
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plots, err := PartialPlots(fit, md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plots) != 2 || plots[1].Term != "b" || len(plots[0].X) != 30 {
+		t.Fatalf("unexpected plots %+v", plots)
+	}
+	for j, p := range plots {
+		b := fit.Coefficients[j+1]
+		if got := olsSlope(p.X, p.PartialResidual); math.Abs(got-b) > 1e-9 {
+			t.Errorf("%s: partial residual slope %g, want %g", p.Term, got, b)
+		}
+		if got := addedSlope(p.AddedX, p.AddedY); math.Abs(got-b) > 1e-9 {
+			t.Errorf("%s: added-variable slope %g, want %g", p.Term, got, b)
+		}
+	}
+}
+
+// ✅ Test 2: The HTML report has both plots for every term
+func TestWriteMultiHTMLReport(t *testing.T) {
+	md := correlatedDataset()
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := WriteMultiHTMLReport(&buf, "Test <report>", fit, md); err != nil {
+		t.Fatal(err)
+	}
+	html := buf.String()
+	if got := strings.Count(html, "<svg"); got != 4 {
+		t.Errorf("expected 4 plots, got %d", got)
+	}
+	for _, want := range []string{"<title>Test &lt;report&gt;</title>", "<h2>a</h2>", "<h2>b</h2>", "Added variable", "b | others"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("report is missing %q", want)
+		}
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"text/tabwriter"
+)
+
+// PCA is a principal component analysis of a MultiDataset's predictors. Components[k][j] is the
+// loading of Names[j] on component k+1, and Variances[k] the variance of that component's scores.
+// Components are sorted by decreasing variance and signed so their largest loading is positive.
+type PCA struct {
+	Names      []string    `json:"names"`
+	Means      []float64   `json:"means"`
+	Scales     []float64   `json:"scales"` // standard deviations when standardized, otherwise 1
+	Components [][]float64 `json:"components"`
+	Variances  []float64   `json:"variances"`
+	N          int         `json:"n"`
+}
+
+// FitPCA computes the principal components of md's predictors from the rows without missing values.
+// With standardize, every predictor is scaled to unit variance first (PCA of the correlation
+// matrix), which is usually wanted when predictors have different units.
+func FitPCA(md MultiDataset, standardize bool) (PCA, error) {
+	p := len(md.Names)
+	if p == 0 {
+		return PCA{}, fmt.Errorf("no predictors")
+	}
+	var rows [][]float64
+	for i, row := range md.X {
+		if len(row) != p {
+			return PCA{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), p)
+		}
+		if !hasMissing(row) {
+			rows = append(rows, row)
+		}
+	}
+	if len(rows) < 2 {
+		return PCA{}, fmt.Errorf("need at least two complete rows, have %d", len(rows))
+	}
+
+	pca := PCA{Names: md.Names, Means: make([]float64, p), Scales: make([]float64, p), N: len(rows)}
+	column := make([]float64, len(rows))
+	for j := range md.Names {
+		for i, row := range rows {
+			column[i] = row[j]
+		}
+		mean, variance := meanVariance(column)
+		pca.Means[j], pca.Scales[j] = mean, 1
+		if standardize {
+			if variance == 0 {
+				return PCA{}, fmt.Errorf("%s is constant and cannot be standardized", md.Names[j])
+			}
+			pca.Scales[j] = math.Sqrt(variance)
+		}
+	}
+
+	cov := make([][]float64, p)
+	for j := range cov {
+		cov[j] = make([]float64, p)
+	}
+	for _, row := range rows {
+		z := pca.center(row)
+		for j := range cov {
+			for k := j; k < p; k++ {
+				cov[j][k] += z[j] * z[k]
+			}
+		}
+	}
+	for j := range cov {
+		for k := j; k < p; k++ {
+			cov[j][k] /= float64(len(rows) - 1)
+			cov[k][j] = cov[j][k]
+		}
+	}
+
+	values, vectors := symmetricEigen(cov)
+	order := make([]int, p)
+	for k := range order {
+		order[k] = k
+	}
+	sort.SliceStable(order, func(a, b int) bool { return values[order[a]] > values[order[b]] })
+	for _, k := range order {
+		loading := make([]float64, p)
+		largest := 0
+		for j := range loading {
+			loading[j] = vectors[j][k]
+			if math.Abs(loading[j]) > math.Abs(loading[largest]) {
+				largest = j
+			}
+		}
+		if loading[largest] < 0 {
+			for j := range loading {
+				loading[j] = -loading[j]
+			}
+		}
+		pca.Components = append(pca.Components, loading)
+		// rounding can leave the smallest eigenvalues slightly negative
+		pca.Variances = append(pca.Variances, math.Max(0, values[k]))
+	}
+	return pca, nil
+}
+
+// ExplainedVariance returns the fraction of the total variance carried by each component
+func (p PCA) ExplainedVariance() []float64 {
+	var total float64
+	for _, v := range p.Variances {
+		total += v
+	}
+	out := make([]float64, len(p.Variances))
+	for k, v := range p.Variances {
+		if total > 0 {
+			out[k] = v / total
+		}
+	}
+	return out
+}
+
+// ComponentsFor returns the smallest number of components explaining at least fraction of the variance
+func (p PCA) ComponentsFor(fraction float64) int {
+	var cumulative float64
+	for k, share := range p.ExplainedVariance() {
+		cumulative += share
+		if cumulative >= fraction-1e-12 {
+			return k + 1
+		}
+	}
+	return len(p.Variances)
+}
+
+// Transform returns the component scores of one row of predictors, in Names order
+func (p PCA) Transform(row []float64) []float64 {
+	z := p.center(row)
+	scores := make([]float64, len(p.Components))
+	for k, loading := range p.Components {
+		for j, l := range loading {
+			scores[k] += l * z[j]
+		}
+	}
+	return scores
+}
+
+// Project replaces md's predictors with the scores of the first k components, named PC1..PCk,
+// keeping the response. Rows with a missing predictor get missing scores.
+func (p PCA) Project(md MultiDataset, k int) (MultiDataset, error) {
+	if k < 1 || k > len(p.Components) {
+		return MultiDataset{}, fmt.Errorf("number of components must be between 1 and %d, got %d", len(p.Components), k)
+	}
+	out := MultiDataset{Y: md.Y, X: make([][]float64, len(md.X))}
+	for c := 1; c <= k; c++ {
+		out.Names = append(out.Names, fmt.Sprintf("PC%d", c))
+	}
+	for i, row := range md.X {
+		if len(row) != len(p.Names) {
+			return MultiDataset{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), len(p.Names))
+		}
+		out.X[i] = p.Transform(row)[:k]
+	}
+	return out, nil
+}
+
+func (p PCA) center(row []float64) []float64 {
+	z := make([]float64, len(row))
+	for j, v := range row {
+		z[j] = (v - p.Means[j]) / p.Scales[j]
+	}
+	return z
+}
+
+// FitPrincipalComponents regresses md's response on its first k principal components (principal
+// component regression). The fit keeps the PCA so Predict takes the original predictors.
+func FitPrincipalComponents(md MultiDataset, k int, standardize bool) (MultiFit, error) {
+	pca, err := FitPCA(md, standardize)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	projected, err := pca.Project(md, k)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	fit, err := FitMultiple(projected)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	fit.PCA = &pca
+	return fit, nil
+}
+
+// symmetricEigen diagonalizes a symmetric matrix by cyclic Jacobi rotations. It returns the
+// eigenvalues and a matrix whose column k is the unit eigenvector of values[k].
+func symmetricEigen(m [][]float64) (values []float64, vectors [][]float64) {
+	p := len(m)
+	a := make([][]float64, p)
+	vectors = make([][]float64, p)
+	for i := range a {
+		a[i] = append([]float64(nil), m[i]...)
+		vectors[i] = make([]float64, p)
+		vectors[i][i] = 1
+	}
+
+	for sweep := 0; sweep < 100; sweep++ {
+		var off, diag float64
+		for i := range a {
+			diag += a[i][i] * a[i][i]
+			for j := i + 1; j < p; j++ {
+				off += a[i][j] * a[i][j]
+			}
+		}
+		if off <= 1e-30*diag || off == 0 {
+			break
+		}
+		for i := 0; i < p; i++ {
+			for j := i + 1; j < p; j++ {
+				if a[i][j] == 0 {
+					continue
+				}
+				// the rotation angle that zeroes a[i][j]
+				theta := (a[j][j] - a[i][i]) / (2 * a[i][j])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+				for k := 0; k < p; k++ {
+					aki, akj := a[k][i], a[k][j]
+					a[k][i], a[k][j] = c*aki-s*akj, s*aki+c*akj
+				}
+				for k := 0; k < p; k++ {
+					aik, ajk := a[i][k], a[j][k]
+					a[i][k], a[j][k] = c*aik-s*ajk, s*aik+c*ajk
+				}
+				for k := 0; k < p; k++ {
+					vki, vkj := vectors[k][i], vectors[k][j]
+					vectors[k][i], vectors[k][j] = c*vki-s*vkj, s*vki+c*vkj
+				}
+			}
+		}
+	}
+
+	values = make([]float64, p)
+	for i := range values {
+		values[i] = a[i][i]
+	}
+	return values, vectors
+}
+
+// PrintPCA writes the variance explained by each component followed by the loadings
+func PrintPCA(w io.Writer, pca PCA) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Component\tVariance\tProportion\tCumulative\t")
+	var cumulative float64
+	for k, share := range pca.ExplainedVariance() {
+		cumulative += share
+		fmt.Fprintf(tw, "PC%d\t%.6f\t%.4f\t%.4f\t\n", k+1, pca.Variances[k], share, cumulative)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\nLoadings:\n")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprint(tw, "Predictor\t")
+	for k := range pca.Components {
+		fmt.Fprintf(tw, "PC%d\t", k+1)
+	}
+	fmt.Fprintln(tw)
+	for j, name := range pca.Names {
+		fmt.Fprintf(tw, "%s\t", name)
+		for _, loading := range pca.Components {
+			fmt.Fprintf(tw, "%.4f\t", loading[j])
+		}
+		fmt.Fprintln(tw)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d complete rows\n", pca.N)
+}
+
+// runPCA implements the `pca` subcommand: principal components of the predictors of a CSV file,
+// read as the multi subcommand reads them
+func runPCA(args []string) error {
+	flags := flag.NewFlagSet("pca", flag.ContinueOnError)
+	target := flags.String("target", "", "response column left out of the analysis (defaults to the last column)")
+	raw := flags.Bool("covariance", false, "analyze the covariance matrix instead of standardizing every predictor")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: pca [flags] file.csv")
+	}
+
+	md, err := LoadMultiCSVFile(flags.Arg(0), *target)
+	if err != nil {
+		return err
+	}
+	pca, err := FitPCA(md, !*raw)
+	if err != nil {
+		return err
+	}
+	PrintPCA(os.Stdout, pca)
+	return nil
+}
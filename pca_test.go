@@ -0,0 +1,125 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Eigen-decomposition of a known symmetric matrix
+func TestSymmetricEigen(t *testing.T) {
+	values, vectors := symmetricEigen([][]float64{{2, 1}, {1, 2}})
+	if math.Abs(math.Max(values[0], values[1])-3) > 1e-12 || math.Abs(math.Min(values[0], values[1])-1) > 1e-12 {
+		t.Errorf("eigenvalues %v, want 3 and 1", values)
+	}
+	m := [][]float64{{4, 1, 2}, {1, 3, 0}, {2, 0, 5}}
+	values, vectors = symmetricEigen(m)
+	for k := range values {
+		for i := range m {
+			var mv float64
+			for j := range m {
+				mv += m[i][j] * vectors[j][k]
+			}
+			if math.Abs(mv-values[k]*vectors[i][k]) > 1e-10 {
+				t.Fatalf("column %d is not an eigenvector of value %v", k, values[k])
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Two perfectly correlated predictors load on one component
+func TestFitPCACorrelated(t *testing.T) {
+	md := MultiDataset{Names: []string{"a", "b", "c"}}
+	for i := 0; i < 20; i++ {
+		a := float64(i)
+		md.X = append(md.X, []float64{a, 10 * a, math.Sin(float64(i))})
+		md.Y = append(md.Y, a)
+	}
+	md.X = append(md.X, []float64{math.NaN(), 1, 1})
+	md.Y = append(md.Y, 0)
+
+	pca, err := FitPCA(md, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pca.N != 20 {
+		t.Errorf("expected the incomplete row dropped, N = %d", pca.N)
+	}
+	shares := pca.ExplainedVariance()
+	var total float64
+	for _, s := range shares {
+		total += s
+	}
+	if math.Abs(total-1) > 1e-12 || shares[0] < shares[1] || shares[2] > 1e-10 {
+		t.Errorf("unexpected explained variance %v", shares)
+	}
+	// standardized variances sum to the number of predictors
+	if math.Abs(pca.Variances[0]+pca.Variances[1]+pca.Variances[2]-3) > 1e-9 {
+		t.Errorf("variances %v do not sum to 3", pca.Variances)
+	}
+	if math.Abs(pca.Components[0][0]-pca.Components[0][1]) > 1e-9 || pca.Components[0][0] <= 0 {
+		t.Errorf("expected a and b to share the first component, got %v", pca.Components[0])
+	}
+	if k := pca.ComponentsFor(0.999); k != 2 {
+		t.Errorf("ComponentsFor(0.999) = %d, want 2", k)
+	}
+}
+
+// ✅ Test 3: Scores are uncorrelated with the component variances
+func TestPCAScores(t *testing.T) {
+	md := MultiDataset{Names: []string{"x1", "x2"}}
+	for i := 0; i < 50; i++ {
+		x := float64(i%7) + 0.1*float64(i)
+		md.X = append(md.X, []float64{x, 0.5*x + math.Cos(float64(i))})
+		md.Y = append(md.Y, 0)
+	}
+	pca, err := FitPCA(md, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	projected, err := pca.Project(md, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s11, s22, s12 float64
+	for _, row := range projected.X {
+		s11 += row[0] * row[0]
+		s22 += row[1] * row[1]
+		s12 += row[0] * row[1]
+	}
+	n := float64(len(md.X) - 1)
+	if math.Abs(s12/n) > 1e-9 || math.Abs(s11/n-pca.Variances[0]) > 1e-9 || math.Abs(s22/n-pca.Variances[1]) > 1e-9 {
+		t.Errorf("score covariance [%v %v; %v], variances %v", s11/n, s12/n, s22/n, pca.Variances)
+	}
+	if _, err := pca.Project(md, 3); err == nil {
+		t.Error("expected an error for too many components")
+	}
+}
+
+// ✅ Test 4: Regression on all components reproduces the ordinary fit
+func TestFitPrincipalComponents(t *testing.T) {
+	md := MultiDataset{Names: []string{"x1", "x2"}}
+	for i := 0; i < 15; i++ {
+		x1, x2 := float64(i), float64((i*7)%5)
+		md.X = append(md.X, []float64{x1, x2})
+		md.Y = append(md.Y, 1+2*x1-3*x2+0.1*math.Sin(float64(i)))
+	}
+	ols, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcr, err := FitPrincipalComponents(md, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(pcr.RSquared-ols.RSquared) > 1e-9 {
+		t.Errorf("R-squared %v vs %v", pcr.RSquared, ols.RSquared)
+	}
+	row := []float64{3.5, 2}
+	if math.Abs(pcr.Predict(row)-ols.Predict(row)) > 1e-9 {
+		t.Errorf("prediction %v vs %v", pcr.Predict(row), ols.Predict(row))
+	}
+
+	if _, err := FitPCA(MultiDataset{Names: []string{"c"}, X: [][]float64{{1}, {1}}, Y: []float64{0, 0}}, true); err == nil {
+		t.Error("expected an error standardizing a constant predictor")
+	}
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// PMML 4.4 documents, reduced to the elements a RegressionModel needs. The XML names follow the
+// specification at https://dmg.org/pmml/v4-4-1/Regression.html.
+
+type pmmlDocument struct {
+	XMLName    xml.Name       `xml:"http://www.dmg.org/PMML-4_4 PMML"`
+	Version    string         `xml:"version,attr"`
+	Header     pmmlHeader     `xml:"Header"`
+	Dictionary pmmlDictionary `xml:"DataDictionary"`
+	Model      pmmlRegression `xml:"RegressionModel"`
+}
+
+type pmmlHeader struct {
+	Description string `xml:"description,attr,omitempty"`
+	Application struct {
+		Name    string `xml:"name,attr"`
+		Version string `xml:"version,attr,omitempty"`
+	} `xml:"Application"`
+	Timestamp string `xml:"Timestamp,omitempty"`
+}
+
+type pmmlDictionary struct {
+	NumberOfFields int             `xml:"numberOfFields,attr"`
+	Fields         []pmmlDataField `xml:"DataField"`
+}
+
+type pmmlDataField struct {
+	Name     string `xml:"name,attr"`
+	OpType   string `xml:"optype,attr"`
+	DataType string `xml:"dataType,attr"`
+}
+
+type pmmlRegression struct {
+	Name            string               `xml:"modelName,attr"`
+	Function        string               `xml:"functionName,attr"`
+	Algorithm       string               `xml:"algorithmName,attr,omitempty"`
+	MiningSchema    []pmmlMiningField    `xml:"MiningSchema>MiningField"`
+	Transformations *pmmlTransformations `xml:"LocalTransformations"`
+	Table           pmmlRegressionTable  `xml:"RegressionTable"`
+}
+
+type pmmlMiningField struct {
+	Name  string `xml:"name,attr"`
+	Usage string `xml:"usageType,attr,omitempty"`
+}
+
+type pmmlTransformations struct {
+	Fields []pmmlDerivedField `xml:"DerivedField"`
+}
+
+type pmmlDerivedField struct {
+	Name     string    `xml:"name,attr"`
+	OpType   string    `xml:"optype,attr"`
+	DataType string    `xml:"dataType,attr"`
+	Apply    pmmlApply `xml:"Apply"`
+}
+
+// pmmlApply is a function call whose arguments are constants, field references or further calls,
+// kept in document order
+type pmmlApply struct {
+	Function string           `xml:"function,attr"`
+	Args     []pmmlExpression `xml:",any"`
+}
+
+type pmmlExpression struct {
+	XMLName  xml.Name
+	Field    string           `xml:"field,attr,omitempty"`
+	DataType string           `xml:"dataType,attr,omitempty"`
+	Function string           `xml:"function,attr,omitempty"`
+	Value    string           `xml:",chardata"`
+	Args     []pmmlExpression `xml:",any"`
+}
+
+type pmmlRegressionTable struct {
+	Intercept  float64                `xml:"intercept,attr"`
+	Predictors []pmmlNumericPredictor `xml:"NumericPredictor"`
+	Terms      []pmmlPredictorTerm    `xml:"PredictorTerm"`
+}
+
+type pmmlNumericPredictor struct {
+	Name        string  `xml:"name,attr"`
+	Exponent    int     `xml:"exponent,attr"`
+	Coefficient float64 `xml:"coefficient,attr"`
+}
+
+type pmmlPredictorTerm struct {
+	Coefficient float64        `xml:"coefficient,attr"`
+	Fields      []pmmlFieldRef `xml:"FieldRef"`
+}
+
+type pmmlFieldRef struct {
+	Field string `xml:"field,attr"`
+}
+
+func pmmlConstant(v float64) pmmlExpression {
+	return pmmlExpression{XMLName: xml.Name{Local: "Constant"}, DataType: "double", Value: strconv.FormatFloat(v, 'g', -1, 64)}
+}
+
+func pmmlField(name string) pmmlExpression {
+	return pmmlExpression{XMLName: xml.Name{Local: "FieldRef"}, Field: name}
+}
+
+func pmmlCall(function string, args ...pmmlExpression) pmmlExpression {
+	return pmmlExpression{XMLName: xml.Name{Local: "Apply"}, Function: function, Args: args}
+}
+
+// ExportPMML writes a linear or multiple regression model as a PMML 4.4 RegressionModel whose
+// inputs are m.Inputs() and whose target is the model's Y label ("y" when it has none). Powers of
+// an input become NumericPredictor exponents and interactions PredictorTerms; principal component
+// models compute their components as DerivedFields. Dummy columns of categorical predictors stay
+// numeric 0/1 inputs, as in Model.Predict.
+func ExportPMML(w io.Writer, m Model) error {
+	var coefficients []float64
+	var intercept float64
+	switch m.Kind {
+	case ModelLinear:
+		coefficients, intercept = []float64{m.Line.Slope}, m.Line.Intercept
+	case ModelMultiple:
+		coefficients, intercept = m.Multi.Coefficients[1:], m.Multi.Coefficients[0]
+	default:
+		return fmt.Errorf("%s models cannot be exported to PMML", m.Kind)
+	}
+
+	inputs := m.Inputs()
+	target := "y"
+	if m.YAxis.Label != "" {
+		target = m.YAxis.Label
+	}
+	doc := pmmlDocument{Version: "4.4"}
+	doc.Header.Description = fmt.Sprintf("%s regression %s fitted to %d points", m.Kind, m.Name, m.N)
+	doc.Header.Application.Name = "ai_assitance_go"
+	doc.Header.Application.Version = m.Build
+	if !m.Trained.IsZero() {
+		doc.Header.Timestamp = m.Trained.Format(time.RFC3339)
+	}
+	for _, name := range append(append([]string(nil), inputs...), target) {
+		doc.Dictionary.Fields = append(doc.Dictionary.Fields, pmmlDataField{Name: name, OpType: "continuous", DataType: "double"})
+		doc.Model.MiningSchema = append(doc.Model.MiningSchema, pmmlMiningField{Name: name})
+	}
+	doc.Dictionary.NumberOfFields = len(doc.Dictionary.Fields)
+	doc.Model.MiningSchema[len(inputs)].Usage = "target"
+	doc.Model.Name = m.Name
+	doc.Model.Function = "regression"
+	doc.Model.Algorithm = "least squares"
+	doc.Model.Table.Intercept = intercept
+
+	// the fitted columns, in coefficient order, as products of input fields with repetition
+	columns := make([][]string, len(coefficients))
+	switch {
+	case m.Multi != nil && m.Multi.Expansion != nil:
+		for i, t := range m.Multi.Expansion.Terms {
+			for _, f := range t.Factors {
+				columns[i] = append(columns[i], inputs[f])
+			}
+		}
+	case m.Multi != nil && m.Multi.PCA != nil:
+		p := m.Multi.PCA
+		doc.Model.Transformations = &pmmlTransformations{}
+		for c, name := range m.Multi.Names {
+			var sum []pmmlExpression
+			for j, input := range p.Names {
+				z := pmmlCall("/", pmmlCall("-", pmmlField(input), pmmlConstant(p.Means[j])), pmmlConstant(p.Scales[j]))
+				sum = append(sum, pmmlCall("*", pmmlConstant(p.Components[c][j]), z))
+			}
+			doc.Model.Transformations.Fields = append(doc.Model.Transformations.Fields, pmmlDerivedField{
+				Name: name, OpType: "continuous", DataType: "double", Apply: pmmlApply{Function: "sum", Args: sum},
+			})
+			columns[c] = []string{name}
+		}
+	default:
+		for i, name := range inputs {
+			columns[i] = []string{name}
+		}
+	}
+
+	for i, fields := range columns {
+		same := true
+		for _, f := range fields {
+			same = same && f == fields[0]
+		}
+		if same {
+			doc.Model.Table.Predictors = append(doc.Model.Table.Predictors, pmmlNumericPredictor{Name: fields[0], Exponent: len(fields), Coefficient: coefficients[i]})
+			continue
+		}
+		term := pmmlPredictorTerm{Coefficient: coefficients[i]}
+		for _, f := range fields {
+			term.Fields = append(term.Fields, pmmlFieldRef{Field: f})
+		}
+		doc.Model.Table.Terms = append(doc.Model.Table.Terms, term)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// runPMML implements the pmml subcommand: convert a saved model for PMML scoring engines
+func runPMML(args []string) error {
+	flags := flag.NewFlagSet("pmml", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: pmml model.json out.pmml")
+	}
+	m, err := LoadModelFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err := ExportPMML(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("Model %s written to %s\n", m.Name, flags.Arg(1))
+	return nil
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// evalPMMLExpression evaluates the Apply, FieldRef and Constant elements ExportPMML writes
+func evalPMMLExpression(t *testing.T, e pmmlExpression, fields map[string]float64) float64 {
+	t.Helper()
+	switch e.XMLName.Local {
+	case "Constant":
+		v, err := strconv.ParseFloat(e.Value, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	case "FieldRef":
+		v, ok := fields[e.Field]
+		if !ok {
+			t.Fatalf("undefined field %s", e.Field)
+		}
+		return v
+	case "Apply":
+		return evalPMMLApply(t, pmmlApply{Function: e.Function, Args: e.Args}, fields)
+	}
+	t.Fatalf("unexpected element %s", e.XMLName.Local)
+	return 0
+}
+
+func evalPMMLApply(t *testing.T, a pmmlApply, fields map[string]float64) float64 {
+	t.Helper()
+	var args []float64
+	for _, arg := range a.Args {
+		args = append(args, evalPMMLExpression(t, arg, fields))
+	}
+	switch a.Function {
+	case "sum":
+		s := 0.0
+		for _, v := range args {
+			s += v
+		}
+		return s
+	case "+":
+		return args[0] + args[1]
+	case "-":
+		return args[0] - args[1]
+	case "*":
+		return args[0] * args[1]
+	case "/":
+		return args[0] / args[1]
+	}
+	t.Fatalf("unexpected function %s", a.Function)
+	return 0
+}
+
+// scorePMML is a minimal scoring engine for the documents ExportPMML writes
+func scorePMML(t *testing.T, doc pmmlDocument, row []float64) float64 {
+	t.Helper()
+	fields := map[string]float64{}
+	for i, f := range doc.Model.MiningSchema {
+		if f.Usage != "target" {
+			fields[f.Name] = row[i]
+		}
+	}
+	if doc.Model.Transformations != nil {
+		for _, d := range doc.Model.Transformations.Fields {
+			fields[d.Name] = evalPMMLApply(t, d.Apply, fields)
+		}
+	}
+	table := doc.Model.Table
+	y := table.Intercept
+	for _, p := range table.Predictors {
+		y += p.Coefficient * math.Pow(fields[p.Name], float64(p.Exponent))
+	}
+	for _, term := range table.Terms {
+		v := term.Coefficient
+		for _, f := range term.Fields {
+			v *= fields[f.Field]
+		}
+		y += v
+	}
+	return y
+}
+
+// ✅ Test 1: Exported linear, polynomial and principal component models score like Model.Predict
+func TestExportPMML(t *testing.T) {
+	ds := LoadAnscombeDatasets()["III"]
+	ds.XAxis, ds.YAxis = Axis{Label: "dose"}, Axis{Label: "response"}
+	line, err := AnalyzeDataset("III", ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 30; i++ {
+		a, b := float64(i%10), float64(i*i%13)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+2*a-b+0.5*a*b+0.1*a*a+0.01*float64(i%3))
+	}
+	poly, err := FitExpanded(md, FeatureOptions{Degree: 2, Interactions: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pcr, err := FitPrincipalComponents(md, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows2 := [][]float64{{0, 0}, {3, 4}, {9, 12}, {-2, 0.5}}
+	models := []struct {
+		model Model
+		rows  [][]float64
+		terms int
+	}{
+		{NewLinearModel(line, ds), [][]float64{{4}, {10}, {19}}, 0},
+		{NewMultipleModel("poly", poly, md), rows2, 1},
+		{NewMultipleModel("pcr", pcr, md), rows2, 0},
+	}
+	for _, c := range models {
+		var buf bytes.Buffer
+		if err := ExportPMML(&buf, c.model); err != nil {
+			t.Fatal(err)
+		}
+		var doc pmmlDocument
+		if err := xml.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: %v\n%s", c.model.Name, err, buf.String())
+		}
+		if doc.Version != "4.4" || doc.Dictionary.NumberOfFields != len(c.model.Inputs())+1 || len(doc.Model.Table.Terms) != c.terms {
+			t.Errorf("%s: unexpected document\n%s", c.model.Name, buf.String())
+		}
+		for _, row := range c.rows {
+			want, _ := c.model.Predict(row...)
+			if got := scorePMML(t, doc, row); math.Abs(got-want) > 1e-9*math.Max(math.Abs(want), 1) {
+				t.Errorf("%s: row %v scored %v, want %v", c.model.Name, row, got, want)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	ExportPMML(&buf, models[0].model)
+	if out := buf.String(); !strings.Contains(out, `<MiningField name="response" usageType="target">`) || !strings.Contains(out, `<NumericPredictor name="dose" exponent="1"`) {
+		t.Errorf("expected the axis labels as field names:\n%s", out)
+	}
+}
+
+// ✅ Test 2: Power models cannot be expressed as a regression table
+func TestExportPMMLRejectsPower(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	power, err := FitPower(ds, PowerOptions{Y: PowerLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ExportPMML(&bytes.Buffer{}, NewPowerModel("I", power, ds)); err == nil {
+		t.Error("expected an error")
+	}
+}
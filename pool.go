@@ -0,0 +1,71 @@
+package main
+
+import (
+	"sync"
+
+	"github.com/montanaflynn/stats"
+)
+
+// maxPooledPoints bounds the buffers kept for reuse so one huge request does not pin memory forever
+const maxPooledPoints = 1 << 20
+
+// ReusableFitter fits datasets with the manual least-squares engine while reusing its scratch
+// buffers between calls, so sustained traffic allocates nothing per fit once the buffers are warm.
+// A ReusableFitter is not safe for concurrent use; keep one per goroutine or draw them from a sync.Pool.
+type ReusableFitter struct {
+	x, y []float64
+}
+
+// NewReusableFitter creates a fitter with buffers sized for capacity points
+func NewReusableFitter(capacity int) *ReusableFitter {
+	return &ReusableFitter{x: make([]float64, 0, capacity), y: make([]float64, 0, capacity)}
+}
+
+// Fit applies the same validation and NaN/Inf filtering as PerformLinearRegression, then fits the
+// cleaned pairs with ManualRegression. The inputs are not modified or retained.
+func (f *ReusableFitter) Fit(x, y []float64) (slope, intercept, rSquared float64, err error) {
+	f.x, f.y, err = cleanPairs(f.x[:0], f.y[:0], x, y)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+
+	slope, intercept, rSquared = ManualRegression(f.x, f.y)
+	return slope, intercept, rSquared, nil
+}
+
+// Pools backing the server's /fit handler and the analysis path it calls
+var (
+	requestPool = sync.Pool{New: func() any { return new(FitRequest) }}
+	scratchPool = sync.Pool{New: func() any { return new(regressionScratch) }}
+)
+
+// regressionScratch holds the cleaned pairs of one performLinearRegression call
+type regressionScratch struct {
+	x, y   []float64
+	coords []stats.Coordinate
+}
+
+func getScratch() *regressionScratch {
+	return scratchPool.Get().(*regressionScratch)
+}
+
+func putScratch(s *regressionScratch) {
+	if cap(s.x) > maxPooledPoints {
+		return
+	}
+	scratchPool.Put(s)
+}
+
+func getFitRequest() *FitRequest {
+	req := requestPool.Get().(*FitRequest)
+	// keep the slice capacity: encoding/json appends into the existing backing arrays
+	req.Dataset, req.X, req.Y = "", req.X[:0], req.Y[:0]
+	return req
+}
+
+func putFitRequest(req *FitRequest) {
+	if cap(req.X) > maxPooledPoints || cap(req.Y) > maxPooledPoints {
+		return
+	}
+	requestPool.Put(req)
+}
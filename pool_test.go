@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: ReusableFitter matches ManualRegression and stops allocating once warm
+func TestReusableFitter(t *testing.T) {
+	f := NewReusableFitter(0)
+	for name, data := range LoadAnscombeDatasets() {
+		slope, intercept, r2, err := f.Fit(data.X, data.Y)
+		refSlope, refIntercept, refR2 := ManualRegression(data.X, data.Y)
+		if err != nil || slope != refSlope || intercept != refIntercept || r2 != refR2 {
+			t.Errorf("%s: reusable fit differs from ManualRegression", name)
+		}
+	}
+
+	data := LoadAnscombeDatasets()["II"]
+	if allocs := testing.AllocsPerRun(50, func() { f.Fit(data.X, data.Y) }); allocs != 0 {
+		t.Errorf("warm fitter allocated %.1f times per fit", allocs)
+	}
+
+	if _, _, _, err := f.Fit([]float64{1, math.Inf(1), 3}, []float64{1, 2, math.NaN()}); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+}
+
+// ✅ Test 2: Pooled requests do not leak data between calls, and fits use the analysis engine
+func TestFitHandlerPoolIsolation(t *testing.T) {
+	handler := NewServer(ServerOptions{})
+	for _, body := range []string{
+		`{"dataset":"a","x":[1,2,3,4,5,6],"y":[2,4,6,8,10,12]}`,
+		`{"x":[1,2,3],"y":[1,1,1]}`,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/fit", strings.NewReader(body)))
+		if rec.Code != 200 {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		if strings.Contains(body, `"y":[1,1,1]`) && (!strings.Contains(rec.Body.String(), `"slope":0`) || !strings.Contains(rec.Body.String(), `"dataset":""`)) {
+			t.Errorf("stale pooled data in response: %s", rec.Body)
+		}
+		if !strings.Contains(rec.Body.String(), `"engine":"`+analysisEngine+`"`) {
+			t.Errorf("expected a fit by the %s engine: %s", analysisEngine, rec.Body)
+		}
+	}
+}
+
+// ✅ Test 3: Pooled scratch slices give the same fits as fresh ones and are not allocated again
+func TestRegressionScratchPool(t *testing.T) {
+	for name, data := range LoadAnscombeDatasets() {
+		slope, intercept, r2, _, err := performLinearRegression(data.X, data.Y)
+		want, wantIntercept, wantR2, _, _ := performLinearRegressionScratch(data.X, data.Y, new(regressionScratch))
+		if err != nil || slope != want || intercept != wantIntercept || r2 != wantR2 {
+			t.Errorf("%s: pooled fit differs from a fresh one", name)
+		}
+	}
+
+	x, y := syntheticLine(1000)
+	s := new(regressionScratch)
+	performLinearRegressionScratch(x, y, s)
+	if cap(s.x) < 1000 || cap(s.coords) < 1000 {
+		t.Fatal("scratch slices were not kept")
+	}
+	pooled := testing.AllocsPerRun(20, func() { performLinearRegressionScratch(x, y, s) })
+	fresh := testing.AllocsPerRun(20, func() { performLinearRegressionScratch(x, y, new(regressionScratch)) })
+	if pooled >= fresh {
+		t.Errorf("warm scratch allocated %.0f times per fit, fresh scratch %.0f", pooled, fresh)
+	}
+}
+
+// ✅ Benchmark: pooled /fit handler
+func BenchmarkFitHandler(b *testing.B) {
+	handler := NewServer(ServerOptions{})
+	body := `{"x":[10,8,13,9,11,14,6,4,12,7,5],"y":[8.04,6.95,7.58,8.81,8.33,9.96,7.24,4.26,10.84,4.82,5.68]}`
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest("POST", "/fit", strings.NewReader(body)))
+	}
+}
+
+// ✅ Benchmark: pooled scratch slices vs fresh ones on the analysis path
+func BenchmarkPerformLinearRegression(b *testing.B) {
+	x, y := syntheticLine(10_000)
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			performLinearRegression(x, y)
+		}
+	})
+	b.Run("fresh", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			performLinearRegressionScratch(x, y, new(regressionScratch))
+		}
+	})
+}
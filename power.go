@@ -0,0 +1,217 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// PowerMethod selects a variance-stabilizing power transform
+type PowerMethod string
+
+const (
+	PowerNone       PowerMethod = ""
+	PowerLog        PowerMethod = "log"        // natural log, positive values only
+	PowerBoxCox     PowerMethod = "boxcox"     // (v^λ - 1)/λ, positive values only
+	PowerYeoJohnson PowerMethod = "yeojohnson" // Box-Cox extended to zero and negative values
+)
+
+// powerLambdaMin and powerLambdaMax bound the maximum-likelihood search for λ
+const powerLambdaMin, powerLambdaMax = -5.0, 5.0
+
+// PowerTransform is a fitted power transform
+type PowerTransform struct {
+	Method PowerMethod `json:"method"`
+	Lambda float64     `json:"lambda"`
+}
+
+// FitPowerTransform chooses λ by maximizing the profile log-likelihood of the transformed values
+// under normality (as scipy.stats.boxcox and yeojohnson do). The log transform has no parameter.
+func FitPowerTransform(method PowerMethod, values []float64) (PowerTransform, error) {
+	var finite []float64
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		if (method == PowerLog || method == PowerBoxCox) && v <= 0 {
+			return PowerTransform{}, fmt.Errorf("%s transform needs positive values, got %v", method, v)
+		}
+		finite = append(finite, v)
+	}
+
+	switch method {
+	case PowerNone, PowerLog:
+		return PowerTransform{Method: method}, nil
+	case PowerBoxCox, PowerYeoJohnson:
+	default:
+		return PowerTransform{}, fmt.Errorf("unknown power transform %q", method)
+	}
+	if len(finite) < 2 {
+		return PowerTransform{}, fmt.Errorf("need at least two finite values to choose lambda")
+	}
+
+	lambda := goldenSectionMax(func(lambda float64) float64 {
+		return powerLogLikelihood(PowerTransform{Method: method, Lambda: lambda}, finite)
+	}, powerLambdaMin, powerLambdaMax, 1e-8)
+	return PowerTransform{Method: method, Lambda: lambda}, nil
+}
+
+// powerLogLikelihood is the normal log-likelihood of the transformed values, up to a constant,
+// including the Jacobian of the transform: -n/2·log σ² + (λ-1)·Σ log J
+func powerLogLikelihood(p PowerTransform, values []float64) float64 {
+	n := float64(len(values))
+	var mean, m2, logJacobian float64
+	for i, v := range values {
+		t := p.Apply(v)
+		d := t - mean
+		mean += d / float64(i+1)
+		m2 += d * (t - mean)
+
+		if p.Method == PowerYeoJohnson {
+			logJacobian += math.Copysign(1, v) * math.Log1p(math.Abs(v))
+		} else {
+			logJacobian += math.Log(v)
+		}
+	}
+	if m2 <= 0 {
+		return math.Inf(-1)
+	}
+	return -n/2*math.Log(m2/n) + (p.Lambda-1)*logJacobian
+}
+
+// goldenSectionMax finds the maximum of a unimodal function on [lo, hi]
+func goldenSectionMax(f func(float64) float64, lo, hi, tol float64) float64 {
+	invPhi := (math.Sqrt(5) - 1) / 2
+	a, b := lo, hi
+	c, d := b-invPhi*(b-a), a+invPhi*(b-a)
+	fc, fd := f(c), f(d)
+	for b-a > tol {
+		if fc > fd {
+			b, d, fd = d, c, fc
+			c = b - invPhi*(b-a)
+			fc = f(c)
+		} else {
+			a, c, fc = c, d, fd
+			d = a + invPhi*(b-a)
+			fd = f(d)
+		}
+	}
+	return (a + b) / 2
+}
+
+// Apply transforms a single value; values outside the domain give NaN
+func (p PowerTransform) Apply(v float64) float64 {
+	l := p.Lambda
+	switch p.Method {
+	case PowerLog, PowerBoxCox:
+		if v <= 0 {
+			return math.NaN()
+		}
+		if p.Method == PowerLog || l == 0 {
+			return math.Log(v)
+		}
+		return (math.Pow(v, l) - 1) / l
+	case PowerYeoJohnson:
+		switch {
+		case v >= 0 && l != 0:
+			return (math.Pow(v+1, l) - 1) / l
+		case v >= 0:
+			return math.Log1p(v)
+		case l != 2:
+			return -(math.Pow(1-v, 2-l) - 1) / (2 - l)
+		default:
+			return -math.Log1p(-v)
+		}
+	}
+	return v
+}
+
+// Invert maps a transformed value back to the original scale; values outside the range give NaN
+func (p PowerTransform) Invert(t float64) float64 {
+	l := p.Lambda
+	switch p.Method {
+	case PowerLog:
+		return math.Exp(t)
+	case PowerBoxCox:
+		if l == 0 {
+			return math.Exp(t)
+		}
+		return math.Pow(l*t+1, 1/l)
+	case PowerYeoJohnson:
+		switch {
+		case t >= 0 && l != 0:
+			return math.Pow(l*t+1, 1/l) - 1
+		case t >= 0:
+			return math.Expm1(t)
+		case l != 2:
+			return 1 - math.Pow(1-(2-l)*t, 1/(2-l))
+		default:
+			return -math.Expm1(-t)
+		}
+	}
+	return t
+}
+
+// PowerOptions selects the transforms applied to X and Y by FitPower
+type PowerOptions struct {
+	X PowerMethod
+	Y PowerMethod
+}
+
+// PowerFit reports a fit computed on power-transformed data on both scales.
+// The coefficients describe the line in the transformed space; on the original scale the model
+// is the curve Predict(x), and OriginalRSquared measures how well it explains the untransformed Y.
+type PowerFit struct {
+	X                PowerTransform `json:"x"`
+	Y                PowerTransform `json:"y"`
+	Slope            float64        `json:"slope"`
+	Intercept        float64        `json:"intercept"`
+	RSquared         float64        `json:"rSquared"`
+	OriginalRSquared float64        `json:"originalRSquared"`
+}
+
+// Predict evaluates the fitted model at x on the original scale
+func (f PowerFit) Predict(x float64) float64 {
+	return f.Y.Invert(f.Intercept + f.Slope*f.X.Apply(x))
+}
+
+// FitPower chooses λ for each transformed variable, fits a line on the transformed scale and
+// evaluates the back-transformed curve against the original data
+func FitPower(ds Dataset, opts PowerOptions) (PowerFit, error) {
+	px, err := FitPowerTransform(opts.X, ds.X)
+	if err != nil {
+		return PowerFit{}, fmt.Errorf("x: %w", err)
+	}
+	py, err := FitPowerTransform(opts.Y, ds.Y)
+	if err != nil {
+		return PowerFit{}, fmt.Errorf("y: %w", err)
+	}
+
+	tx := make([]float64, len(ds.X))
+	for i, v := range ds.X {
+		tx[i] = px.Apply(v)
+	}
+	ty := make([]float64, len(ds.Y))
+	for i, v := range ds.Y {
+		ty[i] = py.Apply(v)
+	}
+
+	slope, intercept, rSquared, err := FastRegression(tx, ty)
+	if err != nil {
+		return PowerFit{}, err
+	}
+	fit := PowerFit{X: px, Y: py, Slope: slope, Intercept: intercept, RSquared: rSquared}
+
+	// R² of the back-transformed predictions against the untransformed Y
+	var acc OnlineRegression
+	var ssResidual float64
+	for i := range ds.X {
+		predicted := fit.Predict(ds.X[i])
+		if math.IsNaN(predicted) || math.IsInf(predicted, 0) || math.IsNaN(ds.Y[i]) || math.IsInf(ds.Y[i], 0) {
+			continue
+		}
+		acc.Add(ds.X[i], ds.Y[i])
+		ssResidual += (ds.Y[i] - predicted) * (ds.Y[i] - predicted)
+	}
+	fit.OriginalRSquared = rSquaredFrom(acc.cyy, ssResidual)
+	return fit, nil
+}
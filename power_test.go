@@ -0,0 +1,87 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// ✅ Test 1: Maximum-likelihood lambda recovers the power that normalizes the data
+func TestFitPowerTransformLambda(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 4))
+	squared := make([]float64, 2000)
+	lognormal := make([]float64, 2000)
+	for i := range squared {
+		z := rng.NormFloat64()
+		squared[i] = (10 + z) * (10 + z)
+		lognormal[i] = math.Exp(1 + 0.5*z)
+	}
+
+	for _, tc := range []struct {
+		method PowerMethod
+		values []float64
+		want   float64
+	}{
+		{PowerBoxCox, squared, 0.5},
+		{PowerBoxCox, lognormal, 0},
+		{PowerYeoJohnson, squared, 0.5},
+	} {
+		p, err := FitPowerTransform(tc.method, tc.values)
+		if err != nil || math.Abs(p.Lambda-tc.want) > 0.15 {
+			t.Errorf("%s: lambda %v (err %v), want about %v", tc.method, p.Lambda, err, tc.want)
+		}
+		ll := powerLogLikelihood(p, tc.values)
+		for _, step := range []float64{-0.01, 0.01} {
+			if powerLogLikelihood(PowerTransform{Method: p.Method, Lambda: p.Lambda + step}, tc.values) > ll {
+				t.Errorf("%s: lambda %v is not a likelihood maximum", tc.method, p.Lambda)
+			}
+		}
+	}
+
+	if _, err := FitPowerTransform(PowerBoxCox, []float64{1, 0, 2}); err == nil {
+		t.Error("Box-Cox should reject non-positive values")
+	}
+}
+
+// ✅ Test 2: Transforms are reversible over their whole domain
+func TestPowerTransformInvert(t *testing.T) {
+	for _, p := range []PowerTransform{
+		{Method: PowerLog},
+		{Method: PowerBoxCox, Lambda: 0}, {Method: PowerBoxCox, Lambda: 0.5}, {Method: PowerBoxCox, Lambda: -1},
+		{Method: PowerYeoJohnson, Lambda: 0}, {Method: PowerYeoJohnson, Lambda: 2}, {Method: PowerYeoJohnson, Lambda: 0.7},
+	} {
+		for _, v := range []float64{-3.5, -0.2, 0, 0.3, 1, 42} {
+			if v <= 0 && p.Method != PowerYeoJohnson {
+				if !math.IsNaN(p.Apply(v)) {
+					t.Errorf("%s(%v) should be NaN for %v", p.Method, p.Lambda, v)
+				}
+				continue
+			}
+			if got := p.Invert(p.Apply(v)); math.Abs(got-v) > 1e-9 {
+				t.Errorf("%s(%v): invert(apply(%v)) = %v", p.Method, p.Lambda, v, got)
+			}
+		}
+	}
+}
+
+// ✅ Test 3: An exponential trend is linear after a log transform of Y on both scales
+func TestFitPowerExponential(t *testing.T) {
+	var ds Dataset
+	for i := 0; i < 30; i++ {
+		x := float64(i) / 3
+		ds.X = append(ds.X, x)
+		ds.Y = append(ds.Y, 2*math.Exp(0.4*x))
+	}
+
+	fit, err := FitPower(ds, PowerOptions{Y: PowerLog})
+	if err != nil || math.Abs(fit.Slope-0.4) > 1e-12 || math.Abs(fit.Intercept-math.Log(2)) > 1e-12 {
+		t.Fatalf("unexpected fit %+v (err %v)", fit, err)
+	}
+	if math.Abs(fit.RSquared-1) > 1e-12 || math.Abs(fit.OriginalRSquared-1) > 1e-12 {
+		t.Errorf("expected a perfect fit on both scales, got %v and %v", fit.RSquared, fit.OriginalRSquared)
+	}
+
+	if _, _, linearR2, _ := FastRegression(ds.X, ds.Y); linearR2 >= fit.OriginalRSquared {
+		t.Errorf("log model should beat a straight line on the original scale (%v vs %v)", fit.OriginalRSquared, linearR2)
+	}
+}
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// PredictionStats hold what a model needs for intervals around its predictions: the residual
+// standard error with its degrees of freedom, and the unscaled covariance (X'X)⁻¹ of the fitted
+// design, whose rows and columns follow the coefficients with the intercept first
+type PredictionStats struct {
+	DF         int         `json:"df"`
+	ResidualSE float64     `json:"residualSE"`
+	Covariance [][]float64 `json:"covariance"`
+}
+
+// Prediction is a predicted value with a prediction interval for a new observation (Lower, Upper)
+// and a confidence interval for the mean response (MeanLower, MeanUpper) at the requested level
+type Prediction struct {
+	Y         float64 `json:"y"`
+	Lower     float64 `json:"lower"`
+	Upper     float64 `json:"upper"`
+	MeanLower float64 `json:"meanLower"`
+	MeanUpper float64 `json:"meanUpper"`
+	// Warnings lists the inputs outside the training range; the intervals are widened for them.
+	// PredictResponse reports them for the whole request instead.
+	Warnings []ExtrapolationWarning `json:"-"`
+}
+
+// InputRange is the span of one input in the data a model was fitted to
+type InputRange struct {
+	Name string  `json:"name"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+}
+
+// ExtrapolationWarning reports an input value outside the range the model was fitted on.
+// Row is the index of the observation in a batch, such as a /predict request.
+type ExtrapolationWarning struct {
+	Row   int     `json:"row"`
+	Input string  `json:"input"`
+	Value float64 `json:"value"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+}
+
+func (w ExtrapolationWarning) String() string {
+	return fmt.Sprintf("%s = %g is outside the training range [%g, %g]", w.Input, w.Value, w.Min, w.Max)
+}
+
+// inputRanges returns the span of each column, or nil when a column is empty
+func inputRanges(names []string, cols [][]float64) []InputRange {
+	ranges := make([]InputRange, len(cols))
+	for j, col := range cols {
+		if len(col) == 0 {
+			return nil
+		}
+		ranges[j] = InputRange{Name: names[j], Min: col[0], Max: col[0]}
+		for _, v := range col[1:] {
+			ranges[j].Min, ranges[j].Max = math.Min(ranges[j].Min, v), math.Max(ranges[j].Max, v)
+		}
+	}
+	return ranges
+}
+
+// Extrapolation returns a warning for each input of x outside the model's training range. Models
+// saved without Ranges never warn.
+func (m Model) Extrapolation(x ...float64) []ExtrapolationWarning {
+	if len(x) != len(m.Ranges) {
+		return nil
+	}
+	var warnings []ExtrapolationWarning
+	for j, r := range m.Ranges {
+		if x[j] < r.Min || x[j] > r.Max {
+			warnings = append(warnings, ExtrapolationWarning{Input: r.Name, Value: x[j], Min: r.Min, Max: r.Max})
+		}
+	}
+	return warnings
+}
+
+// extrapolationFactor is how much to widen intervals for the warnings: 1 plus the largest distance
+// beyond the training range, measured in widths of that range. The usual intervals only grow with
+// leverage and assume the model form still holds out there; this also allows for it not holding.
+func extrapolationFactor(warnings []ExtrapolationWarning) float64 {
+	factor := 1.0
+	for _, w := range warnings {
+		width := w.Max - w.Min
+		if width == 0 {
+			width = math.Max(math.Abs(w.Max), 1)
+		}
+		outside := math.Max(w.Min-w.Value, w.Value-w.Max) / width
+		factor = math.Max(factor, 1+outside)
+	}
+	return factor
+}
+
+// linePredictionStats computes the statistics of the line fitted to the finite pairs of x and y.
+// It returns nil when there are too few points or X is constant, and intervals are undefined.
+func linePredictionStats(x, y []float64, slope, intercept float64) *PredictionStats {
+	x, y, err := cleanPairs(nil, nil, x, y)
+	if err != nil || len(x) < 3 {
+		return nil
+	}
+	n := float64(len(x))
+	mean, variance := meanVariance(x)
+	sxx := variance * (n - 1)
+	if sxx == 0 {
+		return nil
+	}
+	return &PredictionStats{
+		DF:         len(x) - 2,
+		ResidualSE: math.Sqrt(residualSumSquares(x, y, slope, intercept) / (n - 2)),
+		Covariance: [][]float64{{1/n + mean*mean/sxx, -mean / sxx}, {-mean / sxx, 1 / sxx}},
+	}
+}
+
+// multiPredictionStats computes the statistics of a multiple regression from the rows of md it
+// was fitted to, or returns nil when they leave no residual degrees of freedom
+func multiPredictionStats(fit MultiFit, md MultiDataset) *PredictionStats {
+	cols := make([][]float64, len(fit.Coefficients))
+	var ssRes float64
+	n := 0
+	for i, row := range md.X {
+		if hasMissing(row) || isMissing(md.Y[i]) {
+			continue
+		}
+		cols[0] = append(cols[0], 1)
+		for j, v := range fit.features(row) {
+			cols[j+1] = append(cols[j+1], v)
+		}
+		r := md.Y[i] - fit.Predict(row)
+		ssRes += r * r
+		n++
+	}
+	df := n - len(cols)
+	if df < 1 {
+		return nil
+	}
+	cov, err := unscaledCovariance(cols)
+	if err != nil {
+		return nil
+	}
+	return &PredictionStats{DF: df, ResidualSE: math.Sqrt(ssRes / float64(df)), Covariance: cov}
+}
+
+// unscaledCovariance returns (A'A)⁻¹ for the design matrix A given by columns. It goes through a
+// QR decomposition (A'A = R'R, so the inverse is R⁻¹R⁻ᵀ), which keeps the conditioning of A
+// instead of squaring it as inverting A'A directly would.
+func unscaledCovariance(cols [][]float64) ([][]float64, error) {
+	p := len(cols)
+	q := make([][]float64, p)
+	for j, c := range cols {
+		q[j] = append([]float64(nil), c...)
+	}
+	r := make([][]float64, p)
+	for k := range r {
+		r[k] = make([]float64, p)
+	}
+	// modified Gram-Schmidt
+	for k := 0; k < p; k++ {
+		r[k][k] = norm2(q[k])
+		if r[k][k] <= 1e-10*norm2(cols[k]) {
+			return nil, fmt.Errorf("design column %d is collinear with earlier ones", k)
+		}
+		for i := range q[k] {
+			q[k][i] /= r[k][k]
+		}
+		for j := k + 1; j < p; j++ {
+			for i := range q[k] {
+				r[k][j] += q[k][i] * q[j][i]
+			}
+			for i := range q[j] {
+				q[j][i] -= r[k][j] * q[k][i]
+			}
+		}
+	}
+
+	// invert the upper triangular R column by column
+	inv := make([][]float64, p)
+	for i := range inv {
+		inv[i] = make([]float64, p)
+	}
+	for j := 0; j < p; j++ {
+		inv[j][j] = 1 / r[j][j]
+		for i := j - 1; i >= 0; i-- {
+			var s float64
+			for k := i + 1; k <= j; k++ {
+				s += r[i][k] * inv[k][j]
+			}
+			inv[i][j] = -s / r[i][i]
+		}
+	}
+
+	cov := make([][]float64, p)
+	for i := range cov {
+		cov[i] = make([]float64, p)
+		for j := range cov[i] {
+			for k := max(i, j); k < p; k++ {
+				cov[i][j] += inv[i][k] * inv[j][k]
+			}
+		}
+	}
+	return cov, nil
+}
+
+// PredictInterval predicts like Predict and adds intervals at level (such as 0.95) from the
+// Student t distribution with the fit's residual degrees of freedom. Power models compute them on
+// the transformed scale and transform the bounds back, so they are asymmetric around Y there.
+// Inputs outside the training range are listed in Warnings and widen the intervals (see
+// extrapolationFactor). Models saved without PredictionStats cannot give intervals.
+func (m Model) PredictInterval(level float64, x ...float64) (Prediction, error) {
+	if !(level > 0 && level < 1) {
+		return Prediction{}, fmt.Errorf("interval level must be between 0 and 1, got %v", level)
+	}
+	y, err := m.Predict(x...)
+	if err != nil {
+		return Prediction{}, err
+	}
+	if m.Stats == nil {
+		return Prediction{}, fmt.Errorf("model %s has no statistics for prediction intervals", m.Name)
+	}
+
+	var design []float64
+	center := y
+	switch m.Kind {
+	case ModelLinear:
+		design = []float64{1, x[0]}
+	case ModelMultiple:
+		design = append([]float64{1}, m.Multi.features(x)...)
+	case ModelPower:
+		xt := m.Power.X.Apply(x[0])
+		design = []float64{1, xt}
+		center = m.Power.Intercept + m.Power.Slope*xt
+	}
+	var q float64
+	for i, a := range design {
+		for j, b := range design {
+			q += a * m.Stats.Covariance[i][j] * b
+		}
+	}
+	warnings := m.Extrapolation(x...)
+	t := studentTQuantile(1-(1-level)/2, float64(m.Stats.DF)) * extrapolationFactor(warnings)
+	meanHalf := t * m.Stats.ResidualSE * math.Sqrt(q)
+	half := t * m.Stats.ResidualSE * math.Sqrt(1+q)
+
+	p := Prediction{Y: y, Lower: center - half, Upper: center + half, MeanLower: center - meanHalf, MeanUpper: center + meanHalf, Warnings: warnings}
+	if m.Kind == ModelPower {
+		p.Lower, p.Upper = m.Power.Y.Invert(p.Lower), m.Power.Y.Invert(p.Upper)
+		p.MeanLower, p.MeanUpper = m.Power.Y.Invert(p.MeanLower), m.Power.Y.Invert(p.MeanUpper)
+	}
+	return p, nil
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Student t quantiles match tables
+func TestStudentTQuantile(t *testing.T) {
+	cases := []struct{ p, df, want float64 }{
+		{0.975, 1, 12.7062},
+		{0.975, 10, 2.2281},
+		{0.95, 9, 1.8331},
+		{0.025, 30, -2.0423},
+		{0.5, 4, 0},
+	}
+	for _, c := range cases {
+		if got := studentTQuantile(c.p, c.df); math.Abs(got-c.want) > 1e-4 {
+			t.Errorf("quantile %v with %v df = %v, want %v", c.p, c.df, got, c.want)
+		}
+	}
+	if !math.IsNaN(studentTQuantile(1, 3)) || !math.IsNaN(studentTQuantile(0.9, 0)) {
+		t.Error("expected NaN for invalid arguments")
+	}
+}
+
+// ✅ Test 2: A linear model's intervals follow the textbook formulas and survive saving
+func TestLinearPredictInterval(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	result, err := AnalyzeDataset("I", ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := NewLinearModel(result, ds).Save(&buf, ModelJSON); err != nil {
+		t.Fatal(err)
+	}
+	model, err := LoadModel(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := float64(len(ds.X))
+	mean, variance := meanVariance(ds.X)
+	s := math.Sqrt(residualSumSquares(ds.X, ds.Y, result.Slope, result.Intercept) / (n - 2))
+	tq := studentTQuantile(0.95, n-2)
+	for _, x := range []float64{4, 9, 14} {
+		p, err := model.PredictInterval(0.9, x)
+		if err != nil {
+			t.Fatal(err)
+		}
+		y := result.Intercept + result.Slope*x
+		leverage := 1/n + (x-mean)*(x-mean)/(variance*(n-1))
+		half, meanHalf := tq*s*math.Sqrt(1+leverage), tq*s*math.Sqrt(leverage)
+		if math.Abs(p.Y-y) > 1e-12 || math.Abs(p.Upper-(y+half)) > 1e-9 || math.Abs(p.Lower-(y-half)) > 1e-9 ||
+			math.Abs(p.MeanUpper-(y+meanHalf)) > 1e-9 || math.Abs(p.MeanLower-(y-meanHalf)) > 1e-9 {
+			t.Errorf("x %v: got %+v, want %v ± %v (mean ± %v)", x, p, y, half, meanHalf)
+		}
+	}
+	if _, err := model.PredictInterval(1.5, 4); err == nil {
+		t.Error("expected an error for a level outside (0, 1)")
+	}
+
+	small := NewLinearModel(RegressionResult{Dataset: "two", Slope: 2, Intercept: 1}, Dataset{X: []float64{0, 1}, Y: []float64{1, 3}})
+	if _, err := small.PredictInterval(0.95, 2); small.Stats != nil || err == nil {
+		t.Error("expected no intervals from a line through two points")
+	}
+}
+
+// ✅ Test 3: Multiple regression intervals agree with the line on one predictor; power bounds are back-transformed
+func TestMultipleAndPowerPredictInterval(t *testing.T) {
+	ds := LoadAnscombeDatasets()["III"]
+	result, err := AnalyzeDataset("III", ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	md := MultiDataset{Names: []string{"x"}, Y: ds.Y}
+	for _, x := range ds.X {
+		md.X = append(md.X, []float64{x})
+	}
+	md.X = append(md.X, []float64{math.NaN()})
+	md.Y = append(md.Y, 1)
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, multi := NewLinearModel(result, ds), NewMultipleModel("III", fit, md)
+	if multi.Stats == nil || multi.Stats.DF != len(ds.X)-2 {
+		t.Fatalf("unexpected stats %+v", multi.Stats)
+	}
+	for _, x := range []float64{5, 13} {
+		a, _ := line.PredictInterval(0.95, x)
+		b, _ := multi.PredictInterval(0.95, x)
+		if math.Abs(a.Lower-b.Lower) > 1e-9 || math.Abs(a.Upper-b.Upper) > 1e-9 || math.Abs(a.MeanLower-b.MeanLower) > 1e-9 {
+			t.Errorf("x %v: line %+v, multiple %+v", x, a, b)
+		}
+	}
+
+	curve := Dataset{}
+	for i := 1; i <= 20; i++ {
+		x := float64(i)
+		curve.X = append(curve.X, x)
+		curve.Y = append(curve.Y, 3*math.Pow(x, 1.5)*(1+0.05*math.Sin(x)))
+	}
+	power, err := FitPower(curve, PowerOptions{X: PowerLog, Y: PowerLog})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewPowerModel("curve", power, curve).PredictInterval(0.95, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !(p.Lower > 0 && p.Lower < p.MeanLower && p.MeanLower < p.Y && p.Y < p.MeanUpper && p.MeanUpper < p.Upper) {
+		t.Errorf("unexpected power interval %+v", p)
+	}
+	if p.Upper-p.Y <= p.Y-p.Lower {
+		t.Errorf("expected the back-transformed interval to be skewed upwards, got %+v", p)
+	}
+}
+
+// ✅ Test 4: POST /predict returns intervals and serves registry versions
+func TestPredictEndpointIntervals(t *testing.T) {
+	registry, _ := OpenModelRegistry(t.TempDir())
+	for _, slope := range []float64{1, 2} {
+		if _, err := registry.Save(registryLine("sensor", slope)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	noisy := Dataset{X: []float64{0, 1, 2, 3}, Y: []float64{1, 3.5, 4.5, 7}}
+	result, _ := AnalyzeDataset("noisy", noisy)
+	srv := httptest.NewServer(NewServer(ServerOptions{Models: map[string]Model{"noisy": NewLinearModel(result, noisy)}, Registry: registry}))
+	defer srv.Close()
+
+	post := func(body string) (int, PredictResponse) {
+		resp, err := http.Post(srv.URL+"/predict", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		var out PredictResponse
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp.StatusCode, out
+	}
+
+	code, out := post(`{"model": "noisy", "x": [1, 5], "level": 0.8}`)
+	if code != http.StatusOK || out.Level != 0.8 || len(out.Intervals) != 2 || out.Intervals[1].Y != out.Predictions[1] ||
+		!(out.Intervals[1].Upper-out.Intervals[1].Lower > out.Intervals[0].Upper-out.Intervals[0].Lower) {
+		t.Errorf("got %d %+v", code, out)
+	}
+	if code, out := post(`{"model": "sensor", "x": [10]}`); code != http.StatusOK || out.Version != 2 || out.Predictions[0] != 21 {
+		t.Errorf("latest: got %d %+v", code, out)
+	}
+	if code, out := post(`{"model": "sensor", "version": "v1", "x": [10]}`); code != http.StatusOK || out.Version != 1 || out.Predictions[0] != 11 {
+		t.Errorf("v1: got %d %+v", code, out)
+	}
+	for body, want := range map[string]int{
+		`{"model": "noisy", "x": [1], "level": 1}`:       http.StatusBadRequest,
+		`{"model": "sensor", "version": "v9", "x": [1]}`: http.StatusNotFound,
+		`{"x": [1]}`: http.StatusNotFound,
+	} {
+		if code, _ := post(body); code != want {
+			t.Errorf("%s: got status %d, want %d", body, code, want)
+		}
+	}
+}
+
+// ✅ Test 5: Inputs outside the training range are flagged and widen the intervals
+func TestPredictExtrapolation(t *testing.T) {
+	ds := LoadAnscombeDatasets()["I"]
+	result, _ := AnalyzeDataset("I", ds)
+	model := NewLinearModel(result, ds)
+	if len(model.Ranges) != 1 || model.Ranges[0].Min != 4 || model.Ranges[0].Max != 14 {
+		t.Fatalf("unexpected ranges %+v", model.Ranges)
+	}
+	if w := model.Extrapolation(9); w != nil {
+		t.Errorf("unexpected warnings %v inside the range", w)
+	}
+
+	// 20 is 6 beyond the maximum of a range 10 wide: intervals are 1.6 times as wide
+	inside, _ := model.PredictInterval(0.95, 14)
+	outside, err := model.PredictInterval(0.95, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outside.Warnings) != 1 || outside.Warnings[0].Value != 20 || len(inside.Warnings) != 0 {
+		t.Fatalf("warnings %+v, %+v", inside.Warnings, outside.Warnings)
+	}
+	n := float64(len(ds.X))
+	mean, variance := meanVariance(ds.X)
+	leverage := 1/n + (20-mean)*(20-mean)/(variance*(n-1))
+	s := math.Sqrt(residualSumSquares(ds.X, ds.Y, result.Slope, result.Intercept) / (n - 2))
+	if want := 1.6 * studentTQuantile(0.975, n-2) * s * math.Sqrt(1+leverage); math.Abs(outside.Upper-outside.Y-want) > 1e-9 {
+		t.Errorf("half width %v, want %v", outside.Upper-outside.Y, want)
+	}
+
+	md := MultiDataset{Names: []string{"a", "b"}}
+	for i := 0; i < 12; i++ {
+		a, b := float64(i), float64(i*i%5)
+		md.X = append(md.X, []float64{a, b})
+		md.Y = append(md.Y, 1+a-b)
+	}
+	fit, _ := FitMultiple(md)
+	warnings := NewMultipleModel("plane", fit, md).Extrapolation(-1, 2)
+	if len(warnings) != 1 || warnings[0].Input != "a" || warnings[0].String() != "a = -1 is outside the training range [0, 11]" {
+		t.Errorf("unexpected warnings %v", warnings)
+	}
+
+	// models without interval statistics still warn through /predict
+	two := NewLinearModel(RegressionResult{Dataset: "two", Slope: 2, Intercept: 1}, Dataset{X: []float64{0, 1}, Y: []float64{1, 3}})
+	srv := httptest.NewServer(NewServer(ServerOptions{Models: map[string]Model{"two": two}}))
+	defer srv.Close()
+	resp, err := http.Post(srv.URL+"/predict", "application/json", strings.NewReader(`{"x": [0.5, 3, -2]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var out PredictResponse
+	json.NewDecoder(resp.Body).Decode(&out)
+	if len(out.Warnings) != 2 || out.Warnings[0].Row != 1 || out.Warnings[1].Row != 2 || out.Intervals != nil {
+		t.Errorf("unexpected response %+v", out)
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// QuantileType selects one of the nine sample quantile definitions of Hyndman and Fan (1996),
+// numbered as R's quantile(type = ...). NumPy's method names are given alongside.
+type QuantileType int
+
+const (
+	QuantileType1 QuantileType = iota + 1 // inverse of the empirical CDF (numpy "inverted_cdf")
+	QuantileType2                         // as type 1, averaging at discontinuities (numpy "averaged_inverted_cdf")
+	QuantileType3                         // nearest even order statistic (SAS; numpy "closest_observation")
+	QuantileType4                         // linear interpolation of the empirical CDF (numpy "interpolated_inverted_cdf")
+	QuantileType5                         // piecewise linear with knots at (k - 0.5)/n (numpy "hazen")
+	QuantileType6                         // p(k) = k/(n+1), used by Minitab and SPSS (numpy "weibull")
+	QuantileType7                         // p(k) = (k-1)/(n-1), the R and numpy default (numpy "linear")
+	QuantileType8                         // approximately median-unbiased (numpy "median_unbiased")
+	QuantileType9                         // approximately unbiased for normal data (numpy "normal_unbiased")
+)
+
+// DefaultQuantileType is the definition used when none is chosen, matching R and NumPy
+const DefaultQuantileType = QuantileType7
+
+// Quantile returns the p-th quantile (0 <= p <= 1) of values using definition t.
+// NaN and Inf values are ignored.
+func Quantile(values []float64, p float64, t QuantileType) (float64, error) {
+	qs, err := Quantiles(values, []float64{p}, t)
+	if err != nil {
+		return 0, err
+	}
+	return qs[0], nil
+}
+
+// Quantiles returns several quantiles of values, sorting them only once
+func Quantiles(values []float64, ps []float64, t QuantileType) ([]float64, error) {
+	if t < QuantileType1 || t > QuantileType9 {
+		return nil, fmt.Errorf("unknown quantile type %d (use 1 to 9)", t)
+	}
+	for _, p := range ps {
+		if !(p >= 0 && p <= 1) {
+			return nil, fmt.Errorf("quantile probability must be between 0 and 1, got %v", p)
+		}
+	}
+	sorted := sortedFinite(values)
+	if len(sorted) == 0 {
+		return nil, fmt.Errorf("no finite values")
+	}
+
+	qs := make([]float64, len(ps))
+	for i, p := range ps {
+		qs[i] = quantileSortedType(sorted, p, t)
+	}
+	return qs, nil
+}
+
+// quantileSorted interpolates the q-th quantile of sorted values with the default definition
+func quantileSorted(sorted []float64, q float64) float64 {
+	return quantileSortedType(sorted, q, DefaultQuantileType)
+}
+
+// quantileFuzz absorbs rounding in n*p so that, e.g., p = 0.3 with n = 10 lands exactly on an order
+// statistic; it is the tolerance R's quantile.default uses
+const quantileFuzz = 4 * 2.220446049250313e-16
+
+// quantileSortedType follows R's quantile.default step for step so results agree to the last bit:
+// position j (1-based) and weight h select between order statistics x[j] and x[j+1].
+func quantileSortedType(sorted []float64, p float64, t QuantileType) float64 {
+	n := float64(len(sorted))
+	var j, h float64
+	if t <= QuantileType3 {
+		nppm := n * p
+		if t == QuantileType3 {
+			nppm -= 0.5
+		}
+		j = math.Floor(nppm + quantileFuzz)
+		switch t {
+		case QuantileType1:
+			h = boolFloat(nppm > j)
+		case QuantileType2:
+			h = (boolFloat(nppm > j) + 1) / 2
+		case QuantileType3:
+			h = boolFloat(nppm != j || math.Mod(j, 2) == 1)
+		}
+	} else {
+		a, b := quantileParams(t)
+		nppm := a + p*(n+1-a-b)
+		j = math.Floor(nppm + quantileFuzz)
+		h = nppm - j
+		if math.Abs(h) < quantileFuzz {
+			h = 0
+		}
+	}
+
+	// order statistics outside 1..n clamp to the extremes
+	at := func(k float64) float64 {
+		i := min(max(int(k)-1, 0), len(sorted)-1)
+		return sorted[i]
+	}
+	lo, hi := at(j), at(j+1)
+	switch {
+	case h == 1:
+		return hi
+	case h > 0 && h < 1 && lo != hi:
+		return (1-h)*lo + h*hi
+	}
+	return lo
+}
+
+// quantileParams returns the plotting-position constants a and b of the continuous types 4 to 9
+func quantileParams(t QuantileType) (a, b float64) {
+	switch t {
+	case QuantileType4:
+		return 0, 1
+	case QuantileType5:
+		return 0.5, 0.5
+	case QuantileType6:
+		return 0, 0
+	case QuantileType8:
+		return 1.0 / 3, 1.0 / 3
+	case QuantileType9:
+		return 3.0 / 8, 3.0 / 8
+	}
+	return 1, 1
+}
+
+func boolFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
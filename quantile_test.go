@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: All nine types match R's quantile(1:10, c(0.1, 0.25, 0.5), type = k)
+func TestQuantileTypesMatchR(t *testing.T) {
+	values := []float64{10, 9, 8, 7, 6, 5, 4, 3, 2, 1}
+	ps := []float64{0.1, 0.25, 0.5}
+	want := map[QuantileType][]float64{
+		QuantileType1: {1, 3, 5},
+		QuantileType2: {1.5, 3, 5.5},
+		QuantileType3: {1, 2, 5},
+		QuantileType4: {1, 2.5, 5},
+		QuantileType5: {1.5, 3, 5.5},
+		QuantileType6: {1.1, 2.75, 5.5},
+		QuantileType7: {1.9, 3.25, 5.5},
+		QuantileType8: {1.3666666666666667, 2.9166666666666665, 5.5},
+		QuantileType9: {1.4, 2.9375, 5.5},
+	}
+	for typ, expected := range want {
+		got, err := Quantiles(values, ps, typ)
+		if err != nil {
+			t.Fatalf("type %d: %v", typ, err)
+		}
+		for i := range ps {
+			if math.Abs(got[i]-expected[i]) > 1e-12 {
+				t.Errorf("type %d, p = %v: expected %v, got %v", typ, ps[i], expected[i], got[i])
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Extremes, NaN handling and invalid arguments
+func TestQuantileEdgeCases(t *testing.T) {
+	values := []float64{3, math.NaN(), 1, 2, math.Inf(1)}
+	for typ := QuantileType1; typ <= QuantileType9; typ++ {
+		lo, _ := Quantile(values, 0, typ)
+		hi, _ := Quantile(values, 1, typ)
+		if lo != 1 || hi != 3 {
+			t.Errorf("type %d: expected the range [1, 3], got [%v, %v]", typ, lo, hi)
+		}
+	}
+	if _, err := Quantile(values, 1.5, DefaultQuantileType); err == nil {
+		t.Error("expected an out-of-range probability error")
+	}
+	if _, err := Quantile(values, 0.5, 10); err == nil {
+		t.Error("expected an unknown type error")
+	}
+	if _, err := Quantile([]float64{math.NaN()}, 0.5, DefaultQuantileType); err == nil {
+		t.Error("expected an error without finite values")
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// RationalFit is the exact least-squares fit of a dataset, computed in rational arithmetic
+type RationalFit struct {
+	N         int
+	Slope     *big.Rat
+	Intercept *big.Rat
+	RSquared  *big.Rat
+}
+
+// RationalRegression fits the finite pairs exactly with math/big rationals. Every float64 is a
+// rational number, so the result is the true least-squares fit of the values the float64 engines
+// receive, with no rounding at all; converting it back with Float64 rounds once. The cost grows
+// with the size of the numerators, so it is meant for verifying engines on small datasets.
+func RationalRegression(x, y []float64) (RationalFit, error) {
+	cleanX, cleanY, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return RationalFit{}, err
+	}
+
+	sumX, sumY, sumXY, sumXX, sumYY := new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat)
+	xi, yi, prod := new(big.Rat), new(big.Rat), new(big.Rat)
+	for i := range cleanX {
+		xi.SetFloat64(cleanX[i])
+		yi.SetFloat64(cleanY[i])
+		sumX.Add(sumX, xi)
+		sumY.Add(sumY, yi)
+		sumXY.Add(sumXY, prod.Mul(xi, yi))
+		sumXX.Add(sumXX, prod.Mul(xi, xi))
+		sumYY.Add(sumYY, prod.Mul(yi, yi))
+	}
+
+	n := new(big.Rat).SetInt64(int64(len(cleanX)))
+	sxy := new(big.Rat).Sub(new(big.Rat).Mul(n, sumXY), new(big.Rat).Mul(sumX, sumY))
+	sxx := new(big.Rat).Sub(new(big.Rat).Mul(n, sumXX), new(big.Rat).Mul(sumX, sumX))
+	syy := new(big.Rat).Sub(new(big.Rat).Mul(n, sumYY), new(big.Rat).Mul(sumY, sumY))
+
+	// degenerate cases follow BigFloatRegression
+	fit := RationalFit{N: len(cleanX), Slope: new(big.Rat), RSquared: new(big.Rat)}
+	if sxx.Sign() != 0 {
+		fit.Slope.Quo(sxy, sxx)
+	}
+	fit.Intercept = new(big.Rat).Quo(new(big.Rat).Sub(sumY, new(big.Rat).Mul(fit.Slope, sumX)), n)
+	switch {
+	case syy.Sign() == 0:
+		fit.RSquared.SetInt64(1)
+	case sxx.Sign() != 0:
+		fit.RSquared.Quo(new(big.Rat).Mul(sxy, sxy), new(big.Rat).Mul(sxx, syy))
+	}
+	return fit, nil
+}
+
+// Float64 rounds the exact fit to the nearest float64 values
+func (f RationalFit) Float64() (slope, intercept, rSquared float64) {
+	slope, _ = f.Slope.Float64()
+	intercept, _ = f.Intercept.Float64()
+	rSquared, _ = f.RSquared.Float64()
+	return slope, intercept, rSquared
+}
+
+// ULPError is the exact distance between got and want in units in the last place of want's nearest
+// float64, so 0.5 is the best any float64 result can do. It is +Inf for non-finite got.
+func ULPError(got float64, want *big.Rat) float64 {
+	if math.IsNaN(got) || math.IsInf(got, 0) {
+		return math.Inf(1)
+	}
+	nearest, _ := want.Float64()
+	ulp := math.Nextafter(math.Abs(nearest), math.Inf(1)) - math.Abs(nearest)
+	if nearest == 0 {
+		ulp = math.SmallestNonzeroFloat64
+	}
+	diff := new(big.Rat).Sub(new(big.Rat).SetFloat64(got), want)
+	diff.Abs(diff)
+	e, _ := diff.Quo(diff, new(big.Rat).SetFloat64(ulp)).Float64()
+	return e
+}
+
+// EngineULPs is the error of one engine against the exact fit, in units in the last place
+type EngineULPs struct {
+	Engine    string
+	Slope     float64
+	Intercept float64
+	RSquared  float64
+}
+
+// Max is the largest of the three errors
+func (e EngineULPs) Max() float64 {
+	return max(e.Slope, e.Intercept, e.RSquared)
+}
+
+// VerifyEngines fits ds exactly and with each engine (every registered engine when none are
+// given) and measures every engine's error in ULPs. An engine that rejects data the exact fit
+// accepts is an error.
+func VerifyEngines(ds Dataset, engines ...Engine) (RationalFit, []EngineULPs, error) {
+	exact, err := RationalRegression(ds.X, ds.Y)
+	if err != nil {
+		return RationalFit{}, nil, err
+	}
+	if len(engines) == 0 {
+		engines = Engines()
+	}
+	var errs []EngineULPs
+	for _, e := range engines {
+		slope, intercept, r2, err := e.Fit(ds.X, ds.Y)
+		if err != nil {
+			return exact, errs, fmt.Errorf("engine %s: %w", e.Name, err)
+		}
+		errs = append(errs, EngineULPs{
+			Engine:    e.Name,
+			Slope:     ULPError(slope, exact.Slope),
+			Intercept: ULPError(intercept, exact.Intercept),
+			RSquared:  ULPError(r2, exact.RSquared),
+		})
+	}
+	return exact, errs, nil
+}
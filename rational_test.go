@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// ✅ Test 1: The exact fit of a hand-checkable dataset has rational coefficients
+func TestRationalRegression(t *testing.T) {
+	// y = 1 + 2x with residuals +1, -1, -1, +1: SSR 20 of SST 24
+	fit, err := RationalRegression([]float64{1, 2, 3, 4, math.NaN()}, []float64{4, 4, 6, 10, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fit.N != 4 || fit.Slope.Cmp(big.NewRat(2, 1)) != 0 || fit.Intercept.Cmp(big.NewRat(1, 1)) != 0 || fit.RSquared.Cmp(big.NewRat(5, 6)) != 0 {
+		t.Errorf("got slope %v, intercept %v, R² %v", fit.Slope, fit.Intercept, fit.RSquared)
+	}
+
+	fit, err = RationalRegression([]float64{2, 2, 2}, []float64{1, 2, 3})
+	if err != nil || fit.Slope.Sign() != 0 || fit.Intercept.Cmp(big.NewRat(2, 1)) != 0 || fit.RSquared.Sign() != 0 {
+		t.Errorf("vertical X: got %+v, %v", fit, err)
+	}
+	if _, err := RationalRegression([]float64{1}, []float64{1}); err == nil {
+		t.Error("expected an error for a single point")
+	}
+
+	if e := ULPError(1, big.NewRat(1, 1)); e != 0 {
+		t.Errorf("ULPError of an exact value = %v", e)
+	}
+	if e := ULPError(math.Nextafter(1, 2), big.NewRat(1, 1)); e != 1 {
+		t.Errorf("ULPError of the next float64 = %v", e)
+	}
+}
+
+// ✅ Test 2: The six-decimal constants of R's lm() and Python's linregress are the exact fits, rounded
+func TestRationalMatchesPublishedAnscombe(t *testing.T) {
+	published := map[string][3]float64{
+		"I":   {0.500091, 3.000091, 0.666542},
+		"II":  {0.500000, 3.000909, 0.666242},
+		"III": {0.499727, 3.002455, 0.666324},
+		"IV":  {0.499909, 3.001727, 0.666707},
+	}
+	for name, ds := range LoadAnscombeDatasets() {
+		exact, err := RationalRegression(ds.X, ds.Y)
+		if err != nil {
+			t.Fatal(err)
+		}
+		slope, intercept, r2 := exact.Float64()
+		for i, got := range []float64{slope, intercept, r2} {
+			if math.Abs(got-published[name][i]) > 5e-7 {
+				t.Errorf("dataset %s: exact value %d is %.9f, published %.6f", name, i, got, published[name][i])
+			}
+		}
+	}
+}
+
+// engineULPBounds are the largest errors, in ULPs, each engine may make on the quartet.
+// bigfloat rounds once from 256 bits, so it is correctly rounded; float32 keeps about 24 of 53 bits.
+// The manual engine's bound depends on the build's kernels (kernels_*_test.go).
+var engineULPBounds = map[string]float64{
+	"bigfloat": 0.5, "compensated": 2, "manual": manualULPBound, "fast": 32, "parallel": 32, "stats": 128, "float32": 1e9,
+}
+
+// ✅ Test 3: Each engine stays within its ULP bound of the exact fit on the quartet
+func TestVerifyEnginesAnscombe(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		_, errs, err := VerifyEngines(ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range errs {
+			bound, ok := engineULPBounds[e.Engine]
+			if !ok {
+				t.Errorf("no ULP bound for engine %s", e.Engine)
+				continue
+			}
+			t.Logf("%-4s %-12s slope %.3g, intercept %.3g, R² %.3g ULPs", name, e.Engine, e.Slope, e.Intercept, e.RSquared)
+			if e.Max() > bound {
+				t.Errorf("dataset %s: engine %s is %.3g ULPs off, beyond %g", name, e.Engine, e.Max(), bound)
+			}
+		}
+	}
+}
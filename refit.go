@@ -0,0 +1,79 @@
+package main
+
+import "fmt"
+
+// LineFit is a least-squares line fitted to a dataset, kept with the dataset's moments so that
+// what-if refits without some of its points cost one downdate per excluded point instead of a
+// pass over the data. A LineFit is not modified by its refits and is safe for concurrent use once
+// fitted.
+type LineFit struct {
+	OnlineSnapshot
+	data Dataset
+	acc  OnlineRegression
+}
+
+// LineRefit is the line refitted without some points, and how far excluding them moved it
+type LineRefit struct {
+	OnlineSnapshot
+	Without         []int   `json:"without"`
+	SlopeChange     float64 `json:"slopeChange"`
+	InterceptChange float64 `json:"interceptChange"`
+}
+
+// FitLine fits a line to the finite pairs of d.
+func FitLine(d Dataset) (*LineFit, error) {
+	if err := checkPairLengths(len(d.X), len(d.Y)); err != nil {
+		return nil, err
+	}
+	f := &LineFit{data: d}
+	for i := range d.X {
+		f.acc.Add(d.X[i], d.Y[i])
+	}
+	if err := checkValidPairs(f.acc.N()); err != nil {
+		return nil, err
+	}
+	f.OnlineSnapshot = f.acc.Snapshot()
+	return f, nil
+}
+
+// RefitWithout refits the line without the points at indices, which index the dataset as
+// PointDiagnostic.Index does. Each point is downdated out of the fit's moments (see
+// OnlineRegression.Remove), so a refit costs O(len(indices)) whatever the size of the dataset.
+// NaN/Inf points were never in the fit and excluding them changes nothing. It is an error to
+// exclude a point twice, to leave fewer than two points, or to leave a single X value of a fit
+// that had several: the points left would not determine a slope, as when the only point off a
+// vertical line (of leverage 1) is excluded.
+func (f *LineFit) RefitWithout(indices ...int) (LineRefit, error) {
+	acc := f.acc
+	seen := make(map[int]bool, len(indices))
+	for _, i := range indices {
+		if i < 0 || i >= len(f.data.X) {
+			return LineRefit{}, fmt.Errorf("point %d is out of range (have %d)", i, len(f.data.X))
+		}
+		if seen[i] {
+			return LineRefit{}, fmt.Errorf("point %d is excluded twice", i)
+		}
+		seen[i] = true
+		acc.Remove(f.data.X[i], f.data.Y[i])
+	}
+	if acc.n < 2 {
+		return LineRefit{}, fmt.Errorf("excluding %d points leaves %d, need at least two", len(indices), acc.n)
+	}
+	if f.acc.cxx > 0 && acc.cxx <= refitConstantX*f.acc.cxx {
+		return LineRefit{}, fmt.Errorf("the points left share one X value, so the slope is undefined")
+	}
+
+	r := LineRefit{OnlineSnapshot: acc.Snapshot(), Without: append([]int{}, indices...)}
+	r.SlopeChange = r.Slope - f.Slope
+	r.InterceptChange = r.Intercept - f.Intercept
+	return r, nil
+}
+
+// refitConstantX is the fraction of the fit's X sum of squares below which what a downdate leaves
+// is taken for cancellation error, and the X values left for constant
+const refitConstantX = 1e-12
+
+// Dataset returns the dataset the line was fitted to
+func (f *LineFit) Dataset() Dataset {
+	return f.data
+}
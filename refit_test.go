@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Refitting without points matches a full fit of the points left
+func TestRefitWithoutMatchesFullFit(t *testing.T) {
+	data := LoadAnscombeDatasets()["III"]
+	fit, err := FitLine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	without := []int{2, 7}
+	refit, err := fit.RefitWithout(without...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var x, y []float64
+	for i := range data.X {
+		if i != 2 && i != 7 {
+			x, y = append(x, data.X[i]), append(y, data.Y[i])
+		}
+	}
+	slope, intercept, r2, _, err := performLinearRegression(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refit.N != len(x) || math.Abs(refit.Slope-slope) > 1e-12 || math.Abs(refit.Intercept-intercept) > 1e-12 || math.Abs(refit.RSquared-r2) > 1e-12 {
+		t.Errorf("refit %+v, full fit slope %g intercept %g r2 %g", refit, slope, intercept, r2)
+	}
+	if math.Abs(refit.SlopeChange-(slope-fit.Slope)) > 1e-12 {
+		t.Errorf("expected slope change %g, got %g", slope-fit.Slope, refit.SlopeChange)
+	}
+	if again, _ := fit.RefitWithout(); again.Slope != fit.Slope || again.N != fit.N {
+		t.Errorf("expected the fit unchanged by refits, got %+v", again)
+	}
+}
+
+// ✅ Test 2: The slope change of each single-point refit matches its influence diagnostic
+func TestRefitWithoutMatchesDiagnostics(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	fit, err := FitLine(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range DatasetDiagnostics(data, fit.Slope, fit.Intercept) {
+		refit, err := fit.RefitWithout(d.Index)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(refit.SlopeChange-d.SlopeChange) > 1e-9 {
+			t.Errorf("point %d: refit slope change %g, diagnostic %g", d.Index, refit.SlopeChange, d.SlopeChange)
+		}
+	}
+}
+
+// ✅ Test 3: Refits that would not determine a line are rejected
+func TestRefitWithoutErrors(t *testing.T) {
+	fit, err := FitLine(LoadAnscombeDatasets()["IV"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		without []int
+		want    string
+	}{
+		{[]int{11}, "out of range"},
+		{[]int{-1}, "out of range"},
+		{[]int{1, 1}, "excluded twice"},
+		{[]int{7}, "one X value"}, // the point at x = 19 has leverage 1
+		{[]int{0, 1, 2, 3, 4, 5, 6, 8, 9, 10}, "need at least two"},
+	}
+	for _, c := range cases {
+		if _, err := fit.RefitWithout(c.without...); err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%v: expected an error containing %q, got %v", c.without, c.want, err)
+		}
+	}
+
+	nan := Dataset{X: []float64{1, 2, math.NaN(), 4}, Y: []float64{2, 4, 5, 8}}
+	if fit, err := FitLine(nan); err != nil {
+		t.Fatal(err)
+	} else if refit, err := fit.RefitWithout(2); err != nil || refit.N != 3 || math.Abs(refit.Slope-2) > 1e-12 {
+		t.Errorf("expected excluding a NaN point to change nothing, got %+v (err %v)", refit, err)
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// ModelRegistry stores successive versions of named models on disk, so a model refitted by a
+// batch job or a monitor keeps its history. Each model has a directory holding one JSON file per
+// version (v1.json, v2.json, ...) and tags.json, which maps tags such as "production" to versions.
+// Versions are never rewritten; saving from several processes at once is safe.
+type ModelRegistry struct {
+	Dir string
+}
+
+// RegistryEntry describes one stored version of a model
+type RegistryEntry struct {
+	Name    string    `json:"name"`
+	Version int       `json:"version"`
+	Kind    ModelKind `json:"kind"`
+	Trained time.Time `json:"trained"`
+	N       int       `json:"n"`
+	Tags    []string  `json:"tags,omitempty"`
+}
+
+// OpenModelRegistry opens the registry in dir, creating the directory if needed
+func OpenModelRegistry(dir string) (*ModelRegistry, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &ModelRegistry{Dir: dir}, nil
+}
+
+func (r *ModelRegistry) modelDir(name string) string {
+	return filepath.Join(r.Dir, strings.TrimSuffix(modelFileName(name), ".json"))
+}
+
+func versionFile(version int) string {
+	return fmt.Sprintf("v%d.json", version)
+}
+
+// versions lists the stored versions of a model in increasing order
+func (r *ModelRegistry) versions(name string) ([]int, error) {
+	entries, err := os.ReadDir(r.modelDir(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var versions []int
+	for _, e := range entries {
+		var v int
+		if _, err := fmt.Sscanf(e.Name(), "v%d.json", &v); err == nil && versionFile(v) == e.Name() {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// Save stores m as the next version of the model named m.Name and returns that version
+func (r *ModelRegistry) Save(m Model) (int, error) {
+	if m.Name == "" {
+		return 0, fmt.Errorf("cannot register a model without a name")
+	}
+	dir := r.modelDir(m.Name)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return 0, err
+	}
+	var buf strings.Builder
+	if err := m.Save(&buf, ModelJSON); err != nil {
+		return 0, err
+	}
+	versions, err := r.versions(m.Name)
+	if err != nil {
+		return 0, err
+	}
+	next := 1
+	if len(versions) > 0 {
+		// names that differ only in characters unsafe in file names share a directory
+		if first, err := LoadModelFile(filepath.Join(dir, versionFile(versions[0]))); err == nil && first.Name != m.Name {
+			return 0, fmt.Errorf("model %q would share the directory of model %q", m.Name, first.Name)
+		}
+		next = versions[len(versions)-1] + 1
+	}
+	// O_EXCL claims the version: a concurrent writer that took it sends us on to the next one
+	for ; ; next++ {
+		f, err := os.OpenFile(filepath.Join(dir, versionFile(next)), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.WriteString(f, buf.String()); err != nil {
+			f.Close()
+			return 0, err
+		}
+		return next, f.Close()
+	}
+}
+
+func (r *ModelRegistry) readTags(name string) (map[string]int, error) {
+	tags := map[string]int{}
+	data, err := os.ReadFile(filepath.Join(r.modelDir(name), "tags.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return tags, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &tags); err != nil {
+		return nil, fmt.Errorf("reading tags of model %s: %w", name, err)
+	}
+	return tags, nil
+}
+
+// Tag points tag at a stored version of a model, moving it if it named another version.
+// Tags that look like versions ("3", "v3") or "latest" are refused, since Load resolves those itself.
+func (r *ModelRegistry) Tag(name string, version int, tag string) error {
+	if tag == "" || tag == "latest" || strings.ContainsAny(tag, "/\\") {
+		return fmt.Errorf("invalid tag %q", tag)
+	}
+	if _, ok := parseVersionRef(tag); ok {
+		return fmt.Errorf("tag %q would shadow a version number", tag)
+	}
+	if _, err := os.Stat(filepath.Join(r.modelDir(name), versionFile(version))); err != nil {
+		return fmt.Errorf("model %s has no version %d", name, version)
+	}
+	tags, err := r.readTags(name)
+	if err != nil {
+		return err
+	}
+	tags[tag] = version
+	data, err := json.MarshalIndent(tags, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write then rename, so readers never see a partial tags file
+	tmp := filepath.Join(r.modelDir(name), fmt.Sprintf(".tags-%d.json", os.Getpid()))
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filepath.Join(r.modelDir(name), "tags.json"))
+}
+
+func parseVersionRef(ref string) (int, bool) {
+	v, err := strconv.Atoi(strings.TrimPrefix(ref, "v"))
+	return v, err == nil && v > 0
+}
+
+// Load reads a version of a model. ref is a version number ("3" or "v3"), a tag, or "latest"
+// (also the meaning of an empty ref). It returns the model with the version it resolved to.
+func (r *ModelRegistry) Load(name, ref string) (Model, int, error) {
+	version, ok := parseVersionRef(ref)
+	if !ok {
+		if ref == "" || ref == "latest" {
+			versions, err := r.versions(name)
+			if err != nil {
+				return Model{}, 0, err
+			}
+			if len(versions) == 0 {
+				return Model{}, 0, fmt.Errorf("no model named %s in %s", name, r.Dir)
+			}
+			version = versions[len(versions)-1]
+		} else {
+			tags, err := r.readTags(name)
+			if err != nil {
+				return Model{}, 0, err
+			}
+			if version, ok = tags[ref]; !ok {
+				return Model{}, 0, fmt.Errorf("model %s has no tag %q", name, ref)
+			}
+		}
+	}
+	m, err := LoadModelFile(filepath.Join(r.modelDir(name), versionFile(version)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Model{}, 0, fmt.Errorf("model %s has no version %d", name, version)
+	}
+	return m, version, err
+}
+
+// List returns every stored version of a model, oldest first, with its tags
+func (r *ModelRegistry) List(name string) ([]RegistryEntry, error) {
+	versions, err := r.versions(name)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := r.readTags(name)
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int][]string{}
+	for tag, v := range tags {
+		byVersion[v] = append(byVersion[v], tag)
+	}
+	entries := make([]RegistryEntry, 0, len(versions))
+	for _, v := range versions {
+		m, err := LoadModelFile(filepath.Join(r.modelDir(name), versionFile(v)))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(byVersion[v])
+		entries = append(entries, RegistryEntry{Name: m.Name, Version: v, Kind: m.Kind, Trained: m.Trained, N: m.N, Tags: byVersion[v]})
+	}
+	return entries, nil
+}
+
+// Names returns the names of the registered models, sorted
+func (r *ModelRegistry) Names() ([]string, error) {
+	dirs, err := os.ReadDir(r.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		versions, err := r.versions(d.Name())
+		if err != nil || len(versions) == 0 {
+			continue
+		}
+		m, err := LoadModelFile(filepath.Join(r.Dir, d.Name(), versionFile(versions[0])))
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, m.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// printRegistryEntries writes the versions of a model as a table, with the coefficients of each
+func printRegistryEntries(w io.Writer, r *ModelRegistry, entries []RegistryEntry) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Model\tVersion\tTrained\tN\tFit\tTags\t")
+	for _, e := range entries {
+		fit := ""
+		if m, _, err := r.Load(e.Name, strconv.Itoa(e.Version)); err == nil && m.Kind == ModelLinear {
+			fit = fmt.Sprintf("y = %.6g + %.6g x (R² %.4f)", m.Line.Intercept, m.Line.Slope, m.Line.RSquared)
+		} else if err == nil && m.Kind == ModelMultiple {
+			fit = fmt.Sprintf("%d terms (R² %.4f)", len(m.Multi.Names), m.Multi.RSquared)
+		}
+		fmt.Fprintf(tw, "%s\tv%d\t%s\t%d\t%s\t%s\t\n", e.Name, e.Version, e.Trained.Format(time.RFC3339), e.N, fit, strings.Join(e.Tags, ","))
+	}
+	tw.Flush()
+}
+
+// runModels implements the models subcommand for inspecting and tagging a model registry
+func runModels(args []string) error {
+	flags := flag.NewFlagSet("models", flag.ContinueOnError)
+	dir := flags.String("registry", "models", "model registry directory")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: models [-registry DIR] list [name] | tag name version tag | show name [version|tag]\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	r, err := OpenModelRegistry(*dir)
+	if err != nil {
+		return err
+	}
+	args = flags.Args()
+	switch {
+	case len(args) == 1 && args[0] == "list":
+		names, err := r.Names()
+		if err != nil {
+			return err
+		}
+		var entries []RegistryEntry
+		for _, name := range names {
+			versions, err := r.List(name)
+			if err != nil {
+				return err
+			}
+			entries = append(entries, versions...)
+		}
+		printRegistryEntries(os.Stdout, r, entries)
+	case len(args) == 2 && args[0] == "list":
+		entries, err := r.List(args[1])
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			return fmt.Errorf("no model named %s in %s", args[1], *dir)
+		}
+		printRegistryEntries(os.Stdout, r, entries)
+	case len(args) == 4 && args[0] == "tag":
+		version, ok := parseVersionRef(args[2])
+		if !ok {
+			return fmt.Errorf("invalid version %q", args[2])
+		}
+		if err := r.Tag(args[1], version, args[3]); err != nil {
+			return err
+		}
+		fmt.Printf("Tagged %s v%d as %s\n", args[1], version, args[3])
+	case (len(args) == 2 || len(args) == 3) && args[0] == "show":
+		ref := ""
+		if len(args) == 3 {
+			ref = args[2]
+		}
+		m, _, err := r.Load(args[1], ref)
+		if err != nil {
+			return err
+		}
+		return m.Save(os.Stdout, ModelJSON)
+	default:
+		flags.Usage()
+		return fmt.Errorf("unknown models command %q", strings.Join(args, " "))
+	}
+	return nil
+}
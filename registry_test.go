@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func registryLine(name string, slope float64) Model {
+	return NewLinearModel(RegressionResult{Dataset: name, Slope: slope, Intercept: 1}, Dataset{X: []float64{0, 1, 2}, Y: []float64{1, 1 + slope, 1 + 2*slope}})
+}
+
+// ✅ Test 1: Versions accumulate and load by number, tag or latest
+func TestModelRegistryVersions(t *testing.T) {
+	r, err := OpenModelRegistry(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, slope := range []float64{1, 2, 3} {
+		version, err := r.Save(registryLine("sensor/a", slope))
+		if err != nil || version != i+1 {
+			t.Fatalf("save %d: version %d, %v", i, version, err)
+		}
+	}
+	if _, err := r.Save(registryLine("other", 5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Tag("sensor/a", 2, "production"); err != nil {
+		t.Fatal(err)
+	}
+
+	for ref, want := range map[string]float64{"": 3, "latest": 3, "1": 1, "v2": 2, "production": 2} {
+		m, _, err := r.Load("sensor/a", ref)
+		if err != nil || m.Line.Slope != want {
+			t.Errorf("ref %q: got %+v, %v; want slope %v", ref, m.Line, err, want)
+		}
+	}
+	if err := r.Tag("sensor/a", 3, "production"); err != nil {
+		t.Fatal(err)
+	}
+	if _, version, _ := r.Load("sensor/a", "production"); version != 3 {
+		t.Errorf("moved tag resolves to v%d", version)
+	}
+
+	entries, err := r.List("sensor/a")
+	if err != nil || len(entries) != 3 || entries[2].Version != 3 || len(entries[2].Tags) != 1 || entries[1].Tags != nil {
+		t.Errorf("unexpected entries %+v, %v", entries, err)
+	}
+	if names, err := r.Names(); err != nil || strings.Join(names, ",") != "other,sensor/a" {
+		t.Errorf("names %v, %v", names, err)
+	}
+
+	var out bytes.Buffer
+	printRegistryEntries(&out, r, entries)
+	if !strings.Contains(out.String(), "y = 1 + 3 x") || !strings.Contains(out.String(), "production") {
+		t.Errorf("unexpected table:\n%s", out.String())
+	}
+}
+
+// ✅ Test 2: Bad references, tags and colliding names are rejected
+func TestModelRegistryErrors(t *testing.T) {
+	r, _ := OpenModelRegistry(t.TempDir())
+	if _, err := r.Save(registryLine("a/b", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Save(registryLine("a_b", 1)); err == nil {
+		t.Error("expected names sharing a directory to be rejected")
+	}
+	for _, tag := range []string{"", "latest", "3", "v1", "a/b"} {
+		if err := r.Tag("a/b", 1, tag); err == nil {
+			t.Errorf("tag %q: expected an error", tag)
+		}
+	}
+	if err := r.Tag("a/b", 2, "prod"); err == nil {
+		t.Error("expected tagging a missing version to fail")
+	}
+	for _, ref := range []string{"v9", "prod"} {
+		if _, _, err := r.Load("a/b", ref); err == nil {
+			t.Errorf("ref %q: expected an error", ref)
+		}
+	}
+	if _, _, err := r.Load("missing", ""); err == nil {
+		t.Error("expected an error for an unknown model")
+	}
+}
+
+// ✅ Test 3: Concurrent saves get distinct versions
+func TestModelRegistryConcurrentSave(t *testing.T) {
+	r, _ := OpenModelRegistry(t.TempDir())
+	var wg sync.WaitGroup
+	versions := make([]int, 8)
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := r.Save(registryLine("shared", float64(i)))
+			if err != nil {
+				t.Error(err)
+			}
+			versions[i] = v
+		}()
+	}
+	wg.Wait()
+	seen := map[int]bool{}
+	for _, v := range versions {
+		if seen[v] || v < 1 || v > len(versions) {
+			t.Errorf("versions %v are not 1..%d", versions, len(versions))
+			break
+		}
+		seen[v] = true
+	}
+}
+
+// ✅ Test 4: A device's model is registered as its baseline, then on drift
+func TestRegisterDeviceModel(t *testing.T) {
+	r, _ := OpenModelRegistry(t.TempDir())
+	monitor := NewTelemetryMonitor(5, 0.5)
+	for i := 0; i < 5; i++ {
+		monitor.Observe(TelemetryReading{Device: "d1", X: float64(i), Y: float64(2 * i)})
+	}
+	data, fit, ok := monitor.Latest("d1")
+	if !ok {
+		t.Fatal("window should be full")
+	}
+	if err := registerDeviceModel(r, data, fit, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := registerDeviceModel(r, data, fit, false); err != nil {
+		t.Fatal(err)
+	}
+	m, version, err := r.Load("d1", "baseline")
+	if err != nil || version != 1 || m.N != 5 || m.Line.Slope < 2-1e-9 || m.Line.Slope > 2+1e-9 {
+		t.Errorf("baseline: %+v v%d, %v", m.Line, version, err)
+	}
+}
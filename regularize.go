@@ -0,0 +1,402 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Penalty is an elastic-net penalty λ·(α·Σ|b| + (1-α)/2·Σb²) on the coefficients of standardized
+// predictors, as in glmnet: Alpha 0 is ridge regression, 1 the lasso and values between mix them.
+// The intercept is never penalized.
+type Penalty struct {
+	Lambda float64 `json:"lambda"`
+	Alpha  float64 `json:"alpha"`
+}
+
+// String returns the command-line form of the penalty's mix, as ParsePenaltyMix reads it
+func (p Penalty) String() string {
+	switch p.Alpha {
+	case 0:
+		return "ridge"
+	case 1:
+		return "lasso"
+	}
+	return fmt.Sprintf("elasticnet:%g", p.Alpha)
+}
+
+// ParsePenaltyMix parses ridge, lasso or elasticnet:ALPHA into the penalty's Alpha
+func ParsePenaltyMix(spec string) (float64, error) {
+	name, arg, _ := strings.Cut(spec, ":")
+	switch name {
+	case "ridge":
+		return 0, nil
+	case "lasso":
+		return 1, nil
+	case "elasticnet":
+		alpha, err := strconv.ParseFloat(arg, 64)
+		if err != nil || !(alpha >= 0 && alpha <= 1) {
+			return 0, fmt.Errorf("invalid penalty %q: elasticnet needs a mix between 0 and 1, as in elasticnet:0.5", spec)
+		}
+		return alpha, nil
+	}
+	return 0, fmt.Errorf("unknown penalty %q (use ridge, lasso or elasticnet:ALPHA)", spec)
+}
+
+// standardized is the complete rows of a MultiDataset with centred, unit-variance predictors
+// and a centred response, which is the scale the penalty applies on
+type standardized struct {
+	x            [][]float64 // by column
+	y            []float64
+	meanX, scale []float64 // scale 0 marks a constant predictor
+	meanY        float64
+}
+
+func standardize(md MultiDataset) (standardized, error) {
+	if len(md.X) != len(md.Y) {
+		return standardized{}, fmt.Errorf("x and y length mismatch: %d rows vs %d responses", len(md.X), len(md.Y))
+	}
+	p := len(md.Names)
+	s := standardized{x: make([][]float64, p), meanX: make([]float64, p), scale: make([]float64, p)}
+	for i, row := range md.X {
+		if len(row) != p {
+			return standardized{}, fmt.Errorf("row %d has %d predictors, expected %d", i, len(row), p)
+		}
+		if isMissing(md.Y[i]) || hasMissing(row) {
+			continue
+		}
+		for j, v := range row {
+			s.x[j] = append(s.x[j], v)
+		}
+		s.y = append(s.y, md.Y[i])
+	}
+	if len(s.y) < 3 {
+		return standardized{}, fmt.Errorf("need at least three complete rows, have %d", len(s.y))
+	}
+	s.meanY, _ = meanVariance(s.y)
+	for i := range s.y {
+		s.y[i] -= s.meanY
+	}
+	n := float64(len(s.y))
+	for j, col := range s.x {
+		mean, variance := meanVariance(col)
+		s.meanX[j] = mean
+		// glmnet's convention: the 1/n variance, so Σx² = n on the standardized scale
+		if sd := math.Sqrt(variance * (n - 1) / n); sd > 0 {
+			s.scale[j] = sd
+		}
+		for i := range col {
+			if s.scale[j] > 0 {
+				col[i] = (col[i] - mean) / s.scale[j]
+			} else {
+				col[i] = 0
+			}
+		}
+	}
+	return s, nil
+}
+
+// lambdaMax is the smallest λ at which every coefficient is zero (for ridge, where none ever is,
+// that of alpha = 0.001)
+func (s standardized) lambdaMax(alpha float64) float64 {
+	var m float64
+	for _, col := range s.x {
+		var dot float64
+		for i, v := range col {
+			dot += v * s.y[i]
+		}
+		m = math.Max(m, math.Abs(dot))
+	}
+	return m / float64(len(s.y)) / math.Max(alpha, 1e-3)
+}
+
+// descend minimizes the penalized loss (1/2n)·||y - Xb||² + penalty by cyclic coordinate descent,
+// starting from and overwriting b (standardized coefficients)
+func (s standardized) descend(b []float64, pen Penalty) {
+	n := float64(len(s.y))
+	residual := append([]float64(nil), s.y...)
+	for j, col := range s.x {
+		for i, v := range col {
+			residual[i] -= b[j] * v
+		}
+	}
+	l1, l2 := pen.Lambda*pen.Alpha, pen.Lambda*(1-pen.Alpha)
+	for sweep := 0; sweep < 10000; sweep++ {
+		var change float64
+		for j, col := range s.x {
+			if s.scale[j] == 0 {
+				continue
+			}
+			// with Σx² = n, the partial residual's correlation is rho/n + b[j]
+			var rho float64
+			for i, v := range col {
+				rho += v * residual[i]
+			}
+			z := rho/n + b[j]
+			next := math.Copysign(math.Max(math.Abs(z)-l1, 0), z) / (1 + l2)
+			if next == 0 {
+				next = 0 // not -0, which would print as -0.000000
+			}
+			if delta := next - b[j]; delta != 0 {
+				for i, v := range col {
+					residual[i] -= delta * v
+				}
+				change = math.Max(change, math.Abs(delta))
+				b[j] = next
+			}
+		}
+		if change < 1e-8 {
+			return
+		}
+	}
+}
+
+// fit converts standardized coefficients into a MultiFit on the original scale
+func (s standardized) fit(md MultiDataset, b []float64, pen Penalty) MultiFit {
+	fit := MultiFit{Names: md.Names, Coefficients: make([]float64, len(b)+1), N: len(s.y), Encodings: md.Encodings, Penalty: &pen}
+	fit.Coefficients[0] = s.meanY
+	for j := range b {
+		if s.scale[j] > 0 {
+			fit.Coefficients[j+1] = b[j] / s.scale[j]
+			fit.Coefficients[0] -= fit.Coefficients[j+1] * s.meanX[j]
+		}
+	}
+	var ssTotal, ssResidual float64
+	for i, yc := range s.y {
+		pred := 0.0
+		for j := range b {
+			pred += b[j] * s.x[j][i]
+		}
+		ssTotal += yc * yc
+		ssResidual += (yc - pred) * (yc - pred)
+	}
+	fit.RSquared = rSquaredFrom(ssTotal, ssResidual)
+	return fit
+}
+
+// FitElasticNet fits md's response by least squares under an elastic-net penalty. Predictors are
+// standardized for the penalty, and the coefficients reported on their original scale; constant
+// predictors get a zero coefficient. Rows with a missing value are dropped.
+func FitElasticNet(md MultiDataset, pen Penalty) (MultiFit, error) {
+	if !(pen.Lambda >= 0) || math.IsInf(pen.Lambda, 0) || !(pen.Alpha >= 0 && pen.Alpha <= 1) {
+		return MultiFit{}, fmt.Errorf("penalty needs lambda >= 0 and alpha in [0, 1], got %+v", pen)
+	}
+	s, err := standardize(md)
+	if err != nil {
+		return MultiFit{}, err
+	}
+	b := make([]float64, len(md.Names))
+	s.descend(b, pen)
+	return s.fit(md, b, pen), nil
+}
+
+// TuneOptions control TuneElasticNet. Zero values select the defaults.
+type TuneOptions struct {
+	// Lambdas is the grid to search; by default 50 values spaced evenly on a log scale from the
+	// smallest λ that zeroes every coefficient down to 1/10000 of it
+	Lambdas []float64
+	Folds   int    // default 10, or one per row for fewer rows
+	Seed    uint64 // seeds the assignment of rows to folds
+	Workers int    // folds fitted concurrently; default runtime.NumCPU()
+}
+
+// CVPoint is the cross-validated mean squared error at one λ, with its standard error across folds
+type CVPoint struct {
+	Lambda   float64 `json:"lambda"`
+	MSE      float64 `json:"mse"`
+	StdError float64 `json:"stdError"`
+	Nonzero  int     `json:"nonzero"`
+}
+
+// CVResult is the outcome of TuneElasticNet. Curve runs from the largest λ to the smallest, with
+// Nonzero counted in the full-data fit. Lambda1SE is the largest λ whose error is within one
+// standard error of the best, the usual choice for a sparser model that predicts about as well.
+type CVResult struct {
+	Alpha      float64   `json:"alpha"`
+	Folds      int       `json:"folds"`
+	Curve      []CVPoint `json:"curve"`
+	BestLambda float64   `json:"bestLambda"`
+	Lambda1SE  float64   `json:"lambda1SE"`
+	// Fit is the full-data fit at BestLambda
+	Fit MultiFit `json:"fit"`
+}
+
+// TuneElasticNet picks λ for an elastic-net fit with mixing alpha by k-fold cross-validation.
+// Each fold fits the whole λ path from the largest value down, warm-starting every fit from the
+// previous one, and the folds run in parallel.
+func TuneElasticNet(md MultiDataset, alpha float64, opts TuneOptions) (CVResult, error) {
+	if !(alpha >= 0 && alpha <= 1) {
+		return CVResult{}, fmt.Errorf("alpha must be in [0, 1], got %v", alpha)
+	}
+	all, err := standardize(md)
+	if err != nil {
+		return CVResult{}, err
+	}
+	var rows []int
+	for i, row := range md.X {
+		if !isMissing(md.Y[i]) && !hasMissing(row) {
+			rows = append(rows, i)
+		}
+	}
+
+	lambdas := append([]float64(nil), opts.Lambdas...)
+	if len(lambdas) == 0 {
+		top := all.lambdaMax(alpha)
+		if top == 0 {
+			return CVResult{}, fmt.Errorf("no predictor is correlated with the response")
+		}
+		for k := 0; k < 50; k++ {
+			lambdas = append(lambdas, top*math.Pow(1e-4, float64(k)/49))
+		}
+	}
+	for _, l := range lambdas {
+		if !(l >= 0) || math.IsInf(l, 0) {
+			return CVResult{}, fmt.Errorf("lambda must be finite and non-negative, got %v", l)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.Float64Slice(lambdas)))
+
+	folds := opts.Folds
+	if folds <= 0 {
+		folds = min(10, len(rows))
+	}
+	if folds < 2 || folds > len(rows) {
+		return CVResult{}, fmt.Errorf("need between 2 and %d folds, got %d", len(rows), folds)
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, 0))
+	order := rng.Perm(len(rows))
+	foldOf := make([]int, len(md.X))
+	for i := range foldOf {
+		foldOf[i] = -1
+	}
+	for k, idx := range order {
+		foldOf[rows[idx]] = k % folds
+	}
+
+	// errs[f][l] is the mean squared error of fold f's held-out rows at lambdas[l]
+	errs := make([][]float64, folds)
+	fails := make([]error, folds)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, folds); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range jobs {
+				errs[f], fails[f] = foldPath(md, foldOf, f, alpha, lambdas)
+			}
+		}()
+	}
+	for f := 0; f < folds; f++ {
+		jobs <- f
+	}
+	close(jobs)
+	wg.Wait()
+	for f, err := range fails {
+		if err != nil {
+			return CVResult{}, fmt.Errorf("fold %d: %w", f+1, err)
+		}
+	}
+
+	res := CVResult{Alpha: alpha, Folds: folds}
+	best := 0
+	b := make([]float64, len(md.Names))
+	for l, lambda := range lambdas {
+		values := make([]float64, folds)
+		for f := range errs {
+			values[f] = errs[f][l]
+		}
+		mean, variance := meanVariance(values)
+		all.descend(b, Penalty{Lambda: lambda, Alpha: alpha})
+		nonzero := 0
+		for _, v := range b {
+			if v != 0 {
+				nonzero++
+			}
+		}
+		res.Curve = append(res.Curve, CVPoint{Lambda: lambda, MSE: mean, StdError: math.Sqrt(variance / float64(folds)), Nonzero: nonzero})
+		if mean < res.Curve[best].MSE {
+			best = l
+		}
+	}
+	res.BestLambda = lambdas[best]
+	for _, pt := range res.Curve {
+		if pt.MSE <= res.Curve[best].MSE+res.Curve[best].StdError {
+			res.Lambda1SE = pt.Lambda
+			break
+		}
+	}
+	res.Fit, err = FitElasticNet(md, Penalty{Lambda: res.BestLambda, Alpha: alpha})
+	return res, err
+}
+
+// foldPath fits the λ path without fold f's rows and returns the held-out error at each λ
+func foldPath(md MultiDataset, foldOf []int, f int, alpha float64, lambdas []float64) ([]float64, error) {
+	train := MultiDataset{Names: md.Names}
+	var test []int
+	for i := range md.X {
+		switch foldOf[i] {
+		case -1:
+		case f:
+			test = append(test, i)
+		default:
+			train.X, train.Y = append(train.X, md.X[i]), append(train.Y, md.Y[i])
+		}
+	}
+	s, err := standardize(train)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]float64, len(md.Names))
+	mse := make([]float64, len(lambdas))
+	for l, lambda := range lambdas {
+		pen := Penalty{Lambda: lambda, Alpha: alpha}
+		s.descend(b, pen)
+		fit := s.fit(train, b, pen)
+		for _, i := range test {
+			r := md.Y[i] - fit.Predict(md.X[i])
+			mse[l] += r * r / float64(len(test))
+		}
+	}
+	return mse, nil
+}
+
+// PrintCVResult summarizes a cross-validated λ search
+func PrintCVResult(w io.Writer, res CVResult) {
+	pen := Penalty{Alpha: res.Alpha}
+	fmt.Fprintf(w, "Penalty %s, %d-fold cross-validation over %d values of lambda\n", pen, res.Folds, len(res.Curve))
+	for _, pt := range res.Curve {
+		if pt.Lambda == res.BestLambda {
+			fmt.Fprintf(w, "Best lambda: %.6g (CV MSE %.6g ± %.3g, %d nonzero coefficients)\n", pt.Lambda, pt.MSE, pt.StdError, pt.Nonzero)
+		}
+	}
+	fmt.Fprintf(w, "Largest lambda within one standard error: %.6g\n", res.Lambda1SE)
+}
+
+// WriteCVCurve writes the cross-validation curve as CSV, one row per λ, for plotting
+func WriteCVCurve(w io.Writer, res CVResult) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"lambda", "mse", "std_error", "nonzero"})
+	for _, pt := range res.Curve {
+		cw.Write([]string{
+			strconv.FormatFloat(pt.Lambda, 'g', -1, 64),
+			strconv.FormatFloat(pt.MSE, 'g', -1, 64),
+			strconv.FormatFloat(pt.StdError, 'g', -1, 64),
+			strconv.Itoa(pt.Nonzero),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sparseDataset has two informative predictors out of six, the last a noisy copy of the first
+func sparseDataset(n int, seed uint64) MultiDataset {
+	rng := rand.New(rand.NewPCG(seed, 1))
+	md := MultiDataset{Names: []string{"a", "b", "c", "d", "e", "a2"}}
+	for i := 0; i < n; i++ {
+		row := make([]float64, 6)
+		for j := 0; j < 5; j++ {
+			row[j] = rng.NormFloat64()
+		}
+		row[5] = row[0] + 0.3*rng.NormFloat64()
+		md.X = append(md.X, row)
+		md.Y = append(md.Y, 1+3*row[0]-2*row[1]+rng.NormFloat64())
+	}
+	return md
+}
+
+// ✅ Test 1: Without a penalty the fit is least squares; the lasso satisfies its optimality conditions
+func TestFitElasticNet(t *testing.T) {
+	md := sparseDataset(60, 3)
+	md.Names, md.X = md.Names[:5], func() [][]float64 {
+		rows := make([][]float64, len(md.X))
+		for i, row := range md.X {
+			rows[i] = row[:5]
+		}
+		return rows
+	}()
+	ols, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fit, err := FitElasticNet(md, Penalty{Lambda: 0, Alpha: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range ols.Coefficients {
+		if math.Abs(fit.Coefficients[j]-ols.Coefficients[j]) > 1e-8 {
+			t.Errorf("coefficient %d: got %v, least squares %v", j, fit.Coefficients[j], ols.Coefficients[j])
+		}
+	}
+
+	// on the standardized scale, |x'r|/n equals λ for active predictors and is at most λ otherwise
+	pen := Penalty{Lambda: 0.3, Alpha: 1}
+	fit, err = FitElasticNet(md, pen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, _ := standardize(md)
+	for j, col := range s.x {
+		var dot float64
+		for i, v := range col {
+			dot += v * (md.Y[i] - fit.Predict(md.X[i]))
+		}
+		grad := math.Abs(dot) / float64(len(col))
+		if fit.Coefficients[j+1] != 0 {
+			if math.Abs(grad-pen.Lambda) > 1e-6 {
+				t.Errorf("active %s: gradient %v, want %v", md.Names[j], grad, pen.Lambda)
+			}
+		} else if grad > pen.Lambda+1e-6 {
+			t.Errorf("inactive %s: gradient %v above %v", md.Names[j], grad, pen.Lambda)
+		}
+	}
+	if fit.Coefficients[1] == 0 || fit.Coefficients[2] == 0 || fit.Penalty == nil || *fit.Penalty != pen {
+		t.Errorf("expected the informative predictors to stay, got %v", fit.Coefficients)
+	}
+
+	// ridge shrinks every coefficient as λ grows, without zeroing any
+	prev := math.Inf(1)
+	for _, lambda := range []float64{0.01, 0.1, 1, 10} {
+		fit, _ := FitElasticNet(md, Penalty{Lambda: lambda})
+		norm := norm2(fit.Coefficients[1:])
+		if norm >= prev || slicesContainsZero(fit.Coefficients[1:]) {
+			t.Errorf("ridge λ=%v: coefficients %v", lambda, fit.Coefficients)
+		}
+		prev = norm
+	}
+	if _, err := FitElasticNet(md, Penalty{Lambda: -1, Alpha: 0.5}); err == nil {
+		t.Error("expected an error for a negative lambda")
+	}
+}
+
+func slicesContainsZero(values []float64) bool {
+	for _, v := range values {
+		if v == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// ✅ Test 2: Cross-validation finds a useful λ, and the result does not depend on the worker count
+func TestTuneElasticNet(t *testing.T) {
+	md := sparseDataset(120, 9)
+	res, err := TuneElasticNet(md, 1, TuneOptions{Seed: 4, Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Curve) != 50 || res.Folds != 10 || res.Curve[0].Nonzero != 0 {
+		t.Fatalf("unexpected curve: %d points, %d folds, %d nonzero at the top", len(res.Curve), res.Folds, res.Curve[0].Nonzero)
+	}
+	for i := 1; i < len(res.Curve); i++ {
+		if res.Curve[i].Lambda >= res.Curve[i-1].Lambda {
+			t.Fatalf("lambdas not decreasing at %d", i)
+		}
+	}
+	if res.Lambda1SE < res.BestLambda || res.Fit.Penalty.Lambda != res.BestLambda {
+		t.Errorf("best %v, one-SE %v, fit %+v", res.BestLambda, res.Lambda1SE, res.Fit.Penalty)
+	}
+	// the best fit predicts about as well as the noise allows, and shrinks c, d and e below least squares
+	if best := res.Curve[0].MSE; best < 5 {
+		t.Errorf("null model CV MSE %v, expected well above the noise", best)
+	}
+	for _, pt := range res.Curve {
+		if pt.Lambda == res.BestLambda && (pt.MSE > 1.5 || pt.MSE < 0.6) {
+			t.Errorf("best CV MSE %v, noise variance is 1", pt.MSE)
+		}
+	}
+	ols, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := 3; j <= 5; j++ {
+		if math.Abs(res.Fit.Coefficients[j]) >= math.Abs(ols.Coefficients[j]) {
+			t.Errorf("noise predictor %s has coefficient %v, least squares %v", md.Names[j-1], res.Fit.Coefficients[j], ols.Coefficients[j])
+		}
+	}
+
+	parallel, err := TuneElasticNet(md, 1, TuneOptions{Seed: 4, Workers: 8})
+	if err != nil || !reflect.DeepEqual(parallel, res) {
+		t.Errorf("parallel search differs: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteCVCurve(&buf, res); err != nil || strings.Count(buf.String(), "\n") != 51 {
+		t.Errorf("curve CSV: %v\n%s", err, buf.String())
+	}
+	for _, opts := range []TuneOptions{{Folds: 1}, {Folds: 500}, {Lambdas: []float64{1, -1}}} {
+		if _, err := TuneElasticNet(md, 1, opts); err == nil {
+			t.Errorf("expected an error for %+v", opts)
+		}
+	}
+}
+
+// ✅ Test 3: Penalty mixes parse and print, and penalized models carry no interval statistics
+func TestPenaltyMix(t *testing.T) {
+	for _, spec := range []string{"ridge", "lasso", "elasticnet:0.25"} {
+		alpha, err := ParsePenaltyMix(spec)
+		if err != nil || (Penalty{Alpha: alpha}).String() != spec {
+			t.Errorf("%s: got %v, %v", spec, alpha, err)
+		}
+	}
+	for _, spec := range []string{"", "lasso2", "elasticnet", "elasticnet:1.5"} {
+		if _, err := ParsePenaltyMix(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+	md := sparseDataset(30, 2)
+	fit, err := FitElasticNet(md, Penalty{Lambda: 0.1, Alpha: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m := NewMultipleModel("enet", fit, md); m.Stats != nil {
+		t.Error("penalized model should not claim least squares intervals")
+	}
+}
@@ -0,0 +1,339 @@
+package main
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// HTMLReportDataset is one dataset's section of an HTML report
+type HTMLReportDataset struct {
+	Name   string
+	Data   Dataset
+	Result RegressionResult
+}
+
+// reportInfluencePoints is how many of the most influential points the HTML report lists
+const reportInfluencePoints = 10
+
+// HTMLReportOptions configures the distribution plots of an HTML report
+type HTMLReportOptions struct {
+	Bins      BinRule        // histogram bin rule; Freedman–Diaconis when empty
+	Bandwidth BandwidthRule  // KDE bandwidth rule; Silverman's when empty
+	Manifest  *Manifest      // build, options and seeds of the run, listed at the end when set
+	Locale    NumberLocale   // how fitted values are written; as Go writes them when zero
+	Messages  Messages       // language of the labels and advice; English when zero
+	Intervals IntervalMethod // how the scatter plots' bands are computed; t-based when empty
+}
+
+// WriteHTMLReport writes a self-contained HTML page with, for every dataset, its fit, a scatter plot
+// with the fitted line, and histograms with kernel density estimates of Y and of the residuals
+func WriteHTMLReport(w io.Writer, title string, datasets []HTMLReportDataset, opts HTMLReportOptions) error {
+	type plot struct {
+		Title string
+		SVG   template.HTML
+	}
+	type section struct {
+		HTMLReportDataset
+		Axes         string
+		Conditioning string // the design's condition number (see LineConditioning)
+		Unreliable   string // the warning when it is bad
+		Advice       []Advice
+		Influence    []PointDiagnostic
+		Plots        []plot
+	}
+
+	sections := make([]section, 0, len(datasets))
+	for _, d := range datasets {
+		s := section{HTMLReportDataset: d, Axes: d.Data.AxesLabel()}
+		if c, err := LineConditioning(d.Data); err == nil {
+			normal := engineSolvesNormalEquations(d.Result.Engine)
+			s.Conditioning = formatConditioning(c, normal, opts.Messages)
+			if c.Unreliable(normal) {
+				s.Unreliable = opts.Messages.Sprintf("the fit may be numerically unreliable; %s", c.advice(opts.Messages))
+			}
+		}
+		// too few points for advice is not worth a line in the report
+		s.Advice, _ = Advise(d.Data)
+		for i, a := range s.Advice {
+			s.Advice[i] = a.Localize(opts.Messages)
+		}
+		s.Influence = InfluenceRanking(d.Data, d.Result.Slope, d.Result.Intercept)
+		s.Influence = s.Influence[:min(reportInfluencePoints, len(s.Influence))]
+		caption := "Data and fit with %g%% confidence and prediction bands"
+		if opts.Intervals == IntervalBootstrap {
+			caption = "Data and fit with %g%% bootstrap confidence and prediction bands"
+		}
+		s.Plots = append(s.Plots, plot{opts.Messages.Sprintf(caption, 100*scatterBandLevel), svgScatter(d.Data, d.Result, opts.Intervals)})
+		yAxis := d.Data.YAxis.or(Axis{Label: "Y"}).String()
+		samples := []struct {
+			name, title string // name is for errors, title for the caption
+			values      []float64
+		}{
+			{"distribution of " + yAxis, opts.Messages.Sprintf("Distribution of %s", yAxis), d.Data.Y},
+			{"distribution of residuals", opts.Messages.Text("Distribution of residuals"), Residuals(d.Data.X, d.Data.Y, d.Result.Slope, d.Result.Intercept)},
+		}
+		for _, sample := range samples {
+			svg, err := svgDistribution(sample.values, opts)
+			if err != nil {
+				return fmt.Errorf("dataset %s: %s: %w", d.Name, sample.name, err)
+			}
+			s.Plots = append(s.Plots, plot{sample.title, svg})
+		}
+		sections = append(sections, s)
+	}
+	return htmlReportTemplate.Execute(w, struct {
+		Title    string
+		Sections []section
+		Manifest *Manifest
+		Locale   NumberLocale
+		Messages Messages
+	}{title, sections, opts.Manifest, opts.Locale, opts.Messages})
+}
+
+// WriteHTMLReportFile writes the report to path
+func WriteHTMLReportFile(path, title string, datasets []HTMLReportDataset, opts HTMLReportOptions) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteHTMLReport(f, title, datasets, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"num":  func(l NumberLocale, v float64) string { return l.FormatFloat(v, 6) },
+	"diag": formatDiagnostic,
+	"inc":  func(i int) int { return i + 1 },
+	"change": func(v float64) string {
+		if math.IsNaN(v) {
+			return "undefined: the fit hinges on this point"
+		}
+		return fmt.Sprintf("%+.6f", v)
+	},
+	"withUnit": NumberLocale.withUnit,
+	"text":     Messages.Text,
+	"sprintf":  Messages.Sprintf,
+	"lang":     Messages.htmlLang,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{lang .Messages}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+section { margin-bottom: 2.5rem; }
+table { border-collapse: collapse; margin-bottom: 1rem; }
+td { padding: 0.15rem 1rem 0.15rem 0; }
+td:first-child { color: #666; }
+.plots { display: flex; flex-wrap: wrap; gap: 1.5rem; }
+figure { margin: 0; }
+figcaption { font-size: 0.9rem; color: #444; margin-bottom: 0.25rem; }
+svg text { font-size: 11px; fill: #555; }
+.influence caption { text-align: left; color: #444; }
+.influence th { font-weight: normal; color: #666; text-align: left; padding-right: 1rem; }
+.influence td:first-child { color: inherit; }
+.advice li { margin-bottom: 0.2rem; }
+.advice li:not(.linear) { color: #a33; }
+.advice span { color: #666; }
+.warning { color: #a33; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<section>
+<h2>{{.Name}}</h2>
+<table>
+{{if .Axes}}<tr><td>{{text $.Messages "Axes"}}</td><td>{{.Axes}}</td></tr>
+{{end}}<tr><td>{{text $.Messages "Slope"}}</td><td>{{withUnit $.Locale .Result.Slope .Data.SlopeUnit}}</td></tr>
+<tr><td>{{text $.Messages "Intercept"}}</td><td>{{withUnit $.Locale .Result.Intercept .Data.YAxis.Unit}}</td></tr>
+<tr><td>{{text $.Messages "R-squared"}}</td><td>{{num $.Locale .Result.RSquared}}</td></tr>
+<tr><td>{{text $.Messages "Points"}}</td><td>{{len .Data.X}}</td></tr>
+{{if .Result.Engine}}<tr><td>{{text $.Messages "Engine"}}</td><td>{{.Result.Engine}}{{if .Result.Fallback}} ({{sprintf $.Messages "manual fallback: %s" .Result.Fallback}}){{end}}</td></tr>
+{{end}}{{if .Result.Fingerprint}}<tr><td>{{text $.Messages "Data"}}</td><td>{{.Result.Fingerprint}}</td></tr>
+{{end}}{{if .Conditioning}}<tr><td>{{text $.Messages "Conditioning"}}</td><td>{{.Conditioning}}</td></tr>
+{{end}}{{if .Unreliable}}<tr><td>{{text $.Messages "Warning"}}</td><td class="warning">{{.Unreliable}}</td></tr>
+{{end}}</table>
+{{if .Advice}}<ul class="advice">
+{{range .Advice}}<li class="{{.Kind}}">{{.Message}} <span>({{.Evidence}})</span></li>
+{{end}}</ul>
+{{end}}{{if .Influence}}<table class="influence">
+<caption>{{text $.Messages "Most influential points"}}</caption>
+<tr><th>{{text $.Messages "Point"}}</th><th>x</th><th>y</th><th>{{text $.Messages "Residual"}}</th><th>{{text $.Messages "Leverage"}}</th><th>{{text $.Messages "Cook's distance"}}</th><th>{{text $.Messages "Slope change if removed"}}</th></tr>
+{{range .Influence}}<tr><td>{{if .Label}}{{.Label}}{{else}}{{inc .Index}}{{end}}</td><td>{{.X}}</td><td>{{.Y}}</td><td>{{num $.Locale .Residual}}</td><td>{{num $.Locale .Leverage}}</td><td>{{diag .CooksDistance}}</td><td>{{change .SlopeChange}}</td></tr>
+{{end}}</table>
+{{end}}<div class="plots">
+{{range .Plots}}<figure><figcaption>{{.Title}}</figcaption>{{.SVG}}</figure>
+{{end}}</div>
+</section>
+{{end}}{{with .Manifest}}<section>
+<h2>{{text $.Messages "Reproducibility"}}</h2>
+<table>
+<tr><td>{{text $.Messages "Build"}}</td><td>{{.Module}} {{.Version}}{{if .Revision}} ({{.Revision}}{{if .Modified}}, modified{{end}}){{end}}</td></tr>
+<tr><td>Go</td><td>{{.GoVersion}}</td></tr>
+<tr><td>{{text $.Messages "Engine"}}</td><td>{{.Engine}}, montanaflynn/stats {{.StatsVersion}}</td></tr>
+{{range $name, $value := .Options}}<tr><td>-{{$name}}</td><td>{{$value}}</td></tr>
+{{end}}{{range $step, $seed := .Seeds}}<tr><td>{{sprintf $.Messages "Seed (%s)" $step}}</td><td>{{$seed}}</td></tr>
+{{end}}<tr><td>{{text $.Messages "Created"}}</td><td>{{.Created.Format "2006-01-02 15:04:05 MST"}}</td></tr>
+</table>
+</section>
+{{end}}</body>
+</html>
+`))
+
+// plotFrame maps data coordinates onto an SVG canvas with room for axis labels
+type plotFrame struct {
+	xMin, xMax, yMin, yMax float64
+}
+
+const (
+	plotWidth, plotHeight = 420.0, 260.0
+	plotLeft, plotBottom  = 56.0, 28.0
+	plotTop, plotRight    = 10.0, 10.0
+)
+
+func newPlotFrame(xMin, xMax, yMin, yMax float64) plotFrame {
+	if xMax <= xMin {
+		xMin, xMax = xMin-0.5, xMax+0.5
+	}
+	if yMax <= yMin {
+		yMin, yMax = yMin-0.5, yMax+0.5
+	}
+	return plotFrame{xMin, xMax, yMin, yMax}
+}
+
+func (f plotFrame) px(x float64) float64 {
+	return plotLeft + (x-f.xMin)/(f.xMax-f.xMin)*(plotWidth-plotLeft-plotRight)
+}
+
+func (f plotFrame) py(y float64) float64 {
+	return plotHeight - plotBottom - (y-f.yMin)/(f.yMax-f.yMin)*(plotHeight-plotBottom-plotTop)
+}
+
+// open starts the SVG element and draws the axes with their end values and optional titles
+func (f plotFrame) open(b *strings.Builder, xTitle, yTitle string) {
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%g" height="%g" viewBox="0 0 %g %g">`, plotWidth, plotHeight, plotWidth, plotHeight)
+	x0, x1, y0, y1 := f.px(f.xMin), f.px(f.xMax), f.py(f.yMin), f.py(f.yMax)
+	fmt.Fprintf(b, `<path d="M%.1f %.1fV%.1fH%.1f" fill="none" stroke="#999"/>`, x0, y1, y0, x1)
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f">%.4g</text><text x="%.1f" y="%.1f" text-anchor="end">%.4g</text>`, x0, y0+14, f.xMin, x1, y0+14, f.xMax)
+	fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="end">%.4g</text><text x="%.1f" y="%.1f" text-anchor="end">%.4g</text>`, x0-4, y0, f.yMin, x0-4, y1+8, f.yMax)
+	if xTitle != "" {
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`, (x0+x1)/2, y0+14, html.EscapeString(xTitle))
+	}
+	if yTitle != "" {
+		fmt.Fprintf(b, `<text transform="translate(12 %.1f) rotate(-90)" text-anchor="middle">%s</text>`, (y0+y1)/2, html.EscapeString(yTitle))
+	}
+}
+
+// scatterBandLevel is the coverage of the bands shaded around the fitted line in scatter plots
+const scatterBandLevel = 0.95
+
+// svgScatter plots the complete pairs of data with the fitted line across their X range, shading
+// its confidence band and the wider prediction band, computed by intervals, when there are enough
+// points for them
+func svgScatter(data Dataset, result RegressionResult, intervals IntervalMethod) template.HTML {
+	x, y, err := cleanPairs(nil, nil, data.X, data.Y)
+	if err != nil || len(x) == 0 {
+		return template.HTML(`<p>No complete points to plot.</p>`)
+	}
+	xMin, xMax, yMin, yMax := x[0], x[0], y[0], y[0]
+	for i := range x {
+		xMin, xMax = math.Min(xMin, x[i]), math.Max(xMax, x[i])
+		yMin, yMax = math.Min(yMin, y[i]), math.Max(yMax, y[i])
+	}
+	fitLo, fitHi := result.Intercept+result.Slope*xMin, result.Intercept+result.Slope*xMax
+	if !isMissing(fitLo) && !isMissing(fitHi) {
+		yMin, yMax = math.Min(yMin, math.Min(fitLo, fitHi)), math.Max(yMax, math.Max(fitLo, fitHi))
+	}
+	band, err := FitBand(data, result, scatterBandLevel, 40)
+	if intervals == IntervalBootstrap {
+		band, err = FitBootstrapBand(data, scatterBandLevel, 40, DefaultBootstrapReplicates, intervalSeed)
+	}
+	if err == nil {
+		for i := range band.X {
+			yMin, yMax = math.Min(yMin, band.Lower[i]), math.Max(yMax, band.Upper[i])
+		}
+	}
+	f := newPlotFrame(xMin, xMax, yMin, yMax)
+
+	var b strings.Builder
+	f.open(&b, data.XAxis.String(), data.YAxis.String())
+	if err == nil {
+		svgBand(&b, f, band.X, band.Lower, band.Upper, "0.08")
+		svgBand(&b, f, band.X, band.MeanLower, band.MeanUpper, "0.18")
+	}
+	for i := range x {
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3" fill="#3b6ea5" fill-opacity="0.7"/>`, f.px(x[i]), f.py(y[i]))
+	}
+	if !isMissing(fitLo) && !isMissing(fitHi) {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#c0392b" stroke-width="2"/>`, f.px(xMin), f.py(fitLo), f.px(xMax), f.py(fitHi))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+// svgBand shades the region between the lower and upper curves over xs
+func svgBand(b *strings.Builder, f plotFrame, xs, lower, upper []float64, opacity string) {
+	b.WriteString(`<polygon points="`)
+	for i := range xs {
+		fmt.Fprintf(b, "%.1f,%.1f ", f.px(xs[i]), f.py(upper[i]))
+	}
+	for i := len(xs) - 1; i >= 0; i-- {
+		fmt.Fprintf(b, "%.1f,%.1f ", f.px(xs[i]), f.py(lower[i]))
+	}
+	fmt.Fprintf(b, `" fill="#c0392b" fill-opacity="%s" stroke="none"/>`, opacity)
+}
+
+// svgDistribution plots a density-scaled histogram of the finite values with a KDE curve over it
+func svgDistribution(values []float64, opts HTMLReportOptions) (template.HTML, error) {
+	hist, err := NewHistogram(values, opts.Bins)
+	if err != nil {
+		return "", err
+	}
+	densities := hist.Densities()
+	// a single value has no spread to smooth, so it is drawn as a bar alone
+	var curveX, curveY []float64
+	if hist.N() > 1 {
+		bandwidth, err := SelectBandwidth(values, opts.Bandwidth)
+		if err != nil {
+			return "", err
+		}
+		kde, err := NewKDE(values, bandwidth)
+		if err != nil {
+			return "", err
+		}
+		curveX, curveY = kde.Curve(128)
+	}
+
+	xMin, xMax, yMax := hist.Edges[0], hist.Edges[len(hist.Edges)-1], 0.0
+	for _, d := range densities {
+		yMax = math.Max(yMax, d)
+	}
+	for i := range curveX {
+		xMin, xMax, yMax = math.Min(xMin, curveX[i]), math.Max(xMax, curveX[i]), math.Max(yMax, curveY[i])
+	}
+	f := newPlotFrame(xMin, xMax, 0, yMax)
+
+	var b strings.Builder
+	f.open(&b, "", "density")
+	for k, d := range densities {
+		x0, x1 := f.px(hist.Edges[k]), f.px(hist.Edges[k+1])
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="#9fbfdf" stroke="#fff"/>`, x0, f.py(d), x1-x0, f.py(0)-f.py(d))
+	}
+	if len(curveX) > 0 {
+		b.WriteString(`<polyline fill="none" stroke="#c0392b" stroke-width="2" points="`)
+		for i := range curveX {
+			fmt.Fprintf(&b, "%.1f,%.1f ", f.px(curveX[i]), f.py(curveY[i]))
+		}
+		b.WriteString(`"/>`)
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String()), nil
+}
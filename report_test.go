@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: The HTML report has a section with three plots per dataset and escapes names
+func TestWriteHTMLReport(t *testing.T) {
+	data := Dataset{
+		X:     []float64{1, 2, 3, 4, 5, 6},
+		Y:     []float64{2.1, 3.9, 6.2, 8.1, 9.8, 12.3},
+		XAxis: Axis{Label: "dose", Unit: "mg"},
+		YAxis: Axis{Label: "<response>"},
+	}
+	slope, intercept, r2 := ManualRegression(data.X, data.Y)
+	datasets := []HTMLReportDataset{{Name: "trial & error", Data: data, Result: RegressionResult{Slope: slope, Intercept: intercept, RSquared: r2}}}
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, "Report", datasets, HTMLReportOptions{Bins: BinRuleSturges}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if got := strings.Count(out, "<svg"); got != 3 {
+		t.Errorf("expected 3 plots, got %d", got)
+	}
+	if !strings.Contains(out, "trial &amp; error") || strings.Contains(out, "<response>") {
+		t.Error("expected names and axis labels to be escaped")
+	}
+	if !strings.Contains(out, "1/mg") || !strings.Contains(out, "<polyline") {
+		t.Error("expected the slope unit and a density curve")
+	}
+	if !strings.Contains(out, `<li class="linear">the straight line looks adequate`) {
+		t.Error("expected the dataset's advice")
+	}
+	if !strings.Contains(out, "digits lost to the normal equations") || strings.Contains(out, `class="warning"`) {
+		t.Error("expected the design's conditioning without a warning")
+	}
+
+	if err := WriteHTMLReport(&buf, "Report", datasets, HTMLReportOptions{Bandwidth: "bogus"}); err == nil {
+		t.Error("expected an error for an unknown bandwidth rule")
+	}
+}
+
+// ✅ Test 2: The run's manifest is listed at the end of the report
+func TestWriteHTMLReportManifest(t *testing.T) {
+	data := LoadAnscombeDatasets()["IV"]
+	result, err := AnalyzeDataset("IV", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewManifest(analysisEngine)
+	m.SetSeed("clusters", clusterSeed)
+
+	var buf bytes.Buffer
+	if err := WriteHTMLReport(&buf, "Report", []HTMLReportDataset{{Name: "IV", Data: data, Result: result}}, HTMLReportOptions{Manifest: &m}); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"Reproducibility", "manual fallback: identical X values", "Seed (clusters)", result.Fingerprint} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the report", want)
+		}
+	}
+}
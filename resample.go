@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+)
+
+// ResampleMethod selects the bootstrap scheme
+type ResampleMethod string
+
+const (
+	// ResamplePairs draws (x, y) pairs with replacement; it makes no assumption about the model
+	ResamplePairs ResampleMethod = "pairs"
+	// ResampleResiduals keeps X fixed and adds residuals drawn with replacement to the fitted line;
+	// it assumes the line is correct and the errors are exchangeable
+	ResampleResiduals ResampleMethod = "residuals"
+	// ResampleWeighted keeps every point and multiplies its weight by a draw from the standard
+	// exponential distribution (the Bayesian bootstrap). No point is ever left out, so weighted fits
+	// of replicates stay defined where pair replicates could drop all of a few heavy points.
+	ResampleWeighted ResampleMethod = "weighted"
+	// ResampleBlocks joins blocks of consecutive points, starting at random positions, until the
+	// replicate is as long as the data (the moving-block bootstrap). Points must be in time order;
+	// dependence within a block is preserved, so it suits autocorrelated series that the other
+	// methods, which draw points independently, would make look more certain than they are.
+	ResampleBlocks ResampleMethod = "blocks"
+)
+
+// BootstrapOptions configure NewBootstrapperWithOptions. BlockLength is the number of consecutive
+// points per block of ResampleBlocks, ⌈n^(1/3)⌉ when zero.
+type BootstrapOptions struct {
+	Method      ResampleMethod
+	Seed        uint64
+	BlockLength int
+}
+
+// Bootstrapper draws bootstrap replicates of a dataset one at a time
+type Bootstrapper struct {
+	method    ResampleMethod
+	x, y      []float64 // finite pairs of the original data
+	weights   []float64 // their weights, nil for unweighted data
+	fitted    []float64 // fitted values, residual bootstrap only
+	residuals []float64 // residuals, scaled by the square roots of the weights of weighted data
+	block     int
+	rng       *rand.Rand
+}
+
+// NewBootstrapper prepares replicates of ds. Incomplete pairs are dropped first; the residual
+// method fits the line once with FastRegression. Replicates depend only on seed.
+func NewBootstrapper(ds Dataset, method ResampleMethod, seed uint64) (*Bootstrapper, error) {
+	return NewBootstrapperWithOptions(ds, BootstrapOptions{Method: method, Seed: seed})
+}
+
+// NewBootstrapperWithOptions is NewBootstrapper with a block length for ResampleBlocks. Weights
+// of ds carry over to the replicates: pairs and blocks take each point's weight along, the residual
+// method fits by WeightedRegression and keeps the weights of the fixed X values, and the weighted
+// method scales them.
+func NewBootstrapperWithOptions(ds Dataset, opts BootstrapOptions) (*Bootstrapper, error) {
+	x, y, weights, err := cleanWeightedPairs(ds)
+	if err != nil {
+		return nil, err
+	}
+	b := &Bootstrapper{method: opts.Method, x: x, y: y, weights: weights, rng: rand.New(rand.NewPCG(opts.Seed, 0))}
+
+	switch opts.Method {
+	case ResamplePairs, ResampleWeighted:
+	case ResampleResiduals:
+		var slope, intercept float64
+		if weights != nil {
+			slope, intercept, _, err = WeightedRegression(x, y, weights)
+		} else {
+			slope, intercept, _, err = FastRegression(x, y)
+		}
+		if err != nil {
+			return nil, err
+		}
+		b.fitted = make([]float64, len(x))
+		b.residuals = make([]float64, len(x))
+		for i := range x {
+			b.fitted[i] = intercept + slope*x[i]
+			b.residuals[i] = y[i] - b.fitted[i]
+			if weights != nil {
+				// standardized to one variance, so they can be exchanged between points
+				b.residuals[i] *= math.Sqrt(weights[i])
+			}
+		}
+	case ResampleBlocks:
+		b.block = opts.BlockLength
+		if b.block == 0 {
+			b.block = int(math.Ceil(math.Cbrt(float64(len(x)))))
+		}
+		if b.block < 1 || b.block > len(x) {
+			return nil, fmt.Errorf("block length must be between 1 and the %d points, got %d", len(x), b.block)
+		}
+	default:
+		return nil, fmt.Errorf("unknown resample method %q (use pairs, residuals, weighted or blocks)", opts.Method)
+	}
+	return b, nil
+}
+
+// cleanWeightedPairs drops the incomplete pairs of ds, and with weights the pairs of zero weight,
+// which no weighted fit uses. Weights must be finite and non-negative.
+func cleanWeightedPairs(ds Dataset) (x, y, weights []float64, err error) {
+	if ds.Weights == nil {
+		x, y, err = cleanPairs(nil, nil, ds.X, ds.Y)
+		return x, y, nil, err
+	}
+	if len(ds.Weights) != len(ds.X) {
+		return nil, nil, nil, fmt.Errorf("x and weight length mismatch: %d vs %d", len(ds.X), len(ds.Weights))
+	}
+	var kept Dataset
+	for i, w := range ds.Weights {
+		if w < 0 || math.IsNaN(w) || math.IsInf(w, 0) {
+			return nil, nil, nil, fmt.Errorf("weight %d is %v; weights must be finite and non-negative", i, w)
+		}
+		if w > 0 && i < len(ds.Y) {
+			kept.X, kept.Y, kept.Weights = append(kept.X, ds.X[i]), append(kept.Y, ds.Y[i]), append(kept.Weights, w)
+		}
+	}
+	if x, y, err = cleanPairs(nil, nil, kept.X, kept.Y); err != nil {
+		return nil, nil, nil, err
+	}
+	weights = make([]float64, 0, len(x))
+	for i := range kept.X {
+		if !isMissing(kept.X[i]) && !isMissing(kept.Y[i]) {
+			weights = append(weights, kept.Weights[i])
+		}
+	}
+	return x, y, weights, nil
+}
+
+// Next draws one replicate into dst, reusing its slices when they are large enough. Replicates of
+// weighted data, and all those of ResampleWeighted, have Weights.
+func (b *Bootstrapper) Next(dst Dataset) Dataset {
+	n := len(b.x)
+	dst.X, dst.Y = dst.X[:0], dst.Y[:0]
+	dst.Weights = dst.Weights[:0]
+	switch b.method {
+	case ResampleWeighted:
+		for i := 0; i < n; i++ {
+			w := b.rng.ExpFloat64()
+			if b.weights != nil {
+				w *= b.weights[i]
+			}
+			dst.X, dst.Y, dst.Weights = append(dst.X, b.x[i]), append(dst.Y, b.y[i]), append(dst.Weights, w)
+		}
+		return dst
+	case ResampleBlocks:
+		for len(dst.X) < n {
+			start := b.rng.IntN(n - b.block + 1)
+			for j := start; j < start+b.block && len(dst.X) < n; j++ {
+				b.appendPoint(&dst, j)
+			}
+		}
+		return b.trimWeights(dst)
+	}
+	for i := 0; i < n; i++ {
+		j := b.rng.IntN(n)
+		if b.method == ResampleResiduals {
+			r := b.residuals[j]
+			if b.weights != nil {
+				r /= math.Sqrt(b.weights[i])
+				dst.Weights = append(dst.Weights, b.weights[i])
+			}
+			dst.X = append(dst.X, b.x[i])
+			dst.Y = append(dst.Y, b.fitted[i]+r)
+		} else {
+			b.appendPoint(&dst, j)
+		}
+	}
+	return b.trimWeights(dst)
+}
+
+// appendPoint appends point j of the data, with its weight, to dst
+func (b *Bootstrapper) appendPoint(dst *Dataset, j int) {
+	dst.X = append(dst.X, b.x[j])
+	dst.Y = append(dst.Y, b.y[j])
+	if b.weights != nil {
+		dst.Weights = append(dst.Weights, b.weights[j])
+	}
+}
+
+// trimWeights leaves the replicate of unweighted data unweighted
+func (b *Bootstrapper) trimWeights(dst Dataset) Dataset {
+	if b.weights == nil {
+		dst.Weights = nil
+	}
+	return dst
+}
+
+// Resample returns n pair-bootstrap replicates of ds
+func Resample(ds Dataset, n int, seed uint64) ([]Dataset, error) {
+	return ResampleWithMethod(ds, n, seed, ResamplePairs)
+}
+
+// ResampleWithMethod returns n bootstrap replicates of ds drawn with method
+func ResampleWithMethod(ds Dataset, n int, seed uint64, method ResampleMethod) ([]Dataset, error) {
+	return ResampleWithOptions(ds, n, BootstrapOptions{Method: method, Seed: seed})
+}
+
+// ResampleWithOptions returns n bootstrap replicates of ds drawn as opts says
+func ResampleWithOptions(ds Dataset, n int, opts BootstrapOptions) ([]Dataset, error) {
+	b, err := NewBootstrapperWithOptions(ds, opts)
+	if err != nil {
+		return nil, err
+	}
+	replicates := make([]Dataset, n)
+	for i := range replicates {
+		replicates[i] = b.Next(Dataset{})
+	}
+	return replicates, nil
+}
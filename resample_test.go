@@ -0,0 +1,182 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Pair replicates draw original pairs and are reproducible per seed
+func TestResamplePairs(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	reps, err := Resample(data, 50, 9)
+	if err != nil || len(reps) != 50 {
+		t.Fatalf("got %d replicates, err %v", len(reps), err)
+	}
+
+	pairs := map[[2]float64]bool{}
+	for i := range data.X {
+		pairs[[2]float64{data.X[i], data.Y[i]}] = true
+	}
+	for _, r := range reps {
+		if len(r.X) != len(data.X) {
+			t.Fatalf("replicate has %d points, want %d", len(r.X), len(data.X))
+		}
+		for i := range r.X {
+			if !pairs[[2]float64{r.X[i], r.Y[i]}] {
+				t.Fatalf("replicate contains a pair not in the data: (%v, %v)", r.X[i], r.Y[i])
+			}
+		}
+	}
+
+	again, _ := Resample(data, 50, 9)
+	if !slices.Equal(reps[49].Y, again[49].Y) {
+		t.Error("replicates should be reproducible for the same seed")
+	}
+}
+
+// ✅ Test 2: Residual replicates keep X fixed and the bootstrap slope spread matches the standard error
+func TestResampleResiduals(t *testing.T) {
+	x, y := syntheticLine(200)
+	b, err := NewBootstrapper(Dataset{X: x, Y: y}, ResampleResiduals, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var slopes OnlineRegression
+	var rep Dataset
+	for i := 0; i < 2000; i++ {
+		rep = b.Next(rep)
+		if i == 0 && !slices.Equal(rep.X, x) {
+			t.Fatal("residual bootstrap must keep X fixed")
+		}
+		slope, _, _, _ := FastRegression(rep.X, rep.Y)
+		slopes.Add(slope, 0)
+	}
+	bootSE := math.Sqrt(slopes.cxx / float64(slopes.n-1))
+
+	// analytic standard error of the slope: sqrt(SSE/(n-2) / Sxx)
+	var acc OnlineRegression
+	for i := range x {
+		acc.Add(x[i], y[i])
+	}
+	s := acc.Snapshot()
+	sse := acc.cyy * (1 - s.RSquared)
+	analyticSE := math.Sqrt(sse / float64(len(x)-2) / acc.cxx)
+	if math.Abs(bootSE-analyticSE)/analyticSE > 0.1 {
+		t.Errorf("bootstrap SE %v differs from analytic SE %v by more than 10%%", bootSE, analyticSE)
+	}
+
+	if _, err := NewBootstrapper(Dataset{X: x, Y: y}, "wild", 1); err == nil {
+		t.Error("expected unknown method error")
+	}
+}
+
+// ✅ Test 3: Weighted replicates keep every point and scale its weight; pairs carry weights along
+func TestResampleWeighted(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	data.Weights = make([]float64, len(data.X))
+	for i := range data.Weights {
+		data.Weights[i] = float64(i + 1)
+	}
+	data.Weights[3] = 0 // left out, as WeightedRegression would
+
+	reps, err := ResampleWithMethod(data, 200, 4, ResampleWeighted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mean float64
+	for _, r := range reps {
+		if len(r.X) != 10 || len(r.Weights) != 10 || slices.Contains(r.X, data.X[3]) {
+			t.Fatalf("replicate should keep the 10 weighted points, got %+v", r)
+		}
+		mean += r.Weights[0] / float64(len(reps))
+		if _, _, _, err := WeightedRegression(r.X, r.Y, r.Weights); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the exponential draws average 1, so weights average their original value
+	if math.Abs(mean-1) > 0.2 {
+		t.Errorf("expected the first point's weight to average about 1, got %g", mean)
+	}
+
+	pairs, err := Resample(data, 20, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range pairs {
+		for i := range r.X {
+			j := slices.Index(data.X, r.X[i])
+			if r.Weights[i] != data.Weights[j] {
+				t.Fatalf("pair %v carries weight %v, want %v", r.X[i], r.Weights[i], data.Weights[j])
+			}
+		}
+	}
+	if unweighted, _ := Resample(LoadAnscombeDatasets()["I"], 1, 4); unweighted[0].Weights != nil {
+		t.Error("replicates of unweighted data should be unweighted")
+	}
+
+	b, err := NewBootstrapper(data, ResampleResiduals, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rep := b.Next(Dataset{}); len(rep.X) != 10 || rep.X[3] != data.X[4] || rep.Weights[3] != data.Weights[4] {
+		t.Errorf("residual replicates of weighted data should keep X and weights fixed, got %+v", rep)
+	}
+
+	data.Weights[0] = -1
+	if _, err := NewBootstrapper(data, ResampleWeighted, 1); err == nil {
+		t.Error("expected a negative weight error")
+	}
+}
+
+// ✅ Test 4: Block replicates are runs of consecutive points and widen the slope spread of autocorrelated errors
+func TestResampleBlocks(t *testing.T) {
+	const n = 400
+	x, y := make([]float64, n), make([]float64, n)
+	rng := rand.New(rand.NewPCG(3, 4))
+	var e float64
+	for i := range x {
+		e = 0.9*e + rng.NormFloat64() // AR(1) errors
+		x[i], y[i] = float64(i%40), 2+0.5*float64(i%40)+e
+	}
+	data := Dataset{X: x, Y: y}
+
+	b, err := NewBootstrapperWithOptions(data, BootstrapOptions{Method: ResampleBlocks, Seed: 5, BlockLength: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep := b.Next(Dataset{})
+	if len(rep.X) != n {
+		t.Fatalf("replicate has %d points, want %d", len(rep.X), n)
+	}
+	for k := 0; k < n; k += 20 {
+		start := slices.Index(y, rep.Y[k])
+		if !slices.Equal(rep.Y[k:k+20], y[start:start+20]) {
+			t.Fatalf("block at %d is not a run of consecutive points", k)
+		}
+	}
+
+	spread := func(method ResampleMethod) float64 {
+		reps, err := ResampleWithOptions(data, 300, BootstrapOptions{Method: method, Seed: 6, BlockLength: 20})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var slopes OnlineRegression
+		for _, r := range reps {
+			slope, _, _, _ := FastRegression(r.X, r.Y)
+			slopes.Add(slope, 0)
+		}
+		return math.Sqrt(slopes.cxx / float64(slopes.n-1))
+	}
+	if blocks, pairs := spread(ResampleBlocks), spread(ResamplePairs); blocks < 1.5*pairs {
+		t.Errorf("expected blocks to widen the slope spread of autocorrelated data, got %g vs pairs %g", blocks, pairs)
+	}
+
+	for _, length := range []int{-1, n + 1} {
+		if _, err := NewBootstrapperWithOptions(data, BootstrapOptions{Method: ResampleBlocks, BlockLength: length}); err == nil {
+			t.Errorf("expected an error for block length %d", length)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// RecursiveLeastSquares tracks a slowly changing line by discounting old points: after n points,
+// the fit minimizes Σ λ^(n-i)·(yᵢ - ŷᵢ)² for the forgetting factor λ, so a point's influence halves
+// every ln 2 / -ln λ points (about 14 for λ = 0.95) and the fit follows drift without the abrupt
+// evictions of a RollingRegression window. λ = 1 never forgets and matches OnlineRegression.
+//
+// Rather than propagating the gain and inverse covariance matrix of textbook RLS, it keeps
+// exponentially weighted means and co-moments (the weighted Welford update of WeightedRegression
+// with all old weights scaled by λ). That gives the same estimates without an initial covariance
+// guess and without the covariance wind-up RLS suffers when X stops varying.
+type RecursiveLeastSquares struct {
+	Lambda float64
+
+	n            int
+	weight       float64
+	meanX, meanY float64
+	cxx, cxy     float64
+	cyy          float64
+}
+
+// NewRecursiveLeastSquares creates an estimator with forgetting factor lambda in (0, 1]
+func NewRecursiveLeastSquares(lambda float64) (*RecursiveLeastSquares, error) {
+	if !(lambda > 0 && lambda <= 1) {
+		return nil, fmt.Errorf("forgetting factor must be in (0, 1], got %v", lambda)
+	}
+	return &RecursiveLeastSquares{Lambda: lambda}, nil
+}
+
+// Add includes a point in the fit, discounting the earlier ones, and returns the new fit.
+// NaN and Inf values are ignored.
+func (r *RecursiveLeastSquares) Add(x, y float64) OnlineSnapshot {
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+		return r.Snapshot()
+	}
+	r.n++
+	r.weight = r.Lambda*r.weight + 1
+	r.cxx *= r.Lambda
+	r.cxy *= r.Lambda
+	r.cyy *= r.Lambda
+
+	dx := x - r.meanX
+	dy := y - r.meanY
+	r.meanX += dx / r.weight
+	r.meanY += dy / r.weight
+	r.cxx += dx * (x - r.meanX)
+	r.cxy += dx * (y - r.meanY)
+	r.cyy += dy * (y - r.meanY)
+	return r.Snapshot()
+}
+
+// Weight returns the total weight of the points seen, the effective number of points the fit
+// rests on; it approaches 1/(1-λ)
+func (r *RecursiveLeastSquares) Weight() float64 {
+	return r.weight
+}
+
+// Reset forgets every point
+func (r *RecursiveLeastSquares) Reset() {
+	*r = RecursiveLeastSquares{Lambda: r.Lambda}
+}
+
+// Snapshot returns the current fit, with the exponentially weighted means and R², following the
+// degenerate-case rules of OnlineRegression. N counts every point added, however discounted.
+func (r *RecursiveLeastSquares) Snapshot() OnlineSnapshot {
+	s := OnlineSnapshot{N: r.n, MeanX: r.meanX, MeanY: r.meanY}
+	if r.n < 2 {
+		return s
+	}
+	if r.cxx > 0 {
+		s.Slope = r.cxy / r.cxx
+	}
+	s.Intercept = r.meanY - s.Slope*r.meanX
+	switch {
+	case r.cyy > 0 && r.cxx > 0:
+		c := r.cxy / math.Sqrt(r.cxx) / math.Sqrt(r.cyy)
+		s.RSquared = math.Min(c*c, 1)
+	case r.cyy > 0:
+		s.RSquared = 0
+	default:
+		s.RSquared = 1
+	}
+	return s
+}
+
+// RLSSlopes runs a RecursiveLeastSquares estimator over a whole series and returns the fit after
+// every point, the forgetting counterpart of RollingSlopes
+func RLSSlopes(x, y []float64, lambda float64) ([]OnlineSnapshot, error) {
+	r, err := NewRecursiveLeastSquares(lambda)
+	if err != nil {
+		return nil, err
+	}
+	n := min(len(x), len(y))
+	fits := make([]OnlineSnapshot, 0, n)
+	for i := 0; i < n; i++ {
+		fits = append(fits, r.Add(x[i], y[i]))
+	}
+	return fits, nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: The fit equals weighted least squares with weights λ^(n-i), and λ = 1 matches OnlineRegression
+func TestRecursiveLeastSquares(t *testing.T) {
+	x, y := syntheticLine(300)
+	for i := 150; i < len(y); i++ {
+		y[i] += 2 * (x[i] - 150) // the slope steps from 0.5 to 2.5
+	}
+
+	for _, lambda := range []float64{0.9, 0.99} {
+		r, err := NewRecursiveLeastSquares(lambda)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range x {
+			fit := r.Add(x[i], y[i])
+			if i%37 != 36 {
+				continue
+			}
+			w := make([]float64, i+1)
+			for j := range w {
+				w[j] = math.Pow(lambda, float64(i-j))
+			}
+			slope, intercept, r2, _ := WeightedRegression(x[:i+1], y[:i+1], w)
+			if deviation(fit.Slope, slope) > 1e-9 || deviation(fit.Intercept, intercept) > 1e-9 || math.Abs(fit.RSquared-r2) > 1e-9 {
+				t.Errorf("λ %v, point %d: got %+v, want %v %v %v", lambda, i, fit, slope, intercept, r2)
+			}
+		}
+		if want := (1 - math.Pow(lambda, 300)) / (1 - lambda); math.Abs(r.Weight()-want) > 1e-9*want {
+			t.Errorf("λ %v: weight %v, want %v", lambda, r.Weight(), want)
+		}
+	}
+
+	var online OnlineRegression
+	r, _ := NewRecursiveLeastSquares(1)
+	for i := range x {
+		online.Add(x[i], y[i])
+		r.Add(x[i], y[i])
+	}
+	if got, want := r.Snapshot(), online.Snapshot(); deviation(got.Slope, want.Slope) > 1e-12 || deviation(got.Intercept, want.Intercept) > 1e-12 {
+		t.Errorf("λ 1: %+v vs online %+v", got, want)
+	}
+}
+
+// ✅ Test 2: A forgetting fit tracks a slope change that a growing fit averages away
+func TestRLSSlopesTracking(t *testing.T) {
+	var x, y []float64
+	for i := 0; i < 400; i++ {
+		v := float64(i % 20)
+		slope := 1.0
+		if i >= 200 {
+			slope = 3
+		}
+		x = append(x, v)
+		y = append(y, 5+slope*v+0.01*math.Sin(float64(i)))
+	}
+	fits, err := RLSSlopes(x, y, 0.9)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fits) != 400 || math.Abs(fits[199].Slope-1) > 0.01 || math.Abs(fits[399].Slope-3) > 0.01 {
+		t.Errorf("slopes %v before and %v after the change", fits[199].Slope, fits[399].Slope)
+	}
+	if growing, _ := RLSSlopes(x, y, 1); math.Abs(growing[399].Slope-2) > 0.1 {
+		t.Errorf("expected λ 1 to average the slopes, got %v", growing[399].Slope)
+	}
+
+	for _, lambda := range []float64{0, -0.5, 1.5, math.NaN()} {
+		if _, err := NewRecursiveLeastSquares(lambda); err == nil {
+			t.Errorf("λ %v: expected an error", lambda)
+		}
+	}
+	r, _ := NewRecursiveLeastSquares(0.5)
+	r.Add(1, 1)
+	r.Add(math.NaN(), 2)
+	if s := r.Add(1, 3); s.N != 2 || s.Slope != 0 || s.RSquared != 0 {
+		t.Errorf("constant x: %+v", s)
+	}
+	r.Reset()
+	if s := r.Snapshot(); s.N != 0 || r.Lambda != 0.5 {
+		t.Errorf("after reset: %+v", s)
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// RollingRegression maintains a fit over the most recent points of a time series.
+// Points leave the window once more than Window newer points have arrived or once they are older
+// than Span relative to the newest point; a zero limit disables that bound. Adding and evicting
+// are O(1) (see OnlineRegression.Remove), with the co-moments periodically rebuilt from the
+// retained points so rounding error from long add/evict sequences cannot accumulate.
+// A RollingRegression is not safe for concurrent use.
+type RollingRegression struct {
+	Window int
+	Span   time.Duration
+
+	acc     OnlineRegression
+	points  []rollingPoint // FIFO queue, oldest at head
+	head    int
+	evicted int // evictions since the co-moments were last rebuilt
+}
+
+type rollingPoint struct {
+	t    time.Time
+	x, y float64
+}
+
+// RollingFit is the state of a rolling window after the point at Time was added
+type RollingFit struct {
+	Time time.Time `json:"time"`
+	OnlineSnapshot
+}
+
+// NewRollingRegression creates a rolling estimator over the last window points and/or span duration
+func NewRollingRegression(window int, span time.Duration) *RollingRegression {
+	return &RollingRegression{Window: window, Span: span}
+}
+
+// Add appends a point observed at t, evicts points that fell out of the window and returns the new fit.
+// Points are expected in non-decreasing time order. NaN and Inf values are ignored.
+func (r *RollingRegression) Add(t time.Time, x, y float64) RollingFit {
+	if math.IsNaN(x) || math.IsInf(x, 0) || math.IsNaN(y) || math.IsInf(y, 0) {
+		return RollingFit{Time: t, OnlineSnapshot: r.acc.Snapshot()}
+	}
+
+	r.points = append(r.points, rollingPoint{t: t, x: x, y: y})
+	r.acc.Add(x, y)
+
+	for r.Len() > 0 {
+		oldest := r.points[r.head]
+		tooMany := r.Window > 0 && r.Len() > r.Window
+		tooOld := r.Span > 0 && t.Sub(oldest.t) > r.Span
+		if !tooMany && !tooOld {
+			break
+		}
+		r.acc.Remove(oldest.x, oldest.y)
+		r.head++
+		r.evicted++
+	}
+
+	r.compact()
+	return RollingFit{Time: t, OnlineSnapshot: r.acc.Snapshot()}
+}
+
+// compact drops evicted points from the queue and rebuilds the co-moments once as many points
+// have been evicted as are retained, keeping both the memory and the rebuild cost amortized O(1)
+func (r *RollingRegression) compact() {
+	if r.head == 0 || r.evicted < r.Len() {
+		return
+	}
+
+	n := copy(r.points, r.points[r.head:])
+	r.points = r.points[:n]
+	r.head = 0
+	r.evicted = 0
+
+	r.acc.Reset()
+	for _, p := range r.points {
+		r.acc.Add(p.x, p.y)
+	}
+}
+
+// Len returns the number of points currently in the window
+func (r *RollingRegression) Len() int {
+	return len(r.points) - r.head
+}
+
+// Snapshot returns the fit over the current window
+func (r *RollingRegression) Snapshot() OnlineSnapshot {
+	return r.acc.Snapshot()
+}
+
+// Points returns a copy of the points currently in the window, oldest first
+func (r *RollingRegression) Points() Dataset {
+	data := Dataset{X: make([]float64, 0, r.Len()), Y: make([]float64, 0, r.Len())}
+	for _, p := range r.points[r.head:] {
+		data.X = append(data.X, p.x)
+		data.Y = append(data.Y, p.y)
+	}
+	return data
+}
+
+// RollingSlopes runs a rolling estimator over a whole time series and returns the fit after
+// every point, i.e. the time series of slopes used for trend monitoring
+func RollingSlopes(times []time.Time, x, y []float64, window int, span time.Duration) []RollingFit {
+	r := NewRollingRegression(window, span)
+	n := min(len(times), len(x), len(y))
+	fits := make([]RollingFit, 0, n)
+	for i := 0; i < n; i++ {
+		fits = append(fits, r.Add(times[i], x[i], y[i]))
+	}
+	return fits
+}
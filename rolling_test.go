@@ -0,0 +1,68 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// ✅ Test 1: A count window matches a fresh fit over the same last N points
+func TestRollingRegressionWindow(t *testing.T) {
+	x, y := syntheticLine(5000)
+	base := time.Unix(0, 0)
+	r := NewRollingRegression(100, 0)
+
+	for i := range x {
+		fit := r.Add(base.Add(time.Duration(i)*time.Second), x[i], y[i])
+		if i%997 != 0 || i < 100 {
+			continue
+		}
+		refSlope, refIntercept, refR2, _ := BigFloatRegression(x[i-99:i+1], y[i-99:i+1])
+		if fit.N != 100 || deviation(fit.Slope, refSlope) > 1e-9 || deviation(fit.Intercept, refIntercept) > 1e-9 || math.Abs(fit.RSquared-refR2) > 1e-9 {
+			t.Errorf("point %d: rolling %+v vs reference %v %v %v", i, fit.OnlineSnapshot, refSlope, refIntercept, refR2)
+		}
+	}
+	if r.Len() != 100 || len(r.points) > 200 {
+		t.Errorf("expected 100 retained points in at most 200 slots, got %d in %d", r.Len(), len(r.points))
+	}
+}
+
+// ✅ Test 2: A duration window evicts points older than the span
+func TestRollingRegressionSpan(t *testing.T) {
+	base := time.Unix(0, 0)
+	var times []time.Time
+	var x, y []float64
+	for i := 0; i < 20; i++ {
+		times = append(times, base.Add(time.Duration(i)*time.Minute))
+		x = append(x, float64(i))
+		slope := 1.0
+		if i >= 10 {
+			slope = 4
+		}
+		y = append(y, slope*float64(i))
+	}
+
+	fits := RollingSlopes(times, x, y, 0, 5*time.Minute)
+	if len(fits) != 20 {
+		t.Fatalf("expected a fit per point, got %d", len(fits))
+	}
+	if fits[9].N != 6 || math.Abs(fits[9].Slope-1) > 1e-12 {
+		t.Errorf("expected 6 points with slope 1 at minute 9, got %+v", fits[9])
+	}
+	if math.Abs(fits[19].Slope-4) > 1e-12 || !fits[19].Time.Equal(times[19]) {
+		t.Errorf("expected slope 4 at minute 19, got %+v", fits[19])
+	}
+}
+
+// ✅ Test 3: Invalid points are ignored and Points returns the window oldest first
+func TestRollingRegressionPoints(t *testing.T) {
+	r := NewRollingRegression(3, 0)
+	now := time.Now()
+	for _, v := range []float64{1, 2, math.NaN(), 3, 4} {
+		r.Add(now, v, 2*v)
+	}
+	data := r.Points()
+	if r.Len() != 3 || data.X[0] != 2 || data.X[2] != 4 || data.Y[2] != 8 {
+		t.Errorf("unexpected window %+v", data)
+	}
+}
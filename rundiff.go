@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// RunDiffOptions control DiffRuns. Ignore lists field names skipped wherever they appear.
+type RunDiffOptions struct {
+	Tolerance GoldenTolerance
+	Ignore    []string
+}
+
+// defaultRunDiffIgnore are the fields of result exports that change on every run
+var defaultRunDiffIgnore = []string{"duration", "created", "started", "alloc"}
+
+// DiffRuns compares two result exports (a golden file, a `history show -json` run or any other
+// JSON export of fits) field by field, numbers within the tolerance and everything else exactly,
+// and returns what changed from a to b: Want holds the value in a and Got the value in b. Lists
+// of objects with distinct names, such as datasets, are matched by name rather than position, so
+// a reordered dataset is not reported and an added one appears once under its own name.
+func DiffRuns(a, b []byte, opts RunDiffOptions) ([]GoldenDiff, error) {
+	var docA, docB any
+	if err := json.Unmarshal(a, &docA); err != nil {
+		return nil, fmt.Errorf("run A: %w", err)
+	}
+	if err := json.Unmarshal(b, &docB); err != nil {
+		return nil, fmt.Errorf("run B: %w", err)
+	}
+	var diffs []GoldenDiff
+	compareJSON("", normalizeRunJSON(docA, opts.Ignore), normalizeRunJSON(docB, opts.Ignore), opts.Tolerance, &diffs)
+	return diffs, nil
+}
+
+// normalizeRunJSON drops the ignored fields and turns lists of named objects into objects keyed
+// by name
+func normalizeRunJSON(v any, ignore []string) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, field := range v {
+			if !slices.Contains(ignore, k) {
+				out[k] = normalizeRunJSON(field, ignore)
+			}
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		byName := make(map[string]any, len(v))
+		for i, item := range v {
+			out[i] = normalizeRunJSON(item, ignore)
+			if obj, ok := out[i].(map[string]any); ok {
+				if name, ok := obj["name"].(string); ok {
+					byName[name] = obj
+				}
+			}
+		}
+		if len(v) > 0 && len(byName) == len(v) {
+			return byName
+		}
+		return out
+	}
+	return v
+}
+
+// printRunDiffs writes the changes as a table of the value in each run and, for numbers, the change
+func printRunDiffs(w io.Writer, nameA, nameB string, diffs []GoldenDiff) {
+	fmt.Fprintf(w, "=== Run Diff: %s -> %s ===\n", nameA, nameB)
+	if len(diffs) == 0 {
+		fmt.Fprintf(w, "No differences\n")
+		return
+	}
+	fmt.Fprintf(w, "%d fields changed\n\n", len(diffs))
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Field\tA\tB\tChange\t")
+	for _, d := range diffs {
+		change := ""
+		if a, ok := d.Want.(float64); ok {
+			if b, ok := d.Got.(float64); ok {
+				change = fmt.Sprintf("%+.6g", b-a)
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t\n", d.Path, formatDiffValue(d.Want), formatDiffValue(d.Got), change)
+	}
+	tw.Flush()
+}
+
+// formatDiffValue writes a JSON value on one line, summarizing objects and lists
+func formatDiffValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "-"
+	case map[string]any:
+		return fmt.Sprintf("{%d fields}", len(v))
+	case []any:
+		return fmt.Sprintf("[%d items]", len(v))
+	case string:
+		return fmt.Sprintf("%q", v)
+	}
+	return fmt.Sprint(v)
+}
+
+// isJSONExport reports whether a diff argument names a JSON export rather than a CSV file
+func isJSONExport(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".json")
+}
+
+// runRunDiff compares two JSON result exports for runDiff
+func runRunDiff(pathA, pathB string, opts RunDiffOptions) error {
+	a, err := os.ReadFile(pathA)
+	if err != nil {
+		return err
+	}
+	b, err := os.ReadFile(pathB)
+	if err != nil {
+		return err
+	}
+	diffs, err := DiffRuns(a, b, opts)
+	if err != nil {
+		return err
+	}
+	printRunDiffs(os.Stdout, pathA, pathB, diffs)
+	return nil
+}
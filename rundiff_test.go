@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Datasets are matched by name and only changed fields are reported
+func TestDiffRuns(t *testing.T) {
+	a := `{"datasets": [{"name": "I", "slope": 0.5, "duration": 10}, {"name": "II", "slope": 0.5, "engine": "stats"}]}`
+	b := `{"datasets": [{"name": "III", "slope": 0.49}, {"name": "II", "slope": 0.5000000000001, "engine": "gonum"}, {"name": "I", "slope": 0.6, "duration": 99}]}`
+	diffs, err := DiffRuns([]byte(a), []byte(b), RunDiffOptions{Tolerance: DefaultGoldenTolerance(), Ignore: defaultRunDiffIgnore})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var paths []string
+	for _, d := range diffs {
+		paths = append(paths, d.Path)
+	}
+	want := []string{"/datasets/I/slope", "/datasets/II/engine", "/datasets/III"}
+	if strings.Join(paths, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected changes at %v, got %v", want, paths)
+	}
+	if diffs[0].Want != 0.5 || diffs[0].Got != 0.6 || diffs[2].Want != nil {
+		t.Errorf("unexpected diffs %v", diffs)
+	}
+
+	loose, err := DiffRuns([]byte(a), []byte(b), RunDiffOptions{Tolerance: GoldenTolerance{Rel: 0.5}, Ignore: []string{"engine", "duration"}})
+	if err != nil || len(loose) != 1 || loose[0].Path != "/datasets/III" {
+		t.Errorf("expected only the added dataset with loose tolerances, got %v (%v)", loose, err)
+	}
+
+	// lists without distinct names are still compared by position
+	if d, _ := DiffRuns([]byte(`[{"name": "x"}, {"name": "x"}]`), []byte(`[{"name": "x"}, {"name": "x", "v": 1}]`), RunDiffOptions{}); len(d) != 1 || d[0].Path != "/1/v" {
+		t.Errorf("expected a positional difference, got %v", d)
+	}
+	if _, err := DiffRuns([]byte(`{`), []byte(`{}`), RunDiffOptions{}); err == nil || !strings.Contains(err.Error(), "run A") {
+		t.Errorf("expected a parse error for run A, got %v", err)
+	}
+}
+
+// ✅ Test 2: The report lists each change with the values of both runs
+func TestPrintRunDiffs(t *testing.T) {
+	var buf bytes.Buffer
+	printRunDiffs(&buf, "a.json", "b.json", []GoldenDiff{{Path: "/datasets/I/slope", Want: 0.5, Got: 0.75}, {Path: "/datasets/IV", Got: map[string]any{"name": "IV"}}})
+	for _, want := range []string{"a.json -> b.json", "2 fields changed", "/datasets/I/slope", "+0.25", "{1 fields}"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("report missing %q:\n%s", want, buf.String())
+		}
+	}
+	buf.Reset()
+	printRunDiffs(&buf, "a.json", "b.json", nil)
+	if !strings.Contains(buf.String(), "No differences") {
+		t.Errorf("expected no differences, got:\n%s", buf.String())
+	}
+}
+
+// ✅ Test 3: The diff subcommand compares two golden files and refuses to mix JSON with CSV
+func TestRunDiffJSON(t *testing.T) {
+	dir := t.TempDir()
+	rec := NewRunRecord(AnalyzeAll(anscombeJobs(), 2), RunRecordOptions{})
+	pathA, pathB := filepath.Join(dir, "a.json"), filepath.Join(dir, "b.json")
+	if err := WriteGoldenFile(pathA, rec); err != nil {
+		t.Fatal(err)
+	}
+	rec.Datasets[0].Slope += 0.1
+	data, _ := json.Marshal(rec)
+	if err := os.WriteFile(pathB, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runDiff([]string{"-rel", "1e-6", pathA, pathB}); err != nil {
+		t.Errorf("diff failed: %v", err)
+	}
+	if err := runDiff([]string{pathA, "b.csv"}); err == nil || !strings.Contains(err.Error(), "JSON export with a CSV") {
+		t.Errorf("expected a mixed-format error, got %v", err)
+	}
+}
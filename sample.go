@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+)
+
+// SampleOptions tunes SampleWithOptions
+type SampleOptions struct {
+	// StratifyBins > 1 divides X into that many equal-count bins and draws from each in proportion
+	// to its size, so the subsample covers the whole X range (see SplitOptions.StratifyBins)
+	StratifyBins int
+}
+
+// Sample draws n points uniformly at random without replacement. The selection depends only on
+// seed and the sample keeps the original point order; n >= the dataset size returns every point.
+func Sample(ds Dataset, n int, seed uint64) (Dataset, error) {
+	return SampleWithOptions(ds, n, seed, SampleOptions{})
+}
+
+// SampleWithOptions is Sample with optional stratification by X bins
+func SampleWithOptions(ds Dataset, n int, seed uint64, opts SampleOptions) (Dataset, error) {
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	if err := ds.validateMetadata(); err != nil {
+		return Dataset{}, err
+	}
+	if n < 0 {
+		return Dataset{}, fmt.Errorf("sample size must not be negative, got %d", n)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	var picked []int
+	if opts.StratifyBins > 1 {
+		groups := splitGroups(ds.X, opts.StratifyBins)
+		for b, count := range stratumSizes(groups, n) {
+			for _, i := range sampleIndexes(rng, len(groups[b]), count) {
+				picked = append(picked, groups[b][i])
+			}
+		}
+	} else {
+		picked = sampleIndexes(rng, len(ds.X), n)
+	}
+	slices.Sort(picked)
+
+	out := Dataset{XAxis: ds.XAxis, YAxis: ds.YAxis}
+	for _, i := range picked {
+		out.appendPoint(ds, i)
+	}
+	return out, nil
+}
+
+// sampleIndexes picks k distinct indexes in [0, n) with Floyd's algorithm, which needs memory
+// proportional to k rather than n
+func sampleIndexes(rng *rand.Rand, n, k int) []int {
+	if k >= n {
+		all := make([]int, n)
+		for i := range all {
+			all[i] = i
+		}
+		return all
+	}
+	chosen := make(map[int]struct{}, k)
+	out := make([]int, 0, k)
+	for j := n - k; j < n; j++ {
+		t := rng.IntN(j + 1)
+		if _, ok := chosen[t]; ok {
+			t = j
+		}
+		chosen[t] = struct{}{}
+		out = append(out, t)
+	}
+	return out
+}
+
+// stratumSizes shares n draws among the groups in proportion to their sizes, giving the
+// remainders to the groups with the largest fractional shares so the sizes add up to n
+func stratumSizes(groups [][]int, n int) []int {
+	total := 0
+	for _, g := range groups {
+		total += len(g)
+	}
+	sizes := make([]int, len(groups))
+	if total == 0 {
+		return sizes
+	}
+	n = min(n, total)
+
+	remainders := make([]int, len(groups))
+	assigned := 0
+	for i, g := range groups {
+		sizes[i] = n * len(g) / total
+		remainders[i] = n * len(g) % total
+		assigned += sizes[i]
+	}
+	order := make([]int, len(groups))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return remainders[b] - remainders[a] })
+	for _, i := range order[:n-assigned] {
+		sizes[i]++
+	}
+	return sizes
+}
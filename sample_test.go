@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// ✅ Test 1: Sample size, reproducibility, order and distinct points
+func TestSample(t *testing.T) {
+	x, y := syntheticLine(10000)
+	ds := Dataset{X: x, Y: y}
+
+	s, err := Sample(ds, 500, 7)
+	if err != nil || len(s.X) != 500 {
+		t.Fatalf("got %d points, err %v", len(s.X), err)
+	}
+	again, _ := Sample(ds, 500, 7)
+	other, _ := Sample(ds, 500, 8)
+	if !slices.Equal(s.X, again.X) || slices.Equal(s.X, other.X) {
+		t.Error("expected the sample to depend on the seed only")
+	}
+	// syntheticLine X is strictly increasing, so a strictly increasing sample is ordered and distinct
+	for i := 1; i < len(s.X); i++ {
+		if s.X[i] <= s.X[i-1] {
+			t.Fatalf("sample out of order or repeated at %d", i)
+		}
+	}
+
+	if all, _ := Sample(ds, 20000, 1); len(all.X) != len(ds.X) {
+		t.Errorf("oversized sample should return every point, got %d", len(all.X))
+	}
+	if _, err := Sample(ds, -1, 1); err == nil {
+		t.Error("expected an error for a negative size")
+	}
+}
+
+// ✅ Test 2: Stratified samples draw from every X bin in proportion
+func TestSampleStratified(t *testing.T) {
+	x, y := syntheticLine(1000)
+	ds := Dataset{X: x, Y: y}
+
+	s, err := SampleWithOptions(ds, 100, 3, SampleOptions{StratifyBins: 10})
+	if err != nil || len(s.X) != 100 {
+		t.Fatalf("got %d points, err %v", len(s.X), err)
+	}
+	counts := make([]int, 10)
+	for _, v := range s.X {
+		idx, _ := slices.BinarySearch(x, v)
+		counts[idx*10/len(x)]++
+	}
+	for b, c := range counts {
+		if c != 10 {
+			t.Errorf("bin %d: expected 10 points, got %d", b, c)
+		}
+	}
+
+	if sizes := stratumSizes([][]int{{0, 1, 2}, {3, 4, 5}, {6, 7, 8}}, 4); sizes[0]+sizes[1]+sizes[2] != 4 {
+		t.Errorf("stratum sizes %v do not add up to 4", sizes)
+	}
+}
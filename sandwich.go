@@ -0,0 +1,259 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// CovarianceEstimator is how LineStandardErrors estimates the covariance of the coefficients
+type CovarianceEstimator string
+
+const (
+	// CovarianceClassical assumes one residual variance for all points, estimated as SSres/(n-2)
+	CovarianceClassical CovarianceEstimator = "classical"
+	// CovarianceHC0 is White's sandwich estimator, weighting each point by its squared residual
+	CovarianceHC0 CovarianceEstimator = "hc0"
+	// CovarianceHC1 scales HC0 by n/(n-2) for the two fitted coefficients
+	CovarianceHC1 CovarianceEstimator = "hc1"
+	// CovarianceHC2 divides each squared residual by 1-h, h being the point's leverage
+	CovarianceHC2 CovarianceEstimator = "hc2"
+	// CovarianceHC3 divides each squared residual by (1-h)², which approximates the jackknife and
+	// is the safest choice in small samples
+	CovarianceHC3 CovarianceEstimator = "hc3"
+	// CovarianceHAC is Newey and West's estimator, which also allows residuals to be correlated
+	// with their neighbours in data order (see NeweyWestStandardErrors)
+	CovarianceHAC CovarianceEstimator = "hac"
+)
+
+// ParseCovarianceEstimator returns the estimator named by s, in any case
+func ParseCovarianceEstimator(s string) (CovarianceEstimator, error) {
+	switch e := CovarianceEstimator(strings.ToLower(strings.TrimSpace(s))); e {
+	case CovarianceClassical, CovarianceHC0, CovarianceHC1, CovarianceHC2, CovarianceHC3, CovarianceHAC:
+		return e, nil
+	}
+	return "", fmt.Errorf("unknown standard error estimator %q (use classical, hc0, hc1, hc2, hc3 or hac)", s)
+}
+
+// LineStandardErrors returns the standard errors of a line's slope and intercept. The HC
+// estimators stay valid when the residual variance changes along X (see BreuschPagan), where the
+// classical ones are too small or too large. Pairs with NaN/Inf values are dropped; at least three
+// complete pairs with spread in X are needed. HC2 and HC3 are undefined when a point has leverage
+// 1, as the single point away from the others in Anscombe's quartet IV does. CovarianceHAC uses
+// the lag chosen by NeweyWestLag.
+func LineStandardErrors(x, y []float64, slope, intercept float64, est CovarianceEstimator) (slopeSE, interceptSE float64, err error) {
+	if est == CovarianceHAC {
+		return NeweyWestStandardErrors(x, y, slope, intercept, -1)
+	}
+	x, y, meanX, sxx, err := standardErrorPairs(x, y)
+	if err != nil {
+		return 0, 0, err
+	}
+	n := float64(len(x))
+
+	if est == CovarianceClassical {
+		s2 := residualSumSquares(x, y, slope, intercept) / (n - 2)
+		return math.Sqrt(s2 / sxx), math.Sqrt(s2 * (1/n + meanX*meanX/sxx)), nil
+	}
+
+	// The slope is Σ d_i y_i and the intercept Σ c_i y_i, so with independent errors of variance
+	// ω_i their variances are Σ d_i² ω_i and Σ c_i² ω_i; the sandwich estimators differ in ω_i.
+	var varSlope, varIntercept float64
+	for i := range x {
+		d := (x[i] - meanX) / sxx
+		c := 1/n - meanX*d
+		r := y[i] - (intercept + slope*x[i])
+		omega := r * r
+		switch h := 1/n + (x[i]-meanX)*d; est {
+		case CovarianceHC1:
+			omega *= n / (n - 2)
+		case CovarianceHC2, CovarianceHC3:
+			if 1-h < 1e-12 {
+				return 0, 0, fmt.Errorf("point at x = %g has leverage 1, so %s is undefined; use hc0 or hc1", x[i], est)
+			}
+			omega /= 1 - h
+			if est == CovarianceHC3 {
+				omega /= 1 - h
+			}
+		case CovarianceHC0:
+		default:
+			return 0, 0, fmt.Errorf("unknown standard error estimator %q", est)
+		}
+		varSlope += d * d * omega
+		varIntercept += c * c * omega
+	}
+	return math.Sqrt(varSlope), math.Sqrt(varIntercept), nil
+}
+
+// NeweyWestStandardErrors returns the standard errors of a line's slope and intercept when the
+// residuals may be correlated up to lag points apart, as in time-ordered data: Newey and West's
+// sandwich estimator, with the autocovariances of the residual terms down-weighted linearly
+// (Bartlett weights 1 - l/(lag+1)) so the estimate stays positive. The points must be in time
+// order; pairs with NaN/Inf values are dropped and their neighbours taken as adjacent. A negative
+// lag selects NeweyWestLag; lag 0 gives HC0.
+func NeweyWestStandardErrors(x, y []float64, slope, intercept float64, lag int) (slopeSE, interceptSE float64, err error) {
+	x, y, meanX, sxx, err := standardErrorPairs(x, y)
+	if err != nil {
+		return 0, 0, err
+	}
+	n := len(x)
+	if lag < 0 {
+		lag = NeweyWestLag(n)
+	}
+	if lag >= n {
+		return 0, 0, fmt.Errorf("lag %d needs more than %d points", lag, n)
+	}
+
+	// as in LineStandardErrors, the coefficients are Σ d_i y_i and Σ c_i y_i, and u_i, v_i are
+	// the terms d_i e_i and c_i e_i whose autocovariances the estimator sums
+	u, v := make([]float64, n), make([]float64, n)
+	for i := range x {
+		d := (x[i] - meanX) / sxx
+		r := y[i] - (intercept + slope*x[i])
+		u[i], v[i] = d*r, (1/float64(n)-meanX*d)*r
+	}
+	var varSlope, varIntercept float64
+	for l := 0; l <= lag; l++ {
+		weight := 1.0
+		if l > 0 {
+			weight = 2 * (1 - float64(l)/float64(lag+1))
+		}
+		var gu, gv float64
+		for i := l; i < n; i++ {
+			gu += u[i] * u[i-l]
+			gv += v[i] * v[i-l]
+		}
+		varSlope += weight * gu
+		varIntercept += weight * gv
+	}
+	return math.Sqrt(math.Max(varSlope, 0)), math.Sqrt(math.Max(varIntercept, 0)), nil
+}
+
+// NeweyWestLag is the usual automatic lag for n points, ⌊4·(n/100)^(2/9)⌋
+func NeweyWestLag(n int) int {
+	return int(math.Floor(4 * math.Pow(float64(n)/100, 2.0/9)))
+}
+
+// standardErrorPairs drops the incomplete pairs of x and y and checks that enough are left for
+// standard errors, returning the mean and sum of squares of X
+func standardErrorPairs(x, y []float64) (cx, cy []float64, meanX, sxx float64, err error) {
+	cx, cy, err = cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return nil, nil, 0, 0, err
+	}
+	if len(cx) < 3 {
+		return nil, nil, 0, 0, fmt.Errorf("need at least three points for standard errors (have %d)", len(cx))
+	}
+	for _, v := range cx {
+		meanX += v
+	}
+	meanX /= float64(len(cx))
+	for _, v := range cx {
+		sxx += (v - meanX) * (v - meanX)
+	}
+	if sxx == 0 {
+		return nil, nil, 0, 0, fmt.Errorf("no variance in X")
+	}
+	return cx, cy, meanX, sxx, nil
+}
+
+// DurbinWatson returns the Durbin-Watson statistic of a line's residuals in data order,
+// Σ(e_i - e_{i-1})² / Σe_i². It is near 2 without autocorrelation, towards 0 when neighbouring
+// residuals move together and towards 4 when they alternate. NaN is returned for fewer than two
+// complete pairs or residuals that are all zero.
+func DurbinWatson(x, y []float64, slope, intercept float64) float64 {
+	var num, den, prev float64
+	n := 0
+	for i := range x {
+		if i >= len(y) || math.IsNaN(x[i]) || math.IsInf(x[i], 0) || math.IsNaN(y[i]) || math.IsInf(y[i], 0) {
+			continue
+		}
+		r := y[i] - (intercept + slope*x[i])
+		if n > 0 {
+			num += (r - prev) * (r - prev)
+		}
+		den += r * r
+		prev = r
+		n++
+	}
+	if n < 2 || den == 0 {
+		return math.NaN()
+	}
+	return num / den
+}
+
+// BreuschPagan tests the residuals of a line for variance that changes with X, in Koenker's
+// studentized form: n·R² of the squared residuals regressed on X, chi-squared with one degree of
+// freedom when the variance is constant. Unlike the original test it does not assume normal errors.
+func BreuschPagan(x, y []float64, slope, intercept float64) (ChiSquaredResult, error) {
+	x, y, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return ChiSquaredResult{}, err
+	}
+	if len(x) < 3 {
+		return ChiSquaredResult{}, fmt.Errorf("need at least three points for the Breusch-Pagan test (have %d)", len(x))
+	}
+	var acc OnlineRegression
+	for i := range x {
+		r := y[i] - (intercept + slope*x[i])
+		acc.Add(x[i], r*r)
+	}
+	if acc.cxx == 0 {
+		return ChiSquaredResult{}, fmt.Errorf("no variance in X")
+	}
+	stat := 0.0
+	if acc.cyy > 0 {
+		stat = float64(acc.n) * acc.Snapshot().RSquared
+	}
+	return ChiSquaredResult{Statistic: stat, DF: 1, PValue: chiSquaredSurvival(stat, 1)}, nil
+}
+
+// breuschPaganAlpha is the significance level at which printStandardErrors calls residuals
+// heteroscedastic
+const breuschPaganAlpha = 0.05
+
+// printStandardErrors writes a dataset's standard errors by est, next to the classical ones, and
+// the Breusch-Pagan and Durbin-Watson statistics that say whether they are needed. lag is the
+// Newey-West lag of CovarianceHAC, negative for NeweyWestLag.
+func printStandardErrors(w io.Writer, data Dataset, result RegressionResult, est CovarianceEstimator, lag int) {
+	var slopeSE, interceptSE float64
+	var err error
+	name := string(est)
+	if est == CovarianceHAC {
+		if lag < 0 {
+			x, _, _ := cleanPairs(nil, nil, data.X, data.Y)
+			lag = NeweyWestLag(len(x))
+		}
+		slopeSE, interceptSE, err = NeweyWestStandardErrors(data.X, data.Y, result.Slope, result.Intercept, lag)
+		name = fmt.Sprintf("hac, lag %d", lag)
+	} else {
+		slopeSE, interceptSE, err = LineStandardErrors(data.X, data.Y, result.Slope, result.Intercept, est)
+	}
+	if err != nil {
+		fmt.Fprintf(w, "  Std. errors: n/a (%v)\n", err)
+		return
+	}
+	fmt.Fprintf(w, "  Std. errors (%s): slope ± %.6f, intercept ± %.6f\n", name, slopeSE, interceptSE)
+	if est != CovarianceClassical {
+		slopeSE, interceptSE, _ = LineStandardErrors(data.X, data.Y, result.Slope, result.Intercept, CovarianceClassical)
+		fmt.Fprintf(w, "    classical: slope ± %.6f, intercept ± %.6f\n", slopeSE, interceptSE)
+	}
+	bp, err := BreuschPagan(data.X, data.Y, result.Slope, result.Intercept)
+	if err != nil {
+		return
+	}
+	verdict := "no evidence of heteroscedasticity"
+	if bp.PValue < breuschPaganAlpha {
+		verdict = "heteroscedastic: prefer HC standard errors"
+	}
+	fmt.Fprintf(w, "    Breusch-Pagan: chi2(%d) = %.4f, p = %.4g (%s)\n", bp.DF, bp.Statistic, bp.PValue, verdict)
+	if dw := DurbinWatson(data.X, data.Y, result.Slope, result.Intercept); !math.IsNaN(dw) {
+		verdict = "no sign of autocorrelation"
+		// the usual rule of thumb, as the exact bounds depend on X
+		if dw < 1.5 || dw > 2.5 {
+			verdict = "autocorrelated in data order: prefer hac standard errors"
+		}
+		fmt.Fprintf(w, "    Durbin-Watson: d = %.4f (%s)\n", dw, verdict)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// sandwichSE computes the HC standard errors from the matrix form (X'X)⁻¹ X'ΩX (X'X)⁻¹
+func sandwichSE(t *testing.T, x, y []float64, slope, intercept float64, est CovarianceEstimator) (slopeSE, interceptSE float64) {
+	t.Helper()
+	ones := make([]float64, len(x))
+	for i := range ones {
+		ones[i] = 1
+	}
+	bread, err := unscaledCovariance([][]float64{ones, x})
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := float64(len(x))
+	var meat [2][2]float64
+	for i := range x {
+		row := [2]float64{1, x[i]}
+		h := 0.0
+		for j := range 2 {
+			for k := range 2 {
+				h += row[j] * bread[j][k] * row[k]
+			}
+		}
+		r := y[i] - (intercept + slope*x[i])
+		omega := r * r
+		switch est {
+		case CovarianceHC1:
+			omega *= n / (n - 2)
+		case CovarianceHC2:
+			omega /= 1 - h
+		case CovarianceHC3:
+			omega /= (1 - h) * (1 - h)
+		}
+		for j := range 2 {
+			for k := range 2 {
+				meat[j][k] += row[j] * omega * row[k]
+			}
+		}
+	}
+	var cov [2][2]float64
+	for j := range 2 {
+		for k := range 2 {
+			for a := range 2 {
+				for b := range 2 {
+					cov[j][k] += bread[j][a] * meat[a][b] * bread[b][k]
+				}
+			}
+		}
+	}
+	return math.Sqrt(cov[1][1]), math.Sqrt(cov[0][0])
+}
+
+// ✅ Test 1: HC standard errors match the sandwich in matrix form, and classical ones the usual formula
+func TestLineStandardErrors(t *testing.T) {
+	data := LoadAnscombeDatasets()["III"]
+	slope, intercept, _, _, err := performLinearRegression(data.X, data.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, est := range []CovarianceEstimator{CovarianceHC0, CovarianceHC1, CovarianceHC2, CovarianceHC3} {
+		slopeSE, interceptSE, err := LineStandardErrors(data.X, data.Y, slope, intercept, est)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantSlope, wantIntercept := sandwichSE(t, data.X, data.Y, slope, intercept, est)
+		if math.Abs(slopeSE-wantSlope) > 1e-10 || math.Abs(interceptSE-wantIntercept) > 1e-10 {
+			t.Errorf("%s: got %g, %g, want %g, %g", est, slopeSE, interceptSE, wantSlope, wantIntercept)
+		}
+	}
+
+	slopeSE, _, err := LineStandardErrors(data.X, data.Y, slope, intercept, CovarianceClassical)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, want, _, err := slopeStdErr(data); err != nil || math.Abs(slopeSE-want) > 1e-12 {
+		t.Errorf("classical slope SE %g, want %g (err %v)", slopeSE, want, err)
+	}
+}
+
+// ✅ Test 2: HC2 and HC3 refuse points of leverage 1, and estimators are parsed by name
+func TestLineStandardErrorsErrors(t *testing.T) {
+	data := LoadAnscombeDatasets()["IV"]
+	if _, _, err := LineStandardErrors(data.X, data.Y, 0.5, 3, CovarianceHC3); err == nil || !strings.Contains(err.Error(), "leverage 1") {
+		t.Errorf("expected a leverage error for quartet IV, got %v", err)
+	}
+	if _, _, err := LineStandardErrors(data.X, data.Y, 0.5, 3, CovarianceHC1); err != nil {
+		t.Errorf("expected HC1 to work for quartet IV, got %v", err)
+	}
+	if est, err := ParseCovarianceEstimator(" HC3 "); err != nil || est != CovarianceHC3 {
+		t.Errorf("expected hc3, got %q (err %v)", est, err)
+	}
+	if _, err := ParseCovarianceEstimator("hc4"); err == nil {
+		t.Error("expected an unknown estimator error")
+	}
+}
+
+// ✅ Test 3: Breusch-Pagan detects variance growing with X and not constant variance
+func TestBreuschPagan(t *testing.T) {
+	var x, y []float64
+	for i := range 60 {
+		v := float64(i + 1)
+		sign := float64(1 - 2*(i%2))
+		x, y = append(x, v), append(y, 2*v+sign*0.1*v*v)
+	}
+	slope, intercept, _, _, err := performLinearRegression(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bp, err := BreuschPagan(x, y, slope, intercept)
+	if err != nil || bp.DF != 1 || bp.PValue > 0.001 {
+		t.Errorf("expected heteroscedasticity, got %+v (err %v)", bp, err)
+	}
+
+	data := LoadAnscombeDatasets()["I"]
+	slope, intercept, _, _, _ = performLinearRegression(data.X, data.Y)
+	if bp, err := BreuschPagan(data.X, data.Y, slope, intercept); err != nil || bp.PValue < 0.05 {
+		t.Errorf("expected no evidence of heteroscedasticity in quartet I, got %+v (err %v)", bp, err)
+	}
+}
+
+// ✅ Test 4: Newey-West standard errors reduce to HC0 at lag 0 and match the double sum of the scores
+func TestNeweyWestStandardErrors(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	slope, intercept, _, _, err := performLinearRegression(data.X, data.Y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hac, _, err := NeweyWestStandardErrors(data.X, data.Y, slope, intercept, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hc0, _, _ := LineStandardErrors(data.X, data.Y, slope, intercept, CovarianceHC0); math.Abs(hac-hc0) > 1e-12 {
+		t.Errorf("lag 0: got %g, want HC0 %g", hac, hc0)
+	}
+
+	const lag = 3
+	mean, sxx := 0.0, 0.0
+	for _, v := range data.X {
+		mean += v / float64(len(data.X))
+	}
+	for _, v := range data.X {
+		sxx += (v - mean) * (v - mean)
+	}
+	var want float64
+	for i := range data.X {
+		for j := range data.X {
+			if l := math.Abs(float64(i - j)); l <= lag {
+				ui := (data.X[i] - mean) / sxx * (data.Y[i] - intercept - slope*data.X[i])
+				uj := (data.X[j] - mean) / sxx * (data.Y[j] - intercept - slope*data.X[j])
+				want += (1 - l/(lag+1)) * ui * uj
+			}
+		}
+	}
+	if got, _, err := NeweyWestStandardErrors(data.X, data.Y, slope, intercept, lag); err != nil || math.Abs(got-math.Sqrt(want)) > 1e-12 {
+		t.Errorf("lag %d: got %g, want %g (err %v)", lag, got, math.Sqrt(want), err)
+	}
+	if _, _, err := NeweyWestStandardErrors(data.X, data.Y, slope, intercept, 11); err == nil {
+		t.Error("expected an error for a lag as long as the data")
+	}
+	if got := []int{NeweyWestLag(11), NeweyWestLag(100), NeweyWestLag(1000)}; got[0] != 2 || got[1] != 4 || got[2] != 6 {
+		t.Errorf("unexpected automatic lags %v", got)
+	}
+}
+
+// ✅ Test 5: Durbin-Watson is low for trending residuals and high for alternating ones
+func TestDurbinWatson(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5, 6}
+	if got := DurbinWatson(x, []float64{1, -1, 1, -1, 1, -1}, 0, 0); math.Abs(got-20.0/6) > 1e-12 {
+		t.Errorf("alternating residuals: got %g, want %g", got, 20.0/6)
+	}
+	if got := DurbinWatson(x, []float64{1, 1, 1, -1, -1, -1}, 0, 0); math.Abs(got-4.0/6) > 1e-12 {
+		t.Errorf("runs of residuals: got %g, want %g", got, 4.0/6)
+	}
+	if got := DurbinWatson(x, []float64{1, 2, 3, 4, 5, 6}, 1, 0); !math.IsNaN(got) {
+		t.Errorf("expected NaN for an exact fit, got %g", got)
+	}
+}
@@ -0,0 +1,482 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expr is a compiled arithmetic expression over named variables, such as "log(y) - 2*x" or
+// "x > 0 && abs(y) < 100". It has the usual operators with C precedence (^ is a power, binding
+// tighter than unary minus), comparisons and the logical operators &&, || and !, which treat any
+// non-zero value as true and yield 1 or 0. The functions are abs, sqrt, exp, log, log10, floor,
+// ceil, round, min, max, pow and isnan, and pi is a constant. Arithmetic follows IEEE rules, so
+// log of a negative value is NaN, which the fitting engines treat as missing.
+type Expr struct {
+	source string
+	eval   func(env map[string]float64) float64
+}
+
+// String returns the expression as it was written
+func (e *Expr) String() string { return e.source }
+
+// Eval evaluates the expression with the given variable values
+func (e *Expr) Eval(env map[string]float64) float64 { return e.eval(env) }
+
+// True evaluates the expression as a condition: non-zero and not NaN
+func (e *Expr) True(env map[string]float64) bool {
+	v := e.eval(env)
+	return v != 0 && !math.IsNaN(v)
+}
+
+var exprFunctions = map[string]struct {
+	arity int
+	call  func(args []float64) float64
+}{
+	"abs":   {1, func(a []float64) float64 { return math.Abs(a[0]) }},
+	"sqrt":  {1, func(a []float64) float64 { return math.Sqrt(a[0]) }},
+	"exp":   {1, func(a []float64) float64 { return math.Exp(a[0]) }},
+	"log":   {1, func(a []float64) float64 { return math.Log(a[0]) }},
+	"log10": {1, func(a []float64) float64 { return math.Log10(a[0]) }},
+	"floor": {1, func(a []float64) float64 { return math.Floor(a[0]) }},
+	"ceil":  {1, func(a []float64) float64 { return math.Ceil(a[0]) }},
+	"round": {1, func(a []float64) float64 { return math.Round(a[0]) }},
+	"isnan": {1, func(a []float64) float64 { return exprBool(math.IsNaN(a[0])) }},
+	"min":   {2, func(a []float64) float64 { return math.Min(a[0], a[1]) }},
+	"max":   {2, func(a []float64) float64 { return math.Max(a[0], a[1]) }},
+	"pow":   {2, func(a []float64) float64 { return math.Pow(a[0], a[1]) }},
+}
+
+func exprBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// CompileExpr parses an expression that may use only the listed variables, so that a misspelt
+// name is reported before any data is read
+func CompileExpr(source string, vars []string) (*Expr, error) {
+	p := &exprParser{src: source, vars: vars}
+	p.next()
+	node, err := p.or()
+	if err == nil && p.tok != "" {
+		err = fmt.Errorf("unexpected %q", p.tok)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression %q: %w", source, err)
+	}
+	return &Expr{source: strings.TrimSpace(source), eval: node}, nil
+}
+
+type exprNode = func(env map[string]float64) float64
+
+// exprParser is a recursive-descent parser with one token of lookahead; tok is "" at the end
+type exprParser struct {
+	src  string
+	pos  int
+	tok  string
+	vars []string
+}
+
+func (p *exprParser) next() {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	start := p.pos
+	switch {
+	case p.pos == len(p.src):
+	case isExprDigit(p.src[p.pos]) || p.src[p.pos] == '.':
+		for p.pos < len(p.src) && (isExprDigit(p.src[p.pos]) || p.src[p.pos] == '.') {
+			p.pos++
+		}
+		// an exponent, as in 1e-3
+		if p.pos < len(p.src) && (p.src[p.pos] == 'e' || p.src[p.pos] == 'E') {
+			p.pos++
+			if p.pos < len(p.src) && (p.src[p.pos] == '+' || p.src[p.pos] == '-') {
+				p.pos++
+			}
+			for p.pos < len(p.src) && isExprDigit(p.src[p.pos]) {
+				p.pos++
+			}
+		}
+	case isExprLetter(p.src[p.pos]):
+		for p.pos < len(p.src) && (isExprLetter(p.src[p.pos]) || isExprDigit(p.src[p.pos])) {
+			p.pos++
+		}
+	case p.pos+1 < len(p.src) && slices.Contains([]string{"&&", "||", "<=", ">=", "==", "!="}, p.src[p.pos:p.pos+2]):
+		p.pos += 2
+	default:
+		p.pos++
+	}
+	p.tok = p.src[start:p.pos]
+}
+
+func isExprDigit(c byte) bool  { return c >= '0' && c <= '9' }
+func isExprLetter(c byte) bool { return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' }
+
+// binary parses operands joined by any of ops, left to right
+func (p *exprParser) binary(operand func() (exprNode, error), ops map[string]func(a, b float64) float64) (exprNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := ops[p.tok]
+		if !ok {
+			return left, nil
+		}
+		p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(env map[string]float64) float64 { return op(l(env), right(env)) }
+	}
+}
+
+func (p *exprParser) or() (exprNode, error) {
+	return p.binary(p.and, map[string]func(a, b float64) float64{
+		"||": func(a, b float64) float64 { return exprBool(a != 0 && !math.IsNaN(a) || b != 0 && !math.IsNaN(b)) },
+	})
+}
+
+func (p *exprParser) and() (exprNode, error) {
+	return p.binary(p.comparison, map[string]func(a, b float64) float64{
+		"&&": func(a, b float64) float64 { return exprBool(a != 0 && !math.IsNaN(a) && b != 0 && !math.IsNaN(b)) },
+	})
+}
+
+func (p *exprParser) comparison() (exprNode, error) {
+	return p.binary(p.sum, map[string]func(a, b float64) float64{
+		"<":  func(a, b float64) float64 { return exprBool(a < b) },
+		"<=": func(a, b float64) float64 { return exprBool(a <= b) },
+		">":  func(a, b float64) float64 { return exprBool(a > b) },
+		">=": func(a, b float64) float64 { return exprBool(a >= b) },
+		"==": func(a, b float64) float64 { return exprBool(a == b) },
+		"!=": func(a, b float64) float64 { return exprBool(a != b) },
+	})
+}
+
+func (p *exprParser) sum() (exprNode, error) {
+	return p.binary(p.product, map[string]func(a, b float64) float64{
+		"+": func(a, b float64) float64 { return a + b },
+		"-": func(a, b float64) float64 { return a - b },
+	})
+}
+
+func (p *exprParser) product() (exprNode, error) {
+	return p.binary(p.unary, map[string]func(a, b float64) float64{
+		"*": func(a, b float64) float64 { return a * b },
+		"/": func(a, b float64) float64 { return a / b },
+		"%": math.Mod,
+	})
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	switch p.tok {
+	case "-", "!":
+		op := p.tok
+		p.next()
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if op == "-" {
+			return func(env map[string]float64) float64 { return -operand(env) }, nil
+		}
+		return func(env map[string]float64) float64 { v := operand(env); return exprBool(v == 0 || math.IsNaN(v)) }, nil
+	}
+	return p.power()
+}
+
+func (p *exprParser) power() (exprNode, error) {
+	base, err := p.primary()
+	if err != nil || p.tok != "^" {
+		return base, err
+	}
+	p.next()
+	// right-associative: 2^3^2 is 2^9
+	exponent, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	return func(env map[string]float64) float64 { return math.Pow(base(env), exponent(env)) }, nil
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	tok := p.tok
+	switch {
+	case tok == "":
+		return nil, fmt.Errorf("unexpected end")
+	case tok == "(":
+		p.next()
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok != ")" {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.next()
+		return inner, nil
+	case isExprDigit(tok[0]) || tok[0] == '.':
+		v, err := strconv.ParseFloat(tok, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q", tok)
+		}
+		p.next()
+		return func(map[string]float64) float64 { return v }, nil
+	case isExprLetter(tok[0]):
+		p.next()
+		if p.tok == "(" {
+			return p.call(tok)
+		}
+		if tok == "pi" {
+			return func(map[string]float64) float64 { return math.Pi }, nil
+		}
+		if !slices.Contains(p.vars, tok) {
+			return nil, fmt.Errorf("unknown variable %q (use %s)", tok, strings.Join(p.vars, ", "))
+		}
+		return func(env map[string]float64) float64 { return env[tok] }, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", tok)
+}
+
+func (p *exprParser) call(name string) (exprNode, error) {
+	fn, ok := exprFunctions[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q", name)
+	}
+	p.next()
+	var args []exprNode
+	for p.tok != ")" {
+		if len(args) > 0 {
+			if p.tok != "," {
+				return nil, fmt.Errorf("expected , or ) in call of %s", name)
+			}
+			p.next()
+		}
+		arg, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	p.next()
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("%s takes %d arguments, got %d", name, fn.arity, len(args))
+	}
+	return func(env map[string]float64) float64 {
+		values := make([]float64, len(args))
+		for i, arg := range args {
+			values[i] = arg(env)
+		}
+		return fn.call(values)
+	}, nil
+}
+
+// Variables available to script hooks. Point expressions see one point at a time, with i its
+// 0-based index and w its weight (1 without weights); checks see the fitted line.
+var (
+	pointVariables = []string{"x", "y", "w", "i", "n"}
+	checkVariables = []string{"slope", "intercept", "r2", "n"}
+)
+
+// Derivation replaces a column of every point with an expression of the point, as in "y = log(y)"
+type Derivation struct {
+	Column string // x, y or w
+	Expr   *Expr
+}
+
+// ParseDerivation reads "COLUMN = EXPR" where COLUMN is x, y or w
+func ParseDerivation(spec string) (Derivation, error) {
+	column, source, ok := strings.Cut(spec, "=")
+	column = strings.TrimSpace(column)
+	if !ok || !slices.Contains([]string{"x", "y", "w"}, column) {
+		return Derivation{}, fmt.Errorf("derivation %q must have the form x=EXPR, y=EXPR or w=EXPR", spec)
+	}
+	expr, err := CompileExpr(source, pointVariables)
+	if err != nil {
+		return Derivation{}, err
+	}
+	return Derivation{Column: column, Expr: expr}, nil
+}
+
+// ScriptHooks customize a run without recompiling: derivations rewrite columns point by point,
+// in order, then filters keep only the points for which every filter holds, and after the fit
+// every check must hold for the dataset to pass.
+type ScriptHooks struct {
+	Derive  []Derivation
+	Filters []*Expr
+	Checks  []*Expr
+}
+
+// Empty reports whether there are no hooks
+func (h *ScriptHooks) Empty() bool {
+	return h == nil || len(h.Derive)+len(h.Filters)+len(h.Checks) == 0
+}
+
+// AddDerive, AddFilter and AddCheck compile a hook from its command-line form
+func (h *ScriptHooks) AddDerive(spec string) error {
+	d, err := ParseDerivation(spec)
+	if err == nil {
+		h.Derive = append(h.Derive, d)
+	}
+	return err
+}
+
+func (h *ScriptHooks) AddFilter(source string) error {
+	expr, err := CompileExpr(source, pointVariables)
+	if err == nil {
+		h.Filters = append(h.Filters, expr)
+	}
+	return err
+}
+
+func (h *ScriptHooks) AddCheck(source string) error {
+	expr, err := CompileExpr(source, checkVariables)
+	if err == nil {
+		h.Checks = append(h.Checks, expr)
+	}
+	return err
+}
+
+// ReadScriptHooks reads hooks from a script with one per line: "derive y = log(y)",
+// "filter x > 0" or "check r2 > 0.5". Blank lines and lines starting with # are skipped.
+func ReadScriptHooks(r io.Reader) (*ScriptHooks, error) {
+	hooks := &ScriptHooks{}
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		kind, rest, _ := strings.Cut(text, " ")
+		var err error
+		switch kind {
+		case "derive":
+			err = hooks.AddDerive(rest)
+		case "filter":
+			err = hooks.AddFilter(rest)
+		case "check":
+			err = hooks.AddCheck(rest)
+		default:
+			err = fmt.Errorf("unknown hook %q (use derive, filter or check)", kind)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return hooks, scanner.Err()
+}
+
+// LoadScriptFile reads hooks from a script file (see ReadScriptHooks)
+func LoadScriptFile(path string) (*ScriptHooks, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	hooks, err := ReadScriptHooks(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return hooks, nil
+}
+
+// Merge appends the hooks of other after those of h
+func (h *ScriptHooks) Merge(other *ScriptHooks) {
+	h.Derive = append(h.Derive, other.Derive...)
+	h.Filters = append(h.Filters, other.Filters...)
+	h.Checks = append(h.Checks, other.Checks...)
+}
+
+// Apply runs the derivations and filters over a copy of ds. Labels, tags and weights stay aligned
+// with the points that are kept. A point whose filter evaluates to NaN is dropped.
+func (h *ScriptHooks) Apply(ds Dataset) (Dataset, error) {
+	if h == nil || len(h.Derive)+len(h.Filters) == 0 {
+		return ds, nil
+	}
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	out := ds
+	out.X, out.Y, out.Weights, out.Labels, out.Tags = nil, nil, nil, nil, nil
+	derivesWeights := slices.ContainsFunc(h.Derive, func(d Derivation) bool { return d.Column == "w" })
+	env := map[string]float64{"n": float64(len(ds.X))}
+	for i := range ds.X {
+		env["x"], env["y"], env["w"], env["i"] = ds.X[i], ds.Y[i], 1, float64(i)
+		if ds.Weights != nil {
+			env["w"] = ds.Weights[i]
+		}
+		for _, d := range h.Derive {
+			env[d.Column] = d.Expr.Eval(env)
+		}
+		kept := true
+		for _, f := range h.Filters {
+			if !f.True(env) {
+				kept = false
+				break
+			}
+		}
+		if !kept {
+			continue
+		}
+		out.X, out.Y = append(out.X, env["x"]), append(out.Y, env["y"])
+		if ds.Weights != nil || derivesWeights {
+			out.Weights = append(out.Weights, env["w"])
+		}
+		if ds.Labels != nil {
+			out.Labels = append(out.Labels, ds.Labels[i])
+		}
+		if ds.Tags != nil {
+			out.Tags = append(out.Tags, ds.Tags[i])
+		}
+	}
+	return out, nil
+}
+
+// FailedChecks returns the checks that do not hold for the line fitted to data, in order. n is the
+// number of complete pairs.
+func (h *ScriptHooks) FailedChecks(data Dataset, result RegressionResult) []*Expr {
+	if h == nil {
+		return nil
+	}
+	n := 0
+	for i := range data.X {
+		if i < len(data.Y) && !isMissing(data.X[i]) && !isMissing(data.Y[i]) {
+			n++
+		}
+	}
+	env := map[string]float64{"slope": result.Slope, "intercept": result.Intercept, "r2": result.RSquared, "n": float64(n)}
+	var failed []*Expr
+	for _, c := range h.Checks {
+		if !c.True(env) {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// printChecks writes whether a dataset passed the post-fit checks
+func printChecks(w io.Writer, failed []*Expr, total int) {
+	if len(failed) == 0 {
+		fmt.Fprintf(w, "  Checks:    all %d passed\n", total)
+		return
+	}
+	for i, c := range failed {
+		label := "  Checks:   "
+		if i > 0 {
+			label = "           "
+		}
+		fmt.Fprintf(w, "%s FAILED %s\n", label, c)
+	}
+}
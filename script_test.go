@@ -0,0 +1,153 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Expressions follow the usual precedence and treat non-zero as true
+func TestExprEval(t *testing.T) {
+	env := map[string]float64{"x": 3, "y": -2}
+	cases := map[string]float64{
+		"1 + 2 * 3":             7,
+		"(1 + 2) * 3":           9,
+		"-x^2":                  -9,
+		"2^3^2":                 512,
+		"7 % 4":                 3,
+		"abs(y) + max(x, 10)":   12,
+		"log(exp(1.5))":         1.5,
+		"x > 2 && y < 0":        1,
+		"x > 2 && !(y < 0)":     0,
+		"x < 0 || y == -2":      1,
+		"1e-1 * 10":             1,
+		"isnan(log(y))":         1,
+		"round(pi * 100) / 100": 3.14,
+	}
+	for source, want := range cases {
+		e, err := CompileExpr(source, []string{"x", "y"})
+		if err != nil {
+			t.Errorf("%s: %v", source, err)
+			continue
+		}
+		if got := e.Eval(env); math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: expected %v, got %v", source, want, got)
+		}
+	}
+}
+
+// ✅ Test 2: Malformed expressions and unknown names are rejected when compiled
+func TestExprErrors(t *testing.T) {
+	for source, fragment := range map[string]string{
+		"x +":        "unexpected end",
+		"(x":         "missing )",
+		"z > 1":      "unknown variable",
+		"foo(x)":     "unknown function",
+		"min(x)":     "takes 2 arguments",
+		"x y":        "unexpected",
+		"max(x, y":   "expected , or )",
+		"1.2.3 + x":  "invalid number",
+		"x > 1 $ 2":  "unexpected",
+		"":           "unexpected end",
+		"log(x) ==":  "unexpected end",
+		"sqrt(x,, )": "unexpected",
+	} {
+		if _, err := CompileExpr(source, []string{"x", "y"}); err == nil || !strings.Contains(err.Error(), fragment) {
+			t.Errorf("%q: expected an error containing %q, got %v", source, fragment, err)
+		}
+	}
+}
+
+// ✅ Test 3: Derivations rewrite columns in order and filters keep labels and weights aligned
+func TestScriptHooksApply(t *testing.T) {
+	var hooks ScriptHooks
+	for _, spec := range []string{"y = log(y)", "x = x - 1", "w = w * 2"} {
+		if err := hooks.AddDerive(spec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hooks.AddFilter("x > 0 && i != 3"); err != nil {
+		t.Fatal(err)
+	}
+	data := Dataset{
+		X:       []float64{1, 2, 3, 4, 5},
+		Y:       []float64{1, math.E, -1, 10, math.E * math.E},
+		Weights: []float64{1, 2, 3, 4, 5},
+		Labels:  []string{"a", "b", "c", "d", "e"},
+	}
+	out, err := hooks.Apply(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(out.X, []float64{1, 2, 4}) || !slices.Equal(out.Labels, []string{"b", "c", "e"}) || !slices.Equal(out.Weights, []float64{4, 6, 10}) {
+		t.Fatalf("unexpected points %v, labels %v, weights %v", out.X, out.Labels, out.Weights)
+	}
+	if out.Y[0] != 1 || !math.IsNaN(out.Y[1]) || math.Abs(out.Y[2]-2) > 1e-15 {
+		t.Errorf("unexpected Y %v", out.Y)
+	}
+	if data.X[0] != 1 || data.Y[1] != math.E {
+		t.Errorf("the input dataset was modified")
+	}
+
+	if _, err := ParseDerivation("z = x"); err == nil {
+		t.Errorf("expected an error deriving an unknown column")
+	}
+	if err := hooks.AddFilter("slope > 0"); err == nil {
+		t.Errorf("expected slope to be unknown in a filter")
+	}
+}
+
+// ✅ Test 4: Checks see the fitted line and report the ones that fail
+func TestScriptHooksChecks(t *testing.T) {
+	var hooks ScriptHooks
+	for _, source := range []string{"r2 > 0.6", "slope > 0.4 && slope < 0.6", "n == 11", "intercept > 5"} {
+		if err := hooks.AddCheck(source); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := hooks.AddCheck("x > 0"); err == nil {
+		t.Errorf("expected x to be unknown in a check")
+	}
+	data := LoadAnscombeDatasets()["I"]
+	slope, intercept, r2 := ManualRegression(data.X, data.Y)
+	failed := hooks.FailedChecks(data, RegressionResult{Slope: slope, Intercept: intercept, RSquared: r2})
+	if len(failed) != 1 || failed[0].String() != "intercept > 5" {
+		t.Errorf("expected only the intercept check to fail, got %v", failed)
+	}
+}
+
+// ✅ Test 5: A script file lists hooks one per line, and a bad line is reported by number
+func TestReadScriptHooks(t *testing.T) {
+	hooks, err := ReadScriptHooks(strings.NewReader("# keep positive readings\nderive y = log(y)\n\nfilter y > 0\ncheck r2 > 0.5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks.Derive) != 1 || len(hooks.Filters) != 1 || len(hooks.Checks) != 1 || hooks.Checks[0].String() != "r2 > 0.5" {
+		t.Errorf("unexpected hooks %+v", hooks)
+	}
+
+	_, err = ReadScriptHooks(strings.NewReader("filter x > 0\nkeep x < 1\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error on line 2, got %v", err)
+	}
+}
+
+// ✅ Test 6: Hooks run on every job before validation and fitting
+func TestAnalyzeAllScript(t *testing.T) {
+	var hooks ScriptHooks
+	if err := hooks.AddFilter("x < 14"); err != nil {
+		t.Fatal(err)
+	}
+	jobs := anscombeJobs()
+	for i := range jobs {
+		jobs[i].Script = &hooks
+		jobs[i].Rules = []Rule{XWithin(0, 13)}
+	}
+	for _, o := range AnalyzeAll(jobs, 2) {
+		// without the filter the rule would reject the point at x = 14 (19 in IV)
+		if o.Err != nil || len(o.Data.X) != 10 {
+			t.Errorf("%s: expected 10 points, got %d (%v)", o.Name, len(o.Data.X), o.Err)
+		}
+	}
+}
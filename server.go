@@ -0,0 +1,549 @@
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"math"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed dashboard
+var dashboardAssets embed.FS
+
+// maxUploadBytes caps request bodies so a single upload cannot exhaust memory
+const maxUploadBytes = 10 << 20
+
+// ServerOptions configures the HTTP server started by `serve`
+type ServerOptions struct {
+	Dashboard bool
+	// AllocStats adds per-fit heap allocation counts to /fit responses (see AnalyzeOptions.AllocStats)
+	AllocStats bool
+	// Anomalies, when set, enables POST /readings, which checks readings against it, and
+	// GET /anomalies, a server-sent event stream of the anomalies found
+	Anomalies *AnomalyDetector
+	// Models, when set, enables POST /predict, which evaluates them by name, and POST /bands,
+	// which computes their confidence bands for plotting
+	Models map[string]Model
+	// Registry, when set, also enables POST /predict and POST /bands and serves the models
+	// stored in it, by name and version or tag
+	Registry *ModelRegistry
+	// Keys, when set, rejects requests without one of its API keys, and RateLimit, when set,
+	// limits the requests of each client (see protect). The dashboard sends no key, so serve
+	// refuses to combine Keys with it.
+	Keys      *APIKeys
+	RateLimit *RateLimiter
+}
+
+// DatasetView is the JSON shape the dashboard uses to plot a dataset with its fit
+type DatasetView struct {
+	Name  string           `json:"name"`
+	X     []float64        `json:"x"`
+	Y     []float64        `json:"y"`
+	XAxis Axis             `json:"xAxis"`
+	YAxis Axis             `json:"yAxis"`
+	Fit   RegressionResult `json:"fit"`
+	// Band is the fit's 95% confidence and prediction band, when there are enough points for it
+	Band *ConfidenceBand `json:"band,omitempty"`
+}
+
+// NewServer builds the HTTP handler exposing the regression API and, optionally, the dashboard
+func NewServer(opts ServerOptions) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /fit", fitHandler(opts))
+	mux.HandleFunc("GET /graphql", handleGraphQL)
+	mux.HandleFunc("POST /graphql", handleGraphQL)
+
+	if opts.Anomalies != nil {
+		feed := NewAnomalyFeed()
+		mux.HandleFunc("POST /readings", readingsHandler(opts.Anomalies, feed))
+		mux.HandleFunc("GET /anomalies", anomalyStreamHandler(feed))
+	}
+
+	if len(opts.Models) > 0 || opts.Registry != nil {
+		mux.HandleFunc("POST /predict", predictHandler(opts.Models, opts.Registry))
+		mux.HandleFunc("POST /bands", bandsHandler(opts.Models, opts.Registry))
+	}
+
+	if opts.Dashboard {
+		assets, err := fs.Sub(dashboardAssets, "dashboard")
+		if err != nil {
+			// the embed directive guarantees the directory exists
+			panic(err)
+		}
+		mux.Handle("GET /", http.FileServerFS(assets))
+		mux.HandleFunc("GET /api/datasets", handleDatasets)
+		mux.HandleFunc("POST /api/upload", handleUpload)
+		mux.HandleFunc("POST /api/refit", handleRefit)
+	}
+
+	return protect(mux, opts.Keys, opts.RateLimit)
+}
+
+// fitHandler serves the hot path of server mode. Request structs, and the cleaned pairs of each fit
+// (see performLinearRegression), are pooled so sustained traffic does not churn the GC; fits go
+// through AnalyzeDataset, like every other command.
+func fitHandler(opts ServerOptions) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		handleFit(w, r, opts.AllocStats)
+	}
+}
+
+func handleFit(w http.ResponseWriter, r *http.Request, allocStats bool) {
+	start := time.Now()
+
+	req := getFitRequest()
+	defer putFitRequest(req)
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes)).Decode(req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+
+	result, err := AnalyzeDatasetWithOptions(req.Dataset, Dataset{X: req.X, Y: req.Y}, AnalyzeOptions{AllocStats: allocStats})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	// time the whole request, decoding included
+	result.Duration = time.Since(start)
+	writeJSON(w, http.StatusOK, result)
+}
+
+// PredictRequest asks a model for predictions. X lists values of a single-input model's input;
+// Rows lists observations of any model's inputs. Model may be left out when one model is loaded.
+// Version picks a version or tag of a registry model, the latest by default. Level sets the
+// coverage of the intervals, 0.95 by default.
+type PredictRequest struct {
+	Model   string      `json:"model"`
+	Version string      `json:"version,omitempty"`
+	X       []float64   `json:"x,omitempty"`
+	Rows    [][]float64 `json:"rows,omitempty"`
+	Level   *float64    `json:"level,omitempty"`
+}
+
+// PredictResponse holds one prediction per requested value or row, in request order, and their
+// intervals when the model has the statistics for them (see Model.PredictInterval). Warnings
+// flag the rows whose inputs lie outside the training range.
+type PredictResponse struct {
+	Model       string       `json:"model"`
+	Version     int          `json:"version,omitempty"`
+	Inputs      []string     `json:"inputs"`
+	Level       float64      `json:"level,omitempty"`
+	Predictions []float64    `json:"predictions"`
+	Intervals   []Prediction `json:"intervals,omitempty"`
+
+	Warnings []ExtrapolationWarning `json:"warnings,omitempty"`
+}
+
+// predictHandler evaluates saved models (see Model) for the values in a PredictRequest. Models
+// loaded at startup take precedence over the registry unless a version is requested.
+func predictHandler(models map[string]Model, registry *ModelRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req PredictRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+		level := 0.95
+		if req.Level != nil {
+			level = *req.Level
+		}
+		if !(level > 0 && level < 1) {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("level must be between 0 and 1, got %v", level)})
+			return
+		}
+		model, version, ok := lookupModel(models, registry, &req.Model, req.Version)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown model %q", req.Model)})
+			return
+		}
+
+		rows := req.Rows
+		for _, x := range req.X {
+			rows = append(rows, []float64{x})
+		}
+		resp := PredictResponse{Model: req.Model, Version: version, Inputs: model.Inputs(), Predictions: make([]float64, 0, len(rows))}
+		if model.Stats != nil {
+			resp.Level = level
+			resp.Intervals = make([]Prediction, 0, len(rows))
+		}
+		for i, row := range rows {
+			if model.Stats == nil {
+				y, err := model.Predict(row...)
+				if err != nil {
+					writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("row %d: %v", i, err)})
+					return
+				}
+				resp.Predictions = append(resp.Predictions, y)
+				for _, warning := range model.Extrapolation(row...) {
+					warning.Row = i
+					resp.Warnings = append(resp.Warnings, warning)
+				}
+				continue
+			}
+			p, err := model.PredictInterval(level, row...)
+			if err != nil {
+				writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("row %d: %v", i, err)})
+				return
+			}
+			for j := range p.Warnings {
+				p.Warnings[j].Row = i
+			}
+			resp.Predictions = append(resp.Predictions, p.Y)
+			resp.Intervals = append(resp.Intervals, p)
+			resp.Warnings = append(resp.Warnings, p.Warnings...)
+		}
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
+
+// lookupModel finds the model a request names, filling in the name when it is left out and only
+// one model is loaded. Loaded models take precedence over the registry unless a version is given.
+func lookupModel(models map[string]Model, registry *ModelRegistry, name *string, version string) (Model, int, bool) {
+	if *name == "" && len(models) == 1 && registry == nil {
+		for n := range models {
+			*name = n
+		}
+	}
+	model, ok := models[*name]
+	if (!ok || version != "") && registry != nil && *name != "" {
+		m, v, err := registry.Load(*name, version)
+		if err != nil {
+			return Model{}, 0, false
+		}
+		return m, v, true
+	}
+	return model, 0, ok
+}
+
+// BandsRequest asks for a single-input model's bands over Points values from From to To, by
+// default 50 values spanning the training range, at Level (0.95 by default)
+type BandsRequest struct {
+	Model   string   `json:"model"`
+	Version string   `json:"version,omitempty"`
+	From    *float64 `json:"from,omitempty"`
+	To      *float64 `json:"to,omitempty"`
+	Points  int      `json:"points,omitempty"`
+	Level   *float64 `json:"level,omitempty"`
+}
+
+// maxBandPoints caps the grid of a BandsRequest
+const maxBandPoints = 10000
+
+// bandsHandler responds to a BandsRequest with the model's ConfidenceBand
+func bandsHandler(models map[string]Model, registry *ModelRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req BandsRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+		model, _, ok := lookupModel(models, registry, &req.Model, req.Version)
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("unknown model %q", req.Model)})
+			return
+		}
+		level, points := 0.95, 50
+		if req.Level != nil {
+			level = *req.Level
+		}
+		if req.Points != 0 {
+			points = req.Points
+		}
+		if points < 2 || points > maxBandPoints {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("points must be between 2 and %d, got %d", maxBandPoints, points)})
+			return
+		}
+		var from, to float64
+		if len(model.Ranges) == 1 {
+			from, to = model.Ranges[0].Min, model.Ranges[0].Max
+		}
+		if req.From != nil {
+			from = *req.From
+		}
+		if req.To != nil {
+			to = *req.To
+		}
+		if (req.From == nil || req.To == nil) && len(model.Ranges) != 1 {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("model %s has no training range; give from and to", req.Model)})
+			return
+		}
+		band, err := model.Band(level, BandGrid(from, to, points))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		writeJSON(w, http.StatusOK, band)
+	}
+}
+
+// readingsHandler accepts one TelemetryReading or an array of them and responds with the anomalies
+// among them, which are also published to feed
+func readingsHandler(detector *AnomalyDetector, feed *AnomalyFeed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxUploadBytes))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("reading request body: %v", err)})
+			return
+		}
+		var readings []TelemetryReading
+		if trimmed := strings.TrimSpace(string(body)); strings.HasPrefix(trimmed, "[") {
+			err = json.Unmarshal(body, &readings)
+		} else {
+			readings = make([]TelemetryReading, 1)
+			err = json.Unmarshal(body, &readings[0])
+		}
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+			return
+		}
+
+		anomalies := []AnomalyEvent{}
+		for _, reading := range readings {
+			if event := detector.Observe(reading); event != nil {
+				feed.Publish(*event)
+				anomalies = append(anomalies, *event)
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string][]AnomalyEvent{"anomalies": anomalies})
+	}
+}
+
+// anomalyStreamHandler streams anomalies as server-sent events until the client disconnects
+func anomalyStreamHandler(feed *AnomalyFeed) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming is not supported"})
+			return
+		}
+		events, unsubscribe := feed.Subscribe()
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event := <-events:
+				payload, err := json.Marshal(event)
+				if err != nil {
+					log.Printf("Encoding anomaly failed: %v", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "event: anomaly\ndata: %s\n\n", payload); err != nil {
+					return
+				}
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func handleDatasets(w http.ResponseWriter, r *http.Request) {
+	datasets := LoadAnscombeDatasets()
+	names := make([]string, 0, len(datasets))
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	views := make([]DatasetView, 0, len(names))
+	for _, name := range names {
+		view, err := newDatasetView(name, datasets[name])
+		if err != nil {
+			log.Printf("Regression failed for dataset %s: %v", name, err)
+			continue
+		}
+		views = append(views, view)
+	}
+	writeJSON(w, http.StatusOK, views)
+}
+
+// handleUpload accepts either a multipart form with a "file" field or a raw CSV body
+func handleUpload(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxUploadBytes)
+
+	name := "upload"
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("missing file field: %v", err)})
+			return
+		}
+		defer file.Close()
+		name = strings.TrimSuffix(filepath.Base(header.Filename), filepath.Ext(header.Filename))
+		body = file
+	}
+
+	ds, err := LoadCSVDataset(body)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	view, err := newDatasetView(name, ds)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, view)
+}
+
+// RefitRequest asks for the line through X and Y refitted without the points at the indices in
+// Without, as the dashboard does when points are clicked out of a plot
+type RefitRequest struct {
+	X       []float64 `json:"x"`
+	Y       []float64 `json:"y"`
+	Without []int     `json:"without"`
+}
+
+// handleRefit answers a RefitRequest with a LineRefit
+func handleRefit(w http.ResponseWriter, r *http.Request) {
+	var req RefitRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUploadBytes)).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("invalid request body: %v", err)})
+		return
+	}
+	fit, err := FitLine(Dataset{X: req.X, Y: req.Y})
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	refit, err := fit.RefitWithout(req.Without...)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, refit)
+}
+
+// newDatasetView fits ds and keeps only the finite pairs, since NaN cannot be encoded as JSON
+func newDatasetView(name string, ds Dataset) (DatasetView, error) {
+	result, err := AnalyzeDataset(name, ds)
+	if err != nil {
+		return DatasetView{}, err
+	}
+
+	view := DatasetView{Name: name, XAxis: ds.XAxis, YAxis: ds.YAxis, Fit: result}
+	for i := range ds.X {
+		if math.IsNaN(ds.X[i]) || math.IsInf(ds.X[i], 0) || math.IsNaN(ds.Y[i]) || math.IsInf(ds.Y[i], 0) {
+			continue
+		}
+		view.X = append(view.X, ds.X[i])
+		view.Y = append(view.Y, ds.Y[i])
+	}
+	if band, err := FitBand(ds, result, scatterBandLevel, 40); err == nil {
+		view.Band = &band
+	}
+	return view, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Writing response failed: %v", err)
+	}
+}
+
+// runServe implements the `serve` subcommand
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", ":8080", "listen address")
+	dashboard := flags.Bool("dashboard", false, "serve the web dashboard at /")
+	allocStats := flags.Bool("alloc-stats", false, "report heap allocations per fit in /fit responses (adds a stop-the-world read per request)")
+	anomalyK := flags.Float64("anomaly-k", 0, "accept readings at POST /readings and stream those whose residual exceeds this many sigmas at GET /anomalies (0 disables)")
+	anomalyMin := flags.Int("anomaly-min-points", 10, "readings per device before anomalies are flagged, with -anomaly-k")
+	models := map[string]Model{}
+	flags.Func("model", "serve predictions from a model saved with -save-models or multi -save-model at POST /predict; repeatable", func(path string) error {
+		model, err := LoadModelFile(path)
+		if err != nil {
+			return err
+		}
+		if _, dup := models[model.Name]; dup {
+			return fmt.Errorf("two models are named %q", model.Name)
+		}
+		models[model.Name] = model
+		return nil
+	})
+	registryDir := flags.String("registry", "", "serve predictions at POST /predict from the models in this registry, by name and version or tag")
+	keys := map[string]string{}
+	flags.Func("api-key", "require this key (at least 16 characters) as \"Authorization: Bearer KEY\" or \"X-API-Key: KEY\" on every request; repeatable (prefer -api-keys, as flags show in the process list)", func(key string) error {
+		keys[fmt.Sprintf("key%d", len(keys)+1)] = key
+		return nil
+	})
+	keysPath := flags.String("api-keys", "", "require one of the keys in this file, one \"client key\" pair per line, on every request")
+	rate := flags.Float64("rate", 0, "limit each client (by API key, or address without one) to this many requests per second (0 disables)")
+	burst := flags.Int("burst", 20, "with -rate, requests a client may make at once before the rate applies")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *dashboard && (len(keys) > 0 || *keysPath != "") {
+		return fmt.Errorf("-dashboard cannot be used with API keys, as the dashboard does not send one; serve it without keys on a private address, or behind a proxy that authenticates users")
+	}
+	if *keysPath != "" {
+		fileKeys, err := LoadAPIKeysFile(*keysPath)
+		if err != nil {
+			return err
+		}
+		for name, key := range fileKeys {
+			if _, dup := keys[name]; dup {
+				return fmt.Errorf("client %s is named by both -api-key and -api-keys", name)
+			}
+			keys[name] = key
+		}
+	}
+	var apiKeys *APIKeys
+	if len(keys) > 0 {
+		var err error
+		if apiKeys, err = NewAPIKeys(keys); err != nil {
+			return err
+		}
+	}
+	var limiter *RateLimiter
+	if *rate != 0 {
+		var err error
+		if limiter, err = NewRateLimiter(*rate, *burst); err != nil {
+			return fmt.Errorf("-rate: %w", err)
+		}
+	}
+	var anomalies *AnomalyDetector
+	if *anomalyK != 0 {
+		var err error
+		if anomalies, err = NewAnomalyDetector(*anomalyK, *anomalyMin); err != nil {
+			return err
+		}
+	}
+	var registry *ModelRegistry
+	if *registryDir != "" {
+		var err error
+		if registry, err = OpenModelRegistry(*registryDir); err != nil {
+			return err
+		}
+	}
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           NewServer(ServerOptions{Dashboard: *dashboard, AllocStats: *allocStats, Anomalies: anomalies, Models: models, Registry: registry, Keys: apiKeys, RateLimit: limiter}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Printf("Listening on %s (dashboard: %v, API keys: %d, rate limit: %g/s)\n", *addr, *dashboard, len(keys), *rate)
+	if apiKeys == nil {
+		log.Printf("Serving without authentication; use -api-keys before exposing %s beyond this machine", *addr)
+	}
+	return server.ListenAndServe()
+}
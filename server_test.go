@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: /fit returns a RegressionResult
+func TestServerFit(t *testing.T) {
+	srv := httptest.NewServer(NewServer(ServerOptions{}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/fit", "application/json", strings.NewReader(`{"x":[1,2,3],"y":[3,5,7]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	var result RegressionResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || result.Slope != 2 || result.Intercept != 1 {
+		t.Errorf("status %d, result %+v", resp.StatusCode, result)
+	}
+}
+
+// ✅ Test 2: Dashboard routes are only mounted when enabled
+func TestServerDashboardRoutes(t *testing.T) {
+	plain := httptest.NewServer(NewServer(ServerOptions{}))
+	defer plain.Close()
+	if resp, err := http.Get(plain.URL + "/api/datasets"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Errorf("dashboard API should be disabled, got %v %v", resp.StatusCode, err)
+	}
+
+	srv := httptest.NewServer(NewServer(ServerOptions{Dashboard: true}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("index not served: %v", err)
+	}
+
+	resp, err = http.Get(srv.URL + "/api/datasets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var views []DatasetView
+	if err := json.NewDecoder(resp.Body).Decode(&views); err != nil {
+		t.Fatal(err)
+	}
+	if len(views) != 4 || views[0].Name != "I" {
+		t.Errorf("unexpected dataset listing: %+v", views)
+	}
+}
+
+// ✅ Test 3: CSV uploads are analyzed, NaN rows are dropped from the view
+func TestServerUpload(t *testing.T) {
+	srv := httptest.NewServer(NewServer(ServerOptions{Dashboard: true}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	form := multipart.NewWriter(&buf)
+	part, _ := form.CreateFormFile("file", "trend.csv")
+	part.Write([]byte("x,y\n1,1\n2,NA\n3,3\n4,4\n"))
+	form.Close()
+
+	resp, err := http.Post(srv.URL+"/api/upload", form.FormDataContentType(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var view DatasetView
+	if err := json.NewDecoder(resp.Body).Decode(&view); err != nil {
+		t.Fatal(err)
+	}
+	if view.Name != "trend" || len(view.X) != 3 || view.Fit.Slope != 1 {
+		t.Errorf("unexpected upload view: %+v", view)
+	}
+}
+
+// ✅ Test 4: Uploaded headers are returned as plot axes
+func TestServerUploadAxes(t *testing.T) {
+	data, err := LoadCSVDataset(strings.NewReader("time (day),dose (mg)\n1,1\n2,2\n3,3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := newDatasetView("dose", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(view)
+	if !strings.Contains(string(body), `"xAxis":{"label":"time","unit":"day"}`) {
+		t.Errorf("axes missing from view: %s", body)
+	}
+}
+
+// ✅ Test 5: The dashboard refits without clicked-out points, and says when none would be left
+func TestServerRefit(t *testing.T) {
+	srv := httptest.NewServer(NewServer(ServerOptions{Dashboard: true}))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/api/refit", "application/json", strings.NewReader(`{"x":[1,2,3,4],"y":[3,5,7,20],"without":[3]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var refit LineRefit
+	if err := json.NewDecoder(resp.Body).Decode(&refit); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || refit.N != 3 || math.Abs(refit.Slope-2) > 1e-9 || math.Abs(refit.Intercept-1) > 1e-9 {
+		t.Errorf("status %d, refit %+v", resp.StatusCode, refit)
+	}
+
+	resp, err = http.Post(srv.URL+"/api/refit", "application/json", strings.NewReader(`{"x":[1,2,3],"y":[3,5,7],"without":[0,1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 for a refit of one point, got %d", resp.StatusCode)
+	}
+}
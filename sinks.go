@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// OutputFormat names a report of a run that can be delivered to sinks
+type OutputFormat string
+
+const (
+	// OutputJSON is the run record, as golden files and the history database hold it
+	OutputJSON OutputFormat = "json"
+	// OutputHTML is the HTML report with its plots
+	OutputHTML OutputFormat = "html"
+	// OutputManifest is the reproducibility manifest
+	OutputManifest OutputFormat = "manifest"
+)
+
+// Output is one report rendered for delivery. Manifest and Run are what the report was rendered
+// from, for sinks that store the run rather than the rendered bytes.
+type Output struct {
+	Format      OutputFormat
+	ContentType string
+	Body        []byte
+	Manifest    Manifest
+	Run         RunRecord
+}
+
+// Title names the report in messages
+func (o Output) Title() string {
+	switch o.Format {
+	case OutputHTML:
+		return "HTML report"
+	case OutputManifest:
+		return "Manifest"
+	}
+	return "Run record"
+}
+
+// OutputSink is a destination for reports. String describes the destination in messages.
+type OutputSink interface {
+	Deliver(ctx context.Context, out Output) error
+	String() string
+}
+
+// OutputTarget delivers the report in Format to Sink
+type OutputTarget struct {
+	Format OutputFormat
+	Sink   OutputSink
+}
+
+// ParseOutputTarget parses FORMAT=DEST, where DEST is - for standard output, an http or https URL
+// to POST to, s3://bucket/key for an S3 object (see S3Sink), history:PATH for a history database,
+// which only takes json, or else a file path
+func ParseOutputTarget(spec string) (OutputTarget, error) {
+	format, dest, ok := strings.Cut(spec, "=")
+	if !ok || dest == "" {
+		return OutputTarget{}, fmt.Errorf("output %q is not FORMAT=DEST", spec)
+	}
+	t := OutputTarget{Format: OutputFormat(strings.ToLower(format))}
+	switch t.Format {
+	case OutputJSON, OutputHTML, OutputManifest:
+	default:
+		return OutputTarget{}, fmt.Errorf("unknown output format %q (use json, html or manifest)", format)
+	}
+	switch {
+	case dest == "-":
+		t.Sink = ConsoleSink{W: os.Stdout}
+	case strings.HasPrefix(dest, "http://") || strings.HasPrefix(dest, "https://"):
+		t.Sink = NewHTTPSink(dest)
+	case strings.HasPrefix(dest, "s3://"):
+		sink, err := NewS3Sink(dest)
+		if err != nil {
+			return OutputTarget{}, err
+		}
+		t.Sink = sink
+	case strings.HasPrefix(dest, "history:"):
+		if t.Format != OutputJSON {
+			return OutputTarget{}, fmt.Errorf("a history database records the json run record, not %s", t.Format)
+		}
+		t.Sink = &HistorySink{Path: strings.TrimPrefix(dest, "history:")}
+	default:
+		t.Sink = FileSink{Path: strings.TrimPrefix(dest, "file:")}
+	}
+	return t, nil
+}
+
+// DeliverOutputs renders each format the targets ask for once, with render, and delivers it to
+// every target of that format in order. It stops at the first failure; done is called after
+// each delivery.
+func DeliverOutputs(ctx context.Context, targets []OutputTarget, render func(OutputFormat) (Output, error), done func(OutputTarget, Output)) error {
+	rendered := map[OutputFormat]Output{}
+	for _, t := range targets {
+		out, ok := rendered[t.Format]
+		if !ok {
+			var err error
+			if out, err = render(t.Format); err != nil {
+				return fmt.Errorf("rendering %s: %w", t.Format, err)
+			}
+			rendered[t.Format] = out
+		}
+		if err := t.Sink.Deliver(ctx, out); err != nil {
+			return fmt.Errorf("delivering %s to %s: %w", t.Format, t.Sink, err)
+		}
+		if done != nil {
+			done(t, out)
+		}
+	}
+	return nil
+}
+
+// ConsoleSink writes reports to W, normally standard output
+type ConsoleSink struct {
+	W io.Writer
+}
+
+// Deliver implements OutputSink
+func (s ConsoleSink) Deliver(_ context.Context, out Output) error {
+	_, err := s.W.Write(out.Body)
+	return err
+}
+
+func (s ConsoleSink) String() string { return "standard output" }
+
+// FileSink writes reports to the file at Path, replacing it
+type FileSink struct {
+	Path string
+}
+
+// Deliver implements OutputSink
+func (s FileSink) Deliver(_ context.Context, out Output) error {
+	return os.WriteFile(s.Path, out.Body, 0o644)
+}
+
+func (s FileSink) String() string { return s.Path }
+
+// HTTPSink posts reports to URL with their content type; any status other than 2xx is an error
+type HTTPSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewHTTPSink creates a sink posting to rawURL with a 30 second timeout
+func NewHTTPSink(rawURL string) *HTTPSink {
+	return &HTTPSink{URL: rawURL, Client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+// Deliver implements OutputSink
+func (s *HTTPSink) Deliver(ctx context.Context, out Output) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(out.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", out.ContentType)
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("server responded %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *HTTPSink) String() string { return s.URL }
+
+// HistorySink records the run of each report in the history database at Path (see HistoryStore);
+// the rendered bytes are not used. Recorded is the id of the last run recorded.
+type HistorySink struct {
+	Path     string
+	Recorded int64
+}
+
+// Deliver implements OutputSink
+func (s *HistorySink) Deliver(_ context.Context, out Output) error {
+	store, err := OpenHistory(s.Path)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	s.Recorded, err = store.Record(out.Manifest, out.Run)
+	return err
+}
+
+func (s *HistorySink) String() string { return "history database " + s.Path }
+
+// S3Sink puts reports as an object of an S3 bucket, signed with AWS Signature Version 4. The
+// credentials and region come from the usual environment variables: AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN when the keys are temporary, and AWS_REGION (us-east-1
+// when unset). AWS_ENDPOINT_URL_S3 or AWS_ENDPOINT_URL point it at an S3-compatible service such
+// as MinIO instead, addressing the bucket in the path.
+type S3Sink struct {
+	Bucket, Key  string
+	Region       string
+	Endpoint     string // scheme and host; the bucket is in the path when set
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+	Client       *http.Client
+	now          func() time.Time
+}
+
+// NewS3Sink creates a sink for rawURL, s3://bucket/key, with credentials from the environment
+func NewS3Sink(rawURL string) (*S3Sink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "s3" || u.Host == "" || strings.Trim(u.Path, "/") == "" {
+		return nil, fmt.Errorf("S3 output %q is not s3://bucket/key", rawURL)
+	}
+	s := &S3Sink{
+		Bucket:       u.Host,
+		Key:          strings.TrimPrefix(u.Path, "/"),
+		Region:       os.Getenv("AWS_REGION"),
+		Endpoint:     os.Getenv("AWS_ENDPOINT_URL_S3"),
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		Client:       &http.Client{Timeout: 60 * time.Second},
+	}
+	if s.Region == "" {
+		s.Region = "us-east-1"
+	}
+	if s.Endpoint == "" {
+		s.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	if s.AccessKey == "" || s.SecretKey == "" {
+		return nil, fmt.Errorf("S3 output needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	return s, nil
+}
+
+// objectURL is where the object is put
+func (s *S3Sink) objectURL() string {
+	key := s3EscapePath(s.Key)
+	if s.Endpoint != "" {
+		return strings.TrimSuffix(s.Endpoint, "/") + "/" + s.Bucket + "/" + key
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, key)
+}
+
+// Deliver implements OutputSink
+func (s *S3Sink) Deliver(ctx context.Context, out Output) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(), bytes.NewReader(out.Body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", out.ContentType)
+	now := time.Now
+	if s.now != nil {
+		now = s.now
+	}
+	s.sign(req, out.Body, now().UTC())
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// S3 explains refusals in a short XML body
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("S3 responded %s: %s", resp.Status, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+func (s *S3Sink) String() string { return "s3://" + s.Bucket + "/" + s.Key }
+
+// sign adds the Signature Version 4 headers for body at t to req, signing the host and every
+// header already set
+func (s *S3Sink) sign(req *http.Request, body []byte, t time.Time) {
+	payload := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(payload[:])
+	stamp, day := t.Format("20060102T150405Z"), t.Format("20060102")
+	req.Header.Set("X-Amz-Date", stamp)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var canonical strings.Builder
+	fmt.Fprintf(&canonical, "%s\n%s\n%s\n", req.Method, req.URL.EscapedPath(), req.URL.RawQuery)
+	for _, name := range names {
+		fmt.Fprintf(&canonical, "%s:%s\n", name, headers[name])
+	}
+	signed := strings.Join(names, ";")
+	fmt.Fprintf(&canonical, "\n%s\n%s", signed, payloadHash)
+
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	request := sha256.Sum256([]byte(canonical.String()))
+	toSign := "AWS4-HMAC-SHA256\n" + stamp + "\n" + scope + "\n" + hex.EncodeToString(request[:])
+	key := s3SigningKey(s.SecretKey, day, s.Region, "s3")
+	signature := hex.EncodeToString(hmacSHA256(key, toSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKey, scope, signed, signature))
+}
+
+// s3SigningKey derives the Signature Version 4 key for a day, region and service
+func s3SigningKey(secret, day, region, service string) []byte {
+	key := hmacSHA256([]byte("AWS4"+secret), day)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// s3EscapePath escapes an object key as Signature Version 4 expects: every byte but the unreserved
+// characters and the slashes between segments
+func s3EscapePath(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// renderOutput renders a run's report in format: the run record and manifest as indented JSON,
+// or the HTML report of datasets with opts
+func renderOutput(format OutputFormat, manifest Manifest, run RunRecord, title string, datasets []HTMLReportDataset, opts HTMLReportOptions) (Output, error) {
+	out := Output{Format: format, ContentType: "application/json", Manifest: manifest, Run: run}
+	var err error
+	switch format {
+	case OutputJSON:
+		out.Body, err = json.MarshalIndent(run, "", "  ")
+	case OutputManifest:
+		out.Body, err = json.MarshalIndent(manifest, "", "  ")
+	case OutputHTML:
+		var buf bytes.Buffer
+		err = WriteHTMLReport(&buf, title, datasets, opts)
+		out.Body, out.ContentType = buf.Bytes(), "text/html; charset=utf-8"
+		return out, err
+	default:
+		return Output{}, fmt.Errorf("unknown output format %q", format)
+	}
+	out.Body = append(out.Body, '\n')
+	return out, err
+}
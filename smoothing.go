@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SMA returns the trailing simple moving average over window points. The first window-1 entries,
+// and any window containing a missing value, are NaN so the fitters drop them.
+func SMA(values []float64, window int) ([]float64, error) {
+	if window < 1 {
+		return nil, fmt.Errorf("window must be at least 1, got %d", window)
+	}
+	out := make([]float64, len(values))
+	var sum float64
+	missing := 0
+	for i, v := range values {
+		if isMissing(v) {
+			missing++
+		} else {
+			sum += v
+		}
+		if i >= window {
+			if old := values[i-window]; isMissing(old) {
+				missing--
+			} else {
+				sum -= old
+			}
+		}
+		if i < window-1 || missing > 0 {
+			out[i] = math.NaN()
+			continue
+		}
+		out[i] = sum / float64(window)
+	}
+	return out, nil
+}
+
+// EMA returns the exponential moving average s_t = alpha*v_t + (1-alpha)*s_{t-1}, starting from
+// the first value. Missing values stay NaN and leave the average unchanged.
+func EMA(values []float64, alpha float64) ([]float64, error) {
+	if !(alpha > 0 && alpha <= 1) {
+		return nil, fmt.Errorf("alpha must be in (0, 1], got %v", alpha)
+	}
+	out := make([]float64, len(values))
+	s, started := 0.0, false
+	for i, v := range values {
+		if isMissing(v) {
+			out[i] = math.NaN()
+			continue
+		}
+		if !started {
+			s, started = v, true
+		} else {
+			s = alpha*v + (1-alpha)*s
+		}
+		out[i] = s
+	}
+	return out, nil
+}
+
+// HoltResult is Holt's linear exponential smoothing of a series: a level and a trend per step.
+// Level and Trend are NaN where the input was missing; SSE is the sum of squared one-step-ahead
+// forecast errors the parameters were judged by.
+type HoltResult struct {
+	Alpha float64   `json:"alpha"`
+	Beta  float64   `json:"beta"`
+	Level []float64 `json:"level"`
+	Trend []float64 `json:"trend"`
+	SSE   float64   `json:"sse"`
+
+	lastLevel, lastTrend float64
+}
+
+// Holt smooths values with level parameter alpha and trend parameter beta, both in (0, 1].
+// As in R's HoltWinters, the level starts at the second value and the trend at their difference.
+func Holt(values []float64, alpha, beta float64) (HoltResult, error) {
+	if !(alpha > 0 && alpha <= 1) || !(beta > 0 && beta <= 1) {
+		return HoltResult{}, fmt.Errorf("alpha and beta must be in (0, 1], got %v and %v", alpha, beta)
+	}
+	first, second := -1, -1
+	for i, v := range values {
+		if isMissing(v) {
+			continue
+		}
+		if first < 0 {
+			first = i
+		} else {
+			second = i
+			break
+		}
+	}
+	if second < 0 {
+		return HoltResult{}, fmt.Errorf("need at least two finite values")
+	}
+
+	r := HoltResult{Alpha: alpha, Beta: beta, Level: make([]float64, len(values)), Trend: make([]float64, len(values))}
+	level := values[second]
+	trend := (values[second] - values[first]) / float64(second-first)
+	for i := range values {
+		switch {
+		case i < second && i != first:
+			r.Level[i], r.Trend[i] = math.NaN(), math.NaN()
+			continue
+		case i == first:
+			r.Level[i], r.Trend[i] = values[first], trend
+			continue
+		case i == second:
+			r.Level[i], r.Trend[i] = level, trend
+			continue
+		}
+
+		forecast := level + trend
+		if isMissing(values[i]) {
+			level = forecast
+			r.Level[i], r.Trend[i] = math.NaN(), math.NaN()
+			continue
+		}
+		r.SSE += (values[i] - forecast) * (values[i] - forecast)
+		prevLevel := level
+		level = alpha*values[i] + (1-alpha)*forecast
+		trend = beta*(level-prevLevel) + (1-beta)*trend
+		r.Level[i], r.Trend[i] = level, trend
+	}
+	r.lastLevel, r.lastTrend = level, trend
+	return r, nil
+}
+
+// Forecast extrapolates the smoothed series h steps past its end
+func (r HoltResult) Forecast(h float64) float64 {
+	return r.lastLevel + h*r.lastTrend
+}
+
+// FitHolt chooses alpha and beta minimizing the one-step-ahead squared error over a 0.05 grid,
+// for use as a standalone trend estimate
+func FitHolt(values []float64) (HoltResult, error) {
+	var best HoltResult
+	found := false
+	for a := 1; a <= 20; a++ {
+		for b := 1; b <= 20; b++ {
+			r, err := Holt(values, float64(a)/20, float64(b)/20)
+			if err != nil {
+				return HoltResult{}, err
+			}
+			if !found || r.SSE < best.SSE {
+				best, found = r, true
+			}
+		}
+	}
+	return best, nil
+}
+
+// SmoothMethod selects how Smooth preprocesses Y
+type SmoothMethod string
+
+const (
+	SmoothNone SmoothMethod = ""     // leave the data as loaded
+	SmoothSMA  SmoothMethod = "sma"  // trailing simple moving average
+	SmoothEMA  SmoothMethod = "ema"  // exponential moving average
+	SmoothHolt SmoothMethod = "holt" // Holt level (linear exponential smoothing)
+)
+
+// SmoothOptions configures Smooth. Window is used by SMA, Alpha by EMA and Holt, Beta by Holt.
+type SmoothOptions struct {
+	Method SmoothMethod
+	Window int
+	Alpha  float64
+	Beta   float64
+}
+
+// ParseSmoothOptions reads the command-line form of SmoothOptions: "sma:WINDOW", "ema:ALPHA" or
+// "holt:ALPHA,BETA". An empty spec disables smoothing.
+func ParseSmoothOptions(spec string) (SmoothOptions, error) {
+	if spec == "" {
+		return SmoothOptions{}, nil
+	}
+	method, args, _ := strings.Cut(spec, ":")
+	opts := SmoothOptions{Method: SmoothMethod(method)}
+	var err error
+	switch opts.Method {
+	case SmoothSMA:
+		opts.Window, err = strconv.Atoi(args)
+	case SmoothEMA:
+		opts.Alpha, err = strconv.ParseFloat(args, 64)
+	case SmoothHolt:
+		alpha, beta, ok := strings.Cut(args, ",")
+		opts.Alpha, err = strconv.ParseFloat(alpha, 64)
+		if err == nil {
+			opts.Beta, err = strconv.ParseFloat(beta, 64)
+		}
+		if !ok && err == nil {
+			err = fmt.Errorf("missing beta")
+		}
+	default:
+		return SmoothOptions{}, fmt.Errorf("unknown smoothing method %q (use sma:WINDOW, ema:ALPHA or holt:ALPHA,BETA)", method)
+	}
+	if err != nil {
+		return SmoothOptions{}, fmt.Errorf("invalid smoothing %q: %w", spec, err)
+	}
+	return opts, nil
+}
+
+// String returns the command-line form of the options
+func (o SmoothOptions) String() string {
+	switch o.Method {
+	case SmoothSMA:
+		return fmt.Sprintf("sma:%d", o.Window)
+	case SmoothEMA:
+		return fmt.Sprintf("ema:%g", o.Alpha)
+	case SmoothHolt:
+		return fmt.Sprintf("holt:%g,%g", o.Alpha, o.Beta)
+	}
+	return ""
+}
+
+// Smooth returns a copy of ds with Y smoothed along increasing X, keeping the original point
+// order and metadata. Points whose smoothed value is undefined, or whose X is missing, get a NaN Y
+// and are dropped by the fit.
+func Smooth(ds Dataset, opts SmoothOptions) (Dataset, error) {
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	if opts.Method == SmoothNone {
+		return ds, nil
+	}
+
+	order := make([]int, 0, len(ds.X))
+	for i, x := range ds.X {
+		if !isMissing(x) {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return ds.X[order[a]] < ds.X[order[b]] })
+	series := make([]float64, len(order))
+	for k, i := range order {
+		series[k] = ds.Y[i]
+	}
+
+	var smoothed []float64
+	var err error
+	switch opts.Method {
+	case SmoothSMA:
+		smoothed, err = SMA(series, opts.Window)
+	case SmoothEMA:
+		smoothed, err = EMA(series, opts.Alpha)
+	case SmoothHolt:
+		var r HoltResult
+		r, err = Holt(series, opts.Alpha, opts.Beta)
+		smoothed = r.Level
+	default:
+		err = fmt.Errorf("unknown smoothing method %q", opts.Method)
+	}
+	if err != nil {
+		return Dataset{}, err
+	}
+
+	out := ds
+	out.Y = make([]float64, len(ds.Y))
+	for i := range out.Y {
+		out.Y[i] = math.NaN()
+	}
+	for k, i := range order {
+		out.Y[i] = smoothed[k]
+	}
+	return out, nil
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: SMA averages trailing windows and leaves incomplete ones NaN
+func TestSMA(t *testing.T) {
+	got, err := SMA([]float64{1, 2, 3, 4, math.NaN(), 6, 7, 8}, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []float64{math.NaN(), math.NaN(), 2, 3, math.NaN(), math.NaN(), math.NaN(), 7}
+	for i := range want {
+		if math.IsNaN(want[i]) != math.IsNaN(got[i]) || (!math.IsNaN(want[i]) && math.Abs(got[i]-want[i]) > 1e-12) {
+			t.Errorf("SMA[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+	if _, err := SMA([]float64{1}, 0); err == nil {
+		t.Error("expected an error for a zero window")
+	}
+}
+
+// ✅ Test 2: EMA follows its recursion and skips missing values
+func TestEMA(t *testing.T) {
+	got, err := EMA([]float64{2, 4, math.NaN(), 8}, 0.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got[0] != 2 || got[1] != 3 || !math.IsNaN(got[2]) || got[3] != 5.5 {
+		t.Errorf("unexpected EMA %v", got)
+	}
+	if _, err := EMA(got, 1.5); err == nil {
+		t.Error("expected an error for alpha above 1")
+	}
+}
+
+// ✅ Test 3: Holt recovers a linear series exactly and forecasts along it
+func TestHoltLinear(t *testing.T) {
+	series := []float64{3, 5, 7, 9, 11, 13}
+	r, err := Holt(series, 0.4, 0.3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.SSE > 1e-20 {
+		t.Errorf("expected no one-step error, got SSE %v", r.SSE)
+	}
+	if math.Abs(r.Forecast(2)-17) > 1e-9 || math.Abs(r.Trend[len(series)-1]-2) > 1e-9 {
+		t.Errorf("forecast %v, trend %v; want 17 and 2", r.Forecast(2), r.Trend[len(series)-1])
+	}
+
+	fit, err := FitHolt([]float64{1, 3, 2, 5, 4, 7, 6, 9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manual, _ := Holt([]float64{1, 3, 2, 5, 4, 7, 6, 9}, 0.5, 0.5)
+	if fit.SSE > manual.SSE {
+		t.Errorf("grid search SSE %v is worse than alpha=beta=0.5 (%v)", fit.SSE, manual.SSE)
+	}
+	if _, err := Holt([]float64{1, math.NaN()}, 0.5, 0.5); err == nil {
+		t.Error("expected an error with a single finite value")
+	}
+}
+
+// ✅ Test 4: Smoothing specs round-trip and bad ones are rejected
+func TestParseSmoothOptions(t *testing.T) {
+	for _, spec := range []string{"", "sma:4", "ema:0.3", "holt:0.5,0.2"} {
+		opts, err := ParseSmoothOptions(spec)
+		if err != nil {
+			t.Errorf("%q: %v", spec, err)
+			continue
+		}
+		if opts.String() != spec {
+			t.Errorf("%q round-tripped to %q", spec, opts.String())
+		}
+	}
+	for _, spec := range []string{"sma", "ema:x", "holt:0.5", "median:3"} {
+		if _, err := ParseSmoothOptions(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+// ✅ Test 5: Smooth works along increasing X but keeps the dataset's point order
+func TestSmoothKeepsOrder(t *testing.T) {
+	ds := Dataset{
+		X:      []float64{3, 1, math.NaN(), 2},
+		Y:      []float64{30, 10, 99, 20},
+		Labels: []string{"c", "a", "?", "b"},
+	}
+	out, err := Smooth(ds, SmoothOptions{Method: SmoothSMA, Window: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Y[0] != 25 || !math.IsNaN(out.Y[1]) || !math.IsNaN(out.Y[2]) || out.Y[3] != 15 {
+		t.Errorf("unexpected smoothed Y %v", out.Y)
+	}
+	if out.Labels[0] != "c" || ds.Y[0] != 30 {
+		t.Error("expected metadata kept and the input left untouched")
+	}
+}
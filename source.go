@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// PointSource yields x,y pairs one at a time, so loaders can stream points from disk or the
+// network into the incremental fitter without materializing slices. Next returns ok=false once
+// the source is exhausted; sources that can fail also implement SourceError.
+type PointSource interface {
+	Next() (x, y float64, ok bool)
+}
+
+// SourceError is implemented by sources that can stop early because of an error
+type SourceError interface {
+	Err() error
+}
+
+// SliceSource iterates over an in-memory dataset
+type SliceSource struct {
+	data Dataset
+	i    int
+}
+
+// NewSliceSource returns a PointSource over data. Extra values in the longer slice are ignored.
+func NewSliceSource(data Dataset) *SliceSource {
+	return &SliceSource{data: data}
+}
+
+// Next implements PointSource
+func (s *SliceSource) Next() (x, y float64, ok bool) {
+	if s.i >= len(s.data.X) || s.i >= len(s.data.Y) {
+		return 0, 0, false
+	}
+	x, y = s.data.X[s.i], s.data.Y[s.i]
+	s.i++
+	return x, y, true
+}
+
+// CSVSource streams x,y pairs from the first two columns of a CSV stream, with the same rules as
+// LoadCSVDataset: a non-numeric first row is a header, and empty or NA fields are NaN.
+type CSVSource struct {
+	reader *csv.Reader
+	locale NumberLocale
+	line   int
+	header []string
+	err    error
+}
+
+// NewCSVSource returns a PointSource reading r lazily, one record per Next call
+func NewCSVSource(r io.Reader) *CSVSource {
+	return NewCSVSourceWithLocale(r, NumberLocale{})
+}
+
+// NewCSVSourceWithLocale is NewCSVSource for numbers and field separators written in locale l
+func NewCSVSourceWithLocale(r io.Reader, l NumberLocale) *CSVSource {
+	reader := l.newCSVReader(r)
+	reader.ReuseRecord = true
+	return &CSVSource{reader: reader, locale: l}
+}
+
+// Next implements PointSource
+func (s *CSVSource) Next() (x, y float64, ok bool) {
+	for s.err == nil {
+		record, err := s.reader.Read()
+		if errors.Is(err, io.EOF) {
+			return 0, 0, false
+		}
+		if err != nil {
+			s.err = fmt.Errorf("reading CSV: %w", err)
+			break
+		}
+		s.line++
+
+		if len(record) < 2 {
+			s.err = fmt.Errorf("line %d: expected at least 2 columns, got %d", s.line, len(record))
+			break
+		}
+
+		x, errX := s.locale.ParseFloat(record[0])
+		y, errY := s.locale.ParseFloat(record[1])
+		if errX != nil || errY != nil {
+			if s.line == 1 {
+				// header row
+				s.header = slices.Clone(record)
+				continue
+			}
+			s.err = fmt.Errorf("line %d: non-numeric value in %q", s.line, strings.Join(record[:2], string(s.reader.Comma)))
+			break
+		}
+		return x, y, true
+	}
+	return 0, 0, false
+}
+
+// Header returns the header row, or nil when the stream has none or it has not been read yet
+func (s *CSVSource) Header() []string {
+	return s.header
+}
+
+// Err returns the error that stopped the source, if any
+func (s *CSVSource) Err() error {
+	return s.err
+}
+
+// AddSource adds every point of src to the fit and returns how many were read.
+// NaN and Inf pairs are read but ignored, as with Add.
+func (o *OnlineRegression) AddSource(src PointSource) int {
+	read := 0
+	for {
+		x, y, ok := src.Next()
+		if !ok {
+			return read
+		}
+		o.Add(x, y)
+		read++
+	}
+}
+
+// FitSource fits a streamed series in a single pass with constant memory.
+// Source errors are returned as-is.
+func FitSource(src PointSource) (slope, intercept, rSquared float64, err error) {
+	var acc OnlineRegression
+	read := acc.AddSource(src)
+	if e, ok := src.(SourceError); ok && e.Err() != nil {
+		return 0, 0, 0, e.Err()
+	}
+	// A source yields X and Y together, so only the count can be short.
+	if err := checkPairLengths(read, read); err != nil {
+		return 0, 0, 0, err
+	}
+	if err := checkValidPairs(acc.N()); err != nil {
+		return 0, 0, 0, err
+	}
+
+	s := acc.Snapshot()
+	return s.Slope, s.Intercept, s.RSquared, nil
+}
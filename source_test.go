@@ -0,0 +1,49 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Streaming a CSV gives the same fit as loading it
+func TestFitSourceCSV(t *testing.T) {
+	input := "x,y\n1,3\n2,5\nNA,6\n3,7\n4,9.5\n"
+
+	slope, intercept, r2, err := FitSource(NewCSVSource(strings.NewReader(input)))
+	data, loadErr := LoadCSVDataset(strings.NewReader(input))
+	refSlope, refIntercept, refR2, _ := PerformLinearRegression(data.X, data.Y)
+	if err != nil || loadErr != nil || math.Abs(slope-refSlope) > 1e-12 || math.Abs(intercept-refIntercept) > 1e-12 || math.Abs(r2-refR2) > 1e-12 {
+		t.Errorf("streamed (%v %v %v %v) vs loaded (%v %v %v %v)", slope, intercept, r2, err, refSlope, refIntercept, refR2, loadErr)
+	}
+}
+
+// ✅ Test 2: Source errors and validation errors are reported
+func TestFitSourceErrors(t *testing.T) {
+	if _, _, _, err := FitSource(NewCSVSource(strings.NewReader("x,y\n1,2\nfoo,3\n"))); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected line 3 parse error, got %v", err)
+	}
+	if _, _, _, err := FitSource(NewSliceSource(Dataset{X: []float64{1}, Y: []float64{1}})); err == nil {
+		t.Error("expected need at least two data points error")
+	}
+	if _, _, _, err := FitSource(NewSliceSource(Dataset{X: []float64{1, math.NaN()}, Y: []float64{1, 2}})); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+}
+
+// ✅ Test 3: The incremental fitter consumes sources without materializing them
+func TestOnlineRegressionAddSource(t *testing.T) {
+	var acc OnlineRegression
+	if read := acc.AddSource(NewSliceSource(Dataset{X: []float64{1, 2, 3}, Y: []float64{2, 4, 6}})); read != 3 {
+		t.Errorf("expected 3 points read, got %d", read)
+	}
+	acc.AddSource(NewSliceSource(Dataset{X: []float64{4}, Y: []float64{8}}))
+	if s := acc.Snapshot(); s.N != 4 || math.Abs(s.Slope-2) > 1e-12 {
+		t.Errorf("unexpected snapshot %+v", s)
+	}
+
+	src := NewSliceSource(Dataset{X: []float64{1, 2}, Y: []float64{1, 2}})
+	if allocs := testing.AllocsPerRun(100, func() { src.i = 0; acc.AddSource(src) }); allocs != 0 {
+		t.Errorf("expected no allocations, got %.1f", allocs)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// defaultSpillChunk is the number of points whose moments make up one spilled record
+const defaultSpillChunk = 1 << 20
+
+// spillRecordSize is the size of one spilled chunk: the count and the five moments of an
+// OnlineRegression, little-endian
+const spillRecordSize = 6 * 8
+
+// SpillOptions configure FitSpilled. Dir holds the temporary file, os.TempDir() when empty;
+// Chunk is the number of points per spilled record, defaultSpillChunk when zero.
+type SpillOptions struct {
+	Dir   string
+	Chunk int
+}
+
+// SpillFit is the result of FitSpilled
+type SpillFit struct {
+	OnlineSnapshot
+	Read       int   `json:"read"`       // points read, including the NaN/Inf pairs left out of the fit
+	Chunks     int   `json:"chunks"`     // records spilled and merged
+	SpillBytes int64 `json:"spillBytes"` // size of the temporary file
+}
+
+// FitSpilled fits a streamed series too large for memory, such as a multi-gigabyte CSV file or a
+// pipe. Each chunk's means and co-moments are accumulated as the points stream by and written to
+// a temporary file, so memory stays constant however long the input is; the file is then read back
+// and the chunks merged in order (see OnlineRegression.Merge), which gives the same result as
+// ParallelRegression over the same chunking. The temporary file is removed before returning.
+// Source errors are returned as-is.
+func FitSpilled(src PointSource, opts SpillOptions) (SpillFit, error) {
+	chunk := opts.Chunk
+	if chunk <= 0 {
+		chunk = defaultSpillChunk
+	}
+	f, err := os.CreateTemp(opts.Dir, "module5-spill-*.bin")
+	if err != nil {
+		return SpillFit{}, fmt.Errorf("creating spill file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	var fit SpillFit
+	w := bufio.NewWriter(f)
+	var local OnlineRegression
+	inChunk := 0
+	for {
+		x, y, ok := src.Next()
+		if ok {
+			local.Add(x, y)
+			fit.Read++
+			inChunk++
+		}
+		if inChunk == chunk || !ok && inChunk > 0 {
+			if err := writeSpillRecord(w, local); err != nil {
+				return SpillFit{}, fmt.Errorf("spilling chunk %d: %w", fit.Chunks+1, err)
+			}
+			fit.Chunks++
+			local, inChunk = OnlineRegression{}, 0
+		}
+		if !ok {
+			break
+		}
+	}
+	if e, ok := src.(SourceError); ok && e.Err() != nil {
+		return SpillFit{}, e.Err()
+	}
+	if err := w.Flush(); err != nil {
+		return SpillFit{}, fmt.Errorf("spilling: %w", err)
+	}
+
+	// merge pass
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return SpillFit{}, err
+	}
+	acc, merged, err := mergeSpillRecords(bufio.NewReader(f))
+	if err != nil {
+		return SpillFit{}, fmt.Errorf("merging spilled chunks: %w", err)
+	}
+	if merged != fit.Chunks {
+		return SpillFit{}, fmt.Errorf("merging spilled chunks: read %d of %d", merged, fit.Chunks)
+	}
+	fit.SpillBytes = int64(fit.Chunks) * spillRecordSize
+
+	// a source has no separate X and Y to mismatch, only too few points
+	if err := checkPairLengths(fit.Read, fit.Read); err != nil {
+		return SpillFit{}, err
+	}
+	if err := checkValidPairs(acc.N()); err != nil {
+		return SpillFit{}, err
+	}
+	fit.OnlineSnapshot = acc.Snapshot()
+	return fit, nil
+}
+
+// writeSpillRecord writes the state of o as one record
+func writeSpillRecord(w io.Writer, o OnlineRegression) error {
+	var buf [spillRecordSize]byte
+	binary.LittleEndian.PutUint64(buf[0:], uint64(o.n))
+	for i, v := range []float64{o.meanX, o.meanY, o.cxx, o.cxy, o.cyy} {
+		binary.LittleEndian.PutUint64(buf[8*(i+1):], math.Float64bits(v))
+	}
+	_, err := w.Write(buf[:])
+	return err
+}
+
+// mergeSpillRecords reads records until the end of r and merges them in order
+func mergeSpillRecords(r io.Reader) (acc OnlineRegression, records int, err error) {
+	var buf [spillRecordSize]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); errors.Is(err, io.EOF) {
+			return acc, records, nil
+		} else if err != nil {
+			return OnlineRegression{}, records, err
+		}
+		var o OnlineRegression
+		o.n = int(binary.LittleEndian.Uint64(buf[0:]))
+		for i, v := range []*float64{&o.meanX, &o.meanY, &o.cxx, &o.cxy, &o.cyy} {
+			*v = math.Float64frombits(binary.LittleEndian.Uint64(buf[8*(i+1):]))
+		}
+		acc.Merge(o)
+		records++
+	}
+}
+
+// fitSpilledFile fits a CSV file, or standard input for "-", with FitSpilled and returns its axes
+func fitSpilledFile(path string, opts SpillOptions, l NumberLocale) (SpillFit, Dataset, error) {
+	in := os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return SpillFit{}, Dataset{}, err
+		}
+		defer f.Close()
+		in = f
+	}
+	src := NewCSVSourceWithLocale(bufio.NewReaderSize(in, 1<<20), l)
+	fit, err := FitSpilled(src, opts)
+	if err != nil {
+		return SpillFit{}, Dataset{}, fmt.Errorf("%s: %w", path, err)
+	}
+	var axes Dataset
+	if header := src.Header(); header != nil {
+		axes.XAxis, axes.YAxis = ParseAxis(header[0]), ParseAxis(header[1])
+	}
+	return fit, axes, nil
+}
+
+// printSpilledFits fits each CSV file with fitSpilledFile and prints the coefficients, written in
+// locale l as the files are
+func printSpilledFits(paths []string, opts SpillOptions, l NumberLocale) error {
+	fmt.Printf("=== Spilled Regression Analysis ===\n")
+	for _, path := range paths {
+		fit, axes, err := fitSpilledFile(path, opts, l)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\nDataset %s:\n", path)
+		if label := axes.AxesLabel(); label != "" {
+			fmt.Printf("  Axes:      %s\n", label)
+		}
+		fmt.Printf("  Slope:     %s\n", l.withUnit(fit.Slope, axes.SlopeUnit()))
+		fmt.Printf("  Intercept: %s\n", l.withUnit(fit.Intercept, axes.YAxis.Unit))
+		fmt.Printf("  R-squared: %s\n", l.FormatFloat(fit.RSquared, 6))
+		fmt.Printf("  Points:    %d used of %d read\n", fit.N, fit.Read)
+		fmt.Printf("  Spilled:   %d chunks, %d bytes\n", fit.Chunks, fit.SpillBytes)
+	}
+	return nil
+}
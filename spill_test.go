@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Spilled chunks merge to exactly the in-memory chunked fit and the spill file is removed
+func TestFitSpilled(t *testing.T) {
+	n := 10_000
+	x, y := make([]float64, n), make([]float64, n)
+	for i := range x {
+		x[i] = 1e6 + float64(i)/7
+		y[i] = 3 + 0.5*x[i] + math.Sin(float64(i))
+	}
+	y[17] = math.NaN()
+	dir := t.TempDir()
+
+	fit, err := FitSpilled(NewSliceSource(Dataset{X: x, Y: y}), SpillOptions{Dir: dir, Chunk: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := chunkedMoments(x, y, 1024, 4)
+	if fit.OnlineSnapshot != want.Snapshot() {
+		t.Errorf("expected %+v, got %+v", want.Snapshot(), fit.OnlineSnapshot)
+	}
+	if fit.Read != n || fit.N != n-1 || fit.Chunks != 10 || fit.SpillBytes != 10*spillRecordSize {
+		t.Errorf("unexpected counts %+v", fit)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the spill file removed, found %v", entries)
+	}
+}
+
+// ✅ Test 2: Streamed CSV errors, too few points and an unusable directory are reported
+func TestFitSpilledErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := FitSpilled(NewCSVSource(strings.NewReader("x,y\n1,2\n2,oops\n")), SpillOptions{Dir: dir}); err == nil || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("expected the CSV error, got %v", err)
+	}
+	if _, err := FitSpilled(NewCSVSource(strings.NewReader("1,2\nNA,3\n")), SpillOptions{Dir: dir}); err == nil || !strings.Contains(err.Error(), "have 1") {
+		t.Errorf("expected a too-few-points error, got %v", err)
+	}
+	if _, err := FitSpilled(NewSliceSource(Dataset{X: []float64{1, 2}, Y: []float64{1, 2}}), SpillOptions{Dir: dir + "/missing"}); err == nil {
+		t.Error("expected an error for a missing spill directory")
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected no spill files left after errors, found %v", entries)
+	}
+}
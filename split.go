@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// SplitOptions tunes SplitWithOptions
+type SplitOptions struct {
+	// StratifyBins > 1 divides X into that many equal-count bins and splits each bin separately,
+	// so train and test both cover the whole X range even for small datasets
+	StratifyBins int
+}
+
+// Split randomly assigns ratio of the points to train and the rest to test.
+// The assignment depends only on seed, and both parts keep the original point order.
+func Split(ds Dataset, ratio float64, seed uint64) (train, test Dataset, err error) {
+	return SplitWithOptions(ds, ratio, seed, SplitOptions{})
+}
+
+// SplitWithOptions is Split with optional stratification by X bins
+func SplitWithOptions(ds Dataset, ratio float64, seed uint64, opts SplitOptions) (train, test Dataset, err error) {
+	if len(ds.X) != len(ds.Y) {
+		return Dataset{}, Dataset{}, fmt.Errorf("x and y length mismatch: %d vs %d", len(ds.X), len(ds.Y))
+	}
+	if err := ds.validateMetadata(); err != nil {
+		return Dataset{}, Dataset{}, err
+	}
+	if !(ratio > 0 && ratio < 1) {
+		return Dataset{}, Dataset{}, fmt.Errorf("split ratio must be between 0 and 1, got %v", ratio)
+	}
+
+	rng := rand.New(rand.NewPCG(seed, 0))
+	inTrain := make([]bool, len(ds.X))
+
+	for _, group := range splitGroups(ds.X, opts.StratifyBins) {
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		for _, idx := range group[:int(math.Round(ratio*float64(len(group))))] {
+			inTrain[idx] = true
+		}
+	}
+
+	train.XAxis, train.YAxis = ds.XAxis, ds.YAxis
+	test.XAxis, test.YAxis = ds.XAxis, ds.YAxis
+	for i := range ds.X {
+		if inTrain[i] {
+			train.appendPoint(ds, i)
+		} else {
+			test.appendPoint(ds, i)
+		}
+	}
+	return train, test, nil
+}
+
+// splitGroups returns the point indexes to split independently: everything at once, or
+// bins of (nearly) equal size over the points ordered by X
+func splitGroups(x []float64, bins int) [][]int {
+	order := make([]int, len(x))
+	for i := range order {
+		order[i] = i
+	}
+	if bins <= 1 || len(x) == 0 {
+		return [][]int{order}
+	}
+
+	bins = min(bins, len(x))
+	sort.SliceStable(order, func(a, b int) bool { return x[order[a]] < x[order[b]] })
+
+	groups := make([][]int, 0, bins)
+	for b := 0; b < bins; b++ {
+		groups = append(groups, order[b*len(x)/bins:(b+1)*len(x)/bins])
+	}
+	return groups
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+)
+
+// ✅ Test 1: Split sizes follow the ratio, are reproducible and partition the data
+func TestSplit(t *testing.T) {
+	x, y := syntheticLine(1000)
+	ds := Dataset{X: x, Y: y}
+
+	train, test, err := Split(ds, 0.8, 42)
+	if err != nil || len(train.X) != 800 || len(test.X) != 200 {
+		t.Fatalf("got %d/%d points, err %v", len(train.X), len(test.X), err)
+	}
+
+	again, _, _ := Split(ds, 0.8, 42)
+	other, _, _ := Split(ds, 0.8, 43)
+	same, differs := true, false
+	for i := range train.X {
+		same = same && train.X[i] == again.X[i]
+		differs = differs || train.X[i] != other.X[i]
+	}
+	if !same || !differs {
+		t.Errorf("expected the split to depend on the seed only (same=%v differs=%v)", same, differs)
+	}
+
+	// syntheticLine X is increasing, so original order is preserved when both parts are increasing
+	for _, part := range []Dataset{train, test} {
+		for i := 1; i < len(part.X); i++ {
+			if part.X[i] < part.X[i-1] {
+				t.Fatal("split should keep the original point order")
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Stratified splits put test points in every X bin
+func TestSplitStratified(t *testing.T) {
+	ds := Dataset{}
+	for i := 0; i < 20; i++ {
+		ds.X = append(ds.X, float64(i))
+		ds.Y = append(ds.Y, float64(2*i))
+	}
+
+	_, test, err := SplitWithOptions(ds, 0.75, 7, SplitOptions{StratifyBins: 5})
+	if err != nil || len(test.X) != 5 {
+		t.Fatalf("expected 5 test points, got %d (err %v)", len(test.X), err)
+	}
+	for bin := 0; bin < 5; bin++ {
+		if x := test.X[bin]; x < float64(4*bin) || x >= float64(4*bin+4) {
+			t.Errorf("bin %d has no test point (test X %v)", bin, test.X)
+		}
+	}
+
+	for _, ratio := range []float64{0, 1, -0.5} {
+		if _, _, err := Split(ds, ratio, 1); err == nil {
+			t.Errorf("expected error for ratio %v", ratio)
+		}
+	}
+}
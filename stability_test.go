@@ -0,0 +1,193 @@
+package main
+
+import (
+	"math"
+	"math/rand/v2"
+	"os"
+	"testing"
+)
+
+// stabilityCase is an extreme dataset of the numerical stability suite
+type stabilityCase struct {
+	name string
+	x, y []float64
+}
+
+func stabilityCases() []stabilityCase {
+	const n = 2000
+	gen := func(name string, seed uint64, xAt func(u float64) float64, yAt func(x, noise float64) float64) stabilityCase {
+		rng := rand.New(rand.NewPCG(seed, 36))
+		c := stabilityCase{name: name, x: make([]float64, n), y: make([]float64, n)}
+		for i := range c.x {
+			c.x[i] = xAt(float64(i) / n)
+			c.y[i] = yAt(c.x[i], rng.NormFloat64())
+		}
+		return c
+	}
+	line := func(x, noise float64) float64 { return 3 + 0.5*x + noise }
+	return []stabilityCase{
+		gen("well", 1, func(u float64) float64 { return 10 * u }, line),
+		gen("offset 1e6", 2, func(u float64) float64 { return 1e6 + 10*u }, line),
+		gen("offset 1e9", 3, func(u float64) float64 { return 1e9 + 10*u }, line),
+		gen("huge 1e150", 4, func(u float64) float64 { return 1e150 * (1 + u) }, func(x, noise float64) float64 { return 0.5*x + 1e148*noise }),
+		gen("tiny 1e-150", 5, func(u float64) float64 { return 1e-150 * (1 + u) }, func(x, noise float64) float64 { return 0.5*x + 1e-152*noise }),
+		gen("tiny y variance", 6, func(u float64) float64 { return 10 * u }, func(x, noise float64) float64 { return 5 + 1e-9*x + 1e-10*noise }),
+		gen("near-constant x", 7, func(u float64) float64 { return 1 + 1e-7*u }, func(x, noise float64) float64 { return 3 + 0.5*x + 1e-9*noise }),
+	}
+}
+
+// breakdown marks a known failure of an engine on a case: the fit must run without panicking or
+// returning NaN, but its accuracy is not bounded
+var breakdown = math.Inf(1)
+
+// unsupported marks a case an engine rejects with an error, as float32 does for values beyond its range
+const unsupported = -1.0
+
+// stabilityBounds are the largest errors each engine may make on each case against
+// BigFloatRegression: relative in slope and intercept, absolute in R². They sit about two orders
+// of magnitude above the measured errors (go test -run TestStability -v logs them), so a change
+// that costs accuracy fails here. The breakdowns quantify the normal equations' weakness: raw sums
+// of squares lose every digit of the spread once the offset of X reaches 1e9 or its spread 1e-7.
+var stabilityBounds = map[string]map[string][3]float64{
+	"well": {
+		"stats": {1e-11, 1e-11, 1e-12}, "manual": {1e-13, 1e-13, 1e-13}, "compensated": {1e-13, 1e-13, 1e-13},
+		"fast": {1e-12, 1e-11, 1e-13}, "parallel": {1e-12, 1e-11, 1e-13}, "float32": {1e-6, 1e-6, 1e-6},
+	},
+	"offset 1e6": {
+		"stats": {1e-2, 1, 1e-12}, "manual": {1e-2, 1, 1e-6}, "compensated": {1e-13, 1e-12, 1e-13},
+		"fast": {1e-6, 1e-5, 1e-7}, "parallel": {1e-6, 1e-5, 1e-7}, "float32": {1e-1, breakdown, 1e-1},
+	},
+	"offset 1e9": {
+		"stats": {breakdown, breakdown, 1e-11}, "manual": {breakdown, breakdown, breakdown}, "compensated": {1e-12, 1e-10, 1e-13},
+		"fast": {1e-4, 1e-2, 1e-5}, "parallel": {1e-4, 1e-2, 1e-5}, "float32": {breakdown, breakdown, breakdown},
+	},
+	"huge 1e150": {
+		"stats": {1e-12, 1e-7, 1e-12}, "manual": {1e-12, 1e-7, 1e-13}, "compensated": {1e-13, 1e-9, 1e-13},
+		"fast": {1e-10, 1e-6, 1e-12}, "parallel": {1e-10, 1e-6, 1e-12}, "float32": {unsupported},
+	},
+	"tiny 1e-150": {
+		"stats": {1e-11, 1e-9, 1e-13}, "manual": {1e-11, 1e-9, 1e-13}, "compensated": {1e-13, 1e-11, 1e-13},
+		"fast": {1e-11, 1e-8, 1e-13}, "parallel": {1e-11, 1e-8, 1e-13}, "float32": {breakdown, breakdown, breakdown},
+	},
+	"tiny y variance": {
+		"stats": {1e-3, 1e-11, 1e-8}, "manual": {1e-4, 1e-13, 1e-8}, "compensated": {1e-13, 1e-13, 1e-11},
+		"fast": {1e-4, 1e-12, 1e-6}, "parallel": {1e-4, 1e-12, 1e-6}, "float32": {breakdown, 1e-10, breakdown},
+	},
+	"near-constant x": {
+		"stats": {breakdown, breakdown, 1e-11}, "manual": {breakdown, breakdown, breakdown}, "compensated": {1e-13, 1e-13, 1e-13},
+		"fast": {1e-4, 1e-5, 1e-7}, "parallel": {1e-4, 1e-5, 1e-7}, "float32": {breakdown, breakdown, breakdown},
+	},
+}
+
+// ✅ Test 1: Every engine stays within its measured error bounds on extreme inputs
+func TestStabilityBounds(t *testing.T) {
+	for _, c := range stabilityCases() {
+		refSlope, refIntercept, refR2, err := BigFloatRegression(c.x, c.y)
+		if err != nil {
+			t.Fatalf("%s: reference: %v", c.name, err)
+		}
+		for _, e := range Engines() {
+			if e.Name == "bigfloat" {
+				continue
+			}
+			bound, ok := stabilityBounds[c.name][e.Name]
+			if !ok {
+				t.Errorf("%s: no bounds for engine %s", c.name, e.Name)
+				continue
+			}
+			slope, intercept, r2, err := e.Fit(c.x, c.y)
+			if bound[0] == unsupported {
+				if err == nil {
+					t.Errorf("%s: engine %s should reject the input", c.name, e.Name)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: engine %s: %v", c.name, e.Name, err)
+				continue
+			}
+			errs := [3]float64{relErr(slope, refSlope), relErr(intercept, refIntercept), math.Abs(r2 - refR2)}
+			t.Logf("%-16s %-12s slope %.1e, intercept %.1e, R² %.1e", c.name, e.Name, errs[0], errs[1], errs[2])
+			for i, name := range []string{"slope", "intercept", "R²"} {
+				if math.IsNaN(errs[i]) || errs[i] > bound[i] {
+					t.Errorf("%s: engine %s %s error %.3g exceeds %.0e", c.name, e.Name, name, errs[i], bound[i])
+				}
+			}
+		}
+	}
+}
+
+// ✅ Test 2: Multiple regression recovers an exact plane from nearly collinear predictors
+func TestStabilityNearCollinear(t *testing.T) {
+	rng := rand.New(rand.NewPCG(8, 36))
+	md := MultiDataset{Names: []string{"x1", "x2"}}
+	for i := 0; i < 500; i++ {
+		x1 := rng.Float64() * 10
+		x2 := x1 + 1e-6*rng.NormFloat64() // correlation with x1 above 1 - 1e-13
+		md.X = append(md.X, []float64{x1, x2})
+		md.Y = append(md.Y, 1+2*x1+3*x2)
+	}
+	fit, err := FitMultiple(md)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the condition number is about 1e7, so each coefficient may lose about seven digits
+	for i, want := range []float64{1, 2, 3} {
+		if e := relErr(fit.Coefficients[i], want); e > 1e-6 {
+			t.Errorf("coefficient %d = %v, relative error %.3g", i, fit.Coefficients[i], e)
+		}
+	}
+	if fit.RSquared < 1-1e-12 {
+		t.Errorf("expected an exact fit, got R² %v", fit.RSquared)
+	}
+}
+
+// ✅ Test 3: Exact lines of 1e8 points are recovered by the streaming and chunked engines.
+// It holds 1.6 GB of data, so it only runs with STABILITY_LARGE=1.
+func TestStabilityHundredMillion(t *testing.T) {
+	if os.Getenv("STABILITY_LARGE") == "" || testing.Short() {
+		t.Skip("set STABILITY_LARGE=1 to fit 1e8-point datasets")
+	}
+	const n = 100_000_000
+	x, y := make([]float64, n), make([]float64, n)
+	for i := range x {
+		// integers and halves are exact in float64, so the true fit is known without big.Float
+		x[i] = float64(i)
+		y[i] = 3 + 0.5*x[i]
+	}
+	// the manual engines are called without the NaN filtering of Engine.Fit, which would copy both slices
+	manual := func(opts ManualOptions) func(x, y []float64) (float64, float64, float64, error) {
+		return func(x, y []float64) (float64, float64, float64, error) {
+			slope, intercept, r2 := ManualRegressionWithOptions(x, y, opts)
+			return slope, intercept, r2, nil
+		}
+	}
+	// intercept = ȳ - slope·x̄ cancels two terms of size |slope·x̄| ≈ 2.5e7, so its absolute error
+	// is measured in units of ε·|x̄|·|slope|; the slope error of the plain sums enters the same way
+	const meanX, slopeX = (n - 1) / 2.0, 0.5
+	interceptUnit := 0x1p-52 * meanX * slopeX
+	engines := []struct {
+		name      string
+		bound     float64
+		intercept float64 // bound on |intercept - 3| in units of interceptUnit
+		fit       func(x, y []float64) (float64, float64, float64, error)
+	}{
+		{"manual", 1e-6, 1e4, manual(ManualOptions{})},
+		{"compensated", 1e-12, 4, manual(ManualOptions{Compensated: true})},
+		{"fast", 1e-9, 4, FastRegression},
+		{"parallel", 1e-9, 4, ParallelRegression},
+	}
+	for _, e := range engines {
+		slope, intercept, r2, err := e.fit(x, y)
+		if err != nil {
+			t.Fatalf("engine %s: %v", e.name, err)
+		}
+		t.Logf("%-12s slope %.1e, intercept %.1e, R² %.1e", e.name, relErr(slope, 0.5), relErr(intercept, 3), math.Abs(r2-1))
+		if relErr(slope, slopeX) > e.bound || math.Abs(r2-1) > e.bound {
+			t.Errorf("engine %s: slope %v, R² %v beyond %.0e", e.name, slope, r2, e.bound)
+		}
+		if err := math.Abs(intercept - 3); err > e.intercept*interceptUnit {
+			t.Errorf("engine %s: intercept %v off by %.1e, beyond %.1e", e.name, intercept, err, e.intercept*interceptUnit)
+		}
+	}
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// STLOptions configures Decompose. Windows are LOESS spans in observations and are rounded up to
+// the next odd number.
+type STLOptions struct {
+	Period         int  // observations per seasonal cycle, at least 2
+	SeasonalWindow int  // span of the cycle-subseries smoother; 7 when 0
+	TrendWindow    int  // span of the trend smoother; derived from Period and SeasonalWindow when 0
+	Robust         bool // downweight outliers with bisquare weights over 15 outer passes
+}
+
+// Decomposition splits a series into Trend + Seasonal + Remainder. Remainder is NaN where the
+// input was missing.
+type Decomposition struct {
+	Period    int       `json:"period"`
+	Trend     []float64 `json:"trend"`
+	Seasonal  []float64 `json:"seasonal"`
+	Remainder []float64 `json:"remainder"`
+}
+
+// Decompose performs a seasonal-trend decomposition by LOESS (Cleveland et al., 1990) of evenly
+// spaced values, with the defaults of R's stl(). Missing values get zero weight, so the trend and
+// seasonal components are still estimated there.
+func Decompose(values []float64, opts STLOptions) (Decomposition, error) {
+	period := opts.Period
+	if period < 2 {
+		return Decomposition{}, fmt.Errorf("period must be at least 2, got %d", period)
+	}
+	n := len(values)
+	if len(finiteInOrder(values)) < 2*period {
+		return Decomposition{}, fmt.Errorf("need at least two full periods (%d finite values), have %d", 2*period, len(finiteInOrder(values)))
+	}
+	seasonalWindow := opts.SeasonalWindow
+	if seasonalWindow == 0 {
+		seasonalWindow = 7
+	}
+	if seasonalWindow < 3 {
+		return Decomposition{}, fmt.Errorf("seasonal window must be at least 3, got %d", seasonalWindow)
+	}
+	seasonalWindow = nextOdd(seasonalWindow)
+	trendWindow := opts.TrendWindow
+	if trendWindow == 0 {
+		trendWindow = int(math.Ceil(1.5 * float64(period) / (1 - 1.5/float64(seasonalWindow))))
+	}
+	if trendWindow < 3 {
+		return Decomposition{}, fmt.Errorf("trend window must be at least 3, got %d", trendWindow)
+	}
+	trendWindow = nextOdd(trendWindow)
+	lowPassWindow := nextOdd(period)
+
+	inner, outer := 2, 0
+	if opts.Robust {
+		inner, outer = 1, 15
+	}
+	base := make([]float64, n)
+	for i, v := range values {
+		if !isMissing(v) {
+			base[i] = 1
+		}
+	}
+	weights := append([]float64(nil), base...)
+
+	d := Decomposition{Period: period, Trend: make([]float64, n), Seasonal: make([]float64, n), Remainder: make([]float64, n)}
+	deseasonalized := make([]float64, n)
+	for pass := 0; pass <= outer; pass++ {
+		for k := 0; k < inner; k++ {
+			d.Seasonal = stlSeasonal(values, d.Trend, weights, period, seasonalWindow, lowPassWindow)
+			for i, v := range values {
+				deseasonalized[i] = v - d.Seasonal[i]
+			}
+			d.Trend = loessSmooth(deseasonalized, weights, trendWindow)
+		}
+		if pass < outer {
+			weights = bisquareWeights(values, d.Trend, d.Seasonal, base)
+		}
+	}
+	for i, v := range values {
+		d.Remainder[i] = v - d.Trend[i] - d.Seasonal[i]
+	}
+	return d, nil
+}
+
+// TrendStrength is max(0, 1 - Var(R)/Var(T+R)) (Wang, Smith & Hyndman): near 1 for a dominant trend
+func (d Decomposition) TrendStrength() float64 {
+	return componentStrength(d.Trend, d.Remainder)
+}
+
+// SeasonalStrength is max(0, 1 - Var(R)/Var(S+R)): near 1 for a dominant seasonal pattern
+func (d Decomposition) SeasonalStrength() float64 {
+	return componentStrength(d.Seasonal, d.Remainder)
+}
+
+// SeasonalRange returns the peak-to-trough size of the seasonal component
+func (d Decomposition) SeasonalRange() float64 {
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, s := range d.Seasonal {
+		lo, hi = math.Min(lo, s), math.Max(hi, s)
+	}
+	return hi - lo
+}
+
+func componentStrength(component, remainder []float64) float64 {
+	var sum, rest []float64
+	for i, r := range remainder {
+		if !isMissing(r) {
+			sum = append(sum, component[i]+r)
+			rest = append(rest, r)
+		}
+	}
+	_, varSum := meanVariance(sum)
+	_, varRest := meanVariance(rest)
+	if varSum == 0 {
+		return 0
+	}
+	return math.Max(0, 1-varRest/varSum)
+}
+
+// Decompose decomposes the series' values, which should be evenly spaced in time
+func (ts TimeSeriesDataset) Decompose(opts STLOptions) (Decomposition, error) {
+	return Decompose(ts.Y, opts)
+}
+
+// TrendSeries returns the series with its values replaced by the decomposition's trend, ready for
+// FitTimeSeries
+func (ts TimeSeriesDataset) TrendSeries(d Decomposition) (TimeSeriesDataset, error) {
+	if len(d.Trend) != len(ts.Times) {
+		return TimeSeriesDataset{}, fmt.Errorf("decomposition has %d points, series has %d", len(d.Trend), len(ts.Times))
+	}
+	return TimeSeriesDataset{Times: ts.Times, Y: append([]float64(nil), d.Trend...)}, nil
+}
+
+// stlSeasonal runs one pass of STL's seasonal step on values minus trend: LOESS on each
+// cycle-subseries, extended one cycle at both ends, minus a low-pass filter of the result
+func stlSeasonal(values, trend, weights []float64, period, seasonalWindow, lowPassWindow int) []float64 {
+	n := len(values)
+	cycle := make([]float64, n+2*period)
+	for s := 0; s < period; s++ {
+		m := (n - s + period - 1) / period
+		sub, subWeights := make([]float64, m), make([]float64, m)
+		for j := range sub {
+			sub[j] = values[s+j*period] - trend[s+j*period]
+			subWeights[j] = weights[s+j*period]
+		}
+		for j := -1; j <= m; j++ {
+			v, ok := loessAt(sub, subWeights, seasonalWindow, float64(j))
+			if !ok {
+				// no weighted neighbours: assume no seasonal effect
+				v = 0
+			}
+			cycle[s+(j+1)*period] = v
+		}
+	}
+
+	lowPass := movingAverage(movingAverage(movingAverage(cycle, period), period), 3)
+	ones := make([]float64, n)
+	for i := range ones {
+		ones[i] = 1
+	}
+	lowPass = loessSmooth(lowPass, ones, lowPassWindow)
+
+	seasonal := make([]float64, n)
+	for i := range seasonal {
+		seasonal[i] = cycle[period+i] - lowPass[i]
+	}
+	return seasonal
+}
+
+// loessSmooth evaluates loessAt at every position, keeping the input where no neighbour has weight
+func loessSmooth(values, weights []float64, span int) []float64 {
+	out := make([]float64, len(values))
+	for i := range values {
+		v, ok := loessAt(values, weights, span, float64(i))
+		if !ok {
+			v = values[i]
+		}
+		out[i] = v
+	}
+	return out
+}
+
+// loessAt fits a weighted local line through the span values nearest position x (the values sit at
+// positions 0, 1, ...) with tricube distance weights, and returns its value at x. As in STL, a span
+// longer than the series widens the neighbourhood beyond its ends.
+func loessAt(values, weights []float64, span int, x float64) (float64, bool) {
+	n := len(values)
+	q := min(span, n)
+	lo := max(0, min(n-q, int(math.Round(x))-q/2))
+	hi := lo + q - 1
+	h := math.Max(x-float64(lo), float64(hi)-x)
+	if span > n {
+		h += float64(span-n) / 2
+	}
+
+	var sumW, sumX, sumY float64
+	w := make([]float64, q)
+	for j := lo; j <= hi; j++ {
+		if weights[j] == 0 {
+			continue
+		}
+		u := 0.0
+		if h > 0 {
+			u = math.Abs(float64(j)-x) / h
+		}
+		if u >= 1 {
+			continue
+		}
+		t := 1 - u*u*u
+		w[j-lo] = weights[j] * t * t * t
+		sumW += w[j-lo]
+		sumX += w[j-lo] * float64(j)
+		sumY += w[j-lo] * values[j]
+	}
+	if sumW == 0 {
+		return 0, false
+	}
+	meanX, meanY := sumX/sumW, sumY/sumW
+	var sxx, sxy float64
+	for j := lo; j <= hi; j++ {
+		if w[j-lo] > 0 {
+			dx := float64(j) - meanX
+			sxx += w[j-lo] * dx * dx
+			sxy += w[j-lo] * dx * values[j]
+		}
+	}
+	// fall back to the local mean when the weighted positions are too concentrated for a slope
+	if r := 0.001 * float64(n-1); sxx <= r*r {
+		return meanY, true
+	}
+	return meanY + sxy/sxx*(x-meanX), true
+}
+
+// movingAverage returns the len(values)-window+1 complete trailing means
+func movingAverage(values []float64, window int) []float64 {
+	out := make([]float64, len(values)-window+1)
+	var sum float64
+	for i, v := range values {
+		sum += v
+		if i >= window {
+			sum -= values[i-window]
+		}
+		if i >= window-1 {
+			out[i-window+1] = sum / float64(window)
+		}
+	}
+	return out
+}
+
+// bisquareWeights are STL's robustness weights, B(|r| / 6 median|r|) for the remainder r, times the
+// base weights that zero out missing values
+func bisquareWeights(values, trend, seasonal, base []float64) []float64 {
+	abs := make([]float64, 0, len(values))
+	for i, v := range values {
+		if base[i] > 0 {
+			abs = append(abs, math.Abs(v-trend[i]-seasonal[i]))
+		}
+	}
+	sorted := append([]float64(nil), abs...)
+	sort.Float64s(sorted)
+	h := 6 * quantileSorted(sorted, 0.5)
+
+	weights := make([]float64, len(values))
+	k := 0
+	for i := range values {
+		if base[i] == 0 {
+			continue
+		}
+		u := 0.0
+		if h > 0 {
+			u = abs[k] / h
+		}
+		k++
+		if u < 1 {
+			weights[i] = (1 - u*u) * (1 - u*u)
+		}
+	}
+	return weights
+}
+
+// nextOdd rounds n up to an odd number
+func nextOdd(n int) int {
+	if n%2 == 0 {
+		return n + 1
+	}
+	return n
+}
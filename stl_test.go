@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// ✅ Test 1: A line plus a fixed seasonal pattern decomposes into those two parts
+func TestDecomposeLinearSeasonal(t *testing.T) {
+	pattern := []float64{3, 1, -2, -4, -1, 3}
+	values := make([]float64, 60)
+	for i := range values {
+		values[i] = 10 + 0.5*float64(i) + pattern[i%len(pattern)]
+	}
+
+	d, err := Decompose(values, STLOptions{Period: len(pattern)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 6; i < len(values)-6; i++ {
+		if math.Abs(d.Trend[i]-(10+0.5*float64(i))) > 0.05 {
+			t.Fatalf("trend[%d] = %v, want %v", i, d.Trend[i], 10+0.5*float64(i))
+		}
+		if math.Abs(d.Seasonal[i]-pattern[i%len(pattern)]) > 0.05 {
+			t.Fatalf("seasonal[%d] = %v, want %v", i, d.Seasonal[i], pattern[i%len(pattern)])
+		}
+		if math.Abs(d.Trend[i]+d.Seasonal[i]+d.Remainder[i]-values[i]) > 1e-9 {
+			t.Fatalf("components at %d do not add up", i)
+		}
+	}
+	if d.SeasonalStrength() < 0.99 || d.TrendStrength() < 0.99 {
+		t.Errorf("expected strong components, got seasonal %v, trend %v", d.SeasonalStrength(), d.TrendStrength())
+	}
+	if math.Abs(d.SeasonalRange()-7) > 0.1 {
+		t.Errorf("seasonal range %v, want about 7", d.SeasonalRange())
+	}
+}
+
+// ✅ Test 2: Robust decomposition confines a spike and a missing value to the remainder
+func TestDecomposeRobustAndMissing(t *testing.T) {
+	values := make([]float64, 48)
+	for i := range values {
+		values[i] = float64(i) + 5*math.Sin(2*math.Pi*float64(i)/12)
+	}
+	values[20] += 100
+	values[30] = math.NaN()
+
+	d, err := Decompose(values, STLOptions{Period: 12, Robust: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Remainder[20] < 90 {
+		t.Errorf("expected the spike in the remainder, got %v", d.Remainder[20])
+	}
+	if !math.IsNaN(d.Remainder[30]) || math.IsNaN(d.Trend[30]) || math.Abs(d.Trend[30]-30) > 1 {
+		t.Errorf("missing value: remainder %v, trend %v", d.Remainder[30], d.Trend[30])
+	}
+	if math.Abs(d.Trend[24]-24) > 1 {
+		t.Errorf("trend near the spike pulled to %v", d.Trend[24])
+	}
+}
+
+// ✅ Test 3: Invalid periods and short series are rejected
+func TestDecomposeErrors(t *testing.T) {
+	if _, err := Decompose([]float64{1, 2, 3, 4}, STLOptions{Period: 1}); err == nil {
+		t.Error("expected an error for period 1")
+	}
+	if _, err := Decompose([]float64{1, 2, 3, 4, 5}, STLOptions{Period: 3}); err == nil {
+		t.Error("expected an error for fewer than two periods")
+	}
+}
+
+// ✅ Test 4: Fitting the trend series removes the seasonal pattern from the slope
+func TestTrendSeriesFit(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := TimeSeriesDataset{}
+	for i := 0; i < 30; i++ {
+		ts.Times = append(ts.Times, start.Add(time.Duration(i)*24*time.Hour))
+		// weekly cycle that peaks at the end of the series biases a raw fit
+		ts.Y = append(ts.Y, 2*float64(i)+10*math.Cos(2*math.Pi*float64(i)/7))
+	}
+	d, err := ts.Decompose(STLOptions{Period: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trend, err := ts.TrendSeries(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fit, err := FitTimeSeries(trend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(fit.SlopePer(24*time.Hour)-2) > 0.05 {
+		t.Errorf("trend slope %v per day, want 2", fit.SlopePer(24*time.Hour))
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// TelemetryReading is the JSON payload expected on telemetry topics.
+// When Device is empty the MQTT topic identifies the device.
+type TelemetryReading struct {
+	Device string  `json:"device"`
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+}
+
+// SlopeDriftAlert is raised when a slope has stayed outside the tolerated band long enough.
+// Since is when the excursion began.
+type SlopeDriftAlert struct {
+	Device   string    `json:"device"`
+	Slope    float64   `json:"slope"`
+	Baseline float64   `json:"baseline"`
+	Drift    float64   `json:"drift"`
+	RSquared float64   `json:"rSquared"`
+	Points   int       `json:"points"`
+	Time     time.Time `json:"time"`
+	Since    time.Time `json:"since"`
+}
+
+// TelemetryMonitor maintains a rolling-window regression (see RollingRegression) per device and
+// follows its slope with a SlopeDriftMonitor. The first full window fixes the device's baseline
+// slope; an alert is raised when the slope leaves the band configured in Drift, and re-armed once
+// it comes back.
+type TelemetryMonitor struct {
+	Window int
+	Drift  DriftOptions
+
+	devices map[string]*deviceWindow
+}
+
+type deviceWindow struct {
+	rolling *RollingRegression
+	SlopeDriftMonitor
+	fit RegressionResult
+}
+
+// NewTelemetryMonitor creates a monitor fitting the last window readings of every device and
+// alerting as soon as a slope moves further than driftThreshold from its baseline
+func NewTelemetryMonitor(window int, driftThreshold float64) *TelemetryMonitor {
+	return &TelemetryMonitor{Window: window, Drift: DriftOptions{Threshold: driftThreshold}, devices: map[string]*deviceWindow{}}
+}
+
+// Observe adds a reading and returns an alert when the device's slope starts drifting
+func (m *TelemetryMonitor) Observe(r TelemetryReading) *SlopeDriftAlert {
+	if math.IsNaN(r.X) || math.IsInf(r.X, 0) || math.IsNaN(r.Y) || math.IsInf(r.Y, 0) {
+		return nil
+	}
+
+	w, ok := m.devices[r.Device]
+	if !ok {
+		w = &deviceWindow{rolling: NewRollingRegression(m.Window, 0), SlopeDriftMonitor: SlopeDriftMonitor{opts: m.Drift}}
+		m.devices[r.Device] = w
+	}
+
+	fit := w.rolling.Add(time.Now(), r.X, r.Y)
+	if fit.N < m.Window {
+		return nil
+	}
+	w.fit = RegressionResult{Dataset: r.Device, Slope: fit.Slope, Intercept: fit.Intercept, RSquared: fit.RSquared}
+
+	alert := w.SlopeDriftMonitor.Observe(fit)
+	if alert != nil {
+		alert.Device = r.Device
+	}
+	return alert
+}
+
+// SlopeHistory returns the device's recent slopes, as many as Drift.History keeps
+func (m *TelemetryMonitor) SlopeHistory(device string) []SlopeSample {
+	w, ok := m.devices[device]
+	if !ok {
+		return nil
+	}
+	return w.History()
+}
+
+// Latest returns a copy of the device's current window and its fit, once the window has filled
+func (m *TelemetryMonitor) Latest(device string) (Dataset, RegressionResult, bool) {
+	w, ok := m.devices[device]
+	if !ok || w.rolling.Len() < m.Window {
+		return Dataset{}, RegressionResult{}, false
+	}
+	return w.rolling.Points(), w.fit, true
+}
+
+// registerDeviceModel saves a device's current fit as a new version in the registry, tagging the
+// first one as the device's baseline
+func registerDeviceModel(registry *ModelRegistry, data Dataset, fit RegressionResult, baseline bool) error {
+	version, err := registry.Save(NewLinearModel(fit, data))
+	if err != nil {
+		return err
+	}
+	if baseline {
+		return registry.Tag(fit.Dataset, version, "baseline")
+	}
+	return nil
+}
+
+// runMQTT implements the `mqtt` subcommand: subscribe to telemetry and publish slope-drift alerts
+func runMQTT(args []string) error {
+	flags := flag.NewFlagSet("mqtt", flag.ContinueOnError)
+	broker := flags.String("broker", "localhost:1883", "MQTT broker address")
+	topic := flags.String("topic", "sensors/#", "topic filter carrying telemetry readings")
+	alertTopic := flags.String("alert-topic", "regression/alerts", "topic slope-drift alerts are published to (empty to disable)")
+	clientID := flags.String("client-id", "regression-monitor", "MQTT client identifier")
+	username := flags.String("username", "", "MQTT username")
+	password := flags.String("password", os.Getenv("MQTT_PASSWORD"), "MQTT password (defaults to $MQTT_PASSWORD)")
+	window := flags.Int("window", 50, "number of readings per device in the rolling window")
+	drift := flags.Float64("drift", 0.1, "absolute slope change from the baseline that raises an alert")
+	band := flags.String("drift-band", "", "alert when the slope leaves this fixed LO:HI band instead of the band around the baseline")
+	sustain := flags.Duration("drift-sustain", 0, "how long the slope must stay out of the band before alerting")
+	sustainPoints := flags.Int("drift-sustain-points", 0, "how many consecutive windows must be out of the band before alerting")
+	anomalyK := flags.Float64("anomaly-k", 0, "flag readings whose residual from the device's running fit exceeds this many sigmas (0 disables)")
+	anomalyMin := flags.Int("anomaly-min-points", 10, "readings per device before anomalies are flagged, with -anomaly-k")
+	anomalyTopic := flags.String("anomaly-topic", "regression/anomalies", "topic anomaly events are published to (empty to only print them)")
+	thresholds := DefaultAlertThresholds()
+	alertWebhook := flags.String("alert-webhook", "", "webhook (or Slack incoming webhook) URL notified when a device fit breaches the alert thresholds")
+	flags.Float64Var(&thresholds.MinRSquared, "alert-min-r2", thresholds.MinRSquared, "alert when a device's R-squared drops below this value")
+	flags.Float64Var(&thresholds.SlopeMin, "alert-slope-min", thresholds.SlopeMin, "alert when a device's slope falls below this value")
+	flags.Float64Var(&thresholds.SlopeMax, "alert-slope-max", thresholds.SlopeMax, "alert when a device's slope rises above this value")
+	registryDir := flags.String("registry", "", "register each device's fitted line in this model registry when its first window fills (tagged baseline) and on every drift alert")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *window < 3 {
+		return fmt.Errorf("window must be at least 3, got %d", *window)
+	}
+	driftOpts := DriftOptions{Threshold: *drift, Sustain: *sustain, SustainPoints: *sustainPoints}
+	if *band != "" {
+		var err error
+		if driftOpts.SlopeMin, driftOpts.SlopeMax, err = ParseSlopeBand(*band); err != nil {
+			return err
+		}
+	}
+	if _, err := NewSlopeDriftMonitor(driftOpts); err != nil {
+		return err
+	}
+	var anomalies *AnomalyDetector
+	if *anomalyK != 0 {
+		var err error
+		if anomalies, err = NewAnomalyDetector(*anomalyK, *anomalyMin); err != nil {
+			return err
+		}
+	}
+	var registry *ModelRegistry
+	registered := map[string]bool{}
+	if *registryDir != "" {
+		var err error
+		if registry, err = OpenModelRegistry(*registryDir); err != nil {
+			return err
+		}
+	}
+
+	client, err := DialMQTT(*broker, MQTTOptions{ClientID: *clientID, Username: *username, Password: *password, KeepAlive: 30 * time.Second})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Subscribe(*topic); err != nil {
+		return err
+	}
+	fmt.Printf("Subscribed to %s on %s (window %d, drift threshold %.4f)\n", *topic, *broker, *window, *drift)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		client.Close()
+	}()
+
+	monitor := NewTelemetryMonitor(*window, *drift)
+	monitor.Drift = driftOpts
+	var hook *AlertHook
+	if *alertWebhook != "" {
+		hook = NewAlertHook(thresholds, NewWebhookNotifier(*alertWebhook))
+	}
+
+	for {
+		msg, err := client.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var reading TelemetryReading
+		if err := json.Unmarshal(msg.Payload, &reading); err != nil {
+			log.Printf("Skipping malformed reading on %s: %v", msg.Topic, err)
+			continue
+		}
+		if reading.Device == "" {
+			reading.Device = msg.Topic
+		}
+
+		if anomalies != nil {
+			if event := anomalies.Observe(reading); event != nil {
+				fmt.Printf("Anomaly on %s: y %.6f at x %.6f, expected %.6f (%.1f sigma)\n", event.Device, event.Y, event.X, event.Predicted, event.Score)
+				if *anomalyTopic != "" {
+					payload, _ := json.Marshal(event)
+					if err := client.Publish(*anomalyTopic, payload); err != nil {
+						return fmt.Errorf("publishing anomaly: %w", err)
+					}
+				}
+			}
+		}
+
+		alert := monitor.Observe(reading)
+		if registry != nil && (alert != nil || !registered[reading.Device]) {
+			if data, fit, ok := monitor.Latest(reading.Device); ok {
+				if err := registerDeviceModel(registry, data, fit, !registered[reading.Device]); err != nil {
+					log.Printf("Registering model of %s failed: %v", reading.Device, err)
+				}
+				registered[reading.Device] = true
+			}
+		}
+		if hook != nil {
+			if data, fit, ok := monitor.Latest(reading.Device); ok {
+				if err := hook.Evaluate(ctx, reading.Device, data, fit); err != nil {
+					log.Printf("Alert delivery failed for %s: %v", reading.Device, err)
+				}
+			}
+		}
+		if alert == nil {
+			continue
+		}
+
+		fmt.Printf("Slope drift on %s: slope %.6f vs baseline %.6f (drift %+.6f, out of band since %s)\n",
+			alert.Device, alert.Slope, alert.Baseline, alert.Drift, alert.Since.Format(time.RFC3339))
+		if *alertTopic != "" {
+			payload, _ := json.Marshal(alert)
+			if err := client.Publish(*alertTopic, payload); err != nil {
+				return fmt.Errorf("publishing alert: %w", err)
+			}
+		}
+	}
+}
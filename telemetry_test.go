@@ -0,0 +1,47 @@
+package main
+
+import (
+	"testing"
+)
+
+// ✅ Test 1: Alerts fire once when the slope leaves the band and re-arm when it returns
+func TestTelemetryMonitorDrift(t *testing.T) {
+	monitor := NewTelemetryMonitor(10, 0.5)
+	feed := func(slope float64, from, to int) int {
+		alerts := 0
+		for i := from; i < to; i++ {
+			if monitor.Observe(TelemetryReading{Device: "d1", X: float64(i), Y: slope * float64(i)}) != nil {
+				alerts++
+			}
+		}
+		return alerts
+	}
+
+	if n := feed(1, 0, 30); n != 0 {
+		t.Errorf("stable slope raised %d alerts", n)
+	}
+	if n := feed(3, 30, 60); n != 1 {
+		t.Errorf("expected exactly one alert for sustained drift, got %d", n)
+	}
+	if n := feed(1, 60, 100); n != 0 {
+		t.Errorf("returning to the baseline raised %d alerts", n)
+	}
+	if n := feed(3, 100, 130); n != 1 {
+		t.Errorf("expected the re-armed monitor to alert again, got %d", n)
+	}
+}
+
+// ✅ Test 2: Devices are tracked independently and invalid readings are ignored
+func TestTelemetryMonitorDevices(t *testing.T) {
+	monitor := NewTelemetryMonitor(5, 0.1)
+	for i := 0; i < 10; i++ {
+		monitor.Observe(TelemetryReading{Device: "a", X: float64(i), Y: float64(i)})
+		monitor.Observe(TelemetryReading{Device: "b", X: float64(i), Y: -float64(i)})
+	}
+	if len(monitor.devices) != 2 {
+		t.Fatalf("expected 2 devices, got %d", len(monitor.devices))
+	}
+	if monitor.devices["a"].baseline != 1 || monitor.devices["b"].baseline != -1 {
+		t.Errorf("unexpected baselines: a=%v b=%v", monitor.devices["a"].baseline, monitor.devices["b"].baseline)
+	}
+}
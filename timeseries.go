@@ -0,0 +1,311 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeSeriesDataset is a series whose X values are instants
+type TimeSeriesDataset struct {
+	Times []time.Time
+	Y     []float64
+	YAxis Axis // from the header row, when there is one
+}
+
+// ToDataset converts the times to numeric offsets from origin, measured in unit
+func (ts TimeSeriesDataset) ToDataset(origin time.Time, unit time.Duration) Dataset {
+	data := Dataset{X: make([]float64, len(ts.Times)), Y: append([]float64(nil), ts.Y...)}
+	for i, t := range ts.Times {
+		data.X[i] = float64(t.Sub(origin)) / float64(unit)
+	}
+	return data
+}
+
+// Origin returns the earliest time in the series, the default zero point for fitting
+func (ts TimeSeriesDataset) Origin() time.Time {
+	var origin time.Time
+	for i, t := range ts.Times {
+		if i == 0 || t.Before(origin) {
+			origin = t
+		}
+	}
+	return origin
+}
+
+// TimeSeriesFit is a linear trend over time. Intercept is the fitted value at Origin and
+// Slope is the change per Unit; use SlopePer to express it per any other duration.
+type TimeSeriesFit struct {
+	Origin    time.Time     `json:"origin"`
+	Unit      time.Duration `json:"unit"`
+	Slope     float64       `json:"slope"`
+	Intercept float64       `json:"intercept"`
+	RSquared  float64       `json:"rSquared"`
+}
+
+// SlopePer returns the fitted change over duration d, e.g. SlopePer(24*time.Hour) for a daily trend
+func (f TimeSeriesFit) SlopePer(d time.Duration) float64 {
+	return f.Slope * float64(d) / float64(f.Unit)
+}
+
+// Predict evaluates the trend at t
+func (f TimeSeriesFit) Predict(t time.Time) float64 {
+	return f.Intercept + f.Slope*float64(t.Sub(f.Origin))/float64(f.Unit)
+}
+
+// FitTimeSeries fits Y against time measured in hours from the earliest point. Measuring from the
+// series rather than the Unix epoch keeps X small, avoiding the precision loss of large offsets.
+func FitTimeSeries(ts TimeSeriesDataset) (TimeSeriesFit, error) {
+	if len(ts.Times) != len(ts.Y) {
+		return TimeSeriesFit{}, fmt.Errorf("time and y length mismatch: %d vs %d", len(ts.Times), len(ts.Y))
+	}
+	fit := TimeSeriesFit{Origin: ts.Origin(), Unit: time.Hour}
+	data := ts.ToDataset(fit.Origin, fit.Unit)
+
+	var err error
+	fit.Slope, fit.Intercept, fit.RSquared, err = FastRegression(data.X, data.Y)
+	if err != nil {
+		return TimeSeriesFit{}, err
+	}
+	return fit, nil
+}
+
+// timeLayouts are tried in order when parsing timestamps. Layouts without a zone are read in the
+// loader's location.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006-01-02",
+}
+
+// TimeFormat says how a CSV file writes its timestamps. Layouts are Go reference layouts, such as
+// "02.01.2006 15:04", or "unix" and "unixms" for seconds and milliseconds since the epoch; when
+// given, only they are tried, in order, so a day-first file is never read month-first. Without
+// Layouts, the ISO-like timeLayouts and Unix seconds are accepted. Timestamps without an explicit
+// offset are read in Location, UTC when nil.
+type TimeFormat struct {
+	Location *time.Location
+	Layouts  []string
+}
+
+// ParseTimestamp parses an RFC 3339 or ISO-like timestamp, or Unix seconds. Timestamps without an
+// explicit offset are interpreted in loc (UTC when nil).
+func ParseTimestamp(field string, loc *time.Location) (time.Time, error) {
+	return TimeFormat{Location: loc}.Parse(field)
+}
+
+// Parse parses a timestamp written in the format
+func (f TimeFormat) Parse(field string) (time.Time, error) {
+	field = strings.TrimSpace(field)
+	loc := f.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	layouts := f.Layouts
+	if len(layouts) == 0 {
+		layouts = append(timeLayouts[:len(timeLayouts):len(timeLayouts)], "unix")
+	}
+	for _, layout := range layouts {
+		switch layout {
+		case "unix", "unixms":
+			v, err := strconv.ParseFloat(field, 64)
+			if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			if layout == "unixms" {
+				v /= 1000
+			}
+			whole, frac := math.Modf(v)
+			return time.Unix(int64(whole), int64(frac*1e9)).In(loc), nil
+		default:
+			if t, err := time.ParseInLocation(layout, field, loc); err == nil {
+				return t, nil
+			}
+		}
+	}
+	if len(f.Layouts) > 0 {
+		return time.Time{}, fmt.Errorf("timestamp %q matches none of the layouts %q", field, f.Layouts)
+	}
+	return time.Time{}, fmt.Errorf("unrecognized timestamp %q", field)
+}
+
+// LoadTimeSeriesCSV reads timestamp,y pairs from the first two columns of a CSV stream, skipping a
+// header row. Timestamps without an offset are interpreted in loc. Rows are sorted by time.
+func LoadTimeSeriesCSV(r io.Reader, loc *time.Location) (TimeSeriesDataset, error) {
+	return LoadTimeSeriesCSVWithFormat(r, TimeFormat{Location: loc})
+}
+
+// LoadTimeSeriesCSVWithFormat is LoadTimeSeriesCSV for timestamps written in format f. The header
+// row's second column becomes the series' YAxis.
+func LoadTimeSeriesCSVWithFormat(r io.Reader, f TimeFormat) (TimeSeriesDataset, error) {
+	return LoadTimeSeriesCSVWithLocale(r, f, NumberLocale{})
+}
+
+// LoadTimeSeriesCSVWithLocale is LoadTimeSeriesCSVWithFormat for a stream whose values are written
+// in locale l, with its field separator. Timestamps are parsed by f alone.
+func LoadTimeSeriesCSVWithLocale(r io.Reader, f TimeFormat, l NumberLocale) (TimeSeriesDataset, error) {
+	reader := l.newCSVReader(r)
+
+	var ts TimeSeriesDataset
+	line := 0
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return TimeSeriesDataset{}, fmt.Errorf("reading CSV: %w", err)
+		}
+		line++
+		if len(record) < 2 {
+			return TimeSeriesDataset{}, fmt.Errorf("line %d: expected at least 2 columns, got %d", line, len(record))
+		}
+
+		t, errT := f.Parse(record[0])
+		y, errY := l.ParseFloat(record[1])
+		if errT != nil || errY != nil {
+			if line == 1 {
+				// header row
+				ts.YAxis = ParseAxis(record[1])
+				continue
+			}
+			if errT != nil {
+				return TimeSeriesDataset{}, fmt.Errorf("line %d: %w", line, errT)
+			}
+			return TimeSeriesDataset{}, fmt.Errorf("line %d: invalid value in %q", line, strings.Join(record[:2], ","))
+		}
+		ts.Times = append(ts.Times, t)
+		ts.Y = append(ts.Y, y)
+	}
+	if len(ts.Times) == 0 {
+		return TimeSeriesDataset{}, fmt.Errorf("no data rows found")
+	}
+
+	sort.Stable(byTime(ts))
+	return ts, nil
+}
+
+// byTime sorts a series chronologically
+type byTime TimeSeriesDataset
+
+func (b byTime) Len() int           { return len(b.Times) }
+func (b byTime) Less(i, j int) bool { return b.Times[i].Before(b.Times[j]) }
+func (b byTime) Swap(i, j int) {
+	b.Times[i], b.Times[j] = b.Times[j], b.Times[i]
+	b.Y[i], b.Y[j] = b.Y[j], b.Y[i]
+}
+
+// LoadTimeSeriesFile opens path and loads it with LoadTimeSeriesCSV
+func LoadTimeSeriesFile(path string, loc *time.Location) (TimeSeriesDataset, error) {
+	return LoadTimeSeriesFileWithFormat(path, TimeFormat{Location: loc})
+}
+
+// LoadTimeSeriesFileWithFormat opens path and loads it with LoadTimeSeriesCSVWithFormat
+func LoadTimeSeriesFileWithFormat(path string, format TimeFormat) (TimeSeriesDataset, error) {
+	return LoadTimeSeriesFileWithLocale(path, format, NumberLocale{})
+}
+
+// LoadTimeSeriesFileWithLocale opens path and loads it with LoadTimeSeriesCSVWithLocale
+func LoadTimeSeriesFileWithLocale(path string, format TimeFormat, l NumberLocale) (TimeSeriesDataset, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return TimeSeriesDataset{}, err
+	}
+	defer f.Close()
+
+	ts, err := LoadTimeSeriesCSVWithLocale(f, format, l)
+	if err != nil {
+		return TimeSeriesDataset{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return ts, nil
+}
+
+// timeSeriesReportOptions configures printTimeSeriesReport. A positive Period adds a seasonal-trend
+// decomposition; FitTrend then fits the line to its trend instead of the raw values. Slopes are
+// reported per Unit, or per each series' AutoTimeUnit when Unit is zero. Files are read, and the
+// fitted values written, in Locale; headings are in the language of Messages.
+type timeSeriesReportOptions struct {
+	Format   TimeFormat
+	Locale   NumberLocale
+	Messages Messages
+	Unit     TimeUnit
+	Period   int
+	FitTrend bool
+}
+
+// printTimeSeriesReport fits each file as a time series and prints its trend per time unit
+func printTimeSeriesReport(paths []string, opts timeSeriesReportOptions) error {
+	fmt.Printf("=== %s ===\n", opts.Messages.Text("Time Series Regression Analysis"))
+	for _, path := range paths {
+		ts, err := LoadTimeSeriesFileWithLocale(path, opts.Format, opts.Locale)
+		if err != nil {
+			return err
+		}
+		var decomposition *Decomposition
+		fitted := ts
+		if opts.Period > 0 {
+			d, err := ts.Decompose(STLOptions{Period: opts.Period})
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			decomposition = &d
+			if opts.FitTrend {
+				if fitted, err = ts.TrendSeries(d); err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+			}
+		}
+		fit, err := FitTimeSeries(fitted)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+
+		unit, chosen := opts.Unit, ""
+		if unit.Duration == 0 {
+			unit = AutoTimeUnit(ts.Span())
+			chosen = fmt.Sprintf(" (the series spans %.4g %ss)", float64(ts.Span())/float64(unit.Duration), unit.Name)
+		}
+
+		fmt.Printf("\n%s\n", opts.Messages.Sprintf("Dataset %s:", path))
+		if ts.YAxis != (Axis{}) {
+			fmt.Printf("  Y:         %s\n", ts.YAxis)
+		}
+		fmt.Printf("  From:      %s\n", fit.Origin.Format(time.RFC3339))
+		fmt.Printf("  To:        %s\n", ts.Times[len(ts.Times)-1].Format(time.RFC3339))
+		numbers := opts.Locale
+		fmt.Printf("  Slope:     %s per %s%s\n", numbers.withUnit(fit.SlopePer(unit.Duration), ts.YAxis.Unit), unit.Name, chosen)
+		fmt.Printf("  Intercept: %s at %s\n", numbers.withUnit(fit.Intercept, ts.YAxis.Unit), fit.Origin.Format(time.RFC3339))
+		fmt.Printf("  R-squared: %s\n", numbers.FormatFloat(fit.RSquared, 6))
+		if decomposition != nil {
+			fmt.Printf("  Seasonal:  period %d, range %.6f, strength %.4f (trend strength %.4f)\n", decomposition.Period,
+				decomposition.SeasonalRange(), decomposition.SeasonalStrength(), decomposition.TrendStrength())
+			if opts.FitTrend {
+				fmt.Printf("  Fitted:    seasonal-trend decomposition trend\n")
+			}
+		}
+		// Holt's trend is per observation; convert it with the average spacing between them
+		step := ts.Times[len(ts.Times)-1].Sub(ts.Times[0]) / time.Duration(max(len(ts.Times)-1, 1))
+		if holt, err := FitHolt(ts.Y); err == nil && step > 0 {
+			perUnit := (holt.Forecast(1) - holt.Forecast(0)) * float64(unit.Duration) / float64(step)
+			fmt.Printf("  Holt:      %s per %s at the end of the series (alpha %.2f, beta %.2f)\n", numbers.withUnit(perUnit, ts.YAxis.Unit), unit.Name, holt.Alpha, holt.Beta)
+		}
+
+		residuals := make([]float64, len(ts.Y))
+		for i, t := range ts.Times {
+			residuals[i] = ts.Y[i] - fit.Predict(t)
+		}
+		fmt.Printf("  Autocorrelation:\n")
+		printAutocorrelation(os.Stdout, "Y", ts.Y)
+		printAutocorrelation(os.Stdout, "Residuals", residuals)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ✅ Test 1: Timestamps are parsed with zones, sorted and fitted per hour
+func TestLoadAndFitTimeSeries(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	input := "time,value\n2024-03-02 00:00,12\n2024-03-01T00:00:00+01:00,10\n2024-03-03,14\n"
+	ts, err := LoadTimeSeriesCSV(strings.NewReader(input), paris)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ts.Times[0].Equal(time.Date(2024, 2, 29, 23, 0, 0, 0, time.UTC)) || ts.Y[0] != 10 {
+		t.Errorf("expected rows sorted and zone-aware, got %v %v", ts.Times, ts.Y)
+	}
+
+	fit, err := FitTimeSeries(ts)
+	if err != nil || math.Abs(fit.SlopePer(24*time.Hour)-2) > 1e-9 || math.Abs(fit.SlopePer(time.Hour)-2.0/24) > 1e-12 {
+		t.Errorf("unexpected fit %+v (err %v)", fit, err)
+	}
+	if v := fit.Predict(ts.Times[2]); math.Abs(v-14) > 1e-9 {
+		t.Errorf("Predict at the last point = %v, want 14", v)
+	}
+}
+
+// ✅ Test 2: Supported timestamp formats
+func TestParseTimestamp(t *testing.T) {
+	want := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for _, field := range []string{"2024-01-02T03:04:05Z", "2024-01-02T03:04:05", "2024-01-02 03:04:05", "1704164645"} {
+		got, err := ParseTimestamp(field, nil)
+		if err != nil || !got.Equal(want) {
+			t.Errorf("%q: got %v (err %v)", field, got, err)
+		}
+	}
+	if _, err := ParseTimestamp("yesterday", nil); err == nil {
+		t.Error("expected error for unrecognized timestamp")
+	}
+}
+
+// ✅ Test 3: Large epoch offsets do not lose precision because X is measured from the series start
+func TestTimeSeriesToDataset(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	ts := TimeSeriesDataset{}
+	for i := 0; i < 5; i++ {
+		ts.Times = append(ts.Times, start.Add(time.Duration(i)*time.Millisecond))
+		ts.Y = append(ts.Y, float64(i))
+	}
+	data := ts.ToDataset(ts.Origin(), time.Millisecond)
+	for i, x := range data.X {
+		if x != float64(i) {
+			t.Errorf("x[%d] = %v, want %d", i, x, i)
+		}
+	}
+}
+
+// ✅ Test 4: Configured layouts are tried in order, exclusively, and fail with the line number
+func TestLoadTimeSeriesWithFormat(t *testing.T) {
+	format := TimeFormat{Layouts: []string{"02.01.2006 15:04", "02.01.2006"}}
+	input := "Datum,Verbrauch (kWh)\n02.03.2024 12:00,12\n01.03.2024,10\n"
+	ts, err := LoadTimeSeriesCSVWithFormat(strings.NewReader(input), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ts.Times[0].Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !ts.Times[1].Equal(time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected day-first dates, got %v", ts.Times)
+	}
+	if ts.YAxis != (Axis{Label: "Verbrauch", Unit: "kWh"}) {
+		t.Errorf("expected the header's Y axis, got %+v", ts.YAxis)
+	}
+
+	// with layouts, the ISO defaults are not tried
+	_, err = LoadTimeSeriesCSVWithFormat(strings.NewReader("t,y\n01.03.2024,1\n2024-03-02,2\n"), format)
+	if err == nil || !strings.Contains(err.Error(), "line 3") || !strings.Contains(err.Error(), "2024-03-02") {
+		t.Errorf("expected an error naming the line and timestamp, got %v", err)
+	}
+
+	for layout, field := range map[string]string{"unix": "1704164645", "unixms": "1704164645000"} {
+		got, err := TimeFormat{Layouts: []string{layout}}.Parse(field)
+		if err != nil || !got.Equal(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)) {
+			t.Errorf("%s %q: got %v (err %v)", layout, field, got, err)
+		}
+	}
+}
+
+// ✅ Test 5: Series written in a locale are read with its separator and decimal mark
+func TestLoadTimeSeriesWithLocale(t *testing.T) {
+	de, _ := ParseNumberLocale("de")
+	format := TimeFormat{Layouts: []string{"02.01.2006"}}
+	input := "Datum;Verbrauch (kWh)\n01.03.2024;1.234,5\n02.03.2024;1.236,5\n"
+	ts, err := LoadTimeSeriesCSVWithLocale(strings.NewReader(input), format, de)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(ts.Y) != 2 || ts.Y[0] != 1234.5 || ts.Y[1] != 1236.5 {
+		t.Errorf("expected German decimals, got %v", ts.Y)
+	}
+	fit, err := FitTimeSeries(ts)
+	if err != nil || math.Abs(fit.SlopePer(24*time.Hour)-2) > 1e-9 {
+		t.Errorf("expected a daily slope of 2, got %v (err %v)", fit.SlopePer(24*time.Hour), err)
+	}
+
+	// the default locale rejects the same file rather than misreading it
+	if _, err := LoadTimeSeriesCSVWithFormat(strings.NewReader(input), format); err == nil {
+		t.Error("expected the default locale to reject a ';'-separated file")
+	}
+}
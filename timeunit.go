@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TimeUnit is a duration a time series slope is reported per, such as "day"
+type TimeUnit struct {
+	Name     string
+	Duration time.Duration
+}
+
+// timeUnits are the units accepted by ParseTimeUnit, shortest first. A year is the average
+// Gregorian year, so yearly slopes do not depend on leap days.
+var timeUnits = []TimeUnit{
+	{"second", time.Second},
+	{"minute", time.Minute},
+	{"hour", time.Hour},
+	{"day", 24 * time.Hour},
+	{"week", 7 * 24 * time.Hour},
+	{"year", time.Duration(365.2425 * 24 * float64(time.Hour))},
+}
+
+// timeUnitAbbreviations are the short names ParseTimeUnit accepts as well
+var timeUnitAbbreviations = map[string]string{
+	"s": "second", "sec": "second", "min": "minute", "h": "hour", "hr": "hour",
+	"d": "day", "w": "week", "wk": "week", "y": "year", "yr": "year",
+}
+
+// timeUnitNames lists the unit names for usage messages
+func timeUnitNames() string {
+	names := make([]string, len(timeUnits))
+	for i, u := range timeUnits {
+		names[i] = u.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// ParseTimeUnit returns a unit by name, abbreviation or plural, such as "day", "d" or "days".
+// The empty name and "auto" return the zero TimeUnit, which AutoTimeUnit replaces.
+func ParseTimeUnit(name string) (TimeUnit, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		return TimeUnit{}, nil
+	}
+	if full, ok := timeUnitAbbreviations[name]; ok {
+		name = full
+	}
+	for _, u := range timeUnits {
+		if name == u.Name || name == u.Name+"s" {
+			return u, nil
+		}
+	}
+	return TimeUnit{}, fmt.Errorf("unknown time unit %q (use auto, %s)", name, timeUnitNames())
+}
+
+// AutoTimeUnit picks the longest unit the span covers at least once, so a slope is reported
+// per hour over an afternoon, per day over a few days and per week over a few months.
+// Spans shorter than a second are reported per second.
+func AutoTimeUnit(span time.Duration) TimeUnit {
+	unit := timeUnits[0]
+	for _, u := range timeUnits {
+		if span >= u.Duration {
+			unit = u
+		}
+	}
+	return unit
+}
+
+// Span returns the time between the earliest and latest points
+func (ts TimeSeriesDataset) Span() time.Duration {
+	if len(ts.Times) == 0 {
+		return 0
+	}
+	latest := ts.Times[0]
+	for _, t := range ts.Times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest.Sub(ts.Origin())
+}
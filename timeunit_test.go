@@ -0,0 +1,65 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// ✅ Test 1: Units are found by name, abbreviation and plural, and auto is the zero unit
+func TestParseTimeUnit(t *testing.T) {
+	for _, name := range []string{"day", "Days", "d"} {
+		if u, err := ParseTimeUnit(name); err != nil || u.Name != "day" || u.Duration != 24*time.Hour {
+			t.Errorf("%q: got %+v (err %v)", name, u, err)
+		}
+	}
+	if u, err := ParseTimeUnit("w"); err != nil || u.Duration != 7*24*time.Hour {
+		t.Errorf("expected a week, got %+v (err %v)", u, err)
+	}
+	for _, name := range []string{"", "auto"} {
+		if u, err := ParseTimeUnit(name); err != nil || u != (TimeUnit{}) {
+			t.Errorf("%q: expected the zero unit, got %+v (err %v)", name, u, err)
+		}
+	}
+	if _, err := ParseTimeUnit("fortnight"); err == nil {
+		t.Error("expected an unknown unit error")
+	}
+}
+
+// ✅ Test 2: The automatic unit is the longest one the series spans
+func TestAutoTimeUnit(t *testing.T) {
+	cases := map[time.Duration]string{
+		0:                    "second",
+		90 * time.Second:     "minute",
+		3 * time.Hour:        "hour",
+		6 * 24 * time.Hour:   "day",
+		90 * 24 * time.Hour:  "week",
+		800 * 24 * time.Hour: "year",
+	}
+	for span, want := range cases {
+		if got := AutoTimeUnit(span); got.Name != want {
+			t.Errorf("span %v: expected per %s, got per %s", span, want, got.Name)
+		}
+	}
+}
+
+// ✅ Test 3: A daily rise is reported per week over a month of data
+func TestTimeSeriesSlopeUnit(t *testing.T) {
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	var ts TimeSeriesDataset
+	for day := 0; day <= 30; day += 3 {
+		ts.Times = append(ts.Times, start.AddDate(0, 0, day))
+		ts.Y = append(ts.Y, 5+0.5*float64(day))
+	}
+	if ts.Span() != 30*24*time.Hour {
+		t.Fatalf("unexpected span %v", ts.Span())
+	}
+	fit, err := FitTimeSeries(ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unit := AutoTimeUnit(ts.Span())
+	if unit.Name != "week" || math.Abs(fit.SlopePer(unit.Duration)-3.5) > 1e-9 {
+		t.Errorf("expected 3.5 per week, got %v per %s", fit.SlopePer(unit.Duration), unit.Name)
+	}
+}
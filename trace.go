@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// FitTrace holds the intermediate quantities of a least-squares fit by the textbook formulas,
+// for checking a fit by hand
+type FitTrace struct {
+	N                               int
+	SumX, SumY, SumXX, SumXY, SumYY float64
+	MeanX, MeanY                    float64
+	SSxx, SSxy, SST                 float64
+	Slope, Intercept                float64
+	SSE, SSR, RSquared              float64
+	Points                          []TracePoint
+}
+
+// TracePoint is one complete pair with its deviations from the means, fitted value and residual
+type TracePoint struct {
+	X, Y, DX, DY, Fitted, Residual float64
+}
+
+// TraceFit computes every step of the least-squares fit of y on x. Pairs with NaN or Inf are
+// dropped first, as PerformLinearRegression does.
+func TraceFit(x, y []float64) (FitTrace, error) {
+	cx, cy, err := cleanPairs(nil, nil, x, y)
+	if err != nil {
+		return FitTrace{}, err
+	}
+	t := FitTrace{N: len(cx)}
+	for i := range cx {
+		t.SumX += cx[i]
+		t.SumY += cy[i]
+		t.SumXX += cx[i] * cx[i]
+		t.SumXY += cx[i] * cy[i]
+		t.SumYY += cy[i] * cy[i]
+	}
+	n := float64(t.N)
+	t.MeanX, t.MeanY = t.SumX/n, t.SumY/n
+	t.SSxx = t.SumXX - t.SumX*t.SumX/n
+	t.SSxy = t.SumXY - t.SumX*t.SumY/n
+	t.SST = t.SumYY - t.SumY*t.SumY/n
+	if t.SSxx == 0 {
+		return FitTrace{}, fmt.Errorf("all X values are equal (SSxx = 0), so the slope is undefined")
+	}
+	t.Slope = t.SSxy / t.SSxx
+	t.Intercept = t.MeanY - t.Slope*t.MeanX
+
+	t.Points = make([]TracePoint, len(cx))
+	for i := range cx {
+		fitted := t.Intercept + t.Slope*cx[i]
+		t.Points[i] = TracePoint{X: cx[i], Y: cy[i], DX: cx[i] - t.MeanX, DY: cy[i] - t.MeanY, Fitted: fitted, Residual: cy[i] - fitted}
+		t.SSE += t.Points[i].Residual * t.Points[i].Residual
+	}
+	t.SSR = t.SST - t.SSE
+	t.RSquared = rSquaredFrom(t.SST, t.SSE)
+	return t, nil
+}
+
+// tracePointLimit is the most points whose row-by-row table PrintFitTrace shows
+const tracePointLimit = 25
+
+// PrintFitTrace writes the trace as a worked calculation, with a row per point for small datasets
+func PrintFitTrace(w io.Writer, t FitTrace) {
+	fmt.Fprintf(w, "  Trace:\n")
+	if len(t.Points) <= tracePointLimit {
+		fmt.Fprintf(w, "    %10s %10s %10s %10s %10s %10s\n", "x", "y", "x - mean", "y - mean", "fitted", "residual")
+		for _, p := range t.Points {
+			fmt.Fprintf(w, "    %10.4f %10.4f %10.4f %10.4f %10.4f %10.4f\n", p.X, p.Y, p.DX, p.DY, p.Fitted, p.Residual)
+		}
+	}
+	fmt.Fprintf(w, "    n   = %d\n", t.N)
+	fmt.Fprintf(w, "    Σx  = %.6f   Σy  = %.6f\n", t.SumX, t.SumY)
+	fmt.Fprintf(w, "    Σx² = %.6f   Σxy = %.6f   Σy² = %.6f\n", t.SumXX, t.SumXY, t.SumYY)
+	fmt.Fprintf(w, "    x̄   = Σx / n = %.6f   ȳ = Σy / n = %.6f\n", t.MeanX, t.MeanY)
+	fmt.Fprintf(w, "    SSxx = Σx² - (Σx)²/n = %.6f\n", t.SSxx)
+	fmt.Fprintf(w, "    SSxy = Σxy - Σx·Σy/n = %.6f\n", t.SSxy)
+	fmt.Fprintf(w, "    SST  = Σy² - (Σy)²/n = %.6f\n", t.SST)
+	fmt.Fprintf(w, "    slope     b1 = SSxy / SSxx = %.6f\n", t.Slope)
+	fmt.Fprintf(w, "    intercept b0 = ȳ - b1·x̄ = %.6f\n", t.Intercept)
+	fmt.Fprintf(w, "    SSE = Σ(y - ŷ)² = %.6f\n", t.SSE)
+	fmt.Fprintf(w, "    SSR = SST - SSE = %.6f\n", t.SSR)
+	fmt.Fprintf(w, "    R²  = 1 - SSE / SST = %.6f\n", t.RSquared)
+}
+
+// printTrace writes the trace section of a dataset report and flags a reported fit that the
+// formulas do not reproduce, as after smoothing or imputation
+func printTrace(w io.Writer, data Dataset, result RegressionResult) {
+	if data.Weights != nil {
+		fmt.Fprintf(w, "  Trace:     n/a (weighted fit)\n")
+		return
+	}
+	t, err := TraceFit(data.X, data.Y)
+	if err != nil {
+		fmt.Fprintf(w, "  Trace:     n/a (%v)\n", err)
+		return
+	}
+	PrintFitTrace(w, t)
+	const tol = 1e-9
+	if math.Abs(t.Slope-result.Slope) > tol*math.Max(1, math.Abs(t.Slope)) || math.Abs(t.Intercept-result.Intercept) > tol*math.Max(1, math.Abs(t.Intercept)) {
+		fmt.Fprintf(w, "    note: the reported fit (slope %.6f, intercept %.6f) was computed from transformed data\n", result.Slope, result.Intercept)
+	}
+}
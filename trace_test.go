@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Hand-checkable sums and sums of squares for a small dataset
+func TestTraceFit(t *testing.T) {
+	// y = 1 + 2x with residuals +1, -1, -1, +1 around x = 1..4
+	tr, err := TraceFit([]float64{1, 2, 3, 4, math.NaN()}, []float64{4, 4, 6, 10, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.N != 4 || tr.SumX != 10 || tr.SumY != 24 || tr.SumXX != 30 || tr.SumXY != 70 || tr.SumYY != 168 {
+		t.Fatalf("unexpected sums %+v", tr)
+	}
+	checks := []struct {
+		name      string
+		got, want float64
+	}{
+		{"SSxx", tr.SSxx, 5}, {"SSxy", tr.SSxy, 10}, {"SST", tr.SST, 24},
+		{"slope", tr.Slope, 2}, {"intercept", tr.Intercept, 1},
+		{"SSE", tr.SSE, 4}, {"SSR", tr.SSR, 20}, {"R-squared", tr.RSquared, 20.0 / 24},
+	}
+	for _, c := range checks {
+		if math.Abs(c.got-c.want) > 1e-12 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if p := tr.Points[1]; p.DX != -0.5 || p.Fitted != 5 || p.Residual != -1 {
+		t.Errorf("unexpected second point %+v", p)
+	}
+
+	if _, err := TraceFit([]float64{2, 2, 2}, []float64{1, 2, 3}); err == nil {
+		t.Error("expected an error when all X values are equal")
+	}
+}
+
+// ✅ Test 2: The trace agrees with the reported fit of every Anscombe dataset
+func TestTraceMatchesAnscombe(t *testing.T) {
+	for name, ds := range LoadAnscombeDatasets() {
+		result, err := AnalyzeDataset(name, ds)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		printTrace(&buf, ds, result)
+		out := buf.String()
+		if strings.Contains(out, "note:") || strings.Count(out, "\n") != 2+11+12 {
+			t.Errorf("dataset %s: unexpected trace\n%s", name, out)
+		}
+		if !strings.Contains(out, "SSxx = Σx² - (Σx)²/n = 110.000000") {
+			t.Errorf("dataset %s: expected SSxx = 110\n%s", name, out)
+		}
+	}
+}
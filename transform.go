@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ScaleMethod selects a reversible affine transform applied to a variable before fitting
+type ScaleMethod string
+
+const (
+	ScaleNone   ScaleMethod = ""
+	ScaleZScore ScaleMethod = "zscore" // (v - mean) / standard deviation
+	ScaleMinMax ScaleMethod = "minmax" // (v - min) / (max - min), mapping onto [0, 1]
+	ScaleCenter ScaleMethod = "center" // v - mean
+)
+
+// Scaler is a fitted affine transform: scaled = (v - Offset) / Scale
+type Scaler struct {
+	Method ScaleMethod `json:"method"`
+	Offset float64     `json:"offset"`
+	Scale  float64     `json:"scale"`
+}
+
+// FitScaler estimates the transform parameters from the finite values
+func FitScaler(method ScaleMethod, values []float64) (Scaler, error) {
+	s := Scaler{Method: method, Scale: 1}
+	if method == ScaleNone {
+		return s, nil
+	}
+
+	var n, mean, m2 float64
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			continue
+		}
+		n++
+		d := v - mean
+		mean += d / n
+		m2 += d * (v - mean)
+		lo, hi = math.Min(lo, v), math.Max(hi, v)
+	}
+	if n == 0 {
+		return Scaler{}, fmt.Errorf("no finite values to fit the %s transform", method)
+	}
+
+	switch method {
+	case ScaleZScore:
+		s.Offset = mean
+		if n > 1 {
+			s.Scale = math.Sqrt(m2 / (n - 1))
+		}
+	case ScaleMinMax:
+		s.Offset, s.Scale = lo, hi-lo
+	case ScaleCenter:
+		s.Offset = mean
+	default:
+		return Scaler{}, fmt.Errorf("unknown scale method %q", method)
+	}
+	if s.Scale == 0 {
+		// a constant variable cannot be rescaled; only shift it
+		s.Scale = 1
+	}
+	return s, nil
+}
+
+// Apply maps v to the scaled space
+func (s Scaler) Apply(v float64) float64 {
+	return (v - s.Offset) / s.Scale
+}
+
+// Invert maps a scaled value back to the original space
+func (s Scaler) Invert(v float64) float64 {
+	return v*s.Scale + s.Offset
+}
+
+// ApplyAll returns the scaled copy of values
+func (s Scaler) ApplyAll(values []float64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = s.Apply(v)
+	}
+	return out
+}
+
+// TransformOptions selects the transforms applied to X and Y before fitting
+type TransformOptions struct {
+	X ScaleMethod
+	Y ScaleMethod
+}
+
+// TransformedFit is a fit computed on transformed data. Slope and Intercept are back-transformed to
+// the original scale; ScaledSlope and ScaledIntercept are the coefficients in the transformed space
+// (with z-scores on both variables ScaledSlope is the standardized coefficient). R² is unchanged by
+// affine transforms and is reported once.
+type TransformedFit struct {
+	Slope           float64 `json:"slope"`
+	Intercept       float64 `json:"intercept"`
+	RSquared        float64 `json:"rSquared"`
+	ScaledSlope     float64 `json:"scaledSlope"`
+	ScaledIntercept float64 `json:"scaledIntercept"`
+	X               Scaler  `json:"x"`
+	Y               Scaler  `json:"y"`
+}
+
+// FitTransformed transforms X and/or Y, fits with FastRegression and back-transforms the coefficients.
+// With y' = (y - oy)/sy and x' = (x - ox)/sx, a fit y' = a + b·x' becomes
+// y = (oy + sy·a - sy·b·ox/sx) + (sy·b/sx)·x.
+func FitTransformed(ds Dataset, opts TransformOptions) (TransformedFit, error) {
+	sx, err := FitScaler(opts.X, ds.X)
+	if err != nil {
+		return TransformedFit{}, fmt.Errorf("x: %w", err)
+	}
+	sy, err := FitScaler(opts.Y, ds.Y)
+	if err != nil {
+		return TransformedFit{}, fmt.Errorf("y: %w", err)
+	}
+
+	b, a, rSquared, err := FastRegression(sx.ApplyAll(ds.X), sy.ApplyAll(ds.Y))
+	if err != nil {
+		return TransformedFit{}, err
+	}
+
+	slope := sy.Scale * b / sx.Scale
+	return TransformedFit{
+		Slope:           slope,
+		Intercept:       sy.Offset + sy.Scale*a - slope*sx.Offset,
+		RSquared:        rSquared,
+		ScaledSlope:     b,
+		ScaledIntercept: a,
+		X:               sx,
+		Y:               sy,
+	}, nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Scalers are reversible and produce the documented ranges
+func TestScalers(t *testing.T) {
+	values := []float64{2, 4, 4, 4, 5, 5, 7, 9, math.NaN()}
+
+	z, _ := FitScaler(ScaleZScore, values)
+	if z.Offset != 5 || math.Abs(z.Scale-math.Sqrt(32.0/7)) > 1e-12 {
+		t.Errorf("unexpected z-score scaler %+v", z)
+	}
+	m, _ := FitScaler(ScaleMinMax, values)
+	if m.Apply(2) != 0 || m.Apply(9) != 1 {
+		t.Errorf("min-max should map onto [0,1], got %v..%v", m.Apply(2), m.Apply(9))
+	}
+	for _, s := range []Scaler{z, m} {
+		if v := s.Invert(s.Apply(7)); math.Abs(v-7) > 1e-12 {
+			t.Errorf("%s not reversible: %v", s.Method, v)
+		}
+	}
+
+	if c, _ := FitScaler(ScaleZScore, []float64{3, 3, 3}); c.Scale != 1 || c.Apply(3) != 0 {
+		t.Errorf("constant values should only be shifted, got %+v", c)
+	}
+	if _, err := FitScaler("log", values); err == nil {
+		t.Error("expected unknown method error")
+	}
+}
+
+// ✅ Test 2: Coefficients are back-transformed to the original scale
+func TestFitTransformed(t *testing.T) {
+	data := LoadAnscombeDatasets()["I"]
+	refSlope, refIntercept, refR2, _ := FastRegression(data.X, data.Y)
+
+	for _, opts := range []TransformOptions{{}, {X: ScaleZScore, Y: ScaleZScore}, {X: ScaleMinMax}, {Y: ScaleCenter}, {X: ScaleCenter, Y: ScaleMinMax}} {
+		fit, err := FitTransformed(data, opts)
+		if err != nil || math.Abs(fit.Slope-refSlope) > 1e-12 || math.Abs(fit.Intercept-refIntercept) > 1e-12 || math.Abs(fit.RSquared-refR2) > 1e-12 {
+			t.Errorf("%+v: got %+v (err %v), want %v %v %v", opts, fit, err, refSlope, refIntercept, refR2)
+		}
+	}
+
+	// with z-scores on both sides the slope is the correlation coefficient
+	fit, _ := FitTransformed(data, TransformOptions{X: ScaleZScore, Y: ScaleZScore})
+	if math.Abs(fit.ScaledSlope*fit.ScaledSlope-refR2) > 1e-12 || math.Abs(fit.ScaledIntercept) > 1e-12 {
+		t.Errorf("standardized coefficients %v %v", fit.ScaledSlope, fit.ScaledIntercept)
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// TrendPoint is one recorded run's fit of a named dataset. When Compared is set, the change fields
+// compare it with the previous run in which the dataset was fitted.
+type TrendPoint struct {
+	Run         int64     `json:"run"`
+	Started     time.Time `json:"started"`
+	Fingerprint string    `json:"fingerprint,omitempty"`
+	Engine      string    `json:"engine,omitempty"`
+	Slope       float64   `json:"slope"`
+	Intercept   float64   `json:"intercept"`
+	RSquared    float64   `json:"rSquared"`
+	Error       string    `json:"error,omitempty"`
+
+	Compared       bool    `json:"compared"`
+	SlopeChange    float64 `json:"slopeChange"`    // relative to the previous slope
+	RSquaredChange float64 `json:"rSquaredChange"` // absolute
+	DataChanged    bool    `json:"dataChanged"`
+	EngineChanged  bool    `json:"engineChanged"`
+	// Shifted is set when the slope or R² moved beyond the thresholds
+	Shifted bool `json:"shifted"`
+}
+
+// TrendThresholds decide when a fit has shifted from the previous run: when the slope moves by
+// more than SlopeRel of its previous value, or R² by more than RSquaredAbs
+type TrendThresholds struct {
+	SlopeRel    float64
+	RSquaredAbs float64
+}
+
+// DefaultTrendThresholds() flag a 5% change of slope or a change of 0.02 in R²
+func DefaultTrendThresholds() TrendThresholds {
+	return TrendThresholds{SlopeRel: 0.05, RSquaredAbs: 0.02}
+}
+
+// Series returns every recorded fit of the named dataset, oldest run first
+func (s *HistoryStore) Series(name string) ([]TrendPoint, error) {
+	rows, err := s.db.Query("SELECT runs.id, runs.started, fits.record FROM fits JOIN runs ON runs.id = fits.run_id WHERE fits.dataset = ? ORDER BY runs.id, fits.position", name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var points []TrendPoint
+	for rows.Next() {
+		var p TrendPoint
+		var started, record string
+		var d DatasetRecord
+		if err := rows.Scan(&p.Run, &started, &record); err != nil {
+			return nil, err
+		}
+		if p.Started, err = time.Parse(time.RFC3339Nano, started); err != nil {
+			return nil, fmt.Errorf("run %d: %w", p.Run, err)
+		}
+		if err := json.Unmarshal([]byte(record), &d); err != nil {
+			return nil, fmt.Errorf("run %d: %w", p.Run, err)
+		}
+		p.Fingerprint, p.Engine, p.Error = d.Fingerprint, d.Engine, d.Error
+		p.Slope, p.Intercept, p.RSquared = d.Slope, d.Intercept, d.RSquared
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// DetectTrendChanges compares each fit with the previous successful one and sets the change
+// fields. Failed fits are skipped, so the fit after a failure is compared with the one before it.
+func DetectTrendChanges(points []TrendPoint, th TrendThresholds) {
+	prev := -1
+	for i := range points {
+		p := &points[i]
+		if p.Error != "" {
+			continue
+		}
+		if prev >= 0 {
+			q := points[prev]
+			p.Compared = true
+			switch {
+			case p.Slope == q.Slope:
+			case q.Slope == 0:
+				p.SlopeChange = math.Copysign(math.Inf(1), p.Slope)
+			default:
+				p.SlopeChange = (p.Slope - q.Slope) / math.Abs(q.Slope)
+			}
+			p.RSquaredChange = p.RSquared - q.RSquared
+			p.DataChanged = p.Fingerprint != q.Fingerprint
+			p.EngineChanged = p.Engine != q.Engine
+			p.Shifted = math.Abs(p.SlopeChange) > th.SlopeRel || math.Abs(p.RSquaredChange) > th.RSquaredAbs
+		}
+		prev = i
+	}
+}
+
+// trendNote explains what changed since the previous fit
+func trendNote(p TrendPoint) string {
+	var notes []string
+	if p.Shifted {
+		notes = append(notes, "SHIFT")
+	}
+	if p.DataChanged {
+		notes = append(notes, "data changed")
+	}
+	if p.EngineChanged {
+		notes = append(notes, "engine changed")
+	}
+	return strings.Join(notes, ", ")
+}
+
+// printTrend writes a dataset's fits across runs, one row per run
+func printTrend(w io.Writer, name string, points []TrendPoint) {
+	shifts := 0
+	for _, p := range points {
+		if p.Shifted {
+			shifts++
+		}
+	}
+	fmt.Fprintf(w, "Dataset %s: %d runs, %d shifts\n", name, len(points), shifts)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Run\tStarted\tSlope\tChange\tR²\tChange\tNote\t")
+	for _, p := range points {
+		started := p.Started.Local().Format(time.RFC3339)
+		if p.Error != "" {
+			fmt.Fprintf(tw, "%d\t%s\terror: %s\t\t\t\t\t\n", p.Run, started, p.Error)
+			continue
+		}
+		slopeChange, r2Change := "", ""
+		if p.Compared {
+			slopeChange, r2Change = fmt.Sprintf("%+.2f%%", 100*p.SlopeChange), fmt.Sprintf("%+.4f", p.RSquaredChange)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%.6f\t%s\t%.6f\t%s\t%s\t\n", p.Run, started, p.Slope, slopeChange, p.RSquared, r2Change, trendNote(p))
+	}
+	tw.Flush()
+}
+
+// WriteTrendHTML writes a self-contained page plotting a dataset's slope and R² across runs, with
+// the fits that shifted beyond th in red, above the table of printTrend
+func WriteTrendHTML(w io.Writer, name string, points []TrendPoint, th TrendThresholds) error {
+	type row struct {
+		TrendPoint
+		Note string
+	}
+	rows := make([]row, len(points))
+	for i, p := range points {
+		rows[i] = row{p, trendNote(p)}
+	}
+	return trendTemplate.Execute(w, struct {
+		Name       string
+		Rows       []row
+		SlopePlot  template.HTML
+		RSqPlot    template.HTML
+		Thresholds TrendThresholds
+	}{name, rows, svgTrend(points, "slope", func(p TrendPoint) float64 { return p.Slope }),
+		svgTrend(points, "R²", func(p TrendPoint) float64 { return p.RSquared }), th})
+}
+
+// WriteTrendHTMLFile writes the trend page to path
+func WriteTrendHTMLFile(path, name string, points []TrendPoint, th TrendThresholds) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := WriteTrendHTML(f, name, points, th); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// svgTrend plots a value of the successful fits against their run ids
+func svgTrend(points []TrendPoint, title string, value func(TrendPoint) float64) template.HTML {
+	var ok []TrendPoint
+	for _, p := range points {
+		if p.Error == "" && !isMissing(value(p)) {
+			ok = append(ok, p)
+		}
+	}
+	if len(ok) == 0 {
+		return template.HTML(`<p>No successful fits to plot.</p>`)
+	}
+	yMin, yMax := value(ok[0]), value(ok[0])
+	for _, p := range ok {
+		yMin, yMax = math.Min(yMin, value(p)), math.Max(yMax, value(p))
+	}
+	f := newPlotFrame(float64(ok[0].Run), float64(ok[len(ok)-1].Run), yMin, yMax)
+
+	var b strings.Builder
+	f.open(&b, "run", title)
+	b.WriteString(`<polyline fill="none" stroke="#3b6ea5" stroke-width="1.5" points="`)
+	for _, p := range ok {
+		fmt.Fprintf(&b, "%.1f,%.1f ", f.px(float64(p.Run)), f.py(value(p)))
+	}
+	b.WriteString(`"/>`)
+	for _, p := range ok {
+		color := "#3b6ea5"
+		if p.Shifted {
+			color = "#c0392b"
+		}
+		fmt.Fprintf(&b, `<circle cx="%.1f" cy="%.1f" r="3.5" fill="%s"><title>run %d: %.6g</title></circle>`, f.px(float64(p.Run)), f.py(value(p)), color, p.Run, value(p))
+	}
+	b.WriteString(`</svg>`)
+	return template.HTML(b.String())
+}
+
+var trendTemplate = template.Must(template.New("trend").Funcs(template.FuncMap{
+	"num":     func(v float64) string { return fmt.Sprintf("%.6f", v) },
+	"percent": func(v float64) string { return fmt.Sprintf("%+.2f%%", 100*v) },
+	"share":   func(v float64) string { return fmt.Sprintf("%g%%", 100*v) },
+	"signed":  func(v float64) string { return fmt.Sprintf("%+.4f", v) },
+	"time":    func(t time.Time) string { return t.Local().Format("2006-01-02 15:04:05") },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Trend of {{.Name}}</title>
+<style>
+body { font-family: system-ui, sans-serif; margin: 2rem; color: #222; }
+table { border-collapse: collapse; margin-top: 1.5rem; }
+th { font-weight: normal; color: #666; text-align: left; padding-right: 1rem; }
+td { padding: 0.15rem 1rem 0.15rem 0; }
+tr.shifted td { color: #a33; }
+.plots { display: flex; flex-wrap: wrap; gap: 1.5rem; }
+figure { margin: 0; }
+figcaption { font-size: 0.9rem; color: #444; margin-bottom: 0.25rem; }
+svg text { font-size: 11px; fill: #555; }
+</style>
+</head>
+<body>
+<h1>Trend of {{.Name}}</h1>
+<div class="plots">
+<figure><figcaption>Slope by run</figcaption>{{.SlopePlot}}</figure>
+<figure><figcaption>R-squared by run</figcaption>{{.RSqPlot}}</figure>
+</div>
+<table>
+<tr><th>Run</th><th>Started</th><th>Slope</th><th>Change</th><th>R-squared</th><th>Change</th><th>Note</th><th>Data</th></tr>
+{{range $r := .Rows}}{{if $r.Error}}<tr><td>{{$r.Run}}</td><td>{{time $r.Started}}</td><td colspan="6">error: {{$r.Error}}</td></tr>
+{{else}}<tr{{if $r.Shifted}} class="shifted"{{end}}><td>{{$r.Run}}</td><td>{{time $r.Started}}</td><td>{{num $r.Slope}}</td><td>{{if $r.Compared}}{{percent $r.SlopeChange}}{{end}}</td><td>{{num $r.RSquared}}</td><td>{{if $r.Compared}}{{signed $r.RSquaredChange}}{{end}}</td><td>{{$r.Note}}</td><td>{{$r.Fingerprint}}</td></tr>
+{{end}}{{end}}</table>
+<p>Shifts are changes of slope beyond {{share .Thresholds.SlopeRel}} or of R-squared beyond {{.Thresholds.RSquaredAbs}} since the previous fit.</p>
+</body>
+</html>
+`))
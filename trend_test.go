@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ✅ Test 1: Each fit is compared with the previous successful one
+func TestDetectTrendChanges(t *testing.T) {
+	points := []TrendPoint{
+		{Run: 1, Slope: 0.5, RSquared: 0.66, Fingerprint: "a", Engine: "stats"},
+		{Run: 2, Slope: 0.51, RSquared: 0.665, Fingerprint: "a", Engine: "stats"},
+		{Run: 3, Error: "no points"},
+		{Run: 4, Slope: 0.6, RSquared: 0.665, Fingerprint: "b", Engine: "stats"},
+		{Run: 5, Slope: 0.6, RSquared: 0.6, Fingerprint: "b", Engine: "gonum"},
+	}
+	DetectTrendChanges(points, DefaultTrendThresholds())
+
+	if points[0].Compared || points[2].Compared {
+		t.Errorf("the first fit and failed fits have nothing to compare")
+	}
+	if p := points[1]; !p.Compared || p.Shifted || math.Abs(p.SlopeChange-0.02) > 1e-12 || p.DataChanged {
+		t.Errorf("run 2: expected a small change, got %+v", p)
+	}
+	// run 4 is compared with run 2, across the failure
+	if p := points[3]; !p.Shifted || math.Abs(p.SlopeChange-(0.6-0.51)/0.51) > 1e-12 || !p.DataChanged || p.EngineChanged {
+		t.Errorf("run 4: expected a slope shift with new data, got %+v", p)
+	}
+	if p := points[4]; !p.Shifted || p.SlopeChange != 0 || math.Abs(p.RSquaredChange+0.065) > 1e-12 || !p.EngineChanged {
+		t.Errorf("run 5: expected an R² shift with a new engine, got %+v", p)
+	}
+
+	zero := []TrendPoint{{Slope: 0}, {Slope: -1}}
+	DetectTrendChanges(zero, DefaultTrendThresholds())
+	if !math.IsInf(zero[1].SlopeChange, -1) || !zero[1].Shifted {
+		t.Errorf("expected an infinite change from a zero slope, got %+v", zero[1])
+	}
+}
+
+// ✅ Test 2: A dataset's series comes from every run that recorded it, oldest first
+func TestHistorySeries(t *testing.T) {
+	s := openTestHistory(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, slope := range []float64{0.5, 0.5, 0.7} {
+		manifest := NewManifest(analysisEngine)
+		manifest.Created = start.Add(time.Duration(i) * 24 * time.Hour)
+		rec := RunRecord{Datasets: []DatasetRecord{{Name: "other", Slope: 9}, {Name: "sales", Slope: slope, RSquared: 0.9, Fingerprint: "f"}}}
+		if _, err := s.Record(manifest, rec); err != nil {
+			t.Fatal(err)
+		}
+	}
+	points, err := s.Series("sales")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(points) != 3 || points[0].Run != 1 || points[2].Slope != 0.7 || !points[2].Started.Equal(start.Add(48*time.Hour)) {
+		t.Fatalf("unexpected series %+v", points)
+	}
+	if none, err := s.Series("missing"); err != nil || len(none) != 0 {
+		t.Errorf("expected no points for an unknown dataset, got %v (%v)", none, err)
+	}
+
+	DetectTrendChanges(points, DefaultTrendThresholds())
+	var text bytes.Buffer
+	printTrend(&text, "sales", points)
+	if !strings.Contains(text.String(), "3 runs, 1 shifts") || !strings.Contains(text.String(), "+40.00%") {
+		t.Errorf("unexpected trend table:\n%s", text.String())
+	}
+	var page bytes.Buffer
+	if err := WriteTrendHTML(&page, "sales", points, DefaultTrendThresholds()); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<h1>Trend of sales</h1>", `<tr class="shifted"><td>3</td>`, "<polyline", "beyond 5%"} {
+		if !strings.Contains(page.String(), want) {
+			t.Errorf("expected %q in the trend page", want)
+		}
+	}
+}
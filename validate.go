@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule is a constraint a dataset must satisfy before it is fitted.
+// Check returns nil when the dataset passes.
+type Rule struct {
+	Name  string
+	Check func(Dataset) *RuleViolation
+}
+
+// RuleViolation describes how a dataset broke a rule. Points lists the offending indexes for
+// per-point rules and is empty when the rule applies to the dataset as a whole.
+type RuleViolation struct {
+	Rule    string
+	Message string
+	Points  []int
+}
+
+// maxReportedPoints bounds how many offending indexes a violation message lists
+const maxReportedPoints = 5
+
+func (v RuleViolation) String() string {
+	if len(v.Points) == 0 {
+		return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+	}
+	shown := v.Points
+	more := ""
+	if len(shown) > maxReportedPoints {
+		shown, more = shown[:maxReportedPoints], " ..."
+	}
+	return fmt.Sprintf("%s: %s at %d points (indexes %v%s)", v.Rule, v.Message, len(v.Points), shown, more)
+}
+
+// ValidationError is returned when a dataset violates one or more rules, with one entry per failed rule
+type ValidationError struct {
+	Violations []RuleViolation
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		parts[i] = v.String()
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validate evaluates every rule against ds and returns a *ValidationError listing the failed ones
+func Validate(ds Dataset, rules []Rule) error {
+	var violations []RuleViolation
+	for _, rule := range rules {
+		if v := rule.Check(ds); v != nil {
+			v.Rule = rule.Name
+			violations = append(violations, *v)
+		}
+	}
+	if len(violations) > 0 {
+		return &ValidationError{Violations: violations}
+	}
+	return nil
+}
+
+// XStrictlyIncreasing requires every X to be greater than the previous one. Missing values are skipped.
+func XStrictlyIncreasing() Rule {
+	return Rule{Name: "x-increasing", Check: func(ds Dataset) *RuleViolation {
+		var points []int
+		prev, seen := 0.0, false
+		for i, x := range ds.X {
+			if isMissing(x) {
+				continue
+			}
+			if seen && x <= prev {
+				points = append(points, i)
+			}
+			prev, seen = x, true
+		}
+		return pointViolation("x is not strictly increasing", points)
+	}}
+}
+
+// XWithin requires every X to lie in [lo, hi]. Missing values are skipped.
+func XWithin(lo, hi float64) Rule {
+	return rangeRule("x-range", lo, hi, func(ds Dataset) []float64 { return ds.X })
+}
+
+// YWithin requires every Y to lie in [lo, hi]. Missing values are skipped.
+func YWithin(lo, hi float64) Rule {
+	return rangeRule("y-range", lo, hi, func(ds Dataset) []float64 { return ds.Y })
+}
+
+func rangeRule(name string, lo, hi float64, values func(Dataset) []float64) Rule {
+	return Rule{Name: name, Check: func(ds Dataset) *RuleViolation {
+		var points []int
+		for i, v := range values(ds) {
+			if !isMissing(v) && (v < lo || v > hi) {
+				points = append(points, i)
+			}
+		}
+		return pointViolation(fmt.Sprintf("outside [%g, %g]", lo, hi), points)
+	}}
+}
+
+// MinPoints requires at least n complete (finite x and y) pairs
+func MinPoints(n int) Rule {
+	return Rule{Name: "min-n", Check: func(ds Dataset) *RuleViolation {
+		complete := 0
+		for i := range ds.X {
+			if i < len(ds.Y) && !isMissing(ds.X[i]) && !isMissing(ds.Y[i]) {
+				complete++
+			}
+		}
+		if complete < n {
+			return &RuleViolation{Message: fmt.Sprintf("need at least %d complete points, have %d", n, complete)}
+		}
+		return nil
+	}}
+}
+
+// NoMissing rejects any point with a missing (NaN/Inf) x or y
+func NoMissing() Rule {
+	return Rule{Name: "no-missing", Check: func(ds Dataset) *RuleViolation {
+		var points []int
+		for i := range ds.X {
+			if isMissing(ds.X[i]) || (i < len(ds.Y) && isMissing(ds.Y[i])) {
+				points = append(points, i)
+			}
+		}
+		return pointViolation("missing value", points)
+	}}
+}
+
+func pointViolation(message string, points []int) *RuleViolation {
+	if len(points) == 0 {
+		return nil
+	}
+	return &RuleViolation{Message: message, Points: points}
+}
+
+// ParseRule reads a rule from its command-line form:
+// x-increasing, no-missing, min-n=N, x-range=LO:HI or y-range=LO:HI.
+func ParseRule(spec string) (Rule, error) {
+	name, arg, hasArg := strings.Cut(strings.TrimSpace(spec), "=")
+	switch name {
+	case "x-increasing", "no-missing":
+		if hasArg {
+			return Rule{}, fmt.Errorf("rule %s takes no argument", name)
+		}
+		if name == "no-missing" {
+			return NoMissing(), nil
+		}
+		return XStrictlyIncreasing(), nil
+	case "min-n":
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return Rule{}, fmt.Errorf("rule min-n needs a non-negative count, got %q", arg)
+		}
+		return MinPoints(n), nil
+	case "x-range", "y-range":
+		loText, hiText, ok := strings.Cut(arg, ":")
+		lo, errLo := strconv.ParseFloat(loText, 64)
+		hi, errHi := strconv.ParseFloat(hiText, 64)
+		if !ok || errLo != nil || errHi != nil || !(lo <= hi) {
+			return Rule{}, fmt.Errorf("rule %s needs LO:HI with LO <= HI, got %q", name, arg)
+		}
+		if name == "x-range" {
+			return XWithin(lo, hi), nil
+		}
+		return YWithin(lo, hi), nil
+	}
+	return Rule{}, fmt.Errorf("unknown rule %q (use x-increasing, no-missing, min-n=N, x-range=LO:HI or y-range=LO:HI)", name)
+}
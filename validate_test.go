@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// ✅ Test 1: Each rule reports the points that break it
+func TestValidateRules(t *testing.T) {
+	data := Dataset{X: []float64{1, 2, 2, math.NaN(), 5, 4}, Y: []float64{10, 20, 30, 40, 500, 60}}
+	err := Validate(data, []Rule{XStrictlyIncreasing(), YWithin(0, 100), NoMissing(), MinPoints(3)})
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) != 3 {
+		t.Fatalf("expected 3 violations, got %v", err)
+	}
+	want := map[string][]int{"x-increasing": {2, 5}, "y-range": {4}, "no-missing": {3}}
+	for _, v := range verr.Violations {
+		if !slices.Equal(v.Points, want[v.Rule]) {
+			t.Errorf("%s: expected points %v, got %v", v.Rule, want[v.Rule], v.Points)
+		}
+	}
+
+	if err := Validate(data, []Rule{MinPoints(6)}); err == nil || !strings.Contains(err.Error(), "have 5") {
+		t.Errorf("expected a min-n violation, got %v", err)
+	}
+	if err := Validate(LoadAnscombeDatasets()["I"], []Rule{MinPoints(11), YWithin(0, 20)}); err != nil {
+		t.Errorf("unexpected violation: %v", err)
+	}
+}
+
+// ✅ Test 2: Command-line rule specs parse, and malformed ones are rejected
+func TestParseRule(t *testing.T) {
+	for _, spec := range []string{"x-increasing", "no-missing", "min-n=10", "x-range=0:1", "y-range=-5:5"} {
+		if rule, err := ParseRule(spec); err != nil || rule.Check == nil {
+			t.Errorf("%s: %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"", "min-n=-1", "y-range=5:1", "x-range=1", "x-increasing=yes", "sorted"} {
+		if _, err := ParseRule(spec); err == nil {
+			t.Errorf("%q: expected an error", spec)
+		}
+	}
+}
+
+// ✅ Test 3: Batch jobs fail with the violations instead of being fitted
+func TestAnalyzeAllRules(t *testing.T) {
+	jobs := anscombeJobs()
+	for i := range jobs {
+		jobs[i].Rules = []Rule{XStrictlyIncreasing()}
+	}
+	for _, outcome := range AnalyzeAll(jobs, 2) {
+		var verr *ValidationError
+		if !errors.As(outcome.Err, &verr) || outcome.Result.Slope != 0 {
+			t.Errorf("%s: expected a validation error, got %v", outcome.Name, outcome.Err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// WeightedRegression fits y = intercept + slope·x minimizing Σ w·(y - ŷ)², accumulating weighted
+// means and co-moments in one pass (West's weighted form of Welford's update). Pairs with NaN/Inf
+// values or zero weight are skipped; negative or non-finite weights are an error.
+// R² is the weighted coefficient of determination 1 - Σw·r² / Σw·(y - ȳw)².
+func WeightedRegression(x, y, w []float64) (slope, intercept, rSquared float64, err error) {
+	if len(x) != len(y) || len(x) != len(w) {
+		return 0, 0, 0, fmt.Errorf("x, y and weight length mismatch: %d, %d, %d", len(x), len(y), len(w))
+	}
+	if len(x) < 2 {
+		return 0, 0, 0, fmt.Errorf("need at least two data points")
+	}
+
+	var sumW, meanX, meanY, cxx, cxy, cyy float64
+	n := 0
+	for i := range x {
+		if w[i] < 0 || math.IsNaN(w[i]) || math.IsInf(w[i], 0) {
+			return 0, 0, 0, fmt.Errorf("weight %d is %v; weights must be finite and non-negative", i, w[i])
+		}
+		if w[i] == 0 || isMissing(x[i]) || isMissing(y[i]) {
+			continue
+		}
+		n++
+		sumW += w[i]
+		dx := x[i] - meanX
+		dy := y[i] - meanY
+		meanX += w[i] / sumW * dx
+		meanY += w[i] / sumW * dy
+		cxx += w[i] * dx * (x[i] - meanX)
+		cxy += w[i] * dx * (y[i] - meanY)
+		cyy += w[i] * dy * (y[i] - meanY)
+	}
+	if n < 2 {
+		return 0, 0, 0, fmt.Errorf("not enough valid points after removing NaN/Inf and zero weights (have %d)", n)
+	}
+
+	// degenerate cases follow ManualRegression
+	if cxx > 0 {
+		slope = cxy / cxx
+	}
+	intercept = meanY - slope*meanX
+	switch {
+	case cyy > 0 && cxx > 0:
+		rSquared = cxy * cxy / (cxx * cyy)
+	case cyy > 0:
+		rSquared = 0
+	default:
+		rSquared = 1
+	}
+	return slope, intercept, rSquared, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// ✅ Test 1: Integer weights are equivalent to repeating points
+func TestWeightedRegressionRepeats(t *testing.T) {
+	x := []float64{1, 2, 3, 4}
+	y := []float64{2, 3.5, 7, 8}
+	w := []float64{1, 3, 0, 2}
+
+	var rx, ry []float64
+	for i := range x {
+		for k := 0; k < int(w[i]); k++ {
+			rx, ry = append(rx, x[i]), append(ry, y[i])
+		}
+	}
+
+	slope, intercept, r2, err := WeightedRegression(x, y, w)
+	refSlope, refIntercept, refR2, _ := FastRegression(rx, ry)
+	if err != nil || math.Abs(slope-refSlope) > 1e-12 || math.Abs(intercept-refIntercept) > 1e-12 || math.Abs(r2-refR2) > 1e-12 {
+		t.Errorf("weighted (%v %v %v %v) vs repeated (%v %v %v)", slope, intercept, r2, err, refSlope, refIntercept, refR2)
+	}
+}
+
+// ✅ Test 2: Invalid weights and inputs are rejected
+func TestWeightedRegressionValidation(t *testing.T) {
+	for _, w := range [][]float64{{1, -1, 1}, {1, math.NaN(), 1}, {1, 1}} {
+		if _, _, _, err := WeightedRegression([]float64{1, 2, 3}, []float64{1, 2, 3}, w); err == nil {
+			t.Errorf("expected error for weights %v", w)
+		}
+	}
+	if _, _, _, err := WeightedRegression([]float64{1, 2, 3}, []float64{1, 2, 3}, []float64{1, 0, 0}); err == nil {
+		t.Error("expected not enough valid points error")
+	}
+}